@@ -286,13 +286,14 @@ func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	return http.DefaultTransport.RoundTrip(req)
 }
 
-// RedactedURL returns a URL string with the userinfo redacted.
+// RedactedURL returns a URL string with the userinfo
+// and the secret parameters of the driver redacted.
 func RedactedURL(s string) (string, error) {
 	u, err := sqlclient.ParseURL(s)
 	if err != nil {
 		return "", err
 	}
-	return u.Redacted(), nil
+	return sqlclient.RedactURL(u), nil
 }
 
 // version of the CLI set by cmdapi.
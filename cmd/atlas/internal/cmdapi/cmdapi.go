@@ -43,6 +43,8 @@ var (
 		SelectedEnv string
 		// Vars contains the input variables passed from the CLI to Atlas DDL or project files.
 		Vars Vars
+		// Components contains the project components selected via the --component flag.
+		Components []string
 	}
 
 	// flavor holds Atlas flavor. Custom flavors (like the community build) should set this by build flag
@@ -97,6 +99,7 @@ func init() {
 		GlobalFlags.ConfigURL = ""
 		GlobalFlags.Vars = nil
 		GlobalFlags.SelectedEnv = ""
+		GlobalFlags.Components = nil
 	})
 }
 
@@ -209,15 +212,25 @@ func (v *Vars) Type() string {
 
 const (
 	flagAllowDirty     = "allow-dirty"
+	flagAnonymize      = "anonymize"
+	flagAnonChecks     = "anonymize-checks"
 	flagEdit           = "edit"
 	flagAutoApprove    = "auto-approve"
 	flagBaseline       = "baseline"
+	flagAddr           = "addr"
+	flagAllowURL       = "allow-url"
+	flagAuditPrincipal = "audit-principal"
+	flagAuditURL       = "audit-url"
+	flagBackfillBatch  = "backfill-batch-size"
+	flagBackfillSleep  = "backfill-sleep"
+	flagComponent      = "component"
 	flagConfig         = "config"
 	flagDevURL         = "dev-url"
 	flagDirURL         = "dir"
 	flagDirFormat      = "dir-format"
 	flagDryRun         = "dry-run"
 	flagEnv            = "env"
+	flagEstimate       = "estimate"
 	flagExclude        = "exclude"
 	flagFile           = "file"
 	flagFrom           = "from"
@@ -226,23 +239,32 @@ const (
 	flagGitBase        = "git-base"
 	flagGitDir         = "git-dir"
 	flagLatest         = "latest"
+	flagLeaseTTL       = "lease-ttl"
 	flagLockTimeout    = "lock-timeout"
 	flagLog            = "log"
+	flagNotifyURL      = "notify-url"
 	flagRevisionSchema = "revisions-schema"
+	flagRevisionStmts  = "revisions-stmts"
+	flagSavepoints     = "savepoints"
 	flagSchema         = "schema"
 	flagSchemaShort    = "s"
+	flagTLSCert        = "tls-cert"
+	flagTLSKey         = "tls-key"
 	flagTo             = "to"
 	flagTxMode         = "tx-mode"
 	flagURL            = "url"
 	flagURLShort       = "u"
 	flagVar            = "var"
 	flagQualifier      = "qualifier"
+	flagQuote          = "quote"
+	flagIdentCase      = "ident-case"
 )
 
 func addGlobalFlags(set *pflag.FlagSet) {
 	set.StringVar(&GlobalFlags.SelectedEnv, flagEnv, "", "set which env from the config file to use")
 	set.Var(&GlobalFlags.Vars, flagVar, "input variables")
 	set.StringVarP(&GlobalFlags.ConfigURL, flagConfig, "c", defaultConfigPath, "select config (project) file using URL format")
+	set.StringSliceVar(&GlobalFlags.Components, flagComponent, nil, "run the env for the given project components, and the components they depend on")
 }
 
 func addFlagAutoApprove(set *pflag.FlagSet, target *bool) {
@@ -410,6 +432,7 @@ type stateReaderConfig struct {
 	client, dev *sqlclient.Client // database connections, while dev is considered a dev database, client is not
 	schemas     []string          // schemas to work on
 	exclude     []string          // exclude flag values
+	searchPath  []string          // schemas that unqualified references are resolved from
 	vars        Vars
 }
 
@@ -425,12 +448,13 @@ func (c *stateReaderConfig) Exported() (*cmdext.StateReaderConfig, error) {
 		}
 	}
 	return &cmdext.StateReaderConfig{
-		URLs:    parsed,
-		Client:  c.client,
-		Dev:     c.dev,
-		Schemas: c.schemas,
-		Exclude: c.exclude,
-		Vars:    c.vars,
+		URLs:       parsed,
+		Client:     c.client,
+		Dev:        c.dev,
+		Schemas:    c.schemas,
+		Exclude:    c.exclude,
+		SearchPath: c.searchPath,
+		Vars:       c.vars,
 	}, nil
 }
 
@@ -10,11 +10,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"text/template"
 
 	"ariga.io/atlas/cmd/atlas/internal/cloudapi"
 	cmdmigrate "ariga.io/atlas/cmd/atlas/internal/migrate"
 	"ariga.io/atlas/cmd/atlas/internal/migratelint"
+	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlcheck"
 	"ariga.io/atlas/sql/sqlclient"
 
@@ -44,6 +46,7 @@ func init() {
 		migrateValidateCmd(),
 	)
 	Root.AddCommand(migrateCmd)
+	Root.AddCommand(serverCmd())
 }
 
 // migrateLintSetFlags allows setting extra flags for the 'migrate lint' command.
@@ -69,7 +72,9 @@ func migrateLintRun(cmd *cobra.Command, _ []string, flags migrateLintFlags) erro
 	case flags.latest > 0:
 		detect = migratelint.LatestChanges(dir, int(flags.latest))
 	case flags.gitBase != "":
-		detect, err = migratelint.NewGitChangeDetector(
+		// Only files that were added or modified compared
+		// to the base branch are analyzed.
+		detect, err = migratelint.NewGitDir(
 			dir,
 			migratelint.WithWorkDir(flags.gitDir),
 			migratelint.WithBase(flags.gitBase),
@@ -104,6 +109,9 @@ func migrateLintRun(cmd *cobra.Command, _ []string, flags migrateLintFlags) erro
 		},
 		Analyzers: az,
 	}
+	if r.Stats, err = lintStats(cmd.Context(), flags.url, az); err != nil {
+		return err
+	}
 	err = r.Run(cmd.Context())
 	// Print the error in case it was not printed before.
 	cmd.SilenceErrors = errors.As(err, &migratelint.SilentError{})
@@ -111,6 +119,27 @@ func migrateLintRun(cmd *cobra.Command, _ []string, flags migrateLintFlags) erro
 	return err
 }
 
+// lintStats inspects the table statistics of the target database, in case
+// it was given and one of the analyzers takes the size of tables into account.
+func lintStats(ctx context.Context, url string, az []sqlcheck.Analyzer) (*schema.Realm, error) {
+	if url == "" || !slices.ContainsFunc(az, func(a sqlcheck.Analyzer) bool {
+		sa, ok := a.(sqlcheck.StatsAnalyzer)
+		return ok && sa.UsesStats()
+	}) {
+		return nil, nil
+	}
+	c, err := sqlclient.Open(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	opts := &schema.InspectRealmOption{Stats: true}
+	if c.URL.Schema != "" {
+		opts.Schemas = append(opts.Schemas, c.URL.Schema)
+	}
+	return c.InspectRealm(ctx, opts)
+}
+
 func promptApply(cmd *cobra.Command, flags schemaApplyFlags, diff *diff, client, _ *sqlclient.Client) error {
 	if !flags.dryRun && (flags.autoApprove || promptUser(cmd)) {
 		return applyChanges(cmd.Context(), client, diff.changes, flags.txMode)
@@ -14,6 +14,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"os/user"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -27,6 +28,7 @@ import (
 	cmdmigrate "ariga.io/atlas/cmd/atlas/internal/migrate"
 	"ariga.io/atlas/cmd/atlas/internal/migrate/ent/revision"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/migrate/notify"
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlclient"
 	"ariga.io/atlas/sql/sqltool"
@@ -56,9 +58,18 @@ type migrateApplyFlags struct {
 	dryRun          bool
 	logFormat       string
 	lockTimeout     time.Duration
-	allowDirty      bool   // allow working on a database that already has resources
-	baselineVersion string // apply with this version as baseline
-	txMode          string // (none, file, all)
+	leaseTTL        time.Duration // lease the revisions table, if set
+	auditURL        string        // record executed statements to this sink, if set
+	auditPrincipal  string        // principal attached to audit records
+	notifyURLs      []string      // send lifecycle events to these webhooks, if set
+	allowDirty      bool          // allow working on a database that already has resources
+	baselineVersion string        // apply with this version as baseline
+	txMode          string        // (none, file, all)
+	savepoints      bool          // wrap each statement in a savepoint
+	revisionStmts   bool          // store the text of the executed statements in the revisions
+	backfillSize    int           // run backfill statements in batches of this size, if set
+	backfillSleep   time.Duration // sleep between backfill batches
+	protect         []*migrate.ProtectionRule
 }
 
 func (f *migrateApplyFlags) migrateOptions() (opts []migrate.ExecutorOption) {
@@ -68,6 +79,12 @@ func (f *migrateApplyFlags) migrateOptions() (opts []migrate.ExecutorOption) {
 	if v := f.baselineVersion; v != "" {
 		opts = append(opts, migrate.WithBaselineVersion(v))
 	}
+	if f.backfillSize > 0 {
+		opts = append(opts, migrate.WithBackfill(f.backfillSize, f.backfillSleep))
+	}
+	if f.revisionStmts {
+		opts = append(opts, migrate.WithStmtText(true))
+	}
 	return
 }
 
@@ -93,7 +110,14 @@ If run with the "--dry-run" flag, atlas will not execute any SQL.`,
 			RunE: func(cmd *cobra.Command, args []string) (cmdErr error) {
 				switch {
 				case GlobalFlags.SelectedEnv == "":
-					if err := migrateFlagsFromConfig(cmd); err != nil {
+					env, err := selectEnv(cmd)
+					if err != nil {
+						return err
+					}
+					if err := setMigrateEnvFlags(cmd, env); err != nil {
+						return err
+					}
+					if flags.protect, err = env.ProtectionRules(promptProtected(cmd)); err != nil {
 						return err
 					}
 					return migrateApplyRun(cmd, args, flags, &MigrateReport{}) // nop reporter
@@ -111,11 +135,14 @@ If run with the "--dry-run" flag, atlas will not execute any SQL.`,
 							set.Flush(cmd, cmdErr)
 						}
 					}()
-					return cmdEnvsRun(envs, setMigrateEnvFlags, cmd, func(env *Env) error {
+					return cmdEnvsRun(envs, setMigrateEnvFlags, cmd, func(env *Env) (err error) {
 						// Report deployments only if one of the migration directories is a cloud directory.
 						if u, err := url.Parse(flags.dirURL); err == nil && u.Scheme != cmdmigrate.DirTypeFile {
 							hasRemote = true
 						}
+						if flags.protect, err = env.ProtectionRules(promptProtected(cmd)); err != nil {
+							return err
+						}
 						return migrateApplyRun(cmd, args, flags, set.ReportFor(flags, env))
 					})
 				}
@@ -132,7 +159,15 @@ If run with the "--dry-run" flag, atlas will not execute any SQL.`,
 	addFlagLockTimeout(cmd.Flags(), &flags.lockTimeout)
 	cmd.Flags().StringVarP(&flags.baselineVersion, flagBaseline, "", "", "start the first migration after the given baseline version")
 	cmd.Flags().StringVarP(&flags.txMode, flagTxMode, "", txModeFile, "set transaction mode [none, file, all]")
+	cmd.Flags().BoolVarP(&flags.savepoints, flagSavepoints, "", false, "roll back only the failed statement and commit the statements executed before it")
 	cmd.Flags().BoolVarP(&flags.allowDirty, flagAllowDirty, "", false, "allow start working on a non-clean database")
+	cmd.Flags().DurationVarP(&flags.leaseTTL, flagLeaseTTL, "", 0, "lease the revisions table with the given ttl, renewed by heartbeats while applying")
+	cmd.Flags().StringVarP(&flags.auditURL, flagAuditURL, "", "", "record executed statements to an audit sink [file://, s3://, or a database URL]")
+	cmd.Flags().StringVarP(&flags.auditPrincipal, flagAuditPrincipal, "", "", "principal attached to audit records (defaults to the OS user)")
+	cmd.Flags().StringSliceVarP(&flags.notifyURLs, flagNotifyURL, "", nil, "send lifecycle events of the execution to the given webhook URLs (Slack incoming webhooks are detected by their host)")
+	cmd.Flags().IntVarP(&flags.backfillSize, flagBackfillBatch, "", 0, "run statements marked with atlas:backfill in key-range batches of the given size")
+	cmd.Flags().DurationVarP(&flags.backfillSleep, flagBackfillSleep, "", 0, "sleep between backfill batches")
+	cmd.Flags().BoolVarP(&flags.revisionStmts, flagRevisionStmts, "", false, "store the text of the executed statements in the revisions table (it may contain sensitive literals)")
 	cmd.MarkFlagsMutuallyExclusive(flagLog, flagFormat)
 	return cmd
 }
@@ -164,6 +199,9 @@ func migrateApplyRun(cmd *cobra.Command, args []string, flags migrateApplyFlags,
 	if flags.url == "" {
 		return errors.New(`required flag "url" not set`)
 	}
+	if flags.savepoints && flags.txMode == txModeNone {
+		return fmt.Errorf("flag --%s cannot be used with --%s %s", flagSavepoints, flagTxMode, txModeNone)
+	}
 	client, err := sqlclient.Open(ctx, flags.url)
 	if err != nil {
 		return err
@@ -194,6 +232,28 @@ func migrateApplyRun(cmd *cobra.Command, args []string, flags migrateApplyFlags,
 	if err := mrrw.Migrate(ctx); err != nil {
 		return err
 	}
+	// Lease the revisions table, so a crashed applier
+	// does not block other runners after the lease expires.
+	if flags.leaseTTL > 0 && !flags.dryRun {
+		l, ok := mrrw.(cmdmigrate.Leaser)
+		if !ok {
+			return fmt.Errorf("revisions read-writer %T does not support leases", mrrw)
+		}
+		lease, err := l.AcquireLease(ctx, cmdmigrate.LeaseHolder(), operatorVersion(), flags.leaseTTL)
+		if err != nil {
+			return fmt.Errorf("acquiring revisions lease: %w", err)
+		}
+		if p := lease.Takeover; p != nil {
+			cmd.PrintErrf("Taking over a stale revisions lease of %q (last heartbeat at %s)\n", p.Holder, p.Heartbeat.Format(time.RFC3339))
+		}
+		var release func() error
+		ctx, release = l.KeepAlive(ctx, lease, flags.leaseTTL/3)
+		defer func() {
+			if rerr := release(); rerr != nil && err == nil {
+				err = fmt.Errorf("releasing revisions lease: %w", rerr)
+			}
+		}()
+	}
 	// Setup reporting info.
 	report := cmdlog.NewMigrateApply(ctx, client, dir)
 	mr.Init(client, report, mrrw)
@@ -202,8 +262,42 @@ func migrateApplyRun(cmd *cobra.Command, args []string, flags migrateApplyFlags,
 	if err := mr.RecordTargetID(cmd.Context()); err != nil {
 		return err
 	}
+	// Lifecycle events are sent only for executions that run statements.
+	var logger migrate.Logger = report
+	if len(flags.notifyURLs) > 0 && !flags.dryRun {
+		n, err := notifiers(flags.notifyURLs)
+		if err != nil {
+			return err
+		}
+		nl := notify.NewLogger(ctx, n, report)
+		defer func() {
+			// Notification failures do not fail the execution.
+			if nerr := nl.Close(); nerr != nil {
+				cmd.PrintErrf("Warning: sending notifications: %v\n", nerr)
+			}
+		}()
+		logger = nl
+	}
 	// Determine pending files.
-	opts := append(flags.migrateOptions(), migrate.WithOperatorVersion(operatorVersion()), migrate.WithLogger(report))
+	opts := append(flags.migrateOptions(), migrate.WithOperatorVersion(operatorVersion()), migrate.WithLogger(logger))
+	// Record executed statements to the audit sink. Statements
+	// are not executed in dry-run mode, and thus not audited.
+	if flags.auditURL != "" && !flags.dryRun {
+		sink, closeSink, err := cmdmigrate.OpenAuditSink(ctx, flags.auditURL)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := closeSink(); cerr != nil && err == nil {
+				err = fmt.Errorf("closing audit sink: %w", cerr)
+			}
+		}()
+		principal := flags.auditPrincipal
+		if principal == "" {
+			principal = auditPrincipal()
+		}
+		opts = append(opts, migrate.WithAuditSink(sink, principal, client.URL.Redacted()))
+	}
 	ex, err := migrate.NewExecutor(client.Driver, dir, rrw, opts...)
 	if err != nil {
 		return err
@@ -228,14 +322,21 @@ func migrateApplyRun(cmd *cobra.Command, args []string, flags migrateApplyFlags,
 		count = l
 	}
 	pending = pending[:count]
-	migrate.LogIntro(report, applied, pending)
+	// Statements are not executed in dry-run mode.
+	if len(flags.protect) > 0 && !flags.dryRun {
+		if err := protectMigrateApply(ctx, client, pending, flags.protect); err != nil {
+			return err
+		}
+	}
+	migrate.LogIntro(logger, applied, pending)
 	var (
 		mux = tx{
-			dryRun: flags.dryRun,
-			mode:   flags.txMode,
-			schema: flags.revisionSchema,
-			c:      client,
-			rrw:    rrw,
+			dryRun:     flags.dryRun,
+			mode:       flags.txMode,
+			schema:     flags.revisionSchema,
+			savepoints: flags.savepoints,
+			c:          client,
+			rrw:        rrw,
 		}
 		drv migrate.Driver
 	)
@@ -243,7 +344,13 @@ func migrateApplyRun(cmd *cobra.Command, args []string, flags migrateApplyFlags,
 		if drv, rrw, err = mux.driverFor(ctx, f); err != nil {
 			break
 		}
-		if ex, err = migrate.NewExecutor(drv, dir, rrw, opts...); err != nil {
+		fopts := opts
+		// Savepoints are usable only within a transaction, and
+		// files may opt out of it using the txmode directive.
+		if mux.savepoints && mux.tx != nil {
+			fopts = append(opts[:len(opts):len(opts)], migrate.WithSavepoints(true))
+		}
+		if ex, err = migrate.NewExecutor(drv, dir, rrw, fopts...); err != nil {
 			return fmt.Errorf("unexpected executor creation error: %w", err)
 		}
 		if err = mux.mayRollback(ex.Execute(ctx, f)); err != nil {
@@ -255,15 +362,57 @@ func migrateApplyRun(cmd *cobra.Command, args []string, flags migrateApplyFlags,
 	}
 	if err == nil {
 		if err = mux.commit(); err == nil {
-			report.Log(migrate.LogDone{})
+			logger.Log(migrate.LogDone{})
 		}
 	}
-	if err != nil {
+	switch {
+	case errors.Is(err, migrate.ErrAborted):
+		// Graceful aborts are reported as such, and not as failed migrations.
+		report.Aborted = true
+	case err != nil:
 		report.Error = err.Error()
 	}
 	return errors.Join(err, mr.Done(cmd, flags))
 }
 
+// notifiers returns the webhook notifiers of the given URLs.
+func notifiers(urls []string) (notify.Notifiers, error) {
+	ns := make(notify.Notifiers, 0, len(urls))
+	for _, s := range urls {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse notify url: %w", err)
+		}
+		var n notify.Notifier
+		if u.Host == "hooks.slack.com" {
+			n, err = notify.NewSlack(s)
+		} else {
+			n, err = notify.NewWebhook(s)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+// protectMigrateApply checks the statements of the pending files against the protection rules.
+func protectMigrateApply(ctx context.Context, client *sqlclient.Client, pending []migrate.File, rules []*migrate.ProtectionRule) error {
+	a := &migrate.ProtectedApply{URL: client.URL.Redacted()}
+	for _, f := range pending {
+		stmts, err := f.Stmts()
+		if err != nil {
+			return fmt.Errorf("reading statements of %q: %w", f.Name(), err)
+		}
+		for _, s := range stmts {
+			a.Class |= migrate.ClassifyStmt(s)
+		}
+		a.Stmts = append(a.Stmts, stmts...)
+	}
+	return migrate.CheckProtection(ctx, rules, a)
+}
+
 type (
 	// MigrateReport responsible for reporting 'migrate apply' reports.
 	MigrateReport struct {
@@ -532,6 +681,7 @@ type migrateDiffFlags struct {
 	lockTimeout       time.Duration
 	format            string
 	qualifier         string // optional table qualifier
+	quote, identCase  string // identifiers style
 }
 
 // migrateDiffCmd represents the 'atlas migrate diff' subcommand.
@@ -577,14 +727,44 @@ an HCL, SQL, or ORM schema. See: https://atlasgo.io/versioned/diff`,
 	addFlagLockTimeout(cmd.Flags(), &flags.lockTimeout)
 	addFlagFormat(cmd.Flags(), &flags.format)
 	cmd.Flags().StringVar(&flags.qualifier, flagQualifier, "", "qualify tables with custom qualifier when working on a single schema")
+	cmd.Flags().StringVar(&flags.quote, flagQuote, "always", "set when identifiers are quoted: always, needed or never")
+	cmd.Flags().StringVar(&flags.identCase, flagIdentCase, "", "normalize the case of identifiers in the desired schema: lower or upper")
 	cmd.Flags().BoolVarP(&flags.edit, flagEdit, "", false, "edit the generated migration file(s)")
 	cobra.CheckErr(cmd.MarkFlagRequired(flagTo))
 	cobra.CheckErr(cmd.MarkFlagRequired(flagDevURL))
 	return cmd
 }
 
+// identOptions returns the planner options for the configured identifiers style.
+func (f *migrateDiffFlags) identOptions() ([]migrate.PlannerOption, error) {
+	var opts []migrate.PlannerOption
+	switch strings.ToLower(f.quote) {
+	case "", "always":
+	case "needed":
+		opts = append(opts, migrate.PlanWithQuotePolicy(migrate.QuoteNeeded))
+	case "never":
+		opts = append(opts, migrate.PlanWithQuotePolicy(migrate.QuoteNever))
+	default:
+		return nil, fmt.Errorf("unknown --%s value %q. Expect one of: always, needed or never", flagQuote, f.quote)
+	}
+	switch strings.ToLower(f.identCase) {
+	case "":
+	case "lower":
+		opts = append(opts, migrate.PlanWithIdentCase(migrate.CaseLower))
+	case "upper":
+		opts = append(opts, migrate.PlanWithIdentCase(migrate.CaseUpper))
+	default:
+		return nil, fmt.Errorf("unknown --%s value %q. Expect one of: lower or upper", flagIdentCase, f.identCase)
+	}
+	return opts, nil
+}
+
 func migrateDiffRun(cmd *cobra.Command, args []string, flags migrateDiffFlags, env *Env) error {
 	ctx := cmd.Context()
+	identOpts, err := flags.identOptions()
+	if err != nil {
+		return err
+	}
 	dev, err := sqlclient.Open(ctx, flags.devURL)
 	if err != nil {
 		return err
@@ -611,6 +791,23 @@ func migrateDiffRun(cmd *cobra.Command, args []string, flags migrateDiffFlags, e
 	if flags.edit {
 		dir = &editDir{dir}
 	}
+	// Migrations of the components that the component depends on are
+	// replayed first, as its migrations may reference their objects.
+	if env.scope != nil {
+		cd := &componentDir{Dir: dir}
+		for _, d := range env.scope.depDirs() {
+			u, err := url.Parse(d)
+			if err != nil {
+				return err
+			}
+			dep, err := cmdmigrate.DirURL(ctx, u, false)
+			if err != nil {
+				return err
+			}
+			cd.deps = append(cd.deps, dep)
+		}
+		dir = cd
+	}
 	var name, indent string
 	if len(args) > 0 {
 		name = args[0]
@@ -637,21 +834,29 @@ func migrateDiffRun(cmd *cobra.Command, args []string, flags migrateDiffFlags, e
 	}
 	// Get a state reader for the desired state.
 	desired, err := stateReader(ctx, &stateReaderConfig{
-		urls:    flags.desiredURLs,
-		dev:     dev,
-		client:  dev,
-		schemas: flags.schemas,
-		vars:    GlobalFlags.Vars,
+		urls:       flags.desiredURLs,
+		dev:        dev,
+		client:     dev,
+		schemas:    flags.schemas,
+		searchPath: env.SearchPath(),
+		vars:       GlobalFlags.Vars,
 	})
 	if err != nil {
 		return err
 	}
 	defer desired.Close()
+	var owners map[string]string
+	if env.scope != nil {
+		if owners, err = env.scope.owners(ctx, dev, dev, dev.URL.Schema == ""); err != nil {
+			return err
+		}
+	}
 	opts := []migrate.PlannerOption{
 		migrate.PlanFormat(f),
 		migrate.PlanWithIndent(indent),
 		migrate.PlanWithDiffOptions(env.DiffOptions()...),
 	}
+	opts = append(opts, identOpts...)
 	if dev.URL.Schema != "" {
 		// Disable tables qualifier in schema-mode.
 		opts = append(opts, migrate.PlanWithSchemaQualifier(flags.qualifier))
@@ -671,6 +876,11 @@ func migrateDiffRun(cmd *cobra.Command, args []string, flags migrateDiffFlags, e
 	case err != nil:
 		return maskNoPlan(cmd, err)
 	default:
+		if env.scope != nil {
+			if env.scope.scopePlan(plan, owners, dev.URL.Schema == ""); len(plan.Changes) == 0 {
+				return maskNoPlan(cmd, migrate.ErrNoPlan)
+			}
+		}
 		// Write the plan to a new file.
 		return pl.WritePlan(plan)
 	}
@@ -893,6 +1103,7 @@ func migrateImportRun(cmd *cobra.Command, _ []string, flags migrateImportFlags)
 type migrateLintFlags struct {
 	dirURL, dirFormat string
 	devURL            string
+	url               string // Optional target database for reading table statistics.
 	logFormat         string
 	latest            uint   // --latest 1
 	gitBase, gitDir   string // --git-base master --git-dir /path/to/git/repo
@@ -926,6 +1137,7 @@ func migrateLintCmd() *cobra.Command {
 	)
 	cmd.Flags().SortFlags = false
 	addFlagDevURL(cmd.Flags(), &flags.devURL)
+	addFlagURL(cmd.Flags(), &flags.url)
 	addFlagDirURL(cmd.Flags(), &flags.dirURL)
 	addFlagDirFormat(cmd.Flags(), &flags.dirFormat)
 	addFlagLog(cmd.Flags(), &flags.logFormat)
@@ -1200,6 +1412,7 @@ type migrateStatusFlags struct {
 	dirURL, dirFormat string
 	revisionSchema    string
 	logFormat         string
+	estimate          bool
 }
 
 // migrateStatusCmd represents the 'atlas migrate status' subcommand.
@@ -1233,6 +1446,7 @@ func migrateStatusCmd() *cobra.Command {
 	addFlagDirURL(cmd.Flags(), &flags.dirURL)
 	addFlagDirFormat(cmd.Flags(), &flags.dirFormat)
 	addFlagRevisionSchema(cmd.Flags(), &flags.revisionSchema)
+	cmd.Flags().BoolVar(&flags.estimate, flagEstimate, false, "estimate the execution time of the pending files")
 	cmd.MarkFlagsMutuallyExclusive(flagLog, flagFormat)
 	return cmd
 }
@@ -1252,9 +1466,10 @@ func migrateStatusRun(cmd *cobra.Command, _ []string, flags migrateStatusFlags)
 		return err
 	}
 	report, err := (&cmdmigrate.StatusReporter{
-		Client: client,
-		Dir:    dir,
-		Schema: revisionSchemaName(client, flags.revisionSchema),
+		Client:   client,
+		Dir:      dir,
+		Schema:   revisionSchemaName(client, flags.revisionSchema),
+		Estimate: flags.estimate,
 	}).Report(ctx)
 	if err != nil {
 		return err
@@ -1420,8 +1635,11 @@ const (
 type tx struct {
 	dryRun       bool
 	mode, schema string
-	c            *sqlclient.Client
-	rrw          migrate.RevisionReadWriter
+	// savepoints indicates that failed statements were rolled back to their savepoint,
+	// and the statements executed before them are committed instead of rolled back.
+	savepoints bool
+	c          *sqlclient.Client
+	rrw        migrate.RevisionReadWriter
 	// current transaction context.
 	tx    *sqlclient.TxClient
 	txrrw migrate.RevisionReadWriter
@@ -1475,6 +1693,14 @@ func (tx *tx) driverFor(ctx context.Context, f migrate.File) (migrate.Driver, mi
 
 // mayRollback may roll back a transaction depending on the given transaction mode.
 func (tx *tx) mayRollback(err error) error {
+	// The transaction is still usable, and the partially applied
+	// file is committed along with its revision to be resumed later.
+	if tx.tx != nil && err != nil && tx.savepoints && !tx.dryRun {
+		if err2 := tx.commit(); err2 != nil {
+			err = fmt.Errorf("%v: %w", err2, err)
+		}
+		return err
+	}
 	if tx.tx != nil && err != nil {
 		if err2 := tx.tx.Rollback(); err2 != nil {
 			err = fmt.Errorf("%v: %w", err2, err)
@@ -1528,6 +1754,14 @@ func operatorVersion() string {
 	return "Atlas CLI " + v
 }
 
+// auditPrincipal returns the default principal of audit records.
+func auditPrincipal() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "unknown"
+}
+
 // dirFormatBC ensures the soon-to-be deprecated --dir-format flag gets set on all migration directory URLs.
 func dirFormatBC(flag string, urls ...*string) error {
 	for _, s := range urls {
@@ -1663,6 +1897,12 @@ func setMigrateEnvFlags(cmd *cobra.Command, env *Env) error {
 		if err := maySetFlag(cmd, flagLockTimeout, env.Migration.LockTimeout); err != nil {
 			return err
 		}
+		if err := maySetFlag(cmd, flagLeaseTTL, env.Migration.LeaseTTL); err != nil {
+			return err
+		}
+		if err := maySetFlag(cmd, flagAuditURL, env.Migration.AuditURL); err != nil {
+			return err
+		}
 	case "diff", "checkpoint":
 		if err := maySetFlag(cmd, flagLockTimeout, env.Migration.LockTimeout); err != nil {
 			return err
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -27,6 +28,7 @@ import (
 	"ariga.io/atlas/cmd/atlas/internal/cmdlog"
 	migrate2 "ariga.io/atlas/cmd/atlas/internal/migrate"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/migrate/notify"
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlclient"
 	"ariga.io/atlas/sql/sqlite"
@@ -600,6 +602,60 @@ env "prod" {
 	})
 }
 
+func TestMigrate_ApplyProtect(t *testing.T) {
+	p := t.TempDir()
+	h := fmt.Sprintf(`
+variable "url" {
+  type = string
+}
+
+env "local" {
+  url = var.url
+  migration {
+    dir = "file://testdata/sqlite"
+  }
+  protect {
+    urls    = ["sqlite://file:%[1]s/protected.*"]
+    approve = true
+  }
+  protect {
+    urls    = ["sqlite://file:%[1]s/closed.*"]
+    windows = [%[2]q]
+  }
+}
+`, p, time.Now().UTC().Add(48*time.Hour).Weekday().String()+" 00:00-24:00")
+	path := filepath.Join(p, "atlas.hcl")
+	require.NoError(t, os.WriteFile(path, []byte(h), 0600))
+	run := func(name string, args ...string) (string, error) {
+		cmd := migrateCmd()
+		cmd.AddCommand(migrateApplyCmd())
+		cmd.SetIn(strings.NewReader(""))
+		return runCmd(
+			cmd, append([]string{
+				"apply",
+				"-c", "file://" + path,
+				"--env", "local",
+				"--var", fmt.Sprintf("url=sqlite://file:%s?cache=shared&_fk=1", filepath.Join(p, name)),
+			}, args...)...,
+		)
+	}
+	s, err := run("other.db")
+	require.NoError(t, err)
+	require.Contains(t, s, "Migrating to version 20220318104615 (2 migrations in total)")
+
+	// Approval is required, but the input is not interactive.
+	_, err = run("protected.db")
+	require.ErrorIs(t, err, migrate.ErrNotApproved)
+	require.ErrorContains(t, err, "protected.db\" is protected: apply was not approved")
+	// Dry runs are not protected.
+	s, err = run("protected.db", "--dry-run")
+	require.NoError(t, err)
+	require.Contains(t, s, "Migrating to version 20220318104615 (2 migrations in total)")
+
+	_, err = run("closed.db")
+	require.ErrorContains(t, err, "closed.db\" is protected: applies are allowed only within")
+}
+
 func TestMigrate_ApplyTxMode(t *testing.T) {
 	for _, mode := range []string{"none", "file", "all"} {
 		t.Run(mode, func(t *testing.T) {
@@ -709,6 +765,77 @@ func TestMigrate_ApplyTxModeDirective(t *testing.T) {
 	require.Equal(t, s, `unknown txmode "unknown" found in file directive "20220925094021_second.sql"`)
 }
 
+func TestMigrate_ApplySavepoints(t *testing.T) {
+	p := t.TempDir()
+	dir, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, dir.WriteFile("1_t.sql", []byte("CREATE TABLE t1(c int);\nINSERT INTO t2 VALUES (1);\n")))
+	sum, err := dir.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+	u := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(p, "test.db"))
+
+	_, err = runCmd(migrateApplyCmd(), "--dir", "file://"+p, "--url", u, "--tx-mode", txModeNone, "--savepoints")
+	require.EqualError(t, err, "flag --savepoints cannot be used with --tx-mode none")
+
+	// The failed statement is rolled back, and the statements
+	// executed before it are committed along with the revision.
+	_, err = runCmd(migrateApplyCmd(), "--dir", "file://"+p, "--url", u, "--savepoints")
+	require.ErrorContains(t, err, "no such table: t2")
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?cache=shared&_fk=1", filepath.Join(p, "test.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 't1'").Scan(&n))
+	require.Equal(t, 1, n)
+	var applied int
+	require.NoError(t, db.QueryRow("SELECT applied FROM atlas_schema_revisions WHERE version = '1'").Scan(&applied))
+	require.Equal(t, 1, applied)
+
+	// Resuming continues from the failed statement.
+	_, err = db.Exec("CREATE TABLE t2(c int)")
+	require.NoError(t, err)
+	_, err = runCmd(migrateApplyCmd(), "--dir", "file://"+p, "--url", u, "--savepoints")
+	require.NoError(t, err)
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM t2").Scan(&n))
+	require.Equal(t, 1, n)
+}
+
+func TestMigrate_ApplyNotify(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []*notify.Event
+		srv    = httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			var e notify.Event
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&e))
+			mu.Lock()
+			events = append(events, &e)
+			mu.Unlock()
+		}))
+	)
+	defer srv.Close()
+	p := t.TempDir()
+	dir, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, dir.WriteFile("1_t.sql", []byte("CREATE TABLE t1(c int);\nCREATE TABLE t2(c int);\n")))
+	sum, err := dir.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+	u := fmt.Sprintf("sqlite://file:%s?cache=shared&_fk=1", filepath.Join(p, "test.db"))
+
+	// No events are sent in dry-run mode.
+	_, err = runCmd(migrateApplyCmd(), "--dir", "file://"+p, "--url", u, "--notify-url", srv.URL, "--dry-run")
+	require.NoError(t, err)
+	require.Empty(t, events)
+	_, err = runCmd(migrateApplyCmd(), "--dir", "file://"+p, "--url", u, "--notify-url", srv.URL)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, notify.ApplyStarted, events[0].Type)
+	require.Equal(t, []string{"1_t.sql"}, events[0].Files)
+	require.Equal(t, notify.ApplyFinished, events[1].Type)
+	require.Equal(t, 2, events[1].Applied)
+}
+
 func TestMigrate_ApplyBaseline(t *testing.T) {
 	t.Run("FromFlags", func(t *testing.T) {
 		p := t.TempDir()
@@ -1158,6 +1285,37 @@ env {
 	})
 }
 
+func TestMigrate_DiffIdentStyle(t *testing.T) {
+	p := t.TempDir()
+	_, err := runCmd(
+		migrateDiffCmd(),
+		"name",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", hclURL(t),
+		"--quote", "needed",
+	)
+	require.NoError(t, err)
+	files, err := os.ReadDir(p)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	buf, err := os.ReadFile(filepath.Join(p, files[0].Name()))
+	require.NoError(t, err)
+	// Reserved words are quoted.
+	require.Contains(t, string(buf), "CREATE TABLE `table` (col int NOT NULL")
+	require.NotContains(t, string(buf), "`col`")
+
+	_, err = runCmd(
+		migrateDiffCmd(),
+		"name",
+		"--dir", "file://"+t.TempDir(),
+		"--dev-url", openSQLite(t, ""),
+		"--to", hclURL(t),
+		"--quote", "sometimes",
+	)
+	require.EqualError(t, err, `unknown --quote value "sometimes". Expect one of: always, needed or never`)
+}
+
 func TestMigrate_Diff(t *testing.T) {
 	p := t.TempDir()
 	to := hclURL(t)
@@ -1547,6 +1705,35 @@ func TestMigrate_StatusJSON(t *testing.T) {
 	require.Equal(t, `"sqlite3"`, s)
 }
 
+func TestMigrate_StatusAborted(t *testing.T) {
+	ctx := context.Background()
+	u := fmt.Sprintf("sqlite://file:%s?_fk=1", filepath.Join(t.TempDir(), "test.db"))
+	_, err := runCmd(migrateApplyCmd(), "--dir", "file://testdata/sqlite2", "--url", u, "1")
+	require.NoError(t, err)
+	c, err := sqlclient.Open(ctx, u)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, c.Close())
+	})
+	rrw, err := migrate2.NewEntRevisions(ctx, c)
+	require.NoError(t, err)
+	// Aborted executions record the partial revision without an error.
+	require.NoError(t, rrw.WriteRevision(ctx, &migrate.Revision{Version: "20220318104615", Description: "second", Applied: 1, Total: 3}))
+	s, err := runCmd(migrateStatusCmd(), "--dir", "file://testdata/sqlite2", "--url", u)
+	require.NoError(t, err)
+	require.Contains(t, s, "Executed Files:  2 (last one partially)")
+	require.Contains(t, s, "Last migration attempt was interrupted, and resumes from the next statement.")
+	require.NotContains(t, s, "had errors")
+
+	// Failed executions are reported with their error.
+	require.NoError(t, rrw.WriteRevision(ctx, &migrate.Revision{Version: "20220318104615", Description: "second", Applied: 1, Total: 3, Error: "syntax error", ErrorStmt: "asdasd"}))
+	s, err = runCmd(migrateStatusCmd(), "--dir", "file://testdata/sqlite2", "--url", u)
+	require.NoError(t, err)
+	require.Contains(t, s, "Last migration attempt had errors:")
+	require.Contains(t, s, "syntax error")
+	require.NotContains(t, s, "interrupted")
+}
+
 func TestMigrate_Set(t *testing.T) {
 	u := fmt.Sprintf("sqlite://file:%s?_fk=1", filepath.Join(t.TempDir(), "test.db"))
 	_, err := runCmd(
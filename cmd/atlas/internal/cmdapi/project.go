@@ -5,17 +5,23 @@
 package cmdapi
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 
 	"ariga.io/atlas/cmd/atlas/internal/cloudapi"
 	"ariga.io/atlas/cmd/atlas/internal/cmdext"
 	cmdmigrate "ariga.io/atlas/cmd/atlas/internal/migrate"
 	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
@@ -42,10 +48,12 @@ func WithInput(values map[string]cty.Value) LoadOption {
 type (
 	// Project represents an atlas.hcl project config file.
 	Project struct {
-		Envs []*Env `spec:"env"`  // List of environments
-		Lint *Lint  `spec:"lint"` // Optional global lint policy
-		Diff *Diff  `spec:"diff"` // Optional global diff policy
-		cfg  *cmdext.AtlasConfig
+		Envs       []*Env       `spec:"env"`       // List of environments
+		Components []*Component `spec:"component"` // List of components
+		Lint       *Lint        `spec:"lint"`      // Optional global lint policy
+		Diff       *Diff        `spec:"diff"`      // Optional global diff policy
+		Protect    []*Protect   `spec:"protect"`   // Optional global protection rules
+		cfg        *cmdext.AtlasConfig
 	}
 
 	// Env represents an Atlas environment.
@@ -79,8 +87,46 @@ type (
 		// Format of the environment.
 		Format Format `spec:"format"`
 
+		// Protect holds the protection rules of the environment.
+		Protect []*Protect `spec:"protect"`
+
+		// Extends holds an optional reference to a base environment. Attributes
+		// that are not set on this environment are inherited from the base.
+		Extends *schemahcl.Ref `spec:"extends"`
+
+		schemahcl.DefaultExtension
+		cfg   *cmdext.AtlasConfig
+		scope *componentScope // set for envs that run on a component.
+	}
+
+	// Component represents a named migration directory and schema source of a project, e.g. the
+	// schema of a single service in a monorepo. Components may depend on other components, for
+	// example, in case the schema of one service references tables owned by another service.
+	//
+	//	component "users" {
+	//	  src = "file://services/users/schema.hcl"
+	//	  migration {
+	//	    dir              = "file://services/users/migrations"
+	//	    revisions_schema = "users_revisions"
+	//	  }
+	//	  depends_on = [component.auth]
+	//	}
+	Component struct {
+		// Name of the component.
+		Name string `spec:"name,name"`
+
+		// Migration configuration of the component. Attributes that are
+		// set override the migration configuration of the selected env.
+		Migration *Migration `spec:"migration"`
+
+		// List of schemas managed by the component. If set,
+		// it overrides the schemas of the selected env.
+		Schemas []string `spec:"schemas"`
+
+		// DependsOn holds references to the components this component depends on.
+		DependsOn []*schemahcl.Ref `spec:"depends_on"`
+
 		schemahcl.DefaultExtension
-		cfg *cmdext.AtlasConfig
 	}
 
 	// Migration represents the migration directory for the Env.
@@ -89,6 +135,8 @@ type (
 		Format          string `spec:"format"`
 		Baseline        string `spec:"baseline"`
 		LockTimeout     string `spec:"lock_timeout"`
+		LeaseTTL        string `spec:"lease_ttl"`
+		AuditURL        string `spec:"audit_url"`
 		RevisionsSchema string `spec:"revisions_schema"`
 	}
 
@@ -111,6 +159,18 @@ type (
 	Diff struct {
 		// SkipChanges configures the skip changes policy.
 		SkipChanges *SkipChanges `spec:"skip"`
+		// ExactTypes configures the diff to compare the exact
+		// spelling of column types, instead of their aliases.
+		ExactTypes bool `spec:"exact_types"`
+		// ColumnOrder configures the diff to plan changes to the
+		// order of columns, on databases that can move them.
+		ColumnOrder bool `spec:"column_order"`
+		// DetectRenames configures the diff to plan likely renames
+		// of tables and columns, instead of dropping and adding them.
+		DetectRenames bool `spec:"detect_renames"`
+		// SearchPath configures the schemas that unqualified references
+		// in the desired state are resolved from, by their order.
+		SearchPath []string `spec:"search_path"`
 		schemahcl.DefaultExtension
 	}
 
@@ -133,6 +193,27 @@ type (
 		ModifyForeignKey bool `spec:"modify_foreign_key"`
 	}
 
+	// Protect represents a protection rule for the targets an environment applies changes on.
+	// Rules defined at the top-level of the project file apply to all environments.
+	//
+	//	protect {
+	//	  urls             = ["postgres://*.prod.internal:*/*"]
+	//	  deny_destructive = true
+	//	  approve          = true
+	//	  windows          = ["Sat,Sun 02:00-06:00 UTC"]
+	//	}
+	Protect struct {
+		// URLs holds the patterns of the protected URLs. An empty list matches all URLs.
+		URLs []string `spec:"urls"`
+		// DenyDestructive denies applying destructive changes.
+		DenyDestructive bool `spec:"deny_destructive"`
+		// Approve requires an interactive approval before applying changes.
+		Approve bool `spec:"approve"`
+		// Windows holds the time windows applies are allowed in.
+		// See migrate.ParseTimeWindow for the format.
+		Windows []string `spec:"windows"`
+	}
+
 	// Format represents the output formatting configuration of an environment.
 	Format struct {
 		Migrate struct {
@@ -257,6 +338,18 @@ func (d *Diff) Extend(global *Diff) *Diff {
 	if d.SkipChanges == nil {
 		d.SkipChanges = global.SkipChanges
 	}
+	if !d.ExactTypes {
+		d.ExactTypes = global.ExactTypes
+	}
+	if !d.ColumnOrder {
+		d.ColumnOrder = global.ColumnOrder
+	}
+	if !d.DetectRenames {
+		d.DetectRenames = global.DetectRenames
+	}
+	if len(d.SearchPath) == 0 {
+		d.SearchPath = global.SearchPath
+	}
 	return d
 }
 
@@ -266,6 +359,18 @@ func (d *Diff) Options() (opts []schema.DiffOption) {
 	opts = append(opts, func(opts *schema.DiffOptions) {
 		opts.Extra = d.DefaultExtension
 	})
+	if d.ExactTypes {
+		opts = append(opts, schema.DiffExactTypes())
+	}
+	if d.ColumnOrder {
+		opts = append(opts, schema.DiffColumnOrder())
+	}
+	if d.DetectRenames {
+		opts = append(opts, schema.DiffResolver(&schema.RenameHeuristics{}))
+	}
+	if len(d.SearchPath) > 0 {
+		opts = append(opts, schema.DiffSearchPath(d.SearchPath...))
+	}
 	if d.SkipChanges == nil {
 		return
 	}
@@ -299,9 +404,52 @@ func (e *Env) DiffOptions() []schema.DiffOption {
 	return e.Diff.Options()
 }
 
+// SearchPath returns the search path configured in the diff policy of the
+// environment, or nil if no environment or diff policy were set.
+func (e *Env) SearchPath() []string {
+	if e == nil || e.Diff == nil {
+		return nil
+	}
+	return e.Diff.SearchPath
+}
+
+// ProtectionRules returns the protection rules configured for the environment.
+// The given function is used to approve applies on targets that require it.
+func (e *Env) ProtectionRules(approve func(context.Context, *migrate.ProtectedApply) error) ([]*migrate.ProtectionRule, error) {
+	if e == nil || len(e.Protect) == 0 {
+		return nil, nil
+	}
+	rules := make([]*migrate.ProtectionRule, 0, len(e.Protect))
+	for _, p := range e.Protect {
+		r := &migrate.ProtectionRule{URLs: p.URLs, DenyDestructive: p.DenyDestructive}
+		if p.Approve {
+			r.Approve = approve
+		}
+		for _, s := range p.Windows {
+			w, err := migrate.ParseTimeWindow(s)
+			if err != nil {
+				return nil, err
+			}
+			r.Windows = append(r.Windows, w)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
 // Sources returns the paths containing the Atlas schema.
 func (e *Env) Sources() ([]string, error) {
-	attr, exists := e.Attr("src")
+	return sources(&e.DefaultExtension)
+}
+
+// Sources returns the paths containing the Atlas schema of the component.
+func (c *Component) Sources() ([]string, error) {
+	return sources(&c.DefaultExtension)
+}
+
+// sources returns the paths set in the "src" attribute of the given block.
+func sources(ext *schemahcl.DefaultExtension) ([]string, error) {
+	attr, exists := ext.Attr("src")
 	if !exists {
 		return nil, nil
 	}
@@ -319,6 +467,610 @@ func (e *Env) Sources() ([]string, error) {
 	}
 }
 
+// Env returns a copy of the given environment, configured to run on the component. The
+// migration configuration, the schemas and the schema sources of the component override
+// the ones of the environment.
+func (c *Component) Env(e *Env) (*Env, error) {
+	ce := *e
+	if e.Migration != nil {
+		m := *e.Migration
+		ce.Migration = &m
+	} else {
+		ce.Migration = &Migration{}
+	}
+	if c.Migration != nil {
+		setNonZero(ce.Migration, c.Migration)
+	}
+	if len(c.Schemas) > 0 {
+		ce.Schemas = c.Schemas
+	}
+	src, ok := c.Attr("src")
+	if !ok {
+		return &ce, nil
+	}
+	if _, err := c.Sources(); err != nil {
+		return nil, fmt.Errorf("component %q: %w", c.Name, err)
+	}
+	attrs := make([]*schemahcl.Attr, 0, len(e.Extra.Attrs)+1)
+	for _, a := range e.Extra.Attrs {
+		if a.K != "src" {
+			attrs = append(attrs, a)
+		}
+	}
+	ce.Extra.Attrs = append(attrs, src)
+	return &ce, nil
+}
+
+// Inherit sets the attributes that are not set on the environment from the given base
+// environment. Migration and format blocks are merged attribute by attribute, and extra
+// attributes, such as input variables of the schema, are inherited unless overridden.
+// For example:
+//
+//	env "base" {
+//	  dev     = "docker://mysql/8/dev"
+//	  exclude = ["*.internal_*"]
+//	  migration {
+//	    dir = "file://migrations"
+//	  }
+//	}
+//
+//	env "prod" {
+//	  extends = env.base
+//	  url     = getenv("PROD_URL")
+//	  migration {
+//	    baseline = "20230101000000"
+//	  }
+//	}
+func (e *Env) Inherit(base *Env) {
+	if e.URL == "" {
+		e.URL = base.URL
+	}
+	if e.DevURL == "" {
+		e.DevURL = base.DevURL
+	}
+	if len(e.Schemas) == 0 {
+		e.Schemas = base.Schemas
+	}
+	if len(e.Exclude) == 0 {
+		e.Exclude = base.Exclude
+	}
+	switch {
+	case base.Migration == nil:
+	case e.Migration == nil:
+		m := *base.Migration
+		e.Migration = &m
+	default:
+		m := *base.Migration
+		setNonZero(&m, e.Migration)
+		e.Migration = &m
+	}
+	switch {
+	case base.Lint == nil:
+	case e.Lint == nil:
+		l := *base.Lint
+		e.Lint = &l
+	default:
+		if e.Lint.Format == "" {
+			e.Lint.Format = base.Lint.Format
+		}
+		if e.Lint.Latest == 0 && e.Lint.Git.Dir == "" && e.Lint.Git.Base == "" {
+			e.Lint.Latest, e.Lint.Git = base.Lint.Latest, base.Lint.Git
+		}
+		e.Lint.Extra = mergeResources(base.Lint.Extra, e.Lint.Extra)
+	}
+	if e.Diff == nil && base.Diff != nil {
+		d := *base.Diff
+		e.Diff = &d
+	}
+	// Protection rules are never overridden, only added.
+	e.Protect = append(append([]*Protect(nil), base.Protect...), e.Protect...)
+	migrate, schema := base.Format.Migrate, base.Format.Schema
+	setNonZero(&migrate, &e.Format.Migrate)
+	setNonZero(&schema, &e.Format.Schema)
+	e.Format.Migrate, e.Format.Schema = migrate, schema
+	e.Extra = mergeResources(base.Extra, e.Extra)
+}
+
+// inheritEnvs resolves the base environments of the project
+// environments, and sets the attributes they inherit from them.
+func (p *Project) inheritEnvs() error {
+	byName := make(map[string][]*Env, len(p.Envs))
+	for _, e := range p.Envs {
+		byName[e.Name] = append(byName[e.Name], e)
+	}
+	resolved := make(map[*Env]bool, len(p.Envs))
+	var inherit func(*Env, []string) error
+	inherit = func(e *Env, path []string) error {
+		if e.Extends == nil || resolved[e] {
+			return nil
+		}
+		for _, n := range path {
+			if n == e.Name {
+				return fmt.Errorf("envs inheritance cycle: %s -> %s", strings.Join(path, " -> "), e.Name)
+			}
+		}
+		name, err := e.Extends.ByType(blockEnv)
+		if err != nil {
+			return fmt.Errorf("env %q: %w", e.Name, err)
+		}
+		if len(name) != 1 {
+			return fmt.Errorf("env %q: unexpected base env reference %q", e.Name, e.Extends.V)
+		}
+		switch bs := byName[name[0]]; len(bs) {
+		case 0:
+			return fmt.Errorf("env %q extends undefined env %q", e.Name, name[0])
+		case 1:
+			if err := inherit(bs[0], append(path, e.Name)); err != nil {
+				return err
+			}
+			e.Inherit(bs[0])
+		default:
+			return fmt.Errorf("env %q extends env %q that is defined more than once", e.Name, name[0])
+		}
+		resolved[e] = true
+		return nil
+	}
+	for _, e := range p.Envs {
+		if err := inherit(e, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setNonZero sets the non-zero fields of the struct pointed by from on the struct pointed by to.
+func setNonZero(to, from any) {
+	tv, fv := reflect.ValueOf(to).Elem(), reflect.ValueOf(from).Elem()
+	for i := 0; i < fv.NumField(); i++ {
+		if f := fv.Field(i); !f.IsZero() {
+			tv.Field(i).Set(f)
+		}
+	}
+}
+
+// mergeResources returns a resource holding the attributes and children of the given base
+// resource, overridden by the attributes and children of the given resource with the same key.
+func mergeResources(base, r schemahcl.Resource) schemahcl.Resource {
+	merged := r
+	merged.Attrs = append([]*schemahcl.Attr(nil), r.Attrs...)
+	for _, a := range base.Attrs {
+		if _, ok := r.Attr(a.K); !ok {
+			merged.Attrs = append(merged.Attrs, a)
+		}
+	}
+	merged.Children = append([]*schemahcl.Resource(nil), r.Children...)
+	for _, c := range base.Children {
+		if !hasChild(&r, c) {
+			merged.Children = append(merged.Children, c)
+		}
+	}
+	return merged
+}
+
+// hasChild reports if the resource holds a child resource with the same type and name.
+func hasChild(r *schemahcl.Resource, c *schemahcl.Resource) bool {
+	for _, rc := range r.Children {
+		if rc.Type == c.Type && rc.Name == c.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// ComponentsOrder returns the components with the given names, and the components they depend on,
+// ordered such that every component comes after its dependencies. If no names were given, all the
+// components of the project are returned.
+func (p *Project) ComponentsOrder(names ...string) ([]*Component, error) {
+	byName := make(map[string]*Component, len(p.Components))
+	for _, c := range p.Components {
+		if c.Name == "" {
+			return nil, errors.New("all components must have names")
+		}
+		if _, ok := byName[c.Name]; ok {
+			return nil, fmt.Errorf("component %q is defined more than once", c.Name)
+		}
+		byName[c.Name] = c
+	}
+	if len(names) == 0 {
+		for _, c := range p.Components {
+			names = append(names, c.Name)
+		}
+	}
+	const (
+		visiting = iota + 1
+		visited
+	)
+	var (
+		order []*Component
+		state = make(map[string]int, len(byName))
+		visit func(string, []string) error
+	)
+	visit = func(name string, path []string) error {
+		c, ok := byName[name]
+		if !ok {
+			if len(path) > 0 {
+				return fmt.Errorf("component %q depends on undefined component %q", path[len(path)-1], name)
+			}
+			return fmt.Errorf("component %q not defined in project file", name)
+		}
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("components dependency cycle: %s -> %s", strings.Join(path, " -> "), name)
+		}
+		state[name] = visiting
+		for _, r := range c.DependsOn {
+			dep, err := r.ByType(blockComponent)
+			if err != nil {
+				return fmt.Errorf("component %q: %w", name, err)
+			}
+			if len(dep) != 1 {
+				return fmt.Errorf("component %q: unexpected dependency reference %q", name, r.V)
+			}
+			// Copy the path, as it is shared by the sibling dependencies.
+			if err := visit(dep[0], append(path[:len(path):len(path)], name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		order = append(order, c)
+		return nil
+	}
+	for _, n := range names {
+		if err := visit(n, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// componentsEnvs returns the environments of the given components (and their
+// dependencies), ordered by their dependencies, for each of the given envs.
+// The schema sources of a component env are preceded by the sources of its
+// dependencies, to resolve the references between them, and its diff is scoped
+// to its own objects (see componentScope).
+func (p *Project) componentsEnvs(envs []*Env, names ...string) ([]*Env, error) {
+	cs, err := p.ComponentsOrder(names...)
+	if err != nil {
+		return nil, err
+	}
+	all, err := p.ComponentsOrder()
+	if err != nil {
+		return nil, err
+	}
+	ces := make([]*Env, 0, len(envs)*len(cs))
+	for _, e := range envs {
+		defs := make([]*componentDef, 0, len(all))
+		for _, c := range all {
+			ce, err := c.Env(e)
+			if err != nil {
+				return nil, err
+			}
+			srcs, err := ce.Sources()
+			if err != nil {
+				return nil, err
+			}
+			order, err := p.ComponentsOrder(c.Name)
+			if err != nil {
+				return nil, err
+			}
+			d := &componentDef{name: c.Name, env: ce, srcs: srcs}
+			for _, dep := range order[:len(order)-1] {
+				d.deps = append(d.deps, dep.Name)
+			}
+			defs = append(defs, d)
+		}
+		for _, c := range cs {
+			scope := &componentScope{name: c.Name, defs: defs}
+			ce := *scope.def(c.Name).env
+			ce.scope = scope
+			ce.Schemas = scope.schemas(c.Name)
+			if srcs := scope.sources(c.Name); len(srcs) > 0 {
+				attrs := make([]*schemahcl.Attr, 0, len(ce.Extra.Attrs))
+				for _, a := range ce.Extra.Attrs {
+					if a.K != "src" {
+						attrs = append(attrs, a)
+					}
+				}
+				ce.Extra.Attrs = append(attrs, schemahcl.StringsAttr("src", srcs...))
+			}
+			ces = append(ces, &ce)
+		}
+	}
+	return ces, nil
+}
+
+type (
+	// componentScope scopes the diff of a component env to the objects that are defined
+	// by the component. An object is defined by the first component, in dependency order,
+	// whose schema (including the schemas of its dependencies) contains it. Changes to the
+	// objects of other components, e.g. the tables of a dependency that are referenced by
+	// the component, or the tables of a component that shares the same database schema,
+	// are not planned.
+	componentScope struct {
+		name string          // name of the component.
+		defs []*componentDef // all components of the project, in dependency order.
+	}
+
+	// componentDef describes a component of the project.
+	componentDef struct {
+		name string   // name of the component.
+		env  *Env     // env configured to run on the component.
+		srcs []string // schema sources of the component.
+		deps []string // names of its dependencies, in dependency order.
+	}
+)
+
+// def returns the definition of the component with the given name.
+func (s *componentScope) def(name string) *componentDef {
+	for _, d := range s.defs {
+		if d.name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// sources returns the schema sources of the given component, preceded by the sources of its dependencies.
+func (s *componentScope) sources(name string) []string {
+	var (
+		srcs []string
+		seen = make(map[string]bool)
+		d    = s.def(name)
+	)
+	for _, n := range append(d.deps, name) {
+		for _, src := range s.def(n).srcs {
+			if !seen[src] {
+				seen[src] = true
+				srcs = append(srcs, src)
+			}
+		}
+	}
+	return srcs
+}
+
+// schemas returns the schemas of the given component, followed by the schemas of its dependencies.
+// If the component does not limit its schemas, nil is returned.
+func (s *componentScope) schemas(name string) []string {
+	d := s.def(name)
+	if len(d.env.Schemas) == 0 {
+		return nil
+	}
+	schemas := slices.Clone(d.env.Schemas)
+	for _, n := range d.deps {
+		for _, sc := range s.def(n).env.Schemas {
+			if !slices.Contains(schemas, sc) {
+				schemas = append(schemas, sc)
+			}
+		}
+	}
+	return schemas
+}
+
+// depDirs returns the migration directories of the dependencies of the component.
+func (s *componentScope) depDirs() []string {
+	var (
+		dirs []string
+		own  = s.def(s.name).env.Migration.Dir
+		seen = map[string]bool{own: true}
+	)
+	for _, n := range s.def(s.name).deps {
+		if dir := s.def(n).env.Migration.Dir; dir != "" && !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// owners returns the component that defines each object of the project components. The states are
+// read using the given dev database (or the client, for HCL sources). In case qualified is false,
+// objects are identified only by their names, e.g. when the connection is bound to a single schema.
+func (s *componentScope) owners(ctx context.Context, client, dev *sqlclient.Client, qualified bool) (map[string]string, error) {
+	owners := make(map[string]string)
+	for _, d := range s.defs {
+		srcs := s.sources(d.name)
+		if len(srcs) == 0 {
+			continue
+		}
+		sr, err := stateReader(ctx, &stateReaderConfig{
+			urls:       srcs,
+			client:     client,
+			dev:        dev,
+			schemas:    s.schemas(d.name),
+			searchPath: d.env.SearchPath(),
+			vars:       GlobalFlags.Vars,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("component %q: %w", d.name, err)
+		}
+		r, err := sr.ReadState(ctx)
+		if cerr := sr.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("component %q: %w", d.name, err)
+		}
+		for _, k := range realmKeys(r, qualified) {
+			if _, ok := owners[k]; !ok {
+				owners[k] = d.name
+			}
+		}
+	}
+	return owners, nil
+}
+
+// scopeChanges returns the changes that do not modify objects defined by other components.
+func (s *componentScope) scopeChanges(changes []schema.Change, owners map[string]string, qualified bool) []schema.Change {
+	scoped := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		if !s.foreign(c, owners, qualified) {
+			scoped = append(scoped, c)
+		}
+	}
+	return scoped
+}
+
+// scopePlan removes the planned changes of objects that are defined by other components.
+func (s *componentScope) scopePlan(plan *migrate.Plan, owners map[string]string, qualified bool) {
+	scoped := make([]*migrate.Change, 0, len(plan.Changes))
+	for _, c := range plan.Changes {
+		if c.Source == nil || !s.foreign(c.Source, owners, qualified) {
+			scoped = append(scoped, c)
+		}
+	}
+	plan.Changes = scoped
+}
+
+// foreign reports if the given change modifies objects that are defined by other components.
+func (s *componentScope) foreign(c schema.Change, owners map[string]string, qualified bool) bool {
+	other := func(k string) bool {
+		o, ok := owners[k]
+		return ok && o != s.name
+	}
+	// Schemas that contain objects of other components are never dropped.
+	if d, ok := c.(*schema.DropSchema); ok {
+		for _, k := range schemaKeys(d.S, qualified) {
+			if other(k) {
+				return true
+			}
+		}
+		return false
+	}
+	keys := changeKeys(c, qualified)
+	for _, k := range keys {
+		if !other(k) {
+			return false
+		}
+	}
+	return len(keys) > 0
+}
+
+// objectKey returns the key identifies an object in the ownership map.
+func objectKey(kind string, s *schema.Schema, name string, qualified bool) string {
+	if qualified && s != nil {
+		return kind + " " + s.Name + "." + name
+	}
+	return kind + " " + name
+}
+
+// realmKeys returns the keys of the objects in the given realm.
+func realmKeys(r *schema.Realm, qualified bool) []string {
+	var keys []string
+	for _, s := range r.Schemas {
+		keys = append(keys, schemaKeys(s, qualified)...)
+	}
+	return keys
+}
+
+// schemaKeys returns the keys of the objects in the given schema.
+func schemaKeys(s *schema.Schema, qualified bool) []string {
+	var keys []string
+	for _, t := range s.Tables {
+		keys = append(keys, objectKey("table", s, t.Name, qualified))
+	}
+	for _, v := range s.Views {
+		keys = append(keys, objectKey("view", s, v.Name, qualified))
+	}
+	for _, f := range s.Funcs {
+		keys = append(keys, objectKey("func", s, f.Name, qualified))
+	}
+	for _, p := range s.Procs {
+		keys = append(keys, objectKey("proc", s, p.Name, qualified))
+	}
+	for _, o := range s.Objects {
+		if e, ok := o.(*schema.EnumType); ok {
+			keys = append(keys, objectKey("enum", s, e.T, qualified))
+		}
+	}
+	return keys
+}
+
+// changeKeys returns the keys of the objects modified by the given change.
+func changeKeys(c schema.Change, qualified bool) []string {
+	switch c := c.(type) {
+	case *schema.AddTable:
+		return []string{objectKey("table", c.T.Schema, c.T.Name, qualified)}
+	case *schema.DropTable:
+		return []string{objectKey("table", c.T.Schema, c.T.Name, qualified)}
+	case *schema.ModifyTable:
+		return []string{objectKey("table", c.T.Schema, c.T.Name, qualified)}
+	case *schema.RenameTable:
+		return []string{objectKey("table", c.From.Schema, c.From.Name, qualified), objectKey("table", c.To.Schema, c.To.Name, qualified)}
+	case *schema.AddView:
+		return []string{objectKey("view", c.V.Schema, c.V.Name, qualified)}
+	case *schema.DropView:
+		return []string{objectKey("view", c.V.Schema, c.V.Name, qualified)}
+	case *schema.ModifyView:
+		return []string{objectKey("view", c.To.Schema, c.To.Name, qualified)}
+	case *schema.RenameView:
+		return []string{objectKey("view", c.From.Schema, c.From.Name, qualified), objectKey("view", c.To.Schema, c.To.Name, qualified)}
+	case *schema.AddFunc:
+		return []string{objectKey("func", c.F.Schema, c.F.Name, qualified)}
+	case *schema.DropFunc:
+		return []string{objectKey("func", c.F.Schema, c.F.Name, qualified)}
+	case *schema.ModifyFunc:
+		return []string{objectKey("func", c.To.Schema, c.To.Name, qualified)}
+	case *schema.RenameFunc:
+		return []string{objectKey("func", c.From.Schema, c.From.Name, qualified), objectKey("func", c.To.Schema, c.To.Name, qualified)}
+	case *schema.AddProc:
+		return []string{objectKey("proc", c.P.Schema, c.P.Name, qualified)}
+	case *schema.DropProc:
+		return []string{objectKey("proc", c.P.Schema, c.P.Name, qualified)}
+	case *schema.ModifyProc:
+		return []string{objectKey("proc", c.To.Schema, c.To.Name, qualified)}
+	case *schema.RenameProc:
+		return []string{objectKey("proc", c.From.Schema, c.From.Name, qualified), objectKey("proc", c.To.Schema, c.To.Name, qualified)}
+	case *schema.AddObject:
+		return enumKeys(qualified, c.O)
+	case *schema.DropObject:
+		return enumKeys(qualified, c.O)
+	case *schema.ModifyObject:
+		return enumKeys(qualified, c.To)
+	case *schema.RenameObject:
+		return enumKeys(qualified, c.From, c.To)
+	}
+	return nil
+}
+
+// enumKeys returns the keys of the given objects, if they are enums.
+func enumKeys(qualified bool, objs ...schema.Object) []string {
+	var keys []string
+	for _, o := range objs {
+		if e, ok := o.(*schema.EnumType); ok {
+			keys = append(keys, objectKey("enum", e.Schema, e.T, qualified))
+		}
+	}
+	return keys
+}
+
+// componentDir is the migration directory of a component, in which the migration files
+// of its dependencies precede its own files. Replaying it on the dev database creates the
+// objects of the dependencies before the component's migrations that reference them. The
+// checksum, and the files written to the directory, are the ones of the component.
+type componentDir struct {
+	migrate.Dir
+	deps []migrate.Dir
+}
+
+// Files implements the migrate.Dir interface.
+func (d *componentDir) Files() ([]migrate.File, error) {
+	var files []migrate.File
+	for _, dep := range d.deps {
+		fs, err := migrate.FilesFromLastCheckpoint(dep)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, fs...)
+	}
+	own, err := d.Dir.Files()
+	if err != nil {
+		return nil, err
+	}
+	return append(files, own...), nil
+}
+
 // asMap returns the extra attributes stored in the Env as a map[string]string.
 func (e *Env) asMap() (map[string]string, error) {
 	m := make(map[string]string, len(e.Extra.Attrs))
@@ -367,6 +1119,9 @@ func EnvByName(cmd *cobra.Command, name string, opts ...LoadOption) (*Project, [
 	if err := project.Lint.remainedLog(); err != nil {
 		return nil, nil, err
 	}
+	if err := project.inheritEnvs(); err != nil {
+		return nil, nil, err
+	}
 	envs := make(map[string][]*Env)
 	for _, e := range project.Envs {
 		if e.Name == "" {
@@ -383,6 +1138,7 @@ func EnvByName(cmd *cobra.Command, name string, opts ...LoadOption) (*Project, [
 		}
 		e.Diff = e.Diff.Extend(project.Diff)
 		e.Lint = e.Lint.Extend(project.Lint)
+		e.Protect = append(e.Protect, project.Protect...)
 		if err := e.Lint.remainedLog(); err != nil {
 			return nil, nil, err
 		}
@@ -395,6 +1151,12 @@ func EnvByName(cmd *cobra.Command, name string, opts ...LoadOption) (*Project, [
 		return project, nil, nil
 	case len(envs[name]) == 0:
 		return nil, nil, fmt.Errorf("env %q not defined in project file", name)
+	case len(GlobalFlags.Components) > 0:
+		ces, err := project.componentsEnvs(envs[name], GlobalFlags.Components...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return project, ces, nil
 	default:
 		return project, envs[name], nil
 	}
@@ -402,6 +1164,8 @@ func EnvByName(cmd *cobra.Command, name string, opts ...LoadOption) (*Project, [
 
 const (
 	blockEnv          = "env"
+	blockComponent    = "component"
+	attrExtends       = "extends"
 	refAtlas          = "atlas"
 	defaultConfigPath = "file://atlas.hcl"
 )
@@ -462,6 +1226,7 @@ func parseConfig(path, env string, opts ...LoadOption) (*Project, error) {
 func init() {
 	cloudapi.SetVersion(version, flavor)
 	schemahcl.Register(blockEnv, &Env{})
+	schemahcl.Register(blockComponent, &Component{})
 }
 
 func partialParse(path, env string) (*hclparse.Parser, error) {
@@ -470,10 +1235,17 @@ func partialParse(path, env string) (*hclparse.Parser, error) {
 	if err != nil {
 		return nil, err
 	}
-	var used []*hclsyntax.Block
+	var (
+		used  []*hclsyntax.Block
+		named = make(map[string]*hclsyntax.Block)
+		bases = make(map[string]bool)
+	)
 	for _, b := range fi.Body.(*hclsyntax.Body).Blocks {
 		switch b.Type {
 		case blockEnv:
+			if len(b.Labels) == 1 {
+				named[b.Labels[0]] = b
+			}
 			switch n := len(b.Labels); {
 			// No env was selected.
 			case env == "" && n == 0:
@@ -491,6 +1263,38 @@ func partialParse(path, env string) (*hclparse.Parser, error) {
 			used = append(used, b)
 		}
 	}
+	// Keep the envs that the selected envs extend, directly or transitively.
+	for i := 0; i < len(used); i++ {
+		b := used[i]
+		if b.Type != blockEnv || b.Body == nil || b.Body.Attributes[attrExtends] == nil {
+			continue
+		}
+		t, ok := b.Body.Attributes[attrExtends].Expr.(*hclsyntax.ScopeTraversalExpr)
+		if !ok || len(t.Traversal) != 2 || t.Traversal.RootName() != blockEnv {
+			continue
+		}
+		a, ok := t.Traversal[1].(hcl.TraverseAttr)
+		if !ok || bases[a.Name] || env != "" && a.Name == env {
+			continue
+		}
+		if base, ok := named[a.Name]; ok {
+			bases[a.Name] = true
+			used = append(used, base)
+		}
+	}
+	if len(bases) > 0 {
+		// Preserve the order of the blocks in the file.
+		kept := make(map[*hclsyntax.Block]bool, len(used))
+		for _, b := range used {
+			kept[b] = true
+		}
+		used = used[:0]
+		for _, b := range fi.Body.(*hclsyntax.Body).Blocks {
+			if kept[b] {
+				used = append(used, b)
+			}
+		}
+	}
 	fi.Body = &hclsyntax.Body{
 		Blocks:     used,
 		Attributes: fi.Body.(*hclsyntax.Body).Attributes,
@@ -880,6 +880,25 @@ func TestSchema_InspectLog(t *testing.T) {
 	require.Equal(t, `{"schemas":[{"name":"main","tables":[{"name":"t1","columns":[{"name":"id","type":"INTEGER","null":true}],"primary_key":{"parts":[{"column":"id"}]}},{"name":"t2","columns":[{"name":"name","type":"TEXT","null":true}]}]}]}`, s)
 }
 
+func TestSchema_InspectAnonymize(t *testing.T) {
+	db := openSQLite(t, "create table t1 (id integer primary key, ssn text default 'secret-default', CHECK (ssn <> '123-45-6789'));")
+	s, err := runCmd(schemaInspectCmd(), "-u", db, "--anonymize", "strip", "--anonymize-checks", "ssn")
+	require.NoError(t, err)
+	require.NotContains(t, s, "secret-default")
+	require.NotContains(t, s, "123-45-6789")
+
+	s, err = runCmd(schemaInspectCmd(), "-u", db, "--anonymize", "hash", "--anonymize-checks", "ssn")
+	require.NoError(t, err)
+	require.NotContains(t, s, "secret-default")
+	require.NotContains(t, s, "123-45-6789")
+	require.Contains(t, s, "anon_")
+
+	_, err = runCmd(schemaInspectCmd(), "-u", db, "--anonymize", "unknown")
+	require.EqualError(t, err, `unknown anonymize mode "unknown", expect 'strip' or 'hash'`)
+	_, err = runCmd(schemaInspectCmd(), "-u", db, "--anonymize-checks", "ssn")
+	require.EqualError(t, err, "--anonymize-checks requires the --anonymize flag")
+}
+
 func TestSchema_InspectFile(t *testing.T) {
 	var (
 		p   = t.TempDir()
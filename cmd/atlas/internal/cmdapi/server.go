@@ -0,0 +1,100 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/cmd/atlas/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+// envServerTokens holds the comma-separated list of the bearer tokens accepted by the server.
+// Tokens are not accepted as flags, as they would be exposed in the process list.
+const envServerTokens = "ATLAS_SERVER_TOKENS"
+
+type serverFlags struct {
+	addr            string
+	allowURLs       []string
+	tlsCert, tlsKey string
+}
+
+// serverCmd represents the 'atlas server' command.
+func serverCmd() *cobra.Command {
+	var (
+		flags serverFlags
+		cmd   = &cobra.Command{
+			Use:   "server [flags]",
+			Short: "Run Atlas as an HTTP server.",
+			Long: `'atlas server' exposes the inspection, diffing, linting and apply operations of Atlas as HTTP endpoints.
+Requests are authenticated using the bearer tokens set in the ` + envServerTokens + ` environment variable, and may
+access only the database URLs that match one of the --allow-url patterns.`,
+			Example: `  ATLAS_SERVER_TOKENS=secret atlas server --allow-url "postgres://*.staging.internal:5432/*"
+  ATLAS_SERVER_TOKENS=secret atlas server --addr :8443 --tls-cert server.crt --tls-key server.key --allow-url "mysql://db:3306/*"`,
+			Args: cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				return serverRun(cmd, flags)
+			},
+		}
+	)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVar(&flags.addr, flagAddr, ":8080", "the address to listen on")
+	cmd.Flags().StringSliceVar(&flags.allowURLs, flagAllowURL, nil, "patterns of the database URLs the server may connect to")
+	cmd.Flags().StringVar(&flags.tlsCert, flagTLSCert, "", "path to the TLS certificate file of the server")
+	cmd.Flags().StringVar(&flags.tlsKey, flagTLSKey, "", "path to the TLS key file of the server")
+	cmd.MarkFlagsRequiredTogether(flagTLSCert, flagTLSKey)
+	cobra.CheckErr(cmd.MarkFlagRequired(flagAllowURL))
+	return cmd
+}
+
+func serverRun(cmd *cobra.Command, flags serverFlags) error {
+	var tokens []string
+	for _, t := range strings.Split(os.Getenv(envServerTokens), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("missing bearer tokens. Set them using the %s environment variable", envServerTokens)
+	}
+	srv, err := server.New(server.BearerTokens(tokens...), server.WithAllowedURLs(flags.allowURLs...))
+	if err != nil {
+		return err
+	}
+	// Prevent usage printing after input validation.
+	cmd.SilenceUsage = true
+	var (
+		ctx  = cmd.Context()
+		errC = make(chan error, 1)
+		hs   = &http.Server{Addr: flags.addr, Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+	)
+	go func() {
+		if flags.tlsCert != "" {
+			errC <- hs.ListenAndServeTLS(flags.tlsCert, flags.tlsKey)
+		} else {
+			errC <- hs.ListenAndServe()
+		}
+	}()
+	cmd.Printf("Listening on %s\n", flags.addr)
+	select {
+	case err := <-errC:
+		return err
+	case <-ctx.Done():
+		// Give in-flight applies a chance to complete.
+		sctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := hs.Shutdown(sctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
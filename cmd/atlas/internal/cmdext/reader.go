@@ -14,7 +14,9 @@ import (
 	"path/filepath"
 
 	cmdmigrate "ariga.io/atlas/cmd/atlas/internal/migrate"
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/postgres"
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlclient"
 	"github.com/hashicorp/hcl/v2"
@@ -37,6 +39,7 @@ type (
 		Client, Dev *sqlclient.Client // database connections, while dev is considered a dev database, client is not
 		Schemas     []string          // schemas to work on
 		Exclude     []string          // exclude flag values
+		SearchPath  []string          // schemas that unqualified references are resolved from
 		Vars        map[string]cty.Value
 	}
 )
@@ -134,8 +137,17 @@ func stateReaderHCL(ctx context.Context, config *StateReaderConfig, paths []stri
 	if err != nil {
 		return nil, err
 	}
-	realm := &schema.Realm{}
-	if err := client.Eval(parser, realm, config.Vars); err != nil {
+	var (
+		realm = &schema.Realm{}
+		eval  = client.Evaluator
+	)
+	if len(config.SearchPath) > 0 {
+		if client.Name != postgres.DriverName {
+			return nil, fmt.Errorf("search_path is not supported by driver %q", client.Name)
+		}
+		eval = postgres.EvalHCLSearchPath(config.SearchPath...)
+	}
+	if err := eval.Eval(parser, realm, config.Vars); err != nil {
 		return nil, err
 	}
 	if len(config.Schemas) > 0 {
@@ -167,6 +179,16 @@ func stateReaderHCL(ctx context.Context, config *StateReaderConfig, paths []stri
 		// objects created by the migrations, which are not yet supported by Atlas, such as functions,
 		// won't be cleaned and can be referenced by the HCL schema.
 		StateReader: migrate.StateReaderFunc(func(ctx context.Context) (*schema.Realm, error) {
+			// Infer the columns of views that were defined without them, before normalization,
+			// as the dev database is cleaned after each of them. Views are created as defined
+			// in the document, and therefore, a schema-bound dev database must match its schema.
+			if vi, ok := client.Driver.(schema.ViewInferrer); ok && !normalized && config.Dev != nil &&
+				(config.Dev.URL.Schema == "" || len(realm.Schemas) == 1 && realm.Schemas[0].Name == config.Dev.URL.Schema) {
+				sqlparse.LinkViewDeps(client.Name, realm)
+				if err := vi.InferViewColumns(ctx, realm); err != nil {
+					return nil, err
+				}
+			}
 			// Normalize once, only on dev database connection.
 			if nr, ok := client.Driver.(schema.Normalizer); ok && !normalized && config.Dev != nil {
 				switch {
@@ -181,6 +203,9 @@ func stateReaderHCL(ctx context.Context, config *StateReaderConfig, paths []stri
 					return nil, err
 				}
 			}
+			// Views without explicit dependencies (depends_on)
+			// are linked to the objects used in their definition.
+			sqlparse.LinkViewDeps(client.Name, realm)
 			return realm, nil
 		}),
 	}, nil
@@ -14,7 +14,12 @@ import (
 	"text/template"
 	"time"
 
+	"ariga.io/atlas/sql/cdc"
+	"ariga.io/atlas/sql/erd"
+	"ariga.io/atlas/sql/gogen"
+	"ariga.io/atlas/sql/inventory"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/openapi"
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlclient"
 
@@ -106,11 +111,16 @@ var (
   {{ yellow "--" }} Next Version:    {{ cyan .Next }}{{ if .FromCheckpoint }} (checkpoint){{ end }}
 {{- if gt .Total 0 }}{{ printf " (%s statements left)" (yellow "%d" .Left) }}{{ end }}
   {{ yellow "--" }} Executed Files:  {{ len .Applied }}{{ if gt .Total 0 }} (last one partially){{ end }}
-  {{ yellow "--" }} Pending Files:   {{ len .Pending }}
-{{ if gt .Total 0 }}
+  {{ yellow "--" }} Pending Files:   {{ len .Pending }}{{ with .Estimated }} (estimated {{ yellow "%s" . }}){{ end }}
+{{- range .Estimates }}
+     {{ cyan .Version }}: {{ .Duration }}{{ with .Rows }} ({{ . }} rows){{ end }}{{ with .Unknown }} ({{ . }} on tables without statistics){{ end }}
+{{- end }}
+{{ if and (gt .Total 0) .Error }}
 Last migration attempt had errors:
   {{ yellow "--" }} SQL:   {{ .SQL }}
   {{ yellow "--" }} {{ red "ERROR:" }} {{ .Error }}
+{{ else if gt .Total 0 }}
+Last migration attempt was interrupted, and resumes from the next statement.
 {{ end }}`)
 			if err != nil {
 				return "", err
@@ -138,6 +148,19 @@ type MigrateStatus struct {
 	Status          string              `json:"Status,omitempty"`    // Status of migration (OK, PENDING)
 	Error           string              `json:"Error,omitempty"`     // Last Error that occurred
 	SQL             string              `json:"SQL,omitempty"`       // SQL that caused the last Error
+	Estimated       time.Duration       `json:"Estimated,omitempty"` // Estimated execution time of the pending files
+	Estimates       []*FileEstimate     `json:"Estimates,omitempty"` // Estimates of the pending files
+}
+
+// FileEstimate describes the estimated execution time of a pending migration file.
+type FileEstimate struct {
+	Version  string        `json:"Version"`
+	Duration time.Duration `json:"Duration"`
+	// Rows is the estimated number of rows that are scanned or rewritten by the file
+	// statements, and Unknown is the number of statements that scan tables without
+	// statistics, and are estimated only by their base cost.
+	Rows    int64 `json:"Rows,omitempty"`
+	Unknown int   `json:"Unknown,omitempty"`
 }
 
 // Left returns the amount of statements left to apply (if any).
@@ -301,13 +324,16 @@ No migration files to execute
 {{- else -}}
 Migrating to version {{ cyan .Target }}{{ with .Current }} from {{ cyan . }}{{ end }} ({{ len .Pending }} migrations in total):
 {{ range $i, $f := .Applied }}
-  {{ yellow "--" }} migrating version {{ cyan $f.File.Version }}{{ range $f.Applied }}
-    {{ cyan "->" }} {{ . }}{{ end }}
+  {{ yellow "--" }} migrating version {{ cyan $f.File.Version }}{{ range $j, $s := $f.Applied }}
+    {{ cyan "->" }} {{ $s }}{{ with $f.BackfillOf $j }}
+       {{ yellow "--" }} backfilled {{ .Rows }} rows in {{ .Batches }} batches{{ end }}{{ end }}
   {{- with .Error }}
     {{ redBgWhiteFg .Text }}
+  {{- else }}{{ if .Aborted }}
+  {{ yellow "--" }} aborted ({{ yellow (.End.Sub .Start).String }})
   {{- else }}
   {{ yellow "--" }} ok ({{ yellow (.End.Sub .Start).String }})
-  {{- end }}
+  {{- end }}{{ end }}
 {{ end }}
   {{ cyan "-------------------------" }}
   {{ yellow "--" }} {{ .End.Sub .Start }}
@@ -317,7 +343,7 @@ Migrating to version {{ cyan .Target }}{{ with .Current }} from {{ cyan . }}{{ e
   {{ yellow "--" }} {{ dec $files }} migrations ok (1 with errors)
   {{ yellow "--" }} {{ dec $stmts }} sql statements ok (1 with errors)
 {{- else }}
-  {{ yellow "--" }} {{ len .Applied }} migrations 
+  {{ yellow "--" }} {{ len .Applied }} migrations {{ if .Aborted }}(aborted){{ end }}
   {{ yellow "--" }} {{ .CountStmts  }} sql statements
 {{- end }}
 {{- end }}
@@ -338,6 +364,9 @@ type (
 		// Error is set even then, if it was not caused by a statement in a migration file,
 		// but by Atlas, e.g. when committing or rolling back a transaction.
 		Error string `json:"Error,omitempty"`
+		// Aborted is set if the execution was stopped gracefully (e.g. on interrupt),
+		// and it is not reported as an error.
+		Aborted bool `json:"Aborted,omitempty"`
 	}
 
 	// AppliedFile is part of an MigrateApply containing information about an applied file in a migration attempt.
@@ -348,6 +377,16 @@ type (
 		Skipped int      // Amount of skipped SQL statements in a partially applied file.
 		Applied []string // SQL statements applied with success
 		Error   *StmtError
+		Aborted bool // The execution was aborted before the file was fully applied.
+		// Backfills holds the progress of statements executed in batches.
+		Backfills []*Backfill
+	}
+
+	// Backfill describes the progress of a statement executed in batches.
+	Backfill struct {
+		Stmt    int   `json:"Stmt"`    // Index of the statement in the applied statements.
+		Batches int   `json:"Batches"` // Number of executed batches.
+		Rows    int64 `json:"Rows"`    // Number of rows affected by all batches.
 	}
 )
 
@@ -385,6 +424,16 @@ func (a *MigrateApply) Log(e migrate.LogEntry) {
 	case migrate.LogStmt:
 		f := a.Applied[len(a.Applied)-1]
 		f.Applied = append(f.Applied, e.SQL)
+	case migrate.LogBackfill:
+		if l := len(a.Applied); l > 0 {
+			f := a.Applied[l-1]
+			b := f.BackfillOf(len(f.Applied) - 1)
+			if b == nil {
+				b = &Backfill{Stmt: len(f.Applied) - 1}
+				f.Backfills = append(f.Backfills, b)
+			}
+			b.Batches, b.Rows = e.Batch, e.Total
+		}
 	case migrate.LogError:
 		if l := len(a.Applied); l > 0 {
 			f := a.Applied[len(a.Applied)-1]
@@ -395,6 +444,14 @@ func (a *MigrateApply) Log(e migrate.LogEntry) {
 				Text: e.Error.Error(),
 			}
 		}
+	case migrate.LogAbort:
+		a.Aborted = true
+		if l := len(a.Applied); l > 0 {
+			f := a.Applied[l-1]
+			f.End = time.Now()
+			f.Aborted = true
+			a.End = f.End
+		}
 	case migrate.LogDone:
 		n := time.Now()
 		if l := len(a.Applied); l > 0 {
@@ -430,17 +487,28 @@ func (a *MigrateApply) MarshalJSON() ([]byte, error) {
 	return json.Marshal(v)
 }
 
+// BackfillOf returns the backfill progress of the i-th applied statement, if it was executed in batches.
+func (f *AppliedFile) BackfillOf(i int) *Backfill {
+	for _, b := range f.Backfills {
+		if b.Stmt == i {
+			return b
+		}
+	}
+	return nil
+}
+
 // MarshalJSON implements json.Marshaler.
 func (f *AppliedFile) MarshalJSON() ([]byte, error) {
 	type local struct {
-		Name        string     `json:"Name,omitempty"`
-		Version     string     `json:"Version,omitempty"`
-		Description string     `json:"Description,omitempty"`
-		Start       time.Time  `json:"Start,omitempty"`
-		End         time.Time  `json:"End,omitempty"`
-		Skipped     int        `json:"Skipped,omitempty"`
-		Stmts       []string   `json:"Applied,omitempty"`
-		Error       *StmtError `json:"Error,omitempty"`
+		Name        string      `json:"Name,omitempty"`
+		Version     string      `json:"Version,omitempty"`
+		Description string      `json:"Description,omitempty"`
+		Start       time.Time   `json:"Start,omitempty"`
+		End         time.Time   `json:"End,omitempty"`
+		Skipped     int         `json:"Skipped,omitempty"`
+		Stmts       []string    `json:"Applied,omitempty"`
+		Error       *StmtError  `json:"Error,omitempty"`
+		Backfills   []*Backfill `json:"Backfills,omitempty"`
 	}
 	return json.Marshal(local{
 		Name:        f.Name(),
@@ -451,6 +519,7 @@ func (f *AppliedFile) MarshalJSON() ([]byte, error) {
 		Skipped:     f.Skipped,
 		Stmts:       f.Applied,
 		Error:       f.Error,
+		Backfills:   f.Backfills,
 	})
 }
 
@@ -534,8 +603,17 @@ type SchemaInspect struct {
 var (
 	// InspectTemplateFuncs are global functions available in inspect report templates.
 	InspectTemplateFuncs = template.FuncMap{
-		"sql":  sqlInspect,
-		"json": jsonEncode,
+		"sql":        sqlInspect,
+		"json":       jsonEncode,
+		"dot":        dotInspect,
+		"svg":        svgInspect,
+		"csv":        csvInspect,
+		"openapi":    openapiInspect,
+		"avro":       avroInspect,
+		"jsonschema": jsonschemaInspect,
+		"go":         goInspect,
+		// Parquet files are binary, and should be redirected to a file.
+		"parquet": parquetInspect,
 	}
 
 	// SchemaInspectTemplate holds the default template of the 'schema inspect' command.
@@ -709,6 +787,109 @@ func sqlInspect(report *SchemaInspect, indent ...string) (string, error) {
 	return fmtPlan(report.Context, report.Client, changes, indent)
 }
 
+// dotInspect returns the entity-relationship diagram of the inspected realm as a graphviz document.
+func dotInspect(report *SchemaInspect) (string, error) {
+	var b bytes.Buffer
+	if err := erd.Dot(&b, report.Realm); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// csvInspect returns the column inventory of the inspected realm as a CSV document.
+func csvInspect(report *SchemaInspect) (string, error) {
+	var b bytes.Buffer
+	if err := inventory.WriteCSV(&b, report.Realm); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// parquetInspect returns the column inventory of the inspected realm as a Parquet file.
+func parquetInspect(report *SchemaInspect) (string, error) {
+	var b bytes.Buffer
+	if err := inventory.WriteParquet(&b, report.Realm); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// openapiInspect returns the OpenAPI component schemas of the inspected tables,
+// as a JSON document that can be merged into an OpenAPI 3.1 specification.
+func openapiInspect(report *SchemaInspect) (string, error) {
+	c, err := openapi.Components(report.Realm)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(struct {
+		Components *openapi.ComponentsObject `json:"components"`
+	}{c}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// avroInspect returns the Avro schemas of the CDC events of the inspected tables, keyed by their
+// schema registry subjects. The topic prefix is the name of the captured server in Debezium.
+func avroInspect(report *SchemaInspect, prefix string) (string, error) {
+	return cdcSubjects(report, prefix, (*cdc.Table).AvroSchema)
+}
+
+// jsonschemaInspect returns the JSON Schemas of the CDC events of the
+// inspected tables, keyed by their schema registry subjects.
+func jsonschemaInspect(report *SchemaInspect, prefix string) (string, error) {
+	return cdcSubjects(report, prefix, (*cdc.Table).JSONSchema)
+}
+
+func cdcSubjects(report *SchemaInspect, prefix string, gen func(*cdc.Table, cdc.Kind) ([]byte, error)) (string, error) {
+	var (
+		opts     []cdc.Option
+		subjects = make(map[string]json.RawMessage)
+	)
+	if report.Client != nil {
+		opts = append(opts, cdc.WithDialect(report.Client.Name))
+	}
+	for _, t := range cdc.Tables(report.Realm, prefix, opts...) {
+		// Tables without primary keys produce events without keys.
+		if len(t.Keys) > 0 {
+			b, err := gen(t, cdc.Key)
+			if err != nil {
+				return "", err
+			}
+			subjects[t.Subject(cdc.Key)] = b
+		}
+		b, err := gen(t, cdc.Envelope)
+		if err != nil {
+			return "", err
+		}
+		subjects[t.Subject(cdc.Envelope)] = b
+	}
+	b, err := json.MarshalIndent(subjects, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// goInspect returns the Go structs of the inspected tables, in a file of the given package.
+func goInspect(report *SchemaInspect, pkg string) (string, error) {
+	src, err := gogen.Generate(report.Realm, gogen.WithPackage(pkg))
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
+
+// svgInspect returns the entity-relationship diagram of the inspected realm as an SVG image.
+func svgInspect(report *SchemaInspect) (string, error) {
+	var b bytes.Buffer
+	if err := erd.SVG(&b, report.Realm); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
 // SchemaDiff contains a summary of the 'schema diff' command.
 type SchemaDiff struct {
 	context.Context   `json:"-"`
@@ -720,7 +901,8 @@ type SchemaDiff struct {
 var (
 	// SchemaDiffFuncs are global functions available in diff report templates.
 	SchemaDiffFuncs = template.FuncMap{
-		"sql": sqlDiff,
+		"sql":        sqlDiff,
+		"htmlreport": htmlDiff,
 	}
 	// SchemaDiffTemplate holds the default template of the 'schema diff' command.
 	SchemaDiffTemplate = template.Must(template.
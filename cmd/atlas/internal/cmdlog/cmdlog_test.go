@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 	"text/template"
 
@@ -152,6 +153,127 @@ func TestSchemaInspect_EncodeSQL(t *testing.T) {
 	require.Equal(t, "-- Create \"users\" table\nCREATE TABLE `users` (`id` int NOT NULL, `name` text NOT NULL);\n", b.String())
 }
 
+func TestSchemaInspect_ERD(t *testing.T) {
+	var (
+		id     = schema.NewIntColumn("id", "int")
+		uid    = schema.NewIntColumn("user_id", "int")
+		users  = schema.NewTable("users").AddColumns(id).SetPrimaryKey(schema.NewPrimaryKey(id))
+		posts  = schema.NewTable("posts").AddColumns(uid)
+		report = &cmdlog.SchemaInspect{Realm: schema.NewRealm(schema.New("main").AddTables(users, posts))}
+	)
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(uid).SetRefTable(users).AddRefColumns(id))
+
+	var b bytes.Buffer
+	tmpl := template.Must(template.New("format").Funcs(cmdlog.InspectTemplateFuncs).Parse(`{{ dot . }}`))
+	require.NoError(t, tmpl.Execute(&b, report))
+	require.Contains(t, b.String(), `"main.posts":"user_id" -> "main.users":"id";`)
+
+	b.Reset()
+	tmpl = template.Must(template.New("format").Funcs(cmdlog.InspectTemplateFuncs).Parse(`{{ svg . }}`))
+	require.NoError(t, tmpl.Execute(&b, report))
+	require.True(t, strings.HasPrefix(b.String(), `<svg xmlns="http://www.w3.org/2000/svg"`))
+	require.Contains(t, b.String(), `<title>main.posts.user_id -&gt; main.users.id</title>`)
+}
+
+func TestSchemaInspect_Inventory(t *testing.T) {
+	report := &cmdlog.SchemaInspect{
+		Realm: schema.NewRealm(
+			schema.New("main").AddTables(
+				schema.NewTable("users").AddColumns(
+					schema.NewIntColumn("id", "int"),
+					schema.NewNullStringColumn("name", "text").SetComment("display name"),
+				),
+			),
+		),
+	}
+	var b bytes.Buffer
+	tmpl := template.Must(template.New("format").Funcs(cmdlog.InspectTemplateFuncs).Parse(`{{ csv . }}`))
+	require.NoError(t, tmpl.Execute(&b, report))
+	require.Equal(t, "schema,table,column,position,type,nullable,comment\nmain,users,id,1,int,false,\nmain,users,name,2,text,true,display name\n", b.String())
+
+	b.Reset()
+	tmpl = template.Must(template.New("format").Funcs(cmdlog.InspectTemplateFuncs).Parse(`{{ parquet . }}`))
+	require.NoError(t, tmpl.Execute(&b, report))
+	require.True(t, strings.HasPrefix(b.String(), "PAR1"))
+	require.True(t, strings.HasSuffix(b.String(), "PAR1"))
+}
+
+func TestSchemaInspect_CDC(t *testing.T) {
+	id := schema.NewIntColumn("id", "bigint")
+	report := &cmdlog.SchemaInspect{
+		Realm: schema.NewRealm(
+			schema.New("public").AddTables(
+				schema.NewTable("users").AddColumns(id).SetPrimaryKey(schema.NewPrimaryKey(id)),
+				schema.NewTable("logs").AddColumns(schema.NewStringColumn("entry", "text")),
+			),
+		),
+	}
+	var b bytes.Buffer
+	tmpl := template.Must(template.New("format").Funcs(cmdlog.InspectTemplateFuncs).Parse(`{{ avro . "inventory" }}`))
+	require.NoError(t, tmpl.Execute(&b, report))
+	var subjects map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(b.Bytes(), &subjects))
+	require.Len(t, subjects, 3, "tables without primary keys have no key subjects")
+	require.JSONEq(t, `{"type":"record","name":"Key","namespace":"inventory.public.users","fields":[{"name":"id","type":"long"}],"connect.name":"inventory.public.users.Key"}`, string(subjects["inventory.public.users-key"]))
+	require.Contains(t, string(subjects["inventory.public.logs-value"]), `"name": "Envelope"`)
+
+	b.Reset()
+	tmpl = template.Must(template.New("format").Funcs(cmdlog.InspectTemplateFuncs).Parse(`{{ jsonschema . "inventory" }}`))
+	require.NoError(t, tmpl.Execute(&b, report))
+	require.NoError(t, json.Unmarshal(b.Bytes(), &subjects))
+	require.Len(t, subjects, 3)
+	require.Contains(t, string(subjects["inventory.public.users-value"]), `"title": "inventory.public.users.Envelope"`)
+}
+
+func TestSchemaInspect_Go(t *testing.T) {
+	report := &cmdlog.SchemaInspect{
+		Realm: schema.NewRealm(
+			schema.New("main").AddTables(
+				schema.NewTable("users").AddColumns(
+					schema.NewIntColumn("id", "int"),
+					schema.NewNullStringColumn("name", "text"),
+				),
+			),
+		),
+	}
+	var b bytes.Buffer
+	tmpl := template.Must(template.New("format").Funcs(cmdlog.InspectTemplateFuncs).Parse(`{{ go . "models" }}`))
+	require.NoError(t, tmpl.Execute(&b, report))
+	require.Contains(t, b.String(), "package models\n")
+	require.Contains(t, b.String(), "type Users struct {\n\tID   int32          `db:\"id\" json:\"id\"`\n\tName sql.NullString `db:\"name\" json:\"name\"`\n}\n")
+}
+
+func TestSchemaInspect_OpenAPI(t *testing.T) {
+	report := &cmdlog.SchemaInspect{
+		Realm: schema.NewRealm(
+			schema.New("main").AddTables(
+				schema.NewTable("users").AddColumns(
+					schema.NewIntColumn("id", "int"),
+					schema.NewNullStringColumn("name", "text").SetComment("display name"),
+				),
+			),
+		),
+	}
+	var b bytes.Buffer
+	tmpl := template.Must(template.New("format").Funcs(cmdlog.InspectTemplateFuncs).Parse(`{{ openapi . }}`))
+	require.NoError(t, tmpl.Execute(&b, report))
+	require.JSONEq(t, `{
+  "components": {
+    "schemas": {
+      "users": {
+        "type": "object",
+        "title": "users",
+        "properties": {
+          "id": {"type": "integer", "format": "int32"},
+          "name": {"type": ["string", "null"], "description": "display name"}
+        },
+        "required": ["id"]
+      }
+    }
+  }
+}`, b.String())
+}
+
 func TestSchemaDiff_MarshalSQL(t *testing.T) {
 	client, err := sqlclient.Open(context.Background(), "sqlite://ci?mode=memory&_fk=1")
 	require.NoError(t, err)
@@ -172,6 +294,77 @@ func TestSchemaDiff_MarshalSQL(t *testing.T) {
 	require.Equal(t, "-- Create \"users\" table\nCREATE TABLE `users` (`id` int NOT NULL);\n", b)
 }
 
+func TestSchemaDiff_MarshalHTML(t *testing.T) {
+	client, err := sqlclient.Open(context.Background(), "sqlite://ci?mode=memory&_fk=1")
+	require.NoError(t, err)
+	defer client.Close()
+	var (
+		from    = schema.New("main")
+		id      = schema.NewIntColumn("id", "int")
+		name    = schema.NewStringColumn("name", "text")
+		email   = schema.NewStringColumn("email", "text")
+		users   = schema.NewTable("users").AddColumns(id, name, email)
+		orders  = schema.NewTable("orders").AddColumns(schema.NewIntColumn("user_email", "text"))
+		legacy  = schema.NewTable("legacy").AddColumns(schema.NewIntColumn("id", "int"))
+		v       = schema.NewView("emails", "SELECT email FROM users")
+		to      = schema.New("main")
+		toUsers = schema.NewTable("users").AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewStringColumn("full_name", "text"),
+			schema.NewNullIntColumn("age", "int"),
+		)
+	)
+	orders.AddForeignKeys(schema.NewForeignKey("orders_email").AddColumns(orders.Columns[0]).SetRefTable(users).AddRefColumns(email))
+	from.AddTables(users, orders, legacy).AddViews(v.AddDeps(users))
+	to.AddTables(toUsers)
+	diff := &cmdlog.SchemaDiff{
+		Client: client,
+		From:   schema.NewRealm(from),
+		To:     schema.NewRealm(to),
+		Changes: schema.Changes{
+			&schema.ModifyTable{
+				T: toUsers,
+				Changes: schema.Changes{
+					&schema.RenameColumn{From: name, To: toUsers.Columns[1]},
+					&schema.DropColumn{C: email},
+					&schema.AddColumn{C: toUsers.Columns[2]},
+					&schema.AddIndex{I: schema.NewIndex("users_age").AddColumns(toUsers.Columns[2])},
+				},
+			},
+			&schema.DropTable{T: legacy},
+			&schema.DropView{V: v},
+		},
+	}
+	out, err := diff.MarshalHTML()
+	require.NoError(t, err)
+	require.Contains(t, out, `<span class="badge critical">critical</span>`)
+	require.Contains(t, out, "1 table dropped, 1 table modified, 1 column added, 1 column dropped, 1 column renamed, 1 index added, 1 view dropped (critical)")
+	require.Contains(t, out, `<h3>main.users <span class="modified">(modified)</span></h3>`)
+	require.Contains(t, out, `<tr class="renamed" title="renamed"><td>name</td><td>text</td><td>false</td><td></td><td class="sep"></td><td>full_name</td><td>text</td><td>false</td><td></td></tr>`)
+	require.Contains(t, out, `<tr class="dropped" title="dropped"><td>email</td><td>text</td><td>false</td><td></td><td class="sep"></td><td></td><td></td><td></td><td></td></tr>`)
+	require.Contains(t, out, `<tr class="added" title="added"><td></td><td></td><td></td><td></td><td class="sep"></td><td>age</td><td>int</td><td>true</td><td></td></tr>`)
+	require.Contains(t, out, `<tr><td>id</td><td>int</td><td>false</td><td></td><td class="sep"></td><td>id</td><td>int</td><td>false</td><td></td></tr>`)
+	require.Contains(t, out, `<li>index &#34;users_age&#34; added</li>`)
+	require.Contains(t, out, `<h3>main.legacy <span class="dropped">(dropped)</span></h3>`)
+	require.Contains(t, out, `<tr class="dropped"><td>view</td><td>main.emails</td><td>dropped</td></tr>`)
+	require.Contains(t, out, `<li>view main.emails depends on renamed column main.users.name</li>`)
+	require.Contains(t, out, `<li>foreign key &#34;orders_email&#34; of table main.orders references dropped column main.users.email</li>`)
+	require.Contains(t, out, "<h2>SQL</h2>")
+
+	// The report is available in the diff template.
+	var b bytes.Buffer
+	tmpl := template.Must(template.New("format").Funcs(cmdlog.SchemaDiffFuncs).Parse(`{{ htmlreport . }}`))
+	require.NoError(t, tmpl.Execute(&b, &cmdlog.SchemaDiff{Client: client}))
+	require.Contains(t, b.String(), `<span class="badge none">none</span>`)
+	require.Contains(t, b.String(), "<p>no changes</p>")
+
+	// The builtin html escaper is not shadowed.
+	b.Reset()
+	tmpl = template.Must(template.New("format").Funcs(cmdlog.SchemaDiffFuncs).Parse(`{{ html "<b>" }}`))
+	require.NoError(t, tmpl.Execute(&b, nil))
+	require.Equal(t, "&lt;b&gt;", b.String())
+}
+
 func TestMigrateSet(t *testing.T) {
 	var (
 		b   bytes.Buffer
@@ -219,3 +412,47 @@ func TestMigrateSet(t *testing.T) {
 
 `, b.String())
 }
+
+func TestMigrateApply_Backfill(t *testing.T) {
+	var (
+		b   bytes.Buffer
+		f   = migrate.NewLocalFile("1_backfill.sql", []byte("UPDATE users SET name = upper(name);\nDELETE FROM sessions;"))
+		log = cmdlog.NewMigrateApply(context.Background(), &sqlclient.Client{Name: "sqlite"}, &migrate.MemDir{})
+	)
+	color.NoColor = true
+	log.Log(migrate.LogExecution{To: "1", Files: []migrate.File{f}})
+	log.Log(migrate.LogFile{File: f, Version: "1"})
+	log.Log(migrate.LogStmt{SQL: "UPDATE users SET name = upper(name);"})
+	log.Log(migrate.LogBackfill{SQL: "UPDATE users SET name = upper(name);", Batch: 1, From: 1, To: 10, Rows: 10, Total: 10})
+	log.Log(migrate.LogBackfill{SQL: "UPDATE users SET name = upper(name);", Batch: 2, From: 11, To: 20, Rows: 8, Total: 18})
+	log.Log(migrate.LogStmt{SQL: "DELETE FROM sessions;"})
+	log.Log(migrate.LogDone{})
+	require.Equal(t, []*cmdlog.Backfill{{Stmt: 0, Batches: 2, Rows: 18}}, log.Applied[0].Backfills)
+	require.NoError(t, cmdlog.MigrateApplyTemplate.Execute(&b, log))
+	require.Contains(t, b.String(), `
+    -> UPDATE users SET name = upper(name);
+       -- backfilled 18 rows in 2 batches
+    -> DELETE FROM sessions;
+`)
+}
+
+func TestMigrateApply_Aborted(t *testing.T) {
+	var (
+		b   bytes.Buffer
+		f   = migrate.NewLocalFile("1_init.sql", []byte("CREATE TABLE t1(c int);\nCREATE TABLE t2(c int);"))
+		log = cmdlog.NewMigrateApply(context.Background(), &sqlclient.Client{Name: "sqlite"}, &migrate.MemDir{})
+	)
+	color.NoColor = true
+	log.Log(migrate.LogExecution{To: "1", Files: []migrate.File{f}})
+	log.Log(migrate.LogFile{File: f, Version: "1"})
+	log.Log(migrate.LogStmt{SQL: "CREATE TABLE t1(c int);"})
+	log.Log(migrate.LogAbort{Error: migrate.ErrAborted})
+	require.True(t, log.Aborted)
+	require.True(t, log.Applied[0].Aborted)
+	require.Nil(t, log.Applied[0].Error)
+	require.Empty(t, log.Error)
+	require.NoError(t, cmdlog.MigrateApplyTemplate.Execute(&b, log))
+	require.Contains(t, b.String(), "\n    -> CREATE TABLE t1(c int);\n  -- aborted (")
+	require.Contains(t, b.String(), "-- 1 migrations (aborted)\n  -- 1 sql statements")
+	require.NotContains(t, b.String(), "with errors")
+}
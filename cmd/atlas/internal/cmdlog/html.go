@@ -0,0 +1,391 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cmdlog
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// htmlReport is the data of the HTML diff report.
+	htmlReport struct {
+		Summary *migrate.Summary
+		Tables  []*htmlTable
+		Objects []*htmlObject
+		Impact  []*htmlImpact
+		SQL     string
+	}
+
+	// htmlTable describes the changes of a table, side-by-side.
+	htmlTable struct {
+		From, To string // Qualified names. Empty, if the table does not exist on this side.
+		Action   string // added, dropped, modified or renamed.
+		Rows     []*htmlRow
+		Changes  []string // Changes of indexes, foreign keys and checks.
+		from, to *schema.Table
+	}
+
+	// htmlRow is a row of columns in the side-by-side view.
+	htmlRow struct {
+		Action   string // Empty, if the column was not changed.
+		From, To *htmlColumn
+	}
+
+	htmlColumn struct {
+		Name, Type, Default string
+		Null                bool
+	}
+
+	// htmlObject describes a change of a non-table object.
+	htmlObject struct {
+		Kind, Name, Action string
+	}
+
+	// htmlImpact describes an object that depends on a changed object.
+	htmlImpact struct {
+		Object, Reason string
+	}
+)
+
+var (
+	//go:embed html.tmpl
+	htmlTmpl string
+	// htmlTemplate is the template of the HTML diff report. Reports are self-contained,
+	// and do not load external resources, in order to be attached to pull requests.
+	htmlTemplate = template.Must(template.New("html").Parse(htmlTmpl))
+)
+
+// MarshalHTML returns a self-contained HTML report of the diff. The report shows the changed
+// tables side-by-side, highlights their column changes, and lists the objects that depend on
+// them, such as views and foreign keys of other tables.
+func (s *SchemaDiff) MarshalHTML() (string, error) {
+	return htmlDiff(s)
+}
+
+func htmlDiff(diff *SchemaDiff) (string, error) {
+	r := &htmlReport{Summary: migrate.Summarize(diff.Changes)}
+	if len(diff.Changes) > 0 && diff.Client != nil {
+		sql, err := sqlDiff(diff)
+		if err != nil {
+			return "", err
+		}
+		r.SQL = sql
+	}
+	r.build(diff)
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, r); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// build builds the report from the diff changes.
+func (r *htmlReport) build(diff *SchemaDiff) {
+	tables := make(map[*schema.Table]*htmlTable)
+	table := func(from, to *schema.Table, action string) *htmlTable {
+		// Changes of the same table are merged, e.g. a rename and a modification.
+		for _, t := range []*schema.Table{from, to} {
+			if h, ok := tables[t]; ok && t != nil {
+				if from != nil {
+					h.from = from
+				}
+				if !strings.Contains(h.Action, action) {
+					h.Action += ", " + action
+				}
+				tables[to] = h
+				return h
+			}
+		}
+		h := &htmlTable{Action: action, from: from, to: to}
+		tables[from], tables[to] = h, h
+		r.Tables = append(r.Tables, h)
+		return h
+	}
+	var changes []schema.Change
+	for _, c := range diff.Changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			table(nil, c.T, "added")
+		case *schema.DropTable:
+			table(c.T, nil, "dropped")
+			r.impact(diff.From, c.T, nil, "dropped")
+		case *schema.RenameTable:
+			table(c.From, c.To, "renamed")
+			r.impact(diff.From, c.From, nil, "renamed")
+		case *schema.ModifyTable:
+			h := table(nil, c.T, "modified")
+			changes = append(changes, c.Changes...)
+			for _, c := range c.Changes {
+				switch c := c.(type) {
+				case *schema.AddColumn, *schema.ModifyColumn, *schema.DropColumn, *schema.RenameColumn:
+				default:
+					h.Changes = append(h.Changes, changeDesc(c))
+				}
+			}
+		case *schema.AddSchema:
+			r.object("schema", c.S.Name, "added")
+		case *schema.DropSchema:
+			r.object("schema", c.S.Name, "dropped")
+		case *schema.ModifySchema:
+			r.object("schema", c.S.Name, "modified")
+		case *schema.AddView:
+			r.object("view", qualified(c.V.Schema, c.V.Name), "added")
+		case *schema.DropView:
+			r.object("view", qualified(c.V.Schema, c.V.Name), "dropped")
+		case *schema.ModifyView:
+			r.object("view", qualified(c.To.Schema, c.To.Name), "modified")
+		case *schema.RenameView:
+			r.object("view", qualified(c.From.Schema, c.From.Name)+" → "+qualified(c.To.Schema, c.To.Name), "renamed")
+		case *schema.AddFunc:
+			r.object("function", qualified(c.F.Schema, c.F.Name), "added")
+		case *schema.DropFunc:
+			r.object("function", qualified(c.F.Schema, c.F.Name), "dropped")
+		case *schema.ModifyFunc:
+			r.object("function", qualified(c.To.Schema, c.To.Name), "modified")
+		case *schema.RenameFunc:
+			r.object("function", qualified(c.From.Schema, c.From.Name)+" → "+qualified(c.To.Schema, c.To.Name), "renamed")
+		case *schema.AddProc:
+			r.object("procedure", qualified(c.P.Schema, c.P.Name), "added")
+		case *schema.DropProc:
+			r.object("procedure", qualified(c.P.Schema, c.P.Name), "dropped")
+		case *schema.ModifyProc:
+			r.object("procedure", qualified(c.To.Schema, c.To.Name), "modified")
+		case *schema.RenameProc:
+			r.object("procedure", qualified(c.From.Schema, c.From.Name)+" → "+qualified(c.To.Schema, c.To.Name), "renamed")
+		case *schema.AddObject:
+			r.object("object", objectName(c.O), "added")
+		case *schema.DropObject:
+			r.object("object", objectName(c.O), "dropped")
+		case *schema.ModifyObject:
+			r.object("object", objectName(c.To), "modified")
+		case *schema.RenameObject:
+			r.object("object", objectName(c.From)+" → "+objectName(c.To), "renamed")
+		}
+	}
+	for _, h := range r.Tables {
+		if h.from == nil && h.to != nil && h.Action != "added" {
+			h.from = findTable(diff.From, h.to)
+		}
+		if h.from != nil {
+			h.From = qualified(h.from.Schema, h.from.Name)
+		}
+		if h.to != nil {
+			h.To = qualified(h.to.Schema, h.to.Name)
+		}
+		h.rows(changes)
+		if h.from != nil && strings.Contains(h.Action, "modified") {
+			for _, row := range h.Rows {
+				if row.From != nil && (row.Action == "dropped" || strings.Contains(row.Action, "renamed")) {
+					c, _ := h.from.Column(row.From.Name)
+					r.impact(diff.From, h.from, c, row.Action)
+				}
+			}
+		}
+	}
+}
+
+// rows builds the side-by-side rows of the table columns.
+func (h *htmlTable) rows(changes []schema.Change) {
+	var (
+		actions = make(map[*schema.Column][]string)
+		renamed = make(map[*schema.Column]*schema.Column)
+		added   []*schema.Column
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddColumn:
+			if h.to != nil && columnOf(h.to, c.C) {
+				added = append(added, c.C)
+			}
+		case *schema.DropColumn:
+			actions[c.C] = append(actions[c.C], "dropped")
+		case *schema.ModifyColumn:
+			actions[c.From] = append(actions[c.From], "modified")
+			renamed[c.From] = c.To
+		case *schema.RenameColumn:
+			actions[c.From] = append(actions[c.From], "renamed")
+			renamed[c.From] = c.To
+		}
+	}
+	switch {
+	case h.from == nil && h.to != nil:
+		for _, c := range h.to.Columns {
+			h.Rows = append(h.Rows, &htmlRow{Action: "added", To: newColumn(c)})
+		}
+		return
+	case h.from != nil && h.to == nil:
+		for _, c := range h.from.Columns {
+			h.Rows = append(h.Rows, &htmlRow{Action: "dropped", From: newColumn(c)})
+		}
+		return
+	case h.from == nil:
+		return
+	}
+	for _, c := range h.from.Columns {
+		row := &htmlRow{Action: strings.Join(actions[c], ", "), From: newColumn(c)}
+		switch to, ok := renamed[c]; {
+		case row.Action == "dropped":
+		case ok:
+			row.To = newColumn(to)
+		default:
+			if to, ok := h.to.Column(c.Name); ok {
+				row.To = newColumn(to)
+			}
+		}
+		h.Rows = append(h.Rows, row)
+	}
+	for _, c := range added {
+		h.Rows = append(h.Rows, &htmlRow{Action: "added", To: newColumn(c)})
+	}
+}
+
+// impact adds the objects that depend on the given table (or column) to the report.
+func (r *htmlReport) impact(realm *schema.Realm, t *schema.Table, c *schema.Column, action string) {
+	if realm == nil || t == nil {
+		return
+	}
+	target := fmt.Sprintf("table %s", qualified(t.Schema, t.Name))
+	if c != nil {
+		target = fmt.Sprintf("column %s.%s", qualified(t.Schema, t.Name), c.Name)
+	}
+	for _, s := range realm.Schemas {
+		for _, v := range s.Views {
+			for _, d := range v.Deps {
+				if d == t {
+					r.Impact = append(r.Impact, &htmlImpact{
+						Object: fmt.Sprintf("view %s", qualified(v.Schema, v.Name)),
+						Reason: fmt.Sprintf("depends on %s %s", action, target),
+					})
+					break
+				}
+			}
+		}
+		for _, t1 := range s.Tables {
+			for _, fk := range t1.ForeignKeys {
+				if fk.RefTable != t || t1 == t && c == nil || c != nil && !columnOf(&schema.Table{Columns: fk.RefColumns}, c) {
+					continue
+				}
+				r.Impact = append(r.Impact, &htmlImpact{
+					Object: fmt.Sprintf("foreign key %q of table %s", fk.Symbol, qualified(t1.Schema, t1.Name)),
+					Reason: fmt.Sprintf("references %s %s", action, target),
+				})
+			}
+		}
+	}
+}
+
+// Class returns the CSS class of the given action. For example, "renamed" for "renamed, modified".
+func (*htmlReport) Class(action string) string {
+	class, _, _ := strings.Cut(action, ",")
+	return class
+}
+
+func (r *htmlReport) object(kind, name, action string) {
+	r.Objects = append(r.Objects, &htmlObject{Kind: kind, Name: name, Action: action})
+}
+
+// changeDesc returns a short description of a table change that is not a column change.
+func changeDesc(c schema.Change) string {
+	switch c := c.(type) {
+	case *schema.AddIndex:
+		return fmt.Sprintf("index %q added", c.I.Name)
+	case *schema.DropIndex:
+		return fmt.Sprintf("index %q dropped", c.I.Name)
+	case *schema.ModifyIndex:
+		return fmt.Sprintf("index %q modified", c.To.Name)
+	case *schema.RenameIndex:
+		return fmt.Sprintf("index %q renamed to %q", c.From.Name, c.To.Name)
+	case *schema.AddPrimaryKey:
+		return "primary key added"
+	case *schema.DropPrimaryKey:
+		return "primary key dropped"
+	case *schema.ModifyPrimaryKey:
+		return "primary key modified"
+	case *schema.AddForeignKey:
+		return fmt.Sprintf("foreign key %q added", c.F.Symbol)
+	case *schema.DropForeignKey:
+		return fmt.Sprintf("foreign key %q dropped", c.F.Symbol)
+	case *schema.ModifyForeignKey:
+		return fmt.Sprintf("foreign key %q modified", c.To.Symbol)
+	case *schema.AddCheck:
+		return fmt.Sprintf("check %q added", c.C.Name)
+	case *schema.DropCheck:
+		return fmt.Sprintf("check %q dropped", c.C.Name)
+	case *schema.ModifyCheck:
+		return fmt.Sprintf("check %q modified", c.To.Name)
+	default:
+		return "table attributes modified"
+	}
+}
+
+func newColumn(c *schema.Column) *htmlColumn {
+	h := &htmlColumn{Name: c.Name, Type: "unknown"}
+	if c.Type != nil {
+		h.Null = c.Type.Null
+		switch rv := reflect.Indirect(reflect.ValueOf(c.Type.Type)); {
+		case c.Type.Raw != "":
+			h.Type = c.Type.Raw
+		case rv.Kind() == reflect.Struct && rv.FieldByName("T").Kind() == reflect.String && rv.FieldByName("T").String() != "":
+			h.Type = rv.FieldByName("T").String()
+		case c.Type.Type != nil:
+			h.Type = reflect.TypeOf(c.Type.Type).String()
+		}
+	}
+	switch x := c.Default.(type) {
+	case *schema.Literal:
+		h.Default = x.V
+	case *schema.RawExpr:
+		h.Default = x.X
+	}
+	return h
+}
+
+// findTable returns the table in the realm with the same (qualified) name, if exists.
+func findTable(r *schema.Realm, t *schema.Table) *schema.Table {
+	if r == nil {
+		return nil
+	}
+	for _, s := range r.Schemas {
+		if t.Schema != nil && len(r.Schemas) > 1 && s.Name != t.Schema.Name {
+			continue
+		}
+		if t1, ok := s.Table(t.Name); ok {
+			return t1
+		}
+	}
+	return nil
+}
+
+// columnOf reports if the column exists in the table columns.
+func columnOf(t *schema.Table, c *schema.Column) bool {
+	for _, c1 := range t.Columns {
+		if c1 == c {
+			return true
+		}
+	}
+	return false
+}
+
+func qualified(s *schema.Schema, name string) string {
+	if s == nil || s.Name == "" {
+		return name
+	}
+	return s.Name + "." + name
+}
+
+func objectName(o schema.Object) string {
+	if e, ok := o.(*schema.EnumType); ok {
+		return qualified(e.Schema, e.T)
+	}
+	return fmt.Sprintf("%T", o)
+}
@@ -0,0 +1,94 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+)
+
+func TestOpenAuditSink(t *testing.T) {
+	ctx := context.Background()
+	r := &migrate.AuditRecord{
+		Time:      time.Now(),
+		Duration:  time.Millisecond,
+		Principal: "a8m",
+		Target:    "sqlite://test",
+		Version:   "1",
+		File:      "1_init.sql",
+		Stmt:      "CREATE TABLE t(c int);",
+	}
+
+	// File.
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, closer, err := OpenAuditSink(ctx, "file://"+path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Audit(ctx, r))
+	require.NoError(t, sink.Audit(ctx, r))
+	require.NoError(t, closer())
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(string(buf)), "\n"), 2)
+
+	// Database.
+	dbpath := filepath.Join(t.TempDir(), "audit.db")
+	sink, closer, err = OpenAuditSink(ctx, "sqlite://"+dbpath)
+	require.NoError(t, err)
+	require.NoError(t, sink.Audit(ctx, r))
+	require.NoError(t, closer())
+	c, err := sqlclient.Open(ctx, "sqlite://"+dbpath)
+	require.NoError(t, err)
+	defer c.Close()
+	rows, err := c.QueryContext(ctx, "SELECT stmt, principal FROM "+AuditTable)
+	require.NoError(t, err)
+	defer rows.Close()
+	var stmt, principal string
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&stmt, &principal))
+	require.Equal(t, r.Stmt, stmt)
+	require.Equal(t, r.Principal, principal)
+	require.False(t, rows.Next())
+}
+
+func TestBlobAuditSink(t *testing.T) {
+	ctx := context.Background()
+	b, err := blob.OpenBucket(ctx, "mem://")
+	require.NoError(t, err)
+	sink := NewBlobAuditSink(b)
+	require.NoError(t, sink.Audit(ctx, &migrate.AuditRecord{Stmt: "CREATE TABLE t(c int);"}))
+	// Records are written as they are audited.
+	exists, err := b.Exists(ctx, sink.prefix+"000001.json")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.NoError(t, sink.Audit(ctx, &migrate.AuditRecord{Stmt: "DROP TABLE t;"}))
+	var stmts []string
+	it := b.List(&blob.ListOptions{Prefix: sink.prefix})
+	for {
+		obj, err := it.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		buf, err := b.ReadAll(ctx, obj.Key)
+		require.NoError(t, err)
+		var r migrate.AuditRecord
+		require.NoError(t, json.Unmarshal(buf, &r))
+		stmts = append(stmts, r.Stmt)
+	}
+	require.Equal(t, []string{"CREATE TABLE t(c int);", "DROP TABLE t;"}, stmts)
+	require.NoError(t, sink.Close())
+}
@@ -0,0 +1,119 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/azureblob"
+	_ "gocloud.dev/blob/gcsblob"
+)
+
+// List of object storage directory types.
+const (
+	DirTypeS3    = "s3"
+	DirTypeGCS   = "gs"
+	DirTypeAzure = "azblob"
+)
+
+// BlobDir is a migrate.Dir stored in an object storage bucket, such as S3, GCS or Azure Blob Storage.
+// The directory content, including the atlas.sum file, is read when the directory is opened, and
+// written files are synced back to the bucket, allowing CI systems to apply migrations (or write new
+// ones) without checking out the repository.
+type BlobDir struct {
+	*migrate.MemDir
+	bucket *blob.Bucket
+}
+
+// OpenBlobDir opens the migration directory stored in the bucket of the given URL. The path of the
+// URL is used as the directory prefix in the bucket. For example:
+//
+//	s3://bucket/path/to/migrations?region=us-east-1
+//	gs://bucket/path/to/migrations
+//	azblob://container/path/to/migrations
+func OpenBlobDir(ctx context.Context, u *url.URL) (*BlobDir, error) {
+	bu, err := bucketURL(u)
+	if err != nil {
+		return nil, err
+	}
+	b, err := blob.OpenBucket(ctx, bu)
+	if err != nil {
+		return nil, fmt.Errorf("open migration directory bucket: %w", err)
+	}
+	d, err := NewBlobDir(ctx, b)
+	if err != nil {
+		b.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewBlobDir returns a BlobDir for the given bucket. The migration directory is expected
+// to be at the root of the bucket. Use blob.PrefixedBucket to scope it to a sub-directory.
+func NewBlobDir(ctx context.Context, b *blob.Bucket) (*BlobDir, error) {
+	d := &BlobDir{MemDir: &migrate.MemDir{}, bucket: b}
+	iter := b.List(&blob.ListOptions{Delimiter: "/"})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list migration directory: %w", err)
+		}
+		// Nested directories are not part of the migration directory.
+		if obj.IsDir {
+			continue
+		}
+		data, err := b.ReadAll(ctx, obj.Key)
+		if err != nil {
+			return nil, fmt.Errorf("read migration file %q: %w", obj.Key, err)
+		}
+		if err := d.MemDir.WriteFile(obj.Key, data); err != nil {
+			return nil, err
+		}
+	}
+	// Files written after the directory was loaded are synced to the bucket.
+	d.SyncWrites(func(name string, data []byte) error {
+		if err := b.WriteAll(ctx, name, data, nil); err != nil {
+			return fmt.Errorf("write migration file %q: %w", name, err)
+		}
+		return nil
+	})
+	return d, nil
+}
+
+// Close closes the underlying bucket.
+func (d *BlobDir) Close() error {
+	return d.bucket.Close()
+}
+
+// bucketURL returns the bucket URL of the given directory
+// URL, with the directory path set as the bucket prefix.
+func bucketURL(u *url.URL) (string, error) {
+	switch f := u.Query().Get("format"); f {
+	case "", FormatAtlas:
+	default:
+		return "", fmt.Errorf("dir format %q is not supported by %s directories", f, u.Scheme)
+	}
+	bu := *u
+	q := bu.Query()
+	q.Del("format")
+	if p := strings.Trim(bu.Path, "/"); p != "" {
+		q.Set("prefix", path.Join(q.Get("prefix"), p)+"/")
+	}
+	bu.Path, bu.RawPath, bu.RawQuery = "", "", q.Encode()
+	return bu.String(), nil
+}
+
+var _ migrate.CheckpointDir = (*BlobDir)(nil)
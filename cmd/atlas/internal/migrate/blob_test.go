@@ -0,0 +1,84 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"io/fs"
+	"net/url"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/memblob"
+)
+
+func TestBlobDir(t *testing.T) {
+	ctx := context.Background()
+	b := memblob.OpenBucket(nil)
+	local := &migrate.MemDir{}
+	require.NoError(t, local.WriteFile("1_init.sql", []byte("CREATE TABLE t(c int);")))
+	require.NoError(t, local.WriteFile("2_add.sql", []byte("ALTER TABLE t ADD c2 int;")))
+	sum, err := local.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(local, sum))
+	for _, name := range []string{"1_init.sql", "2_add.sql", migrate.HashFileName} {
+		data, err := fs.ReadFile(local, name)
+		require.NoError(t, err)
+		require.NoError(t, b.WriteAll(ctx, "migrations/"+name, data, nil))
+	}
+	// Objects outside the directory are ignored.
+	require.NoError(t, b.WriteAll(ctx, "migrations/nested/3_ignored.sql", []byte("DROP TABLE t;"), nil))
+	require.NoError(t, b.WriteAll(ctx, "other/3_ignored.sql", []byte("DROP TABLE t;"), nil))
+
+	pb := blob.PrefixedBucket(b, "migrations/")
+	defer pb.Close()
+	d, err := NewBlobDir(ctx, pb)
+	require.NoError(t, err)
+	require.NoError(t, migrate.Validate(d))
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "1_init.sql", files[0].Name())
+	require.Equal(t, "2_add.sql", files[1].Name())
+
+	// Writes are synced to the bucket.
+	require.NoError(t, d.WriteFile("3_drop.sql", []byte("DROP TABLE t;")))
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	data, err := pb.ReadAll(ctx, "3_drop.sql")
+	require.NoError(t, err)
+	require.Equal(t, "DROP TABLE t;", string(data))
+
+	d, err = NewBlobDir(ctx, pb)
+	require.NoError(t, err)
+	require.NoError(t, migrate.Validate(d))
+	files, err = d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+}
+
+func TestBucketURL(t *testing.T) {
+	for _, tt := range []struct{ in, out, err string }{
+		{in: "s3://bucket?region=us-east-1", out: "s3://bucket?region=us-east-1"},
+		{in: "s3://bucket/path/to/migrations?region=us-east-1", out: "s3://bucket?prefix=path%2Fto%2Fmigrations%2F&region=us-east-1"},
+		{in: "gs://bucket/migrations/?format=atlas", out: "gs://bucket?prefix=migrations%2F"},
+		{in: "azblob://container/migrations?prefix=service", out: "azblob://container?prefix=service%2Fmigrations%2F"},
+		{in: "s3://bucket/migrations?format=flyway", err: `dir format "flyway" is not supported by s3 directories`},
+	} {
+		u, err := url.Parse(tt.in)
+		require.NoError(t, err)
+		out, err := bucketURL(u)
+		if tt.err != "" {
+			require.EqualError(t, err, tt.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tt.out, out)
+	}
+}
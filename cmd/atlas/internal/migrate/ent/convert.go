@@ -22,6 +22,7 @@ func (rc *RevisionCreate) SetRevision(rev *migrate.Revision) *RevisionCreate {
 	rc.SetHash(rev.Hash)
 	rc.SetPartialHashes(rev.PartialHashes)
 	rc.SetOperatorVersion(rev.OperatorVersion)
+	rc.SetStmts(rev.Stmts)
 	return rc
 }
 
@@ -40,5 +41,6 @@ func (r *Revision) AtlasRevision() *migrate.Revision {
 		Hash:            r.Hash,
 		PartialHashes:   r.PartialHashes,
 		OperatorVersion: r.OperatorVersion,
+		Stmts:           r.Stmts,
 	}
 }
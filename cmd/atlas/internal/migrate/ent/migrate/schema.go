@@ -27,6 +27,7 @@ var (
 		{Name: "hash", Type: field.TypeString},
 		{Name: "partial_hashes", Type: field.TypeJSON, Nullable: true},
 		{Name: "operator_version", Type: field.TypeString},
+		{Name: "stmts", Type: field.TypeJSON, Nullable: true},
 	}
 	// AtlasSchemaRevisionsTable holds the schema information for the "atlas_schema_revisions" table.
 	AtlasSchemaRevisionsTable = &schema.Table{
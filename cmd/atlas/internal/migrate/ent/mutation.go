@@ -54,6 +54,8 @@ type RevisionMutation struct {
 	partial_hashes       *[]string
 	appendpartial_hashes []string
 	operator_version     *string
+	stmts                *[]*migrate.RevisionStmt
+	appendstmts          []*migrate.RevisionStmt
 	clearedFields        map[string]struct{}
 	done                 bool
 	oldValue             func(context.Context) (*Revision, error)
@@ -695,6 +697,71 @@ func (m *RevisionMutation) ResetOperatorVersion() {
 	m.operator_version = nil
 }
 
+// SetStmts sets the "stmts" field.
+func (m *RevisionMutation) SetStmts(ms []*migrate.RevisionStmt) {
+	m.stmts = &ms
+	m.appendstmts = nil
+}
+
+// Stmts returns the value of the "stmts" field in the mutation.
+func (m *RevisionMutation) Stmts() (r []*migrate.RevisionStmt, exists bool) {
+	v := m.stmts
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldStmts returns the old "stmts" field's value of the Revision entity.
+// If the Revision object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RevisionMutation) OldStmts(ctx context.Context) (v []*migrate.RevisionStmt, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldStmts is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldStmts requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldStmts: %w", err)
+	}
+	return oldValue.Stmts, nil
+}
+
+// AppendStmts adds ms to the "stmts" field.
+func (m *RevisionMutation) AppendStmts(ms []*migrate.RevisionStmt) {
+	m.appendstmts = append(m.appendstmts, ms...)
+}
+
+// AppendedStmts returns the list of values that were appended to the "stmts" field in this mutation.
+func (m *RevisionMutation) AppendedStmts() ([]*migrate.RevisionStmt, bool) {
+	if len(m.appendstmts) == 0 {
+		return nil, false
+	}
+	return m.appendstmts, true
+}
+
+// ClearStmts clears the value of the "stmts" field.
+func (m *RevisionMutation) ClearStmts() {
+	m.stmts = nil
+	m.appendstmts = nil
+	m.clearedFields[revision.FieldStmts] = struct{}{}
+}
+
+// StmtsCleared returns if the "stmts" field was cleared in this mutation.
+func (m *RevisionMutation) StmtsCleared() bool {
+	_, ok := m.clearedFields[revision.FieldStmts]
+	return ok
+}
+
+// ResetStmts resets all changes to the "stmts" field.
+func (m *RevisionMutation) ResetStmts() {
+	m.stmts = nil
+	m.appendstmts = nil
+	delete(m.clearedFields, revision.FieldStmts)
+}
+
 // Where appends a list predicates to the RevisionMutation builder.
 func (m *RevisionMutation) Where(ps ...predicate.Revision) {
 	m.predicates = append(m.predicates, ps...)
@@ -729,7 +796,7 @@ func (m *RevisionMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *RevisionMutation) Fields() []string {
-	fields := make([]string, 0, 11)
+	fields := make([]string, 0, 12)
 	if m.description != nil {
 		fields = append(fields, revision.FieldDescription)
 	}
@@ -763,6 +830,9 @@ func (m *RevisionMutation) Fields() []string {
 	if m.operator_version != nil {
 		fields = append(fields, revision.FieldOperatorVersion)
 	}
+	if m.stmts != nil {
+		fields = append(fields, revision.FieldStmts)
+	}
 	return fields
 }
 
@@ -793,6 +863,8 @@ func (m *RevisionMutation) Field(name string) (ent.Value, bool) {
 		return m.PartialHashes()
 	case revision.FieldOperatorVersion:
 		return m.OperatorVersion()
+	case revision.FieldStmts:
+		return m.Stmts()
 	}
 	return nil, false
 }
@@ -824,6 +896,8 @@ func (m *RevisionMutation) OldField(ctx context.Context, name string) (ent.Value
 		return m.OldPartialHashes(ctx)
 	case revision.FieldOperatorVersion:
 		return m.OldOperatorVersion(ctx)
+	case revision.FieldStmts:
+		return m.OldStmts(ctx)
 	}
 	return nil, fmt.Errorf("unknown Revision field %s", name)
 }
@@ -910,6 +984,13 @@ func (m *RevisionMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetOperatorVersion(v)
 		return nil
+	case revision.FieldStmts:
+		v, ok := value.([]*migrate.RevisionStmt)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStmts(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Revision field %s", name)
 }
@@ -1000,6 +1081,9 @@ func (m *RevisionMutation) ClearedFields() []string {
 	if m.FieldCleared(revision.FieldPartialHashes) {
 		fields = append(fields, revision.FieldPartialHashes)
 	}
+	if m.FieldCleared(revision.FieldStmts) {
+		fields = append(fields, revision.FieldStmts)
+	}
 	return fields
 }
 
@@ -1023,6 +1107,9 @@ func (m *RevisionMutation) ClearField(name string) error {
 	case revision.FieldPartialHashes:
 		m.ClearPartialHashes()
 		return nil
+	case revision.FieldStmts:
+		m.ClearStmts()
+		return nil
 	}
 	return fmt.Errorf("unknown Revision nullable field %s", name)
 }
@@ -1064,6 +1151,9 @@ func (m *RevisionMutation) ResetField(name string) error {
 	case revision.FieldOperatorVersion:
 		m.ResetOperatorVersion()
 		return nil
+	case revision.FieldStmts:
+		m.ResetStmts()
+		return nil
 	}
 	return fmt.Errorf("unknown Revision field %s", name)
 }
@@ -45,7 +45,9 @@ type Revision struct {
 	PartialHashes []string `json:"partial_hashes,omitempty"`
 	// OperatorVersion holds the value of the "operator_version" field.
 	OperatorVersion string `json:"operator_version,omitempty"`
-	selectValues    sql.SelectValues
+	// Stmts holds the value of the "stmts" field.
+	Stmts        []*migrate.RevisionStmt `json:"stmts,omitempty"`
+	selectValues sql.SelectValues
 }
 
 // scanValues returns the types for scanning values from sql.Rows.
@@ -53,7 +55,7 @@ func (*Revision) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case revision.FieldPartialHashes:
+		case revision.FieldPartialHashes, revision.FieldStmts:
 			values[i] = new([]byte)
 		case revision.FieldType, revision.FieldApplied, revision.FieldTotal, revision.FieldExecutionTime:
 			values[i] = new(sql.NullInt64)
@@ -150,6 +152,14 @@ func (r *Revision) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				r.OperatorVersion = value.String
 			}
+		case revision.FieldStmts:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field stmts", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &r.Stmts); err != nil {
+					return fmt.Errorf("unmarshal field stmts: %w", err)
+				}
+			}
 		default:
 			r.selectValues.Set(columns[i], values[i])
 		}
@@ -218,6 +228,9 @@ func (r *Revision) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("operator_version=")
 	builder.WriteString(r.OperatorVersion)
+	builder.WriteString(", ")
+	builder.WriteString("stmts=")
+	builder.WriteString(fmt.Sprintf("%v", r.Stmts))
 	builder.WriteByte(')')
 	return builder.String()
 }
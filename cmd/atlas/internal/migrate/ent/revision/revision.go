@@ -38,6 +38,8 @@ const (
 	FieldPartialHashes = "partial_hashes"
 	// FieldOperatorVersion holds the string denoting the operator_version field in the database.
 	FieldOperatorVersion = "operator_version"
+	// FieldStmts holds the string denoting the stmts field in the database.
+	FieldStmts = "stmts"
 	// Table holds the table name of the revision in the database.
 	Table = "atlas_schema_revisions"
 )
@@ -56,6 +58,7 @@ var Columns = []string{
 	FieldHash,
 	FieldPartialHashes,
 	FieldOperatorVersion,
+	FieldStmts,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
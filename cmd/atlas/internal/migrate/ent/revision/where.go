@@ -718,3 +718,13 @@ func Or(predicates ...predicate.Revision) predicate.Revision {
 func Not(p predicate.Revision) predicate.Revision {
 	return predicate.Revision(sql.NotPredicates(p))
 }
+
+// StmtsIsNil applies the IsNil predicate on the "stmts" field.
+func StmtsIsNil() predicate.Revision {
+	return predicate.Revision(sql.FieldIsNull(FieldStmts))
+}
+
+// StmtsNotNil applies the NotNil predicate on the "stmts" field.
+func StmtsNotNil() predicate.Revision {
+	return predicate.Revision(sql.FieldNotNull(FieldStmts))
+}
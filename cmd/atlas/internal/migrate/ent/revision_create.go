@@ -134,6 +134,12 @@ func (rc *RevisionCreate) SetOperatorVersion(s string) *RevisionCreate {
 	return rc
 }
 
+// SetStmts sets the "stmts" field.
+func (rc *RevisionCreate) SetStmts(ms []*migrate.RevisionStmt) *RevisionCreate {
+	rc.mutation.SetStmts(ms)
+	return rc
+}
+
 // SetID sets the "id" field.
 func (rc *RevisionCreate) SetID(s string) *RevisionCreate {
 	rc.mutation.SetID(s)
@@ -306,6 +312,10 @@ func (rc *RevisionCreate) createSpec() (*Revision, *sqlgraph.CreateSpec) {
 		_spec.SetField(revision.FieldOperatorVersion, field.TypeString, value)
 		_node.OperatorVersion = value
 	}
+	if value, ok := rc.mutation.Stmts(); ok {
+		_spec.SetField(revision.FieldStmts, field.TypeJSON, value)
+		_node.Stmts = value
+	}
 	return _node, _spec
 }
 
@@ -508,6 +518,24 @@ func (u *RevisionUpsert) UpdateOperatorVersion() *RevisionUpsert {
 	return u
 }
 
+// SetStmts sets the "stmts" field.
+func (u *RevisionUpsert) SetStmts(v []*migrate.RevisionStmt) *RevisionUpsert {
+	u.Set(revision.FieldStmts, v)
+	return u
+}
+
+// UpdateStmts sets the "stmts" field to the value that was provided on create.
+func (u *RevisionUpsert) UpdateStmts() *RevisionUpsert {
+	u.SetExcluded(revision.FieldStmts)
+	return u
+}
+
+// ClearStmts clears the value of the "stmts" field.
+func (u *RevisionUpsert) ClearStmts() *RevisionUpsert {
+	u.SetNull(revision.FieldStmts)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create except the ID field.
 // Using this option is equivalent to using:
 //
@@ -737,6 +765,27 @@ func (u *RevisionUpsertOne) UpdateOperatorVersion() *RevisionUpsertOne {
 	})
 }
 
+// SetStmts sets the "stmts" field.
+func (u *RevisionUpsertOne) SetStmts(v []*migrate.RevisionStmt) *RevisionUpsertOne {
+	return u.Update(func(s *RevisionUpsert) {
+		s.SetStmts(v)
+	})
+}
+
+// UpdateStmts sets the "stmts" field to the value that was provided on create.
+func (u *RevisionUpsertOne) UpdateStmts() *RevisionUpsertOne {
+	return u.Update(func(s *RevisionUpsert) {
+		s.UpdateStmts()
+	})
+}
+
+// ClearStmts clears the value of the "stmts" field.
+func (u *RevisionUpsertOne) ClearStmts() *RevisionUpsertOne {
+	return u.Update(func(s *RevisionUpsert) {
+		s.ClearStmts()
+	})
+}
+
 // Exec executes the query.
 func (u *RevisionUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1129,6 +1178,27 @@ func (u *RevisionUpsertBulk) UpdateOperatorVersion() *RevisionUpsertBulk {
 	})
 }
 
+// SetStmts sets the "stmts" field.
+func (u *RevisionUpsertBulk) SetStmts(v []*migrate.RevisionStmt) *RevisionUpsertBulk {
+	return u.Update(func(s *RevisionUpsert) {
+		s.SetStmts(v)
+	})
+}
+
+// UpdateStmts sets the "stmts" field to the value that was provided on create.
+func (u *RevisionUpsertBulk) UpdateStmts() *RevisionUpsertBulk {
+	return u.Update(func(s *RevisionUpsert) {
+		s.UpdateStmts()
+	})
+}
+
+// ClearStmts clears the value of the "stmts" field.
+func (u *RevisionUpsertBulk) ClearStmts() *RevisionUpsertBulk {
+	return u.Update(func(s *RevisionUpsert) {
+		s.ClearStmts()
+	})
+}
+
 // Exec executes the query.
 func (u *RevisionUpsertBulk) Exec(ctx context.Context) error {
 	for i, b := range u.create.builders {
@@ -181,6 +181,24 @@ func (ru *RevisionUpdate) SetOperatorVersion(s string) *RevisionUpdate {
 	return ru
 }
 
+// SetStmts sets the "stmts" field.
+func (ru *RevisionUpdate) SetStmts(ms []*migrate.RevisionStmt) *RevisionUpdate {
+	ru.mutation.SetStmts(ms)
+	return ru
+}
+
+// AppendStmts appends ms to the "stmts" field.
+func (ru *RevisionUpdate) AppendStmts(ms []*migrate.RevisionStmt) *RevisionUpdate {
+	ru.mutation.AppendStmts(ms)
+	return ru
+}
+
+// ClearStmts clears the value of the "stmts" field.
+func (ru *RevisionUpdate) ClearStmts() *RevisionUpdate {
+	ru.mutation.ClearStmts()
+	return ru
+}
+
 // Mutation returns the RevisionMutation object of the builder.
 func (ru *RevisionUpdate) Mutation() *RevisionMutation {
 	return ru.mutation
@@ -293,6 +311,17 @@ func (ru *RevisionUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := ru.mutation.OperatorVersion(); ok {
 		_spec.SetField(revision.FieldOperatorVersion, field.TypeString, value)
 	}
+	if value, ok := ru.mutation.Stmts(); ok {
+		_spec.SetField(revision.FieldStmts, field.TypeJSON, value)
+	}
+	if value, ok := ru.mutation.AppendedStmts(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, revision.FieldStmts, value)
+		})
+	}
+	if ru.mutation.StmtsCleared() {
+		_spec.ClearField(revision.FieldStmts, field.TypeJSON)
+	}
 	_spec.Node.Schema = ru.schemaConfig.Revision
 	ctx = internal.NewSchemaConfigContext(ctx, ru.schemaConfig)
 	if n, err = sqlgraph.UpdateNodes(ctx, ru.driver, _spec); err != nil {
@@ -461,6 +490,24 @@ func (ruo *RevisionUpdateOne) SetOperatorVersion(s string) *RevisionUpdateOne {
 	return ruo
 }
 
+// SetStmts sets the "stmts" field.
+func (ruo *RevisionUpdateOne) SetStmts(ms []*migrate.RevisionStmt) *RevisionUpdateOne {
+	ruo.mutation.SetStmts(ms)
+	return ruo
+}
+
+// AppendStmts appends ms to the "stmts" field.
+func (ruo *RevisionUpdateOne) AppendStmts(ms []*migrate.RevisionStmt) *RevisionUpdateOne {
+	ruo.mutation.AppendStmts(ms)
+	return ruo
+}
+
+// ClearStmts clears the value of the "stmts" field.
+func (ruo *RevisionUpdateOne) ClearStmts() *RevisionUpdateOne {
+	ruo.mutation.ClearStmts()
+	return ruo
+}
+
 // Mutation returns the RevisionMutation object of the builder.
 func (ruo *RevisionUpdateOne) Mutation() *RevisionMutation {
 	return ruo.mutation
@@ -603,6 +650,17 @@ func (ruo *RevisionUpdateOne) sqlSave(ctx context.Context) (_node *Revision, err
 	if value, ok := ruo.mutation.OperatorVersion(); ok {
 		_spec.SetField(revision.FieldOperatorVersion, field.TypeString, value)
 	}
+	if value, ok := ruo.mutation.Stmts(); ok {
+		_spec.SetField(revision.FieldStmts, field.TypeJSON, value)
+	}
+	if value, ok := ruo.mutation.AppendedStmts(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, revision.FieldStmts, value)
+		})
+	}
+	if ruo.mutation.StmtsCleared() {
+		_spec.ClearField(revision.FieldStmts, field.TypeJSON)
+	}
 	_spec.Node.Schema = ruo.schemaConfig.Revision
 	ctx = internal.NewSchemaConfigContext(ctx, ruo.schemaConfig)
 	_node = &Revision{config: ruo.config}
@@ -52,6 +52,8 @@ func (Revision) Fields() []ent.Field {
 		field.Strings("partial_hashes").
 			Optional(),
 		field.String("operator_version"),
+		field.JSON("stmts", []*migrate.RevisionStmt{}).
+			Optional(),
 	}
 }
 
@@ -0,0 +1,240 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"ariga.io/atlas/cmd/atlas/internal/migrate/ent"
+	"ariga.io/atlas/cmd/atlas/internal/migrate/ent/revision"
+	"ariga.io/atlas/sql/migrate"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/google/uuid"
+)
+
+type (
+	// A Lease is a time-bounded lock on the revisions table that is held by a single applier.
+	// Unlike database advisory locks, leases are stored in the revisions table itself, and are
+	// kept alive by heartbeats. A lease that was not renewed within its TTL is considered stale,
+	// and can be taken over by another applier, e.g. in case the previous applier crashed.
+	//
+	// Note that heartbeats are recorded using the clock of the applier. Hence, the TTL should be
+	// large enough to tolerate the clock skew between different appliers.
+	Lease struct {
+		Holder    string        // Identifier of the lease holder.
+		Heartbeat time.Time     // Last time the holder renewed the lease.
+		TTL       time.Duration // Duration after the last heartbeat the lease expires.
+		Operator  string        // Operator version of the lease holder.
+		// Takeover holds the stale lease that was taken over
+		// when this lease was acquired, if there was any.
+		Takeover *Lease
+		beat     string // stored heartbeat value.
+	}
+
+	// Leaser is optionally implemented by revision read-writers
+	// that support leasing the revisions table.
+	Leaser interface {
+		// AcquireLease acquires the lease of the revisions table for the given holder.
+		AcquireLease(ctx context.Context, holder, operatorV string, ttl time.Duration) (*Lease, error)
+		// KeepAlive renews the lease in the background until the returned function is called.
+		KeepAlive(ctx context.Context, l *Lease, interval time.Duration) (context.Context, func() error)
+	}
+
+	// LeaseHeldError is returned by AcquireLease in case
+	// the lease is held by another (non-stale) applier.
+	LeaseHeldError struct {
+		Lease *Lease
+	}
+)
+
+// ErrLeaseLost is returned when a lease could not be renewed
+// because it was released or taken over by another applier.
+var ErrLeaseLost = errors.New("lease was lost")
+
+// Error implements the error interface.
+func (e *LeaseHeldError) Error() string {
+	return fmt.Sprintf("revisions table is leased by %q (last heartbeat at %s, ttl %s)", e.Lease.Holder, e.Lease.Heartbeat.Format(time.RFC3339), e.Lease.TTL)
+}
+
+// LeaseHolder returns a holder identifier for the current process.
+func LeaseHolder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), uuid.NewString()[:8])
+}
+
+// Expired reports if the lease was not renewed in its TTL, relative to the given time.
+func (l *Lease) Expired(now time.Time) bool {
+	return now.After(l.Heartbeat.Add(l.TTL))
+}
+
+// revisionLease holds the column "id" ("version") of the revision that holds the lease of the
+// revisions table. The lease holder is stored in the "hash" column, the last heartbeat in the
+// "error" column and the TTL in the "execution_time" column, as these columns are updatable.
+const revisionLease = ".atlas_lease"
+
+// ReadLease returns the current lease of the revisions table,
+// or ErrRevisionNotExist if the table is not leased.
+func (r *EntRevisions) ReadLease(ctx context.Context) (*Lease, error) {
+	rev, err := r.ec.Revision.Get(ctx, revisionLease)
+	if err != nil && !ent.IsNotFound(err) {
+		return nil, err
+	}
+	if ent.IsNotFound(err) {
+		return nil, migrate.ErrRevisionNotExist
+	}
+	beat, err := time.Parse(time.RFC3339Nano, rev.Error)
+	if err != nil {
+		return nil, fmt.Errorf("parse lease heartbeat: %w", err)
+	}
+	return &Lease{
+		Holder:    rev.Hash,
+		Heartbeat: beat,
+		TTL:       rev.ExecutionTime,
+		Operator:  rev.OperatorVersion,
+		beat:      rev.Error,
+	}, nil
+}
+
+// AcquireLease acquires the lease of the revisions table for the given holder. If the lease is held
+// by another holder, and it is not stale, a LeaseHeldError is returned. Stale leases are taken over
+// and returned in the Takeover field of the acquired lease.
+func (r *EntRevisions) AcquireLease(ctx context.Context, holder, operatorV string, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("invalid lease ttl: %s", ttl)
+	}
+	now := time.Now()
+	l := &Lease{Holder: holder, Heartbeat: now, TTL: ttl, Operator: operatorV, beat: now.Format(time.RFC3339Nano)}
+	err := r.ec.Revision.Create().
+		SetID(revisionLease). // lease key
+		SetDescription("").
+		SetExecutedAt(now).    // when it was created
+		SetExecutionTime(ttl). // lease ttl
+		SetHash(holder).       // lease holder
+		SetError(l.beat).      // last heartbeat
+		SetOperatorVersion(operatorV).
+		OnConflict(sql.ConflictColumns(revision.FieldID)).
+		Ignore().
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("upsert revision-table lease: %w", err)
+	}
+	cur, err := r.ReadLease(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read revision-table lease: %w", err)
+	}
+	switch {
+	// Lease was created by this call.
+	case cur.Holder == holder && cur.beat == l.beat:
+		return l, nil
+	case cur.Holder != holder && !cur.Expired(now):
+		return nil, &LeaseHeldError{Lease: cur}
+	case cur.Holder != holder:
+		l.Takeover = cur
+	}
+	// Take over the lease (or renew it), only if it was not changed since it was read.
+	n, err := r.ec.Revision.Update().
+		Where(revision.ID(revisionLease), revision.HashEQ(cur.Holder), revision.ErrorEQ(cur.beat)).
+		SetHash(holder).
+		SetError(l.beat).
+		SetExecutionTime(ttl).
+		SetOperatorVersion(operatorV).
+		Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("update revision-table lease: %w", err)
+	}
+	if n == 0 {
+		if cur, err = r.ReadLease(ctx); err != nil {
+			return nil, fmt.Errorf("read revision-table lease: %w", err)
+		}
+		return nil, &LeaseHeldError{Lease: cur}
+	}
+	return l, nil
+}
+
+// RenewLease records a heartbeat for the given lease. ErrLeaseLost is
+// returned in case the lease was released or taken over by another holder.
+func (r *EntRevisions) RenewLease(ctx context.Context, l *Lease) error {
+	now := time.Now()
+	beat := now.Format(time.RFC3339Nano)
+	n, err := r.ec.Revision.Update().
+		Where(revision.ID(revisionLease), revision.HashEQ(l.Holder), revision.ErrorEQ(l.beat)).
+		SetError(beat).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("renew revision-table lease: %w", err)
+	}
+	if n == 0 {
+		return ErrLeaseLost
+	}
+	l.Heartbeat, l.beat = now, beat
+	return nil
+}
+
+// ReleaseLease releases the given lease. Releasing a lease
+// that was already taken over by another holder is a no-op.
+func (r *EntRevisions) ReleaseLease(ctx context.Context, l *Lease) error {
+	_, err := r.ec.Revision.Delete().
+		Where(revision.ID(revisionLease), revision.HashEQ(l.Holder)).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("release revision-table lease: %w", err)
+	}
+	return nil
+}
+
+// KeepAlive renews the lease in the background every interval. The returned context is canceled
+// in case the lease was lost, and the returned function stops the renewal and releases the lease.
+func (r *EntRevisions) KeepAlive(ctx context.Context, l *Lease, interval time.Duration) (context.Context, func() error) {
+	var (
+		wg          sync.WaitGroup
+		done        = make(chan struct{})
+		leaseCtx, c = context.WithCancelCause(ctx)
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-leaseCtx.Done():
+				return
+			case <-t.C:
+				if err := r.RenewLease(leaseCtx, l); err != nil {
+					c(err)
+					return
+				}
+			}
+		}
+	}()
+	return leaseCtx, func() error {
+		close(done)
+		wg.Wait()
+		lost := context.Cause(leaseCtx)
+		c(nil)
+		// Release the lease using the parent context, as
+		// the lease context is canceled at this stage.
+		if err := r.ReleaseLease(ctx, l); err != nil {
+			return err
+		}
+		if lost != nil && !errors.Is(lost, context.Canceled) {
+			return lost
+		}
+		return nil
+	}
+}
+
+var _ Leaser = (*EntRevisions)(nil)
@@ -0,0 +1,101 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/cmd/atlas/internal/migrate/ent/revision"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntRevisions_Lease(t *testing.T) {
+	ctx := context.Background()
+	c, err := sqlclient.Open(ctx, "sqlite://?mode=memory")
+	require.NoError(t, err)
+	r, err := NewEntRevisions(ctx, c)
+	require.NoError(t, err)
+	require.NoError(t, r.Migrate(ctx))
+
+	_, err = r.ReadLease(ctx)
+	require.True(t, errors.Is(err, migrate.ErrRevisionNotExist))
+	l1, err := r.AcquireLease(ctx, "a", "v0.12.0", time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, l1.Takeover)
+
+	// Lease is not returned as a revision.
+	revs, err := r.ReadRevisions(ctx)
+	require.NoError(t, err)
+	require.Empty(t, revs)
+	_, err = r.ReadRevision(ctx, revisionLease)
+	require.Error(t, err)
+	require.Error(t, r.WriteRevision(ctx, &migrate.Revision{Version: revisionLease}))
+	require.Error(t, r.DeleteRevision(ctx, revisionLease))
+
+	// Lease is held by another applier.
+	_, err = r.AcquireLease(ctx, "b", "v0.12.0", time.Minute)
+	var held *LeaseHeldError
+	require.True(t, errors.As(err, &held))
+	require.Equal(t, "a", held.Lease.Holder)
+	require.Equal(t, time.Minute, held.Lease.TTL)
+
+	// Heartbeats renew the lease.
+	beat := l1.Heartbeat
+	require.NoError(t, r.RenewLease(ctx, l1))
+	require.True(t, l1.Heartbeat.After(beat))
+	cur, err := r.ReadLease(ctx)
+	require.NoError(t, err)
+	require.True(t, cur.Heartbeat.Equal(l1.Heartbeat))
+
+	// Stale leases are taken over.
+	_, err = r.ec.Revision.Update().
+		Where(revision.ID(revisionLease)).
+		SetError(time.Now().Add(-time.Hour).Format(time.RFC3339Nano)).
+		Save(ctx)
+	require.NoError(t, err)
+	cur, err = r.ReadLease(ctx)
+	require.NoError(t, err)
+	require.True(t, cur.Expired(time.Now()))
+	l2, err := r.AcquireLease(ctx, "b", "v0.12.0", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, l2.Takeover)
+	require.Equal(t, "a", l2.Takeover.Holder)
+
+	// The previous holder lost its lease.
+	require.True(t, errors.Is(r.RenewLease(ctx, l1), ErrLeaseLost))
+	require.NoError(t, r.ReleaseLease(ctx, l1))
+	cur, err = r.ReadLease(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "b", cur.Holder)
+
+	// KeepAlive renews the lease and releases it once stopped.
+	lctx, stop := r.KeepAlive(ctx, l2, 10*time.Millisecond)
+	beat = l2.Heartbeat
+	require.Eventually(t, func() bool {
+		cur, err := r.ReadLease(ctx)
+		return err == nil && cur.Heartbeat.After(beat)
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, lctx.Err())
+	require.NoError(t, stop())
+	_, err = r.ReadLease(ctx)
+	require.True(t, errors.Is(err, migrate.ErrRevisionNotExist))
+	require.Error(t, lctx.Err())
+
+	// Losing the lease cancels the context.
+	l3, err := r.AcquireLease(ctx, "c", "v0.12.0", time.Minute)
+	require.NoError(t, err)
+	lctx, stop = r.KeepAlive(ctx, l3, 10*time.Millisecond)
+	_, err = r.ec.Revision.Update().Where(revision.ID(revisionLease)).SetHash("d").Save(ctx)
+	require.NoError(t, err)
+	<-lctx.Done()
+	require.True(t, errors.Is(context.Cause(lctx), ErrLeaseLost))
+	require.True(t, errors.Is(stop(), ErrLeaseLost))
+}
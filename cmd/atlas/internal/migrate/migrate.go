@@ -122,6 +122,9 @@ func (r *EntRevisions) ReadRevision(ctx context.Context, v string) (*migrate.Rev
 	if v == revisionID {
 		return nil, errors.New("cannot read revision-table identifier as revision")
 	}
+	if v == revisionLease {
+		return nil, errors.New("cannot read revision-table lease as revision")
+	}
 	rev, err := r.ec.Revision.Get(ctx, v)
 	if err != nil && !ent.IsNotFound(err) {
 		return nil, err
@@ -137,7 +140,7 @@ func (r *EntRevisions) ReadRevision(ctx context.Context, v string) (*migrate.Rev
 // ReadRevisions will not return results only saved to cache.
 func (r *EntRevisions) ReadRevisions(ctx context.Context) ([]*migrate.Revision, error) {
 	revs, err := r.ec.Revision.Query().
-		Where(revision.IDNEQ(revisionID)).
+		Where(revision.IDNotIn(revisionID, revisionLease)).
 		Order(revision.ByID()).
 		All(ctx)
 	if err != nil {
@@ -153,7 +156,7 @@ func (r *EntRevisions) ReadRevisions(ctx context.Context) ([]*migrate.Revision,
 // CurrentRevision returns the current (latest) revision in the revisions table.
 func (r *EntRevisions) CurrentRevision(ctx context.Context) (*migrate.Revision, error) {
 	rev, err := r.ec.Revision.Query().
-		Where(revision.IDNEQ(revisionID)).
+		Where(revision.IDNotIn(revisionID, revisionLease)).
 		Order(revision.ByID(sql.OrderDesc())).
 		First(ctx)
 	if err != nil && !ent.IsNotFound(err) {
@@ -170,6 +173,9 @@ func (r *EntRevisions) WriteRevision(ctx context.Context, rev *migrate.Revision)
 	if rev.Version == revisionID {
 		return errors.New("writing the revision-table identifier is not allowed")
 	}
+	if rev.Version == revisionLease {
+		return errors.New("writing the revision-table lease is not allowed")
+	}
 	return r.ec.Revision.Create().
 		SetRevision(rev).
 		OnConflict(sql.ConflictColumns(revision.FieldID)).
@@ -182,6 +188,9 @@ func (r *EntRevisions) DeleteRevision(ctx context.Context, v string) error {
 	if v == revisionID {
 		return errors.New("deleting the revision-table identifier is not allowed")
 	}
+	if v == revisionLease {
+		return errors.New("deleting the revision-table lease is not allowed")
+	}
 	return r.ec.Revision.DeleteOneID(v).Exec(ctx)
 }
 
@@ -328,7 +337,7 @@ const (
 	DirTypeAtlas = "atlas"
 )
 
-// DirURL returns a migrate.Dir to use as migration directory. For now only local directories are supported.
+// DirURL returns a migrate.Dir to use as migration directory. Local, in-memory and object storage directories are supported.
 func DirURL(ctx context.Context, u *url.URL, create bool) (migrate.Dir, error) {
 	p := filepath.Join(u.Host, u.Path)
 	switch u.Scheme {
@@ -340,6 +349,8 @@ func DirURL(ctx context.Context, u *url.URL, create bool) (migrate.Dir, error) {
 		}
 	case DirTypeAtlas:
 		return openAtlasDir(ctx, u)
+	case DirTypeS3, DirTypeGCS, DirTypeAzure:
+		return OpenBlobDir(ctx, u)
 	default:
 		return nil, fmt.Errorf("unsupported driver %q", u.Scheme)
 	}
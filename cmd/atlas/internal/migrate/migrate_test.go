@@ -112,6 +112,20 @@ func runRevisionsTests(ctx context.Context, t *testing.T, drv migrate.Driver, r
 	require.Equal(t, "1", revs[0].Version)
 	require.Equal(t, "2", revs[1].Version)
 
+	// Statement timings are stored with the revision.
+	next.Version = "3"
+	next.Stmts = []*migrate.RevisionStmt{
+		{Stmt: "CREATE TABLE t1(c int)", ExecutionTime: time.Second},
+		{Stmt: "CREATE TABLE t2(c int)", ExecutionTime: time.Minute, Error: "oops"},
+	}
+	require.NoError(t, r.WriteRevision(ctx, &next))
+	rev, err := r.ReadRevision(ctx, "3")
+	require.NoError(t, err)
+	require.Len(t, rev.Stmts, 2)
+	require.Equal(t, time.Second, rev.Stmts[0].ExecutionTime)
+	require.Equal(t, "oops", rev.Stmts[1].Error)
+	require.NoError(t, r.DeleteRevision(ctx, "3"))
+
 	id, err := r.ID(ctx, "v0.10.1")
 	require.NoError(t, err)
 	require.NotEmpty(t, id)
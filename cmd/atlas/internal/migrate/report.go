@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"ariga.io/atlas/cmd/atlas/internal/cmdlog"
 	"ariga.io/atlas/cmd/atlas/internal/migrate/ent/revision"
@@ -25,6 +26,42 @@ type StatusReporter struct {
 	Dir migrate.Dir
 	// Schema name the revision table resides in.
 	Schema string
+	// Estimate the execution time of the pending files, based
+	// on the timings of the applied ones. See migrate.Estimator.
+	Estimate bool
+}
+
+// estimate estimates the execution time of the pending files, based on the timings of the
+// applied files and the statistics of the tables in the database. See migrate.Estimator.
+func (r *StatusReporter) estimate(ctx context.Context, rep *cmdlog.MigrateStatus) error {
+	opts := &schema.InspectRealmOption{Stats: true}
+	if r.Client.URL.Schema != "" {
+		opts.Schemas = append(opts.Schemas, r.Client.URL.Schema)
+	}
+	stats, err := r.Client.InspectRealm(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("inspecting table statistics: %w", err)
+	}
+	e := &migrate.Estimator{History: rep.Applied, Stats: stats}
+	for _, f := range rep.Pending {
+		est, err := e.File(f)
+		if err != nil {
+			return err
+		}
+		fe := &cmdlog.FileEstimate{Version: f.Version(), Duration: est.Duration.Round(time.Millisecond)}
+		for _, s := range est.Stmts {
+			switch {
+			case s.Rows > 0:
+				fe.Rows += s.Rows
+			case s.Rows < 0:
+				fe.Unknown++
+			}
+		}
+		rep.Estimated += est.Duration
+		rep.Estimates = append(rep.Estimates, fe)
+	}
+	rep.Estimated = rep.Estimated.Round(time.Millisecond)
+	return nil
 }
 
 // Report creates and writes a MigrateStatus.
@@ -83,6 +120,11 @@ func (r *StatusReporter) Report(ctx context.Context) (*cmdlog.MigrateStatus, err
 		rep.Status = "PENDING"
 		rep.Next = rep.Pending[0].Version()
 	}
+	if r.Estimate && len(rep.Pending) > 0 {
+		if err := r.estimate(ctx, rep); err != nil {
+			return nil, err
+		}
+	}
 	// If the last one is partially applied (and not manually resolved).
 	if len(rep.Applied) != 0 {
 		last := rep.Applied[len(rep.Applied)-1]
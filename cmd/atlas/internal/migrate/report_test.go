@@ -113,6 +113,73 @@ Last migration attempt had errors:
 `, buf.String())
 }
 
+func TestReporter_Estimate(t *testing.T) {
+	var (
+		buf strings.Builder
+		ctx = context.Background()
+	)
+	dir, err := migrate.NewLocalDir(filepath.Join("../migrate/testdata", "broken"))
+	require.NoError(t, err)
+	c, err := sqlclient.Open(ctx, "sqlite://?mode=memory")
+	require.NoError(t, err)
+	defer c.Close()
+	report, err := (&StatusReporter{Client: c, Dir: dir, Estimate: true}).Report(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 4*migrate.DefaultStmtCost, report.Estimated)
+	require.NoError(t, cmdlog.MigrateStatusTemplate.Execute(&buf, report))
+	require.Equal(t, `Migration Status: PENDING
+  -- Current Version: No migration applied yet
+  -- Next Version:    1
+  -- Executed Files:  0
+  -- Pending Files:   3 (estimated 40ms)
+     1: 10ms
+     2: 10ms
+     3: 20ms
+`, buf.String())
+
+	// Calibrated by the applied files.
+	rrw, err := NewEntRevisions(ctx, c)
+	require.NoError(t, err)
+	require.NoError(t, rrw.Migrate(ctx))
+	ex, err := migrate.NewExecutor(c.Driver, dir, rrw)
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(ctx, 1))
+	report, err = (&StatusReporter{Client: c, Dir: dir, Estimate: true}).Report(ctx)
+	require.NoError(t, err)
+	require.Len(t, report.Pending, 2)
+	require.NotEqual(t, 3*migrate.DefaultStmtCost, report.Estimated)
+
+	// No estimation by default.
+	report, err = (&StatusReporter{Client: c, Dir: dir}).Report(ctx)
+	require.NoError(t, err)
+	require.Zero(t, report.Estimated)
+	require.Empty(t, report.Estimates)
+
+	// Statements on analyzed tables are estimated by their rows.
+	buf.Reset()
+	dir, err = migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, dir.WriteFile("1.sql", []byte("CREATE INDEX t_c ON t (c);\nCREATE INDEX u_c ON u (c);")))
+	sum, err := dir.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+	c2, err := sqlclient.Open(ctx, "sqlite://?mode=memory")
+	require.NoError(t, err)
+	defer c2.Close()
+	_, err = c2.ExecContext(ctx, "CREATE TABLE t (c int); CREATE TABLE u (c int); WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 100000) INSERT INTO t SELECT i FROM n; ANALYZE t;")
+	require.NoError(t, err)
+	report, err = (&StatusReporter{Client: c2, Dir: dir, Estimate: true}).Report(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cmdlog.MigrateStatusTemplate.Execute(&buf, report))
+	require.Equal(t, `Migration Status: PENDING
+  -- Current Version: No migration applied yet
+  -- Next Version:    1
+  -- Executed Files:  0
+  -- Pending Files:   1 (estimated 1.02s)
+     1: 1.02s (100000 rows) (1 on tables without statistics)
+`, buf.String())
+}
+
 func TestReporter_FromCheckpoint(t *testing.T) {
 	var (
 		buf strings.Builder
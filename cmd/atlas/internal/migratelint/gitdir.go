@@ -0,0 +1,171 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migratelint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+)
+
+type (
+	// GitDir is a migrate.Dir that is aware of the git base revision of the migration directory,
+	// and exposes which of its files were added or modified compared to it. As a ChangeDetector,
+	// the files preceding the first changed file are considered "base", and are replayed on the
+	// dev database before the changed files. Unchanged files that follow a changed file are
+	// replayed as well to keep the history linear, but only changed files are analyzed.
+	GitDir struct {
+		migrate.Dir
+		git *GitChangeDetector
+	}
+
+	// FileStatus describes the status of a migration file compared to the git base revision.
+	FileStatus uint
+
+	// ChangedFilesDetector is optionally implemented by ChangeDetectors that can report which of
+	// the new files were changed. The Runner analyzes only the changed files, if it is implemented.
+	ChangedFilesDetector interface {
+		ChangedFiles(context.Context) ([]migrate.File, error)
+	}
+)
+
+// List of file statuses.
+const (
+	FileUnchanged FileStatus = iota
+	FileAdded
+	FileModified
+)
+
+// String implements fmt.Stringer.
+func (s FileStatus) String() string {
+	switch s {
+	case FileAdded:
+		return "added"
+	case FileModified:
+		return "modified"
+	default:
+		return "unchanged"
+	}
+}
+
+// NewGitDir wraps the given directory with a GitDir. It accepts the same options as the
+// GitChangeDetector for configuring the git working directory, base revision and the
+// path of the migration directory.
+func NewGitDir(dir migrate.Dir, opts ...GitChangeDetectorOption) (*GitDir, error) {
+	d, err := NewGitChangeDetector(dir, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &GitDir{Dir: dir, git: d}, nil
+}
+
+// Base returns the git base revision of the directory.
+func (d *GitDir) Base() string {
+	return d.git.base
+}
+
+// Status returns the status of the migration files that were changed compared to the base revision.
+// Files that were deleted are not returned, and files that were renamed are considered added.
+func (d *GitDir) Status(ctx context.Context) (map[string]FileStatus, error) {
+	buf, err := d.exec(ctx, "diff", "--name-status", "--diff-filter=AMRC", d.git.base, "HEAD", "--", d.git.path)
+	if err != nil {
+		return nil, err
+	}
+	status := make(map[string]FileStatus)
+	for _, l := range strings.Split(buf, "\n") {
+		parts := strings.Split(l, "\t")
+		if len(parts) < 2 {
+			continue
+		}
+		// Renamed and copied files hold the new path last.
+		name := path.Base(parts[len(parts)-1])
+		switch parts[0][0] {
+		case 'M':
+			status[name] = FileModified
+		default:
+			status[name] = FileAdded
+		}
+	}
+	return status, nil
+}
+
+// ChangedFiles returns the migration files that were added or modified compared to the base revision.
+func (d *GitDir) ChangedFiles(ctx context.Context) ([]migrate.File, error) {
+	status, err := d.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files, err := d.Files()
+	if err != nil {
+		return nil, fmt.Errorf("reading migration directory: %w", err)
+	}
+	var changed []migrate.File
+	for _, f := range files {
+		if status[f.Name()] != FileUnchanged {
+			changed = append(changed, f)
+		}
+	}
+	return changed, nil
+}
+
+// BaseContent returns the content of the named migration file in the base revision.
+func (d *GitDir) BaseContent(ctx context.Context, name string) ([]byte, error) {
+	buf, err := d.exec(ctx, "show", fmt.Sprintf("%s:%s", d.git.base, path.Join(d.git.path, name)))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(buf), nil
+}
+
+// DetectChanges implements the ChangeDetector interface. Every migration file preceding the first changed
+// file is considered old, and the changed file itself and everything thereafter are considered new.
+func (d *GitDir) DetectChanges(ctx context.Context) ([]migrate.File, []migrate.File, error) {
+	status, err := d.Status(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := d.Files()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading migration directory: %w", err)
+	}
+	for i, f := range files {
+		if status[f.Name()] != FileUnchanged {
+			return files[:i], files[i:], nil
+		}
+	}
+	return files, nil, nil
+}
+
+// exec executes the git command with the given arguments in the configured working directory.
+func (d *GitDir) exec(ctx context.Context, cmd string, args ...string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("lookup git: %w", err)
+	}
+	var gitArgs []string
+	if d.git.work != "" {
+		gitArgs = append(gitArgs, "-C", d.git.work)
+	}
+	gitArgs = append(append(gitArgs, "--no-pager", cmd), args...)
+	buf, err := exec.CommandContext(ctx, "git", gitArgs...).Output()
+	if ee := (*exec.ExitError)(nil); errors.As(err, &ee) {
+		return "", fmt.Errorf("git %s: %w: %s", cmd, err, bytes.TrimSpace(ee.Stderr))
+	}
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", cmd, err)
+	}
+	return string(buf), nil
+}
+
+var (
+	_ migrate.Dir          = (*GitDir)(nil)
+	_ ChangeDetector       = (*GitDir)(nil)
+	_ ChangedFilesDetector = (*GitDir)(nil)
+)
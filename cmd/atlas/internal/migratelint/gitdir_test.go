@@ -0,0 +1,101 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migratelint_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/cmd/atlas/internal/migratelint"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitDir(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	mdir := filepath.Join(root, "migrations")
+	require.NoError(t, os.MkdirAll(mdir, 0755))
+	git := func(args ...string) {
+		out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(mdir, name), []byte(content), 0644))
+	}
+	git("init")
+	git("config", "user.name", "a8m")
+	git("config", "user.email", "a8m@atlasgo.io")
+	git("checkout", "-b", "master")
+	write("1.sql", "CREATE TABLE users (id INT);")
+	write("2.sql", "CREATE TABLE pets (id INT);")
+	write("3.sql", "CREATE TABLE t3 (id INT);")
+	git("add", ".")
+	git("commit", "-m", "applied migrations")
+	git("checkout", "-b", "feature")
+	write("2.sql", "CREATE TABLE pets (id INT, name TEXT);")
+	write("4.sql", "DROP TABLE t3;")
+	git("add", ".")
+	git("commit", "-m", "changed migrations")
+
+	local, err := migrate.NewLocalDir(mdir)
+	require.NoError(t, err)
+	dir, err := migratelint.NewGitDir(local, migratelint.WithWorkDir(root))
+	require.NoError(t, err)
+	require.Equal(t, "master", dir.Base())
+	status, err := dir.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]migratelint.FileStatus{"2.sql": migratelint.FileModified, "4.sql": migratelint.FileAdded}, status)
+
+	changed, err := dir.ChangedFiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, changed, 2)
+	require.Equal(t, "2.sql", changed[0].Name())
+	require.Equal(t, "4.sql", changed[1].Name())
+	base, feat, err := dir.DetectChanges(ctx)
+	require.NoError(t, err)
+	require.Len(t, base, 1)
+	require.Len(t, feat, 3)
+	require.Equal(t, "1.sql", base[0].Name())
+	require.Equal(t, "2.sql", feat[0].Name())
+
+	b, err := dir.BaseContent(ctx, "2.sql")
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE pets (id INT);", string(b))
+	_, err = dir.BaseContent(ctx, "4.sql")
+	require.Error(t, err)
+
+	// Only changed files are analyzed, after the unchanged ones were replayed.
+	c, err := sqlclient.Open(ctx, "sqlite://gitdir?mode=memory&_fk=1")
+	require.NoError(t, err)
+	defer c.Close()
+	az := &testAnalyzer{}
+	r := &migratelint.Runner{
+		Dir:            dir,
+		Dev:            c,
+		ChangeDetector: dir,
+		Analyzers:      []sqlcheck.Analyzer{az},
+		ReportWriter:   &migratelint.TemplateWriter{T: migratelint.DefaultTemplate, W: io.Discard},
+	}
+	require.NoError(t, r.Run(ctx))
+	require.Len(t, az.passes, 2)
+	require.Equal(t, "2.sql", az.passes[0].File.Name())
+	require.Equal(t, "4.sql", az.passes[1].File.Name())
+
+	// No changes compared to the base.
+	dir, err = migratelint.NewGitDir(local, migratelint.WithWorkDir(root), migratelint.WithBase("feature"))
+	require.NoError(t, err)
+	base, feat, err = dir.DetectChanges(ctx)
+	require.NoError(t, err)
+	require.Len(t, base, 4)
+	require.Empty(t, feat)
+}
@@ -15,6 +15,7 @@ import (
 	"text/template"
 
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlcheck"
 	"ariga.io/atlas/sql/sqlclient"
 
@@ -37,6 +38,10 @@ type Runner struct {
 	// Analyzers defines the analysis to be run in the CI job.
 	Analyzers []sqlcheck.Analyzer
 
+	// Stats optionally holds the statistics of the tables in the target
+	// database, and is passed to analyzers that take table sizes into account.
+	Stats *schema.Realm
+
 	// ReportWriter writes the summary report.
 	ReportWriter ReportWriter
 
@@ -119,8 +124,26 @@ func (r *Runner) summary(ctx context.Context) error {
 	r.sum.StepResult(StepLoadChanges, fmt.Sprintf("Loaded %d changes on dev database", len(diff.Files)), nil)
 	r.sum.WriteSchema(r.Dev, diff)
 
-	// Analyze files.
-	return r.analyze(ctx, diff.Files)
+	// Analyze files. If the detector can tell which of the new files were
+	// changed, the rest were replayed only as context for the changed ones.
+	files := diff.Files
+	if c, ok := r.ChangeDetector.(ChangedFilesDetector); ok {
+		changed, err := c.ChangedFiles(ctx)
+		if err != nil {
+			return r.sum.StepError(StepDetectChanges, "Failed find changed migration files", err)
+		}
+		names := make(map[string]bool, len(changed))
+		for _, f := range changed {
+			names[f.Name()] = true
+		}
+		files = make([]*sqlcheck.File, 0, len(changed))
+		for _, f := range diff.Files {
+			if names[f.Name()] {
+				files = append(files, f)
+			}
+		}
+	}
+	return r.analyze(ctx, files)
 }
 
 // analyze runs the analysis on the given files.
@@ -139,6 +162,7 @@ func (r *Runner) analyze(ctx context.Context, files []*sqlcheck.File) error {
 				File:     f,
 				Dev:      r.Dev,
 				Reporter: nl.reporterFor(fr, az),
+				Stats:    r.Stats,
 			})
 			// If the last report was skipped,
 			// skip emitting its error.
@@ -0,0 +1,489 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package server exposes the inspection, diffing, linting and apply operations of Atlas as
+// authenticated HTTP endpoints, allowing platform teams to run Atlas as a centralized service.
+// Note, only the HTTP (JSON) API is provided, and there is no gRPC counterpart.
+//
+// All endpoints accept a JSON body using the POST method, require authentication, and operate
+// only on the database URLs that match the allowlist of the server:
+//
+//	POST /v1/inspect	inspects a database and returns its HCL representation.
+//	POST /v1/diff		computes the plan between a database and a desired state.
+//	POST /v1/lint		lints a set of migration files using a dev database.
+//	POST /v1/apply		applies a desired state, and streams the logs as JSON lines.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	cmdmigrate "ariga.io/atlas/cmd/atlas/internal/migrate"
+	"ariga.io/atlas/cmd/atlas/internal/migratelint"
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlclient"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+type (
+	// Server is an http.Handler that serves the Atlas API.
+	Server struct {
+		auth      Authenticator
+		open      func(context.Context, string) (*sqlclient.Client, error)
+		revisions func(context.Context, *sqlclient.Client) (migrate.RevisionReadWriter, error)
+		allowed   []string
+		maxBody   int64
+		mux       *http.ServeMux
+	}
+
+	// Option configures the Server.
+	Option func(*Server)
+
+	// Authenticator authenticates incoming requests.
+	Authenticator interface {
+		// Authenticate returns an error if the request is not authenticated.
+		Authenticate(*http.Request) error
+	}
+
+	// AuthenticatorFunc allows using an ordinary function as an Authenticator.
+	AuthenticatorFunc func(*http.Request) error
+)
+
+// Authenticate implements the Authenticator interface.
+func (f AuthenticatorFunc) Authenticate(r *http.Request) error {
+	return f(r)
+}
+
+// BearerTokens returns an Authenticator that accepts requests with
+// an "Authorization: Bearer <token>" header of one of the given tokens.
+func BearerTokens(tokens ...string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		t, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || t == "" {
+			return errors.New("missing bearer token")
+		}
+		for _, v := range tokens {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(v)) == 1 {
+				return nil
+			}
+		}
+		return errors.New("invalid bearer token")
+	})
+}
+
+// WithOpener configures the function used for opening database
+// connections. Defaults to sqlclient.Open.
+func WithOpener(open func(context.Context, string) (*sqlclient.Client, error)) Option {
+	return func(s *Server) {
+		s.open = open
+	}
+}
+
+// WithAllowedURLs sets the patterns of the database URLs the server may connect to. A pattern
+// is matched using path.Match against the URL without its user info and query, for example,
+// "postgres://*.staging.internal:5432/*". At least one pattern is required.
+func WithAllowedURLs(patterns ...string) Option {
+	return func(s *Server) {
+		s.allowed = append(s.allowed, patterns...)
+	}
+}
+
+// WithMaxBodySize sets the maximum size of request bodies in bytes. Defaults to 4MB.
+func WithMaxBodySize(n int64) Option {
+	return func(s *Server) {
+		s.maxBody = n
+	}
+}
+
+// WithRevisions configures the function that returns the revisions read-writer used for
+// recording applies. Defaults to a revisions table in the "atlas_server_revisions" schema.
+// A nil read-writer disables the recording.
+func WithRevisions(f func(context.Context, *sqlclient.Client) (migrate.RevisionReadWriter, error)) Option {
+	return func(s *Server) {
+		s.revisions = f
+	}
+}
+
+// New returns a new Server that authenticates requests using the given Authenticator.
+func New(auth Authenticator, opts ...Option) (*Server, error) {
+	if auth == nil {
+		return nil, errors.New("server: missing authenticator")
+	}
+	s := &Server{
+		auth: auth,
+		open: func(ctx context.Context, u string) (*sqlclient.Client, error) {
+			return sqlclient.Open(ctx, u)
+		},
+		revisions: revisions,
+		maxBody:   defaultMaxBody,
+		mux:       http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if len(s.allowed) == 0 {
+		return nil, errors.New("server: missing allowed database urls")
+	}
+	for _, p := range s.allowed {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("server: invalid url pattern %q: %w", p, err)
+		}
+	}
+	if s.maxBody <= 0 {
+		return nil, errors.New("server: max body size must be positive")
+	}
+	s.mux.Handle("/v1/inspect", handle(s.inspect))
+	s.mux.Handle("/v1/diff", handle(s.diff))
+	s.mux.Handle("/v1/lint", handle(s.lint))
+	s.mux.HandleFunc("/v1/apply", s.apply)
+	return s, nil
+}
+
+const (
+	// defaultMaxBody is the default limit of request bodies.
+	defaultMaxBody = 4 << 20
+	// revisionSchema is the schema of the revisions table used for recording applies.
+	revisionSchema = "atlas_server_revisions"
+	// applyLockValue is the name of the lock that is held during applies. It is shared
+	// with "atlas migrate apply", to prevent concurrent executions on the same database.
+	applyLockValue = "atlas_migrate_execute"
+	// lockTimeout is the time to wait for the database lock.
+	lockTimeout = 10 * time.Second
+)
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method != http.MethodPost:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+	default:
+		if err := s.auth.Authenticate(r); err != nil {
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBody)
+		s.mux.ServeHTTP(w, r)
+	}
+}
+
+type (
+	// InspectRequest is the body of the inspect endpoint.
+	InspectRequest struct {
+		URL     string   `json:"url"`
+		Schemas []string `json:"schemas,omitempty"`
+	}
+
+	// DiffRequest is the body of the diff endpoint.
+	DiffRequest struct {
+		URL     string            `json:"url"`
+		Desired string            `json:"desired"`
+		Vars    map[string]string `json:"vars,omitempty"`
+	}
+
+	// LintRequest is the body of the lint endpoint.
+	LintRequest struct {
+		DevURL string            `json:"dev_url"`
+		Files  map[string]string `json:"files"`
+		Latest int               `json:"latest,omitempty"` // Defaults to 1.
+	}
+
+	// ApplyRequest is the body of the apply endpoint.
+	ApplyRequest struct {
+		DiffRequest
+		DryRun bool `json:"dry_run,omitempty"`
+	}
+
+	// LogEvent is a line in the log stream of the apply endpoint.
+	LogEvent struct {
+		Type    string                    `json:"type"` // plan, stmt, error or done.
+		Stmt    *sqlclient.Stmt           `json:"stmt,omitempty"`
+		Objects []*sqlclient.ObjectChange `json:"objects,omitempty"`
+		Pending int                       `json:"pending,omitempty"`
+		Applied int                       `json:"applied,omitempty"`
+		Error   string                    `json:"error,omitempty"`
+	}
+)
+
+// List of log event types.
+const (
+	EventPlan  = "plan"
+	EventStmt  = "stmt"
+	EventError = "error"
+	EventDone  = "done"
+)
+
+// connect opens a client to the given database URL, if it is allowed by the server.
+func (s *Server) connect(ctx context.Context, u string) (*sqlclient.Client, error) {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+	target := (&url.URL{Scheme: pu.Scheme, Host: pu.Host, Path: pu.Path, Opaque: pu.Opaque}).String()
+	for _, p := range s.allowed {
+		if ok, _ := path.Match(p, target); ok {
+			return s.open(ctx, u)
+		}
+	}
+	return nil, &forbiddenError{target: target}
+}
+
+// forbiddenError is returned for database URLs that are not allowed by the server.
+type forbiddenError struct{ target string }
+
+func (e *forbiddenError) Error() string {
+	return fmt.Sprintf("database url %q is not allowed", e.target)
+}
+
+func (s *Server) inspect(ctx context.Context, req *InspectRequest) (*sqlclient.State, error) {
+	c, err := s.connect(ctx, req.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.ReadState(ctx, req.Schemas...)
+}
+
+func (s *Server) diff(ctx context.Context, req *DiffRequest) (*sqlclient.StatePlan, error) {
+	c, err := s.connect(ctx, req.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.PlanState(ctx, []byte(req.Desired), sqlclient.ReconcileVars(vars(req.Vars)))
+}
+
+func (s *Server) lint(ctx context.Context, req *LintRequest) (*migratelint.SummaryReport, error) {
+	if len(req.Files) == 0 {
+		return nil, errors.New("no migration files to lint")
+	}
+	dev, err := s.connect(ctx, req.DevURL)
+	if err != nil {
+		return nil, err
+	}
+	defer dev.Close()
+	dir := &migrate.MemDir{}
+	for name, content := range req.Files {
+		if err := dir.WriteFile(name, []byte(content)); err != nil {
+			return nil, err
+		}
+	}
+	sum, err := dir.Checksum()
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate.WriteSumFile(dir, sum); err != nil {
+		return nil, err
+	}
+	az, err := sqlcheck.AnalyzerFor(dev.Name, &schemahcl.Resource{})
+	if err != nil {
+		return nil, err
+	}
+	latest := req.Latest
+	if latest <= 0 {
+		latest = 1
+	}
+	var report *migratelint.SummaryReport
+	r := &migratelint.Runner{
+		Dev:            dev,
+		Dir:            dir,
+		Analyzers:      az,
+		ChangeDetector: migratelint.LatestChanges(dir, latest),
+		ReportWriter: reportWriter(func(r *migratelint.SummaryReport) error {
+			report = r
+			return nil
+		}),
+	}
+	if err := r.Run(ctx); err != nil && !errors.As(err, &migratelint.SilentError{}) {
+		return nil, err
+	}
+	if report == nil {
+		return nil, errors.New("empty lint report")
+	}
+	// Credentials of the dev database are not exposed.
+	report.Env.URL = nil
+	return report, nil
+}
+
+// apply applies the desired state, and streams the executed statements as JSON lines. The database
+// lock is held during the apply, and the execution is recorded as a revision of the database.
+func (s *Server) apply(w http.ResponseWriter, r *http.Request) {
+	var (
+		req ApplyRequest
+		ctx = r.Context()
+	)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, statusOf(err, http.StatusBadRequest), err)
+		return
+	}
+	c, err := s.connect(ctx, req.URL)
+	if err != nil {
+		writeError(w, statusOf(err, http.StatusInternalServerError), err)
+		return
+	}
+	defer c.Close()
+	if l, ok := c.Driver.(schema.Locker); ok && !req.DryRun {
+		unlock, err := l.Lock(ctx, applyLockValue, lockTimeout)
+		if err != nil {
+			writeError(w, http.StatusConflict, fmt.Errorf("acquiring database lock: %w", err))
+			return
+		}
+		defer unlock()
+	}
+	plan, err := c.PlanState(ctx, []byte(req.Desired), sqlclient.ReconcileVars(vars(req.Vars)))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var rrw migrate.RevisionReadWriter
+	if !req.DryRun && len(plan.Stmts) > 0 {
+		if rrw, err = s.revisions(ctx, c); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("open revisions: %w", err))
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	var (
+		enc   = json.NewEncoder(w)
+		f, _  = w.(http.Flusher)
+		write = func(e *LogEvent) {
+			// Errors are ignored, as they can be caused
+			// only by clients that closed the connection.
+			_ = enc.Encode(e)
+			if f != nil {
+				f.Flush()
+			}
+		}
+	)
+	write(&LogEvent{Type: EventPlan, Objects: plan.Objects, Pending: len(plan.Stmts)})
+	if req.DryRun {
+		for _, stmt := range plan.Stmts {
+			write(&LogEvent{Type: EventStmt, Stmt: stmt})
+		}
+		write(&LogEvent{Type: EventDone, Pending: len(plan.Stmts)})
+		return
+	}
+	rev := &migrate.Revision{
+		Version:         time.Now().UTC().Format("20060102150405"),
+		Description:     "server_apply",
+		Type:            migrate.RevisionTypeExecute,
+		Total:           len(plan.Stmts),
+		ExecutedAt:      time.Now(),
+		OperatorVersion: "Atlas Server",
+	}
+	record := func() {
+		if rrw == nil {
+			return
+		}
+		rev.ExecutionTime = time.Since(rev.ExecutedAt)
+		// The revision is recorded even if the request was canceled.
+		if err := rrw.WriteRevision(context.WithoutCancel(ctx), rev); err != nil {
+			write(&LogEvent{Type: EventError, Error: fmt.Sprintf("write revision: %v", err), Applied: rev.Applied})
+		}
+	}
+	for i, stmt := range plan.Stmts {
+		if _, err := c.ExecContext(ctx, stmt.Cmd, stmt.Args...); err != nil {
+			rev.Error, rev.ErrorStmt = err.Error(), stmt.Cmd
+			record()
+			write(&LogEvent{Type: EventError, Stmt: stmt, Error: err.Error(), Applied: i, Pending: len(plan.Stmts) - i})
+			return
+		}
+		rev.Applied++
+		write(&LogEvent{Type: EventStmt, Stmt: stmt, Applied: i + 1})
+	}
+	record()
+	write(&LogEvent{Type: EventDone, Applied: len(plan.Stmts)})
+}
+
+// revisions returns the default revisions read-writer of the server. SQLite databases hold a
+// single schema, where the revisions table is shared with versioned migrations, and therefore,
+// applies are not recorded on them.
+func revisions(ctx context.Context, c *sqlclient.Client) (migrate.RevisionReadWriter, error) {
+	if c.Name == sqlite.DriverName {
+		return nil, nil
+	}
+	rrw, err := cmdmigrate.RevisionsForClient(ctx, c, revisionSchema)
+	if err != nil {
+		return nil, err
+	}
+	if err := rrw.Migrate(ctx); err != nil {
+		return nil, err
+	}
+	return rrw, nil
+}
+
+// handle returns an http.Handler that decodes the request body
+// to the request type, and encodes the result of the function.
+func handle[Req, Resp any](fn func(context.Context, *Req) (Resp, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, statusOf(err, http.StatusBadRequest), err)
+			return
+		}
+		resp, err := fn(r.Context(), &req)
+		if err != nil {
+			writeError(w, statusOf(err, http.StatusInternalServerError), err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// statusOf returns the HTTP status code of the given error, or the default code.
+func statusOf(err error, code int) int {
+	var (
+		fe *forbiddenError
+		me *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &fe):
+		return http.StatusForbidden
+	case errors.As(err, &me):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return code
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+}
+
+// vars converts the request variables to input values.
+func vars(m map[string]string) map[string]cty.Value {
+	if len(m) == 0 {
+		return nil
+	}
+	vs := make(map[string]cty.Value, len(m))
+	for k, v := range m {
+		vs[k] = cty.StringVal(v)
+	}
+	return vs
+}
+
+// reportWriter implements the migratelint.ReportWriter interface.
+type reportWriter func(*migratelint.SummaryReport) error
+
+// WriteReport implements the WriteReport method.
+func (f reportWriter) WriteReport(r *migratelint.SummaryReport) error {
+	return f(r)
+}
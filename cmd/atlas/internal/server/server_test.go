@@ -0,0 +1,176 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package server_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/cmd/atlas/internal/server"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+	_ "ariga.io/atlas/sql/sqlite"
+	_ "ariga.io/atlas/sql/sqlite/sqlitecheck"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+const desired = `
+schema "main" {}
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+}
+`
+
+func TestServer(t *testing.T) {
+	_, err := server.New(nil)
+	require.Error(t, err)
+	_, err = server.New(server.BearerTokens("secret"))
+	require.EqualError(t, err, "server: missing allowed database urls")
+	_, err = server.New(server.BearerTokens("secret"), server.WithAllowedURLs("sqlite://["))
+	require.EqualError(t, err, `server: invalid url pattern "sqlite://[": syntax error in pattern`)
+	rrw := &revisions{}
+	srv, err := server.New(
+		server.BearerTokens("secret"),
+		server.WithAllowedURLs("sqlite://server", "sqlite://lint"),
+		server.WithMaxBodySize(1<<10),
+		server.WithRevisions(func(context.Context, *sqlclient.Client) (migrate.RevisionReadWriter, error) {
+			return rrw, nil
+		}),
+	)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	const url = "sqlite://server?mode=memory&cache=shared&_fk=1"
+	// Keep the shared in-memory database alive between requests.
+	c, err := sqlclient.Open(context.Background(), url)
+	require.NoError(t, err)
+	defer c.Close()
+
+	post := func(path, token string, body any) *http.Response {
+		buf, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, ts.URL+path, bytes.NewReader(buf))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	// Authentication.
+	resp := post("/v1/inspect", "", server.InspectRequest{URL: url})
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = post("/v1/inspect", "invalid", server.InspectRequest{URL: url})
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp, err = http.Get(ts.URL + "/v1/inspect")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	// Diff.
+	resp = post("/v1/diff", "secret", server.DiffRequest{URL: url, Desired: desired})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var plan sqlclient.StatePlan
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&plan))
+	require.Len(t, plan.Stmts, 1)
+	require.Equal(t, "CREATE TABLE `users` (`id` int NOT NULL)", plan.Stmts[0].Cmd)
+
+	// Apply streams the logs.
+	resp = post("/v1/apply", "secret", server.ApplyRequest{DiffRequest: server.DiffRequest{URL: url, Desired: desired}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	var events []*server.LogEvent
+	for s := bufio.NewScanner(resp.Body); s.Scan(); {
+		var e server.LogEvent
+		require.NoError(t, json.Unmarshal(s.Bytes(), &e))
+		events = append(events, &e)
+	}
+	require.Len(t, events, 3)
+	require.Equal(t, server.EventPlan, events[0].Type)
+	require.Equal(t, 1, events[0].Pending)
+	require.Equal(t, server.EventStmt, events[1].Type)
+	require.Equal(t, plan.Stmts[0].Cmd, events[1].Stmt.Cmd)
+	require.Equal(t, server.EventDone, events[2].Type)
+	require.Equal(t, 1, events[2].Applied)
+	// Applies are recorded as revisions.
+	require.Len(t, rrw.revs, 1)
+	require.Equal(t, migrate.RevisionTypeExecute, rrw.revs[0].Type)
+	require.Equal(t, 1, rrw.revs[0].Applied)
+	require.Equal(t, 1, rrw.revs[0].Total)
+	require.Empty(t, rrw.revs[0].Error)
+
+	// Applies wait for the database lock.
+	unlock, err := c.Driver.(schema.Locker).Lock(context.Background(), "atlas_migrate_execute", time.Minute)
+	require.NoError(t, err)
+	resp = post("/v1/apply", "secret", server.ApplyRequest{DiffRequest: server.DiffRequest{URL: url, Desired: desired}})
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+	require.NoError(t, unlock())
+
+	// Inspect.
+	resp = post("/v1/inspect", "secret", server.InspectRequest{URL: url})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var state sqlclient.State
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&state))
+	require.Contains(t, state.HCL, `table "users"`)
+	require.Contains(t, state.Objects, "column/main/users/id")
+
+	// Lint.
+	resp = post("/v1/lint", "secret", server.LintRequest{
+		DevURL: "sqlite://lint?mode=memory&_fk=1",
+		Files: map[string]string{
+			"1.sql": "CREATE TABLE users (id int);",
+			"2.sql": "DROP TABLE users;",
+		},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var report struct {
+		Files []struct {
+			Name    string
+			Reports []struct{ Text string }
+		}
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	require.Len(t, report.Files, 1)
+	require.Equal(t, "2.sql", report.Files[0].Name)
+	require.Equal(t, "destructive changes detected", report.Files[0].Reports[0].Text)
+
+	// Errors.
+	resp = post("/v1/diff", "secret", server.DiffRequest{URL: "unknown://"})
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	var e struct{ Error string }
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+	require.Equal(t, `database url "unknown:" is not allowed`, e.Error)
+	resp = post("/v1/apply", "secret", server.ApplyRequest{DiffRequest: server.DiffRequest{URL: "sqlite://other?mode=memory", Desired: desired}})
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = post("/v1/diff", "secret", server.DiffRequest{URL: url, Desired: strings.Repeat(" ", 1<<10)})
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+// revisions is a migrate.RevisionReadWriter that records the written revisions.
+type revisions struct {
+	migrate.RevisionReadWriter
+	revs []*migrate.Revision
+}
+
+func (r *revisions) WriteRevision(_ context.Context, rev *migrate.Revision) error {
+	r.revs = append(r.revs, rev)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"ariga.io/atlas/cmd/atlas/internal/sqlparse/myparse"
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/parseutil"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
 
@@ -439,3 +440,84 @@ type mockDriver struct {
 func (d mockDriver) TableDiff(_, _ *schema.Table, _ ...schema.DiffOption) ([]schema.Change, error) {
 	return d.changes, nil
 }
+
+func TestParser_ParseStmt(t *testing.T) {
+	var p myparse.Parser
+	parse := func(s string) schema.Changes {
+		changes, err := p.ParseStmt(&migrate.Stmt{Text: s})
+		require.NoError(t, err)
+		return changes
+	}
+	changes := parse("CREATE TABLE `s`.`users` (`id` int NOT NULL AUTO_INCREMENT, `name` varchar(255) DEFAULT 'a', PRIMARY KEY (`id`), UNIQUE INDEX `name` (`name`))")
+	require.Len(t, changes, 1)
+	users := changes[0].(*schema.AddTable).T
+	require.Equal(t, "users", users.Name)
+	require.Equal(t, "s", users.Schema.Name)
+	require.Len(t, users.Columns, 2)
+	require.Equal(t, &schema.IntegerType{T: "int"}, users.Columns[0].Type.Type)
+	require.False(t, users.Columns[0].Type.Null)
+	require.Equal(t, &schema.StringType{T: "varchar", Size: 255}, users.Columns[1].Type.Type)
+	require.True(t, users.Columns[1].Type.Null)
+	require.Equal(t, &schema.RawExpr{X: "'a'"}, users.Columns[1].Default)
+	require.Equal(t, users.Columns[0], users.PrimaryKey.Parts[0].C)
+	require.Equal(t, "name", users.Indexes[0].Name)
+	require.True(t, users.Indexes[0].Unique)
+	require.Equal(t, users.Columns[1], users.Indexes[0].Parts[0].C)
+
+	changes = parse("ALTER TABLE `users` ADD COLUMN `age` int NULL, DROP COLUMN `name`, RENAME COLUMN `a` TO `b`, ADD INDEX `age` (`age`), DROP INDEX `name`, RENAME TO `people`")
+	require.Len(t, changes, 2)
+	m := changes[0].(*schema.ModifyTable)
+	require.Equal(t, "users", m.T.Name)
+	require.Len(t, m.Changes, 5)
+	require.Equal(t, "age", m.Changes[0].(*schema.AddColumn).C.Name)
+	require.Equal(t, "name", m.Changes[1].(*schema.DropColumn).C.Name)
+	require.Equal(t, "b", m.Changes[2].(*schema.RenameColumn).To.Name)
+	require.Equal(t, "age", m.Changes[3].(*schema.AddIndex).I.Name)
+	require.Equal(t, "name", m.Changes[4].(*schema.DropIndex).I.Name)
+	require.Equal(t, "people", changes[1].(*schema.RenameTable).To.Name)
+
+	changes = parse("ALTER TABLE `users` CHANGE `a` `b` bigint NOT NULL")
+	require.Len(t, changes[0].(*schema.ModifyTable).Changes, 2)
+	require.Equal(t, "a", changes[0].(*schema.ModifyTable).Changes[0].(*schema.RenameColumn).From.Name)
+	require.Equal(t, &schema.IntegerType{T: "bigint"}, changes[0].(*schema.ModifyTable).Changes[1].(*schema.ModifyColumn).To.Type.Type)
+
+	changes = parse("CREATE UNIQUE INDEX `idx` ON `users` (`a`, (`b` + 1))")
+	idx := changes[0].(*schema.ModifyTable).Changes[0].(*schema.AddIndex).I
+	require.True(t, idx.Unique)
+	require.Equal(t, "a", idx.Parts[0].C.Name)
+	require.Equal(t, &schema.RawExpr{X: "`b`+1"}, idx.Parts[1].X)
+
+	changes = parse("DROP TABLE `t1`, `t2`")
+	require.Equal(t, schema.Changes{&schema.DropTable{T: schema.NewTable("t1")}, &schema.DropTable{T: schema.NewTable("t2")}}, changes)
+	changes = parse("RENAME TABLE `t1` TO `t2`")
+	require.Equal(t, schema.Changes{&schema.RenameTable{From: schema.NewTable("t1"), To: schema.NewTable("t2")}}, changes)
+	changes = parse("CREATE DATABASE `s`")
+	require.Equal(t, schema.Changes{&schema.AddSchema{S: schema.New("s")}}, changes)
+	require.Empty(t, parse("INSERT INTO `users` (`id`) VALUES (1)"))
+
+	_, err := p.ParseStmt(&migrate.Stmt{Text: "CREATE TABLE"})
+	require.Error(t, err)
+}
+
+func TestParser_TableRefs(t *testing.T) {
+	var p myparse.Parser
+	refs, err := p.TableRefs("WITH c AS (SELECT id FROM `t1`) SELECT * FROM c JOIN `s`.`t2` ON c.id = t2.id WHERE c.id IN (SELECT id FROM v1)")
+	require.NoError(t, err)
+	require.Equal(t, []*parseutil.TableRef{{Name: "t1"}, {Schema: "s", Name: "t2"}, {Name: "v1"}}, refs)
+	// CTEs shadow tables only in their scope.
+	refs, err = p.TableRefs("WITH users AS (SELECT * FROM users WHERE active) SELECT * FROM users JOIN posts USING (id)")
+	require.NoError(t, err)
+	require.Equal(t, []*parseutil.TableRef{{Name: "users"}, {Name: "posts"}}, refs)
+	refs, err = p.TableRefs("WITH a AS (SELECT * FROM b), b AS (SELECT * FROM a) SELECT * FROM b")
+	require.NoError(t, err)
+	require.Equal(t, []*parseutil.TableRef{{Name: "b"}}, refs)
+	refs, err = p.TableRefs("WITH RECURSIVE tree AS (SELECT id FROM nodes UNION ALL SELECT n.id FROM nodes n JOIN tree t ON n.parent = t.id) SELECT * FROM tree")
+	require.NoError(t, err)
+	require.Equal(t, []*parseutil.TableRef{{Name: "nodes"}, {Name: "nodes"}}, refs)
+	// Nested WITH clauses.
+	refs, err = p.TableRefs("SELECT * FROM (WITH x AS (SELECT * FROM t1) SELECT * FROM x) AS s JOIN x USING (id)")
+	require.NoError(t, err)
+	require.Equal(t, []*parseutil.TableRef{{Name: "t1"}, {Name: "x"}}, refs)
+	_, err = p.TableRefs("SELECT * FROM")
+	require.Error(t, err)
+}
@@ -0,0 +1,351 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package myparse
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/parseutil"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/format"
+	"github.com/pingcap/tidb/parser/test_driver"
+)
+
+// ParseStmt parses the statement and returns the schema changes it describes. Statements
+// that do not change the schema (e.g. INSERT) or are not supported, return no changes.
+func (p *Parser) ParseStmt(s *migrate.Stmt) (schema.Changes, error) {
+	stmt, err := parser.New().ParseOneStmt(s.Text, "", "")
+	if err != nil {
+		return nil, err
+	}
+	switch stmt := stmt.(type) {
+	case *ast.CreateDatabaseStmt:
+		return schema.Changes{&schema.AddSchema{S: schema.New(stmt.Name.O)}}, nil
+	case *ast.DropDatabaseStmt:
+		return schema.Changes{&schema.DropSchema{S: schema.New(stmt.Name.O)}}, nil
+	case *ast.CreateTableStmt:
+		t := table(stmt.Table)
+		for _, d := range stmt.Cols {
+			c, err := column(t, d)
+			if err != nil {
+				return nil, err
+			}
+			t.AddColumns(c)
+		}
+		for _, c := range stmt.Constraints {
+			constraint(t, c, func(c schema.Change) {
+				switch c := c.(type) {
+				case *schema.AddPrimaryKey:
+					t.SetPrimaryKey(c.P)
+				case *schema.AddIndex:
+					t.AddIndexes(c.I)
+				case *schema.AddForeignKey:
+					t.AddForeignKeys(c.F)
+				case *schema.AddCheck:
+					t.AddChecks(c.C)
+				}
+			})
+		}
+		return schema.Changes{&schema.AddTable{T: t}}, nil
+	case *ast.DropTableStmt:
+		var changes schema.Changes
+		for _, n := range stmt.Tables {
+			if stmt.IsView {
+				changes = append(changes, &schema.DropView{V: view(n)})
+			} else {
+				changes = append(changes, &schema.DropTable{T: table(n)})
+			}
+		}
+		return changes, nil
+	case *ast.RenameTableStmt:
+		var changes schema.Changes
+		for _, r := range stmt.TableToTables {
+			changes = append(changes, &schema.RenameTable{From: table(r.OldTable), To: table(r.NewTable)})
+		}
+		return changes, nil
+	case *ast.CreateViewStmt:
+		return schema.Changes{&schema.AddView{V: view(stmt.ViewName)}}, nil
+	case *ast.CreateIndexStmt:
+		t := table(stmt.Table)
+		idx := schema.NewIndex(stmt.IndexName).SetUnique(stmt.KeyType == ast.IndexKeyTypeUnique)
+		parts(t, idx, stmt.IndexPartSpecifications)
+		return schema.Changes{&schema.ModifyTable{T: t, Changes: schema.Changes{&schema.AddIndex{I: idx}}}}, nil
+	case *ast.DropIndexStmt:
+		t := table(stmt.Table)
+		return schema.Changes{&schema.ModifyTable{T: t, Changes: schema.Changes{&schema.DropIndex{I: schema.NewIndex(stmt.IndexName)}}}}, nil
+	case *ast.AlterTableStmt:
+		return alterTable(stmt)
+	}
+	return nil, nil
+}
+
+// alterTable returns the changes described by the ALTER TABLE statement.
+func alterTable(stmt *ast.AlterTableStmt) (schema.Changes, error) {
+	var (
+		t      = table(stmt.Table)
+		modify = &schema.ModifyTable{T: t}
+		rename *schema.RenameTable
+		add    = func(c schema.Change) { modify.Changes = append(modify.Changes, c) }
+	)
+	for _, s := range stmt.Specs {
+		switch s.Tp {
+		case ast.AlterTableAddColumns:
+			for _, d := range s.NewColumns {
+				c, err := column(t, d)
+				if err != nil {
+					return nil, err
+				}
+				add(&schema.AddColumn{C: c})
+			}
+		case ast.AlterTableDropColumn:
+			add(&schema.DropColumn{C: schema.NewColumn(s.OldColumnName.Name.O)})
+		case ast.AlterTableRenameColumn:
+			add(&schema.RenameColumn{From: schema.NewColumn(s.OldColumnName.Name.O), To: schema.NewColumn(s.NewColumnName.Name.O)})
+		case ast.AlterTableModifyColumn, ast.AlterTableChangeColumn:
+			to, err := column(t, s.NewColumns[0])
+			if err != nil {
+				return nil, err
+			}
+			from := schema.NewColumn(to.Name)
+			if s.OldColumnName != nil {
+				from.Name = s.OldColumnName.Name.O
+			}
+			if from.Name != to.Name {
+				add(&schema.RenameColumn{From: from, To: schema.NewColumn(to.Name)})
+			}
+			add(&schema.ModifyColumn{From: from, To: to, Change: schema.ChangeType | schema.ChangeNull | schema.ChangeDefault})
+		case ast.AlterTableAddConstraint:
+			constraint(t, s.Constraint, add)
+		case ast.AlterTableDropIndex:
+			add(&schema.DropIndex{I: schema.NewIndex(s.Name)})
+		case ast.AlterTableRenameIndex:
+			add(&schema.RenameIndex{From: schema.NewIndex(s.FromKey.O), To: schema.NewIndex(s.ToKey.O)})
+		case ast.AlterTableDropPrimaryKey:
+			add(&schema.DropPrimaryKey{P: schema.NewPrimaryKey()})
+		case ast.AlterTableDropForeignKey:
+			add(&schema.DropForeignKey{F: schema.NewForeignKey(s.Name)})
+		case ast.AlterTableDropCheck:
+			add(&schema.DropCheck{C: schema.NewCheck().SetName(s.Constraint.Name)})
+		case ast.AlterTableRenameTable:
+			rename = &schema.RenameTable{From: schema.NewTable(t.Name), To: table(s.NewTable)}
+		}
+	}
+	var changes schema.Changes
+	if len(modify.Changes) > 0 {
+		changes = append(changes, modify)
+	}
+	if rename != nil {
+		changes = append(changes, rename)
+	}
+	return changes, nil
+}
+
+// constraint passes the change described by the constraint definition to the add function.
+func constraint(t *schema.Table, c *ast.Constraint, add func(schema.Change)) {
+	switch c.Tp {
+	case ast.ConstraintPrimaryKey:
+		pk := schema.NewPrimaryKey()
+		parts(t, pk, c.Keys)
+		add(&schema.AddPrimaryKey{P: pk})
+	case ast.ConstraintKey, ast.ConstraintIndex, ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex, ast.ConstraintFulltext:
+		idx := schema.NewIndex(c.Name).SetUnique(c.Tp == ast.ConstraintUniq || c.Tp == ast.ConstraintUniqKey || c.Tp == ast.ConstraintUniqIndex)
+		parts(t, idx, c.Keys)
+		add(&schema.AddIndex{I: idx})
+	case ast.ConstraintForeignKey:
+		fk := schema.NewForeignKey(c.Name).SetTable(t)
+		for _, k := range c.Keys {
+			if k.Column != nil {
+				fk.AddColumns(column2(t, k.Column.Name.O))
+			}
+		}
+		if c.Refer != nil {
+			ref := table(c.Refer.Table)
+			fk.SetRefTable(ref)
+			for _, k := range c.Refer.IndexPartSpecifications {
+				if k.Column != nil {
+					fk.AddRefColumns(column2(ref, k.Column.Name.O))
+				}
+			}
+		}
+		add(&schema.AddForeignKey{F: fk})
+	case ast.ConstraintCheck:
+		ck := schema.NewCheck().SetName(c.Name)
+		if c.Expr != nil {
+			ck.SetExpr(exprText(c.Expr))
+		}
+		add(&schema.AddCheck{C: ck})
+	}
+}
+
+// parts adds the index parts to the index.
+func parts(t *schema.Table, idx *schema.Index, specs []*ast.IndexPartSpecification) {
+	for _, p := range specs {
+		if p.Column != nil {
+			idx.AddColumns(column2(t, p.Column.Name.O))
+		} else if p.Expr != nil {
+			idx.AddExprs(&schema.RawExpr{X: exprText(p.Expr)})
+		}
+	}
+}
+
+// column returns a schema column from its definition.
+func column(t *schema.Table, d *ast.ColumnDef) (*schema.Column, error) {
+	raw := d.Tp.InfoSchemaStr()
+	typ, err := mysql.ParseType(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse column %q type: %w", d.Name.Name.O, err)
+	}
+	c := &schema.Column{Name: d.Name.Name.O, Type: &schema.ColumnType{Raw: raw, Type: typ, Null: true}}
+	for _, o := range d.Options {
+		switch o.Tp {
+		case ast.ColumnOptionNotNull:
+			c.Type.Null = false
+		case ast.ColumnOptionPrimaryKey:
+			c.Type.Null = false
+			// The column is added to the table by the caller.
+			t.PrimaryKey = schema.NewPrimaryKey(c)
+			t.PrimaryKey.Table = t
+		case ast.ColumnOptionUniqKey:
+			t.AddIndexes(schema.NewUniqueIndex("").AddColumns(c))
+		case ast.ColumnOptionAutoIncrement:
+			c.AddAttrs(&mysql.AutoIncrement{})
+		case ast.ColumnOptionDefaultValue:
+			if o.Expr != nil {
+				c.SetDefault(&schema.RawExpr{X: exprText(o.Expr)})
+			}
+		}
+	}
+	return c, nil
+}
+
+// column2 returns the column with the given name from the table,
+// or a new column in case it was not defined by the statement.
+func column2(t *schema.Table, name string) *schema.Column {
+	if c, ok := t.Column(name); ok {
+		return c
+	}
+	return schema.NewColumn(name)
+}
+
+// table returns a schema table from its name.
+func table(n *ast.TableName) *schema.Table {
+	t := schema.NewTable(n.Name.O)
+	if n.Schema.O != "" {
+		schema.New(n.Schema.O).AddTables(t)
+	}
+	return t
+}
+
+// view returns a schema view from its name.
+func view(n *ast.TableName) *schema.View {
+	v := schema.NewView(n.Name.O, "")
+	if n.Schema.O != "" {
+		schema.New(n.Schema.O).AddViews(v)
+	}
+	return v
+}
+
+// exprText returns the textual representation of the expression.
+func exprText(x ast.ExprNode) string {
+	var (
+		b   strings.Builder
+		ctx = format.NewRestoreCtx(format.DefaultRestoreFlags, &b)
+	)
+	// String literals are restored with their charset introducer.
+	if v, ok := x.(*test_driver.ValueExpr); ok && v.Kind() == test_driver.KindString {
+		ctx.WriteString(v.GetString())
+		return b.String()
+	}
+	if err := x.Restore(ctx); err != nil {
+		return x.Text()
+	}
+	return b.String()
+}
+
+// TableRefs returns the tables and views referenced by the given query.
+func (p *Parser) TableRefs(query string) ([]*parseutil.TableRef, error) {
+	stmt, err := parser.New().ParseOneStmt(query, "", "")
+	if err != nil {
+		return nil, err
+	}
+	v := &refsVisitor{}
+	stmt.Accept(v)
+	return v.refs, nil
+}
+
+// refsVisitor collects the table names referenced by a statement. The scopes
+// stack holds the CTEs that are visible in each nested WITH statement.
+type refsVisitor struct {
+	refs   []*parseutil.TableRef
+	scopes []parseutil.CTEs
+}
+
+// Enter implements ast.Visitor.
+func (v *refsVisitor) Enter(n ast.Node) (ast.Node, bool) {
+	switch n := n.(type) {
+	case *ast.TableName:
+		if ref := (&parseutil.TableRef{Schema: n.Schema.O, Name: n.Name.O}); !v.scope().Has(ref) {
+			v.refs = append(v.refs, ref)
+		}
+	case *ast.SelectStmt:
+		v.enterWith(n.With)
+	case *ast.SetOprStmt:
+		v.enterWith(n.With)
+	}
+	return n, false
+}
+
+// Leave implements ast.Visitor.
+func (v *refsVisitor) Leave(n ast.Node) (ast.Node, bool) {
+	switch n := n.(type) {
+	// In a non-recursive WITH clause, a CTE is visible only
+	// after its definition (i.e. in the following ones).
+	case *ast.CommonTableExpression:
+		if len(v.scopes) > 0 {
+			v.scopes[len(v.scopes)-1] = v.scope().With(n.Name.O)
+		}
+	case *ast.SelectStmt:
+		v.leaveWith(n.With)
+	case *ast.SetOprStmt:
+		v.leaveWith(n.With)
+	}
+	return n, true
+}
+
+// scope returns the CTEs that are visible in the current scope.
+func (v *refsVisitor) scope() parseutil.CTEs {
+	if len(v.scopes) == 0 {
+		return nil
+	}
+	return v.scopes[len(v.scopes)-1]
+}
+
+// enterWith opens a new scope for a statement with a WITH clause. In
+// a recursive clause, all CTEs are visible in all their definitions.
+func (v *refsVisitor) enterWith(w *ast.WithClause) {
+	if w == nil {
+		return
+	}
+	scope := v.scope().With()
+	if w.IsRecursive {
+		for _, c := range w.CTEs {
+			scope[c.Name.O] = true
+		}
+	}
+	v.scopes = append(v.scopes, scope)
+}
+
+// leaveWith closes the scope of a statement with a WITH clause.
+func (v *refsVisitor) leaveWith(w *ast.WithClause) {
+	if w != nil {
+		v.scopes = v.scopes[:len(v.scopes)-1]
+	}
+}
@@ -125,6 +125,72 @@ func MatchStmtAfter(f migrate.File, pos int, p func(*migrate.Stmt) (bool, error)
 	return false, nil
 }
 
+// TableRef describes a reference to a table or a view in a query.
+type TableRef struct {
+	Schema, Name string
+}
+
+// CTEs holds the names of the common table expressions that are visible in a scope of
+// a query. Unqualified references to these names are not references to tables or views.
+type CTEs map[string]bool
+
+// With returns a new scope that extends c with the given names. A CTE shadows tables
+// and views with the same name only in the scope it is visible in. For example, in a
+// non-recursive WITH clause, the CTE is not visible in its own definition.
+func (c CTEs) With(names ...string) CTEs {
+	s := make(CTEs, len(c)+len(names))
+	for n := range c {
+		s[n] = true
+	}
+	for _, n := range names {
+		s[n] = true
+	}
+	return s
+}
+
+// Has reports if the given reference refers to a CTE in the scope.
+func (c CTEs) Has(r *TableRef) bool {
+	return r.Schema == "" && c[r.Name]
+}
+
+// LinkViewDeps derives the dependencies of the views in the realm that do not
+// define them explicitly, by extracting the tables and views referenced by their
+// definitions using the given function. References that cannot be resolved to
+// an object in the realm (e.g. table functions) are ignored, and so are views
+// whose definition cannot be parsed.
+func LinkViewDeps(r *schema.Realm, refs func(string) ([]*TableRef, error)) {
+	for _, s := range r.Schemas {
+		for _, v := range s.Views {
+			if len(v.Deps) > 0 || v.Def == "" {
+				continue
+			}
+			rs, err := refs(v.Def)
+			if err != nil {
+				continue
+			}
+			for _, ref := range rs {
+				s1, ok := s, true
+				if ref.Schema != "" {
+					if s1, ok = r.Schema(ref.Schema); !ok {
+						continue
+					}
+				}
+				var o schema.Object
+				if t, ok := s1.Table(ref.Name); ok {
+					o = t
+				} else if v1, ok := s1.View(ref.Name); ok && v1 != v {
+					o = v1
+				} else if v1, ok := s1.Materialized(ref.Name); ok && v1 != v {
+					o = v1
+				}
+				if o != nil && !slices.Contains(v.Deps, o) {
+					v.AddDeps(o)
+				}
+			}
+		}
+	}
+}
+
 func max(i, j int) int {
 	if i > j {
 		return i
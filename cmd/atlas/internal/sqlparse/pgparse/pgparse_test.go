@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"testing"
 
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/parseutil"
 	"ariga.io/atlas/cmd/atlas/internal/sqlparse/pgparse"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/postgres"
@@ -355,3 +356,111 @@ CREATE VIEW old AS SELECT * FROM new;
 		})
 	}
 }
+
+func TestParser_ParseStmt(t *testing.T) {
+	var p pgparse.Parser
+	parse := func(s string) schema.Changes {
+		changes, err := p.ParseStmt(&migrate.Stmt{Text: s})
+		require.NoError(t, err)
+		return changes
+	}
+	changes := parse(`CREATE TABLE "s"."users" ("id" integer NOT NULL, "name" character varying(255) DEFAULT 'a', PRIMARY KEY ("id"), CONSTRAINT "name" UNIQUE ("name"), CHECK (id > 0))`)
+	require.Len(t, changes, 1)
+	users := changes[0].(*schema.AddTable).T
+	require.Equal(t, "users", users.Name)
+	require.Equal(t, "s", users.Schema.Name)
+	require.Len(t, users.Columns, 2)
+	require.Equal(t, &schema.IntegerType{T: "int"}, users.Columns[0].Type.Type)
+	require.False(t, users.Columns[0].Type.Null)
+	require.Equal(t, &schema.StringType{T: "varchar", Size: 255}, users.Columns[1].Type.Type)
+	require.True(t, users.Columns[1].Type.Null)
+	require.Equal(t, &schema.RawExpr{X: "'a'"}, users.Columns[1].Default)
+	require.Equal(t, users.Columns[0], users.PrimaryKey.Parts[0].C)
+	require.Equal(t, "name", users.Indexes[0].Name)
+	require.True(t, users.Indexes[0].Unique)
+	require.Equal(t, "id > 0", users.Attrs[0].(*schema.Check).Expr)
+
+	changes = parse(`ALTER TABLE "users" ADD COLUMN "age" bigint NULL, DROP COLUMN "name", ALTER COLUMN "c" SET NOT NULL, ALTER COLUMN "d" TYPE text, ADD CONSTRAINT "fk" FOREIGN KEY ("age") REFERENCES "ages" ("id")`)
+	require.Len(t, changes, 1)
+	m := changes[0].(*schema.ModifyTable)
+	require.Len(t, m.Changes, 5)
+	require.Equal(t, "age", m.Changes[0].(*schema.AddColumn).C.Name)
+	require.Equal(t, "name", m.Changes[1].(*schema.DropColumn).C.Name)
+	require.Equal(t, schema.ChangeNull, m.Changes[2].(*schema.ModifyColumn).Change)
+	require.False(t, m.Changes[2].(*schema.ModifyColumn).To.Type.Null)
+	require.Equal(t, &schema.StringType{T: "text"}, m.Changes[3].(*schema.ModifyColumn).To.Type.Type)
+	fk := m.Changes[4].(*schema.AddForeignKey).F
+	require.Equal(t, "ages", fk.RefTable.Name)
+	require.Equal(t, "id", fk.RefColumns[0].Name)
+
+	changes = parse(`CREATE UNIQUE INDEX "idx" ON "users" ("a", (lower(b)))`)
+	idx := changes[0].(*schema.ModifyTable).Changes[0].(*schema.AddIndex).I
+	require.True(t, idx.Unique)
+	require.Equal(t, "a", idx.Parts[0].C.Name)
+	require.Equal(t, &schema.RawExpr{X: "lower(b)"}, idx.Parts[1].X)
+
+	changes = parse(`ALTER TABLE "t1" RENAME TO "t2"; ALTER TABLE "t2" RENAME COLUMN "a" TO "b"`)
+	require.Len(t, changes, 2)
+	require.Equal(t, "t2", changes[0].(*schema.RenameTable).To.Name)
+	require.Equal(t, "b", changes[1].(*schema.ModifyTable).Changes[0].(*schema.RenameColumn).To.Name)
+
+	changes = parse(`DROP TABLE "t1", "s"."t2"`)
+	require.Len(t, changes, 2)
+	require.Equal(t, "t1", changes[0].(*schema.DropTable).T.Name)
+	require.Equal(t, "s", changes[1].(*schema.DropTable).T.Schema.Name)
+	changes = parse(`CREATE SCHEMA "s"`)
+	require.Equal(t, schema.Changes{&schema.AddSchema{S: schema.New("s")}}, changes)
+	require.Empty(t, parse(`INSERT INTO "users" ("id") VALUES (1)`))
+
+	_, err := p.ParseStmt(&migrate.Stmt{Text: "CREATE TABLE"})
+	require.Error(t, err)
+
+	// Dropped constraints are resolved using the current schema.
+	var (
+		id     = schema.NewIntColumn("id", "int")
+		ref    = schema.NewTable("ref").AddColumns(schema.NewIntColumn("id", "int"))
+		orders = schema.NewTable("orders").
+			AddColumns(id).
+			SetPrimaryKey(schema.NewPrimaryKey(id).SetName("orders_pkey")).
+			AddIndexes(schema.NewUniqueIndex("orders_id_key").AddColumns(id)).
+			AddForeignKeys(schema.NewForeignKey("orders_ref_fkey").AddColumns(id).SetRefTable(ref).AddRefColumns(ref.Columns[0])).
+			AddChecks(schema.NewCheck().SetName("orders_id_check").SetExpr("id > 0"))
+		current = schema.NewRealm(schema.New("public").AddTables(ref, orders))
+	)
+	changes, err = p.ParseStmtOn(&migrate.Stmt{Text: `ALTER TABLE "orders" DROP CONSTRAINT "orders_pkey", DROP CONSTRAINT "orders_id_key", DROP CONSTRAINT "orders_ref_fkey", DROP CONSTRAINT "orders_id_check", DROP CONSTRAINT "unknown"`}, current)
+	require.NoError(t, err)
+	m = changes[0].(*schema.ModifyTable)
+	require.Len(t, m.Changes, 4)
+	require.Equal(t, orders.PrimaryKey, m.Changes[0].(*schema.DropPrimaryKey).P)
+	require.Equal(t, orders.Indexes[0], m.Changes[1].(*schema.DropIndex).I)
+	require.Equal(t, orders.ForeignKeys[0], m.Changes[2].(*schema.DropForeignKey).F)
+	require.Equal(t, "orders_id_check", m.Changes[3].(*schema.DropCheck).C.Name)
+	changes, err = p.ParseStmtOn(&migrate.Stmt{Text: `ALTER TABLE "other"."orders" DROP CONSTRAINT "orders_pkey"`}, current)
+	require.NoError(t, err)
+	require.Empty(t, changes, "table does not exist in the current schema")
+	require.Empty(t, parse(`ALTER TABLE "orders" DROP CONSTRAINT "orders_ref_fkey"`), "unknown without the current schema")
+	var _ migrate.StateStmtParser = (*pgparse.Parser)(nil)
+}
+
+func TestParser_TableRefs(t *testing.T) {
+	var p pgparse.Parser
+	refs, err := p.TableRefs(`WITH c AS (SELECT id FROM "t1") SELECT * FROM c JOIN "s"."t2" ON c.id = t2.id WHERE c.id IN (SELECT id FROM v1)`)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*parseutil.TableRef{{Name: "t1"}, {Schema: "s", Name: "t2"}, {Name: "v1"}}, refs)
+	// CTEs shadow tables only in their scope.
+	refs, err = p.TableRefs(`WITH users AS (SELECT * FROM users WHERE active) SELECT * FROM users JOIN (SELECT * FROM posts) AS p USING (id)`)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*parseutil.TableRef{{Name: "users"}, {Name: "posts"}}, refs)
+	refs, err = p.TableRefs(`WITH a AS (SELECT * FROM b), b AS (SELECT * FROM a) SELECT * FROM b`)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*parseutil.TableRef{{Name: "b"}}, refs)
+	refs, err = p.TableRefs(`WITH RECURSIVE tree AS (SELECT id FROM nodes UNION ALL SELECT n.id FROM nodes n JOIN tree t ON n.parent = t.id) SELECT * FROM tree`)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*parseutil.TableRef{{Name: "nodes"}, {Name: "nodes"}}, refs)
+	// Nested WITH clauses.
+	refs, err = p.TableRefs(`SELECT * FROM (WITH x AS (SELECT * FROM t1) SELECT * FROM x) AS s JOIN x USING (id)`)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*parseutil.TableRef{{Name: "t1"}, {Name: "x"}}, refs)
+	_, err = p.TableRefs("SELECT * FROM")
+	require.Error(t, err)
+}
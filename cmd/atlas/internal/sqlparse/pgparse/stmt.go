@@ -0,0 +1,444 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package pgparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/parseutil"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+
+	pgquery "github.com/pganalyze/pg_query_go/v4"
+)
+
+// ParseStmt parses the statement and returns the schema changes it describes. Statements
+// that do not change the schema (e.g. INSERT) or are not supported, return no changes.
+// Dropped constraints are not reported, as their kind is unknown without the schema state.
+// Use ParseStmtOn to resolve them.
+func (p *Parser) ParseStmt(s *migrate.Stmt) (schema.Changes, error) {
+	return p.ParseStmtOn(s, nil)
+}
+
+// ParseStmtOn is like ParseStmt, but resolves the statement against the given realm,
+// the schema state the statement is executed on. For example, the kind of constraint
+// (e.g. foreign key or check) dropped by an "ALTER TABLE ... DROP CONSTRAINT" statement.
+func (p *Parser) ParseStmtOn(s *migrate.Stmt, current *schema.Realm) (schema.Changes, error) {
+	tr, err := pgquery.Parse(s.Text)
+	if err != nil {
+		return nil, err
+	}
+	var changes schema.Changes
+	for _, stmt := range tr.Stmts {
+		c, err := parseStmt(stmt.GetStmt(), current)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c...)
+	}
+	return changes, nil
+}
+
+func parseStmt(stmt *pgquery.Node, current *schema.Realm) (schema.Changes, error) {
+	switch {
+	case stmt.GetCreateSchemaStmt() != nil:
+		return schema.Changes{&schema.AddSchema{S: schema.New(stmt.GetCreateSchemaStmt().GetSchemaname())}}, nil
+	case stmt.GetCreateStmt() != nil:
+		create := stmt.GetCreateStmt()
+		t := table(create.GetRelation())
+		for _, e := range create.GetTableElts() {
+			switch {
+			case e.GetColumnDef() != nil:
+				c, err := column(t, e.GetColumnDef())
+				if err != nil {
+					return nil, err
+				}
+				t.AddColumns(c)
+			case e.GetConstraint() != nil:
+				if err := constraint(t, e.GetConstraint(), func(c schema.Change) {
+					switch c := c.(type) {
+					case *schema.AddPrimaryKey:
+						t.SetPrimaryKey(c.P)
+					case *schema.AddIndex:
+						t.AddIndexes(c.I)
+					case *schema.AddForeignKey:
+						t.AddForeignKeys(c.F)
+					case *schema.AddCheck:
+						t.AddChecks(c.C)
+					}
+				}); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return schema.Changes{&schema.AddTable{T: t}}, nil
+	case stmt.GetViewStmt() != nil:
+		return schema.Changes{&schema.AddView{V: view(stmt.GetViewStmt().GetView())}}, nil
+	case stmt.GetIndexStmt() != nil:
+		create := stmt.GetIndexStmt()
+		t := table(create.GetRelation())
+		idx := schema.NewIndex(create.GetIdxname()).SetUnique(create.GetUnique())
+		for _, p := range create.GetIndexParams() {
+			e := p.GetIndexElem()
+			if e.GetName() != "" {
+				idx.AddColumns(column2(t, e.GetName()))
+				continue
+			}
+			x, err := deparse(e.GetExpr())
+			if err != nil {
+				return nil, err
+			}
+			idx.AddExprs(&schema.RawExpr{X: x})
+		}
+		return schema.Changes{&schema.ModifyTable{T: t, Changes: schema.Changes{&schema.AddIndex{I: idx}}}}, nil
+	case stmt.GetDropStmt() != nil:
+		return dropStmt(stmt.GetDropStmt()), nil
+	case stmt.GetRenameStmt() != nil:
+		return renameStmt(stmt.GetRenameStmt()), nil
+	case stmt.GetAlterTableStmt() != nil && stmt.GetAlterTableStmt().GetObjtype() == pgquery.ObjectType_OBJECT_TABLE:
+		return alterTable(stmt.GetAlterTableStmt(), current)
+	}
+	return nil, nil
+}
+
+// alterTable returns the changes described by the ALTER TABLE statement.
+func alterTable(stmt *pgquery.AlterTableStmt, current *schema.Realm) (schema.Changes, error) {
+	modify := &schema.ModifyTable{T: table(stmt.GetRelation())}
+	add := func(c schema.Change) {
+		modify.Changes = append(modify.Changes, c)
+	}
+	for _, n := range stmt.GetCmds() {
+		cmd := n.GetAlterTableCmd()
+		switch cmd.GetSubtype() {
+		case pgquery.AlterTableType_AT_AddColumn:
+			c, err := column(modify.T, cmd.GetDef().GetColumnDef())
+			if err != nil {
+				return nil, err
+			}
+			add(&schema.AddColumn{C: c})
+		case pgquery.AlterTableType_AT_DropColumn:
+			add(&schema.DropColumn{C: schema.NewColumn(cmd.GetName())})
+		case pgquery.AlterTableType_AT_AlterColumnType:
+			to, err := column(modify.T, cmd.GetDef().GetColumnDef())
+			if err != nil {
+				return nil, err
+			}
+			to.Name = cmd.GetName()
+			add(&schema.ModifyColumn{From: schema.NewColumn(to.Name), To: to, Change: schema.ChangeType})
+		case pgquery.AlterTableType_AT_SetNotNull, pgquery.AlterTableType_AT_DropNotNull:
+			to := schema.NewNullColumn(cmd.GetName())
+			to.Type.Null = cmd.GetSubtype() == pgquery.AlterTableType_AT_DropNotNull
+			add(&schema.ModifyColumn{From: schema.NewColumn(to.Name), To: to, Change: schema.ChangeNull})
+		case pgquery.AlterTableType_AT_ColumnDefault:
+			to := schema.NewColumn(cmd.GetName())
+			if cmd.GetDef() != nil {
+				x, err := deparse(cmd.GetDef())
+				if err != nil {
+					return nil, err
+				}
+				to.SetDefault(&schema.RawExpr{X: x})
+			}
+			add(&schema.ModifyColumn{From: schema.NewColumn(to.Name), To: to, Change: schema.ChangeDefault})
+		case pgquery.AlterTableType_AT_AddConstraint:
+			if err := constraint(modify.T, cmd.GetDef().GetConstraint(), add); err != nil {
+				return nil, err
+			}
+		case pgquery.AlterTableType_AT_DropConstraint:
+			// The constraint kind is unknown without the schema state,
+			// and unresolved constraints are skipped, not misclassified.
+			if c := dropConstraint(current, modify.T, cmd.GetName()); c != nil {
+				add(c)
+			}
+		}
+	}
+	if len(modify.Changes) == 0 {
+		return nil, nil
+	}
+	return schema.Changes{modify}, nil
+}
+
+// dropConstraint returns the change that drops the named constraint of the table,
+// resolved from the current realm. Nil is returned if the constraint was not found.
+func dropConstraint(current *schema.Realm, t *schema.Table, name string) schema.Change {
+	if current == nil {
+		return nil
+	}
+	for _, s := range current.Schemas {
+		if t.Schema != nil && t.Schema.Name != s.Name {
+			continue
+		}
+		t1, ok := s.Table(t.Name)
+		if !ok {
+			continue
+		}
+		if pk := t1.PrimaryKey; pk != nil && pk.Name == name {
+			return &schema.DropPrimaryKey{P: pk}
+		}
+		if fk, ok := t1.ForeignKey(name); ok {
+			return &schema.DropForeignKey{F: fk}
+		}
+		if idx, ok := t1.Index(name); ok && idx.Unique {
+			return &schema.DropIndex{I: idx}
+		}
+		for _, a := range t1.Attrs {
+			if c, ok := a.(*schema.Check); ok && c.Name == name {
+				return &schema.DropCheck{C: c}
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// dropStmt returns the changes described by the DROP statement.
+func dropStmt(stmt *pgquery.DropStmt) schema.Changes {
+	var changes schema.Changes
+	for _, o := range stmt.GetObjects() {
+		names := nameList(o)
+		if len(names) == 0 {
+			continue
+		}
+		name, qualifier := names[len(names)-1], ""
+		if len(names) > 1 {
+			qualifier = names[len(names)-2]
+		}
+		switch stmt.GetRemoveType() {
+		case pgquery.ObjectType_OBJECT_SCHEMA:
+			changes = append(changes, &schema.DropSchema{S: schema.New(name)})
+		case pgquery.ObjectType_OBJECT_TABLE:
+			changes = append(changes, &schema.DropTable{T: table(&pgquery.RangeVar{Schemaname: qualifier, Relname: name})})
+		case pgquery.ObjectType_OBJECT_VIEW:
+			changes = append(changes, &schema.DropView{V: view(&pgquery.RangeVar{Schemaname: qualifier, Relname: name})})
+		case pgquery.ObjectType_OBJECT_INDEX:
+			// The table of the index is unknown without the database state.
+			t := table(&pgquery.RangeVar{Schemaname: qualifier})
+			changes = append(changes, &schema.ModifyTable{T: t, Changes: schema.Changes{&schema.DropIndex{I: schema.NewIndex(name)}}})
+		}
+	}
+	return changes
+}
+
+// renameStmt returns the changes described by the RENAME statement.
+func renameStmt(stmt *pgquery.RenameStmt) schema.Changes {
+	switch stmt.GetRenameType() {
+	case pgquery.ObjectType_OBJECT_TABLE:
+		to := table(stmt.GetRelation())
+		to.Name = stmt.GetNewname()
+		return schema.Changes{&schema.RenameTable{From: table(stmt.GetRelation()), To: to}}
+	case pgquery.ObjectType_OBJECT_COLUMN:
+		return schema.Changes{&schema.ModifyTable{
+			T:       table(stmt.GetRelation()),
+			Changes: schema.Changes{&schema.RenameColumn{From: schema.NewColumn(stmt.GetSubname()), To: schema.NewColumn(stmt.GetNewname())}},
+		}}
+	case pgquery.ObjectType_OBJECT_INDEX:
+		t := table(&pgquery.RangeVar{Schemaname: stmt.GetRelation().GetSchemaname()})
+		return schema.Changes{&schema.ModifyTable{
+			T:       t,
+			Changes: schema.Changes{&schema.RenameIndex{From: schema.NewIndex(stmt.GetRelation().GetRelname()), To: schema.NewIndex(stmt.GetNewname())}},
+		}}
+	}
+	return nil
+}
+
+// constraint passes the change described by the constraint definition to the add function.
+func constraint(t *schema.Table, c *pgquery.Constraint, add func(schema.Change)) error {
+	switch c.GetContype() {
+	case pgquery.ConstrType_CONSTR_PRIMARY:
+		pk := schema.NewPrimaryKey()
+		for _, k := range nameList(&pgquery.Node{Node: &pgquery.Node_List{List: &pgquery.List{Items: c.GetKeys()}}}) {
+			pk.AddColumns(column2(t, k))
+		}
+		add(&schema.AddPrimaryKey{P: pk})
+	case pgquery.ConstrType_CONSTR_UNIQUE:
+		idx := schema.NewUniqueIndex(c.GetConname())
+		for _, k := range nameList(&pgquery.Node{Node: &pgquery.Node_List{List: &pgquery.List{Items: c.GetKeys()}}}) {
+			idx.AddColumns(column2(t, k))
+		}
+		add(&schema.AddIndex{I: idx})
+	case pgquery.ConstrType_CONSTR_FOREIGN:
+		ref := table(c.GetPktable())
+		fk := schema.NewForeignKey(c.GetConname()).SetTable(t).SetRefTable(ref)
+		for _, k := range nameList(&pgquery.Node{Node: &pgquery.Node_List{List: &pgquery.List{Items: c.GetFkAttrs()}}}) {
+			fk.AddColumns(column2(t, k))
+		}
+		for _, k := range nameList(&pgquery.Node{Node: &pgquery.Node_List{List: &pgquery.List{Items: c.GetPkAttrs()}}}) {
+			fk.AddRefColumns(column2(ref, k))
+		}
+		add(&schema.AddForeignKey{F: fk})
+	case pgquery.ConstrType_CONSTR_CHECK:
+		x, err := deparse(c.GetRawExpr())
+		if err != nil {
+			return err
+		}
+		add(&schema.AddCheck{C: schema.NewCheck().SetName(c.GetConname()).SetExpr(x)})
+	}
+	return nil
+}
+
+// column returns a schema column from its definition.
+func column(t *schema.Table, d *pgquery.ColumnDef) (*schema.Column, error) {
+	c := &schema.Column{Name: d.GetColname(), Type: &schema.ColumnType{Null: !d.GetIsNotNull()}}
+	if tn := d.GetTypeName(); tn != nil {
+		raw, err := deparse(&pgquery.Node{Node: &pgquery.Node_TypeCast{TypeCast: &pgquery.TypeCast{
+			Arg:      &pgquery.Node{Node: &pgquery.Node_AConst{AConst: &pgquery.A_Const{Isnull: true}}},
+			TypeName: tn,
+		}}})
+		if err != nil {
+			return nil, err
+		}
+		c.Type.Raw = strings.TrimPrefix(raw, "NULL::")
+		if c.Type.Type, err = postgres.ParseType(c.Type.Raw); err != nil {
+			return nil, fmt.Errorf("parse column %q type: %w", c.Name, err)
+		}
+	}
+	for _, n := range d.GetConstraints() {
+		switch x := n.GetConstraint(); x.GetContype() {
+		case pgquery.ConstrType_CONSTR_NOTNULL:
+			c.Type.Null = false
+		case pgquery.ConstrType_CONSTR_PRIMARY:
+			c.Type.Null = false
+			// The column is added to the table by the caller.
+			t.PrimaryKey = schema.NewPrimaryKey(c)
+			t.PrimaryKey.Table = t
+		case pgquery.ConstrType_CONSTR_UNIQUE:
+			t.AddIndexes(schema.NewUniqueIndex(x.GetConname()).AddColumns(c))
+		case pgquery.ConstrType_CONSTR_DEFAULT:
+			v, err := deparse(x.GetRawExpr())
+			if err != nil {
+				return nil, err
+			}
+			c.SetDefault(&schema.RawExpr{X: v})
+		}
+	}
+	return c, nil
+}
+
+// deparse returns the textual representation of the expression.
+func deparse(x *pgquery.Node) (string, error) {
+	s, err := pgquery.Deparse(&pgquery.ParseResult{
+		Stmts: []*pgquery.RawStmt{{
+			Stmt: &pgquery.Node{Node: &pgquery.Node_SelectStmt{SelectStmt: &pgquery.SelectStmt{
+				TargetList: []*pgquery.Node{pgquery.MakeResTargetNodeWithVal(x, 0)},
+			}}},
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(s, "SELECT "), nil
+}
+
+// nameList returns the names in the given list node.
+func nameList(n *pgquery.Node) []string {
+	var names []string
+	if n.GetString_() != nil {
+		return []string{n.GetString_().GetSval()}
+	}
+	for _, i := range n.GetList().GetItems() {
+		names = append(names, i.GetString_().GetSval())
+	}
+	return names
+}
+
+// column2 returns the column with the given name from the table,
+// or a new column in case it was not defined by the statement.
+func column2(t *schema.Table, name string) *schema.Column {
+	if c, ok := t.Column(name); ok {
+		return c
+	}
+	return schema.NewColumn(name)
+}
+
+// table returns a schema table from its name.
+func table(n *pgquery.RangeVar) *schema.Table {
+	t := schema.NewTable(n.GetRelname())
+	if n.GetSchemaname() != "" {
+		schema.New(n.GetSchemaname()).AddTables(t)
+	}
+	return t
+}
+
+// view returns a schema view from its name.
+func view(n *pgquery.RangeVar) *schema.View {
+	v := schema.NewView(n.GetRelname(), "")
+	if n.GetSchemaname() != "" {
+		schema.New(n.GetSchemaname()).AddViews(v)
+	}
+	return v
+}
+
+// TableRefs returns the tables and views referenced by the given query.
+func (p *Parser) TableRefs(query string) ([]*parseutil.TableRef, error) {
+	tree, err := pgquery.ParseToJSON(query)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal([]byte(tree), &v); err != nil {
+		return nil, err
+	}
+	var (
+		refs []*parseutil.TableRef
+		walk func(any, parseutil.CTEs)
+	)
+	walk = func(v any, ctes parseutil.CTEs) {
+		switch v := v.(type) {
+		case []any:
+			for _, e := range v {
+				walk(e, ctes)
+			}
+		case map[string]any:
+			if r, ok := v["RangeVar"].(map[string]any); ok {
+				ref := &parseutil.TableRef{}
+				ref.Schema, _ = r["schemaname"].(string)
+				ref.Name, _ = r["relname"].(string)
+				if !ctes.Has(ref) {
+					refs = append(refs, ref)
+				}
+			}
+			if w, ok := v["withClause"].(map[string]any); ok {
+				ctes = withCTEs(w, ctes, walk)
+			}
+			for k, e := range v {
+				if k != "withClause" {
+					walk(e, ctes)
+				}
+			}
+		}
+	}
+	walk(v, nil)
+	return refs, nil
+}
+
+// withCTEs walks the definitions of the common table expressions in the given WITH clause,
+// and returns the scope of the statement it is attached to. In a non-recursive clause, each
+// CTE can reference only the CTEs that were defined before it. In a recursive clause, all
+// CTEs are visible in all definitions, including their own.
+func withCTEs(w map[string]any, ctes parseutil.CTEs, walk func(any, parseutil.CTEs)) parseutil.CTEs {
+	var (
+		defs         []map[string]any
+		names        []string
+		recursive, _ = w["recursive"].(bool)
+	)
+	list, _ := w["ctes"].([]any)
+	for _, e := range list {
+		m, _ := e.(map[string]any)
+		if c, ok := m["CommonTableExpr"].(map[string]any); ok {
+			n, _ := c["ctename"].(string)
+			defs, names = append(defs, c), append(names, n)
+		}
+	}
+	for i, c := range defs {
+		scope := ctes.With(names[:i]...)
+		if recursive {
+			scope = ctes.With(names...)
+		}
+		walk(c["ctequery"], scope)
+	}
+	return ctes.With(names...)
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"testing"
 
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/parseutil"
 	"ariga.io/atlas/cmd/atlas/internal/sqlparse/sqliteparse"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
@@ -199,3 +200,83 @@ CREATE VIEW old AS SELECT a, b, c FROM new;
 		})
 	}
 }
+
+func TestFileParser_ParseStmt(t *testing.T) {
+	var p sqliteparse.FileParser
+	parse := func(s string) schema.Changes {
+		changes, err := p.ParseStmt(&migrate.Stmt{Text: s})
+		require.NoError(t, err)
+		return changes
+	}
+	changes := parse("CREATE TABLE `users` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `name` varchar(255) DEFAULT 'a', `age` unsigned big int, CONSTRAINT `fk` FOREIGN KEY (`age`) REFERENCES `ages` (`id`), CHECK (id > 0))")
+	require.Len(t, changes, 1)
+	users := changes[0].(*schema.AddTable).T
+	require.Equal(t, "users", users.Name)
+	require.Len(t, users.Columns, 3)
+	require.Equal(t, &schema.IntegerType{T: "integer"}, users.Columns[0].Type.Type)
+	require.False(t, users.Columns[0].Type.Null)
+	require.Equal(t, users.Columns[0], users.PrimaryKey.Parts[0].C)
+	require.Equal(t, &schema.Literal{V: "'a'"}, users.Columns[1].Default)
+	require.Equal(t, "unsigned big int", users.Columns[2].Type.Raw)
+	require.Equal(t, "fk", users.ForeignKeys[0].Symbol)
+	require.Equal(t, users.Columns[2], users.ForeignKeys[0].Columns[0])
+	require.Equal(t, "ages", users.ForeignKeys[0].RefTable.Name)
+	require.Equal(t, "id > 0", users.Attrs[0].(*schema.Check).Expr)
+
+	changes = parse("ALTER TABLE `users` ADD COLUMN `c` text NOT NULL")
+	require.Len(t, changes, 1)
+	require.Equal(t, "c", changes[0].(*schema.ModifyTable).Changes[0].(*schema.AddColumn).C.Name)
+	changes = parse("ALTER TABLE `users` DROP COLUMN `c`")
+	require.Equal(t, "c", changes[0].(*schema.ModifyTable).Changes[0].(*schema.DropColumn).C.Name)
+	changes = parse("ALTER TABLE `users` RENAME COLUMN `a` TO `b`")
+	rename := changes[0].(*schema.ModifyTable).Changes[0].(*schema.RenameColumn)
+	require.Equal(t, "a", rename.From.Name)
+	require.Equal(t, "b", rename.To.Name)
+	changes = parse("ALTER TABLE `users` RENAME TO `people`")
+	require.Equal(t, "people", changes[0].(*schema.RenameTable).To.Name)
+
+	changes = parse("CREATE UNIQUE INDEX `idx` ON `users` (`name`, lower(`name`))")
+	idx := changes[0].(*schema.ModifyTable).Changes[0].(*schema.AddIndex).I
+	require.True(t, idx.Unique)
+	require.Equal(t, "name", idx.Parts[0].C.Name)
+	require.Equal(t, "lower(`name`)", idx.Parts[1].X.(*schema.RawExpr).X)
+
+	changes = parse("DROP INDEX `idx`")
+	require.Equal(t, "idx", changes[0].(*schema.ModifyTable).Changes[0].(*schema.DropIndex).I.Name)
+	changes = parse("DROP TABLE `users`")
+	require.Equal(t, "users", changes[0].(*schema.DropTable).T.Name)
+	changes = parse("CREATE VIEW `v` AS SELECT 1")
+	require.Equal(t, "v", changes[0].(*schema.AddView).V.Name)
+	require.Empty(t, parse("INSERT INTO `users` (`id`) VALUES (1)"))
+}
+
+func TestFileParser_TableRefs(t *testing.T) {
+	var p sqliteparse.FileParser
+	refs, err := p.TableRefs("WITH c AS (SELECT id FROM `t1`) SELECT * FROM c JOIN `main`.`t2` ON c.id = t2.id WHERE c.id IN (SELECT id FROM v1)")
+	require.NoError(t, err)
+	require.Equal(t, []*parseutil.TableRef{{Name: "t1"}, {Schema: "main", Name: "t2"}, {Name: "v1"}}, refs)
+	// CTEs shadow tables only in their scope.
+	refs, err = p.TableRefs("WITH users AS (SELECT * FROM users WHERE active) SELECT * FROM users JOIN posts USING (id)")
+	require.NoError(t, err)
+	require.Equal(t, []*parseutil.TableRef{{Name: "users"}, {Name: "posts"}}, refs)
+	refs, err = p.TableRefs("WITH a AS (SELECT * FROM b), b AS (SELECT * FROM a) SELECT * FROM b")
+	require.NoError(t, err)
+	require.Equal(t, []*parseutil.TableRef{{Name: "b"}}, refs)
+	refs, err = p.TableRefs("WITH RECURSIVE tree AS (SELECT id FROM nodes UNION ALL SELECT n.id FROM nodes n JOIN tree t ON n.parent = t.id) SELECT * FROM tree")
+	require.NoError(t, err)
+	require.Equal(t, []*parseutil.TableRef{{Name: "nodes"}, {Name: "nodes"}}, refs)
+	// Nested WITH clauses.
+	refs, err = p.TableRefs("SELECT * FROM (WITH x AS (SELECT * FROM t1) SELECT * FROM x) AS s JOIN x USING (id)")
+	require.NoError(t, err)
+	require.Equal(t, []*parseutil.TableRef{{Name: "t1"}, {Name: "x"}}, refs)
+
+	t1, t2 := schema.NewTable("t1"), schema.NewTable("t2")
+	v1 := schema.NewView("v1", "SELECT * FROM t1")
+	v2 := schema.NewView("v2", "SELECT * FROM v1 JOIN t2 JOIN unknown JOIN v1")
+	v3 := schema.NewView("v3", "SELECT * FROM t1").AddDeps(t2)
+	r := schema.NewRealm(schema.New("main").AddTables(t1, t2).AddViews(v1, v2, v3))
+	parseutil.LinkViewDeps(r, p.TableRefs)
+	require.Equal(t, []schema.Object{t1}, v1.Deps)
+	require.Equal(t, []schema.Object{v1, t2}, v2.Deps)
+	require.Equal(t, []schema.Object{t2}, v3.Deps, "explicit dependencies are kept")
+}
@@ -0,0 +1,270 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqliteparse
+
+import (
+	"fmt"
+
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/parseutil"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// ParseStmt parses the statement and returns the schema changes it describes. Statements
+// that do not change the schema (e.g. INSERT) or are not supported, return no changes.
+func (p *FileParser) ParseStmt(s *migrate.Stmt) (schema.Changes, error) {
+	stmt, err := ParseStmt(s.Text)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.stmt.GetChildCount() != 1 {
+		return nil, nil
+	}
+	switch x := stmt.stmt.GetChild(0).(type) {
+	case *Create_table_stmtContext:
+		t := table(x.Schema_name(), x.Table_name())
+		for _, d := range x.AllColumn_def() {
+			c, err := column(s.Text, t, d.(*Column_defContext))
+			if err != nil {
+				return nil, err
+			}
+			t.AddColumns(c)
+		}
+		for _, c := range x.AllTable_constraint() {
+			tableConstraint(s.Text, t, c.(*Table_constraintContext))
+		}
+		return schema.Changes{&schema.AddTable{T: t}}, nil
+	case *Alter_table_stmtContext:
+		t := table(x.Schema_name(), x.Table_name(0))
+		switch {
+		case x.ADD_() != nil && x.Column_def() != nil:
+			c, err := column(s.Text, t, x.Column_def().(*Column_defContext))
+			if err != nil {
+				return nil, err
+			}
+			return schema.Changes{&schema.ModifyTable{T: t, Changes: schema.Changes{&schema.AddColumn{C: c}}}}, nil
+		case x.DROP_() != nil:
+			c := schema.NewColumn(unquote(x.Column_name(0).GetText()))
+			return schema.Changes{&schema.ModifyTable{T: t, Changes: schema.Changes{&schema.DropColumn{C: c}}}}, nil
+		case x.GetNew_table_name() != nil:
+			to := table(x.Schema_name(), x.GetNew_table_name())
+			return schema.Changes{&schema.RenameTable{From: t, To: to}}, nil
+		case x.GetOld_column_name() != nil && x.GetNew_column_name() != nil:
+			from, to := schema.NewColumn(unquote(x.GetOld_column_name().GetText())), schema.NewColumn(unquote(x.GetNew_column_name().GetText()))
+			return schema.Changes{&schema.ModifyTable{T: t, Changes: schema.Changes{&schema.RenameColumn{From: from, To: to}}}}, nil
+		}
+	case *Create_index_stmtContext:
+		t := table(x.Schema_name(), x.Table_name())
+		idx := schema.NewIndex(unquote(x.Index_name().GetText())).SetUnique(x.UNIQUE_() != nil)
+		parts(s.Text, t, idx, x.AllIndexed_column())
+		return schema.Changes{&schema.ModifyTable{T: t, Changes: schema.Changes{&schema.AddIndex{I: idx}}}}, nil
+	case *Create_view_stmtContext:
+		v := schema.NewView(unquote(x.View_name().GetText()), "")
+		if x.Schema_name() != nil {
+			schema.New(unquote(x.Schema_name().GetText())).AddViews(v)
+		}
+		return schema.Changes{&schema.AddView{V: v}}, nil
+	case *Drop_stmtContext:
+		if x.GetObject() == nil || x.Any_name() == nil {
+			return nil, nil
+		}
+		name := unquote(x.Any_name().GetText())
+		switch x.GetObject().GetTokenType() {
+		case ParserTABLE_:
+			return schema.Changes{&schema.DropTable{T: table(x.Schema_name(), x.Any_name())}}, nil
+		case ParserVIEW_:
+			v := schema.NewView(name, "")
+			if x.Schema_name() != nil {
+				schema.New(unquote(x.Schema_name().GetText())).AddViews(v)
+			}
+			return schema.Changes{&schema.DropView{V: v}}, nil
+		case ParserINDEX_:
+			// The table of the index is unknown without the database state.
+			t := table(x.Schema_name(), nil)
+			return schema.Changes{&schema.ModifyTable{T: t, Changes: schema.Changes{&schema.DropIndex{I: schema.NewIndex(name)}}}}, nil
+		}
+	}
+	return nil, nil
+}
+
+// tableConstraint adds the table constraint to the table.
+func tableConstraint(input string, t *schema.Table, c *Table_constraintContext) {
+	var name string
+	if c.Name() != nil {
+		name = unquote(c.Name().GetText())
+	}
+	switch {
+	case c.PRIMARY_() != nil:
+		pk := schema.NewPrimaryKey()
+		parts(input, t, pk, c.AllIndexed_column())
+		t.SetPrimaryKey(pk)
+	case c.UNIQUE_() != nil:
+		idx := schema.NewUniqueIndex(name)
+		parts(input, t, idx, c.AllIndexed_column())
+		t.AddIndexes(idx)
+	case c.CHECK_() != nil:
+		t.AddChecks(schema.NewCheck().SetName(name).SetExpr(text(input, c.Expr())))
+	case c.FOREIGN_() != nil:
+		fk := schema.NewForeignKey(name).SetTable(t)
+		for _, n := range c.AllColumn_name() {
+			fk.AddColumns(column2(t, unquote(n.GetText())))
+		}
+		if r, ok := c.Foreign_key_clause().(*Foreign_key_clauseContext); ok {
+			ref := schema.NewTable(unquote(r.Foreign_table().GetText()))
+			fk.SetRefTable(ref)
+			for _, n := range r.AllColumn_name() {
+				fk.AddRefColumns(column2(ref, unquote(n.GetText())))
+			}
+		}
+		t.AddForeignKeys(fk)
+	}
+}
+
+// parts adds the indexed columns to the index.
+func parts(input string, t *schema.Table, idx *schema.Index, columns []IIndexed_columnContext) {
+	for _, c := range columns {
+		c := c.(*Indexed_columnContext)
+		if c.Column_name() != nil {
+			idx.AddColumns(column2(t, unquote(c.Column_name().GetText())))
+		} else if c.Expr() != nil {
+			idx.AddExprs(&schema.RawExpr{X: text(input, c.Expr())})
+		}
+	}
+}
+
+// column returns a schema column from its definition.
+func column(input string, t *schema.Table, d *Column_defContext) (*schema.Column, error) {
+	c := &schema.Column{Name: unquote(d.Column_name().GetText()), Type: &schema.ColumnType{Null: true}}
+	if d.Type_name() != nil {
+		c.Type.Raw = text(input, d.Type_name())
+		typ, err := sqlite.ParseType(c.Type.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse column %q type: %w", c.Name, err)
+		}
+		c.Type.Type = typ
+	}
+	for _, x := range d.AllColumn_constraint() {
+		x := x.(*Column_constraintContext)
+		switch {
+		case x.NOT_() != nil && x.NULL_() != nil:
+			c.Type.Null = false
+		case x.PRIMARY_() != nil:
+			c.Type.Null = false
+			// The column is added to the table by the caller.
+			t.PrimaryKey = schema.NewPrimaryKey(c)
+			t.PrimaryKey.Table = t
+			if x.AUTOINCREMENT_() != nil {
+				c.AddAttrs(&sqlite.AutoIncrement{})
+			}
+		case x.UNIQUE_() != nil:
+			t.AddIndexes(schema.NewUniqueIndex("").AddColumns(c))
+		case x.DEFAULT_() != nil:
+			switch {
+			case x.Expr() != nil:
+				c.SetDefault(&schema.RawExpr{X: text(input, x.Expr())})
+			case x.Literal_value() != nil:
+				c.SetDefault(&schema.Literal{V: text(input, x.Literal_value())})
+			case x.Signed_number() != nil:
+				c.SetDefault(&schema.Literal{V: text(input, x.Signed_number())})
+			}
+		}
+	}
+	return c, nil
+}
+
+// column2 returns the column with the given name from the table,
+// or a new column in case it was not defined by the statement.
+func column2(t *schema.Table, name string) *schema.Column {
+	if c, ok := t.Column(name); ok {
+		return c
+	}
+	return schema.NewColumn(name)
+}
+
+// table returns a schema table from its (optionally qualified) name.
+func table(qualifier, name antlr.ParseTree) *schema.Table {
+	t := &schema.Table{}
+	if name != nil {
+		t.Name = unquote(name.GetText())
+	}
+	if qualifier != nil {
+		schema.New(unquote(qualifier.GetText())).AddTables(t)
+	}
+	return t
+}
+
+// text returns the original text of the given rule from the input.
+func text(input string, t antlr.ParseTree) string {
+	r, ok := t.(antlr.ParserRuleContext)
+	if !ok || r.GetStart() == nil || r.GetStop() == nil {
+		return t.GetText()
+	}
+	start, stop := r.GetStart().GetStart(), r.GetStop().GetStop()
+	if start < 0 || stop >= len(input) || start > stop {
+		return t.GetText()
+	}
+	return input[start : stop+1]
+}
+
+// TableRefs returns the tables and views referenced by the given query.
+func (p *FileParser) TableRefs(query string) ([]*parseutil.TableRef, error) {
+	stmt, err := ParseStmt(query)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		refs []*parseutil.TableRef
+		walk func(antlr.Tree, parseutil.CTEs)
+	)
+	walk = func(n antlr.Tree, ctes parseutil.CTEs) {
+		if n, ok := n.(*Table_or_subqueryContext); ok && n.Table_name() != nil {
+			ref := &parseutil.TableRef{Name: unquote(n.Table_name().GetText())}
+			if n.Schema_name() != nil {
+				ref.Schema = unquote(n.Schema_name().GetText())
+			}
+			if !ctes.Has(ref) {
+				refs = append(refs, ref)
+			}
+		}
+		children := n.GetChildren()
+		for _, c := range children {
+			if w, ok := c.(*Common_table_stmtContext); ok {
+				ctes = withCTEs(w, ctes, walk)
+			}
+		}
+		for _, c := range children {
+			if _, ok := c.(*Common_table_stmtContext); !ok {
+				walk(c, ctes)
+			}
+		}
+	}
+	walk(stmt.stmt, nil)
+	return refs, nil
+}
+
+// withCTEs walks the definitions of the common table expressions in the given WITH clause,
+// and returns the scope of the statement it is attached to. In a non-recursive clause, each
+// CTE can reference only the CTEs that were defined before it. In a recursive clause, all
+// CTEs are visible in all definitions, including their own.
+func withCTEs(w *Common_table_stmtContext, ctes parseutil.CTEs, walk func(antlr.Tree, parseutil.CTEs)) parseutil.CTEs {
+	var (
+		defs  = w.AllCommon_table_expression()
+		names = make([]string, 0, len(defs))
+	)
+	for _, c := range defs {
+		names = append(names, unquote(c.(*Common_table_expressionContext).Table_name().GetText()))
+	}
+	for i, c := range defs {
+		scope := ctes.With(names[:i]...)
+		if w.RECURSIVE_() != nil {
+			scope = ctes.With(names...)
+		}
+		walk(c, scope)
+	}
+	return ctes.With(names...)
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"ariga.io/atlas/cmd/atlas/internal/sqlparse/myparse"
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse/parseutil"
 	"ariga.io/atlas/cmd/atlas/internal/sqlparse/pgparse"
 	"ariga.io/atlas/cmd/atlas/internal/sqlparse/sqliteparse"
 	"ariga.io/atlas/sql/migrate"
@@ -19,6 +20,9 @@ import (
 
 // A Parser represents an SQL file parser used to fix, search and enrich schema.Changes.
 type Parser interface {
+	// StmtParser parses a single statement into the schema changes it describes.
+	migrate.StmtParser
+
 	// FixChange fixes the changes according to the given statement.
 	FixChange(d migrate.Driver, stmt string, changes schema.Changes) (schema.Changes, error)
 
@@ -29,6 +33,10 @@ type Parser interface {
 	//	UPDATE <table> SET <column> = <value> WHERE <column> IS NULL
 	//
 	ColumnFilledBefore(migrate.File, *schema.Table, *schema.Column, int) (bool, error)
+
+	// TableRefs returns the tables and views referenced by the given query.
+	// It is used to derive the dependencies of views from their definitions.
+	TableRefs(string) ([]*parseutil.TableRef, error)
 }
 
 // drivers specific fixers.
@@ -48,6 +56,14 @@ func ParserFor(name string) Parser {
 	return nil
 }
 
+// LinkViewDeps derives the dependencies of views in the realm that
+// do not define them explicitly, using the parser of the given driver.
+func LinkViewDeps(name string, r *schema.Realm) {
+	if p := ParserFor(name); p != nil {
+		parseutil.LinkViewDeps(r, p.TableRefs)
+	}
+}
+
 func init() {
 	Register(mysql.DriverName, &myparse.Parser{})
 	Register(postgres.DriverName, &pgparse.Parser{})
@@ -18,17 +18,23 @@ import (
 	_ "ariga.io/atlas/sql/mysql/mysqlcheck"
 	_ "ariga.io/atlas/sql/postgres"
 	_ "ariga.io/atlas/sql/postgres/postgrescheck"
+	"ariga.io/atlas/sql/sqlclient"
 	_ "ariga.io/atlas/sql/sqlite"
 	_ "ariga.io/atlas/sql/sqlite/sqlitecheck"
 	"github.com/mitchellh/go-homedir"
 	"golang.org/x/mod/semver"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 	_ "github.com/libsql/libsql-client-go/libsql"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+func init() {
+	// Allow cloud URLs to verify the certificates of MySQL servers using the CA of the provider.
+	sqlclient.RegisterTLSConfigFunc("mysql", mysql.RegisterTLSConfig)
+}
+
 func main() {
 	cmdapi.Root.SetOut(os.Stdout)
 	ctx := newContext()
@@ -0,0 +1,216 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"ariga.io/atlas/sql/sqlclient"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type (
+	// Option allows configuring the registration of a plugin using functional options.
+	Option func(*options)
+
+	options struct {
+		args    []string
+		env     []string
+		stderr  io.Writer
+		timeout time.Duration
+		reg     []sqlclient.RegisterOption
+	}
+)
+
+// WithArgs sets the command-line arguments the plugin executable is started with.
+func WithArgs(args ...string) Option {
+	return func(o *options) {
+		o.args = append(o.args, args...)
+	}
+}
+
+// WithEnv appends the given "key=value" pairs to the environment of the plugin process.
+func WithEnv(env ...string) Option {
+	return func(o *options) {
+		o.env = append(o.env, env...)
+	}
+}
+
+// WithStderr sets the writer the standard error of the plugin process is written to.
+// By default, it is written to the standard error of the host.
+func WithStderr(w io.Writer) Option {
+	return func(o *options) {
+		o.stderr = w
+	}
+}
+
+// WithStartTimeout sets the time to wait for the plugin to write its handshake line.
+// The default is 10 seconds.
+func WithStartTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithRegisterOptions sets the options the plugin is registered with (e.g. its flavours).
+func WithRegisterOptions(opts ...sqlclient.RegisterOption) Option {
+	return func(o *options) {
+		o.reg = append(o.reg, opts...)
+	}
+}
+
+// Register registers the plugin executable in the given path under the given name (URL scheme).
+// The executable is started every time a client is opened for a URL of the registered scheme,
+// and it is stopped when the client is closed.
+//
+//	err := plugin.Register("mydb", "/usr/local/bin/atlas-driver-mydb", plugin.WithArgs("--verbose"))
+func Register(name, path string, opts ...Option) error {
+	o := &options{stderr: os.Stderr, timeout: 10 * time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return sqlclient.RegisterPlugin(sqlclient.Plugin{
+		Name:       name,
+		APIVersion: sqlclient.PluginAPIVersion,
+		Opener: sqlclient.OpenerFunc(func(ctx context.Context, u *url.URL) (*sqlclient.Client, error) {
+			return start(ctx, path, o, u)
+		}),
+		Options: o.reg,
+	})
+}
+
+// Open opens a client for the given URL on an established connection to a plugin server.
+// The connection is closed when the client is closed. Connections to servers that require
+// a secret must be dialed with DialSecret.
+func Open(ctx context.Context, cc *grpc.ClientConn, u *url.URL) (*sqlclient.Client, error) {
+	return open(ctx, cc, u, cc.Close)
+}
+
+func open(ctx context.Context, cc *grpc.ClientConn, u *url.URL, closer func() error) (*sqlclient.Client, error) {
+	resp := &HandshakeResponse{}
+	if err := invoke(ctx, cc, "Handshake", &HandshakeRequest{APIVersion: sqlclient.PluginAPIVersion, URL: u.String()}, resp); err != nil {
+		return nil, errors.Join(fmt.Errorf("plugin: handshake: %w", err), closer())
+	}
+	if resp.APIVersion != sqlclient.PluginAPIVersion {
+		return nil, errors.Join(fmt.Errorf("plugin: api version %d is not supported, expected %d", resp.APIVersion, sqlclient.PluginAPIVersion), closer())
+	}
+	// Closing the database closes the connector, and therefore, the plugin
+	// is stopped only after all connections to it were closed.
+	db := sql.OpenDB(&connector{cc: cc, closer: closer})
+	client, err := sqlclient.OpenDB(resp.Dialect, db, u)
+	if err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+	return client, nil
+}
+
+// start starts the plugin process and opens a client for it.
+func start(ctx context.Context, path string, o *options, u *url.URL) (*sqlclient.Client, error) {
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(path, o.args...)
+	cmd.Env = append(append(os.Environ(), o.env...), SecretEnv+"="+secret)
+	cmd.Stderr = o.stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("plugin: starting %q: %w", path, err)
+	}
+	p := &process{cmd: cmd, stdin: stdin}
+	network, addr, err := handshake(ctx, stdout, o.timeout)
+	if err != nil {
+		return nil, errors.Join(err, p.Close())
+	}
+	// Discard the rest of the output to avoid blocking the plugin.
+	go func() { _, _ = io.Copy(io.Discard, stdout) }()
+	target := addr
+	if network == "unix" {
+		target = "unix:" + addr
+	}
+	cc, err := grpc.DialContext(
+		ctx, target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(codec{})),
+		DialSecret(secret),
+	)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("plugin: dial %s: %w", addr, err), p.Close())
+	}
+	return open(ctx, cc, u, func() error {
+		return errors.Join(cc.Close(), p.Close())
+	})
+}
+
+// handshake reads the handshake line of the plugin.
+func handshake(ctx context.Context, r io.Reader, timeout time.Duration) (network, addr string, err error) {
+	type result struct {
+		line string
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		line, err := bufio.NewReader(r).ReadString('\n')
+		ch <- result{line: line, err: err}
+	}()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		return "", "", fmt.Errorf("plugin: waiting for handshake: %w", ctx.Err())
+	case res := <-ch:
+		if res.err != nil {
+			return "", "", fmt.Errorf("plugin: reading handshake: %w", res.err)
+		}
+		return parseHandshake(res.line)
+	}
+}
+
+// process wraps the plugin process.
+type process struct {
+	cmd   *exec.Cmd
+	stdin io.Closer
+	once  sync.Once
+	err   error
+}
+
+// Close stops the plugin by closing its standard input, and kills
+// the process if it did not exit within a reasonable time.
+func (p *process) Close() error {
+	p.once.Do(func() {
+		done := make(chan error, 1)
+		go func() { done <- p.cmd.Wait() }()
+		if err := p.stdin.Close(); err != nil {
+			p.err = err
+		}
+		select {
+		case err := <-done:
+			p.err = errors.Join(p.err, err)
+		case <-time.After(5 * time.Second):
+			p.err = errors.Join(p.err, p.cmd.Process.Kill())
+			<-done
+		}
+	})
+	return p.err
+}
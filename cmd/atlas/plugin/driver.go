@@ -0,0 +1,244 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package plugin
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// The types below implement a database/sql driver that executes
+// statements and queries on the database connection of the plugin.
+type (
+	connector struct {
+		cc     *grpc.ClientConn
+		closer func() error
+	}
+	conn struct {
+		cc *grpc.ClientConn
+		id int64
+	}
+	tx struct {
+		*conn
+	}
+	stmt struct {
+		*conn
+		query string
+	}
+	rows struct {
+		columns []Column
+		rows    [][]Value
+	}
+	result struct {
+		ExecResponse
+	}
+)
+
+var (
+	_ driver.Connector                      = (*connector)(nil)
+	_ io.Closer                             = (*connector)(nil)
+	_ driver.ConnBeginTx                    = (*conn)(nil)
+	_ driver.ExecerContext                  = (*conn)(nil)
+	_ driver.QueryerContext                 = (*conn)(nil)
+	_ driver.StmtExecContext                = (*stmt)(nil)
+	_ driver.StmtQueryContext               = (*stmt)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+)
+
+// Connect implements the driver.Connector interface.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	resp := &ConnRequest{}
+	if err := invoke(ctx, c.cc, "Connect", &Empty{}, resp); err != nil {
+		return nil, err
+	}
+	return &conn{cc: c.cc, id: resp.Conn}, nil
+}
+
+// Driver implements the driver.Connector interface.
+func (c *connector) Driver() driver.Driver { return drv{} }
+
+// Close stops the plugin. It is called by sql.DB.Close.
+func (c *connector) Close() error { return c.closer() }
+
+// drv is returned by the connector, but it cannot open connections
+// by name, as connections are bound to the running plugin process.
+type drv struct{}
+
+// Open implements the driver.Driver interface.
+func (drv) Open(string) (driver.Conn, error) {
+	return nil, errors.New("plugin: connections can be opened only using the plugin connector")
+}
+
+// Prepare implements the driver.Conn interface.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+// Close implements the driver.Conn interface.
+func (c *conn) Close() error {
+	return invoke(context.Background(), c.cc, "Disconnect", &ConnRequest{Conn: c.id}, &Empty{})
+}
+
+// Begin implements the driver.Conn interface.
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements the driver.ConnBeginTx interface.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	r := &BeginRequest{Conn: c.id, ReadOnly: opts.ReadOnly, Isolation: int(opts.Isolation)}
+	if err := invoke(ctx, c.cc, "Begin", r, &Empty{}); err != nil {
+		return nil, err
+	}
+	return &tx{conn: c}, nil
+}
+
+// ExecContext implements the driver.ExecerContext interface.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r, err := c.request(query, args)
+	if err != nil {
+		return nil, err
+	}
+	res := &result{}
+	if err := invoke(ctx, c.cc, "Exec", r, &res.ExecResponse); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// QueryContext implements the driver.QueryerContext interface.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r, err := c.request(query, args)
+	if err != nil {
+		return nil, err
+	}
+	resp := &QueryResponse{}
+	if err := invoke(ctx, c.cc, "Query", r, resp); err != nil {
+		return nil, err
+	}
+	return &rows{columns: resp.Columns, rows: resp.Rows}, nil
+}
+
+func (c *conn) request(query string, args []driver.NamedValue) (*ExecRequest, error) {
+	vs := make([]any, len(args))
+	for i, a := range args {
+		if a.Name != "" {
+			return nil, errors.New("plugin: named arguments are not supported")
+		}
+		vs[i] = a.Value
+	}
+	values, err := values(vs)
+	if err != nil {
+		return nil, err
+	}
+	return &ExecRequest{Conn: c.id, Query: query, Args: values}, nil
+}
+
+// Commit implements the driver.Tx interface.
+func (t *tx) Commit() error {
+	return invoke(context.Background(), t.cc, "Commit", &ConnRequest{Conn: t.id}, &Empty{})
+}
+
+// Rollback implements the driver.Tx interface.
+func (t *tx) Rollback() error {
+	return invoke(context.Background(), t.cc, "Rollback", &ConnRequest{Conn: t.id}, &Empty{})
+}
+
+// Close implements the driver.Stmt interface.
+func (*stmt) Close() error { return nil }
+
+// NumInput implements the driver.Stmt interface.
+func (*stmt) NumInput() int { return -1 }
+
+// Exec implements the driver.Stmt interface.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), named(args))
+}
+
+// Query implements the driver.Stmt interface.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), named(args))
+}
+
+// ExecContext implements the driver.StmtExecContext interface.
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+// QueryContext implements the driver.StmtQueryContext interface.
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	nv := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return nv
+}
+
+// Columns implements the driver.Rows interface.
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.columns))
+	for i, c := range r.columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// ColumnTypeDatabaseTypeName implements the driver.RowsColumnTypeDatabaseTypeName interface.
+func (r *rows) ColumnTypeDatabaseTypeName(i int) string {
+	return r.columns[i].Type
+}
+
+// Close implements the driver.Rows interface.
+func (r *rows) Close() error {
+	r.rows = nil
+	return nil
+}
+
+// Next implements the driver.Rows interface.
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	for i := range dest {
+		if i >= len(row) {
+			break
+		}
+		v, err := row[i].Any()
+		if err != nil {
+			return err
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+// LastInsertId implements the driver.Result interface.
+func (r *result) LastInsertId() (int64, error) { return r.ExecResponse.LastInsertID, nil }
+
+// RowsAffected implements the driver.Result interface.
+func (r *result) RowsAffected() (int64, error) { return r.ExecResponse.RowsAffected, nil }
+
+// invoke calls the given method of the plugin service. Errors returned by the plugin
+// are unwrapped from their gRPC status, to keep the original database error message.
+func invoke(ctx context.Context, cc *grpc.ClientConn, name string, req, resp any) error {
+	if err := cc.Invoke(ctx, method(name), req, resp, grpc.ForceCodec(codec{})); err != nil {
+		if s, ok := status.FromError(err); ok {
+			return errors.New(s.Message())
+		}
+		return err
+	}
+	return nil
+}
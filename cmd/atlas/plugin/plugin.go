@@ -0,0 +1,285 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package plugin implements drivers that run as external processes and communicate
+// with Atlas over gRPC. It allows third parties to ship drivers for databases that
+// speak the dialect of a builtin driver (e.g. postgres), but require a different
+// transport or client library, without forking the repository.
+//
+// A plugin is an executable that calls Serve from its main function:
+//
+//	func main() {
+//		err := plugin.Serve(plugin.ServeConfig{
+//			Dialect: "postgres",
+//			Open: func(ctx context.Context, u *url.URL) (*sql.DB, error) {
+//				return sql.Open("mydb", u.String())
+//			},
+//		})
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// And it is registered by the host application using Register:
+//
+//	err := plugin.Register("mydb", "/usr/local/bin/atlas-driver-mydb")
+//
+// On open, the host starts the executable and reads the address the plugin listens
+// on from the first line of its standard output. The line has the following format:
+//
+//	atlas-plugin|<api-version>|<network>|<address>
+//
+// The plugin exits when its standard input is closed. Plugins written in Go listen on
+// a unix socket that is accessible only by the user running them. In addition, the host
+// starts the plugin with a random secret in the ATLAS_PLUGIN_SECRET environment variable,
+// and sends it in the "atlas-plugin-secret" metadata of every call. Plugins must reject
+// calls without it, as they run statements with the credentials of their database.
+//
+// The messages of the service are encoded as JSON (content-subtype "json"), and therefore,
+// plugins can also be implemented in other languages that have a gRPC implementation.
+package plugin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/sqlclient"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName is the full name of the gRPC service implemented by plugins.
+const ServiceName = "atlas.sqlclient.plugin.v1.Driver"
+
+// SecretEnv is the environment variable that holds the secret the plugin was started with.
+const SecretEnv = "ATLAS_PLUGIN_SECRET"
+
+const (
+	// handshakePrefix is the prefix of the line written by plugins on start.
+	handshakePrefix = "atlas-plugin"
+	// secretKey is the metadata key of the secret sent with every call.
+	secretKey = "atlas-plugin-secret"
+)
+
+type (
+	// HandshakeRequest is sent by the host once the connection to the plugin is
+	// established. The plugin opens its database connection for the given URL.
+	HandshakeRequest struct {
+		APIVersion int    `json:"api_version"`
+		URL        string `json:"url"`
+	}
+
+	// HandshakeResponse holds the dialect of the database the plugin is connected to.
+	// It must be the name of a builtin driver (e.g. mysql or postgres).
+	HandshakeResponse struct {
+		APIVersion int    `json:"api_version"`
+		Dialect    string `json:"dialect"`
+	}
+
+	// ConnRequest identifies a connection (session) that was opened by Connect.
+	ConnRequest struct {
+		Conn int64 `json:"conn"`
+	}
+
+	// BeginRequest starts a transaction on the given connection.
+	BeginRequest struct {
+		Conn      int64 `json:"conn"`
+		ReadOnly  bool  `json:"read_only,omitempty"`
+		Isolation int   `json:"isolation,omitempty"`
+	}
+
+	// ExecRequest executes a statement, or a query, on the given connection.
+	// Statements are executed in the transaction of the connection, if exists.
+	ExecRequest struct {
+		Conn  int64   `json:"conn"`
+		Query string  `json:"query"`
+		Args  []Value `json:"args,omitempty"`
+	}
+
+	// ExecResponse holds the result of an executed statement.
+	ExecResponse struct {
+		LastInsertID int64 `json:"last_insert_id"`
+		RowsAffected int64 `json:"rows_affected"`
+	}
+
+	// QueryResponse holds the result set of a query.
+	QueryResponse struct {
+		Columns []Column  `json:"columns"`
+		Rows    [][]Value `json:"rows,omitempty"`
+	}
+
+	// Column describes a column in a result set.
+	Column struct {
+		Name string `json:"name"`
+		Type string `json:"type,omitempty"`
+	}
+
+	// Empty is used by methods that do not have a request or a response.
+	Empty struct{}
+
+	// Value holds a value of a query argument or a result set. The Kind is
+	// one of: null, int, float, bool, string, bytes or time. Time values are
+	// encoded as RFC 3339 strings with nanoseconds.
+	Value struct {
+		Kind  string  `json:"kind"`
+		Int   int64   `json:"int,omitempty"`
+		Float float64 `json:"float,omitempty"`
+		Bool  bool    `json:"bool,omitempty"`
+		Str   string  `json:"str,omitempty"`
+		Bytes []byte  `json:"bytes,omitempty"`
+	}
+)
+
+// NewValue returns the Value of the given driver.Value.
+func NewValue(v any) (Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return Value{Kind: "null"}, nil
+	case int64:
+		return Value{Kind: "int", Int: v}, nil
+	case float64:
+		return Value{Kind: "float", Float: v}, nil
+	case bool:
+		return Value{Kind: "bool", Bool: v}, nil
+	case string:
+		return Value{Kind: "string", Str: v}, nil
+	case []byte:
+		return Value{Kind: "bytes", Bytes: v}, nil
+	case time.Time:
+		return Value{Kind: "time", Str: v.Format(time.RFC3339Nano)}, nil
+	default:
+		return Value{}, fmt.Errorf("plugin: unsupported value type %T", v)
+	}
+}
+
+// Any returns the driver.Value of the Value.
+func (v Value) Any() (any, error) {
+	switch v.Kind {
+	case "null":
+		return nil, nil
+	case "int":
+		return v.Int, nil
+	case "float":
+		return v.Float, nil
+	case "bool":
+		return v.Bool, nil
+	case "string":
+		return v.Str, nil
+	case "bytes":
+		if v.Bytes == nil {
+			return []byte{}, nil
+		}
+		return v.Bytes, nil
+	case "time":
+		return time.Parse(time.RFC3339Nano, v.Str)
+	default:
+		return nil, fmt.Errorf("plugin: unexpected value kind %q", v.Kind)
+	}
+}
+
+// values converts the given driver values to plugin values.
+func values(vs []any) ([]Value, error) {
+	args := make([]Value, len(vs))
+	for i, v := range vs {
+		a, err := NewValue(v)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = a
+	}
+	return args, nil
+}
+
+// anys converts the given plugin values to driver values.
+func anys(vs []Value) ([]any, error) {
+	args := make([]any, len(vs))
+	for i, v := range vs {
+		a, err := v.Any()
+		if err != nil {
+			return nil, err
+		}
+		args[i] = a
+	}
+	return args, nil
+}
+
+// codec is the gRPC codec used by the plugin service.
+type codec struct{}
+
+var _ encoding.Codec = codec{}
+
+// Marshal implements encoding.Codec.
+func (codec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal implements encoding.Codec.
+func (codec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// Name implements encoding.Codec.
+func (codec) Name() string { return "json" }
+
+// DialSecret returns a dial option that sends the given secret in the metadata of every call.
+// It is used for connecting to plugin servers that were created with a secret by NewServer.
+func DialSecret(secret string) grpc.DialOption {
+	return grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(metadata.AppendToOutgoingContext(ctx, secretKey, secret), method, req, reply, cc, opts...)
+	})
+}
+
+// requireSecret returns a server interceptor that rejects calls without the given secret.
+func requireSecret(secret string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if vs := md.Get(secretKey); len(vs) != 1 || subtle.ConstantTimeCompare([]byte(vs[0]), []byte(secret)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "plugin: invalid or missing secret")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// newSecret returns a random secret for a plugin process.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("plugin: generate secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// handshakeLine returns the line written by the plugin on start.
+func handshakeLine(network, addr string) string {
+	return strings.Join([]string{handshakePrefix, strconv.Itoa(sqlclient.PluginAPIVersion), network, addr}, "|")
+}
+
+// parseHandshake parses the line written by the plugin on start.
+func parseHandshake(line string) (network, addr string, err error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 4 || parts[0] != handshakePrefix {
+		return "", "", fmt.Errorf("plugin: unexpected handshake line: %q", line)
+	}
+	switch v, err := strconv.Atoi(parts[1]); {
+	case err != nil:
+		return "", "", fmt.Errorf("plugin: invalid api version %q: %w", parts[1], err)
+	case v != sqlclient.PluginAPIVersion:
+		return "", "", fmt.Errorf("plugin: api version %d is not supported, expected %d", v, sqlclient.PluginAPIVersion)
+	}
+	if parts[2] != "tcp" && parts[2] != "unix" {
+		return "", "", fmt.Errorf("plugin: unsupported network %q", parts[2])
+	}
+	if parts[3] == "" {
+		return "", "", errors.New("plugin: missing plugin address")
+	}
+	return parts[2], parts[3], nil
+}
@@ -0,0 +1,275 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package plugin
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv("ATLAS_PLUGIN_HELPER") == "1" {
+		pluginHelper()
+	}
+	// The dialect used by the plugins in tests.
+	sqlclient.Register("plugintest", sqlclient.DriverOpener(func(schema.ExecQuerier) (migrate.Driver, error) {
+		return nil, nil
+	}))
+	os.Exit(m.Run())
+}
+
+// pluginHelper acts as a plugin executable that serves a mocked database.
+func pluginHelper() {
+	err := Serve(ServeConfig{
+		Dialect: "plugintest",
+		Open: func(_ context.Context, u *url.URL) (*sql.DB, error) {
+			db, m, err := sqlmock.New()
+			if err != nil {
+				return nil, err
+			}
+			m.ExpectQuery(regexp.QuoteMeta("SELECT name FROM users WHERE id = ?")).
+				WithArgs(int64(1)).
+				WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(u.Path))
+			m.ExpectClose()
+			return db, nil
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(0)
+}
+
+func TestRegister(t *testing.T) {
+	err := Register("plugintest+proc", os.Args[0], WithEnv("ATLAS_PLUGIN_HELPER=1"), WithStartTimeout(time.Minute))
+	require.NoError(t, err)
+	err = Register("plugintest+proc", os.Args[0])
+	require.EqualError(t, err, `sql/sqlclient: plugin "plugintest+proc": scheme "plugintest+proc" is already registered`)
+
+	c, err := sqlclient.Open(context.Background(), "plugintest+proc://localhost/a8m")
+	require.NoError(t, err)
+	require.Equal(t, "plugintest", c.Name)
+	require.Equal(t, "plugintest+proc", c.URL.Scheme)
+	var name string
+	require.NoError(t, c.DB.QueryRow("SELECT name FROM users WHERE id = ?", 1).Scan(&name))
+	require.Equal(t, "/a8m", name)
+	require.NoError(t, c.Close())
+
+	require.NoError(t, Register("plugintest+missing", "/path/to/missing/plugin"))
+	_, err = sqlclient.Open(context.Background(), "plugintest+missing://localhost")
+	require.ErrorContains(t, err, `plugin: starting "/path/to/missing/plugin"`)
+}
+
+func TestOpen(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	h, err := NewHandler(ServeConfig{
+		Dialect: "plugintest",
+		Open: func(context.Context, *url.URL) (*sql.DB, error) {
+			return db, nil
+		},
+	})
+	require.NoError(t, err)
+	cc := serve(t, h)
+	u, err := url.Parse("remote://localhost/db")
+	require.NoError(t, err)
+	c, err := Open(context.Background(), cc, u)
+	require.NoError(t, err)
+	require.Equal(t, "plugintest", c.Name)
+
+	// Queries.
+	now := time.Date(2023, 4, 1, 10, 0, 0, 5, time.UTC)
+	m.ExpectQuery(regexp.QuoteMeta("SELECT * FROM t WHERE a = ? AND b = ? AND c = ? AND d IS ?")).
+		WithArgs("a", 1.5, true, nil).
+		WillReturnRows(
+			sqlmock.NewRowsWithColumnDefinition(
+				sqlmock.NewColumn("id").OfType("BIGINT", int64(0)),
+				sqlmock.NewColumn("data").OfType("BLOB", []byte(nil)),
+				sqlmock.NewColumn("created_at").OfType("TIMESTAMP", now),
+				sqlmock.NewColumn("deleted_at").OfType("TIMESTAMP", now),
+			).AddRow(int64(1), []byte("data"), now, nil),
+		)
+	rows, err := c.DB.QueryContext(context.Background(), "SELECT * FROM t WHERE a = ? AND b = ? AND c = ? AND d IS ?", "a", 1.5, true, nil)
+	require.NoError(t, err)
+	types, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	require.Equal(t, "BIGINT", types[0].DatabaseTypeName())
+	require.Equal(t, "TIMESTAMP", types[2].DatabaseTypeName())
+	require.True(t, rows.Next())
+	var (
+		id        int64
+		data      []byte
+		createdAt time.Time
+		deletedAt sql.NullTime
+	)
+	require.NoError(t, rows.Scan(&id, &data, &createdAt, &deletedAt))
+	require.Equal(t, int64(1), id)
+	require.Equal(t, []byte("data"), data)
+	require.True(t, now.Equal(createdAt))
+	require.False(t, deletedAt.Valid)
+	require.False(t, rows.Next())
+	require.NoError(t, rows.Close())
+
+	// Statements and errors.
+	m.ExpectExec(regexp.QuoteMeta("INSERT INTO t VALUES (1)")).
+		WillReturnResult(sqlmock.NewResult(10, 1))
+	res, err := c.DB.ExecContext(context.Background(), "INSERT INTO t VALUES (1)")
+	require.NoError(t, err)
+	id, err = res.LastInsertId()
+	require.NoError(t, err)
+	require.Equal(t, int64(10), id)
+	m.ExpectExec(regexp.QuoteMeta("DROP TABLE t")).
+		WillReturnError(sql.ErrNoRows)
+	_, err = c.DB.ExecContext(context.Background(), "DROP TABLE t")
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	// Transactions.
+	m.ExpectBegin()
+	m.ExpectExec(regexp.QuoteMeta("CREATE TABLE t(c int)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectCommit()
+	m.ExpectBegin()
+	m.ExpectRollback()
+	tx, err := c.Tx(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = tx.Tx.ExecContext(context.Background(), "CREATE TABLE t(c int)")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	tx, err = c.Tx(context.Background(), nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	m.ExpectClose()
+	require.NoError(t, c.Close())
+	require.NoError(t, h.(*server).close())
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestOpen_Secret(t *testing.T) {
+	h, err := NewHandler(ServeConfig{
+		Dialect: "plugintest",
+		Open: func(context.Context, *url.URL) (*sql.DB, error) {
+			db, _, err := sqlmock.New()
+			return db, err
+		},
+	})
+	require.NoError(t, err)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	gs := NewServer(h, "secret")
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+	u, err := url.Parse("remote://localhost")
+	require.NoError(t, err)
+	for _, opts := range [][]grpc.DialOption{nil, {DialSecret("guess")}} {
+		cc, err := grpc.Dial(lis.Addr().String(), append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+		require.NoError(t, err)
+		err = cc.Invoke(context.Background(), method("Handshake"), &HandshakeRequest{APIVersion: sqlclient.PluginAPIVersion, URL: u.String()}, &HandshakeResponse{}, grpc.ForceCodec(codec{}))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.NoError(t, cc.Close())
+	}
+}
+
+func TestListen(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not supported on windows")
+	}
+	lis, cleanup, err := listen()
+	require.NoError(t, err)
+	path := lis.Addr().String()
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	require.NoError(t, lis.Close())
+	cleanup()
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestOpen_Errors(t *testing.T) {
+	h, err := NewHandler(ServeConfig{
+		Dialect: "unknown",
+		Open: func(context.Context, *url.URL) (*sql.DB, error) {
+			db, _, err := sqlmock.New()
+			return db, err
+		},
+	})
+	require.NoError(t, err)
+	u, err := url.Parse("remote://localhost")
+	require.NoError(t, err)
+	_, err = Open(context.Background(), serve(t, h), u)
+	require.EqualError(t, err, `sql/sqlclient: unknown dialect "unknown"`)
+
+	_, err = NewHandler(ServeConfig{Dialect: "plugintest"})
+	require.EqualError(t, err, "plugin: missing open function")
+	_, err = NewHandler(ServeConfig{})
+	require.EqualError(t, err, "plugin: missing dialect")
+	err = Serve(ServeConfig{Dialect: "plugintest", Open: func(context.Context, *url.URL) (*sql.DB, error) { return nil, nil }})
+	require.EqualError(t, err, "plugin: missing ATLAS_PLUGIN_SECRET, plugins must be started by the host")
+}
+
+func TestParseHandshake(t *testing.T) {
+	network, addr, err := parseHandshake(handshakeLine("tcp", "127.0.0.1:5000") + "\n")
+	require.NoError(t, err)
+	require.Equal(t, "tcp", network)
+	require.Equal(t, "127.0.0.1:5000", addr)
+
+	_, _, err = parseHandshake("hello world\n")
+	require.EqualError(t, err, `plugin: unexpected handshake line: "hello world\n"`)
+	_, _, err = parseHandshake("atlas-plugin|2|tcp|127.0.0.1:5000")
+	require.EqualError(t, err, "plugin: api version 2 is not supported, expected 1")
+	_, _, err = parseHandshake("atlas-plugin|1|udp|127.0.0.1:5000")
+	require.EqualError(t, err, `plugin: unsupported network "udp"`)
+}
+
+func TestValue(t *testing.T) {
+	for _, v := range []any{nil, int64(1), 1.5, true, "text", []byte("bytes"), time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)} {
+		pv, err := NewValue(v)
+		require.NoError(t, err)
+		v1, err := pv.Any()
+		require.NoError(t, err)
+		require.Equal(t, v, v1)
+	}
+	_, err := NewValue(1)
+	require.EqualError(t, err, "plugin: unsupported value type int")
+	_, err = Value{Kind: "uuid"}.Any()
+	require.EqualError(t, err, `plugin: unexpected value kind "uuid"`)
+}
+
+// serve serves the handler on a local port and returns a connection to it.
+func serve(t *testing.T, h Handler) *grpc.ClientConn {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	gs := NewServer(h, "secret")
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+	cc, err := grpc.Dial(
+		lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(codec{})),
+		DialSecret("secret"),
+	)
+	require.NoError(t, err)
+	return cc
+}
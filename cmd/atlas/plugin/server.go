@@ -0,0 +1,385 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package plugin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"ariga.io/atlas/sql/sqlclient"
+
+	"google.golang.org/grpc"
+)
+
+// ServeConfig configures the plugin server.
+type ServeConfig struct {
+	// Dialect is the name of the builtin driver whose dialect the database
+	// speaks, and is used for inspecting, diffing and planning changes.
+	Dialect string
+	// Open opens the database connection for the URL the plugin was opened with.
+	Open func(context.Context, *url.URL) (*sql.DB, error)
+}
+
+// Serve starts the plugin server on a unix socket, writes the handshake line to the
+// standard output, and serves the host until the standard input is closed. Calls are
+// accepted only with the secret the host started the plugin with (see SecretEnv).
+func Serve(cfg ServeConfig) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	secret := os.Getenv(SecretEnv)
+	if secret == "" {
+		return fmt.Errorf("plugin: missing %s, plugins must be started by the host", SecretEnv)
+	}
+	lis, cleanup, err := listen()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	srv := &server{ServeConfig: cfg}
+	gs := NewServer(srv, secret)
+	done := make(chan error, 1)
+	go func() { done <- gs.Serve(lis) }()
+	if _, err := fmt.Fprintln(os.Stdout, handshakeLine("unix", lis.Addr().String())); err != nil {
+		gs.Stop()
+		return fmt.Errorf("plugin: write handshake: %w", err)
+	}
+	go func() {
+		// The host closes the standard input to stop the plugin.
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		gs.GracefulStop()
+	}()
+	err = <-done
+	return errors.Join(err, srv.close())
+}
+
+// NewServer returns a gRPC server with the plugin service registered. It can be used
+// by plugins that manage their own listeners, or for serving plugins in process. Calls
+// without the given secret are rejected, unless it is empty, which should be used only
+// for servers that are not reachable by other processes.
+func NewServer(h Handler, secret string) *grpc.Server {
+	opts := []grpc.ServerOption{grpc.ForceServerCodec(codec{})}
+	if secret != "" {
+		opts = append(opts, grpc.UnaryInterceptor(requireSecret(secret)))
+	}
+	gs := grpc.NewServer(opts...)
+	gs.RegisterService(&serviceDesc, h)
+	return gs
+}
+
+// listen listens on a unix socket in a private directory, that is accessible
+// only by the current user. The returned function removes the directory.
+func listen() (net.Listener, func(), error) {
+	dir, err := os.MkdirTemp("", "atlas-plugin-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("plugin: create socket directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	path := filepath.Join(dir, "plugin.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("plugin: listen: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		cleanup()
+		return nil, nil, errors.Join(fmt.Errorf("plugin: set socket permissions: %w", err), lis.Close())
+	}
+	return lis, cleanup, nil
+}
+
+// NewHandler returns the Handler of the plugin service for the given config.
+func NewHandler(cfg ServeConfig) (Handler, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &server{ServeConfig: cfg}, nil
+}
+
+func (cfg ServeConfig) validate() error {
+	switch {
+	case cfg.Dialect == "":
+		return errors.New("plugin: missing dialect")
+	case cfg.Open == nil:
+		return errors.New("plugin: missing open function")
+	}
+	return nil
+}
+
+// Handler is the interface implemented by the plugin service.
+type Handler interface {
+	Handshake(context.Context, *HandshakeRequest) (*HandshakeResponse, error)
+	Connect(context.Context, *Empty) (*ConnRequest, error)
+	Disconnect(context.Context, *ConnRequest) (*Empty, error)
+	Exec(context.Context, *ExecRequest) (*ExecResponse, error)
+	Query(context.Context, *ExecRequest) (*QueryResponse, error)
+	Begin(context.Context, *BeginRequest) (*Empty, error)
+	Commit(context.Context, *ConnRequest) (*Empty, error)
+	Rollback(context.Context, *ConnRequest) (*Empty, error)
+}
+
+type (
+	// server implements the Handler interface using a database/sql connection.
+	server struct {
+		ServeConfig
+		mu      sync.Mutex
+		db      *sql.DB
+		last    int64
+		session map[int64]*session
+	}
+	// session holds a connection that was opened by the host, and its active transaction.
+	session struct {
+		conn *sql.Conn
+		tx   *sql.Tx
+	}
+)
+
+var _ Handler = (*server)(nil)
+
+// Handshake implements the Handler interface.
+func (s *server) Handshake(ctx context.Context, r *HandshakeRequest) (*HandshakeResponse, error) {
+	if r.APIVersion != sqlclient.PluginAPIVersion {
+		return nil, fmt.Errorf("plugin: api version %d is not supported, expected %d", r.APIVersion, sqlclient.PluginAPIVersion)
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, fmt.Errorf("plugin: parse url: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db != nil {
+		return nil, errors.New("plugin: handshake was already performed")
+	}
+	if s.db, err = s.Open(ctx, u); err != nil {
+		return nil, err
+	}
+	s.session = make(map[int64]*session)
+	return &HandshakeResponse{APIVersion: sqlclient.PluginAPIVersion, Dialect: s.Dialect}, nil
+}
+
+// Connect implements the Handler interface.
+func (s *server) Connect(ctx context.Context, _ *Empty) (*ConnRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		return nil, errors.New("plugin: handshake was not performed")
+	}
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.last++
+	s.session[s.last] = &session{conn: conn}
+	return &ConnRequest{Conn: s.last}, nil
+}
+
+// Disconnect implements the Handler interface.
+func (s *server) Disconnect(_ context.Context, r *ConnRequest) (*Empty, error) {
+	s.mu.Lock()
+	ss, ok := s.session[r.Conn]
+	delete(s.session, r.Conn)
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("plugin: unknown connection %d", r.Conn)
+	}
+	return &Empty{}, ss.close()
+}
+
+// Exec implements the Handler interface.
+func (s *server) Exec(ctx context.Context, r *ExecRequest) (*ExecResponse, error) {
+	ss, err := s.lookup(r.Conn)
+	if err != nil {
+		return nil, err
+	}
+	args, err := anys(r.Args)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ss.execQuerier().ExecContext(ctx, r.Query, args...)
+	if err != nil {
+		return nil, err
+	}
+	// Drivers that do not support these methods return an error, and zero is reported instead.
+	id, _ := res.LastInsertId()
+	n, _ := res.RowsAffected()
+	return &ExecResponse{LastInsertID: id, RowsAffected: n}, nil
+}
+
+// Query implements the Handler interface.
+func (s *server) Query(ctx context.Context, r *ExecRequest) (*QueryResponse, error) {
+	ss, err := s.lookup(r.Conn)
+	if err != nil {
+		return nil, err
+	}
+	args, err := anys(r.Args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := ss.execQuerier().QueryContext(ctx, r.Query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	resp := &QueryResponse{Columns: make([]Column, len(types))}
+	for i, t := range types {
+		resp.Columns[i] = Column{Name: t.Name(), Type: t.DatabaseTypeName()}
+	}
+	for rows.Next() {
+		vs := make([]any, len(types))
+		ptrs := make([]any, len(types))
+		for i := range vs {
+			ptrs[i] = &vs[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row, err := values(vs)
+		if err != nil {
+			return nil, err
+		}
+		resp.Rows = append(resp.Rows, row)
+	}
+	return resp, rows.Err()
+}
+
+// Begin implements the Handler interface.
+func (s *server) Begin(_ context.Context, r *BeginRequest) (*Empty, error) {
+	ss, err := s.lookup(r.Conn)
+	if err != nil {
+		return nil, err
+	}
+	if ss.tx != nil {
+		return nil, fmt.Errorf("plugin: connection %d is already in a transaction", r.Conn)
+	}
+	// The transaction outlives the request, and therefore, it is not bound to its context.
+	ss.tx, err = ss.conn.BeginTx(context.Background(), &sql.TxOptions{
+		ReadOnly:  r.ReadOnly,
+		Isolation: sql.IsolationLevel(r.Isolation),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+// Commit implements the Handler interface.
+func (s *server) Commit(_ context.Context, r *ConnRequest) (*Empty, error) {
+	return s.endTx(r.Conn, (*sql.Tx).Commit)
+}
+
+// Rollback implements the Handler interface.
+func (s *server) Rollback(_ context.Context, r *ConnRequest) (*Empty, error) {
+	return s.endTx(r.Conn, (*sql.Tx).Rollback)
+}
+
+func (s *server) endTx(id int64, end func(*sql.Tx) error) (*Empty, error) {
+	ss, err := s.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	if ss.tx == nil {
+		return nil, fmt.Errorf("plugin: connection %d is not in a transaction", id)
+	}
+	tx := ss.tx
+	ss.tx = nil
+	return &Empty{}, end(tx)
+}
+
+func (s *server) lookup(id int64) (*session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ss, ok := s.session[id]
+	if !ok {
+		return nil, fmt.Errorf("plugin: unknown connection %d", id)
+	}
+	return ss, nil
+}
+
+// close closes the open sessions and the database connection.
+func (s *server) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		return nil
+	}
+	var errs []error
+	for id, ss := range s.session {
+		errs = append(errs, ss.close())
+		delete(s.session, id)
+	}
+	return errors.Join(append(errs, s.db.Close())...)
+}
+
+func (ss *session) execQuerier() interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+} {
+	if ss.tx != nil {
+		return ss.tx
+	}
+	return ss.conn
+}
+
+func (ss *session) close() error {
+	var err error
+	if ss.tx != nil {
+		err = ss.tx.Rollback()
+	}
+	return errors.Join(err, ss.conn.Close())
+}
+
+// serviceDesc describes the plugin service. It is written by hand,
+// as the messages of the service are encoded using JSON.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*Handler)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("Handshake", Handler.Handshake),
+		unary("Connect", Handler.Connect),
+		unary("Disconnect", Handler.Disconnect),
+		unary("Exec", Handler.Exec),
+		unary("Query", Handler.Query),
+		unary("Begin", Handler.Begin),
+		unary("Commit", Handler.Commit),
+		unary("Rollback", Handler.Rollback),
+	},
+}
+
+// unary returns the description of a unary method of the service.
+func unary[Req, Resp any](name string, f func(Handler, context.Context, *Req) (*Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			r := new(Req)
+			if err := dec(r); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return f(srv.(Handler), ctx, r)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: method(name)}
+			return interceptor(ctx, r, info, func(ctx context.Context, r any) (any, error) {
+				return f(srv.(Handler), ctx, r.(*Req))
+			})
+		},
+	}
+}
+
+// method returns the full name of the given method.
+func method(name string) string {
+	return "/" + ServiceName + "/" + name
+}
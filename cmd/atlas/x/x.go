@@ -13,6 +13,7 @@ import (
 	"errors"
 
 	"ariga.io/atlas/cmd/atlas/internal/migratelint"
+	"ariga.io/atlas/cmd/atlas/internal/sqlparse"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/sqlcheck"
 	"ariga.io/atlas/sql/sqlclient"
@@ -27,6 +28,15 @@ type (
 // ErrEmptyReport is returned when the report is empty.
 var ErrEmptyReport = errors.New("empty report")
 
+// StmtParserFor returns the statement parser used by the lint analysis for the
+// given driver, or nil if the driver has no registered parser.
+func StmtParserFor(driver string) migrate.StmtParser {
+	if p := sqlparse.ParserFor(driver); p != nil {
+		return p
+	}
+	return nil
+}
+
 // lintLatest runs the lint command on the latest changes (files) in the given directory.
 func lintLatest(ctx context.Context, dev *sqlclient.Client, dir migrate.Dir, latest int, az []sqlcheck.Analyzer) (report *SummaryReport, err error) {
 	r := migratelint.Runner{
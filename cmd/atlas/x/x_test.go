@@ -12,6 +12,7 @@ import (
 	"ariga.io/atlas/cmd/atlas/x"
 	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlcheck"
 	"ariga.io/atlas/sql/sqlclient"
 	_ "ariga.io/atlas/sql/sqlite"
@@ -36,3 +37,13 @@ func TestLintLatest(t *testing.T) {
 
 //go:linkname lintLatest ariga.io/atlas/cmd/atlas/x.lintLatest
 func lintLatest(context.Context, *sqlclient.Client, migrate.Dir, int, []sqlcheck.Analyzer) (report *x.SummaryReport, err error)
+
+func TestStmtParserFor(t *testing.T) {
+	require.Nil(t, x.StmtParserFor("unknown"))
+	p := x.StmtParserFor("sqlite3")
+	require.NotNil(t, p)
+	changes, err := p.ParseStmt(&migrate.Stmt{Text: "CREATE TABLE users (id int)"})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "users", changes[0].(*schema.AddTable).T.Name)
+}
@@ -15,15 +15,25 @@ import (
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/gocty"
 )
 
-// blockVar is an HCL resource that defines an input variable to the Atlas DDL document.
-type blockVar struct {
-	Name        string    `hcl:",label"`
-	Type        cty.Value `hcl:"type"`
-	Default     cty.Value `hcl:"default,optional"`
-	Description string    `hcl:"description,optional"`
-}
+type (
+	// blockVar is an HCL resource that defines an input variable to the Atlas DDL document.
+	blockVar struct {
+		Name        string           `hcl:",label"`
+		Type        cty.Value        `hcl:"type"`
+		Default     cty.Value        `hcl:"default,optional"`
+		Description string           `hcl:"description,optional"`
+		Validations []*varValidation `hcl:"validation,block"`
+	}
+	// varValidation is a custom rule that is checked against
+	// the final value of the variable it is defined in.
+	varValidation struct {
+		Condition    hcl.Expression `hcl:"condition"`
+		ErrorMessage hcl.Expression `hcl:"error_message"`
+	}
+)
 
 // setInputVals sets the input values into the evaluation context. HCL documents can define
 // input variables in the document body by defining "variable" blocks:
@@ -31,6 +41,10 @@ type blockVar struct {
 //	variable "name" {
 //	  type = string // also supported: number, bool
 //	  default = "rotemtam"
+//	  validation {
+//	    condition     = length(var.name) > 0
+//	    error_message = "name must not be empty"
+//	  }
 //	}
 func (s *State) setInputVals(ctx *hcl.EvalContext, body hcl.Body, input map[string]cty.Value) error {
 	var doc struct {
@@ -66,12 +80,74 @@ func (s *State) setInputVals(ctx *hcl.EvalContext, body hcl.Body, input map[stri
 		if err != nil {
 			return fmt.Errorf("variable %q: %w", v.Name, err)
 		}
+		if err := v.validate(ctx, cv); err != nil {
+			return err
+		}
 		ctxVars[v.Name] = cv
 	}
 	mergeCtxVar(ctx, ctxVars)
 	return nil
 }
 
+// validate checks the validation rules of the variable against its final value. The
+// conditions can reference only the variable itself, and must evaluate to a boolean.
+func (v *blockVar) validate(ctx *hcl.EvalContext, value cty.Value) error {
+	if len(v.Validations) == 0 {
+		return nil
+	}
+	nctx := ctx.NewChild()
+	nctx.Variables = map[string]cty.Value{
+		RefVar: cty.ObjectVal(map[string]cty.Value{v.Name: value}),
+	}
+	for _, r := range v.Validations {
+		cond, diags := r.Condition.Value(nctx)
+		if diags.HasErrors() {
+			return diags
+		}
+		if cond, err := convert.Convert(cond, cty.Bool); err != nil || cond.IsNull() || !cond.IsKnown() {
+			return fmt.Errorf("%s: invalid validation condition for variable %q: expect a boolean value", r.Condition.Range(), v.Name)
+		} else if cond.True() {
+			continue
+		}
+		msg, diags := r.ErrorMessage.Value(nctx)
+		if diags.HasErrors() {
+			return diags
+		}
+		if msg, err := convert.Convert(msg, cty.String); err == nil && !msg.IsNull() && msg.IsKnown() {
+			return fmt.Errorf("%s: invalid value for variable %q: %s", r.Condition.Range(), v.Name, msg.AsString())
+		}
+		return fmt.Errorf("%s: invalid value for variable %q", r.Condition.Range(), v.Name)
+	}
+	return nil
+}
+
+// InputValues converts the given Go values to their cty.Value representation,
+// so they can be passed as input variables to the Eval functions. For example:
+//
+//	input, err := schemahcl.InputValues(map[string]any{
+//		"prefix": "tenant_",
+//		"shards": []int{1, 2, 3},
+//	})
+func InputValues(values map[string]any) (map[string]cty.Value, error) {
+	input := make(map[string]cty.Value, len(values))
+	for k, v := range values {
+		if cv, ok := v.(cty.Value); ok {
+			input[k] = cv
+			continue
+		}
+		t, err := gocty.ImpliedType(v)
+		if err != nil {
+			return nil, fmt.Errorf("schemahcl: variable %q: %w", k, err)
+		}
+		cv, err := gocty.ToCtyValue(v, t)
+		if err != nil {
+			return nil, fmt.Errorf("schemahcl: variable %q: %w", k, err)
+		}
+		input[k] = cv
+	}
+	return input, nil
+}
+
 // evalReferences evaluates local and data blocks.
 func (s *State) evalReferences(ctx *hcl.EvalContext, body *hclsyntax.Body) error {
 	type node struct {
@@ -405,6 +481,8 @@ const (
 	AttrName      = "name"
 	forEachAttr   = "for_each"
 	eachRef       = "each"
+	countAttr     = "count"
+	countRef      = "count"
 )
 
 // Variables represents the dynamic variables used in a body.
@@ -614,6 +614,80 @@ variable "name" {
 	require.EqualError(t, err, `invalid type "boring" for variable "name". Valid types are: string, number, bool, list, map, or set`)
 }
 
+func TestVariable_Validation(t *testing.T) {
+	h := `
+variable "prefix" {
+  type = string
+  default = "t_"
+  validation {
+    condition     = endswith(var.prefix, "_")
+    error_message = "prefix must end with an underscore"
+  }
+}
+
+variable "shards" {
+  type = list(number)
+  validation {
+    condition     = try(var.shards[0], 0) > 0
+    error_message = "at least one positive shard is required"
+  }
+}
+
+prefix = var.prefix
+shards = length(var.shards)
+`
+	var test struct {
+		Prefix string `spec:"prefix"`
+		Shards int    `spec:"shards"`
+	}
+	input, err := InputValues(map[string]any{"shards": []int{1, 2}})
+	require.NoError(t, err)
+	require.NoError(t, New().EvalBytes([]byte(h), &test, input))
+	require.Equal(t, "t_", test.Prefix)
+	require.Equal(t, 2, test.Shards)
+
+	input, err = InputValues(map[string]any{"prefix": "t", "shards": []int{1}})
+	require.NoError(t, err)
+	err = New().EvalBytes([]byte(h), &test, input)
+	require.EqualError(t, err, `:6,21-46: invalid value for variable "prefix": prefix must end with an underscore`)
+
+	input, err = InputValues(map[string]any{"shards": []int{}})
+	require.NoError(t, err)
+	err = New().EvalBytes([]byte(h), &test, input)
+	require.EqualError(t, err, `:14,21-46: invalid value for variable "shards": at least one positive shard is required`)
+
+	err = New().EvalBytes([]byte(`
+variable "name" {
+  type = string
+  default = "a8m"
+  validation {
+    condition     = var.name
+    error_message = "invalid"
+  }
+}
+`), &test, nil)
+	require.EqualError(t, err, `:6,21-29: invalid validation condition for variable "name": expect a boolean value`)
+}
+
+func TestInputValues_Convert(t *testing.T) {
+	input, err := InputValues(map[string]any{
+		"name":   "a8m",
+		"int":    1,
+		"list":   []string{"a", "b"},
+		"map":    map[string]bool{"a": true},
+		"ctyval": cty.StringVal("b"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, cty.StringVal("a8m"), input["name"])
+	require.True(t, input["int"].RawEquals(cty.NumberIntVal(1)))
+	require.Equal(t, cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}), input["list"])
+	require.Equal(t, cty.MapVal(map[string]cty.Value{"a": cty.True}), input["map"])
+	require.Equal(t, cty.StringVal("b"), input["ctyval"])
+
+	_, err = InputValues(map[string]any{"ch": make(chan int)})
+	require.Error(t, err)
+}
+
 func TestTemplateReferences(t *testing.T) {
 	var (
 		d struct {
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
 )
@@ -36,6 +37,11 @@ func (d *DefaultExtension) Remain() *Resource {
 	return &d.Extra
 }
 
+// Range returns the position of the resource in the HCL document, if exists.
+func (d *DefaultExtension) Range() *hcl.Range {
+	return d.Extra.Range()
+}
+
 // Attr returns the Attr by the provided name and reports whether it was found.
 func (d *DefaultExtension) Attr(name string) (*Attr, bool) {
 	return d.Extra.Attr(name)
@@ -210,6 +216,7 @@ func (r *Resource) as(target any) error {
 		return nil
 	}
 	extras := rem.Remain()
+	extras.rang = r.rang
 	for attrName := range existingAttrs {
 		attr, ok := r.Attr(attrName)
 		if !ok {
@@ -0,0 +1,180 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schemahcl
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// JSONSchema returns a JSON Schema document that describes the JSON representation
+// of the documents evaluated into v, which must be a pointer to a struct. The blocks
+// and attributes are derived from the "spec" tags of the struct fields, and the types
+// and enums configured for the state (see WithTypes and WithScopedEnums) are used to
+// restrict the values of the attributes they are scoped to. For example:
+//
+//	{
+//	  "table": {
+//	    "users": {
+//	      "schema": "${schema.public}",
+//	      "column": {
+//	        "id": {"type": "${int}"}
+//	      }
+//	    }
+//	  }
+//	}
+//
+// Blocks that embed DefaultExtension accept additional attributes and blocks, as they
+// may be registered by the drivers.
+func (s *State) JSONSchema(v any) ([]byte, error) {
+	if err := validateStructPtr(v); err != nil {
+		return nil, err
+	}
+	root := s.blockSchema(reflect.TypeOf(v), nil)
+	props := root["properties"].(map[string]any)
+	props[BlockVariable] = labeled(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"type":        map[string]any{"type": "string"},
+			"default":     map[string]any{},
+			"description": map[string]any{"type": "string"},
+		},
+	})
+	props[BlockLocals] = map[string]any{"type": "object"}
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// blockSchema returns the schema of the block defined by the given struct type.
+func (s *State) blockSchema(t reflect.Type, path []string) map[string]any {
+	t = indirect(t)
+	props := make(map[string]any)
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	for _, f := range specFields(reflect.New(t).Interface()) {
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		switch {
+		// Labels are not encoded as attributes.
+		case f.tag == "" || f.isName():
+		case isSingleResource(ft) && !isLabeled(ft):
+			props[f.tag] = s.blockSchema(ft, append(path, f.tag))
+		case isSingleResource(ft):
+			props[f.tag] = labeled(s.blockSchema(ft, append(path, f.tag)))
+		default:
+			props[f.tag] = s.attrSchema(f.Type, strings.Join(append(path, f.tag), "."))
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type == reflect.TypeOf(DefaultExtension{}) {
+			schema["additionalProperties"] = true
+		}
+	}
+	return schema
+}
+
+// refPattern matches the JSON representation of references and expressions.
+const refPattern = `^\$\{.+\}$`
+
+// attrSchema returns the schema of an attribute with the given type and path.
+func (s *State) attrSchema(t reflect.Type, path string) map[string]any {
+	vars, funcs := s.config.pathVars[path], s.config.pathFuncs[path]
+	if len(vars) > 0 || len(funcs) > 0 {
+		var (
+			patterns []string
+			enums    []string
+		)
+		// Identifiers are used as is, and functions are called with arguments.
+		if len(vars) > 0 {
+			patterns = append(patterns, `^\$\{(`+strings.Join(quoteNames(vars), "|")+`)\}$`)
+		}
+		if len(funcs) > 0 {
+			patterns = append(patterns, `^\$\{(`+strings.Join(quoteNames(funcs), "|")+`)\(.*\)\}$`)
+		}
+		for _, v := range vars {
+			if v.Type() == cty.String {
+				enums = append(enums, v.AsString())
+			}
+		}
+		schema := map[string]any{
+			"type":    "string",
+			"pattern": strings.Join(patterns, "|"),
+		}
+		if len(enums) == 0 {
+			return schema
+		}
+		sort.Strings(enums)
+		return map[string]any{
+			"anyOf": []any{schema, map[string]any{"enum": enums}},
+		}
+	}
+	switch t {
+	case reflect.TypeOf(&Ref{}):
+		return map[string]any{"type": "string", "pattern": refPattern}
+	case reflect.TypeOf([]*Ref{}):
+		return map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string", "pattern": refPattern},
+		}
+	case reflect.TypeOf(cty.Value{}), reflect.TypeOf(&Type{}):
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": s.attrSchema(t.Elem(), "")}
+	case reflect.Ptr:
+		return s.attrSchema(t.Elem(), path)
+	default:
+		return map[string]any{}
+	}
+}
+
+// labeled returns the schema of labeled blocks, in which
+// the block bodies are keyed by their label.
+func labeled(body map[string]any) map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"additionalProperties": body,
+	}
+}
+
+// isLabeled reports if the struct type is decoded from a labeled block.
+func isLabeled(t reflect.Type) bool {
+	for _, f := range specFields(reflect.New(t).Interface()) {
+		if f.isName() {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteNames returns the sorted and quoted keys of the map.
+func quoteNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, regexp.QuoteMeta(n))
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,99 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schemahcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_JSONSchema(t *testing.T) {
+	type (
+		Column struct {
+			Name string `spec:",name"`
+			Null bool   `spec:"null"`
+			Type *Type  `spec:"type"`
+		}
+		PrimaryKey struct {
+			Columns []*Ref `spec:"columns"`
+		}
+		Table struct {
+			Name    string      `spec:",name"`
+			Engine  string      `spec:"engine"`
+			Columns []*Column   `spec:"column"`
+			PK      *PrimaryKey `spec:"primary_key"`
+			DefaultExtension
+		}
+		Doc struct {
+			Tables []*Table `spec:"table"`
+		}
+	)
+	s := New(
+		WithTypes("table.column.type", []*TypeSpec{
+			{Name: "int", T: "int"},
+			{Name: "varchar", T: "varchar", Attributes: []*TypeAttr{{Name: "size", Kind: reflect.Int, Required: true}}},
+		}),
+		WithScopedEnums("table.engine", "InnoDB", "MyISAM"),
+	)
+	got, err := s.JSONSchema(&Doc{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "locals": {"type": "object"},
+    "variable": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "object",
+        "properties": {
+          "default": {},
+          "description": {"type": "string"},
+          "type": {"type": "string"}
+        }
+      }
+    },
+    "table": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "object",
+        "additionalProperties": true,
+        "properties": {
+          "engine": {
+            "anyOf": [
+              {"type": "string", "pattern": "^\\$\\{(InnoDB|MyISAM)\\}$"},
+              {"enum": ["InnoDB", "MyISAM"]}
+            ]
+          },
+          "column": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "object",
+              "additionalProperties": false,
+              "properties": {
+                "null": {"type": "boolean"},
+                "type": {"type": "string", "pattern": "^\\$\\{(int)\\}$|^\\$\\{(varchar)\\(.*\\)\\}$"}
+              }
+            }
+          },
+          "primary_key": {
+            "type": "object",
+            "additionalProperties": false,
+            "properties": {
+              "columns": {"type": "array", "items": {"type": "string", "pattern": "^\\$\\{.+\\}$"}}
+            }
+          }
+        }
+      }
+    }
+  }
+}`, string(got))
+
+	_, err = s.JSONSchema(Doc{})
+	require.EqualError(t, err, "schemahcl: expected target to be a pointer")
+}
@@ -0,0 +1,405 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package lsp
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"ariga.io/atlas/schemahcl"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// blockSpec describes the attributes and child blocks allowed in a block.
+type blockSpec struct {
+	attrs  []string
+	blocks map[string]*blockSpec
+}
+
+// specOf returns the blockSpec of the given struct, based on its "spec" tags.
+func specOf(v any) *blockSpec {
+	return specOfType(reflect.TypeOf(v), make(map[reflect.Type]*blockSpec))
+}
+
+func specOfType(t reflect.Type, seen map[reflect.Type]*blockSpec) *blockSpec {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if s, ok := seen[t]; ok {
+		return s
+	}
+	s := &blockSpec{blocks: make(map[string]*blockSpec)}
+	seen[t] = s
+	if t.Kind() != reflect.Struct {
+		return s
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("spec")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		switch ft := elemType(f.Type); {
+		// Labels are not attributes.
+		case name == "":
+		case ft.Kind() == reflect.Struct && isBlock(ft):
+			s.blocks[name] = specOfType(ft, seen)
+		default:
+			s.attrs = append(s.attrs, name)
+		}
+	}
+	sort.Strings(s.attrs)
+	return s
+}
+
+// elemType returns the element type of pointers and slices.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isBlock reports if the struct type is decoded from a block, and not from an
+// attribute value, like schemahcl.Ref or schemahcl.Type.
+func isBlock(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("spec"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// complete returns the completion items for the given offset in the document.
+func (s *Server) complete(d *document, off int) []completionItem {
+	items := []completionItem{}
+	path, attr, ok := scopeAt(d.text[:off])
+	if !ok {
+		return items
+	}
+	// Completion of an attribute value.
+	if attr != "" {
+		for _, t := range s.types[strings.Join(append(path, attr), ".")] {
+			items = append(items, completionItem{Label: t.Name, Kind: kindType, Detail: typeDetail(t)})
+		}
+		return items
+	}
+	spec := s.spec
+	for _, p := range path {
+		if spec = spec.blocks[p]; spec == nil {
+			return items
+		}
+	}
+	for _, a := range spec.attrs {
+		items = append(items, completionItem{Label: a, Kind: kindProperty, Detail: "attribute"})
+	}
+	blocks := make([]string, 0, len(spec.blocks))
+	for b := range spec.blocks {
+		blocks = append(blocks, b)
+	}
+	sort.Strings(blocks)
+	for _, b := range blocks {
+		items = append(items, completionItem{Label: b, Kind: kindStruct, Detail: "block"})
+	}
+	return items
+}
+
+// typeDetail returns the signature of the type, e.g. varchar(size).
+func typeDetail(t *schemahcl.TypeSpec) string {
+	var args []string
+	for _, a := range t.Attributes {
+		if a.Kind == reflect.Slice {
+			args = append(args, a.Name+"...")
+		} else {
+			args = append(args, a.Name)
+		}
+	}
+	if len(args) == 0 {
+		return t.Name
+	}
+	return t.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// scopeAt returns the path of the block types that enclose the end of src, and the
+// name of the attribute if the end of src is in its value. Tokens are used instead
+// of the syntax tree, as documents are often invalid while being edited. False is
+// returned if the end of src is in an expression that cannot be completed.
+func scopeAt(src []byte) (path []string, attr string, ok bool) {
+	var (
+		stmt   hclsyntax.Tokens
+		blocks []string
+		// Nested braces that do not open a block, like objects,
+		// are pushed to the stack as empty strings.
+		stack []string
+	)
+	tokens, _ := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+	for _, t := range tokens {
+		switch t.Type {
+		case hclsyntax.TokenNewline:
+			stmt = stmt[:0]
+		case hclsyntax.TokenOBrace:
+			typ := ""
+			if isBlockHeader(stmt) {
+				typ = string(stmt[0].Bytes)
+			}
+			stack = append(stack, typ)
+			stmt = stmt[:0]
+		case hclsyntax.TokenCBrace:
+			if n := len(stack); n > 0 {
+				stack = stack[:n-1]
+			}
+			stmt = stmt[:0]
+		case hclsyntax.TokenEOF:
+		default:
+			stmt = append(stmt, t)
+		}
+	}
+	for _, typ := range stack {
+		if typ == "" {
+			return nil, "", false
+		}
+		blocks = append(blocks, typ)
+	}
+	switch {
+	// Attribute name, or the start of a block.
+	case len(stmt) == 0 || len(stmt) == 1 && stmt[0].Type == hclsyntax.TokenIdent && stmt[0].Range.End.Byte == len(src):
+		return blocks, "", true
+	// Attribute value.
+	case len(stmt) >= 2 && stmt[0].Type == hclsyntax.TokenIdent && stmt[1].Type == hclsyntax.TokenEqual:
+		return blocks, string(stmt[0].Bytes), true
+	default:
+		return nil, "", false
+	}
+}
+
+// isBlockHeader reports if the tokens are a block header, e.g. `table "users"`.
+func isBlockHeader(stmt hclsyntax.Tokens) bool {
+	if len(stmt) == 0 || stmt[0].Type != hclsyntax.TokenIdent {
+		return false
+	}
+	for _, t := range stmt[1:] {
+		switch t.Type {
+		case hclsyntax.TokenIdent, hclsyntax.TokenOQuote, hclsyntax.TokenQuotedLit, hclsyntax.TokenCQuote:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// definition returns the location of the block or attribute referenced at the given
+// offset. References are resolved from the innermost enclosing block to the top level
+// of the open documents, the same way they are resolved by schemahcl.
+func (s *Server) definition(d *document, off int) *location {
+	body, ok := d.body()
+	if !ok {
+		return nil
+	}
+	var (
+		names []string
+		scope []*hclsyntax.Block
+	)
+	hclsyntax.VisitAll(body, func(n hclsyntax.Node) hcl.Diagnostics {
+		switch n := n.(type) {
+		case *hclsyntax.Block:
+			if n.Body.SrcRange.ContainsOffset(off) {
+				scope = append(scope, n)
+			}
+		case *hclsyntax.ScopeTraversalExpr:
+			if n.SrcRange.ContainsOffset(off) || n.SrcRange.End.Byte == off {
+				names = traversalNames(n.Traversal)
+			}
+		}
+		return nil
+	})
+	if len(names) < 2 {
+		return nil
+	}
+	switch names[0] {
+	case schemahcl.RefVar:
+		return s.lookup(func(b *hclsyntax.Body) *hcl.Range {
+			for _, v := range b.Blocks {
+				if v.Type == schemahcl.BlockVariable && len(v.Labels) == 1 && v.Labels[0] == names[1] {
+					return v.DefRange().Ptr()
+				}
+			}
+			return nil
+		})
+	case schemahcl.RefLocal:
+		return s.lookup(func(b *hclsyntax.Body) *hcl.Range {
+			for _, l := range b.Blocks {
+				if a, ok := l.Body.Attributes[names[1]]; ok && l.Type == schemahcl.BlockLocals {
+					return a.NameRange.Ptr()
+				}
+			}
+			return nil
+		})
+	}
+	for i := len(scope) - 1; i >= 0; i-- {
+		if b := resolve(scope[i].Body.Blocks, names); b != nil {
+			return d.location(b.DefRange())
+		}
+	}
+	return s.lookup(func(body *hclsyntax.Body) *hcl.Range {
+		if b := resolve(body.Blocks, names); b != nil {
+			return b.DefRange().Ptr()
+		}
+		return nil
+	})
+}
+
+// lookup returns the location of the first range returned by the
+// function for the top-level body of the open documents.
+func (s *Server) lookup(f func(*hclsyntax.Body) *hcl.Range) *location {
+	uris := make([]string, 0, len(s.docs))
+	for uri := range s.docs {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	for _, uri := range uris {
+		d := s.docs[uri]
+		if body, ok := d.body(); ok {
+			if r := f(body); r != nil {
+				return d.location(*r)
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns the block referenced by the given names, e.g. table.users.column.id.
+func resolve(blocks hclsyntax.Blocks, names []string) *hclsyntax.Block {
+	for _, b := range blocks {
+		n := len(b.Labels)
+		if b.Type != names[0] || n == 0 || len(names) <= n || !equal(b.Labels, names[1:n+1]) {
+			continue
+		}
+		rest := names[n+1:]
+		if len(rest) == 0 {
+			return b
+		}
+		if c := resolve(b.Body.Blocks, rest); c != nil {
+			return c
+		}
+		// The rest of the names reference an attribute of the block.
+		return b
+	}
+	return nil
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// traversalNames returns the names of the attributes and indexes in the traversal.
+func traversalNames(t hcl.Traversal) []string {
+	names := make([]string, 0, len(t))
+	for _, s := range t {
+		switch s := s.(type) {
+		case hcl.TraverseRoot:
+			names = append(names, s.Name)
+		case hcl.TraverseAttr:
+			names = append(names, s.Name)
+		case hcl.TraverseIndex:
+			if s.Key.Type() != cty.String || s.Key.IsNull() {
+				return names
+			}
+			names = append(names, s.Key.AsString())
+		default:
+			return names
+		}
+	}
+	return names
+}
+
+// body returns the body of the last parsed version of the document.
+func (d *document) body() (*hclsyntax.Body, bool) {
+	if d.file == nil {
+		return nil, false
+	}
+	b, ok := d.file.Body.(*hclsyntax.Body)
+	return b, ok
+}
+
+// location returns the location of the range in the document.
+func (d *document) location(r hcl.Range) *location {
+	return &location{URI: d.uri, Range: d.lspRange(r)}
+}
+
+// toDiagnostics converts HCL diagnostics to protocol diagnostics.
+func (d *document) toDiagnostics(diags hcl.Diagnostics) []diagnostic {
+	var out []diagnostic
+	for _, hd := range diags {
+		pd := diagnostic{Severity: severityError, Source: "atlas", Message: hd.Summary}
+		if hd.Severity == hcl.DiagWarning {
+			pd.Severity = severityWarning
+		}
+		if hd.Detail != "" {
+			pd.Message += ": " + hd.Detail
+		}
+		if hd.Subject != nil {
+			pd.Range = d.lspRange(*hd.Subject)
+		}
+		out = append(out, pd)
+	}
+	return out
+}
+
+// lspRange converts an HCL range to a protocol range.
+func (d *document) lspRange(r hcl.Range) lspRange {
+	return lspRange{Start: d.position(r.Start.Byte), End: d.position(r.End.Byte)}
+}
+
+// position converts a byte offset to a protocol position,
+// in which characters are counted in UTF-16 code units.
+func (d *document) position(off int) position {
+	if off > len(d.text) {
+		off = len(d.text)
+	}
+	var p position
+	start := 0
+	for i := 0; i < off; i++ {
+		if d.text[i] == '\n' {
+			p.Line++
+			start = i + 1
+		}
+	}
+	for _, r := range string(d.text[start:off]) {
+		p.Character += len(utf16.Encode([]rune{r}))
+	}
+	return p
+}
+
+// offset converts a protocol position to a byte offset.
+func (d *document) offset(p position) int {
+	off := 0
+	for line := 0; line < p.Line && off < len(d.text); off++ {
+		if d.text[off] == '\n' {
+			line++
+		}
+	}
+	for c := 0; c < p.Character && off < len(d.text) && d.text[off] != '\n'; {
+		r, n := utf8.DecodeRune(d.text[off:])
+		c += len(utf16.Encode([]rune{r}))
+		off += n
+	}
+	return off
+}
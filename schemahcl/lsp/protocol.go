@@ -0,0 +1,159 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// The subset of the Language Server Protocol messages
+// and structures that is used by the server.
+type (
+	// message is a JSON-RPC 2.0 request, or a notification if it has no ID.
+	message struct {
+		JSONRPC string           `json:"jsonrpc"`
+		ID      *json.RawMessage `json:"id,omitempty"`
+		Method  string           `json:"method"`
+		Params  json.RawMessage  `json:"params,omitempty"`
+	}
+
+	// response is a JSON-RPC 2.0 response.
+	response struct {
+		JSONRPC string           `json:"jsonrpc"`
+		ID      *json.RawMessage `json:"id"`
+		Result  json.RawMessage  `json:"result,omitempty"`
+		Error   *respError       `json:"error,omitempty"`
+	}
+
+	// respError is a JSON-RPC 2.0 error object.
+	respError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	// notification is a JSON-RPC 2.0 message sent by the server.
+	notification struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params"`
+	}
+
+	position struct {
+		Line      int `json:"line"`
+		Character int `json:"character"`
+	}
+
+	lspRange struct {
+		Start position `json:"start"`
+		End   position `json:"end"`
+	}
+
+	location struct {
+		URI   string   `json:"uri"`
+		Range lspRange `json:"range"`
+	}
+
+	diagnostic struct {
+		Range    lspRange `json:"range"`
+		Severity int      `json:"severity"`
+		Source   string   `json:"source"`
+		Message  string   `json:"message"`
+	}
+
+	publishDiagnosticsParams struct {
+		URI         string       `json:"uri"`
+		Diagnostics []diagnostic `json:"diagnostics"`
+	}
+
+	textDocumentItem struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	}
+
+	textDocumentIdentifier struct {
+		URI string `json:"uri"`
+	}
+
+	didOpenParams struct {
+		TextDocument textDocumentItem `json:"textDocument"`
+	}
+
+	didChangeParams struct {
+		TextDocument   textDocumentIdentifier `json:"textDocument"`
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+	}
+
+	didCloseParams struct {
+		TextDocument textDocumentIdentifier `json:"textDocument"`
+	}
+
+	textDocumentPositionParams struct {
+		TextDocument textDocumentIdentifier `json:"textDocument"`
+		Position     position               `json:"position"`
+	}
+
+	completionItem struct {
+		Label  string `json:"label"`
+		Kind   int    `json:"kind"`
+		Detail string `json:"detail,omitempty"`
+	}
+)
+
+// Protocol constants.
+const (
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+
+	severityError   = 1
+	severityWarning = 2
+
+	syncFull = 1
+
+	kindProperty = 10
+	kindStruct   = 22
+	kindType     = 25
+)
+
+// readMessage reads a single base-protocol message from the reader.
+func readMessage(r *bufio.Reader) (*message, error) {
+	h, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(h.Get("Content-Length")))
+	if err != nil {
+		return nil, fmt.Errorf("lsp: invalid Content-Length header: %w", err)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	m := &message{}
+	if err := json.Unmarshal(buf, m); err != nil {
+		return nil, fmt.Errorf("lsp: invalid message: %w", err)
+	}
+	return m, nil
+}
+
+// writeMessage writes a single base-protocol message to the writer.
+func writeMessage(w io.Writer, v any) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(buf)); err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
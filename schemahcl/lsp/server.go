@@ -0,0 +1,281 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package lsp implements a Language Server Protocol server for Atlas HCL documents.
+// The server communicates over JSON-RPC and offers completion of block, attribute
+// and type names, go-to-definition for references and inline diagnostics.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"ariga.io/atlas/schemahcl"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+type (
+	// Server is a language server for Atlas HCL documents.
+	Server struct {
+		spec     *blockSpec
+		types    map[string][]*schemahcl.TypeSpec
+		validate func(*hclparse.Parser) hcl.Diagnostics
+		docs     map[string]*document
+		w        io.Writer
+	}
+
+	// Option configures a Server.
+	Option func(*Server)
+
+	// document is an open text document.
+	document struct {
+		uri   string
+		text  []byte
+		file  *hcl.File
+		diags hcl.Diagnostics
+	}
+)
+
+// NewServer returns a new Server configured with the given options.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		spec:  &blockSpec{},
+		types: make(map[string][]*schemahcl.TypeSpec),
+		docs:  make(map[string]*document),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithSpec configures the structure of the documents served by the server from
+// the "spec" tags of the given struct. Its block and attribute names are offered
+// as completions. For example:
+//
+//	WithSpec(&struct {
+//		Schemas []*sqlspec.Schema `spec:"schema"`
+//		Tables  []*sqlspec.Table  `spec:"table"`
+//	}{})
+func WithSpec(v any) Option {
+	return func(s *Server) {
+		s.spec = specOf(v)
+	}
+}
+
+// WithTypes configures the given types to be offered as completions for the
+// attribute in the given path, similar to the schemahcl.WithTypes option.
+//
+//	WithTypes("table.column.type", mysql.TypeRegistry.Specs())
+func WithTypes(path string, specs []*schemahcl.TypeSpec) Option {
+	return func(s *Server) {
+		s.types[path] = append(s.types[path], specs...)
+	}
+}
+
+// WithValidate configures the function used for reporting the semantic errors
+// of the open documents, such as duplicate definitions or invalid types. For
+// example:
+//
+//	WithValidate(func(p *hclparse.Parser) hcl.Diagnostics {
+//		return mysql.ValidateHCL(p, nil)
+//	})
+func WithValidate(f func(*hclparse.Parser) hcl.Diagnostics) Option {
+	return func(s *Server) {
+		s.validate = f
+	}
+}
+
+// errExit is returned by the handlers when the client asks the server to exit.
+var errExit = errors.New("lsp: exit")
+
+// Serve reads requests from r and writes responses and notifications to w, until
+// the client sends the "exit" notification, r is closed, or ctx is canceled.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.w = w
+	br := bufio.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m, err := readMessage(br)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result, err := s.handle(m)
+		if errors.Is(err, errExit) {
+			return nil
+		}
+		// Errors of notifications are not reported back to the client.
+		if m.ID == nil {
+			continue
+		}
+		resp := &response{JSONRPC: "2.0", ID: m.ID}
+		var rerr *respError
+		switch {
+		case errors.As(err, &rerr):
+			resp.Error = rerr
+		case err != nil:
+			resp.Error = &respError{Code: codeInvalidParams, Message: err.Error()}
+		default:
+			if resp.Result, err = json.Marshal(result); err != nil {
+				return err
+			}
+		}
+		if err := writeMessage(w, resp); err != nil {
+			return err
+		}
+	}
+}
+
+// Error implements the error interface.
+func (e *respError) Error() string { return e.Message }
+
+// handle dispatches the message to its handler.
+func (s *Server) handle(m *message) (any, error) {
+	switch m.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   syncFull,
+				"definitionProvider": true,
+				"completionProvider": map[string]any{
+					"triggerCharacters": []string{"."},
+				},
+			},
+			"serverInfo": map[string]any{"name": "atlas"},
+		}, nil
+	case "initialized", "textDocument/didSave", "$/cancelRequest":
+		return nil, nil
+	case "shutdown":
+		return nil, nil
+	case "exit":
+		return nil, errExit
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		return nil, s.update(p.TextDocument.URI, []byte(p.TextDocument.Text))
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		// Documents are synced in full, and the last change holds the whole content.
+		if n := len(p.ContentChanges); n > 0 {
+			return nil, s.update(p.TextDocument.URI, []byte(p.ContentChanges[n-1].Text))
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var p didCloseParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		delete(s.docs, p.TextDocument.URI)
+		if err := s.publish(p.TextDocument.URI, nil); err != nil {
+			return nil, err
+		}
+		return nil, s.diagnose("")
+	case "textDocument/completion":
+		var p textDocumentPositionParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		d, ok := s.docs[p.TextDocument.URI]
+		if !ok {
+			return []completionItem{}, nil
+		}
+		return s.complete(d, d.offset(p.Position)), nil
+	case "textDocument/definition":
+		var p textDocumentPositionParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, err
+		}
+		d, ok := s.docs[p.TextDocument.URI]
+		if !ok {
+			return nil, nil
+		}
+		// A nil *location is encoded as null.
+		return s.definition(d, d.offset(p.Position)), nil
+	default:
+		return nil, &respError{Code: codeMethodNotFound, Message: fmt.Sprintf("lsp: method %q not found", m.Method)}
+	}
+}
+
+// update parses the document content and publishes the diagnostics of all open documents.
+func (s *Server) update(uri string, text []byte) error {
+	d := &document{uri: uri, text: text}
+	d.file, d.diags = hclsyntax.ParseConfig(text, uri, hcl.InitialPos)
+	if prev, ok := s.docs[uri]; ok && d.file == nil {
+		d.file = prev.file
+	}
+	s.docs[uri] = d
+	return s.diagnose(uri)
+}
+
+// diagnose publishes the syntax errors of the open documents, or their semantic
+// errors if they are syntactically valid. Open documents are validated together,
+// as a schema can be split into multiple files. Diagnostics without a position
+// are reported on the document that was changed.
+func (s *Server) diagnose(changed string) error {
+	var (
+		uris  = make([]string, 0, len(s.docs))
+		diags = make(map[string][]diagnostic, len(s.docs))
+		valid = s.validate != nil
+	)
+	for uri, d := range s.docs {
+		uris = append(uris, uri)
+		valid = valid && !d.diags.HasErrors()
+	}
+	sort.Strings(uris)
+	for _, uri := range uris {
+		diags[uri] = s.docs[uri].toDiagnostics(s.docs[uri].diags)
+	}
+	if valid {
+		// Documents are parsed again, as the evaluation may modify their body.
+		p := hclparse.NewParser()
+		for _, uri := range uris {
+			p.ParseHCL(s.docs[uri].text, uri)
+		}
+		for _, d := range s.validate(p) {
+			uri := changed
+			if d.Subject != nil {
+				uri = d.Subject.Filename
+			}
+			if doc, ok := s.docs[uri]; ok {
+				diags[uri] = append(diags[uri], doc.toDiagnostics(hcl.Diagnostics{d})...)
+			}
+		}
+	}
+	for _, uri := range uris {
+		if err := s.publish(uri, diags[uri]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publish sends the diagnostics of the document to the client.
+func (s *Server) publish(uri string, diags []diagnostic) error {
+	if diags == nil {
+		diags = []diagnostic{}
+	}
+	return writeMessage(s.w, &notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  &publishDiagnosticsParams{URI: uri, Diagnostics: diags},
+	})
+}
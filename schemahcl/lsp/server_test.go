@@ -0,0 +1,205 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/textproto"
+	"strconv"
+	"testing"
+
+	"ariga.io/atlas/schemahcl/lsp"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/sqlspec"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/stretchr/testify/require"
+)
+
+const doc = `schema "public" {}
+
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  column "id" {
+    type = int
+  }
+}
+
+table "posts" {
+  schema = schema.public
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+`
+
+func TestServer(t *testing.T) {
+	var (
+		in, out bytes.Buffer
+		uri     = "file:///schema.hcl"
+		send    = func(id int, method string, params any) {
+			m := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+			if id > 0 {
+				m["id"] = id
+			}
+			buf, err := json.Marshal(m)
+			require.NoError(t, err)
+			in.WriteString("Content-Length: " + strconv.Itoa(len(buf)) + "\r\n\r\n")
+			in.Write(buf)
+		}
+		pos = func(line, char int) map[string]any {
+			return map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"position":     map[string]any{"line": line, "character": char},
+			}
+		}
+	)
+	send(1, "initialize", map[string]any{})
+	send(0, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "hcl", "version": 1, "text": doc},
+	})
+	// Go to the definition of table.users.column.id.
+	send(2, "textDocument/definition", pos(19, 34))
+	// Go to the definition of column.author_id.
+	send(3, "textDocument/definition", pos(18, 24))
+	// Complete the column type.
+	send(0, "textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []any{map[string]any{"text": "table \"t\" {\n  column \"c\" {\n    type = \n  }\n  \n}\n"}},
+	})
+	send(4, "textDocument/completion", pos(2, 11))
+	// Complete the attributes and blocks of a table.
+	send(5, "textDocument/completion", pos(4, 2))
+	send(6, "unknown/method", map[string]any{})
+	send(7, "shutdown", nil)
+	send(0, "exit", nil)
+
+	s := lsp.NewServer(
+		lsp.WithSpec(&struct {
+			Schemas []*sqlspec.Schema `spec:"schema"`
+			Tables  []*sqlspec.Table  `spec:"table"`
+		}{}),
+		lsp.WithTypes("table.column.type", mysql.TypeRegistry.Specs()),
+		lsp.WithValidate(func(p *hclparse.Parser) hcl.Diagnostics {
+			return mysql.ValidateHCL(p, nil)
+		}),
+	)
+	require.NoError(t, s.Serve(context.Background(), &in, &out))
+
+	msgs := readAll(t, &out)
+	require.Len(t, msgs, 9)
+	var init struct {
+		ID     int
+		Result struct {
+			Capabilities struct {
+				DefinitionProvider bool
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(msgs[0], &init))
+	require.Equal(t, 1, init.ID)
+	require.True(t, init.Result.Capabilities.DefinitionProvider)
+
+	// Diagnostics of the opened document.
+	var diags struct {
+		Method string
+		Params struct {
+			URI         string
+			Diagnostics []struct {
+				Message string
+				Range   struct{ Start struct{ Line, Character int } }
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(msgs[1], &diags))
+	require.Equal(t, "textDocument/publishDiagnostics", diags.Method)
+	require.Equal(t, uri, diags.Params.URI)
+	require.Len(t, diags.Params.Diagnostics, 1)
+	require.Equal(t, `specutil: duplicate column "id" in "users"`, diags.Params.Diagnostics[0].Message)
+	require.Equal(t, 7, diags.Params.Diagnostics[0].Range.Start.Line)
+	require.Equal(t, 2, diags.Params.Diagnostics[0].Range.Start.Character)
+
+	var loc struct {
+		Result struct {
+			URI   string
+			Range struct{ Start struct{ Line, Character int } }
+		}
+	}
+	require.NoError(t, json.Unmarshal(msgs[2], &loc))
+	require.Equal(t, uri, loc.Result.URI)
+	require.Equal(t, 4, loc.Result.Range.Start.Line)
+	require.NoError(t, json.Unmarshal(msgs[3], &loc))
+	require.Equal(t, 14, loc.Result.Range.Start.Line)
+
+	// Diagnostics of the changed document.
+	require.NoError(t, json.Unmarshal(msgs[4], &diags))
+	require.NotEmpty(t, diags.Params.Diagnostics)
+
+	var items struct {
+		Result []struct {
+			Label  string
+			Detail string
+		}
+	}
+	require.NoError(t, json.Unmarshal(msgs[5], &items))
+	labels := make(map[string]string)
+	for _, i := range items.Result {
+		labels[i.Label] = i.Detail
+	}
+	require.Equal(t, "int(unsigned, size)", labels["int"])
+	require.Equal(t, "varchar(size)", labels["varchar"])
+	require.NoError(t, json.Unmarshal(msgs[6], &items))
+	labels = make(map[string]string)
+	for _, i := range items.Result {
+		labels[i.Label] = i.Detail
+	}
+	require.Equal(t, map[string]string{
+		"schema":      "attribute",
+		"column":      "block",
+		"primary_key": "block",
+		"foreign_key": "block",
+		"index":       "block",
+		"check":       "block",
+	}, labels)
+
+	var resp struct {
+		Error struct{ Code int }
+	}
+	require.NoError(t, json.Unmarshal(msgs[7], &resp))
+	require.Equal(t, -32601, resp.Error.Code)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":7,"result":null}`, string(msgs[8]))
+}
+
+func readAll(t *testing.T, r io.Reader) []json.RawMessage {
+	var (
+		msgs []json.RawMessage
+		br   = bufio.NewReader(r)
+	)
+	for {
+		h, err := textproto.NewReader(br).ReadMIMEHeader()
+		if err == io.EOF {
+			return msgs
+		}
+		require.NoError(t, err)
+		n, err := strconv.Atoi(h.Get("Content-Length"))
+		require.NoError(t, err)
+		buf := make([]byte, n)
+		_, err = io.ReadFull(br, buf)
+		require.NoError(t, err)
+		msgs = append(msgs, buf)
+	}
+}
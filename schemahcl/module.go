@@ -0,0 +1,258 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schemahcl
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Module blocks and attributes.
+const (
+	BlockModule  = "module"
+	BlockOutput  = "output"
+	RefModule    = "module"
+	moduleSource = "source"
+	outputValue  = "value"
+	// maxModuleDepth limits the nesting of modules,
+	// and protects against cyclic module imports.
+	maxModuleDepth = 16
+)
+
+// WithModuleSource registers a handler for loading modules from sources with the
+// given URL scheme. Local paths are always supported and resolved relative to the
+// file defining the module block. For example:
+//
+//	WithModuleSource("mem", func(u *url.URL) (fs.FS, error) {
+//		return fstest.MapFS{
+//			"audit.hcl": &fstest.MapFile{Data: []byte(`...`)},
+//		}, nil
+//	})
+//
+//	module "audit" {
+//	  source = "mem://audit"
+//	  prefix = "app_"
+//	}
+func WithModuleSource(scheme string, h func(*url.URL) (fs.FS, error)) Option {
+	return func(c *Config) {
+		if c.modsrc == nil {
+			c.modsrc = make(map[string]func(*url.URL) (fs.FS, error))
+		}
+		c.modsrc[scheme] = h
+	}
+}
+
+// module is a loaded module instance.
+type module struct {
+	name    string
+	blocks  hclsyntax.Blocks
+	outputs cty.Value
+}
+
+// loadModules loads the module blocks defined in the body, removes them from it, and
+// registers their outputs in the context under the "module" object. The blocks defined
+// in the modules are returned and should be merged into the document.
+//
+//	module "audit" {
+//	  source = "./modules/audit"
+//	  // Other attributes are passed as input variables.
+//	  table  = "users"
+//	}
+//
+//	table "users" {
+//	  name = module.audit.table_name
+//	}
+func (s *State) loadModules(ctx *hcl.EvalContext, body *hclsyntax.Body, depth int) (hclsyntax.Blocks, error) {
+	var (
+		loaded  hclsyntax.Blocks
+		outputs = make(map[string]cty.Value)
+		blocks  = make(hclsyntax.Blocks, 0, len(body.Blocks))
+	)
+	if v, ok := ctx.Variables[RefModule]; ok && v.Type().IsObjectType() {
+		outputs = v.AsValueMap()
+	}
+	for _, b := range body.Blocks {
+		if b.Type != BlockModule {
+			blocks = append(blocks, b)
+			continue
+		}
+		m, err := s.loadModule(ctx, b, depth)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := outputs[m.name]; ok {
+			return nil, fmt.Errorf("%s: duplicate module %q", b.DefRange(), m.name)
+		}
+		outputs[m.name] = m.outputs
+		loaded = append(loaded, m.blocks...)
+	}
+	body.Blocks = blocks
+	if len(outputs) > 0 {
+		ctx.Variables[RefModule] = cty.ObjectVal(outputs)
+	}
+	return loaded, nil
+}
+
+// loadModule evaluates the module defined by the given block. Module documents are
+// evaluated in their own scope, where the attributes of the module block are used
+// as input variables, and "output" blocks define the values exposed to the caller.
+func (s *State) loadModule(ctx *hcl.EvalContext, b *hclsyntax.Block, depth int) (*module, error) {
+	if len(b.Labels) != 1 {
+		return nil, fmt.Errorf("%s: module block must have exactly 1 label", b.DefRange())
+	}
+	if depth >= maxModuleDepth {
+		return nil, fmt.Errorf("%s: module %q exceeds the maximum nesting depth of %d", b.DefRange(), b.Labels[0], maxModuleDepth)
+	}
+	m := &module{name: b.Labels[0]}
+	src, ok := b.Body.Attributes[moduleSource]
+	if !ok {
+		return nil, fmt.Errorf("%s: missing source attribute for module %q", b.DefRange(), m.name)
+	}
+	sv, diags := src.Expr.Value(ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if sv.Type() != cty.String || sv.IsNull() {
+		return nil, fmt.Errorf("%s: source attribute of module %q must be a string", src.SrcRange, m.name)
+	}
+	input := make(map[string]cty.Value)
+	for n, a := range b.Body.Attributes {
+		if n == moduleSource {
+			continue
+		}
+		v, diags := a.Expr.Value(ctx)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		input[n] = v
+	}
+	parser, err := s.parseModule(sv.AsString(), b.DefRange().Filename)
+	if err != nil {
+		return nil, fmt.Errorf("%s: loading module %q: %w", src.SrcRange, m.name, err)
+	}
+	var (
+		mctx    = s.newCtx()
+		files   = parser.Files()
+		names   = make([]string, 0, len(files))
+		outputs []*hclsyntax.Block
+	)
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		body := files[name].Body.(*hclsyntax.Body)
+		if err := s.setInputVals(mctx, body, input); err != nil {
+			return nil, fmt.Errorf("module %q: %w", m.name, err)
+		}
+		if err := s.evalReferences(mctx, body); err != nil {
+			return nil, fmt.Errorf("module %q: %w", m.name, err)
+		}
+		nested, err := s.loadModules(mctx, body, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		m.blocks = append(m.blocks, nested...)
+		for _, b := range body.Blocks {
+			switch b.Type {
+			case BlockVariable:
+			case BlockOutput:
+				outputs = append(outputs, b)
+			default:
+				nb, err := expandBlocks(mctx, hclsyntax.Blocks{b})
+				if err != nil {
+					return nil, err
+				}
+				// Bind the module scope to the blocks, as they
+				// are evaluated as part of the calling document.
+				for _, b := range nb {
+					cb, err := copyBlock(mctx, b, RefVar, RefLocal, RefData, RefModule)
+					if err != nil {
+						return nil, fmt.Errorf("module %q: %w", m.name, err)
+					}
+					m.blocks = append(m.blocks, cb)
+				}
+			}
+		}
+	}
+	// Outputs can reference the blocks defined in the module.
+	if _, err := setBlockVars(mctx, &hclsyntax.Body{Blocks: m.blocks}); err != nil {
+		return nil, err
+	}
+	values := make(map[string]cty.Value, len(outputs))
+	for _, o := range outputs {
+		if len(o.Labels) != 1 {
+			return nil, fmt.Errorf("%s: output block must have exactly 1 label", o.DefRange())
+		}
+		a, ok := o.Body.Attributes[outputValue]
+		if !ok {
+			return nil, fmt.Errorf("%s: missing value attribute for output %q", o.DefRange(), o.Labels[0])
+		}
+		v, diags := a.Expr.Value(mctx)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		values[o.Labels[0]] = v
+	}
+	m.outputs = cty.EmptyObjectVal
+	if len(values) > 0 {
+		m.outputs = cty.ObjectVal(values)
+	}
+	return m, nil
+}
+
+// parseModule parses the HCL files of the module source. Local sources are
+// resolved relative to the directory of the file that defines the module.
+func (s *State) parseModule(source, from string) (*hclparse.Parser, error) {
+	var (
+		fsys fs.FS
+		base = source
+		join = path.Join
+	)
+	switch u, err := url.Parse(source); {
+	// Single-letter schemes are Windows volume names.
+	case err == nil && len(u.Scheme) > 1:
+		h, ok := s.config.modsrc[u.Scheme]
+		if !ok {
+			return nil, fmt.Errorf("unsupported module source scheme %q", u.Scheme)
+		}
+		if fsys, err = h(u); err != nil {
+			return nil, err
+		}
+	default:
+		if !filepath.IsAbs(source) {
+			base = filepath.Join(filepath.Dir(from), source)
+		}
+		fsys, join = os.DirFS(base), filepath.Join
+	}
+	names, err := fs.Glob(fsys, "*.hcl")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no HCL files found in %q", source)
+	}
+	parser := hclparse.NewParser()
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if _, diags := parser.ParseHCL(data, join(base, name)); diags.HasErrors() {
+			return nil, diags
+		}
+	}
+	return parser, nil
+}
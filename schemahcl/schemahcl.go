@@ -8,6 +8,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"reflect"
 	"sort"
 	"strconv"
@@ -18,7 +20,9 @@ import (
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/gocty"
 )
 
 type (
@@ -30,6 +34,7 @@ type (
 		pathVars         map[string]map[string]cty.Value
 		pathFuncs        map[string]map[string]function.Function
 		datasrc, initblk map[string]func(*hcl.EvalContext, *hclsyntax.Block) (cty.Value, error)
+		modsrc           map[string]func(*url.URL) (fs.FS, error)
 		validator        func() SchemaValidator
 	}
 	// Option configures a Config.
@@ -40,16 +45,15 @@ type (
 func New(opts ...Option) *State {
 	cfg := &Config{
 		vars:      make(map[string]cty.Value),
-		funcs:     make(map[string]function.Function),
+		funcs:     stdFuncs(),
 		pathVars:  make(map[string]map[string]cty.Value),
 		pathFuncs: make(map[string]map[string]function.Function),
 	}
+	// User-defined functions take
+	// precedence over standard ones.
 	for _, opt := range opts {
 		opt(cfg)
 	}
-	for n, f := range stdFuncs() {
-		cfg.funcs[n] = f
-	}
 	return &State{
 		config: cfg,
 		newCtx: func() *hcl.EvalContext {
@@ -98,6 +102,13 @@ func WithVariables(vars map[string]cty.Value) Option {
 }
 
 // WithFunctions registers a list of functions to be injected into the context.
+// Functions registered with this option override standard functions with the
+// same name. For example:
+//
+//	WithFunctions(map[string]function.Function{
+//		"file":   MakeFileFunc(base),
+//		"getenv": GetenvFunc,
+//	})
 func WithFunctions(funcs map[string]function.Function) Option {
 	return func(c *Config) {
 		if c.funcs == nil {
@@ -280,23 +291,31 @@ func (s *State) Eval(parsed *hclparse.Parser, v any, input map[string]cty.Value)
 		if err := s.evalReferences(ctx, body); err != nil {
 			return err
 		}
-		blocks := make(hclsyntax.Blocks, 0, len(body.Blocks))
-		for _, b := range body.Blocks {
+		modules, err := s.loadModules(ctx, body, 0)
+		if err != nil {
+			return err
+		}
+		blocks := make(hclsyntax.Blocks, 0, len(body.Blocks)+len(modules))
+		for _, b := range append(modules, body.Blocks...) {
 			switch {
 			// Variable blocks are not reachable by reference.
 			case b.Type == BlockVariable:
 				continue
-			// Semi-evaluate blocks with the for_each meta argument.
-			case b.Body != nil && b.Body.Attributes[forEachAttr] != nil:
-				nb, err := forEachBlocks(ctx, b)
+			default:
+				// Semi-evaluate blocks with the for_each or count meta arguments.
+				nb, err := expandBlocks(ctx, hclsyntax.Blocks{b})
 				if err != nil {
 					return err
 				}
 				blocks = append(blocks, nb...)
-			default:
-				blocks = append(blocks, b)
+				for _, b := range nb {
+					reg.child(extractDef(b, reg))
+				}
+				// Keep empty expansions reachable by reference.
+				if len(nb) == 0 {
+					reg.child(extractDef(b, reg))
+				}
 			}
-			reg.child(extractDef(b, reg))
 		}
 		body.Blocks = blocks
 		allBlocks = append(allBlocks, blocks...)
@@ -493,7 +512,7 @@ func (s *State) toAttrs(ctx *hcl.EvalContext, vr SchemaValidator, hclAttrs hclsy
 		if err := vr.ValidateAttribute(ctx, hclAttr, value); err != nil {
 			return nil, err
 		}
-		at := &Attr{K: hclAttr.Name}
+		at := &Attr{K: hclAttr.Name, rang: hclAttr.SrcRange.Ptr()}
 		switch t := value.Type(); {
 		case isRef(value):
 			at.V = cty.CapsuleVal(ctyRefType, &Ref{V: value.GetAttr("__ref").AsString()})
@@ -580,7 +599,7 @@ func (s *State) toResource(ctx *hcl.EvalContext, vr SchemaValidator, block *hcls
 	if err != nil {
 		return nil, err
 	}
-	spec = &Resource{Type: block.Type}
+	spec = &Resource{Type: block.Type, rang: block.DefRange().Ptr()}
 	switch len(block.Labels) {
 	case 0:
 	case 1:
@@ -874,15 +893,48 @@ func hclList(items []hclwrite.Tokens) hclwrite.Tokens {
 	return t
 }
 
+// expandBlocks expands the blocks that use the "for_each" or "count" meta
+// arguments, including nested blocks, into their concrete instances.
+func expandBlocks(ctx *hcl.EvalContext, blocks hclsyntax.Blocks) (hclsyntax.Blocks, error) {
+	expanded := make(hclsyntax.Blocks, 0, len(blocks))
+	for _, b := range blocks {
+		switch {
+		case b.Body == nil:
+			expanded = append(expanded, b)
+		case b.Body.Attributes[forEachAttr] != nil && b.Body.Attributes[countAttr] != nil:
+			return nil, fmt.Errorf("%s: schemahcl: for_each and count cannot be used together", b.DefRange())
+		case b.Body.Attributes[forEachAttr] != nil:
+			nb, err := forEachBlocks(ctx, b)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, nb...)
+		case b.Body.Attributes[countAttr] != nil:
+			nb, err := countBlocks(ctx, b)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, nb...)
+		default:
+			children, err := expandBlocks(ctx, b.Body.Blocks)
+			if err != nil {
+				return nil, err
+			}
+			b.Body.Blocks = children
+			expanded = append(expanded, b)
+		}
+	}
+	return expanded, nil
+}
+
 func forEachBlocks(ctx *hcl.EvalContext, b *hclsyntax.Block) ([]*hclsyntax.Block, error) {
 	forEach, diags := b.Body.Attributes[forEachAttr].Expr.Value(ctx)
 	if diags.HasErrors() {
 		return nil, diags
 	}
-	if t := forEach.Type(); !t.IsSetType() && !t.IsObjectType() {
+	if t := forEach.Type(); !t.IsSetType() && !t.IsObjectType() && !t.IsMapType() {
 		return nil, fmt.Errorf("schemahcl: for_each does not support %s type", t.FriendlyName())
 	}
-	delete(b.Body.Attributes, forEachAttr)
 	blocks := make([]*hclsyntax.Block, 0, forEach.LengthInt())
 	for it := forEach.ElementIterator(); it.Next(); {
 		k, v := it.Element()
@@ -893,7 +945,7 @@ func forEachBlocks(ctx *hcl.EvalContext, b *hclsyntax.Block) ([]*hclsyntax.Block
 				"value": v,
 			}),
 		}
-		nb, err := copyBlock(nctx, b)
+		nb, err := copyBlock(nctx, b, eachRef, countRef)
 		if err != nil {
 			return nil, fmt.Errorf("schemahcl: evaluate block for value %q: %w", v, err)
 		}
@@ -902,34 +954,115 @@ func forEachBlocks(ctx *hcl.EvalContext, b *hclsyntax.Block) ([]*hclsyntax.Block
 	return blocks, nil
 }
 
-func copyBlock(ctx *hcl.EvalContext, b *hclsyntax.Block) (*hclsyntax.Block, error) {
+func countBlocks(ctx *hcl.EvalContext, b *hclsyntax.Block) ([]*hclsyntax.Block, error) {
+	attr := b.Body.Attributes[countAttr]
+	count, diags := attr.Expr.Value(ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	countErr := func(detail string) error {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "schemahcl: invalid count argument",
+			Detail:   detail,
+			Subject:  attr.Expr.Range().Ptr(),
+		}}
+	}
+	switch {
+	case count.IsNull():
+		return nil, countErr("count must not be null")
+	case !count.IsKnown():
+		return nil, countErr("count must be known, got an unknown value")
+	}
+	num, err := convert.Convert(count, cty.Number)
+	if err != nil {
+		return nil, countErr(fmt.Sprintf("count must be a number, got %s", count.Type().FriendlyName()))
+	}
+	var n int
+	if err := gocty.FromCtyValue(num, &n); err != nil || n < 0 {
+		return nil, countErr(fmt.Sprintf("count must be a non-negative whole number, got %s", num.AsBigFloat().String()))
+	}
+	blocks := make([]*hclsyntax.Block, 0, n)
+	for i := 0; i < n; i++ {
+		nctx := ctx.NewChild()
+		nctx.Variables = map[string]cty.Value{
+			countRef: cty.ObjectVal(map[string]cty.Value{
+				"index": cty.NumberIntVal(int64(i)),
+			}),
+		}
+		nb, err := copyBlock(nctx, b, eachRef, countRef)
+		if err != nil {
+			return nil, fmt.Errorf("schemahcl: evaluate block for index %d: %w", i, err)
+		}
+		blocks = append(blocks, nb)
+	}
+	return blocks, nil
+}
+
+// copyBlock copies the block and evaluates the attributes that reference the
+// given root variables (e.g., "each" or "count"). Other attributes are left as
+// is, to be evaluated along with the rest of the document.
+func copyBlock(ctx *hcl.EvalContext, b *hclsyntax.Block, refs ...string) (*hclsyntax.Block, error) {
 	nb := &hclsyntax.Block{
-		Type:   b.Type,
-		Labels: b.Labels,
+		Type:            b.Type,
+		Labels:          b.Labels,
+		TypeRange:       b.TypeRange,
+		LabelRanges:     b.LabelRanges,
+		OpenBraceRange:  b.OpenBraceRange,
+		CloseBraceRange: b.CloseBraceRange,
 		Body: &hclsyntax.Body{
 			Attributes: make(map[string]*hclsyntax.Attribute),
-			Blocks:     make([]*hclsyntax.Block, 0, len(b.Body.Blocks)),
+			SrcRange:   b.Body.SrcRange,
+			EndRange:   b.Body.EndRange,
 		},
 	}
 	for k, v := range b.Body.Attributes {
-		x, diags := v.Expr.Value(ctx)
-		if diags.HasErrors() {
-			return nil, diags
+		// Meta arguments of the copied block.
+		if k == forEachAttr || k == countAttr {
+			continue
 		}
 		nv := *v
-		nv.Expr = &hclsyntax.LiteralValueExpr{Val: x}
+		if hasRefs(v.Expr, refs...) {
+			x, diags := v.Expr.Value(ctx)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			nv.Expr = &hclsyntax.LiteralValueExpr{Val: x, SrcRange: v.Expr.Range()}
+		}
 		nb.Body.Attributes[k] = &nv
 	}
-	for _, v := range b.Body.Blocks {
-		nv, err := copyBlock(ctx, v)
+	for _, c := range b.Body.Blocks {
+		// Expanded blocks are already copied. Others are
+		// copied to avoid changing the original definition.
+		if c.Body.Attributes[forEachAttr] != nil || c.Body.Attributes[countAttr] != nil {
+			children, err := expandBlocks(ctx, hclsyntax.Blocks{c})
+			if err != nil {
+				return nil, err
+			}
+			nb.Body.Blocks = append(nb.Body.Blocks, children...)
+			continue
+		}
+		nc, err := copyBlock(ctx, c, refs...)
 		if err != nil {
 			return nil, err
 		}
-		nb.Body.Blocks = append(nb.Body.Blocks, nv)
+		nb.Body.Blocks = append(nb.Body.Blocks, nc)
 	}
 	return nb, nil
 }
 
+// hasRefs reports if the expression references any of the given root variables.
+func hasRefs(x hclsyntax.Expression, refs ...string) bool {
+	for _, v := range hclsyntax.Variables(x) {
+		for _, r := range refs {
+			if v.RootName() == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Eval implements the Evaluator interface.
 func (f EvalFunc) Eval(p *hclparse.Parser, i any, input map[string]cty.Value) error {
 	return f(p, i, input)
@@ -7,11 +7,14 @@ package schemahcl
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -463,6 +466,213 @@ env "dev" {
 	require.EqualError(t, err, `variable "domains": a number is required`)
 }
 
+func TestCountResources(t *testing.T) {
+	type (
+		Column struct {
+			Name string `spec:",name"`
+			Type string `spec:"type"`
+		}
+		Table struct {
+			Name    string    `spec:",name"`
+			Alias   string    `spec:"name"`
+			Columns []*Column `spec:"column"`
+		}
+	)
+	var (
+		doc struct {
+			Tables []*Table `spec:"table"`
+		}
+		b = []byte(`
+variable "months" {
+  type    = list(string)
+  default = ["jan", "feb", "mar"]
+}
+
+variable "columns" {
+  type    = map(string)
+  default = {
+    id   = "int"
+    name = "text"
+  }
+}
+
+table "events" {
+  count = length(var.months)
+  name  = "events_${var.months[count.index]}"
+  column "id" {
+    type = "int"
+  }
+  column "month" {
+    type = "text"
+  }
+}
+
+table "users" {
+  column "c" {
+    for_each = var.columns
+    type     = each.value
+  }
+}
+
+table "empty" {
+  count = 0
+}
+`)
+	)
+	require.NoError(t, New().EvalBytes(b, &doc, nil))
+	require.Len(t, doc.Tables, 4)
+	for i, m := range []string{"jan", "feb", "mar"} {
+		require.Equal(t, "events", doc.Tables[i].Name)
+		require.Equal(t, "events_"+m, doc.Tables[i].Alias)
+		require.Len(t, doc.Tables[i].Columns, 2)
+		require.Equal(t, "int", doc.Tables[i].Columns[0].Type)
+	}
+	require.Equal(t, "users", doc.Tables[3].Name)
+	require.Len(t, doc.Tables[3].Columns, 2)
+	require.Equal(t, "int", doc.Tables[3].Columns[0].Type)
+	require.Equal(t, "text", doc.Tables[3].Columns[1].Type)
+
+	err := New().EvalBytes([]byte(`
+table "t" {
+  count = -1
+}
+`), &doc, nil)
+	require.EqualError(t, err, ":3,11-13: schemahcl: invalid count argument; count must be a non-negative whole number, got -1")
+
+	err = New().EvalBytes([]byte(`
+table "t" {
+  count = "abc"
+}
+`), &doc, nil)
+	require.EqualError(t, err, `:3,11-16: schemahcl: invalid count argument; count must be a number, got string`)
+
+	err = New().EvalBytes([]byte(`
+table "t" {
+  count = null
+}
+`), &doc, nil)
+	require.EqualError(t, err, ":3,11-15: schemahcl: invalid count argument; count must not be null")
+
+	f, diags := hclsyntax.ParseConfig([]byte(`
+table "t" {
+  count = var.n
+}
+`), "", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	ctx := &hcl.EvalContext{Variables: map[string]cty.Value{
+		"var": cty.ObjectVal(map[string]cty.Value{"n": cty.UnknownVal(cty.Number)}),
+	}}
+	_, err = countBlocks(ctx, f.Body.(*hclsyntax.Body).Blocks[0])
+	require.EqualError(t, err, ":3,11-16: schemahcl: invalid count argument; count must be known, got an unknown value")
+
+	err = New().EvalBytes([]byte(`
+table "t" {
+  count    = 1
+  for_each = toset(["a"])
+}
+`), &doc, nil)
+	require.EqualError(t, err, ":2,1-12: schemahcl: for_each and count cannot be used together")
+}
+
+func TestModules(t *testing.T) {
+	type (
+		Column struct {
+			Name string `spec:",name"`
+			Type string `spec:"type"`
+		}
+		Table struct {
+			Name    string    `spec:",name"`
+			Alias   string    `spec:"name"`
+			Comment string    `spec:"comment"`
+			Columns []*Column `spec:"column"`
+		}
+	)
+	var (
+		doc struct {
+			Tables []*Table `spec:"table"`
+		}
+		dir = t.TempDir()
+	)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "modules", "audit"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "modules", "audit", "main.hcl"), []byte(`
+variable "prefix" {
+  type = string
+}
+
+locals {
+  name = "${var.prefix}audit"
+}
+
+table "audit" {
+  name = local.name
+  column "id" {
+    type = "bigint"
+  }
+  column "actor" {
+    for_each = toset(["created_by", "updated_by"])
+    type     = "${each.value}:text"
+  }
+}
+
+output "table" {
+  value = table.audit.name
+}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.hcl"), []byte(`
+module "audit" {
+  source = "./modules/audit"
+  prefix = "app_"
+}
+
+module "remote" {
+  source = "mem://soft-delete"
+}
+
+table "users" {
+  comment = "audited by ${module.audit.table}"
+  column "id" {
+    type = "int"
+  }
+}
+`), 0644))
+	err := New(
+		WithModuleSource("mem", func(u *url.URL) (fs.FS, error) {
+			require.Equal(t, "soft-delete", u.Host)
+			return fstest.MapFS{
+				"main.hcl": &fstest.MapFile{Data: []byte(`table "deleted" {}`)},
+			}, nil
+		}),
+	).EvalFiles([]string{filepath.Join(dir, "main.hcl")}, &doc, nil)
+	require.NoError(t, err)
+	require.Len(t, doc.Tables, 3)
+	require.Equal(t, "audit", doc.Tables[0].Name)
+	require.Equal(t, "app_audit", doc.Tables[0].Alias)
+	require.Len(t, doc.Tables[0].Columns, 3)
+	require.Equal(t, "created_by:text", doc.Tables[0].Columns[1].Type)
+	require.Equal(t, "updated_by:text", doc.Tables[0].Columns[2].Type)
+	require.Equal(t, "deleted", doc.Tables[1].Name)
+	require.Equal(t, "users", doc.Tables[2].Name)
+	require.Equal(t, "audited by app_audit", doc.Tables[2].Comment)
+
+	err = New().EvalBytes([]byte(`
+module "audit" {
+  source = "unknown://audit"
+}
+`), &doc, nil)
+	require.EqualError(t, err, `:3,3-29: loading module "audit": unsupported module source scheme "unknown"`)
+
+	err = New().EvalFiles([]string{filepath.Join(dir, "main.hcl")}, &doc, nil)
+	require.ErrorContains(t, err, `unsupported module source scheme "mem"`)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "missing.hcl"), []byte(`
+module "audit" {
+  source = "./modules/audit"
+}
+`), 0644))
+	err = New().EvalFiles([]string{filepath.Join(dir, "missing.hcl")}, &doc, nil)
+	require.EqualError(t, err, `module "audit": missing value for required variable "prefix"`)
+}
+
 func TestDataLocalsRefs(t *testing.T) {
 	var (
 		opts = []Option{
@@ -5,12 +5,15 @@
 package schemahcl
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
 	"strings"
 
 	"ariga.io/atlas/sql/schema"
+
+	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
 )
@@ -23,12 +26,14 @@ type (
 		Type      string
 		Attrs     []*Attr
 		Children  []*Resource
+		rang      *hcl.Range
 	}
 
 	// Attr is an attribute of a Resource.
 	Attr struct {
-		K string
-		V cty.Value
+		K    string
+		V    cty.Value
+		rang *hcl.Range
 	}
 
 	// Ref implements Value and represents a reference to another Resource.
@@ -91,6 +96,113 @@ type (
 	}
 )
 
+// PosError is an error that is associated with a position in the HCL document.
+type PosError struct {
+	Range hcl.Range
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *PosError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Range, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PosError) Unwrap() error {
+	return e.Err
+}
+
+// Diagnostic returns the error as an HCL diagnostic.
+func (e *PosError) Diagnostic() *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  e.Err.Error(),
+		Subject:  e.Range.Ptr(),
+	}
+}
+
+// ErrorAt attaches the given position to the error. If the error is already
+// associated with a (more specific) position, the position is moved to the top
+// of the error chain, so it is reported first.
+func ErrorAt(p *hcl.Range, err error) error {
+	var pe *PosError
+	switch _, joined := joinedError(err); {
+	case err == nil:
+		return nil
+	// Joined errors hold their own positions.
+	case joined:
+		return err
+	case errors.As(err, &pe):
+		if err == error(pe) {
+			return err
+		}
+		return &PosError{
+			Range: pe.Range,
+			Err:   &wrapError{msg: strings.Replace(err.Error(), pe.Error(), pe.Err.Error(), 1), err: err},
+		}
+	case p == nil:
+		return err
+	default:
+		return &PosError{Range: *p, Err: err}
+	}
+}
+
+// wrapError wraps an error with a custom message.
+type wrapError struct {
+	msg string
+	err error
+}
+
+func (e *wrapError) Error() string { return e.msg }
+func (e *wrapError) Unwrap() error { return e.err }
+
+// Diagnostics converts the given error to HCL diagnostics. Positions
+// are extracted from the error chain if exist, and errors joined with
+// errors.Join are converted to a diagnostic each.
+func Diagnostics(err error) hcl.Diagnostics {
+	var (
+		pe    *PosError
+		diags hcl.Diagnostics
+	)
+	switch errs, ok := joinedError(err); {
+	case err == nil:
+		return nil
+	case ok:
+		for _, err := range errs {
+			diags = append(diags, Diagnostics(err)...)
+		}
+		return diags
+	case errors.As(err, &diags):
+		return diags
+	case errors.As(err, &pe):
+		if err = ErrorAt(nil, err); errors.As(err, &pe) {
+			return hcl.Diagnostics{pe.Diagnostic()}
+		}
+	}
+	return hcl.Diagnostics{{Severity: hcl.DiagError, Summary: err.Error()}}
+}
+
+// joinedError returns the errors of the first joined error in the chain, if exists.
+func joinedError(err error) ([]error, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if j, ok := err.(interface{ Unwrap() []error }); ok {
+			return j.Unwrap(), true
+		}
+	}
+	return nil, false
+}
+
+// Range returns the position of the attribute in the HCL document,
+// or nil if the attribute was not created from a document.
+func (a *Attr) Range() *hcl.Range {
+	return a.rang
+}
+
+// SetRange sets the position of the attribute in the HCL document.
+func (a *Attr) SetRange(p *hcl.Range) {
+	a.rang = p
+}
+
 // IsRef indicates if the attribute is a reference type.
 func (a *Attr) IsRef() bool {
 	if !a.V.Type().IsCapsuleType() {
@@ -360,6 +472,17 @@ func (a *Attr) Bools() (vs []bool, err error) {
 	return vs, nil
 }
 
+// Range returns the position of the resource in the HCL document,
+// or nil if the resource was not created from a document.
+func (r *Resource) Range() *hcl.Range {
+	return r.rang
+}
+
+// SetRange sets the position of the resource in the HCL document.
+func (r *Resource) SetRange(p *hcl.Range) {
+	r.rang = p
+}
+
 // Resource returns the first child Resource by its type and reports whether it was found.
 func (r *Resource) Resource(t string) (*Resource, bool) {
 	if r == nil {
@@ -5,9 +5,12 @@
 package schemahcl
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/require"
 )
 
@@ -148,3 +151,39 @@ func TestBuildRef(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorAt(t *testing.T) {
+	require.NoError(t, ErrorAt(nil, nil))
+	err := errors.New("error")
+	require.Equal(t, err, ErrorAt(nil, err))
+
+	r1 := &hcl.Range{Filename: "a.hcl", Start: hcl.Pos{Line: 1, Column: 1}, End: hcl.Pos{Line: 1, Column: 5}}
+	r2 := &hcl.Range{Filename: "a.hcl", Start: hcl.Pos{Line: 2, Column: 3}, End: hcl.Pos{Line: 2, Column: 7}}
+	err1 := ErrorAt(r2, err)
+	require.EqualError(t, err1, "a.hcl:2,3-7: error")
+	require.ErrorIs(t, err1, err)
+	// Positioned errors are not overridden.
+	require.Equal(t, err1, ErrorAt(r1, err1))
+
+	// Inner positions are moved to the top.
+	err2 := ErrorAt(r1, fmt.Errorf("outer: %w", err1))
+	require.EqualError(t, err2, "a.hcl:2,3-7: outer: error")
+	require.ErrorIs(t, err2, err)
+
+	diags := Diagnostics(fmt.Errorf("outer: %w", err1))
+	require.Len(t, diags, 1)
+	require.Equal(t, "outer: error", diags[0].Summary)
+	require.Equal(t, r2, diags[0].Subject)
+	diags = Diagnostics(err)
+	require.Len(t, diags, 1)
+	require.Equal(t, "error", diags[0].Summary)
+	require.Nil(t, diags[0].Subject)
+
+	// Joined errors are reported separately.
+	joined := fmt.Errorf("outer: %w", errors.Join(ErrorAt(r1, err), err1))
+	require.Equal(t, joined, ErrorAt(r1, joined))
+	diags = Diagnostics(joined)
+	require.Len(t, diags, 2)
+	require.Equal(t, r1, diags[0].Subject)
+	require.Equal(t, r2, diags[1].Subject)
+}
@@ -10,7 +10,9 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
+	"github.com/go-openapi/inflect"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/ext/tryfunc"
 	"github.com/zclconf/go-cty/cty"
@@ -104,15 +106,20 @@ func stdTypes(ctx *hcl.EvalContext) *hcl.EvalContext {
 func stdFuncs() map[string]function.Function {
 	return map[string]function.Function{
 		"abs":             stdlib.AbsoluteFunc,
+		"alltrue":         allTrueFunc,
+		"anytrue":         anyTrueFunc,
+		"camelcase":       camelCaseFunc,
 		"ceil":            stdlib.CeilFunc,
 		"chomp":           stdlib.ChompFunc,
 		"chunklist":       stdlib.ChunklistFunc,
+		"coalesce":        stdlib.CoalesceFunc,
 		"coalescelist":    stdlib.CoalesceListFunc,
 		"compact":         stdlib.CompactFunc,
 		"concat":          stdlib.ConcatFunc,
 		"contains":        stdlib.ContainsFunc,
 		"csvdecode":       stdlib.CSVDecodeFunc,
 		"distinct":        stdlib.DistinctFunc,
+		"endswith":        endsWithFunc,
 		"element":         stdlib.ElementFunc,
 		"flatten":         stdlib.FlattenFunc,
 		"floor":           stdlib.FloorFunc,
@@ -125,27 +132,36 @@ func stdFuncs() map[string]function.Function {
 		"jsondecode":      stdlib.JSONDecodeFunc,
 		"jsonencode":      stdlib.JSONEncodeFunc,
 		"keys":            stdlib.KeysFunc,
+		"length":          stdlib.LengthFunc,
 		"log":             stdlib.LogFunc,
+		"lookup":          stdlib.LookupFunc,
 		"lower":           stdlib.LowerFunc,
 		"max":             stdlib.MaxFunc,
 		"merge":           stdlib.MergeFunc,
 		"min":             stdlib.MinFunc,
 		"parseint":        stdlib.ParseIntFunc,
+		"pluralize":       pluralizeFunc,
 		"pow":             stdlib.PowFunc,
 		"print":           printFunc,
 		"range":           stdlib.RangeFunc,
 		"regex":           stdlib.RegexFunc,
 		"regexall":        stdlib.RegexAllFunc,
 		"regexreplace":    stdlib.RegexReplaceFunc,
+		"replace":         stdlib.ReplaceFunc,
 		"reverse":         stdlib.ReverseListFunc,
 		"setintersection": stdlib.SetIntersectionFunc,
 		"setproduct":      stdlib.SetProductFunc,
 		"setsubtract":     stdlib.SetSubtractFunc,
 		"setunion":        stdlib.SetUnionFunc,
 		"signum":          stdlib.SignumFunc,
+		"singularize":     singularizeFunc,
 		"slice":           stdlib.SliceFunc,
+		"snakecase":       snakeCaseFunc,
 		"sort":            stdlib.SortFunc,
 		"split":           stdlib.SplitFunc,
+		"startswith":      startsWithFunc,
+		"strcontains":     strContainsFunc,
+		"strlen":          stdlib.StrlenFunc,
 		"strrev":          stdlib.ReverseFunc,
 		"substr":          stdlib.SubstrFunc,
 		"timeadd":         stdlib.TimeAddFunc,
@@ -344,6 +360,18 @@ var (
 		},
 	})
 
+	startsWithFunc  = makeStrPredFunc("prefix", strings.HasPrefix)
+	endsWithFunc    = makeStrPredFunc("suffix", strings.HasSuffix)
+	strContainsFunc = makeStrPredFunc("substr", strings.Contains)
+
+	snakeCaseFunc   = makeStrFunc(inflect.Underscore)
+	camelCaseFunc   = makeStrFunc(inflect.CamelizeDownFirst)
+	pluralizeFunc   = makeStrFunc(inflect.Pluralize)
+	singularizeFunc = makeStrFunc(inflect.Singularize)
+
+	allTrueFunc = makeBoolListFunc(true)
+	anyTrueFunc = makeBoolListFunc(false)
+
 	printFunc = function.New(&function.Spec{
 		Params: []function.Parameter{
 			{
@@ -374,6 +402,87 @@ var (
 	})
 )
 
+// makeStrFunc returns a function that transforms its string argument using f.
+func makeStrFunc(f func(string) string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "string",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			return cty.StringVal(f(args[0].AsString())), nil
+		},
+	})
+}
+
+// makeStrPredFunc returns a function that reports if f is true for its string arguments.
+func makeStrPredFunc(name string, f func(string, string) bool) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "string",
+				Type: cty.String,
+			},
+			{
+				Name: name,
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Bool),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			return cty.BoolVal(f(args[0].AsString(), args[1].AsString())), nil
+		},
+	})
+}
+
+// makeBoolListFunc returns the "alltrue" function if all is true, and "anytrue" otherwise.
+func makeBoolListFunc(all bool) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "list",
+				Type: cty.List(cty.Bool),
+			},
+		},
+		Type: function.StaticReturnType(cty.Bool),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			for it := args[0].ElementIterator(); it.Next(); {
+				_, v := it.Element()
+				if v.IsNull() || !v.IsKnown() {
+					return cty.False, nil
+				}
+				if v.True() != all {
+					return cty.BoolVal(!all), nil
+				}
+			}
+			return cty.BoolVal(all), nil
+		},
+	})
+}
+
+// GetenvFunc is a function that returns the value of the environment variable
+// named by its argument. It is not part of the standard functions, and should be
+// registered explicitly using the WithFunctions option. For example:
+//
+//	WithFunctions(map[string]function.Function{
+//		"getenv": GetenvFunc,
+//	})
+var GetenvFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "key",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+		return cty.StringVal(os.Getenv(args[0].AsString())), nil
+	},
+})
+
 // MakeFileFunc returns a function that reads a file
 // from the given base directory.
 func MakeFileFunc(base string) function.Function {
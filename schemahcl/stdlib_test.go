@@ -12,6 +12,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
 )
 
 func TestURLSetPathFunc(t *testing.T) {
@@ -150,6 +151,65 @@ func TestMakeFileFunc(t *testing.T) {
 	require.Equal(t, "person \"rotemtam\" {\n  hobby = var.hobby\n}", v.AsString())
 }
 
+func TestStringFuncs(t *testing.T) {
+	var d struct {
+		Snake    string `spec:"snake"`
+		Camel    string `spec:"camel"`
+		Plural   string `spec:"plural"`
+		Singular string `spec:"singular"`
+		Starts   bool   `spec:"starts"`
+		Ends     bool   `spec:"ends"`
+		Contains bool   `spec:"contains"`
+		Replace  string `spec:"replace"`
+		Length   int    `spec:"length"`
+		All      bool   `spec:"all"`
+		Any      bool   `spec:"any"`
+		Lookup   string `spec:"lookup"`
+	}
+	err := New().EvalBytes([]byte(`
+snake    = snakecase("UserGroups")
+camel    = camelcase("user_groups")
+plural   = pluralize("user")
+singular = singularize("users")
+starts   = startswith("atlas_users", "atlas_")
+ends     = endswith("atlas_users", "_groups")
+contains = strcontains("atlas_users", "s_u")
+replace  = replace("a-b-c", "-", "_")
+length   = length(["a", "b", "c"])
+all      = alltrue([true, startswith("ab", "a")])
+any      = anytrue([false, endswith("ab", "a")])
+lookup   = lookup({ a = "b" }, "c", "d")
+`), &d, nil)
+	require.NoError(t, err)
+	require.Equal(t, "user_groups", d.Snake)
+	require.Equal(t, "userGroups", d.Camel)
+	require.Equal(t, "users", d.Plural)
+	require.Equal(t, "user", d.Singular)
+	require.True(t, d.Starts)
+	require.False(t, d.Ends)
+	require.True(t, d.Contains)
+	require.Equal(t, "a_b_c", d.Replace)
+	require.Equal(t, 3, d.Length)
+	require.True(t, d.All)
+	require.False(t, d.Any)
+	require.Equal(t, "d", d.Lookup)
+}
+
+func TestWithFunctions_Override(t *testing.T) {
+	var d struct {
+		V string `spec:"v"`
+	}
+	t.Setenv("ATLAS_SCHEMAHCL_TEST", "value")
+	err := New(
+		WithFunctions(map[string]function.Function{
+			"getenv": GetenvFunc,
+			"upper":  makeStrFunc(func(string) string { return "overridden" }),
+		}),
+	).EvalBytes([]byte(`v = "${getenv("ATLAS_SCHEMAHCL_TEST")}-${upper("a")}"`), &d, nil)
+	require.NoError(t, err)
+	require.Equal(t, "value-overridden", d.V)
+}
+
 func Example_PrintFunc() {
 	for _, f := range []string{
 		`v  = print("a")`,
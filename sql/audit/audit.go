@@ -0,0 +1,217 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package audit generates audit tables for database tables, and the triggers that populate
+// them. Each change of an audited table is recorded in its audit table, with the operation,
+// the old and new rows as JSON, the actor and the timestamp of the change.
+//
+// Generate works on a copy of the given schema, and returns the copy with the audit tables
+// and the triggers attached to the audited tables. The result is a desired state, that can
+// be diffed and planned like any other schema. Note, triggers are compared with the current
+// state only if it was inspected with triggers (i.e. schema.InspectOptions.Triggers). The
+// builtin dialects reside in the driver packages. For example:
+//
+//	desired, err := audit.Generate(postgresaudit.Dialect, s, audit.WithTables("users", "posts"))
+//	if err != nil {
+//		return err
+//	}
+//	current, err := drv.InspectSchema(ctx, s.Name, &schema.InspectOptions{Triggers: true})
+//	if err != nil {
+//		return err
+//	}
+//	changes, err := drv.SchemaDiff(current, desired)
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Column names of audit tables.
+const (
+	ColumnID    = "id"
+	ColumnOp    = "operation"
+	ColumnOld   = "old_row"
+	ColumnNew   = "new_row"
+	ColumnActor = "actor"
+	ColumnTime  = "changed_at"
+)
+
+type (
+	// Dialect describes how audit tables and their triggers are generated for a database.
+	// Custom dialects, or modified copies of the builtin ones, can be passed to Generate.
+	Dialect struct {
+		// ID returns the primary-key column of audit tables. Usually, an auto-increment integer.
+		ID func() *schema.Column
+		// String, JSON and Time are the types of the operation and actor columns,
+		// the old and new row columns, and the timestamp column respectively.
+		String, JSON, Time schema.Type
+		// Now is the default value of the timestamp column.
+		Now string
+		// Actor is the expression that returns the actor of a change. Can be
+		// overridden by the WithActor option.
+		Actor string
+		// MaxName is the maximum length of identifiers. Generated names that exceed
+		// it are truncated and suffixed with a hash. Zero means no limit.
+		MaxName int
+		// Triggers attaches the triggers that populate the audit table to the audited
+		// table. Objects the triggers depend on (e.g. functions) are attached to them.
+		Triggers func(*Audit) error
+	}
+
+	// Audit describes the audit of a table.
+	Audit struct {
+		T     *schema.Table // Audited table.
+		Log   *schema.Table // Audit table.
+		Actor string        // Actor expression.
+		max   int
+	}
+
+	// Option allows configuring the generation using functional options.
+	Option func(*generator)
+
+	// generator holds the configuration of the generation.
+	generator struct {
+		suffix, actor string
+		tables        []string
+	}
+)
+
+// WithSuffix sets the suffix of audit table names. Defaults to "_audit".
+func WithSuffix(s string) Option {
+	return func(g *generator) {
+		g.suffix = s
+	}
+}
+
+// WithActor sets the expression that returns the actor of a change. For example, a
+// setting that is set by the application at the start of each PostgreSQL transaction:
+//
+//	audit.WithActor("current_setting('app.user', true)")
+func WithActor(x string) Option {
+	return func(g *generator) {
+		g.actor = x
+	}
+}
+
+// WithTables sets the names of the audited tables. Defaults to all schema tables.
+func WithTables(names ...string) Option {
+	return func(g *generator) {
+		g.tables = append(g.tables, names...)
+	}
+}
+
+// Name returns the name of an object of the audit, formed by the audit table name and
+// the given suffix, and bounded by the maximum identifier length of the dialect.
+func (a *Audit) Name(suffix string) string {
+	return boundName(a.Log.Name+suffix, a.max)
+}
+
+// Generate returns a copy of the schema, in which the audited tables have audit
+// tables, and triggers that populate them. The given schema is not modified.
+func Generate(d *Dialect, s *schema.Schema, opts ...Option) (*schema.Schema, error) {
+	g := &generator{suffix: "_audit", actor: d.Actor}
+	for _, opt := range opts {
+		opt(g)
+	}
+	var (
+		c      = sqlx.CloneSchema(s)
+		tables = c.Tables
+	)
+	if len(g.tables) > 0 {
+		tables = make([]*schema.Table, 0, len(g.tables))
+		for _, name := range g.tables {
+			t, ok := c.Table(name)
+			if !ok {
+				return nil, fmt.Errorf("audit: table %q was not found in schema %q", name, s.Name)
+			}
+			tables = append(tables, t)
+		}
+	}
+	for _, t := range tables {
+		name := boundName(t.Name+g.suffix, d.MaxName)
+		if _, ok := c.Table(name); ok {
+			return nil, fmt.Errorf("audit: table %q already exists in schema %q", name, s.Name)
+		}
+		id := d.ID()
+		a := &Audit{
+			T: t,
+			Log: schema.NewTable(name).
+				SetComment(fmt.Sprintf("Audit log of table %s.", t.Name)).
+				AddColumns(
+					id,
+					schema.NewColumn(ColumnOp).SetType(d.String),
+					schema.NewNullColumn(ColumnOld).SetType(d.JSON),
+					schema.NewNullColumn(ColumnNew).SetType(d.JSON),
+					schema.NewNullColumn(ColumnActor).SetType(d.String),
+					schema.NewColumn(ColumnTime).SetType(d.Time).SetDefault(&schema.RawExpr{X: d.Now}),
+				).
+				SetPrimaryKey(schema.NewPrimaryKey(id)),
+			Actor: g.actor,
+			max:   d.MaxName,
+		}
+		c.AddTables(a.Log)
+		if err := d.Triggers(a); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// RowTriggers attaches a row-level trigger for each operation to the audited table,
+// that inserts the old and new rows, built by the JSON object function f, into the audit
+// table. Identifiers are quoted by the given function. Used by MySQL and SQLite.
+func RowTriggers(a *Audit, f string, quote func(string) string) error {
+	if len(a.T.Columns) == 0 {
+		return fmt.Errorf("audit: table %q has no columns", a.T.Name)
+	}
+	row := func(prefix string) string {
+		args := make([]string, len(a.T.Columns))
+		for i, c := range a.T.Columns {
+			args[i] = "'" + strings.ReplaceAll(c.Name, "'", "''") + "', " + prefix + "." + quote(c.Name)
+		}
+		return f + "(" + strings.Join(args, ", ") + ")"
+	}
+	for _, op := range []schema.TriggerEvent{schema.TriggerEventInsert, schema.TriggerEventUpdate, schema.TriggerEventDelete} {
+		oldRow, newRow := "NULL", "NULL"
+		if op != schema.TriggerEventInsert {
+			oldRow = row("OLD")
+		}
+		if op != schema.TriggerEventDelete {
+			newRow = row("NEW")
+		}
+		a.T.AddTriggers(&schema.Trigger{
+			Name:       a.Name("_" + strings.ToLower(string(op))),
+			ActionTime: schema.TriggerTimeAfter,
+			Events:     []schema.TriggerEvent{op},
+			For:        schema.TriggerForRow,
+			Body: fmt.Sprintf(
+				"INSERT INTO %s (%s) VALUES ('%s', %s, %s, %s)",
+				quote(a.Log.Name), Columns(quote), op, oldRow, newRow, a.Actor,
+			),
+		})
+	}
+	return nil
+}
+
+// Columns returns the quoted and comma-separated list of the columns
+// that are populated by the audit triggers.
+func Columns(quote func(string) string) string {
+	names := []string{ColumnOp, ColumnOld, ColumnNew, ColumnActor}
+	for i := range names {
+		names[i] = quote(names[i])
+	}
+	return strings.Join(names, ", ")
+}
+
+// boundName bounds the name to the given length, if it is positive.
+func boundName(name string, max int) string {
+	if max <= 0 {
+		return name
+	}
+	return sqlx.BoundName(name, max)
+}
@@ -0,0 +1,133 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package audit_test
+
+import (
+	"errors"
+	"testing"
+
+	"ariga.io/atlas/sql/audit"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	s := testSchema()
+	desired, err := audit.Generate(testDialect(0), s)
+	require.NoError(t, err)
+	require.Len(t, s.Tables, 2, "original schema is not modified")
+	users, _ := s.Table("users")
+	require.Empty(t, users.Triggers)
+
+	require.Len(t, desired.Tables, 4)
+	users, ok := desired.Table("users")
+	require.True(t, ok)
+	require.True(t, users.Schema == desired)
+	posts, ok := desired.Table("posts")
+	require.True(t, ok)
+	require.True(t, posts.ForeignKeys[0].RefTable == users, "foreign keys reference the copied tables")
+	require.True(t, posts.ForeignKeys[0].RefColumns[0] == users.Columns[0])
+	log, ok := desired.Table("users_audit")
+	require.True(t, ok)
+	require.Equal(t, []string{"id", "operation", "old_row", "new_row", "actor", "changed_at"}, names(log.Columns))
+	require.Equal(t, "now()", log.Columns[5].Default.(*schema.RawExpr).X)
+	require.Len(t, users.Triggers, 3)
+	require.Equal(t, "users_audit_insert", users.Triggers[0].Name)
+	require.True(t, users.Triggers[0].Table == users)
+	require.Equal(t, `INSERT INTO "users_audit" ("operation", "old_row", "new_row", "actor") VALUES ('INSERT', NULL, json_object('id', NEW."id", 'user''s name', NEW."user's name"), actor())`, users.Triggers[0].Body)
+	require.Equal(t, `INSERT INTO "users_audit" ("operation", "old_row", "new_row", "actor") VALUES ('DELETE', json_object('id', OLD."id", 'user''s name', OLD."user's name"), NULL, actor())`, users.Triggers[2].Body)
+	require.Equal(t, []schema.TriggerEvent{schema.TriggerEventDelete}, users.Triggers[2].Events)
+
+	desired, err = audit.Generate(testDialect(0), s, audit.WithTables("posts"), audit.WithSuffix("_log"), audit.WithActor("'app'"))
+	require.NoError(t, err)
+	require.Len(t, desired.Tables, 3)
+	_, ok = desired.Table("posts_log")
+	require.True(t, ok)
+	posts, _ = desired.Table("posts")
+	require.Len(t, posts.Triggers, 3)
+	require.Contains(t, posts.Triggers[1].Body, "'app')")
+	users, _ = desired.Table("users")
+	require.Empty(t, users.Triggers)
+}
+
+func TestGenerate_MaxName(t *testing.T) {
+	s := schema.New("public").AddTables(
+		schema.NewTable("a_very_long_table_name").AddColumns(schema.NewIntColumn("id", "int")),
+	)
+	desired, err := audit.Generate(testDialect(24), s)
+	require.NoError(t, err)
+	require.Len(t, desired.Tables, 2)
+	log := desired.Tables[1]
+	require.Len(t, log.Name, 24)
+	require.Equal(t, "a_very_long_tab_", log.Name[:16])
+	for _, tr := range desired.Tables[0].Triggers {
+		require.LessOrEqual(t, len(tr.Name), 24)
+	}
+	require.NotEqual(t, desired.Tables[0].Triggers[0].Name, desired.Tables[0].Triggers[1].Name)
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	s := testSchema()
+	_, err := audit.Generate(testDialect(0), s, audit.WithTables("unknown"))
+	require.EqualError(t, err, `audit: table "unknown" was not found in schema "public"`)
+
+	desired, err := audit.Generate(testDialect(0), s)
+	require.NoError(t, err)
+	_, err = audit.Generate(testDialect(0), desired, audit.WithTables("users"))
+	require.EqualError(t, err, `audit: table "users_audit" already exists in schema "public"`)
+
+	_, err = audit.Generate(testDialect(0), schema.New("public").AddTables(schema.NewTable("empty")))
+	require.EqualError(t, err, `audit: table "empty" has no columns`)
+
+	d := testDialect(0)
+	d.Triggers = func(*audit.Audit) error {
+		return errors.New("unsupported")
+	}
+	_, err = audit.Generate(d, s)
+	require.EqualError(t, err, "unsupported")
+}
+
+func testDialect(max int) *audit.Dialect {
+	return &audit.Dialect{
+		ID: func() *schema.Column {
+			return schema.NewIntColumn(audit.ColumnID, "bigint")
+		},
+		String:  &schema.StringType{T: "text"},
+		JSON:    &schema.JSONType{T: "json"},
+		Time:    &schema.TimeType{T: "timestamp"},
+		Now:     "now()",
+		Actor:   "actor()",
+		MaxName: max,
+		Triggers: func(a *audit.Audit) error {
+			return audit.RowTriggers(a, "json_object", func(s string) string {
+				return `"` + s + `"`
+			})
+		},
+	}
+}
+
+func testSchema() *schema.Schema {
+	users := schema.NewTable("users").AddColumns(
+		schema.NewIntColumn("id", "int"),
+		schema.NewStringColumn("user's name", "text"),
+	)
+	posts := schema.NewTable("posts").AddColumns(
+		schema.NewIntColumn("id", "int"),
+		schema.NewIntColumn("author_id", "int"),
+	)
+	posts.AddForeignKeys(
+		schema.NewForeignKey("author").AddColumns(posts.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]),
+	)
+	return schema.New("public").AddTables(users, posts)
+}
+
+func names(cs []*schema.Column) []string {
+	ns := make([]string, len(cs))
+	for i, c := range cs {
+		ns[i] = c.Name
+	}
+	return ns
+}
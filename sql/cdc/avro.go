@@ -0,0 +1,107 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cdc
+
+import (
+	"encoding/json"
+)
+
+type (
+	// avroRecord is an Avro record schema.
+	avroRecord struct {
+		Type        string       `json:"type"`
+		Name        string       `json:"name"`
+		Namespace   string       `json:"namespace"`
+		Fields      []*avroField `json:"fields"`
+		ConnectName string       `json:"connect.name"`
+	}
+
+	// avroField is a field in an Avro record.
+	avroField struct {
+		Name    string          `json:"name"`
+		Type    any             `json:"type"`
+		Default json.RawMessage `json:"default,omitempty"`
+	}
+)
+
+// avroNull is the default value of optional fields.
+var avroNull = json.RawMessage("null")
+
+// AvroSchema returns the Avro schema of the given kind. Key and Value schemas are records of
+// the primary key and table columns, and the Envelope schema is the record of the change event
+// holding the "before" and "after" states of the row, and the operation ("op") of the change.
+// Note, the "source" and "transaction" blocks of Debezium are connector-specific, and they are
+// not included. Hence, Envelope schemas are valid reader schemas of the Debezium events.
+func (t *Table) AvroSchema(k Kind) ([]byte, error) {
+	if k != Envelope {
+		r, err := t.avroRecord(k)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(r)
+	}
+	v, err := t.avroRecord(Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&avroRecord{
+		Type:      "record",
+		Name:      string(Envelope),
+		Namespace: t.Topic,
+		Fields: []*avroField{
+			{Name: "before", Type: []any{"null", v}, Default: avroNull},
+			// Named types are defined once, and referenced by their full names.
+			{Name: "after", Type: []any{"null", t.Name(Value)}, Default: avroNull},
+			{Name: "op", Type: "string"},
+			{Name: "ts_ms", Type: []any{"null", "long"}, Default: avroNull},
+		},
+		ConnectName: t.Name(Envelope),
+	})
+}
+
+func (t *Table) avroRecord(k Kind) (*avroRecord, error) {
+	fs, err := t.fields(k)
+	if err != nil {
+		return nil, err
+	}
+	r := &avroRecord{Type: "record", Name: string(k), Namespace: t.Topic, ConnectName: t.Name(k)}
+	for _, f := range fs {
+		af := &avroField{Name: avroName(f.Name), Type: f.Type.avro()}
+		if f.Optional {
+			af.Type, af.Default = []any{"null", af.Type}, avroNull
+		}
+		r.Fields = append(r.Fields, af)
+	}
+	return r, nil
+}
+
+// avro returns the Avro schema of the type. Types without
+// logical or semantic types are returned as primitive names.
+func (t *Type) avro() any {
+	if t.Logical == "" && t.Connect == "" && t.ConnectInt == "" {
+		return t.Avro
+	}
+	s := map[string]any{"type": t.Avro}
+	if t.Logical != "" {
+		s["logicalType"] = t.Logical
+		if t.Logical == "decimal" {
+			s["precision"], s["scale"] = t.Precision, t.Scale
+		}
+	}
+	if t.Connect != "" {
+		s["connect.name"] = t.Connect
+		// Debezium semantic types are versioned.
+		if t.Connect != connectDecimal {
+			s["connect.version"] = 1
+		}
+	}
+	if t.ConnectInt != "" {
+		s["connect.type"] = t.ConnectInt
+	}
+	if len(t.ConnectArgs) > 0 {
+		s["connect.parameters"] = t.ConnectArgs
+	}
+	return s
+}
@@ -0,0 +1,331 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package cdc generates the schemas of change-data-capture (CDC) events from database tables, so
+// CDC consumers can be generated from the same source of truth as the database. Schemas follow the
+// naming conventions and the type mappings of Debezium: the events of table "users" in schema
+// "public" are published to topic "<prefix>.public.users", and their keys, rows and envelopes are
+// named "<prefix>.public.users.Key", "<prefix>.public.users.Value" and "<prefix>.public.users.Envelope".
+// Schemas are generated either as Avro schemas, or as JSON Schemas. For example:
+//
+//	for _, t := range cdc.Tables(realm, "inventory") {
+//		value, err := t.AvroSchema(cdc.Envelope)
+//		if err != nil {
+//			return err
+//		}
+//		// Register the schema under t.Subject(cdc.Value).
+//	}
+package cdc
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Kind describes the kind of the generated schema.
+type Kind string
+
+// List of schema kinds. Kinds are used as the names of the generated records.
+const (
+	Key      Kind = "Key"      // Primary key of the changed row.
+	Value    Kind = "Value"    // State of the row.
+	Envelope Kind = "Envelope" // Change event, holding the row state before and after the change.
+)
+
+type (
+	// Table describes the change events of a database table.
+	Table struct {
+		// Topic is the name of the topic the events of the table are published to.
+		// e.g. "<prefix>.<schema>.<table>". Segments are adjusted to valid Avro names.
+		Topic string
+		// Keys and Fields hold the fields of the primary key
+		// and the table columns, in their table order.
+		Keys, Fields []*Field
+		T            *schema.Table
+	}
+
+	// Field is a column in the events of the table.
+	Field struct {
+		Name     string
+		Optional bool // Nullable column.
+		Type     *Type
+		C        *schema.Column
+	}
+
+	// Type is the CDC type of a column, as mapped by Debezium.
+	Type struct {
+		// Avro primitive type of the column. e.g. "long" or "bytes".
+		Avro string
+		// Logical Avro type, and the parameters of decimal types.
+		Logical          string
+		Precision, Scale int
+		// Connect holds the Kafka Connect semantic type (e.g. io.debezium.time.Date),
+		// or the Connect integer type for integers that are not mapped to Avro as-is.
+		Connect     string
+		ConnectInt  string
+		ConnectArgs map[string]string
+		// JSON holds the JSON Schema of the column values, as encoded by
+		// the Debezium JSON converter. e.g. dates are encoded as integers.
+		JSON map[string]any
+	}
+
+	// Option configures the generation of the CDC tables.
+	Option func(*options)
+
+	options struct {
+		dialect string // Name of the captured database driver.
+	}
+)
+
+// WithDialect sets the name of the driver of the captured database, e.g. "mysql" or "postgres".
+// Debezium connectors map some types differently, e.g. TIMESTAMP columns are captured as zoned
+// timestamps by the MySQL connector, and as local timestamps by the PostgreSQL connector, which
+// is the default.
+func WithDialect(name string) Option {
+	return func(o *options) {
+		o.dialect = strings.ToLower(name)
+	}
+}
+
+func (o *options) mysql() bool {
+	return o.dialect == "mysql"
+}
+
+// Tables returns the CDC tables of the realm. The topic prefix identifies the captured
+// database server, and is used as the first segment of the topic and schema names.
+func Tables(r *schema.Realm, prefix string, opts ...Option) []*Table {
+	var ts []*Table
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			ts = append(ts, NewTable(prefix, s.Name, t, opts...))
+		}
+	}
+	return ts
+}
+
+// NewTable returns the CDC table of the given table. The schema name can be
+// empty, for databases that do not qualify their tables (e.g. SQLite).
+func NewTable(prefix, schemaName string, t *schema.Table, opts ...Option) *Table {
+	var (
+		parts []string
+		tt    = &Table{T: t}
+		o     = &options{}
+	)
+	for _, opt := range opts {
+		opt(o)
+	}
+	for _, p := range []string{prefix, schemaName, t.Name} {
+		if p != "" {
+			parts = append(parts, avroName(p))
+		}
+	}
+	tt.Topic = strings.Join(parts, ".")
+	for _, c := range t.Columns {
+		tt.Fields = append(tt.Fields, newField(c, o))
+	}
+	if t.PrimaryKey != nil {
+		for _, p := range t.PrimaryKey.Parts {
+			// Expressions are not captured by the keys.
+			if p.C != nil {
+				tt.Keys = append(tt.Keys, newField(p.C, o))
+			}
+		}
+	}
+	return tt
+}
+
+// Subject returns the schema registry subject of the given kind, using the TopicNameStrategy
+// of the Confluent Schema Registry. Note, Envelope schemas are registered under Value subjects.
+func (t *Table) Subject(k Kind) string {
+	if k == Key {
+		return t.Topic + "-key"
+	}
+	return t.Topic + "-value"
+}
+
+// Name returns the full name of the schema of the given kind.
+// e.g. "<prefix>.<schema>.<table>.Value".
+func (t *Table) Name(k Kind) string {
+	return t.Topic + "." + string(k)
+}
+
+// fields returns the fields of the given kind.
+func (t *Table) fields(k Kind) ([]*Field, error) {
+	switch k {
+	case Key:
+		if len(t.Keys) == 0 {
+			return nil, fmt.Errorf("cdc: table %q has no primary key", t.T.Name)
+		}
+		return t.Keys, nil
+	case Value:
+		return t.Fields, nil
+	default:
+		return nil, fmt.Errorf("cdc: unexpected schema kind %q", k)
+	}
+}
+
+func newField(c *schema.Column, o *options) *Field {
+	f := &Field{Name: c.Name, C: c, Type: typeOf(c, o)}
+	if c.Type != nil {
+		f.Optional = c.Type.Null
+	}
+	return f
+}
+
+// Debezium semantic types.
+const (
+	connectDecimal        = "org.apache.kafka.connect.data.Decimal"
+	connectDate           = "io.debezium.time.Date"
+	connectTime           = "io.debezium.time.Time"
+	connectMicroTime      = "io.debezium.time.MicroTime"
+	connectTimestamp      = "io.debezium.time.Timestamp"
+	connectMicroTimestamp = "io.debezium.time.MicroTimestamp"
+	connectZonedTime      = "io.debezium.time.ZonedTime"
+	connectZonedTimestamp = "io.debezium.time.ZonedTimestamp"
+	connectYear           = "io.debezium.time.Year"
+	connectEnum           = "io.debezium.data.Enum"
+	connectJSON           = "io.debezium.data.Json"
+	connectUUID           = "io.debezium.data.Uuid"
+)
+
+// typeOf returns the CDC type of the column, following the default Debezium configuration
+// (e.g. decimal.handling.mode=precise, and time.precision.mode=adaptive, or
+// adaptive_time_microseconds in MySQL).
+func typeOf(c *schema.Column, o *options) *Type {
+	var t schema.Type
+	if c.Type != nil {
+		t = c.Type.Type
+	}
+	switch t := t.(type) {
+	case *schema.BoolType:
+		return &Type{Avro: "boolean", JSON: jsonType("boolean")}
+	case *schema.IntegerType:
+		switch typ := strings.ToLower(t.T); {
+		case typ == "bigint" || typ == "int8" || typ == "bigserial" || typ == "serial8":
+			return &Type{Avro: "long", JSON: jsonType("integer")}
+		case typ == "tinyint" && !t.Unsigned:
+			return &Type{Avro: "int", ConnectInt: "int8", JSON: jsonType("integer")}
+		// Unsigned integers are widened to fit their values.
+		case typ == "tinyint", (typ == "smallint" || typ == "int2" || typ == "smallserial") && !t.Unsigned:
+			return &Type{Avro: "int", ConnectInt: "int16", JSON: jsonType("integer")}
+		case (typ == "int" || typ == "integer" || typ == "int4") && t.Unsigned:
+			return &Type{Avro: "long", JSON: jsonType("integer")}
+		default:
+			return &Type{Avro: "int", JSON: jsonType("integer")}
+		}
+	case *schema.DecimalType:
+		// Decimals without precision (e.g. NUMERIC in PostgreSQL) are
+		// captured as strings, like in decimal.handling.mode=string.
+		if t.Precision == 0 {
+			return &Type{Avro: "string", JSON: jsonType("string")}
+		}
+		return &Type{
+			Avro:      "bytes",
+			Logical:   "decimal",
+			Precision: t.Precision,
+			Scale:     t.Scale,
+			Connect:   connectDecimal,
+			ConnectArgs: map[string]string{
+				"scale":                     fmt.Sprint(t.Scale),
+				"connect.decimal.precision": fmt.Sprint(t.Precision),
+			},
+			// Decimal bytes are encoded as base64 strings.
+			JSON: map[string]any{"type": "string", "contentEncoding": "base64"},
+		}
+	case *schema.FloatType:
+		switch strings.ToLower(t.T) {
+		case "real", "float4":
+			return &Type{Avro: "float", JSON: jsonType("number")}
+		case "float":
+			if t.Precision <= 24 {
+				return &Type{Avro: "float", JSON: jsonType("number")}
+			}
+		}
+		return &Type{Avro: "double", JSON: jsonType("number")}
+	case *schema.EnumType:
+		vs := make([]any, len(t.Values))
+		for i := range t.Values {
+			vs[i] = t.Values[i]
+		}
+		return &Type{
+			Avro:        "string",
+			Connect:     connectEnum,
+			ConnectArgs: map[string]string{"allowed": strings.Join(t.Values, ",")},
+			JSON:        map[string]any{"type": "string", "enum": vs},
+		}
+	case *schema.TimeType:
+		// Fractional seconds precision of the column. Defaults to 0 in MySQL and to 6 in PostgreSQL.
+		p := 6
+		if o.mysql() {
+			p = 0
+		}
+		if t.Precision != nil {
+			p = *t.Precision
+		}
+		switch typ := strings.ToLower(t.T); {
+		case typ == "date":
+			// Number of days since the epoch.
+			return &Type{Avro: "int", Connect: connectDate, JSON: jsonType("integer")}
+		case typ == "year":
+			return &Type{Avro: "int", Connect: connectYear, JSON: jsonType("integer")}
+		case typ == "timetz" || typ == "time with time zone":
+			return &Type{Avro: "string", Connect: connectZonedTime, JSON: jsonType("string")}
+		// MySQL TIMESTAMP values are converted to UTC, and captured as ISO-8601 strings.
+		case typ == "timestamptz" || typ == "timestamp with time zone", typ == "timestamp" && o.mysql():
+			return &Type{Avro: "string", Connect: connectZonedTimestamp, JSON: map[string]any{"type": "string", "format": "date-time"}}
+		// TIME columns are captured in microseconds by the MySQL connector, regardless of their precision.
+		case strings.HasPrefix(typ, "time") && !strings.HasPrefix(typ, "timestamp") && p <= 3 && !o.mysql():
+			// Number of milliseconds since midnight.
+			return &Type{Avro: "int", Connect: connectTime, JSON: jsonType("integer")}
+		case strings.HasPrefix(typ, "time") && !strings.HasPrefix(typ, "timestamp"):
+			// Number of microseconds since midnight.
+			return &Type{Avro: "long", Connect: connectMicroTime, JSON: jsonType("integer")}
+		case p <= 3:
+			// Number of milliseconds since the epoch.
+			return &Type{Avro: "long", Connect: connectTimestamp, JSON: jsonType("integer")}
+		default:
+			// Number of microseconds since the epoch.
+			return &Type{Avro: "long", Connect: connectMicroTimestamp, JSON: jsonType("integer")}
+		}
+	case *schema.JSONType:
+		return &Type{Avro: "string", Connect: connectJSON, JSON: jsonType("string")}
+	case *schema.UUIDType:
+		return &Type{Avro: "string", Connect: connectUUID, JSON: map[string]any{"type": "string", "format": "uuid"}}
+	case *schema.BinaryType:
+		return &Type{Avro: "bytes", JSON: map[string]any{"type": "string", "contentEncoding": "base64"}}
+	// Strings, and types that are not known to this package, are captured as strings.
+	default:
+		return &Type{Avro: "string", JSON: jsonType("string")}
+	}
+}
+
+func jsonType(t string) map[string]any {
+	return map[string]any{"type": t}
+}
+
+// avroName returns a valid Avro name for the given identifier by replacing invalid characters with
+// underscores, and prefixing names that start with a digit. Similar to the Debezium "avro" adjustment.
+func avroName(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_' || r < unicode.MaxASCII && unicode.IsLetter(r):
+			b.WriteRune(r)
+		case r < unicode.MaxASCII && unicode.IsDigit(r):
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
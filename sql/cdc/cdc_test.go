@@ -0,0 +1,167 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cdc_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/cdc"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTables(t *testing.T) {
+	ts := cdc.Tables(testRealm(), "inventory")
+	require.Len(t, ts, 2)
+	require.Equal(t, "inventory.public.users", ts[0].Topic)
+	require.Equal(t, "inventory.public.users-key", ts[0].Subject(cdc.Key))
+	require.Equal(t, "inventory.public.users-value", ts[0].Subject(cdc.Envelope))
+	require.Equal(t, "inventory.public.users.Value", ts[0].Name(cdc.Value))
+	require.Equal(t, "inventory.public.audit_log", ts[1].Topic, "names are adjusted")
+
+	// Tables without schemas.
+	tt := cdc.NewTable("app", "", schema.NewTable("users"))
+	require.Equal(t, "app.users", tt.Topic)
+}
+
+func TestTable_AvroSchema(t *testing.T) {
+	users := cdc.Tables(testRealm(), "inventory")[0]
+	b, err := users.AvroSchema(cdc.Key)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "type": "record",
+  "name": "Key",
+  "namespace": "inventory.public.users",
+  "fields": [{"name": "id", "type": "long"}],
+  "connect.name": "inventory.public.users.Key"
+}`, string(b))
+
+	b, err = users.AvroSchema(cdc.Value)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "type": "record",
+  "name": "Value",
+  "namespace": "inventory.public.users",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "name", "type": ["null", "string"], "default": null},
+    {"name": "age", "type": {"type": "int", "connect.type": "int16"}},
+    {"name": "balance", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2, "connect.name": "org.apache.kafka.connect.data.Decimal", "connect.parameters": {"scale": "2", "connect.decimal.precision": "10"}}},
+    {"name": "status", "type": ["null", {"type": "string", "connect.name": "io.debezium.data.Enum", "connect.version": 1, "connect.parameters": {"allowed": "active,banned"}}], "default": null},
+    {"name": "born", "type": {"type": "int", "connect.name": "io.debezium.time.Date", "connect.version": 1}},
+    {"name": "created_at", "type": {"type": "string", "connect.name": "io.debezium.time.ZonedTimestamp", "connect.version": 1}},
+    {"name": "updated_at", "type": {"type": "long", "connect.name": "io.debezium.time.MicroTimestamp", "connect.version": 1}},
+    {"name": "_2fa", "type": "boolean"}
+  ],
+  "connect.name": "inventory.public.users.Value"
+}`, string(b))
+
+	b, err = users.AvroSchema(cdc.Envelope)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `{"name":"after","type":["null","inventory.public.users.Value"],"default":null}`)
+	require.Contains(t, string(b), `{"name":"op","type":"string"}`)
+	require.Contains(t, string(b), `"connect.name":"inventory.public.users.Envelope"`)
+
+	// Tables without primary keys have no keys.
+	_, err = cdc.Tables(testRealm(), "inventory")[1].AvroSchema(cdc.Key)
+	require.EqualError(t, err, `cdc: table "audit-log" has no primary key`)
+}
+
+func TestTable_JSONSchema(t *testing.T) {
+	users := cdc.Tables(testRealm(), "inventory")[0]
+	b, err := users.JSONSchema(cdc.Value)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "inventory.public.users.Value",
+  "type": "object",
+  "properties": {
+    "id": {"type": "integer"},
+    "name": {"type": ["string", "null"]},
+    "age": {"type": "integer"},
+    "balance": {"type": "string", "contentEncoding": "base64"},
+    "status": {"type": ["string", "null"], "enum": ["active", "banned", null]},
+    "born": {"type": "integer"},
+    "created_at": {"type": "string", "format": "date-time"},
+    "updated_at": {"type": "integer"},
+    "2fa": {"type": "boolean"}
+  },
+  "required": ["id", "age", "balance", "born", "created_at", "updated_at", "2fa"],
+  "additionalProperties": false
+}`, string(b))
+
+	b, err = users.JSONSchema(cdc.Envelope)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"before":{"anyOf":[{"$ref":"#/$defs/Value"},{"type":"null"}]}`)
+	require.Contains(t, string(b), `"$defs":{"Value":{`)
+	require.Contains(t, string(b), `"required":["op"]`)
+
+	// The shared enum values are not modified.
+	b, err = users.JSONSchema(cdc.Value)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"enum":["active","banned",null]`)
+}
+
+// TestTypes follows the temporal type mappings in the documentation of the Debezium
+// connectors, using the default time.precision.mode of each connector.
+func TestTypes(t *testing.T) {
+	for _, tt := range []struct {
+		dialect       string
+		column        *schema.Column
+		avro, connect string
+	}{
+		// PostgreSQL (time.precision.mode=adaptive).
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "date"), avro: "int", connect: "io.debezium.time.Date"},
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "time", schema.TimePrecision(3)), avro: "int", connect: "io.debezium.time.Time"},
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "time", schema.TimePrecision(6)), avro: "long", connect: "io.debezium.time.MicroTime"},
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "time"), avro: "long", connect: "io.debezium.time.MicroTime"},
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "timetz"), avro: "string", connect: "io.debezium.time.ZonedTime"},
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "timestamp", schema.TimePrecision(0)), avro: "long", connect: "io.debezium.time.Timestamp"},
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "timestamp", schema.TimePrecision(3)), avro: "long", connect: "io.debezium.time.Timestamp"},
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "timestamp", schema.TimePrecision(6)), avro: "long", connect: "io.debezium.time.MicroTimestamp"},
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "timestamp without time zone"), avro: "long", connect: "io.debezium.time.MicroTimestamp"},
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "timestamptz"), avro: "string", connect: "io.debezium.time.ZonedTimestamp"},
+		{dialect: "postgres", column: schema.NewTimeColumn("c", "timestamp with time zone", schema.TimePrecision(3)), avro: "string", connect: "io.debezium.time.ZonedTimestamp"},
+		// MySQL (time.precision.mode=adaptive_time_microseconds).
+		{dialect: "mysql", column: schema.NewTimeColumn("c", "date"), avro: "int", connect: "io.debezium.time.Date"},
+		{dialect: "mysql", column: schema.NewTimeColumn("c", "year"), avro: "int", connect: "io.debezium.time.Year"},
+		{dialect: "mysql", column: schema.NewTimeColumn("c", "time"), avro: "long", connect: "io.debezium.time.MicroTime"},
+		{dialect: "mysql", column: schema.NewTimeColumn("c", "time", schema.TimePrecision(3)), avro: "long", connect: "io.debezium.time.MicroTime"},
+		{dialect: "mysql", column: schema.NewTimeColumn("c", "datetime"), avro: "long", connect: "io.debezium.time.Timestamp"},
+		{dialect: "mysql", column: schema.NewTimeColumn("c", "datetime", schema.TimePrecision(3)), avro: "long", connect: "io.debezium.time.Timestamp"},
+		{dialect: "mysql", column: schema.NewTimeColumn("c", "datetime", schema.TimePrecision(6)), avro: "long", connect: "io.debezium.time.MicroTimestamp"},
+		{dialect: "mysql", column: schema.NewTimeColumn("c", "timestamp"), avro: "string", connect: "io.debezium.time.ZonedTimestamp"},
+		{dialect: "mysql", column: schema.NewTimeColumn("c", "timestamp", schema.TimePrecision(6)), avro: "string", connect: "io.debezium.time.ZonedTimestamp"},
+		// PostgreSQL is the default.
+		{column: schema.NewTimeColumn("c", "timestamp"), avro: "long", connect: "io.debezium.time.MicroTimestamp"},
+	} {
+		t.Run(tt.dialect+"/"+tt.column.Type.Type.(*schema.TimeType).T, func(t *testing.T) {
+			tbl := cdc.NewTable("app", "", schema.NewTable("t").AddColumns(tt.column), cdc.WithDialect(tt.dialect))
+			require.Equal(t, tt.avro, tbl.Fields[0].Type.Avro)
+			require.Equal(t, tt.connect, tbl.Fields[0].Type.Connect)
+		})
+	}
+}
+
+func testRealm() *schema.Realm {
+	id := schema.NewIntColumn("id", "bigint")
+	users := schema.NewTable("users").
+		AddColumns(
+			id,
+			schema.NewNullStringColumn("name", "text"),
+			schema.NewIntColumn("age", "smallint"),
+			schema.NewDecimalColumn("balance", "decimal", schema.DecimalPrecision(10), schema.DecimalScale(2)),
+			schema.NewNullEnumColumn("status", schema.EnumValues("active", "banned")),
+			schema.NewTimeColumn("born", "date"),
+			schema.NewTimeColumn("created_at", "timestamptz"),
+			schema.NewTimeColumn("updated_at", "timestamp"),
+			schema.NewBoolColumn("2fa", "boolean"),
+		).
+		SetPrimaryKey(schema.NewPrimaryKey(id))
+	return schema.NewRealm(
+		schema.New("public").AddTables(users, schema.NewTable("audit-log").AddColumns(schema.NewStringColumn("entry", "text"))),
+	)
+}
@@ -0,0 +1,84 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package cdc
+
+import (
+	"encoding/json"
+)
+
+// jsonSchemaDialect is the JSON Schema version of the generated schemas.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns the JSON Schema of the given kind, describing the payloads of the Debezium JSON
+// converter (without embedded schemas). Like Avro schemas, Envelope schemas describe the "before",
+// "after" and "op" fields of the change events, and the Value schema is defined under "$defs".
+func (t *Table) JSONSchema(k Kind) ([]byte, error) {
+	if k != Envelope {
+		s, err := t.jsonObject(k)
+		if err != nil {
+			return nil, err
+		}
+		s["$schema"] = jsonSchemaDialect
+		return json.Marshal(s)
+	}
+	v, err := t.jsonObject(Value)
+	if err != nil {
+		return nil, err
+	}
+	state := map[string]any{
+		"anyOf": []any{
+			map[string]any{"$ref": "#/$defs/" + string(Value)},
+			map[string]any{"type": "null"},
+		},
+	}
+	return json.Marshal(map[string]any{
+		"$schema": jsonSchemaDialect,
+		"title":   t.Name(Envelope),
+		"type":    "object",
+		"$defs":   map[string]any{string(Value): v},
+		"properties": map[string]any{
+			"before": state,
+			"after":  state,
+			// Create, update, delete, read (snapshot) and truncate.
+			"op":    map[string]any{"type": "string", "enum": []string{"c", "u", "d", "r", "t"}},
+			"ts_ms": map[string]any{"type": []string{"integer", "null"}},
+		},
+		"required": []string{"op"},
+	})
+}
+
+// jsonObject returns the JSON Schema object of the given kind.
+func (t *Table) jsonObject(k Kind) (map[string]any, error) {
+	fs, err := t.fields(k)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		props    = make(map[string]any, len(fs))
+		required = make([]string, 0, len(fs))
+	)
+	for _, f := range fs {
+		p := make(map[string]any, len(f.Type.JSON))
+		for k, v := range f.Type.JSON {
+			p[k] = v
+		}
+		if f.Optional {
+			p["type"] = []any{p["type"], "null"}
+			if e, ok := p["enum"].([]any); ok {
+				p["enum"] = append(e[:len(e):len(e)], nil)
+			}
+		} else {
+			required = append(required, f.Name)
+		}
+		props[f.Name] = p
+	}
+	return map[string]any{
+		"title":                t.Name(k),
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
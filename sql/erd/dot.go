@@ -0,0 +1,54 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package erd
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Dot writes the diagram of the realm as a graphviz (dot) document. Schemas are rendered
+// as clusters, and tables as HTML-like labels with a port for each column. For example:
+//
+//	atlas schema inspect -u "..." --format '{{ dot . }}' | dot -Tpng > schema.png
+func Dot(w io.Writer, r *schema.Realm) error {
+	var (
+		g = newGraph(r)
+		b = bufio.NewWriter(w)
+	)
+	b.WriteString("digraph erd {\n")
+	b.WriteString("  graph [rankdir=LR, fontname=\"Helvetica\"];\n")
+	b.WriteString("  node [shape=plaintext, fontname=\"Helvetica\"];\n")
+	// Relationships are drawn from the referencing (many) side to the referenced (one) side.
+	b.WriteString("  edge [dir=both, arrowhead=tee, arrowtail=crow];\n")
+	for i, cl := range g.clusters {
+		fmt.Fprintf(b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(b, "    label=%s;\n", strconv.Quote(cl.name))
+		for _, e := range cl.entities {
+			fmt.Fprintf(b, "    %s [label=<%s>];\n", strconv.Quote(e.id), dotLabel(e))
+		}
+		b.WriteString("  }\n")
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(b, "  %s:%s -> %s:%s;\n", strconv.Quote(e.from.id), strconv.Quote(e.fromRow.name), strconv.Quote(e.to.id), strconv.Quote(e.toRow.name))
+	}
+	b.WriteString("}\n")
+	return b.Flush()
+}
+
+// dotLabel returns the HTML-like label of the entity.
+func dotLabel(e *entity) string {
+	l := `<TABLE BORDER="0" CELLBORDER="1" CELLSPACING="0" CELLPADDING="4">`
+	l += fmt.Sprintf(`<TR><TD BGCOLOR="#E1E4E8"><B>%s</B></TD></TR>`, html.EscapeString(e.name))
+	for _, r := range e.rows {
+		l += fmt.Sprintf(`<TR><TD PORT="%s" ALIGN="LEFT">%s</TD></TR>`, html.EscapeString(r.name), html.EscapeString(r.label()))
+	}
+	return l + "</TABLE>"
+}
@@ -0,0 +1,169 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package erd renders entity-relationship diagrams of schemas. Tables are clustered by their
+// schemas, and relationships are drawn from the foreign-key columns to the referenced columns.
+// Diagrams are rendered either as SVG images that are laid out by this package, or as graphviz
+// (dot) documents for rendering with external tools. For example:
+//
+//	var b bytes.Buffer
+//	if err := erd.SVG(&b, realm); err != nil {
+//		return err
+//	}
+//	err := os.WriteFile("schema.svg", b.Bytes(), 0644)
+package erd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Column markers rendered next to the column types.
+const (
+	markPK = "PK"
+	markFK = "FK"
+)
+
+type (
+	// entity is a table in the diagram.
+	entity struct {
+		id     string // Qualified name of the table.
+		t      *schema.Table
+		name   string
+		rows   []*row
+		x, y   int // Position of the top-left corner. Used only by SVG.
+		width  int
+		height int
+	}
+
+	// row is a column in the table entity.
+	row struct {
+		c      *schema.Column
+		name   string
+		typ    string
+		marks  []string
+		offset int // Vertical offset of the row in its entity. Used only by SVG.
+	}
+
+	// edge is a relationship between two table columns.
+	edge struct {
+		from, to       *entity
+		fromRow, toRow *row
+	}
+
+	// cluster groups the entities of a schema.
+	cluster struct {
+		name     string
+		entities []*entity
+		x, y     int
+		width    int
+		height   int
+	}
+
+	// graph holds the entities and relationships of the realm.
+	graph struct {
+		clusters []*cluster
+		edges    []*edge
+	}
+)
+
+// newGraph builds the diagram graph of the given realm.
+func newGraph(r *schema.Realm) *graph {
+	var (
+		g        = &graph{}
+		entities = make(map[*schema.Table]*entity)
+	)
+	for _, s := range r.Schemas {
+		cl := &cluster{name: s.Name}
+		for _, t := range s.Tables {
+			e := &entity{id: qualified(s.Name, t.Name), t: t, name: t.Name}
+			for _, c := range t.Columns {
+				rw := &row{c: c, name: c.Name, typ: typeName(c)}
+				if t.PrimaryKey != nil && partOf(t.PrimaryKey, c) {
+					rw.marks = append(rw.marks, markPK)
+				}
+				if len(c.ForeignKeys) > 0 {
+					rw.marks = append(rw.marks, markFK)
+				}
+				e.rows = append(e.rows, rw)
+			}
+			entities[t] = e
+			cl.entities = append(cl.entities, e)
+		}
+		g.clusters = append(g.clusters, cl)
+	}
+	for _, cl := range g.clusters {
+		for _, from := range cl.entities {
+			for _, fk := range from.t.ForeignKeys {
+				to, ok := entities[fk.RefTable]
+				// Tables that were not inspected are not rendered.
+				if !ok {
+					continue
+				}
+				for i, c := range fk.Columns {
+					if i >= len(fk.RefColumns) {
+						break
+					}
+					fr, tr := from.row(c), to.row(fk.RefColumns[i])
+					if fr == nil || tr == nil {
+						continue
+					}
+					g.edges = append(g.edges, &edge{from: from, to: to, fromRow: fr, toRow: tr})
+				}
+			}
+		}
+	}
+	return g
+}
+
+// row returns the row of the given column, or nil if it does not exist.
+func (e *entity) row(c *schema.Column) *row {
+	for _, r := range e.rows {
+		if r.c == c {
+			return r
+		}
+	}
+	return nil
+}
+
+// label returns the label of the row, e.g. "id int PK".
+func (r *row) label() string {
+	return strings.Join(append([]string{r.name, r.typ}, r.marks...), " ")
+}
+
+// typeName returns the string representation of the column type.
+func typeName(c *schema.Column) string {
+	if c.Type == nil || c.Type.Type == nil {
+		return "unknown"
+	}
+	if c.Type.Raw != "" {
+		return c.Type.Raw
+	}
+	rv := reflect.Indirect(reflect.ValueOf(c.Type.Type))
+	if rv.Kind() == reflect.Struct {
+		if t := rv.FieldByName("T"); t.IsValid() && t.Kind() == reflect.String && t.String() != "" {
+			return t.String()
+		}
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", c.Type.Type), "*")
+}
+
+func partOf(idx *schema.Index, c *schema.Column) bool {
+	for _, p := range idx.Parts {
+		if p.C == c {
+			return true
+		}
+	}
+	return false
+}
+
+func qualified(s, name string) string {
+	if s == "" {
+		return name
+	}
+	return s + "." + name
+}
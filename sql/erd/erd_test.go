@@ -0,0 +1,88 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package erd_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/erd"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDot(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, erd.Dot(&b, testRealm()))
+	require.Equal(t, `digraph erd {
+  graph [rankdir=LR, fontname="Helvetica"];
+  node [shape=plaintext, fontname="Helvetica"];
+  edge [dir=both, arrowhead=tee, arrowtail=crow];
+  subgraph cluster_0 {
+    label="public";
+    "public.users" [label=<<TABLE BORDER="0" CELLBORDER="1" CELLSPACING="0" CELLPADDING="4"><TR><TD BGCOLOR="#E1E4E8"><B>users</B></TD></TR><TR><TD PORT="id" ALIGN="LEFT">id int PK</TD></TR><TR><TD PORT="manager_id" ALIGN="LEFT">manager_id int FK</TD></TR></TABLE>>];
+  }
+  subgraph cluster_1 {
+    label="sales";
+    "sales.orders" [label=<<TABLE BORDER="0" CELLBORDER="1" CELLSPACING="0" CELLPADDING="4"><TR><TD BGCOLOR="#E1E4E8"><B>orders</B></TD></TR><TR><TD PORT="id" ALIGN="LEFT">id bigint PK</TD></TR><TR><TD PORT="user_id" ALIGN="LEFT">user_id int FK</TD></TR><TR><TD PORT="note" ALIGN="LEFT">note varchar(&lt;255&gt;)</TD></TR></TABLE>>];
+  }
+  "public.users":"manager_id" -> "public.users":"id";
+  "sales.orders":"user_id" -> "public.users":"id";
+}
+`, b.String())
+}
+
+func TestSVG(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, erd.SVG(&b, testRealm()))
+	// The output is a well-formed XML document.
+	dec := xml.NewDecoder(strings.NewReader(b.String()))
+	for {
+		_, err := dec.Token()
+		if err != nil {
+			require.Equal(t, "EOF", err.Error())
+			break
+		}
+	}
+	out := b.String()
+	require.True(t, strings.HasPrefix(out, `<svg xmlns="http://www.w3.org/2000/svg"`))
+	require.Contains(t, out, `<text x="40" y="44" font-weight="bold">public</text>`)
+	require.Contains(t, out, `<g class="table" id="public.users">`)
+	require.Contains(t, out, `<text x="48" y="118">manager_id int FK</text>`)
+	require.Contains(t, out, `<text x="48" y="282">note varchar(&lt;255&gt;)</text>`)
+	require.Equal(t, 2, strings.Count(out, `marker-end="url(#erd-arrow)"`), "one path for each relationship")
+	// Self references loop on the right side of the table.
+	require.Contains(t, out, `<path d="M175,114 C215,114 215,94 175,94"`)
+	require.Contains(t, out, `<title>public.users.manager_id -&gt; public.users.id</title>`)
+	require.Contains(t, out, `<title>sales.orders.user_id -&gt; public.users.id</title>`)
+
+	// Empty realms are rendered as empty images.
+	b.Reset()
+	require.NoError(t, erd.SVG(&b, schema.NewRealm()))
+	require.Contains(t, b.String(), `width="40" height="20"`)
+}
+
+func testRealm() *schema.Realm {
+	var (
+		uid   = schema.NewIntColumn("id", "int")
+		mid   = schema.NewNullIntColumn("manager_id", "int")
+		users = schema.NewTable("users").AddColumns(uid, mid)
+		oid   = schema.NewIntColumn("id", "bigint")
+		ouid  = schema.NewIntColumn("user_id", "int")
+		note  = schema.NewNullStringColumn("note", "varchar")
+	)
+	note.Type.Raw = "varchar(<255>)"
+	users.SetPrimaryKey(schema.NewPrimaryKey(uid))
+	users.AddForeignKeys(schema.NewForeignKey("manager").AddColumns(mid).SetRefTable(users).AddRefColumns(uid))
+	orders := schema.NewTable("orders").AddColumns(oid, ouid, note).SetPrimaryKey(schema.NewPrimaryKey(oid))
+	orders.AddForeignKeys(schema.NewForeignKey("owner").AddColumns(ouid).SetRefTable(users).AddRefColumns(uid))
+	return schema.NewRealm(
+		schema.New("public").AddTables(users),
+		schema.New("sales").AddTables(orders),
+	)
+}
@@ -0,0 +1,147 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package erd
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Layout dimensions, in pixels. Text widths are approximated
+// by the width of a character in a 12px monospace font.
+const (
+	charWidth    = 7
+	rowHeight    = 20
+	textPadding  = 8
+	entityGap    = 60
+	clusterPad   = 20
+	clusterLabel = 24
+	margin       = 20
+	edgeCurve    = 40
+)
+
+// SVG writes the diagram of the realm as a self-contained SVG image. Schemas are rendered as
+// clusters stacked vertically, and the tables of each schema are laid out in a grid. For example:
+//
+//	atlas schema inspect -u "..." --format '{{ svg . }}' > schema.svg
+func SVG(w io.Writer, r *schema.Realm) error {
+	var (
+		g    = newGraph(r)
+		b    = bufio.NewWriter(w)
+		x, y = g.layout()
+	)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="12">`+"\n", x, y, x, y)
+	b.WriteString(`<defs><marker id="erd-arrow" markerWidth="10" markerHeight="10" refX="9" refY="5" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="#57606a"/></marker></defs>` + "\n")
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", x, y)
+	for _, cl := range g.clusters {
+		fmt.Fprintf(b, `<g class="schema"><rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="#f6f8fa" stroke="#d0d7de"/>`, cl.x, cl.y, cl.width, cl.height)
+		fmt.Fprintf(b, `<text x="%d" y="%d" font-weight="bold">%s</text>`+"\n", cl.x+clusterPad, cl.y+clusterLabel, html.EscapeString(cl.name))
+		for _, e := range cl.entities {
+			fmt.Fprintf(b, `<g class="table" id="%s">`, html.EscapeString(e.id))
+			fmt.Fprintf(b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#ffffff" stroke="#57606a"/>`, e.x, e.y, e.width, e.height)
+			fmt.Fprintf(b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#e1e4e8" stroke="#57606a"/>`, e.x, e.y, e.width, rowHeight)
+			fmt.Fprintf(b, `<text x="%d" y="%d" font-weight="bold">%s</text>`, e.x+textPadding, e.y+rowHeight-6, html.EscapeString(e.name))
+			for _, r := range e.rows {
+				fmt.Fprintf(b, `<text x="%d" y="%d">%s</text>`, e.x+textPadding, e.y+r.offset+rowHeight-6, html.EscapeString(r.label()))
+			}
+			b.WriteString("</g>\n")
+		}
+		b.WriteString("</g>\n")
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(b, `<path d="%s" fill="none" stroke="#57606a" marker-end="url(#erd-arrow)"><title>%s</title></path>`+"\n",
+			e.path(), html.EscapeString(fmt.Sprintf("%s.%s -> %s.%s", e.from.id, e.fromRow.name, e.to.id, e.toRow.name)))
+	}
+	b.WriteString("</svg>\n")
+	return b.Flush()
+}
+
+// layout computes the sizes and positions of the clusters and
+// their entities, and returns the dimensions of the diagram.
+func (g *graph) layout() (width, height int) {
+	y := margin
+	for _, cl := range g.clusters {
+		cl.x, cl.y = margin, y
+		cl.width, cl.height = 2*clusterPad+charWidth*len(cl.name), clusterLabel+2*clusterPad
+		if n := len(cl.entities); n > 0 {
+			var (
+				cols  = int(math.Ceil(math.Sqrt(float64(n))))
+				rows  = (n + cols - 1) / cols
+				colsW = make([]int, cols)
+				rowsH = make([]int, rows)
+			)
+			for i, e := range cl.entities {
+				e.size()
+				colsW[i%cols] = maxInt(colsW[i%cols], e.width)
+				rowsH[i/cols] = maxInt(rowsH[i/cols], e.height)
+			}
+			for i, e := range cl.entities {
+				e.x = cl.x + clusterPad + sum(colsW[:i%cols]) + i%cols*entityGap
+				e.y = cl.y + clusterLabel + clusterPad + sum(rowsH[:i/cols]) + i/cols*entityGap
+			}
+			cl.width = maxInt(cl.width, 2*clusterPad+sum(colsW)+(cols-1)*entityGap)
+			cl.height += sum(rowsH) + (rows-1)*entityGap
+		}
+		width = maxInt(width, cl.x+cl.width+margin)
+		y += cl.height + margin
+	}
+	return maxInt(width, 2*margin), y
+}
+
+// size computes the dimensions of the entity, and the offsets of its rows.
+func (e *entity) size() {
+	chars := len(e.name)
+	for i, r := range e.rows {
+		chars = maxInt(chars, len(r.label()))
+		r.offset = (i + 1) * rowHeight
+	}
+	e.width = chars*charWidth + 2*textPadding
+	e.height = (len(e.rows) + 1) * rowHeight
+}
+
+// path returns the SVG path of the edge. Edges leave the referencing column from the side
+// facing the referenced table, and enter the referenced column from the opposite side.
+func (e *edge) path() string {
+	var (
+		sy = e.from.y + e.fromRow.offset + rowHeight/2
+		ty = e.to.y + e.toRow.offset + rowHeight/2
+	)
+	switch {
+	// Self references loop on the right side of the table.
+	case e.from == e.to:
+		x := e.from.x + e.from.width
+		return fmt.Sprintf("M%d,%d C%d,%d %d,%d %d,%d", x, sy, x+edgeCurve, sy, x+edgeCurve, ty, x, ty)
+	case e.to.x >= e.from.x+e.from.width:
+		sx, tx := e.from.x+e.from.width, e.to.x
+		return fmt.Sprintf("M%d,%d C%d,%d %d,%d %d,%d", sx, sy, sx+edgeCurve, sy, tx-edgeCurve, ty, tx, ty)
+	case e.to.x+e.to.width <= e.from.x:
+		sx, tx := e.from.x, e.to.x+e.to.width
+		return fmt.Sprintf("M%d,%d C%d,%d %d,%d %d,%d", sx, sy, sx-edgeCurve, sy, tx+edgeCurve, ty, tx, ty)
+	// Tables that overlap horizontally are connected on their right sides.
+	default:
+		sx, tx := e.from.x+e.from.width, e.to.x+e.to.width
+		cx := maxInt(sx, tx) + edgeCurve
+		return fmt.Sprintf("M%d,%d C%d,%d %d,%d %d,%d", sx, sy, cx, sy, cx, ty, tx, ty)
+	}
+}
+
+func sum(vs []int) (s int) {
+	for _, v := range vs {
+		s += v
+	}
+	return s
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,447 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package gogen generates Go structs from database schemas, as a lightweight alternative to
+// full ORMs. Each table is mapped to a struct, and each column to a field with "db" and "json"
+// tags. Nullable columns are mapped to the null wrappers of the database/sql package (or to
+// pointers), and enum types and checks that restrict columns to a list of values are mapped
+// to string types with a constant for each value. For example:
+//
+//	src, err := gogen.Generate(realm, gogen.WithPackage("models"))
+//	if err != nil {
+//		return err
+//	}
+//	err := os.WriteFile("models/models.go", src, 0644)
+package gogen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// Option allows configuring the generation using functional options.
+	Option func(*generator)
+
+	// generator holds the state of the generation.
+	generator struct {
+		pkg      string
+		pointers bool
+		imports  map[string]bool
+		enums    []*enum
+		// Generated enums, keyed by the names of their database types
+		// (e.g. PostgreSQL enums), or by their columns (e.g. MySQL enums).
+		byName   map[string]*enum
+		byColumn map[*schema.Column]*enum
+		names    map[string]bool
+	}
+
+	// enum is a string type generated for an enum type or an IN check.
+	enum struct {
+		name   string
+		values []string
+		consts []string // Names of the value constants.
+	}
+
+	// field is a struct field.
+	field struct {
+		name, typ, tag, doc string
+	}
+)
+
+// WithPackage sets the package name of the generated file. Defaults to "models".
+func WithPackage(name string) Option {
+	return func(g *generator) {
+		g.pkg = name
+	}
+}
+
+// WithPointers maps nullable columns to pointers (e.g. *string)
+// instead of the null wrappers of database/sql (e.g. sql.NullString).
+func WithPointers() Option {
+	return func(g *generator) {
+		g.pointers = true
+	}
+}
+
+// Generate generates the Go source file of the realm tables. The output is gofmt-ed.
+func Generate(r *schema.Realm, opts ...Option) ([]byte, error) {
+	g := &generator{
+		pkg:      "models",
+		imports:  make(map[string]bool),
+		byName:   make(map[string]*enum),
+		byColumn: make(map[*schema.Column]*enum),
+		names:    make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	var (
+		body    bytes.Buffer
+		structs = make(map[string]*schema.Table)
+		tables  = tableNames(r)
+	)
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			name := tables[t]
+			if prev, ok := structs[name]; ok {
+				return nil, fmt.Errorf("gogen: tables %q and %q are both mapped to struct %s", prev.Name, t.Name, name)
+			}
+			structs[name] = t
+			g.names[name] = true
+		}
+	}
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			if err := g.writeStruct(&body, tables[t], t); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var b bytes.Buffer
+	b.WriteString("// Code generated by atlas. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", g.pkg)
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for p := range g.imports {
+			imports = append(imports, strconv.Quote(p))
+		}
+		sort.Strings(imports)
+		fmt.Fprintf(&b, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+	for _, e := range g.enums {
+		e.write(&b)
+	}
+	b.Write(body.Bytes())
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("gogen: formatting generated source: %w", err)
+	}
+	return src, nil
+}
+
+// writeStruct writes the struct of the given table.
+func (g *generator) writeStruct(b *bytes.Buffer, name string, t *schema.Table) error {
+	var (
+		fields = make([]*field, 0, len(t.Columns))
+		seen   = make(map[string]*schema.Column, len(t.Columns))
+	)
+	for _, c := range t.Columns {
+		f := &field{
+			name: Pascal(c.Name),
+			tag:  fmt.Sprintf("`db:%q json:%q`", c.Name, c.Name),
+			doc:  comment(c.Attrs),
+		}
+		if prev, ok := seen[f.name]; ok {
+			return fmt.Errorf("gogen: columns %q and %q of table %q are both mapped to field %s", prev.Name, c.Name, t.Name, f.name)
+		}
+		seen[f.name] = c
+		typ, err := g.goType(name, t, c)
+		if err != nil {
+			return err
+		}
+		f.typ = typ
+		fields = append(fields, f)
+	}
+	if doc := comment(t.Attrs); doc != "" {
+		writeDoc(b, "", name+" "+doc)
+	} else {
+		fmt.Fprintf(b, "// %s represents a row in the %q table.\n", name, t.Name)
+	}
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, f := range fields {
+		if f.doc != "" {
+			writeDoc(b, "\t", f.doc)
+		}
+		fmt.Fprintf(b, "\t%s %s %s\n", f.name, f.typ, f.tag)
+	}
+	b.WriteString("}\n\n")
+	return nil
+}
+
+// goType returns the Go type of the column.
+func (g *generator) goType(structName string, t *schema.Table, c *schema.Column) (string, error) {
+	if c.Type == nil || c.Type.Type == nil {
+		return "", fmt.Errorf("gogen: missing type for column %q.%q", t.Name, c.Name)
+	}
+	var (
+		// The Go type, and its null wrapper in database/sql, if exists.
+		typ, null string
+		enumVs    = checkValues(t, c)
+	)
+	switch ct := c.Type.Type.(type) {
+	case *schema.BoolType:
+		typ, null = "bool", "sql.NullBool"
+	case *schema.IntegerType:
+		typ = intType(ct)
+		// Unsigned integers are wrapped by wider types to fit their values. There is no
+		// wrapper that fits uint64 values, and such columns are mapped to pointers.
+		switch typ {
+		case "uint8":
+			null = "sql.NullByte"
+		case "int8", "int16":
+			null = "sql.NullInt16"
+		case "int32", "uint16":
+			null = "sql.NullInt32"
+		case "int64", "uint32":
+			null = "sql.NullInt64"
+		}
+	case *schema.FloatType:
+		typ, null = "float64", "sql.NullFloat64"
+		switch strings.ToLower(ct.T) {
+		case "real", "float4":
+			typ = "float32"
+		}
+	// Decimals are mapped to strings to avoid losing precision.
+	case *schema.DecimalType, *schema.UUIDType, *schema.StringType:
+		typ, null = "string", "sql.NullString"
+	case *schema.EnumType:
+		e, err := g.enum(structName, c, ct)
+		if err != nil {
+			return "", err
+		}
+		typ = e.name
+	case *schema.TimeType:
+		g.imports["time"] = true
+		typ, null = "time.Time", "sql.NullTime"
+	// Slices hold NULL values as nil.
+	case *schema.JSONType:
+		g.imports["encoding/json"] = true
+		return "json.RawMessage", nil
+	case *schema.BinaryType:
+		return "[]byte", nil
+	default:
+		typ, null = "string", "sql.NullString"
+	}
+	if len(enumVs) > 0 && typ == "string" {
+		e, err := g.checkEnum(structName, c, enumVs)
+		if err != nil {
+			return "", err
+		}
+		typ, null = e.name, ""
+	}
+	switch {
+	case !c.Type.Null:
+		return typ, nil
+	// Types without null wrappers (e.g. enums) are mapped to pointers.
+	case g.pointers || null == "":
+		return "*" + typ, nil
+	default:
+		g.imports["database/sql"] = true
+		return null, nil
+	}
+}
+
+// enum returns the enum type of the given enum column. Named enums
+// (e.g. PostgreSQL enums) are shared between the columns that use them.
+func (g *generator) enum(structName string, c *schema.Column, t *schema.EnumType) (*enum, error) {
+	if t.T == "" || strings.EqualFold(t.T, "enum") {
+		return g.checkEnum(structName, c, t.Values)
+	}
+	name := Pascal(t.T)
+	if e, ok := g.byName[name]; ok {
+		return e, nil
+	}
+	e, err := g.newEnum(name, t.Values)
+	if err != nil {
+		return nil, err
+	}
+	g.byName[name] = e
+	return e, nil
+}
+
+// checkEnum returns the enum type of a column restricted by an inline enum type or an IN check.
+func (g *generator) checkEnum(structName string, c *schema.Column, values []string) (*enum, error) {
+	if e, ok := g.byColumn[c]; ok {
+		return e, nil
+	}
+	e, err := g.newEnum(structName+Pascal(c.Name), values)
+	if err != nil {
+		return nil, err
+	}
+	g.byColumn[c] = e
+	return e, nil
+}
+
+func (g *generator) newEnum(name string, values []string) (*enum, error) {
+	if g.names[name] {
+		return nil, fmt.Errorf("gogen: enum type %s conflicts with another type", name)
+	}
+	e := &enum{name: name, values: values}
+	g.names[name] = true
+	for _, v := range values {
+		c := name + valueName(v)
+		if g.names[c] {
+			return nil, fmt.Errorf("gogen: enum constant %s conflicts with another identifier", c)
+		}
+		g.names[c] = true
+		e.consts = append(e.consts, c)
+	}
+	g.enums = append(g.enums, e)
+	return e, nil
+}
+
+// write writes the enum type and its constants.
+func (e *enum) write(b *bytes.Buffer) {
+	fmt.Fprintf(b, "// %s is the type of the enum values.\ntype %s string\n\n", e.name, e.name)
+	fmt.Fprintf(b, "// List of %s values.\nconst (\n", e.name)
+	for i, v := range e.values {
+		fmt.Fprintf(b, "\t%s %s = %q\n", e.consts[i], e.name, v)
+	}
+	b.WriteString(")\n\n")
+	fmt.Fprintf(b, "// Values returns the %s values.\nfunc (%s) Values() []string {\n\treturn []string{", e.name, e.name)
+	for i, v := range e.values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(v))
+	}
+	b.WriteString("}\n}\n\n")
+}
+
+// reIn matches checks that restrict a column to a list of values. e.g. "status IN ('a', 'b')".
+var reIn = regexp.MustCompile(`(?is)^\(*\s*[` + "`" + `"\[]?(\w+)[` + "`" + `"\]]?\s+IN\s*\((.+?)\)\s*\)*$`)
+
+// checkValues returns the values of the column, if
+// it is restricted to a list of values by a check.
+func checkValues(t *schema.Table, c *schema.Column) []string {
+	for _, a := range append(c.Attrs[:len(c.Attrs):len(c.Attrs)], t.Attrs...) {
+		ck, ok := a.(*schema.Check)
+		if !ok {
+			continue
+		}
+		m := reIn.FindStringSubmatch(strings.TrimSpace(ck.Expr))
+		if m == nil || m[1] != c.Name {
+			continue
+		}
+		var vs []string
+		for _, v := range strings.Split(m[2], ",") {
+			v = strings.TrimSpace(v)
+			// Only string literals are mapped to enums. Drivers may
+			// append casts to literals, e.g. 'a'::character varying.
+			if i := strings.Index(v, "::"); i > 0 {
+				v = v[:i]
+			}
+			if !sqlx.IsQuoted(v, '\'') {
+				vs = nil
+				break
+			}
+			u, err := sqlx.Unquote(v)
+			if err != nil {
+				vs = nil
+				break
+			}
+			vs = append(vs, u)
+		}
+		if len(vs) > 0 {
+			return vs
+		}
+	}
+	return nil
+}
+
+// intType returns the Go type of the integer type.
+func intType(t *schema.IntegerType) string {
+	typ := "int32"
+	switch strings.ToLower(t.T) {
+	case "tinyint":
+		typ = "int8"
+	case "smallint", "int2", "smallserial", "serial2", "year":
+		typ = "int16"
+	case "bigint", "int8", "bigserial", "serial8":
+		typ = "int64"
+	}
+	if t.Unsigned {
+		typ = "u" + typ
+	}
+	return typ
+}
+
+// tableNames returns the struct names of the tables. Tables that share their names
+// with tables in other schemas are prefixed with the names of their schemas.
+func tableNames(r *schema.Realm) map[*schema.Table]string {
+	var (
+		count = make(map[string]int)
+		names = make(map[*schema.Table]string)
+	)
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			count[Pascal(t.Name)]++
+		}
+	}
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			name := Pascal(t.Name)
+			if count[name] > 1 {
+				name = Pascal(s.Name) + name
+			}
+			names[t] = name
+		}
+	}
+	return names
+}
+
+// initialisms are written in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true,
+	"GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"UID": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// Pascal returns the exported Go identifier of the given name.
+// e.g. "user_id" => "UserID", and "2fa" => "X2fa".
+func Pascal(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if u := strings.ToUpper(w); initialisms[u] {
+			b.WriteString(u)
+			continue
+		}
+		rs := []rune(w)
+		b.WriteRune(unicode.ToUpper(rs[0]))
+		b.WriteString(string(rs[1:]))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// valueName returns the name of the enum value, used as the suffix of its constant.
+func valueName(v string) string {
+	if strings.TrimSpace(v) == "" {
+		return "Empty"
+	}
+	return Pascal(v)
+}
+
+// writeDoc writes a comment, one line per each line of the text.
+func writeDoc(b *bytes.Buffer, indent, text string) {
+	for _, l := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(b, "%s// %s\n", indent, strings.TrimSpace(l))
+	}
+}
+
+// comment returns the comment text from the attributes, if exists.
+func comment(attrs []schema.Attr) string {
+	var c schema.Comment
+	if sqlx.Has(attrs, &c) {
+		return c.Text
+	}
+	return ""
+}
@@ -0,0 +1,208 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package gogen_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/gogen"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := gogen.Generate(testRealm())
+	require.NoError(t, err)
+	require.Equal(t, "// Code generated by atlas. DO NOT EDIT.\n\n"+`package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// UsersStatus is the type of the enum values.
+type UsersStatus string
+
+// List of UsersStatus values.
+const (
+	UsersStatusActive UsersStatus = "active"
+	UsersStatusBanned UsersStatus = "banned"
+)
+
+// Values returns the UsersStatus values.
+func (UsersStatus) Values() []string {
+	return []string{"active", "banned"}
+}
+
+// UsersRole is the type of the enum values.
+type UsersRole string
+
+// List of UsersRole values.
+const (
+	UsersRoleAdmin    UsersRole = "admin"
+	UsersRoleReadOnly UsersRole = "read-only"
+)
+
+// Values returns the UsersRole values.
+func (UsersRole) Values() []string {
+	return []string{"admin", "read-only"}
+}
+
+// Mood is the type of the enum values.
+type Mood string
+
+// List of Mood values.
+const (
+	MoodHappy Mood = "happy"
+	MoodSad   Mood = "sad"
+)
+
+// Values returns the Mood values.
+func (Mood) Values() []string {
+	return []string{"happy", "sad"}
+}
+
+// Users holds the registered users.
+type Users struct {
+	// The user identifier.
+	ID        int64           `+"`db:\"id\" json:\"id\"`"+`
+	UserUUID  string          `+"`db:\"user_uuid\" json:\"user_uuid\"`"+`
+	Name      sql.NullString  `+"`db:\"name\" json:\"name\"`"+`
+	Age       sql.NullInt16   `+"`db:\"age\" json:\"age\"`"+`
+	Balance   string          `+"`db:\"balance\" json:\"balance\"`"+`
+	Score     float32         `+"`db:\"score\" json:\"score\"`"+`
+	Active    bool            `+"`db:\"active\" json:\"active\"`"+`
+	Status    *UsersStatus    `+"`db:\"status\" json:\"status\"`"+`
+	Role      UsersRole       `+"`db:\"role\" json:\"role\"`"+`
+	Mood      Mood            `+"`db:\"mood\" json:\"mood\"`"+`
+	Data      json.RawMessage `+"`db:\"data\" json:\"data\"`"+`
+	Avatar    []byte          `+"`db:\"avatar\" json:\"avatar\"`"+`
+	CreatedAt time.Time       `+"`db:\"created_at\" json:\"created_at\"`"+`
+	DeletedAt sql.NullTime    `+"`db:\"deleted_at\" json:\"deleted_at\"`"+`
+	X2fa      uint32          `+"`db:\"2fa\" json:\"2fa\"`"+`
+}
+
+// PublicPosts represents a row in the "posts" table.
+type PublicPosts struct {
+	Mood Mood `+"`db:\"mood\" json:\"mood\"`"+`
+}
+
+// SalesPosts represents a row in the "posts" table.
+type SalesPosts struct {
+	ID int32 `+"`db:\"id\" json:\"id\"`"+`
+}
+`, string(src))
+}
+
+func TestGenerate_Options(t *testing.T) {
+	src, err := gogen.Generate(testRealm(), gogen.WithPackage("db"), gogen.WithPointers())
+	require.NoError(t, err)
+	require.Contains(t, string(src), "package db\n")
+	require.Contains(t, string(src), "\tName      *string         `db:\"name\" json:\"name\"`\n")
+	require.Contains(t, string(src), "\tDeletedAt *time.Time      `db:\"deleted_at\" json:\"deleted_at\"`\n")
+	require.NotContains(t, string(src), "database/sql")
+
+	// Conflicting names.
+	_, err = gogen.Generate(schema.NewRealm(
+		schema.New("public").AddTables(
+			schema.NewTable("users").AddColumns(
+				schema.NewIntColumn("user_id", "int"),
+				schema.NewIntColumn("UserID", "int"),
+			),
+		),
+	))
+	require.EqualError(t, err, `gogen: columns "user_id" and "UserID" of table "users" are both mapped to field UserID`)
+}
+
+func TestGenerate_Unsigned(t *testing.T) {
+	nullUint := func(name, typ string) *schema.Column {
+		return schema.NewColumn(name).SetType(&schema.IntegerType{T: typ, Unsigned: true}).SetNull(true)
+	}
+	r := schema.NewRealm(
+		schema.New("public").AddTables(
+			schema.NewTable("counters").AddColumns(
+				nullUint("tiny", "tinyint"),
+				nullUint("small", "smallint"),
+				nullUint("medium", "int"),
+				nullUint("big", "bigint"),
+			),
+		),
+	)
+	src, err := gogen.Generate(r)
+	require.NoError(t, err)
+	require.Contains(t, string(src), "\tTiny   sql.NullByte  `db:\"tiny\" json:\"tiny\"`\n")
+	require.Contains(t, string(src), "\tSmall  sql.NullInt32 `db:\"small\" json:\"small\"`\n")
+	require.Contains(t, string(src), "\tMedium sql.NullInt64 `db:\"medium\" json:\"medium\"`\n")
+	require.Contains(t, string(src), "\tBig    *uint64       `db:\"big\" json:\"big\"`\n")
+
+	src, err = gogen.Generate(r, gogen.WithPointers())
+	require.NoError(t, err)
+	require.Contains(t, string(src), "\tTiny   *uint8  `db:\"tiny\" json:\"tiny\"`\n")
+}
+
+func TestGenerate_EmptyEnumValue(t *testing.T) {
+	src, err := gogen.Generate(schema.NewRealm(
+		schema.New("public").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewEnumColumn("status", schema.EnumValues("", "active"))),
+		),
+	))
+	require.NoError(t, err)
+	require.Contains(t, string(src), "\tUsersStatusEmpty  UsersStatus = \"\"\n")
+	require.Contains(t, string(src), "\tUsersStatusActive UsersStatus = \"active\"\n")
+
+	// Blank values are named like empty values.
+	_, err = gogen.Generate(schema.NewRealm(
+		schema.New("public").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewEnumColumn("status", schema.EnumValues("", " "))),
+		),
+	))
+	require.EqualError(t, err, "gogen: enum constant UsersStatusEmpty conflicts with another identifier")
+}
+
+func TestPascal(t *testing.T) {
+	for in, out := range map[string]string{
+		"user_id":    "UserID",
+		"api_url":    "APIURL",
+		"created-at": "CreatedAt",
+		"camelCase":  "CamelCase",
+		"2fa":        "X2fa",
+		"_":          "X",
+	} {
+		require.Equal(t, out, gogen.Pascal(in), in)
+	}
+}
+
+func testRealm() *schema.Realm {
+	mood := &schema.EnumType{T: "mood", Values: []string{"happy", "sad"}}
+	users := schema.NewTable("users").
+		SetComment("holds the registered users.").
+		AddColumns(
+			schema.NewIntColumn("id", "bigint").SetComment("The user identifier."),
+			schema.NewColumn("user_uuid").SetType(&schema.UUIDType{T: "uuid"}),
+			schema.NewNullStringColumn("name", "text"),
+			schema.NewNullIntColumn("age", "smallint"),
+			schema.NewDecimalColumn("balance", "decimal"),
+			schema.NewFloatColumn("score", "real"),
+			schema.NewBoolColumn("active", "boolean"),
+			schema.NewNullStringColumn("status", "varchar"),
+			schema.NewEnumColumn("role", schema.EnumValues("admin", "read-only")),
+			schema.NewColumn("mood").SetType(mood),
+			schema.NewJSONColumn("data", "jsonb"),
+			schema.NewBinaryColumn("avatar", "bytea"),
+			schema.NewTimeColumn("created_at", "timestamp"),
+			schema.NewNullTimeColumn("deleted_at", "timestamp"),
+			schema.NewColumn("2fa").SetType(&schema.IntegerType{T: "int", Unsigned: true}),
+		).
+		AddChecks(schema.NewCheck().SetExpr("(status IN ('active', 'banned'))"))
+	return schema.NewRealm(
+		schema.New("public").AddTables(
+			users,
+			schema.NewTable("posts").AddColumns(schema.NewColumn("mood").SetType(&schema.EnumType{T: "mood", Values: []string{"happy", "sad"}})),
+		),
+		schema.New("sales").AddTables(schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "int"))),
+	)
+}
@@ -14,6 +14,7 @@ import (
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlspec"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -57,6 +58,28 @@ type (
 		View  ConvertViewFunc
 		Func  func(*sqlspec.Func) (*schema.Func, error)
 		Proc  func(*sqlspec.Func) (*schema.Proc, error)
+		// ForeignKey optionally converts the dialect-specific attributes
+		// of a foreign-key after it was linked, e.g., its MATCH type.
+		ForeignKey func(*sqlspec.ForeignKey, *schema.ForeignKey) error
+		// UniqueRefs indicates that the referenced columns of foreign-keys must
+		// form a unique key in the referenced table. Unlike others, MySQL allows
+		// referencing columns of non-unique indexes.
+		UniqueRefs bool
+		// WarnRefs indicates that foreign-keys whose referenced columns do not form a
+		// unique key are reported as warnings by Validate, and do not fail the scan.
+		// e.g., SQLite accepts such foreign keys, and fails only when they are enforced.
+		WarnRefs bool
+		// Ident holds the identifier rules of the dialect,
+		// checked by Validate. Nil means no rules.
+		Ident *IdentRules
+		// Mixins holds the mixins that can be attached to table
+		// blocks, keyed by their names. They are expanded before
+		// the tables are converted.
+		Mixins map[string]MixinFunc
+		// SearchPath holds the schemas that unqualified references,
+		// such as table.users, are resolved from by their order.
+		// For example, the PostgreSQL search_path.
+		SearchPath []string
 	}
 
 	// Funcs represents a set of spec functions
@@ -88,72 +111,81 @@ func Scan(r *schema.Realm, doc *ScanDoc, funcs *ScanFuncs) error {
 		r.AddSchemas(s1)
 		byName[s.Name] = s1
 	}
-	tableFKs := make(map[*schema.Table][]*sqlspec.ForeignKey)
+	var (
+		refs     = refColumns(doc.Tables)
+		tableFKs = make(map[*schema.Table][]*sqlspec.ForeignKey)
+	)
 	for _, st := range doc.Tables {
 		name, err := SchemaName(st.Schema)
 		if err != nil {
-			return fmt.Errorf("specutil: cannot extract schema name for table %q: %w", st.Name, err)
+			return schemahcl.ErrorAt(st.Range(), fmt.Errorf("specutil: cannot extract schema name for table %q: %w", st.Name, err))
 		}
 		s, ok := byName[name]
 		if !ok {
-			return fmt.Errorf("specutil: schema %q not found for table %q", name, st.Name)
+			return schemahcl.ErrorAt(st.Range(), fmt.Errorf("specutil: schema %q not found for table %q", name, st.Name))
+		}
+		if err := expandMixins(st, funcs.Mixins, refs); err != nil {
+			return err
 		}
 		t, err := funcs.Table(st, s)
 		if err != nil {
-			return fmt.Errorf("specutil: cannot convert table %q: %w", st.Name, err)
+			return schemahcl.ErrorAt(st.Range(), fmt.Errorf("specutil: cannot convert table %q: %w", st.Name, err))
 		}
 		tableFKs[t] = st.ForeignKeys
 		s.AddTables(t)
 	}
 	// Link the foreign keys.
 	for t, fks := range tableFKs {
-		if err := linkForeignKeys(t, fks); err != nil {
+		if err := linkForeignKeys(t, fks, funcs); err != nil {
 			return err
 		}
 	}
-	viewDeps := make(map[*schema.View][]*schemahcl.Ref, len(doc.Views))
+	var (
+		viewDeps   = make(map[*schema.View][]*schemahcl.Ref, len(doc.Views))
+		viewDepsAt = make(map[*schema.View]*hcl.Range, len(doc.Views))
+	)
 	for _, sv := range doc.Views {
 		name, err := SchemaName(sv.Schema)
 		if err != nil {
-			return fmt.Errorf("specutil: cannot extract schema name for view %q: %w", sv.Name, err)
+			return schemahcl.ErrorAt(sv.Range(), fmt.Errorf("specutil: cannot extract schema name for view %q: %w", sv.Name, err))
 		}
 		s, ok := byName[name]
 		if !ok {
-			return fmt.Errorf("specutil: schema %q not found for view %q", name, sv.Name)
+			return schemahcl.ErrorAt(sv.Range(), fmt.Errorf("specutil: schema %q not found for view %q", name, sv.Name))
 		}
 		v, err := funcs.View(sv, s)
 		if err != nil {
-			return fmt.Errorf("specutil: cannot convert view %q: %w", sv.Name, err)
+			return schemahcl.ErrorAt(sv.Range(), fmt.Errorf("specutil: cannot convert view %q: %w", sv.Name, err))
 		}
 		s.AddViews(v)
 		if deps, ok := sv.Attr("depends_on"); ok {
 			refs, err := deps.Refs()
 			if err != nil {
-				return fmt.Errorf("specutil: expect list of references for attribute view.%s.depends_on: %w", sv.Name, err)
+				return schemahcl.ErrorAt(deps.Range(), fmt.Errorf("specutil: expect list of references for attribute view.%s.depends_on: %w", sv.Name, err))
 			}
-			viewDeps[v] = refs
+			viewDeps[v], viewDepsAt[v] = refs, deps.Range()
 		}
 	}
 	for _, m := range doc.Materialized {
 		name, err := SchemaName(m.Schema)
 		if err != nil {
-			return fmt.Errorf("specutil: cannot extract schema name for materialized %q: %w", m.Name, err)
+			return schemahcl.ErrorAt(m.Range(), fmt.Errorf("specutil: cannot extract schema name for materialized %q: %w", m.Name, err))
 		}
 		s, ok := byName[name]
 		if !ok {
-			return fmt.Errorf("specutil: schema %q not found for materialized %q", name, m.Name)
+			return schemahcl.ErrorAt(m.Range(), fmt.Errorf("specutil: schema %q not found for materialized %q", name, m.Name))
 		}
 		v, err := funcs.View(m, s)
 		if err != nil {
-			return fmt.Errorf("specutil: cannot convert materialized %q: %w", m.Name, err)
+			return schemahcl.ErrorAt(m.Range(), fmt.Errorf("specutil: cannot convert materialized %q: %w", m.Name, err))
 		}
 		s.AddViews(v.SetMaterialized(true))
 		if deps, ok := m.Attr("depends_on"); ok {
 			refs, err := deps.Refs()
 			if err != nil {
-				return fmt.Errorf("specutil: expect list of references for attribute materialized.%s.depends_on: %w", m.Name, err)
+				return schemahcl.ErrorAt(deps.Range(), fmt.Errorf("specutil: expect list of references for attribute materialized.%s.depends_on: %w", m.Name, err))
 			}
-			viewDeps[v] = refs
+			viewDeps[v], viewDepsAt[v] = refs, deps.Range()
 		}
 	}
 	// Link views' dependencies.
@@ -165,43 +197,43 @@ func Scan(r *schema.Realm, doc *ScanDoc, funcs *ScanFuncs) error {
 		for i, r := range refs {
 			switch p, err := r.Path(); {
 			case err != nil:
-				return fmt.Errorf("specutil: extract reference for %s.%s: %w", srcT, v.Name, err)
+				return schemahcl.ErrorAt(viewDepsAt[v], fmt.Errorf("specutil: extract reference for %s.%s: %w", srcT, v.Name, err))
 			case len(p) == 0:
-				return fmt.Errorf("specutil: empty reference for %s.%s", srcT, v.Name)
+				return schemahcl.ErrorAt(viewDepsAt[v], fmt.Errorf("specutil: empty reference for %s.%s", srcT, v.Name))
 			case p[0].T == typeView:
 				q, n, err := refName(r, typeView)
 				if err != nil {
-					return fmt.Errorf("specutil: extract view name from %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err)
+					return schemahcl.ErrorAt(viewDepsAt[v], fmt.Errorf("specutil: extract view name from %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err))
 				}
-				v1, err := findT(v.Schema, q, n, func(s *schema.Schema, name string) (*schema.View, bool) {
+				v1, err := findT(v.Schema, q, n, funcs.SearchPath, func(s *schema.Schema, name string) (*schema.View, bool) {
 					return s.View(name)
 				})
 				if err != nil {
-					return fmt.Errorf("specutil: find view refrence for %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err)
+					return schemahcl.ErrorAt(viewDepsAt[v], fmt.Errorf("specutil: find view refrence for %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err))
 				}
 				v.AddDeps(v1)
 			case p[0].T == typeMaterialized:
 				q, n, err := refName(r, typeMaterialized)
 				if err != nil {
-					return fmt.Errorf("specutil: extract materialized name from %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err)
+					return schemahcl.ErrorAt(viewDepsAt[v], fmt.Errorf("specutil: extract materialized name from %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err))
 				}
-				v1, err := findT(v.Schema, q, n, func(s *schema.Schema, name string) (*schema.View, bool) {
+				v1, err := findT(v.Schema, q, n, funcs.SearchPath, func(s *schema.Schema, name string) (*schema.View, bool) {
 					return s.Materialized(name)
 				})
 				if err != nil {
-					return fmt.Errorf("specutil: find materialized refrence for %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err)
+					return schemahcl.ErrorAt(viewDepsAt[v], fmt.Errorf("specutil: find materialized refrence for %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err))
 				}
 				v.AddDeps(v1)
 			case p[0].T == typeTable:
 				q, n, err := refName(r, typeTable)
 				if err != nil {
-					return fmt.Errorf("specutil: extract table name from %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err)
+					return schemahcl.ErrorAt(viewDepsAt[v], fmt.Errorf("specutil: extract table name from %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err))
 				}
-				t1, err := findT(v.Schema, q, n, func(s *schema.Schema, name string) (*schema.Table, bool) {
+				t1, err := findT(v.Schema, q, n, funcs.SearchPath, func(s *schema.Schema, name string) (*schema.Table, bool) {
 					return s.Table(name)
 				})
 				if err != nil {
-					return fmt.Errorf("specutil: find table refrence for %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err)
+					return schemahcl.ErrorAt(viewDepsAt[v], fmt.Errorf("specutil: find table refrence for %s.%s.depends_on[%d]: %w", srcT, v.Name, i, err))
 				}
 				v.AddDeps(t1)
 			}
@@ -211,15 +243,15 @@ func Scan(r *schema.Realm, doc *ScanDoc, funcs *ScanFuncs) error {
 		for _, sf := range doc.Funcs {
 			name, err := SchemaName(sf.Schema)
 			if err != nil {
-				return fmt.Errorf("specutil: cannot extract schema name for function %q: %w", sf.Name, err)
+				return schemahcl.ErrorAt(sf.Range(), fmt.Errorf("specutil: cannot extract schema name for function %q: %w", sf.Name, err))
 			}
 			s, ok := byName[name]
 			if !ok {
-				return fmt.Errorf("specutil: schema %q not found for function %q", name, sf.Name)
+				return schemahcl.ErrorAt(sf.Range(), fmt.Errorf("specutil: schema %q not found for function %q", name, sf.Name))
 			}
 			f, err := funcs.Func(sf)
 			if err != nil {
-				return fmt.Errorf("specutil: cannot convert function %q: %w", sf.Name, err)
+				return schemahcl.ErrorAt(sf.Range(), fmt.Errorf("specutil: cannot convert function %q: %w", sf.Name, err))
 			}
 			s.AddFuncs(f)
 		}
@@ -228,15 +260,15 @@ func Scan(r *schema.Realm, doc *ScanDoc, funcs *ScanFuncs) error {
 		for _, sf := range doc.Procs {
 			name, err := SchemaName(sf.Schema)
 			if err != nil {
-				return fmt.Errorf("specutil: cannot extract schema name for procedure %q: %w", sf.Name, err)
+				return schemahcl.ErrorAt(sf.Range(), fmt.Errorf("specutil: cannot extract schema name for procedure %q: %w", sf.Name, err))
 			}
 			s, ok := byName[name]
 			if !ok {
-				return fmt.Errorf("specutil: schema %q not found for procedure %q", name, sf.Name)
+				return schemahcl.ErrorAt(sf.Range(), fmt.Errorf("specutil: schema %q not found for procedure %q", name, sf.Name))
 			}
 			f, err := funcs.Proc(sf)
 			if err != nil {
-				return fmt.Errorf("specutil: cannot convert procedure %q: %w", sf.Name, err)
+				return schemahcl.ErrorAt(sf.Range(), fmt.Errorf("specutil: cannot convert procedure %q: %w", sf.Name, err))
 			}
 			s.AddProcs(f)
 		}
@@ -256,33 +288,33 @@ func Table(spec *sqlspec.Table, parent *schema.Schema, convertColumn ConvertTabl
 	for _, csp := range spec.Columns {
 		col, err := convertColumn(csp, t)
 		if err != nil {
-			return nil, err
+			return nil, schemahcl.ErrorAt(csp.Range(), err)
 		}
 		t.AddColumns(col)
 	}
 	if spec.PrimaryKey != nil {
 		pk, err := convertPK(spec.PrimaryKey, t)
 		if err != nil {
-			return nil, err
+			return nil, schemahcl.ErrorAt(spec.PrimaryKey.Range(), err)
 		}
 		t.SetPrimaryKey(pk)
 	}
 	for _, idx := range spec.Indexes {
 		i, err := convertIndex(idx, t)
 		if err != nil {
-			return nil, err
+			return nil, schemahcl.ErrorAt(idx.Range(), err)
 		}
 		t.AddIndexes(i)
 	}
-	for _, c := range spec.Checks {
-		c, err := convertCheck(c)
+	for _, csp := range spec.Checks {
+		c, err := convertCheck(csp)
 		if err != nil {
-			return nil, err
+			return nil, schemahcl.ErrorAt(csp.Range(), err)
 		}
 		t.AddChecks(c)
 	}
 	if err := convertCommentFromSpec(spec, &t.Attrs); err != nil {
-		return nil, err
+		return nil, schemahcl.ErrorAt(spec.Range(), err)
 	}
 	return t, nil
 }
@@ -291,34 +323,34 @@ func Table(spec *sqlspec.Table, parent *schema.Schema, convertColumn ConvertTabl
 func View(spec *sqlspec.View, parent *schema.Schema, convertC ConvertViewColumnFunc, convertI ConvertViewIndexFunc) (*schema.View, error) {
 	as, ok := spec.Extra.Attr("as")
 	if !ok {
-		return nil, fmt.Errorf("specutil: missing 'as' definition for view %q", spec.Name)
+		return nil, schemahcl.ErrorAt(spec.Range(), fmt.Errorf("specutil: missing 'as' definition for view %q", spec.Name))
 	}
 	def, err := as.String()
 	if err != nil {
-		return nil, fmt.Errorf("specutil: expect string definition for attribute view.%s.as: %w", spec.Name, err)
+		return nil, schemahcl.ErrorAt(as.Range(), fmt.Errorf("specutil: expect string definition for attribute view.%s.as: %w", spec.Name, err))
 	}
 	v := schema.NewView(spec.Name, def).SetSchema(parent)
-	for _, c := range spec.Columns {
-		c, err := convertC(c, v)
+	for _, csp := range spec.Columns {
+		c, err := convertC(csp, v)
 		if err != nil {
-			return nil, err
+			return nil, schemahcl.ErrorAt(csp.Range(), err)
 		}
 		v.AddColumns(c)
 	}
 	for _, idx := range spec.Indexes {
 		i, err := convertI(idx, v)
 		if err != nil {
-			return nil, err
+			return nil, schemahcl.ErrorAt(idx.Range(), err)
 		}
 		v.AddIndexes(i)
 	}
 	if err := convertCommentFromSpec(spec, &v.Attrs); err != nil {
-		return nil, err
+		return nil, schemahcl.ErrorAt(spec.Range(), err)
 	}
 	if c, ok := spec.Extra.Attr("check_option"); ok {
 		o, err := c.String()
 		if err != nil {
-			return nil, fmt.Errorf("specutil: expect string definition for attribute view.%s.check_option: %w", spec.Name, err)
+			return nil, schemahcl.ErrorAt(c.Range(), fmt.Errorf("specutil: expect string definition for attribute view.%s.check_option: %w", spec.Name, err))
 		}
 		v.SetCheckOption(o)
 	}
@@ -445,7 +477,7 @@ func PrimaryKey(spec *sqlspec.PrimaryKey, parent *schema.Table) (*schema.Index,
 	for seqno, c := range spec.Columns {
 		c, err := ColumnByRef(parent, c)
 		if err != nil {
-			return nil, nil
+			return nil, err
 		}
 		parts = append(parts, &schema.IndexPart{
 			SeqNo: seqno,
@@ -461,7 +493,11 @@ func PrimaryKey(spec *sqlspec.PrimaryKey, parent *schema.Table) (*schema.Index,
 // linkForeignKeys creates the foreign keys defined in the Table's spec by creating references
 // to column in the provided Schema. It is assumed that all tables referenced FK definitions in the spec
 // are reachable from the provided schema or its connected realm.
-func linkForeignKeys(tbl *schema.Table, fks []*sqlspec.ForeignKey) error {
+func linkForeignKeys(tbl *schema.Table, fks []*sqlspec.ForeignKey, funcs *ScanFuncs) error {
+	var path []string
+	if funcs != nil {
+		path = funcs.SearchPath
+	}
 	for _, spec := range fks {
 		fk := &schema.ForeignKey{Symbol: spec.Symbol, Table: tbl}
 		if spec.OnUpdate != nil {
@@ -471,35 +507,66 @@ func linkForeignKeys(tbl *schema.Table, fks []*sqlspec.ForeignKey) error {
 			fk.OnDelete = schema.ReferenceOption(FromVar(spec.OnDelete.V))
 		}
 		if n, m := len(spec.Columns), len(spec.RefColumns); n != m {
-			return fmt.Errorf("sqlspec: number of referencing and referenced columns do not match for foreign-key %q", fk.Symbol)
+			return schemahcl.ErrorAt(spec.Range(), fmt.Errorf("sqlspec: number of referencing and referenced columns do not match for foreign-key %q", fk.Symbol))
 		}
 		for _, ref := range spec.Columns {
 			c, err := ColumnByRef(tbl, ref)
 			if err != nil {
-				return err
+				return schemahcl.ErrorAt(spec.Range(), err)
 			}
 			fk.Columns = append(fk.Columns, c)
 		}
 		for i, ref := range spec.RefColumns {
-			t, c, err := externalRef(ref, tbl.Schema)
+			t, c, err := externalRef(ref, tbl.Schema, path)
 			if isLocalRef(ref) {
 				t = fk.Table
 				c, err = ColumnByRef(fk.Table, ref)
 			}
 			if err != nil {
-				return err
+				return schemahcl.ErrorAt(spec.Range(), err)
 			}
 			if i > 0 && fk.RefTable != t {
-				return fmt.Errorf("sqlspec: more than 1 table was referenced for foreign-key %q", fk.Symbol)
+				return schemahcl.ErrorAt(spec.Range(), fmt.Errorf("sqlspec: more than 1 table was referenced for foreign-key %q", fk.Symbol))
 			}
 			fk.RefTable = t
 			fk.RefColumns = append(fk.RefColumns, c)
 		}
+		if funcs != nil && funcs.UniqueRefs {
+			if err := checkRefKey(fk); err != nil {
+				return schemahcl.ErrorAt(spec.Range(), err)
+			}
+		}
+		if funcs != nil && funcs.ForeignKey != nil {
+			if err := funcs.ForeignKey(spec, fk); err != nil {
+				return schemahcl.ErrorAt(spec.Range(), err)
+			}
+		}
 		tbl.ForeignKeys = append(tbl.ForeignKeys, fk)
 	}
 	return nil
 }
 
+// checkRefKey checks that the referenced columns of the foreign-key form a unique key
+// in the referenced table. That is, they are the columns of its primary key, or of one
+// of its unique indexes (or constraints), regardless of their order. Partial indexes
+// and indexes on expressions cannot be referenced.
+func checkRefKey(fk *schema.ForeignKey) error {
+	keys := fk.RefTable.Indexes
+	if pk := fk.RefTable.PrimaryKey; pk != nil {
+		keys = append([]*schema.Index{pk}, keys...)
+	}
+	for _, k := range keys {
+		if (k == fk.RefTable.PrimaryKey || k.Unique) && sqlx.KeyOf(k, fk.RefColumns) {
+			return nil
+		}
+	}
+	names := make([]string, len(fk.RefColumns))
+	for i, c := range fk.RefColumns {
+		names[i] = strconv.Quote(c.Name)
+	}
+	return fmt.Errorf("specutil: referenced columns (%s) of foreign-key %q do not form a unique key in table %q", strings.Join(names, ", "), fk.Symbol, fk.RefTable.Name)
+}
+
 // FromSchema converts a schema.Schema into sqlspec.Schema and []sqlspec.Table.
 func FromSchema(s *schema.Schema, funcs *Funcs) (*SchemaSpec, error) {
 	spec := &SchemaSpec{
@@ -920,12 +987,12 @@ func ColumnByRef(t *schema.Table, ref *schemahcl.Ref) (*schema.Column, error) {
 	return c, nil
 }
 
-func externalRef(ref *schemahcl.Ref, sch *schema.Schema) (*schema.Table, *schema.Column, error) {
+func externalRef(ref *schemahcl.Ref, sch *schema.Schema, path []string) (*schema.Table, *schema.Column, error) {
 	qualifier, name, err := tableName(ref)
 	if err != nil {
 		return nil, nil, err
 	}
-	t, err := findT(sch, qualifier, name, func(s *schema.Schema, name string) (*schema.Table, bool) {
+	t, err := findT(sch, qualifier, name, path, func(s *schema.Schema, name string) (*schema.Table, bool) {
 		return s.Table(name)
 	})
 	if err != nil {
@@ -940,8 +1007,10 @@ func externalRef(ref *schemahcl.Ref, sch *schema.Schema) (*schema.Table, *schema
 
 // findT finds the table/view referenced by ref in the provided schema. If the table/view
 // is not in the provided schema.Schema other schemas in the connected schema.Realm are
-// searched as well.
-func findT[T schema.View | schema.Table](sch *schema.Schema, qualifier, name string, findT func(*schema.Schema, string) (*T, bool)) (*T, error) {
+// searched as well. If a search path is set, unqualified references are resolved from
+// the provided schema first, then from the schemas in the path by their order, and only
+// then from all schemas in the realm.
+func findT[T schema.View | schema.Table](sch *schema.Schema, qualifier, name string, path []string, findT func(*schema.Schema, string) (*T, bool)) (*T, error) {
 	var (
 		matches []*T             // Found references.
 		schemas []*schema.Schema // Schemas to search.
@@ -950,6 +1019,16 @@ func findT[T schema.View | schema.Table](sch *schema.Schema, qualifier, name str
 	case sch.Realm == nil || qualifier == sch.Name:
 		schemas = []*schema.Schema{sch}
 	case qualifier == "":
+		if t, ok := findT(sch, name); ok && len(path) > 0 {
+			return t, nil
+		}
+		for _, n := range path {
+			if s, ok := sch.Realm.Schema(n); ok {
+				if t, ok := findT(s, name); ok {
+					return t, nil
+				}
+			}
+		}
 		schemas = sch.Realm.Schemas
 	default:
 		s, ok := sch.Realm.Schema(qualifier)
@@ -0,0 +1,70 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package specutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// IdentRules describes the rules of identifiers in a dialect,
+// used to validate the names of resources before planning.
+type IdentRules struct {
+	dialect  string
+	maxLen   int
+	reserved map[string]bool
+}
+
+// NewIdentRules returns the identifier rules of a dialect. maxLen is the maximum length
+// of identifiers in bytes (zero means no limit), and reserved is a whitespace-separated
+// list of the dialect reserved words.
+func NewIdentRules(dialect string, maxLen int, reserved string) *IdentRules {
+	r := &IdentRules{dialect: dialect, maxLen: maxLen, reserved: make(map[string]bool)}
+	for _, w := range strings.Fields(reserved) {
+		r.reserved[strings.ToUpper(w)] = true
+	}
+	return r
+}
+
+// Reserved reports if the given name is a reserved word in the dialect.
+func (r *IdentRules) Reserved(name string) bool {
+	return r.reserved[strings.ToUpper(name)]
+}
+
+// Check returns an error if the name of the given resource type exceeds the maximum
+// length of the dialect, as such names are rejected or truncated by the database.
+func (r *IdentRules) Check(typ, name string) error {
+	if r == nil || r.maxLen <= 0 || len(name) <= r.maxLen {
+		return nil
+	}
+	// Keep a prefix of the name, and a hash of the
+	// full name to keep the suggested names unique.
+	n := r.maxLen - 9
+	for n > 0 && !utf8.RuneStart(name[n]) {
+		n--
+	}
+	h := sha256.Sum256([]byte(name))
+	s := strings.TrimRight(name[:n], "_") + "_" + hex.EncodeToString(h[:4])
+	return fmt.Errorf("%s name %q exceeds the %s limit of %d bytes (%d bytes). Consider a shorter name, e.g., %q", typ, name, r.dialect, r.maxLen, len(name), s)
+}
+
+// CheckReserved returns an error if the name of the given resource type collides with one
+// of the dialect reserved words. Atlas quotes identifiers in the statements it generates,
+// and such names are valid, but they require quoting in hand-written queries. The parent
+// is the name of the resource owning the named one (e.g., the table of a column), and it
+// is used to suggest an alternative name.
+func (r *IdentRules) CheckReserved(typ, name, parent string) error {
+	if r == nil || name == "" || !r.Reserved(name) {
+		return nil
+	}
+	s := name + "s"
+	if parent != "" {
+		s = parent + "_" + name
+	}
+	return fmt.Errorf("%s name %q is a reserved word in %s and must be quoted in queries. Consider renaming it, e.g., %q", typ, name, r.dialect, s)
+}
@@ -0,0 +1,41 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package specutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentRules_Check(t *testing.T) {
+	r := NewIdentRules("PostgreSQL", 16, "select order user")
+	require.True(t, r.Reserved("ORDER"))
+	require.False(t, r.Reserved("orders"))
+	require.NoError(t, r.Check("table", "orders"))
+	require.NoError(t, r.Check("index", ""))
+	require.NoError(t, r.Check("column", strings.Repeat("a", 16)))
+	// Reserved words are valid identifiers.
+	require.NoError(t, r.Check("table", "user"))
+	require.NoError(t, r.CheckReserved("table", "orders", ""))
+	require.NoError(t, r.CheckReserved("index", "", "orders"))
+
+	err := r.CheckReserved("column", "order", "items")
+	require.EqualError(t, err, `column name "order" is a reserved word in PostgreSQL and must be quoted in queries. Consider renaming it, e.g., "items_order"`)
+	err = r.CheckReserved("table", "user", "")
+	require.EqualError(t, err, `table name "user" is a reserved word in PostgreSQL and must be quoted in queries. Consider renaming it, e.g., "users"`)
+	err = r.Check("index", "users_created_at_idx")
+	require.EqualError(t, err, `index name "users_created_at_idx" exceeds the PostgreSQL limit of 16 bytes (20 bytes). Consider a shorter name, e.g., "users_c_94f2c863"`)
+
+	// Multibyte characters are not split.
+	err = r.Check("table", "abcde😀fghijklmnop")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `e.g., "abcde_`)
+
+	var nilRules *IdentRules
+	require.NoError(t, nilRules.Check("table", strings.Repeat("a", 100)))
+	require.NoError(t, nilRules.CheckReserved("table", "user", ""))
+}
@@ -0,0 +1,230 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package specutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/sqlspec"
+)
+
+type (
+	// MixinFunc expands a mixin block that is attached to a table spec, for
+	// example, by adding columns or indexes to it.
+	MixinFunc func(*Mixin) error
+
+	// Mixin describes a mixin block that is expanded.
+	Mixin struct {
+		T *sqlspec.Table      // Table spec.
+		R *schemahcl.Resource // Mixin block that holds the mixin configuration.
+		// Refs holds the sets of table columns that are referenced by foreign keys.
+		Refs [][]string
+	}
+)
+
+// List of builtin mixins.
+const (
+	MixinTimestamps = "timestamps"
+	MixinSoftDelete = "soft_delete"
+)
+
+const typeMixin = "mixin"
+
+// expandMixins expands the mixin blocks of the table spec and removes them from it.
+// For example:
+//
+//	table "users" {
+//	  schema = schema.public
+//	  column "id" {
+//	    type = int
+//	  }
+//	  mixin "timestamps" {}
+//	  mixin "soft_delete" {
+//	    column = "removed_at"
+//	  }
+//	}
+func expandMixins(t *sqlspec.Table, mixins map[string]MixinFunc, refs map[string][][]string) error {
+	children := make([]*schemahcl.Resource, 0, len(t.Extra.Children))
+	for _, r := range t.Extra.Children {
+		if r.Type != typeMixin {
+			children = append(children, r)
+			continue
+		}
+		f, ok := mixins[r.Name]
+		if !ok {
+			return schemahcl.ErrorAt(r.Range(), fmt.Errorf("specutil: unknown mixin %q for table %q", r.Name, t.Name))
+		}
+		if err := f(&Mixin{T: t, R: r, Refs: refs[t.Name]}); err != nil {
+			return schemahcl.ErrorAt(r.Range(), fmt.Errorf("specutil: mixin %q of table %q: %w", r.Name, t.Name, err))
+		}
+	}
+	t.Extra.Children = children
+	return nil
+}
+
+// refColumns returns the sets of columns that are referenced by the foreign keys of the
+// table specs, keyed by the names of the referenced tables. Broken references are skipped,
+// as they are reported when the foreign keys are linked.
+func refColumns(specs []*sqlspec.Table) map[string][][]string {
+	refs := make(map[string][][]string)
+Next:
+	for _, t := range specs {
+		for _, fk := range t.ForeignKeys {
+			var (
+				table   = t.Name
+				columns = make([]string, 0, len(fk.RefColumns))
+			)
+			for _, r := range fk.RefColumns {
+				if !isLocalRef(r) {
+					_, name, err := tableName(r)
+					if err != nil {
+						continue Next
+					}
+					table = name
+				}
+				vs, err := r.ByType(typeColumn)
+				if err != nil || len(vs) != 1 {
+					continue Next
+				}
+				columns = append(columns, vs[0])
+			}
+			refs[table] = append(refs[table], columns)
+		}
+	}
+	return refs
+}
+
+// TimestampsMixin returns the "timestamps" mixin. It adds the non-nullable created_at and
+// updated_at columns of type typ to the table, that default to the now expression, and sets
+// onUpdate as the ON UPDATE expression of updated_at. The column names can be changed by the
+// "created" and "updated" attributes of the mixin block. Drivers that do not support ON UPDATE
+// expressions pass an empty onUpdate, and as updated_at cannot be maintained on updates, the
+// mixin is rejected unless it is disabled by setting the "updated" attribute to an empty string.
+func TimestampsMixin(typ *schemahcl.Type, now, onUpdate string) MixinFunc {
+	return func(m *Mixin) error {
+		created, err := mixinAttr(m.R, "created", "created_at")
+		if err != nil {
+			return err
+		}
+		updated, err := mixinAttr(m.R, "updated", "updated_at")
+		if err != nil {
+			return err
+		}
+		if updated != "" && onUpdate == "" {
+			return fmt.Errorf("column %q cannot be maintained on updates without ON UPDATE support, set updated = \"\" to add only %q", updated, created)
+		}
+		for _, c := range []string{created, updated} {
+			if c == "" {
+				continue
+			}
+			col, err := mixinColumn(m.T, c, typ)
+			if err != nil {
+				return err
+			}
+			col.Default = schemahcl.RawExprValue(&schemahcl.RawExpr{X: now})
+			if c == updated {
+				col.Extra.Attrs = append(col.Extra.Attrs, schemahcl.RawAttr("on_update", onUpdate))
+			}
+		}
+		return nil
+	}
+}
+
+// SoftDeleteMixin returns the "soft_delete" mixin. It adds the nullable deleted_at column of
+// type typ to the table. The column name can be changed by the "column" attribute of the mixin
+// block. If quote is not nil, the unique indexes of the table are made partial, and cover only
+// rows that were not deleted, with the column quoted by it in their predicates. Therefore, quote
+// should be set only by drivers that support partial indexes. Unique indexes that are referenced
+// by foreign keys are left as is, as partial indexes cannot be referenced.
+func SoftDeleteMixin(typ *schemahcl.Type, quote func(string) string) MixinFunc {
+	return func(m *Mixin) error {
+		name, err := mixinAttr(m.R, "column", "deleted_at")
+		if err != nil {
+			return err
+		}
+		col, err := mixinColumn(m.T, name, typ)
+		if err != nil {
+			return err
+		}
+		col.Null = true
+		if quote == nil {
+			return nil
+		}
+		live := fmt.Sprintf("%s IS NULL", quote(name))
+		for _, idx := range m.T.Indexes {
+			if !idx.Unique || referenced(idx, m.Refs) {
+				continue
+			}
+			p := live
+			if a, ok := idx.Attr("where"); ok {
+				w, err := a.String()
+				if err != nil {
+					return fmt.Errorf("unexpected predicate of index %q: %w", idx.Name, err)
+				}
+				p = fmt.Sprintf("(%s) AND %s", w, live)
+			}
+			idx.Extra.SetAttr(schemahcl.StringAttr("where", p))
+		}
+		return nil
+	}
+}
+
+// mixinAttr returns the string attribute of the mixin block, or the default value if missing.
+func mixinAttr(r *schemahcl.Resource, name, value string) (string, error) {
+	a, ok := r.Attr(name)
+	if !ok {
+		return value, nil
+	}
+	v, err := a.String()
+	if err != nil {
+		return "", fmt.Errorf("expect string attribute %q: %w", name, err)
+	}
+	return v, nil
+}
+
+// mixinColumn adds a new column to the table, unless a column with this name already exists.
+func mixinColumn(t *sqlspec.Table, name string, typ *schemahcl.Type) (*sqlspec.Column, error) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return nil, fmt.Errorf("column %q already exists", name)
+		}
+	}
+	c := &sqlspec.Column{Name: name, Type: &schemahcl.Type{T: typ.T, Attrs: typ.Attrs}}
+	t.Columns = append(t.Columns, c)
+	return c, nil
+}
+
+// referenced reports if the columns of the index are referenced by a foreign key.
+func referenced(idx *sqlspec.Index, refs [][]string) bool {
+	columns := idx.Columns
+	if len(columns) == 0 {
+		for _, p := range idx.Parts {
+			if p.Column == nil {
+				return false
+			}
+			columns = append(columns, p.Column)
+		}
+	}
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		vs, err := c.ByType(typeColumn)
+		if err != nil || len(vs) != 1 {
+			return false
+		}
+		names = append(names, vs[0])
+	}
+	sort.Strings(names)
+	for _, r := range refs {
+		r = append([]string(nil), r...)
+		sort.Strings(r)
+		if strings.Join(r, ",") == strings.Join(names, ",") {
+			return true
+		}
+	}
+	return false
+}
@@ -11,6 +11,7 @@ import (
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlspec"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -189,3 +190,37 @@ func HCLBytesFunc(ev schemahcl.Evaluator) func(b []byte, v any, inp map[string]c
 		return ev.Eval(parser, v, inp)
 	}
 }
+
+// HCLMergeFunc returns a helper that evaluates multiple parsed HCL documents, for example,
+// files of different teams defining tables in the same schema, and merges them into one
+// Realm. Definitions are resolved across the documents, and the merged documents are
+// validated before they are evaluated. Hence, duplicate definitions are reported with their
+// positions instead of overwriting each other, as done when a single document is evaluated.
+func HCLMergeFunc(ev schemahcl.Evaluator, validate func(*hclparse.Parser, map[string]cty.Value) hcl.Diagnostics) func(files []*hcl.File, inp map[string]cty.Value) (*schema.Realm, error) {
+	return func(files []*hcl.File, inp map[string]cty.Value) (*schema.Realm, error) {
+		parser := hclparse.NewParser()
+		for i, f := range files {
+			// Files are keyed by their name in the parser, and unnamed
+			// documents, or documents with the same name, are not merged.
+			name := f.Body.MissingItemRange().Filename
+			if _, ok := parser.Files()[name]; ok || name == "" {
+				name = fmt.Sprintf("%s#%d", name, i)
+			}
+			parser.AddFile(name, f)
+		}
+		var errs hcl.Diagnostics
+		for _, d := range validate(parser, inp) {
+			if d.Severity == hcl.DiagError {
+				errs = append(errs, d)
+			}
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		var r schema.Realm
+		if err := ev.Eval(parser, &r, inp); err != nil {
+			return nil, err
+		}
+		return &r, nil
+	}
+}
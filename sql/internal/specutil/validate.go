@@ -0,0 +1,271 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package specutil
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlspec"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Validate checks the scanned document for structural errors, such as duplicate
+// definitions, broken references or invalid types, without connecting to a database.
+// Unlike Scan, Validate does not stop on the first error and reports all errors found
+// as diagnostics, pointing to their position in the document if exists.
+func Validate(doc *ScanDoc, funcs *ScanFuncs) hcl.Diagnostics {
+	var (
+		v       validator
+		r       = schema.NewRealm()
+		byName  = make(map[string]*schema.Schema)
+		tables  = make(map[*schema.Table]*sqlspec.Table)
+		refs    = refColumns(doc.Tables)
+		schemaT = func(ref *schemahcl.Ref, typ, name string, rg *hcl.Range) (*schema.Schema, bool) {
+			n, err := SchemaName(ref)
+			if err != nil {
+				v.errorf(rg, "specutil: cannot extract schema name for %s %q: %w", typ, name, err)
+				return nil, false
+			}
+			s, ok := byName[n]
+			if !ok {
+				v.errorf(rg, "specutil: schema %q not found for %s %q", n, typ, name)
+			}
+			return s, ok
+		}
+	)
+	// Duplicate definitions are reported by checkConflicts,
+	// and only their first definition is validated below.
+	v.error(nil, checkConflicts(doc))
+	for _, s := range doc.Schemas {
+		if _, ok := byName[s.Name]; ok {
+			continue
+		}
+		v.ident(funcs.Ident, s.Range(), typeSchema, s.Name, "")
+		byName[s.Name] = schema.New(s.Name)
+		r.AddSchemas(byName[s.Name])
+	}
+	for _, st := range doc.Tables {
+		s, ok := schemaT(st.Schema, typeTable, st.Name, st.Range())
+		if !ok {
+			continue
+		}
+		if _, ok := s.Table(st.Name); ok {
+			continue
+		}
+		if err := expandMixins(st, funcs.Mixins, refs); err != nil {
+			v.error(st.Range(), err)
+			continue
+		}
+		v.uniqueTableChildren(st)
+		v.tableIdents(funcs.Ident, st)
+		t, err := funcs.Table(st, s)
+		if err != nil {
+			v.error(st.Range(), fmt.Errorf("specutil: cannot convert table %q: %w", st.Name, err))
+			continue
+		}
+		s.AddTables(t)
+		tables[t] = st
+	}
+	// Foreign keys are linked after all tables were
+	// converted, as they may reference other tables.
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			for _, fk := range tables[t].ForeignKeys {
+				if err := linkForeignKeys(t, []*sqlspec.ForeignKey{fk}, funcs); err != nil {
+					v.error(fk.Range(), err)
+				} else if funcs.WarnRefs {
+					v.warn(fk.Range(), checkRefKey(t.ForeignKeys[len(t.ForeignKeys)-1]))
+				}
+			}
+		}
+	}
+	for typ, views := range map[string][]*sqlspec.View{typeView: doc.Views, typeMaterialized: doc.Materialized} {
+		for _, sv := range views {
+			s, ok := schemaT(sv.Schema, typ, sv.Name, sv.Range())
+			if !ok {
+				continue
+			}
+			if _, ok := s.View(sv.Name); ok {
+				continue
+			}
+			unique(&v, typeColumn, sv.Name, sv.Columns, func(c *sqlspec.Column) (string, *hcl.Range) { return c.Name, c.Range() })
+			v.ident(funcs.Ident, sv.Range(), typ, sv.Name, "")
+			for _, c := range sv.Columns {
+				v.ident(funcs.Ident, c.Range(), typeColumn, c.Name, sv.Name)
+			}
+			vw, err := funcs.View(sv, s)
+			if err != nil {
+				v.error(sv.Range(), fmt.Errorf("specutil: cannot convert %s %q: %w", typ, sv.Name, err))
+				continue
+			}
+			s.AddViews(vw)
+		}
+	}
+	for typ, specs := range map[string][]*sqlspec.Func{"function": doc.Funcs, "procedure": doc.Procs} {
+		for _, sf := range specs {
+			if _, ok := schemaT(sf.Schema, typ, sf.Name, sf.Range()); !ok {
+				continue
+			}
+			switch {
+			case typ == "function" && funcs.Func != nil:
+				_, err := funcs.Func(sf)
+				v.error(sf.Range(), err)
+			case typ == "procedure" && funcs.Proc != nil:
+				_, err := funcs.Proc(sf)
+				v.error(sf.Range(), err)
+			}
+		}
+	}
+	v.sort()
+	return v.diags
+}
+
+// checkConflicts reports the schemas, tables and views that are defined more than once
+// in the document. The document can be merged from multiple files, and conflicting
+// definitions are reported with their positions rather than silently overwritten.
+func checkConflicts(doc *ScanDoc) error {
+	var (
+		errs []error
+		seen = make(map[string]*hcl.Range)
+		add  = func(r *hcl.Range, typ, name string, scope ...string) {
+			key := strings.Join(append([]string{typ, name}, scope...), ".")
+			prev, ok := seen[key]
+			if !ok {
+				seen[key] = r
+				return
+			}
+			msg := fmt.Sprintf("specutil: duplicate %s %q", typ, name)
+			if len(scope) > 0 {
+				msg += fmt.Sprintf(" in schema %q", scope[0])
+			}
+			if prev != nil {
+				msg += fmt.Sprintf(", previously defined at %s", prev)
+			}
+			errs = append(errs, schemahcl.ErrorAt(r, errors.New(msg)))
+		}
+	)
+	for _, s := range doc.Schemas {
+		add(s.Range(), typeSchema, s.Name)
+	}
+	// Schema errors are reported by the caller.
+	for _, t := range doc.Tables {
+		if name, err := SchemaName(t.Schema); err == nil {
+			add(t.Range(), typeTable, t.Name, name)
+		}
+	}
+	for _, v := range doc.Views {
+		if name, err := SchemaName(v.Schema); err == nil {
+			add(v.Range(), typeView, v.Name, name)
+		}
+	}
+	for _, m := range doc.Materialized {
+		if name, err := SchemaName(m.Schema); err == nil {
+			add(m.Range(), typeMaterialized, m.Name, name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validator collects the diagnostics found during validation.
+type validator struct {
+	diags hcl.Diagnostics
+}
+
+func (v *validator) error(r *hcl.Range, err error) {
+	if err != nil {
+		v.diags = append(v.diags, schemahcl.Diagnostics(schemahcl.ErrorAt(r, err))...)
+	}
+}
+
+func (v *validator) errorf(r *hcl.Range, format string, args ...any) {
+	v.error(r, fmt.Errorf(format, args...))
+}
+
+// warn records the error as a warning, that does not fail the validation.
+func (v *validator) warn(r *hcl.Range, err error) {
+	if err != nil {
+		diags := schemahcl.Diagnostics(schemahcl.ErrorAt(r, err))
+		for _, d := range diags {
+			d.Severity = hcl.DiagWarning
+		}
+		v.diags = append(v.diags, diags...)
+	}
+}
+
+// uniqueTableChildren reports children of the table that are defined more than once.
+func (v *validator) uniqueTableChildren(t *sqlspec.Table) {
+	unique(v, typeColumn, t.Name, t.Columns, func(c *sqlspec.Column) (string, *hcl.Range) { return c.Name, c.Range() })
+	unique(v, "index", t.Name, t.Indexes, func(i *sqlspec.Index) (string, *hcl.Range) { return i.Name, i.Range() })
+	unique(v, "foreign_key", t.Name, t.ForeignKeys, func(f *sqlspec.ForeignKey) (string, *hcl.Range) { return f.Symbol, f.Range() })
+	unique(v, "check", t.Name, t.Checks, func(c *sqlspec.Check) (string, *hcl.Range) { return c.Name, c.Range() })
+}
+
+// ident reports identifiers that exceed the dialect length limit as errors,
+// and identifiers that collide with the dialect reserved words as warnings.
+func (v *validator) ident(rules *IdentRules, r *hcl.Range, typ, name, parent string) {
+	if err := rules.Check(typ, name); err != nil {
+		v.errorf(r, "specutil: %w", err)
+	}
+	if err := rules.CheckReserved(typ, name, parent); err != nil {
+		v.warn(r, fmt.Errorf("specutil: %w", err))
+	}
+}
+
+// tableIdents reports the table identifiers that violate the dialect rules.
+func (v *validator) tableIdents(rules *IdentRules, t *sqlspec.Table) {
+	if rules == nil {
+		return
+	}
+	v.ident(rules, t.Range(), typeTable, t.Name, "")
+	for _, c := range t.Columns {
+		v.ident(rules, c.Range(), typeColumn, c.Name, t.Name)
+	}
+	for _, idx := range t.Indexes {
+		v.ident(rules, idx.Range(), "index", idx.Name, t.Name)
+	}
+	for _, fk := range t.ForeignKeys {
+		v.ident(rules, fk.Range(), "foreign_key", fk.Symbol, t.Name)
+	}
+	for _, c := range t.Checks {
+		v.ident(rules, c.Range(), "check", c.Name, t.Name)
+	}
+}
+
+// unique reports elements with the same name. Unnamed elements are ignored.
+func unique[T any](v *validator, typ, parent string, elems []T, key func(T) (string, *hcl.Range)) {
+	seen := make(map[string]bool, len(elems))
+	for _, e := range elems {
+		name, r := key(e)
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			v.errorf(r, "specutil: duplicate %s %q in %q", typ, name, parent)
+		}
+		seen[name] = true
+	}
+}
+
+// sort sorts the diagnostics by their position in the document,
+// as the order of Go maps iteration is not deterministic.
+func (v *validator) sort() {
+	sort.SliceStable(v.diags, func(i, j int) bool {
+		si, sj := v.diags[i].Subject, v.diags[j].Subject
+		switch {
+		case si == nil || sj == nil:
+			return si != nil
+		case si.Filename != sj.Filename:
+			return si.Filename < sj.Filename
+		default:
+			return si.Start.Byte < sj.Start.Byte
+		}
+	})
+}
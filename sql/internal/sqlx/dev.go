@@ -6,6 +6,7 @@ package sqlx
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	"ariga.io/atlas/sql/migrate"
@@ -78,10 +79,12 @@ func (d *DevDriver) NormalizeRealm(ctx context.Context, r *schema.Realm) (nr *sc
 		for _, p := range s.Procs {
 			changes = append(changes, &schema.AddProc{P: p})
 		}
+		changes = append(changes, addTriggers(s)...)
 	}
 	if err := d.Driver.ApplyChanges(ctx, changes); err != nil {
 		return nil, err
 	}
+	opts.Triggers = hasTriggers(changes)
 	nr, err = d.Driver.InspectRealm(ctx, opts)
 	return
 }
@@ -150,6 +153,7 @@ func (d *DevDriver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*sch
 	for _, p := range s.Procs {
 		changes = append(changes, &schema.AddProc{P: p})
 	}
+	changes = append(changes, addTriggers(s)...)
 	if err := d.Driver.ApplyChanges(ctx, changes, func(opts *migrate.PlanOptions) {
 		noQualifier := ""
 		opts.SchemaQualifier = &noQualifier
@@ -157,7 +161,7 @@ func (d *DevDriver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*sch
 	}); err != nil {
 		return nil, err
 	}
-	ns, err := d.Driver.InspectSchema(ctx, "", nil)
+	ns, err := d.Driver.InspectSchema(ctx, "", &schema.InspectOptions{Triggers: hasTriggers(changes)})
 	if err != nil {
 		return nil, err
 	}
@@ -168,3 +172,124 @@ func (d *DevDriver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*sch
 	}
 	return ns, err
 }
+
+// addTriggers returns the changes for creating the triggers of the schema tables.
+func addTriggers(s *schema.Schema) []schema.Change {
+	var changes []schema.Change
+	for _, t := range s.Tables {
+		for _, tr := range t.Triggers {
+			changes = append(changes, &schema.AddTrigger{T: tr})
+		}
+	}
+	return changes
+}
+
+// hasTriggers reports if the changes create triggers. Triggers are inspected only when
+// requested, and therefore, the normalized state includes them only if they were created.
+func hasTriggers(changes []schema.Change) bool {
+	for _, c := range changes {
+		if _, ok := c.(*schema.AddTrigger); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// InferViewColumns infers the columns of the views in the realm that were defined without
+// columns (e.g. in HCL). The realm objects are created temporarily in the dev database, and
+// the columns of each view are read from the result metadata of an empty query on the view.
+// The builder is used for writing these statements, and conv converts the column types that
+// are reported by the database driver to schema types.
+func (d *DevDriver) InferViewColumns(ctx context.Context, r *schema.Realm, b *Builder, conv func(*sql.ColumnType) (schema.Type, error)) (err error) {
+	var infer bool
+	for _, s := range r.Schemas {
+		for _, v := range s.Views {
+			infer = infer || len(v.Columns) == 0
+		}
+	}
+	if !infer {
+		return nil
+	}
+	var views []*schema.View
+	for _, s := range r.Schemas {
+		views = append(views, s.Views...)
+	}
+	if views, err = SortViews(views); err != nil {
+		return err
+	}
+	if err := d.Driver.CheckClean(ctx, nil); err != nil {
+		return err
+	}
+	restore, err := d.Driver.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rerr := restore(ctx); rerr != nil {
+			if err != nil {
+				rerr = fmt.Errorf("%w: %v", err, rerr)
+			}
+			err = rerr
+		}
+	}()
+	var changes []schema.Change
+	for _, s := range r.Schemas {
+		changes = append(changes, &schema.AddSchema{S: s, Extra: []schema.Clause{&schema.IfNotExists{}}})
+		for _, o := range s.Objects {
+			changes = append(changes, &schema.AddObject{O: o})
+		}
+		for _, t := range s.Tables {
+			changes = append(changes, &schema.AddTable{T: t})
+		}
+		for _, f := range s.Funcs {
+			changes = append(changes, &schema.AddFunc{F: f})
+		}
+	}
+	if err := d.Driver.ApplyChanges(ctx, changes); err != nil {
+		return err
+	}
+	for _, v := range views {
+		if _, err := d.Driver.ExecContext(ctx, b.Clone().P("CREATE VIEW").View(v).P("AS").String()+" "+v.Def); err != nil {
+			return fmt.Errorf("create view %q in dev database: %w", v.Name, err)
+		}
+		if len(v.Columns) > 0 {
+			continue
+		}
+		columns, err := d.viewColumns(ctx, b.Clone().P("SELECT * FROM").View(v).P("LIMIT 0").String(), conv)
+		if err != nil {
+			return fmt.Errorf("infer columns of view %q: %w", v.Name, err)
+		}
+		v.AddColumns(columns...)
+	}
+	return nil
+}
+
+// viewColumns returns the columns of the given query result.
+func (d *DevDriver) viewColumns(ctx context.Context, query string, conv func(*sql.ColumnType) (schema.Type, error)) ([]*schema.Column, error) {
+	rows, err := d.Driver.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]*schema.Column, 0, len(types))
+	for _, ct := range types {
+		t, err := conv(ct)
+		if err != nil {
+			return nil, fmt.Errorf("convert type of column %q: %w", ct.Name(), err)
+		}
+		// Unless reported otherwise, view columns are nullable.
+		null, ok := ct.Nullable()
+		if !ok {
+			null = true
+		}
+		columns = append(columns, &schema.Column{
+			Name: ct.Name(),
+			Type: &schema.ColumnType{Type: t, Raw: ct.DatabaseTypeName(), Null: null},
+		})
+	}
+	return columns, rows.Err()
+}
@@ -6,11 +6,15 @@ package sqlx
 
 import (
 	"context"
+	"database/sql"
+	"regexp"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -38,6 +42,49 @@ func TestDriver_NormalizeRealm(t *testing.T) {
 	}, drv.changes[0])
 }
 
+func TestDriver_InferViewColumns(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	var (
+		drv = &struct {
+			*sql.DB
+			*mockDriver
+		}{DB: db, mockDriver: &mockDriver{}}
+		dev   = &DevDriver{Driver: drv}
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		v1    = schema.NewView("v1", "SELECT id, name FROM users")
+		v2    = schema.NewView("v2", "SELECT id FROM v1").AddColumns(schema.NewIntColumn("id", "int")).AddDeps(v1)
+		r     = schema.NewRealm(schema.New("test").AddTables(users).AddViews(v2, v1))
+		conv  = func(ct *sql.ColumnType) (schema.Type, error) {
+			return &schema.StringType{T: strings.ToLower(ct.DatabaseTypeName())}, nil
+		}
+	)
+	m.ExpectExec(regexp.QuoteMeta("CREATE VIEW `test`.`v1` AS SELECT id, name FROM users")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `test`.`v1` LIMIT 0")).
+		WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+			sqlmock.NewColumn("id").OfType("INT", int64(0)).Nullable(false),
+			sqlmock.NewColumn("name").OfType("TEXT", ""),
+		))
+	m.ExpectExec(regexp.QuoteMeta("CREATE VIEW `test`.`v2` AS SELECT id FROM v1")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, dev.InferViewColumns(context.Background(), r, &Builder{QuoteOpening: '`', QuoteClosing: '`'}, conv))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, []schema.Change{
+		&schema.AddSchema{S: r.Schemas[0], Extra: []schema.Clause{&schema.IfNotExists{}}},
+		&schema.AddTable{T: users},
+	}, drv.changes)
+	require.Len(t, v1.Columns, 2)
+	require.Equal(t, &schema.ColumnType{Type: &schema.StringType{T: "int"}, Raw: "INT"}, v1.Columns[0].Type)
+	require.Equal(t, &schema.ColumnType{Type: &schema.StringType{T: "text"}, Raw: "TEXT", Null: true}, v1.Columns[1].Type)
+	require.Len(t, v2.Columns, 1, "defined columns are kept")
+
+	// Cyclic dependencies.
+	v1.AddDeps(v2)
+	err = dev.InferViewColumns(context.Background(), schema.NewRealm(schema.New("test").AddViews(schema.NewView("v3", "SELECT 1"), v1, v2)), &Builder{}, conv)
+	require.EqualError(t, err, `cyclic dependency of view "v1"`)
+}
+
 type mockDriver struct {
 	migrate.Driver
 	// Inspect.
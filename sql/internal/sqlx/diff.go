@@ -87,6 +87,30 @@ type (
 		AnnotateChanges([]schema.Change, *schema.DiffOptions) error
 	}
 
+	// ForeignKeyAttrDiffer is an optional interface allows DiffDriver to report changes
+	// to the driver-specific attributes of foreign keys. For example, the MATCH type.
+	ForeignKeyAttrDiffer interface {
+		ForeignKeyAttrChanged(from, to []schema.Attr) bool
+	}
+
+	// ColumnMover is an optional interface allows DiffDriver to report changes to the
+	// positions of columns. If DiffOptions.ColumnOrder is enabled, drivers that implement
+	// it report moved columns as ModifyColumn changes with the schema.ChangePosition kind.
+	// Others ignore changes to column order.
+	ColumnMover interface {
+		// SupportsColumnMove reports if the driver can move columns of existing tables.
+		SupportsColumnMove() bool
+	}
+
+	// IndexExprCanonicalizer is an optional interface allows DiffDriver to compare index
+	// expressions by their canonical form. For example, to ignore the type casts that are
+	// added by the database to user-written expressions.
+	IndexExprCanonicalizer interface {
+		// CanonicalIndexExpr returns the canonical form of the given index expression
+		// of the table.
+		CanonicalIndexExpr(*schema.Table, string) string
+	}
+
 	// ProcFuncsDiffer is an optional interface allows DiffDriver to diff
 	// functions and procedures.
 	ProcFuncsDiffer interface {
@@ -106,6 +130,13 @@ func (d *Diff) RealmDiff(from, to *schema.Realm, options ...schema.DiffOption) (
 	// Drop or modify schema.
 	for _, s1 := range from.Schemas {
 		s2, ok := to.Schema(s1.Name)
+		// An unqualified schema in the desired state is
+		// resolved using the search path, if configured.
+		if s, ok1 := to.Schema(""); !ok && ok1 && len(opts.SearchPath) > 0 && searchSchema(opts, from) == s1.Name {
+			c := *s
+			c.Name = s1.Name
+			s2, ok = &c, true
+		}
 		if !ok {
 			changes = opts.AddOrSkip(changes, &schema.DropSchema{S: s1})
 			continue
@@ -121,6 +152,11 @@ func (d *Diff) RealmDiff(from, to *schema.Realm, options ...schema.DiffOption) (
 		if _, ok := from.Schema(s1.Name); ok {
 			continue
 		}
+		if s1.Name == "" && len(opts.SearchPath) > 0 {
+			if _, ok := from.Schema(searchSchema(opts, from)); ok {
+				continue
+			}
+		}
 		changes = opts.AddOrSkip(changes, &schema.AddSchema{S: s1})
 		for _, t := range s1.Tables {
 			changes = opts.AddOrSkip(changes, &schema.AddTable{T: t})
@@ -162,6 +198,13 @@ func (d *Diff) schemaDiff(from, to *schema.Schema, opts *schema.DiffOptions) ([]
 	}
 	changes = opts.AddOrSkip(changes, change...)
 
+	// Rename tables resolved by the user.
+	from, renames, err := d.resolveTables(from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, renames...)
+
 	// Drop or modify tables.
 	for _, t1 := range from.Tables {
 		switch t2, err := d.findTable(to, t1.Name); {
@@ -174,6 +217,15 @@ func (d *Diff) schemaDiff(from, to *schema.Schema, opts *schema.DiffOptions) ([]
 			if err != nil {
 				return nil, err
 			}
+			// Column renames are planned separately, as some databases
+			// do not allow modifying renamed columns in the same statement.
+			if i := renamesIndex(change); i > 0 && i < len(change) {
+				changes = opts.AddOrSkip(changes, &schema.ModifyTable{
+					T:       t2,
+					Changes: change[:i],
+				})
+				change = change[i:]
+			}
 			if len(change) > 0 {
 				changes = opts.AddOrSkip(changes, &schema.ModifyTable{
 					T:       t2,
@@ -219,9 +271,79 @@ func (d *Diff) schemaDiff(from, to *schema.Schema, opts *schema.DiffOptions) ([]
 		}
 		changes = append(changes, change...)
 	}
+	// Add, drop and modify triggers.
+	change, err = d.triggerDiff(from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, change...), nil
+}
+
+// triggerDiff returns the changes for migrating the triggers of the schema tables.
+// Triggers of dropped tables are dropped along with them, and are not reported.
+func (d *Diff) triggerDiff(from, to *schema.Schema, opts *schema.DiffOptions) ([]schema.Change, error) {
+	var changes []schema.Change
+	for _, t2 := range to.Tables {
+		t1, err := d.findTable(from, t2.Name)
+		switch {
+		case schema.IsNotExistError(err):
+			for _, tr := range t2.Triggers {
+				changes = opts.AddOrSkip(changes, &schema.AddTrigger{T: tr})
+			}
+			continue
+		case err != nil:
+			return nil, err
+		}
+		for _, tr1 := range t1.Triggers {
+			switch tr2, ok := t2.Trigger(tr1.Name); {
+			case !ok:
+				changes = opts.AddOrSkip(changes, &schema.DropTrigger{T: tr1})
+			case d.triggerChanged(tr1, tr2):
+				changes = opts.AddOrSkip(changes, &schema.ModifyTrigger{From: tr1, To: tr2})
+			}
+		}
+		for _, tr2 := range t2.Triggers {
+			if _, ok := t1.Trigger(tr2.Name); !ok {
+				changes = opts.AddOrSkip(changes, &schema.AddTrigger{T: tr2})
+			}
+		}
+	}
 	return changes, nil
 }
 
+// triggerChanged checks if the trigger definition has changed.
+// It allows the DiffDriver to override the default implementation.
+func (d *Diff) triggerChanged(t1, t2 *schema.Trigger) bool {
+	if tr, ok := d.DiffDriver.(interface {
+		TriggerChanged(t1, t2 *schema.Trigger) bool
+	}); ok {
+		return tr.TriggerChanged(t1, t2)
+	}
+	return TriggerChanged(t1, t2)
+}
+
+// TriggerChanged reports if the action time, events, granularity or body of the trigger
+// were changed. Bodies are compared after normalizing their case, whitespace and the
+// terminating semicolons.
+func TriggerChanged(t1, t2 *schema.Trigger) bool {
+	if !strings.EqualFold(string(t1.ActionTime), string(t2.ActionTime)) || !strings.EqualFold(string(t1.For), string(t2.For)) || len(t1.Events) != len(t2.Events) {
+		return true
+	}
+	events := make(map[string]bool, len(t2.Events))
+	for _, e := range t2.Events {
+		events[strings.ToUpper(string(e))] = true
+	}
+	for _, e := range t1.Events {
+		if !events[strings.ToUpper(string(e))] {
+			return true
+		}
+	}
+	body := func(b string) string {
+		return strings.TrimRight(strings.TrimSpace(b), ";")
+	}
+	return !ExprEqual(body(t1.Body), body(t2.Body))
+}
+
 // TableDiff implements the schema.TableDiffer interface and returns a list of
 // changes that need to be applied in order to move from one state to the other.
 func (d *Diff) TableDiff(from, to *schema.Table, options ...schema.DiffOption) ([]schema.Change, error) {
@@ -241,8 +363,9 @@ func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]sc
 	// tableDiff can be called with non-identical
 	// names without affecting the diff process.
 	if name := from.Name; name != to.Name {
-		from.Name = to.Name
-		defer func() { from.Name = name }()
+		t := from
+		t.Name = to.Name
+		defer func() { t.Name = name }()
 	}
 	// Normalizing tables before starting the diff process.
 	if n, ok := d.DiffDriver.(Normalizer); ok {
@@ -250,7 +373,11 @@ func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]sc
 			return nil, err
 		}
 	}
-	var changes []schema.Change
+	// Rename columns resolved by the user.
+	from, changes, err := d.resolveColumns(from, to, opts)
+	if err != nil {
+		return nil, err
+	}
 	// Drop or modify attributes (collations, checks, etc).
 	change, err := d.TableAttrDiff(from, to)
 	if err != nil {
@@ -259,6 +386,11 @@ func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]sc
 	changes = append(changes, change...)
 
 	// Drop or modify columns.
+	var (
+		order = d.columnOrder(opts)
+		moved = d.movedColumns(from, to, order)
+		moves = make(map[string]*schema.ModifyColumn, len(moved))
+	)
 	for _, c1 := range from.Columns {
 		c2, ok := to.Column(c1.Name)
 		if !ok {
@@ -269,7 +401,19 @@ func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]sc
 		if err != nil {
 			return nil, err
 		}
-		if change != schema.NoChange {
+		if opts.ExactTypes && !change.Is(schema.ChangeType) && spellingChanged(c1.Type.Type, c2.Type.Type) {
+			change |= schema.ChangeType
+		}
+		switch {
+		// Moved columns are planned in their desired order, as
+		// each one is placed after its preceding column.
+		case moved[c1.Name]:
+			moves[c1.Name] = &schema.ModifyColumn{
+				From:   c1,
+				To:     c2,
+				Change: change | schema.ChangePosition,
+			}
+		case change != schema.NoChange:
 			changes = opts.AddOrSkip(changes, &schema.ModifyColumn{
 				From:   c1,
 				To:     c2,
@@ -277,12 +421,19 @@ func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]sc
 			})
 		}
 	}
+	for _, c2 := range to.Columns {
+		if m, ok := moves[c2.Name]; ok {
+			changes = opts.AddOrSkip(changes, m)
+		}
+	}
 	// Add columns.
 	for _, c1 := range to.Columns {
 		if _, ok := from.Column(c1.Name); !ok {
-			changes = opts.AddOrSkip(changes, &schema.AddColumn{
-				C: c1,
-			})
+			add := &schema.AddColumn{C: c1}
+			if order {
+				add.Extra = append(add.Extra, &schema.ColumnPosition{})
+			}
+			changes = opts.AddOrSkip(changes, add)
 		}
 	}
 
@@ -297,7 +448,7 @@ func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]sc
 			changes = opts.AddOrSkip(changes, &schema.DropForeignKey{F: fk1})
 			continue
 		}
-		if change := d.fkChange(fk1, fk2); change != schema.NoChange {
+		if change := d.fkChange(fk1, fk2, opts); change != schema.NoChange {
 			changes = opts.AddOrSkip(changes, &schema.ModifyForeignKey{
 				From:   fk1,
 				To:     fk2,
@@ -314,6 +465,61 @@ func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]sc
 	return changes, nil
 }
 
+// columnOrder reports if changes to the order of columns should be planned.
+func (d *Diff) columnOrder(opts *schema.DiffOptions) bool {
+	m, ok := d.DiffDriver.(ColumnMover)
+	return ok && opts.ColumnOrder && m.SupportsColumnMove()
+}
+
+// movedColumns returns the names of the columns that should be moved in order to bring
+// the columns of the "from" table into their order in the "to" table. The columns that keep
+// their relative order are those in the longest increasing subsequence of their positions.
+func (d *Diff) movedColumns(from, to *schema.Table, order bool) map[string]bool {
+	if !order {
+		return nil
+	}
+	pos := make(map[string]int, len(from.Columns))
+	for i, c := range from.Columns {
+		pos[c.Name] = i
+	}
+	var common []*schema.Column
+	for _, c := range to.Columns {
+		if _, ok := pos[c.Name]; ok {
+			common = append(common, c)
+		}
+	}
+	// tails[k] holds the index (in common) of the smallest tail
+	// of all increasing subsequences with length k+1.
+	var (
+		tails []int
+		prev  = make([]int, len(common))
+	)
+	for i, c := range common {
+		k := sort.Search(len(tails), func(j int) bool {
+			return pos[common[tails[j]].Name] >= pos[c.Name]
+		})
+		if prev[i] = -1; k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	if len(tails) == len(common) {
+		return nil
+	}
+	moved := make(map[string]bool, len(common)-len(tails))
+	for _, c := range common {
+		moved[c.Name] = true
+	}
+	for i := tails[len(tails)-1]; i != -1; i = prev[i] {
+		delete(moved, common[i].Name)
+	}
+	return moved
+}
+
 func (d *Diff) mayAnnotate(changes []schema.Change, opts *schema.DiffOptions) ([]schema.Change, error) {
 	r, ok := d.DiffDriver.(ChangesAnnotator)
 	if ok {
@@ -468,7 +674,7 @@ func (d *Diff) partsChange(fromI, toI *schema.Index) schema.ChangeKind {
 			}
 		case from[i].X != nil && to[i].X != nil:
 			x1, x2 := from[i].X.(*schema.RawExpr).X, to[i].X.(*schema.RawExpr).X
-			if x1 != x2 && x1 != MayWrap(x2) {
+			if x1 != x2 && x1 != MayWrap(x2) && !d.indexExprEqual(fromI.Table, x1, toI.Table, x2) {
 				return schema.ChangeParts
 			}
 		default: // (C1 != nil) != (C2 != nil) || (X1 != nil) != (X2 != nil).
@@ -478,12 +684,21 @@ func (d *Diff) partsChange(fromI, toI *schema.Index) schema.ChangeKind {
 	return schema.NoChange
 }
 
+// indexExprEqual reports if the two index expressions are equal by their
+// canonical form, if the driver supports it.
+func (d *Diff) indexExprEqual(t1 *schema.Table, x1 string, t2 *schema.Table, x2 string) bool {
+	c, ok := d.DiffDriver.(IndexExprCanonicalizer)
+	return ok && c.CanonicalIndexExpr(t1, x1) == c.CanonicalIndexExpr(t2, x2)
+}
+
 // fkChange returns the schema changes (if any) for migrating one index to the other.
-func (d *Diff) fkChange(from, to *schema.ForeignKey) schema.ChangeKind {
+func (d *Diff) fkChange(from, to *schema.ForeignKey, opts *schema.DiffOptions) schema.ChangeKind {
 	var change schema.ChangeKind
 	switch {
 	case from.Table.Name != to.Table.Name:
 		change |= schema.ChangeRefTable | schema.ChangeRefColumn
+	case refSchemaChanged(from.RefTable, to.RefTable, opts):
+		change |= schema.ChangeRefTable | schema.ChangeRefColumn
 	case len(from.RefColumns) != len(to.RefColumns):
 		change |= schema.ChangeRefColumn
 	default:
@@ -509,9 +724,55 @@ func (d *Diff) fkChange(from, to *schema.ForeignKey) schema.ChangeKind {
 	if d.ReferenceChanged(from.OnDelete, to.OnDelete) {
 		change |= schema.ChangeDeleteAction
 	}
+	if f, ok := d.DiffDriver.(ForeignKeyAttrDiffer); ok && f.ForeignKeyAttrChanged(from.Attrs, to.Attrs) {
+		change |= schema.ChangeAttr
+	}
 	return change
 }
 
+// searchSchema returns the schema that unqualified objects are resolved to. That is,
+// the first schema in the search path that exists in the realm, or the first one in
+// the path if none exists.
+func searchSchema(opts *schema.DiffOptions, r *schema.Realm) string {
+	for _, name := range opts.SearchPath {
+		if _, ok := r.Schema(name); ok {
+			return name
+		}
+	}
+	return opts.SearchPath[0]
+}
+
+// refSchemaChanged reports if the referenced tables of a foreign key reside in different
+// schemas, after resolving unqualified tables using the search path. It is checked only if
+// the search path is configured, and unresolved tables are considered unchanged.
+func refSchemaChanged(from, to *schema.Table, opts *schema.DiffOptions) bool {
+	if len(opts.SearchPath) == 0 || from == nil || to == nil {
+		return false
+	}
+	s1, s2 := refSchema(from, opts), refSchema(to, opts)
+	return s1 != "" && s2 != "" && s1 != s2
+}
+
+// refSchema returns the schema name of a referenced table. Unqualified tables are resolved
+// to the first schema in the search path that contains a table with the same name.
+func refSchema(t *schema.Table, opts *schema.DiffOptions) string {
+	switch {
+	case t.Schema == nil:
+		return ""
+	case t.Schema.Name != "":
+		return t.Schema.Name
+	case t.Schema.Realm != nil:
+		for _, name := range opts.SearchPath {
+			if s, ok := t.Schema.Realm.Schema(name); ok {
+				if _, ok := s.Table(t.Name); ok {
+					return name
+				}
+			}
+		}
+	}
+	return ""
+}
+
 // similarUnnamedIndex searches for an unnamed index with the same index-parts in the table.
 func (d *Diff) similarUnnamedIndex(t *schema.Table, idx1 *schema.Index) (*schema.Index, bool) {
 	match := func(idx1, idx2 *schema.Index) bool {
@@ -543,6 +804,17 @@ func (d *Diff) findTable(s *schema.Schema, name string) (*schema.Table, error) {
 	return t, nil
 }
 
+// renamesIndex returns the index of the first change
+// that follows the column renames in the given changes.
+func renamesIndex(changes []schema.Change) int {
+	for i, c := range changes {
+		if _, ok := c.(*schema.RenameColumn); !ok {
+			return i
+		}
+	}
+	return len(changes)
+}
+
 // CommentChange reports if the element comment was changed.
 func CommentChange(from, to []schema.Attr) schema.ChangeKind {
 	var c1, c2 schema.Comment
@@ -552,6 +824,24 @@ func CommentChange(from, to []schema.Attr) schema.ChangeKind {
 	return schema.NoChange
 }
 
+// spellingChanged reports if the numeric types are spelled differently, although
+// the driver may consider them equivalent (e.g., DECIMAL and NUMERIC). It is used
+// when the diff is configured to compare the exact spelling of types.
+func spellingChanged(from, to schema.Type) bool {
+	switch from := from.(type) {
+	case *schema.IntegerType:
+		to, ok := to.(*schema.IntegerType)
+		return ok && (!strings.EqualFold(from.T, to.T) || from.Unsigned != to.Unsigned)
+	case *schema.DecimalType:
+		to, ok := to.(*schema.DecimalType)
+		return ok && (!strings.EqualFold(from.T, to.T) || from.Precision != to.Precision || from.Scale != to.Scale || from.Unsigned != to.Unsigned)
+	case *schema.FloatType:
+		to, ok := to.(*schema.FloatType)
+		return ok && (!strings.EqualFold(from.T, to.T) || from.Precision != to.Precision || from.Unsigned != to.Unsigned)
+	}
+	return false
+}
+
 // Charset reports if the attribute contains the "charset" attribute,
 // and it needs to be defined explicitly on the schema. This is true, in
 // case the element charset is different from its parent charset.
@@ -0,0 +1,165 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Equivalents holds groups of expressions that are equivalent in a dialect, although
+// they are written differently. For example, CURRENT_TIMESTAMP and now() in PostgreSQL.
+// It is used by the drivers to avoid reporting changes between equivalent column defaults,
+// that are normalized by the database on inspection.
+type Equivalents struct {
+	mu sync.RWMutex
+	// normalized expression to its group.
+	group map[string]int
+	next  int
+}
+
+// NewEquivalents returns a new Equivalents registry with the given groups.
+func NewEquivalents(groups ...[]string) *Equivalents {
+	e := &Equivalents{group: make(map[string]int)}
+	for _, g := range groups {
+		e.Register(g...)
+	}
+	return e
+}
+
+// Register registers the given expressions as equivalent. If one of the expressions
+// was already registered, the other expressions are added to its group.
+func (e *Equivalents) Register(exprs ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	g := -1
+	for _, x := range exprs {
+		if i, ok := e.group[normalizeExpr(x)]; ok {
+			g = i
+			break
+		}
+	}
+	if g == -1 {
+		g = e.next
+		e.next++
+	}
+	for _, x := range exprs {
+		e.group[normalizeExpr(x)] = g
+	}
+}
+
+// Equal reports if the two expressions were registered as equivalent. Expressions
+// are matched after normalization, ignoring case and whitespace differences.
+func (e *Equivalents) Equal(x, y string) bool {
+	x, y = normalizeExpr(x), normalizeExpr(y)
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	g1, ok1 := e.group[x]
+	g2, ok2 := e.group[y]
+	return ok1 && ok2 && g1 == g2
+}
+
+// Canonicalizer computes the canonical form of expressions in a dialect. It is used by
+// the drivers to compare expressions written by users with their form as normalized by
+// the database on inspection. For example, index expressions in PostgreSQL. Note, the
+// canonical form is used only for comparison, and it is not a valid SQL expression.
+type Canonicalizer struct {
+	mu  sync.RWMutex
+	fns []func(string) string
+}
+
+// NewCanonicalizer returns a new Canonicalizer with the given rewrite functions.
+func NewCanonicalizer(fns ...func(string) string) *Canonicalizer {
+	return &Canonicalizer{fns: fns}
+}
+
+// Register registers the given rewrite functions. Functions are applied in the order
+// they were registered, before the expression is normalized.
+func (c *Canonicalizer) Register(fns ...func(string) string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fns = append(c.fns, fns...)
+}
+
+// Canonical returns the canonical form of the given expression. The registered functions
+// are applied first, and then case, whitespace and wrapping parentheses are normalized.
+func (c *Canonicalizer) Canonical(x string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, f := range c.fns {
+		x = f(x)
+	}
+	return normalizeExpr(x)
+}
+
+// Equal reports if the two expressions have the same canonical form.
+func (c *Canonicalizer) Equal(x, y string) bool {
+	return x == y || c.Canonical(x) == c.Canonical(y)
+}
+
+// ExprEqual reports if the two expressions are equal after normalization, ignoring
+// case, whitespace and wrapping parentheses differences. For example, a partial-index
+// predicate written by the user, and its form as inspected from the database.
+func ExprEqual(x, y string) bool {
+	return normalizeExpr(x) == normalizeExpr(y)
+}
+
+// normalizeExpr lowercases the expression, drops redundant whitespace and the
+// parentheses that wrap the entire expression. Quoted strings are kept as-is.
+func normalizeExpr(x string) string {
+	var (
+		b           strings.Builder
+		quote, last rune
+		space       bool
+	)
+	for _, r := range strings.TrimSpace(x) {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		default:
+			r = unicode.ToLower(r)
+		}
+		// Whitespace is kept only between two word characters.
+		if space && isWordChar(r) && isWordChar(last) {
+			b.WriteByte(' ')
+		}
+		space, last = false, r
+		b.WriteRune(r)
+	}
+	s := b.String()
+	for len(s) > 1 && s[0] == '(' && s[len(s)-1] == ')' && wrapped(s) {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+// wrapped reports if the first parenthesis of s is closed by its last one.
+func wrapped(s string) bool {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i < len(s)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || r == '\'' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
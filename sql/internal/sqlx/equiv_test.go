@@ -0,0 +1,70 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEquivalents(t *testing.T) {
+	e := NewEquivalents(
+		[]string{"CURRENT_TIMESTAMP", "now()"},
+		[]string{"now() AT TIME ZONE 'utc'", "timezone('utc', now())"},
+	)
+	require.True(t, e.Equal("CURRENT_TIMESTAMP", "now()"))
+	require.True(t, e.Equal("current_timestamp", "NOW( )"))
+	require.True(t, e.Equal("(now() at  time zone 'utc')", "timezone('utc',now())"))
+	require.False(t, e.Equal("now() AT TIME ZONE 'UTC'", "timezone('utc', now())"), "quoted strings are case-sensitive")
+	require.False(t, e.Equal("now()", "timezone('utc', now())"))
+	require.False(t, e.Equal("'a'", "'A'"), "unregistered expressions")
+
+	// Extend an existing group.
+	e.Register("transaction_timestamp()", "now()")
+	require.True(t, e.Equal("transaction_timestamp()", "CURRENT_TIMESTAMP"))
+	e.Register("gen_random_uuid()", "uuid_generate_v4()")
+	require.True(t, e.Equal("gen_random_uuid()", "uuid_generate_v4()"))
+	require.False(t, e.Equal("gen_random_uuid()", "now()"))
+}
+
+func TestNormalizeExpr(t *testing.T) {
+	for x, want := range map[string]string{
+		"NOW()":                           "now()",
+		"(now())":                         "now()",
+		"((a) + (b))":                     "(a)+(b)",
+		"(a) + (b)":                       "(a)+(b)",
+		"now() AT TIME ZONE 'UTC'":        "now()at time zone 'UTC'",
+		"timezone( 'utc'::text , now() )": "timezone('utc'::text,now())",
+		`"Name" || 'A  B'`:                `"Name"||'A  B'`,
+	} {
+		require.Equal(t, want, normalizeExpr(x), x)
+	}
+}
+
+func TestExprEqual(t *testing.T) {
+	require.True(t, ExprEqual("deleted_at IS NULL", "(deleted_at is null)"))
+	require.True(t, ExprEqual("a = 1 AND b IN (1, 2)", "((a=1 and b in(1,2)))"))
+	require.True(t, ExprEqual("active\n\tAND NOT banned", "active AND NOT banned"))
+	require.False(t, ExprEqual("name = 'A'", "name = 'a'"))
+	require.False(t, ExprEqual(`"Name" IS NULL`, `"name" IS NULL`))
+	require.False(t, ExprEqual("a IS NULL", "a IS NOT NULL"))
+}
+
+func TestCanonicalizer(t *testing.T) {
+	c := NewCanonicalizer(func(x string) string {
+		return strings.ReplaceAll(x, "(name)::text", "name")
+	})
+	require.True(t, c.Equal("lower(name)", "(lower((name)::text))"), "casts are removed and parentheses are normalized")
+	require.True(t, c.Equal("LOWER( name )", "lower(name)"))
+	require.False(t, c.Equal("my_lower(name)", "public.my_lower(name)"))
+	c.Register(func(x string) string {
+		return strings.ReplaceAll(x, "public.", "")
+	})
+	require.True(t, c.Equal("my_lower(name)", "public.my_lower((name)::text)"))
+	require.Equal(t, "my_lower(name)", c.Canonical("(public.my_lower((name)::text))"))
+	require.False(t, c.Equal("my_lower(name)", "PUBLIC.my_lower((name)::text)"), "rewrites are applied before normalization")
+}
@@ -6,7 +6,9 @@ package sqlx
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"sort"
@@ -256,7 +258,8 @@ func dependencies(changes []schema.Change) (map[string][]*schema.Table, error) {
 					if err := checkFK(c.F); err != nil {
 						return nil, err
 					}
-					if isDropped(changes, c.F.RefTable) {
+					// Keys cannot be dropped while foreign keys reference them.
+					if isDropped(changes, c.F.RefTable) || (c.F.RefTable != change.T && isKeyDropped(changes, c.F)) {
 						deps[c.F.RefTable.Name] = append(deps[c.F.RefTable.Name], c.F.Table)
 					}
 				}
@@ -309,6 +312,69 @@ func isDropped(changes []schema.Change, t *schema.Table) bool {
 	return false
 }
 
+// isKeyDropped reports if the unique key referenced by the foreign key
+// is dropped (or rebuilt) by one of the changes of its referenced table.
+func isKeyDropped(changes []schema.Change, fk *schema.ForeignKey) bool {
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok || m.T.Name != fk.RefTable.Name {
+			continue
+		}
+		for _, c := range m.Changes {
+			var idx *schema.Index
+			switch c := c.(type) {
+			case *schema.DropIndex:
+				idx = c.I
+			case *schema.ModifyIndex:
+				idx = c.From
+			}
+			if idx != nil && idx.Unique && KeyOf(idx, fk.RefColumns) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PartialAttr is implemented by index attributes that limit the index
+// to a subset of the table rows, such as the predicates of partial indexes.
+type PartialAttr interface {
+	schema.Attr
+	Partial() bool
+}
+
+// Partial reports if the index is limited to a subset of the table rows.
+func Partial(idx *schema.Index) bool {
+	for _, a := range idx.Attrs {
+		if p, ok := a.(PartialAttr); ok && p.Partial() {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyOf reports if the index is defined exactly on the given columns, regardless of their
+// order. For example, it is used to find the unique key referenced by a foreign key. Partial
+// indexes and indexes on expressions are not keys of their columns.
+func KeyOf(idx *schema.Index, columns []*schema.Column) bool {
+	if len(idx.Parts) != len(columns) || Partial(idx) {
+		return false
+	}
+	for _, p := range idx.Parts {
+		if p.C == nil {
+			return false
+		}
+		var found bool
+		for _, c := range columns {
+			found = found || c.Name == p.C.Name
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 // CheckChangesScope checks that changes can be applied
 // on a schema scope (connection).
 func CheckChangesScope(opts migrate.PlanOptions, changes []schema.Change) error {
@@ -360,6 +426,128 @@ func CheckChangesScope(opts migrate.PlanOptions, changes []schema.Change) error
 	return nil
 }
 
+// CheckUnquoted checks that the identifiers of the objects that are modified by the
+// changes can be written unquoted, as required by the migrate.QuoteNever policy. The
+// mustQuote function reports identifiers that cannot be written unquoted in the dialect,
+// e.g., reserved words. Identifiers in raw expressions, such as defaults, are not checked.
+func CheckUnquoted(changes []schema.Change, mustQuote func(string) bool) error {
+	var names []string
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			names = append(names, c.S.Name)
+		case *schema.DropSchema:
+			names = append(names, c.S.Name)
+		case *schema.ModifySchema:
+			names = append(names, c.S.Name)
+		case *schema.AddTable:
+			names = append(names, tableNames(c.T)...)
+		case *schema.DropTable:
+			names = append(names, tableNames(c.T)...)
+		case *schema.RenameTable:
+			names = append(names, c.From.Name, c.To.Name)
+		case *schema.ModifyTable:
+			names = append(names, tableNames(c.T)...)
+			// Dropped and renamed objects
+			// are not part of the table.
+			for _, c := range c.Changes {
+				switch c := c.(type) {
+				case *schema.DropColumn:
+					names = append(names, c.C.Name)
+				case *schema.RenameColumn:
+					names = append(names, c.From.Name)
+				case *schema.DropIndex:
+					names = append(names, c.I.Name)
+				case *schema.RenameIndex:
+					names = append(names, c.From.Name)
+				case *schema.DropForeignKey:
+					names = append(names, c.F.Symbol)
+				case *schema.DropCheck:
+					names = append(names, c.C.Name)
+				}
+			}
+		case *schema.AddView:
+			names = append(names, c.V.Name)
+		case *schema.DropView:
+			names = append(names, c.V.Name)
+		case *schema.ModifyView:
+			names = append(names, c.To.Name)
+		case *schema.RenameView:
+			names = append(names, c.From.Name, c.To.Name)
+		case *schema.AddTrigger:
+			names = append(names, c.T.Name)
+		case *schema.DropTrigger:
+			names = append(names, c.T.Name)
+		case *schema.ModifyTrigger:
+			names = append(names, c.To.Name)
+		}
+	}
+	for _, n := range names {
+		if n != "" && (!IsPlainIdent(n) || mustQuote != nil && mustQuote(n)) {
+			return fmt.Errorf("identifier %q must be quoted and cannot be written with the QuoteNever policy", n)
+		}
+	}
+	return nil
+}
+
+// tableNames returns the names of the table, its schema and its children.
+func tableNames(t *schema.Table) []string {
+	names := []string{t.Name}
+	if t.Schema != nil {
+		names = append(names, t.Schema.Name)
+	}
+	for _, c := range t.Columns {
+		names = append(names, c.Name)
+	}
+	for _, idx := range t.Indexes {
+		names = append(names, idx.Name)
+	}
+	for _, fk := range t.ForeignKeys {
+		names = append(names, fk.Symbol)
+	}
+	for _, a := range t.Attrs {
+		if c, ok := a.(*schema.Check); ok {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// CopyKey returns the primary key columns of the source table of the given CopyTable
+// change, used for skipping rows that were already copied when copying in batches.
+func CopyKey(c *migrate.CopyTable) ([]*schema.Column, error) {
+	pk := c.From.PrimaryKey
+	if pk == nil || len(pk.Parts) == 0 {
+		return nil, fmt.Errorf("copying table %q in batches requires a primary key", c.From.Name)
+	}
+	copied := make(map[string]bool)
+	for _, n := range c.CopyColumns() {
+		copied[n] = true
+	}
+	key := make([]*schema.Column, 0, len(pk.Parts))
+	for _, p := range pk.Parts {
+		if p.C == nil {
+			return nil, fmt.Errorf("copying table %q in batches requires a primary key of columns", c.From.Name)
+		}
+		if !copied[p.C.Name] {
+			return nil, fmt.Errorf("copying table %q in batches requires copying primary key column %q", c.From.Name, p.C.Name)
+		}
+		key = append(key, p.C)
+	}
+	return key, nil
+}
+
+// BoundName returns the given generated name if it does not exceed max characters.
+// Otherwise, it is truncated and suffixed with a short hash of the full name, to keep
+// names of different objects distinct.
+func BoundName(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+	h := sha256.Sum256([]byte(name))
+	return name[:max-9] + "_" + hex.EncodeToString(h[:4])
+}
+
 // byKeys sorts a map by keys.
 func byKeys[T any](m map[string]T) []struct {
 	K string
@@ -6,6 +6,7 @@ package sqlx
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/migrate"
@@ -109,6 +110,25 @@ func TestDetachCycles(t *testing.T) {
 	require.Equal(t, []schema.Change{changes[1], changes[0]}, planned)
 }
 
+func TestDetachCycles_DropKey(t *testing.T) {
+	var (
+		orgs  = schema.NewTable("orgs").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("slug", "text"))
+		users = schema.NewTable("users").AddColumns(schema.NewStringColumn("org", "text"))
+		key   = schema.NewUniqueIndex("slug").AddColumns(orgs.Columns[1])
+		fk    = schema.NewForeignKey("org").AddColumns(users.Columns[0]).SetRefTable(orgs).AddRefColumns(orgs.Columns[1])
+	)
+	orgs.AddIndexes(key)
+	users.AddForeignKeys(fk)
+	// Foreign keys are dropped before the keys they reference.
+	changes := []schema.Change{
+		&schema.ModifyTable{T: orgs, Changes: []schema.Change{&schema.DropIndex{I: key}}},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropForeignKey{F: fk}}},
+	}
+	planned, err := DetachCycles(changes)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{changes[1], changes[0]}, planned)
+}
+
 func TestConsistentOrder(t *testing.T) {
 	newT := func(n string) *schema.Table { return schema.NewTable(n).AddColumns(schema.NewIntColumn("id", "int")) }
 	t1, t2, t3 := newT("t1"), newT("t2"), newT("t3")
@@ -153,3 +173,64 @@ func TestCheckChangesScope(t *testing.T) {
 	})
 	require.EqualError(t, err, "found 2 schemas when migration plan is scoped to one: [\"s1\" \"s2\"]")
 }
+
+type partialAttr struct {
+	schema.Attr
+	p bool
+}
+
+func (a *partialAttr) Partial() bool { return a.p }
+
+func TestKeyOf(t *testing.T) {
+	var (
+		tbl = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("email", "text"))
+		idx = schema.NewUniqueIndex("key").AddColumns(tbl.Columns...)
+	)
+	require.True(t, KeyOf(idx, []*schema.Column{tbl.Columns[1], tbl.Columns[0]}))
+	require.False(t, KeyOf(idx, tbl.Columns[:1]))
+	idx.AddAttrs(&partialAttr{})
+	require.True(t, KeyOf(idx, tbl.Columns))
+	// Partial indexes are not keys of their columns.
+	idx.Attrs = []schema.Attr{&partialAttr{p: true}}
+	require.False(t, KeyOf(idx, tbl.Columns))
+	// Neither are indexes on expressions.
+	idx = schema.NewUniqueIndex("key").AddColumns(tbl.Columns[0]).AddExprs(&schema.RawExpr{X: "lower(email)"})
+	require.False(t, KeyOf(idx, tbl.Columns))
+}
+
+func TestBoundName(t *testing.T) {
+	require.Equal(t, "atlas_copy_users_users_new", BoundName("atlas_copy_users_users_new", 64))
+	long := "atlas_backfill_" + strings.Repeat("t", 40) + "_" + strings.Repeat("c", 40)
+	b1 := BoundName(long, 64)
+	require.Len(t, b1, 64)
+	require.Equal(t, long[:55], b1[:55])
+	b2 := BoundName(long+"d", 64)
+	require.Len(t, b2, 64)
+	require.NotEqual(t, b1, b2, "truncated names must be distinct")
+	require.Equal(t, b1, BoundName(long, 64), "names must be stable")
+}
+
+func TestCheckUnquoted(t *testing.T) {
+	var (
+		mustQuote = func(s string) bool { return s == "order" }
+		users     = schema.NewTable("users").
+				SetSchema(schema.New("public")).
+				AddColumns(schema.NewIntColumn("id", "int"))
+	)
+	require.NoError(t, CheckUnquoted([]schema.Change{&schema.AddTable{T: users}}, mustQuote))
+
+	users.AddColumns(schema.NewIntColumn("order", "int"))
+	err := CheckUnquoted([]schema.Change{&schema.AddTable{T: users}}, mustQuote)
+	require.EqualError(t, err, `identifier "order" must be quoted and cannot be written with the QuoteNever policy`)
+
+	// Dropped objects are not part of the modified table.
+	err = CheckUnquoted([]schema.Change{
+		&schema.ModifyTable{
+			T:       schema.NewTable("t"),
+			Changes: []schema.Change{&schema.DropColumn{C: schema.NewIntColumn("first name", "int")}},
+		},
+	}, mustQuote)
+	require.EqualError(t, err, `identifier "first name" must be quoted and cannot be written with the QuoteNever policy`)
+	err = CheckUnquoted([]schema.Change{&schema.AddSchema{S: schema.New("Order-Service")}}, nil)
+	require.EqualError(t, err, `identifier "Order-Service" must be quoted and cannot be written with the QuoteNever policy`)
+}
@@ -0,0 +1,314 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"fmt"
+	"sort"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// resolveTables calls the Resolver (if set) for the tables that exist only in the current
+// schema, and returns the resolved renames. In case tables were renamed, the returned schema
+// is a copy of the current one, in which the renamed tables are replaced by copies that are
+// named by their new names. The tables of the caller are not modified.
+func (d *Diff) resolveTables(from, to *schema.Schema, opts *schema.DiffOptions) (*schema.Schema, []schema.Change, error) {
+	if opts.Resolver == nil {
+		return from, nil, nil
+	}
+	var dropped, added []*schema.Table
+	for _, t1 := range from.Tables {
+		switch _, err := d.findTable(to, t1.Name); {
+		case schema.IsNotExistError(err):
+			dropped = append(dropped, t1)
+		case err != nil:
+			return nil, nil, err
+		}
+	}
+	for _, t2 := range to.Tables {
+		switch _, err := d.findTable(from, t2.Name); {
+		case schema.IsNotExistError(err):
+			added = append(added, t2)
+		case err != nil:
+			return nil, nil, err
+		}
+	}
+	var (
+		renames []schema.Change
+		renamed = make(map[*schema.Table]*schema.Table)
+	)
+	for _, t1 := range dropped {
+		if len(added) == 0 {
+			break
+		}
+		cs := make([]*schema.TableCandidate, 0, len(added))
+		for _, t2 := range added {
+			cs = append(cs, &schema.TableCandidate{T: t2, Score: d.tableScore(t1, t2)})
+		}
+		sort.SliceStable(cs, func(i, j int) bool {
+			return cs[i].Score > cs[j].Score
+		})
+		t2, err := opts.Resolver.ResolveTable(t1, cs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if t2 == nil {
+			continue
+		}
+		i := tableIndex(added, t2)
+		if i == -1 {
+			return nil, nil, fmt.Errorf("resolved table %q is not a rename candidate of table %q", t2.Name, t1.Name)
+		}
+		added = append(added[:i], added[i+1:]...)
+		renames = opts.AddOrSkip(renames, &schema.RenameTable{From: t1, To: t2})
+		renamed[t1] = cloneTable(t1)
+		renamed[t1].Name = t2.Name
+	}
+	if len(renamed) == 0 {
+		return from, renames, nil
+	}
+	s := *from
+	s.Tables = make([]*schema.Table, len(from.Tables))
+	for i, t := range from.Tables {
+		if c, ok := renamed[t]; ok {
+			t = c
+		}
+		s.Tables[i] = t
+	}
+	return &s, renames, nil
+}
+
+// resolveColumns calls the Resolver (if set) for the columns that exist only in the current
+// table, and returns the resolved renames. In case columns were renamed, the returned table
+// is a copy of the current one, in which the renamed columns are named by their new names.
+// The columns of the caller are not modified.
+func (d *Diff) resolveColumns(from, to *schema.Table, opts *schema.DiffOptions) (*schema.Table, []schema.Change, error) {
+	if opts.Resolver == nil {
+		return from, nil, nil
+	}
+	var dropped, added []*schema.Column
+	for _, c1 := range from.Columns {
+		if _, ok := to.Column(c1.Name); !ok {
+			dropped = append(dropped, c1)
+		}
+	}
+	for _, c2 := range to.Columns {
+		if _, ok := from.Column(c2.Name); !ok {
+			added = append(added, c2)
+		}
+	}
+	var (
+		renames []schema.Change
+		names   = make(map[int]string)
+	)
+	for _, c1 := range dropped {
+		if len(added) == 0 {
+			break
+		}
+		cs := make([]*schema.ColumnCandidate, 0, len(added))
+		for _, c2 := range added {
+			cs = append(cs, &schema.ColumnCandidate{C: c2, Score: d.columnScore(from, to, c1, c2)})
+		}
+		sort.SliceStable(cs, func(i, j int) bool {
+			return cs[i].Score > cs[j].Score
+		})
+		c2, err := opts.Resolver.ResolveColumn(from, c1, cs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if c2 == nil {
+			continue
+		}
+		i := columnIndex(added, c2)
+		if i == -1 {
+			return nil, nil, fmt.Errorf("resolved column %q is not a rename candidate of column %q", c2.Name, c1.Name)
+		}
+		added = append(added[:i], added[i+1:]...)
+		renames = opts.AddOrSkip(renames, &schema.RenameColumn{From: c1, To: c2})
+		names[columnIndex(from.Columns, c1)] = c2.Name
+	}
+	if len(names) == 0 {
+		return from, renames, nil
+	}
+	t := cloneTable(from)
+	for i, name := range names {
+		t.Columns[i].Name = name
+	}
+	return t, renames, nil
+}
+
+// tableScore returns the similarity of the two tables based on their columns. Columns that
+// exist in both tables with the same definition are scored 1, and 0.5 if their definitions
+// differ. The result is normalized by the number of columns in the larger table.
+func (d *Diff) tableScore(t1, t2 *schema.Table) float64 {
+	n := len(t1.Columns)
+	if len(t2.Columns) > n {
+		n = len(t2.Columns)
+	}
+	if n == 0 {
+		return 0
+	}
+	var score float64
+	for _, c1 := range t1.Columns {
+		c2, ok := t2.Column(c1.Name)
+		if !ok {
+			continue
+		}
+		if k, err := d.ColumnChange(t1, c1, c2); err == nil && k == schema.NoChange {
+			score++
+		} else {
+			score += 0.5
+		}
+	}
+	return score / float64(n)
+}
+
+// columnScore returns the similarity of the two columns based on their
+// types (0.6), nullability (0.2) and positions in their tables (0.2).
+func (d *Diff) columnScore(t1, t2 *schema.Table, c1, c2 *schema.Column) float64 {
+	score := 1.0
+	k, err := d.ColumnChange(t1, c1, c2)
+	if err != nil || k.Is(schema.ChangeType) {
+		score -= 0.6
+	}
+	if c1.Type != nil && c2.Type != nil && c1.Type.Null != c2.Type.Null {
+		score -= 0.2
+	}
+	if columnIndex(t1.Columns, c1) != columnIndex(t2.Columns, c2) {
+		score -= 0.2
+	}
+	return score
+}
+
+// cloneTable returns a copy of the table, in which the columns, indexes, foreign keys and
+// triggers are copied and linked to each other. Tables referenced by the foreign keys are shared.
+func cloneTable(t *schema.Table) *schema.Table {
+	var (
+		c    = *t
+		cols = make(map[*schema.Column]*schema.Column, len(t.Columns))
+	)
+	c.Columns = make([]*schema.Column, len(t.Columns))
+	for i, c1 := range t.Columns {
+		c2 := *c1
+		c2.Indexes, c2.ForeignKeys = nil, nil
+		c.Columns[i], cols[c1] = &c2, &c2
+	}
+	column := func(c1 *schema.Column) *schema.Column {
+		if c2, ok := cols[c1]; ok {
+			return c2
+		}
+		return c1
+	}
+	index := func(idx *schema.Index) *schema.Index {
+		idx2 := *idx
+		idx2.Table = &c
+		idx2.Parts = make([]*schema.IndexPart, len(idx.Parts))
+		for i, p := range idx.Parts {
+			p2 := *p
+			if c2, ok := cols[p.C]; ok {
+				p2.C = c2
+				c2.Indexes = append(c2.Indexes, &idx2)
+			}
+			idx2.Parts[i] = &p2
+		}
+		return &idx2
+	}
+	if t.PrimaryKey != nil {
+		c.PrimaryKey = index(t.PrimaryKey)
+	}
+	c.Indexes = make([]*schema.Index, len(t.Indexes))
+	for i, idx := range t.Indexes {
+		c.Indexes[i] = index(idx)
+	}
+	c.ForeignKeys = make([]*schema.ForeignKey, len(t.ForeignKeys))
+	for i, fk := range t.ForeignKeys {
+		fk2 := *fk
+		fk2.Table = &c
+		fk2.Columns = make([]*schema.Column, len(fk.Columns))
+		for j, c1 := range fk.Columns {
+			fk2.Columns[j] = column(c1)
+			if c2, ok := cols[c1]; ok {
+				c2.ForeignKeys = append(c2.ForeignKeys, &fk2)
+			}
+		}
+		// Self-referencing foreign keys.
+		if fk.RefTable == t {
+			fk2.RefTable = &c
+			fk2.RefColumns = make([]*schema.Column, len(fk.RefColumns))
+			for j, c1 := range fk.RefColumns {
+				fk2.RefColumns[j] = column(c1)
+			}
+		}
+		c.ForeignKeys[i] = &fk2
+	}
+	c.Triggers = make([]*schema.Trigger, len(t.Triggers))
+	for i, tr := range t.Triggers {
+		tr2 := *tr
+		tr2.Table = &c
+		c.Triggers[i] = &tr2
+	}
+	c.Attrs = append([]schema.Attr(nil), t.Attrs...)
+	return &c
+}
+
+// CloneSchema returns a copy of the schema, in which the tables and views are copied, and
+// foreign keys that reference tables of the schema are linked to their copies. Functions,
+// procedures and driver-specific objects are shared with the original schema.
+func CloneSchema(s *schema.Schema) *schema.Schema {
+	c := *s
+	c.Tables = make([]*schema.Table, len(s.Tables))
+	tables := make(map[*schema.Table]*schema.Table, len(s.Tables))
+	for i, t := range s.Tables {
+		c.Tables[i] = cloneTable(t)
+		c.Tables[i].Schema = &c
+		tables[t] = c.Tables[i]
+	}
+	for _, t := range c.Tables {
+		for _, fk := range t.ForeignKeys {
+			ref, ok := tables[fk.RefTable]
+			if !ok {
+				continue
+			}
+			cols := make([]*schema.Column, len(fk.RefColumns))
+			for i, c1 := range fk.RefColumns {
+				cols[i] = c1
+				if j := columnIndex(fk.RefTable.Columns, c1); j != -1 {
+					cols[i] = ref.Columns[j]
+				}
+			}
+			fk.RefTable, fk.RefColumns = ref, cols
+		}
+	}
+	c.Views = make([]*schema.View, len(s.Views))
+	for i, v := range s.Views {
+		v2 := *v
+		v2.Schema = &c
+		c.Views[i] = &v2
+	}
+	c.Funcs = append([]*schema.Func(nil), s.Funcs...)
+	c.Procs = append([]*schema.Proc(nil), s.Procs...)
+	c.Attrs = append([]schema.Attr(nil), s.Attrs...)
+	c.Objects = append([]schema.Object(nil), s.Objects...)
+	return &c
+}
+
+func tableIndex(ts []*schema.Table, t *schema.Table) int {
+	for i := range ts {
+		if ts[i] == t {
+			return i
+		}
+	}
+	return -1
+}
+
+func columnIndex(cs []*schema.Column, c *schema.Column) int {
+	for i := range cs {
+		if cs[i] == c {
+			return i
+		}
+	}
+	return -1
+}
@@ -14,8 +14,10 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
+	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
 )
 
@@ -38,6 +40,12 @@ func (nopCloser) Close() error { return nil }
 // If the ExecQuerier is already bound to a single connection (e.g. Tx, Conn),
 // the connection will return as-is with a NopCloser.
 func SingleConn(ctx context.Context, conn schema.ExecQuerier) (ExecQueryCloser, error) {
+	// A wrapper that provides its own single connections (e.g. intercepted).
+	if opener, ok := conn.(interface {
+		SingleConn(context.Context) (ExecQueryCloser, error)
+	}); ok {
+		return opener.SingleConn(ctx)
+	}
 	// A standard sql.DB or a wrapper of it.
 	if opener, ok := conn.(interface {
 		Conn(context.Context) (*sql.Conn, error)
@@ -54,6 +62,19 @@ func SingleConn(ctx context.Context, conn schema.ExecQuerier) (ExecQueryCloser,
 	return nil, fmt.Errorf("cannot obtain a single connection from %T", conn)
 }
 
+// WithoutCancel returns a context that keeps the values of the given context, but is
+// never canceled. It is used for releasing resources (e.g. locks) after cancellation.
+func WithoutCancel(ctx context.Context) context.Context {
+	return noCancel{ctx}
+}
+
+// noCancel is a context that is never canceled.
+type noCancel struct{ context.Context }
+
+func (noCancel) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (noCancel) Done() <-chan struct{}       { return nil }
+func (noCancel) Err() error                  { return nil }
+
 // ValidString reports if the given string is not null and valid.
 func ValidString(s sql.NullString) bool {
 	return s.Valid && s.String != "" && strings.ToLower(s.String) != "null"
@@ -114,12 +135,24 @@ func SchemaFKs(s *schema.Schema, rows *sql.Rows) error {
 // TypedSchemaFKs is a version of SchemaFKs that allows to specify the type of
 // used to scan update and delete actions from the database.
 func TypedSchemaFKs[T ScanStringer](s *schema.Schema, rows *sql.Rows) error {
+	return TypedSchemaFKsAttr[T](s, rows, nil)
+}
+
+// TypedSchemaFKsAttr is a version of TypedSchemaFKs that scans an additional column
+// that holds a driver-specific attribute of the foreign key (e.g., its MATCH type),
+// and passes it to the attr function once the foreign key is created.
+func TypedSchemaFKsAttr[T ScanStringer](s *schema.Schema, rows *sql.Rows, attr func(*schema.ForeignKey, string)) error {
 	for rows.Next() {
 		var (
 			updateAction, deleteAction                                   = V(new(T)), V(new(T))
 			name, table, column, tSchema, refTable, refColumn, refSchema string
+			extra                                                        sql.NullString
+			dest                                                         = []any{&name, &table, &column, &tSchema, &refTable, &refColumn, &refSchema, &updateAction, &deleteAction}
 		)
-		if err := rows.Scan(&name, &table, &column, &tSchema, &refTable, &refColumn, &refSchema, &updateAction, &deleteAction); err != nil {
+		if attr != nil {
+			dest = append(dest, &extra)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return err
 		}
 		t, ok := s.Table(table)
@@ -147,6 +180,9 @@ func TypedSchemaFKs[T ScanStringer](s *schema.Schema, rows *sql.Rows) error {
 			case tSchema != refSchema:
 				fk.RefTable = &schema.Table{Name: refTable, Schema: &schema.Schema{Name: refSchema}}
 			}
+			if attr != nil {
+				attr(fk, extra.String)
+			}
 			t.ForeignKeys = append(t.ForeignKeys, fk)
 		}
 		c, ok := t.Column(column)
@@ -224,7 +260,7 @@ func ValuesEqual(v1, v2 []string) bool {
 // ModeInspectSchema returns the InspectMode or its default.
 func ModeInspectSchema(o *schema.InspectOptions) schema.InspectMode {
 	if o == nil || o.Mode == 0 {
-		return schema.InspectSchemas | schema.InspectTables | schema.InspectViews | schema.InspectFuncs
+		return schema.InspectSchemas | schema.InspectTables | schema.InspectViews | schema.InspectFuncs | schema.InspectObjects
 	}
 	return o.Mode
 }
@@ -232,7 +268,7 @@ func ModeInspectSchema(o *schema.InspectOptions) schema.InspectMode {
 // ModeInspectRealm returns the InspectMode or its default.
 func ModeInspectRealm(o *schema.InspectRealmOption) schema.InspectMode {
 	if o == nil || o.Mode == 0 {
-		return schema.InspectSchemas | schema.InspectTables | schema.InspectViews | schema.InspectFuncs
+		return schema.InspectSchemas | schema.InspectTables | schema.InspectViews | schema.InspectFuncs | schema.InspectObjects
 	}
 	return o.Mode
 }
@@ -245,6 +281,12 @@ type Builder struct {
 	Schema       *string // schema qualifier
 	Indent       string  // indentation string
 	level        int     // current indentation level
+
+	// QuotePolicy controls when identifiers are quoted. MustQuote reports
+	// if an identifier cannot be written unquoted, and it is used by the
+	// migrate.QuoteNeeded policy. See CheckUnquoted for QuoteNever.
+	QuotePolicy migrate.QuotePolicy
+	MustQuote   func(string) bool
 }
 
 // P writes a list of phrases to the builder separated and
@@ -265,17 +307,39 @@ func (b *Builder) P(phrases ...string) *Builder {
 	return b
 }
 
-// Ident writes the given string quoted as an SQL identifier.
+// Ident writes the given string as an SQL identifier. By default, identifiers
+// are quoted, unless configured otherwise by the builder QuotePolicy.
 func (b *Builder) Ident(s string) *Builder {
-	if s != "" {
+	if s == "" {
+		return b
+	}
+	switch {
+	case b.QuotePolicy == migrate.QuoteNever,
+		b.QuotePolicy == migrate.QuoteNeeded && IsPlainIdent(s) && (b.MustQuote == nil || !b.MustQuote(s)):
+		b.WriteString(s)
+	default:
 		b.WriteByte(b.QuoteOpening)
 		b.WriteString(s)
 		b.WriteByte(b.QuoteClosing)
-		b.WriteByte(' ')
 	}
+	b.WriteByte(' ')
 	return b
 }
 
+// IsPlainIdent reports if the given string is a plain identifier that consists
+// only of ASCII letters, digits and underscores, and does not start with a digit.
+func IsPlainIdent(s string) bool {
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
 // View writes the view identifier to the builder, prefixed
 // with the schema name if exists.
 func (b *Builder) View(v *schema.View) *Builder {
@@ -456,6 +520,8 @@ func (b *Builder) Clone() *Builder {
 	return &Builder{
 		QuoteOpening: b.QuoteOpening,
 		QuoteClosing: b.QuoteClosing,
+		QuotePolicy:  b.QuotePolicy,
+		MustQuote:    b.MustQuote,
 		Buffer:       *bytes.NewBufferString(b.Buffer.String()),
 	}
 }
@@ -618,3 +684,41 @@ func IsUint(s string) bool {
 	}
 	return true
 }
+
+// SortViews returns the given views sorted by their dependencies, such that each view comes
+// after the views it depends on. Dependencies that are not in the given list are ignored.
+func SortViews(views []*schema.View) ([]*schema.View, error) {
+	var (
+		sorted = make([]*schema.View, 0, len(views))
+		state  = make(map[*schema.View]int, len(views))
+		visit  func(*schema.View) error
+	)
+	for _, v := range views {
+		state[v] = 0
+	}
+	visit = func(v *schema.View) error {
+		switch s, ok := state[v]; {
+		case !ok, s == 2:
+			return nil
+		case s == 1:
+			return fmt.Errorf("cyclic dependency of view %q", v.Name)
+		}
+		state[v] = 1
+		for _, o := range v.Deps {
+			if dv, ok := o.(*schema.View); ok {
+				if err := visit(dv); err != nil {
+					return err
+				}
+			}
+		}
+		state[v] = 2
+		sorted = append(sorted, v)
+		return nil
+	}
+	for _, v := range views {
+		if err := visit(v); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
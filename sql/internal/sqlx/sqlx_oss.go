@@ -10,8 +10,48 @@ import (
 	"ariga.io/atlas/sql/schema"
 )
 
-// PlanViewChanges (should) plan view changes in the current order they should be applied.
-// It is unimplemented for community version as views are not supported there.
+// PlanViewChanges plans view changes in the order they should be applied. Views are dropped
+// before the views they depend on, and then, created or modified after the views they depend
+// on. Note, the community version does not plan the view statements themselves.
 func PlanViewChanges(changes []schema.Change) ([]schema.Change, error) {
-	return changes, nil // unimplemented.
+	var (
+		drops, others []*schema.View
+		byView        = make(map[*schema.View]schema.Change, len(changes))
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.DropView:
+			drops = append(drops, c.V)
+			byView[c.V] = c
+		case *schema.AddView:
+			others = append(others, c.V)
+			byView[c.V] = c
+		case *schema.ModifyView:
+			others = append(others, c.To)
+			byView[c.To] = c
+		case *schema.RenameView:
+			others = append(others, c.To)
+			byView[c.To] = c
+		}
+	}
+	// Unknown changes, or changes of
+	// the same view, are kept as is.
+	if len(byView) != len(changes) {
+		return changes, nil
+	}
+	drops, err := SortViews(drops)
+	if err != nil {
+		return nil, err
+	}
+	if others, err = SortViews(others); err != nil {
+		return nil, err
+	}
+	planned := make([]schema.Change, 0, len(changes))
+	for i := len(drops) - 1; i >= 0; i-- {
+		planned = append(planned, byView[drops[i]])
+	}
+	for _, v := range others {
+		planned = append(planned, byView[v])
+	}
+	return planned, nil
 }
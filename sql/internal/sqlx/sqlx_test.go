@@ -5,9 +5,11 @@
 package sqlx
 
 import (
+	"context"
 	"strconv"
 	"testing"
 
+	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/stretchr/testify/require"
@@ -41,6 +43,18 @@ func TestModeInspectSchema(t *testing.T) {
 	require.False(t, m.Is(schema.InspectTables))
 }
 
+func TestWithoutCancel(t *testing.T) {
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	cancel()
+	nc := WithoutCancel(ctx)
+	require.NoError(t, nc.Err())
+	require.Nil(t, nc.Done())
+	require.Equal(t, "value", nc.Value(key{}))
+	_, ok := nc.Deadline()
+	require.False(t, ok)
+}
+
 func TestBuilder(t *testing.T) {
 	var (
 		b       = &Builder{QuoteOpening: '"', QuoteClosing: '"'}
@@ -81,6 +95,36 @@ func TestBuilder_Qualifier(t *testing.T) {
 	require.Equal(t, `CREATE TABLE "users"`, b.String())
 }
 
+func TestBuilder_QuotePolicy(t *testing.T) {
+	var (
+		b = &Builder{
+			QuoteOpening: '"',
+			QuoteClosing: '"',
+			QuotePolicy:  migrate.QuoteNeeded,
+			MustQuote:    func(s string) bool { return s == "order" },
+		}
+		table = schema.NewTable("Users").SetSchema(schema.New("public"))
+	)
+	b.P("SELECT").Ident("id").Comma().Ident("order").Comma().Ident("first name").P("FROM").Table(table)
+	require.Equal(t, `SELECT id, "order", "first name" FROM public.Users`, b.String())
+
+	b.Reset()
+	b.QuotePolicy = migrate.QuoteNever
+	b.P("SELECT").Ident("id").P("FROM").Table(table)
+	require.Equal(t, `SELECT id FROM public.Users`, b.String())
+
+	b.Reset()
+	b.QuotePolicy = migrate.QuoteAlways
+	b.P("SELECT").Ident("id").P("FROM").Table(table)
+	require.Equal(t, `SELECT "id" FROM "public"."Users"`, b.String())
+
+	require.True(t, IsPlainIdent("_a1"))
+	require.False(t, IsPlainIdent("1a"))
+	require.False(t, IsPlainIdent("a-b"))
+	require.False(t, IsPlainIdent("é"))
+	require.False(t, IsPlainIdent(""))
+}
+
 func TestQuote(t *testing.T) {
 	var (
 		s = "s1"
@@ -253,3 +297,32 @@ func TestIsUint(t *testing.T) {
 	require.False(t, IsUint("1.2"))
 	require.False(t, IsUint("1.2.3"))
 }
+
+func TestPlanViewChanges(t *testing.T) {
+	var (
+		v1 = schema.NewView("v1", "SELECT 1")
+		v2 = schema.NewView("v2", "WITH c AS (SELECT * FROM v1) SELECT * FROM c").AddDeps(v1)
+		v3 = schema.NewView("v3", "SELECT * FROM v2").AddDeps(v2, v1)
+		d1 = schema.NewView("d1", "SELECT 1")
+		d2 = schema.NewView("d2", "SELECT * FROM d1").AddDeps(d1)
+	)
+	planned, err := PlanViewChanges([]schema.Change{
+		&schema.AddView{V: v3},
+		&schema.DropView{V: d1},
+		&schema.ModifyView{From: v2, To: v2},
+		&schema.DropView{V: d2},
+		&schema.AddView{V: v1},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.DropView{V: d2},
+		&schema.DropView{V: d1},
+		&schema.AddView{V: v1},
+		&schema.ModifyView{From: v2, To: v2},
+		&schema.AddView{V: v3},
+	}, planned)
+
+	v1.AddDeps(v3)
+	_, err = PlanViewChanges([]schema.Change{&schema.AddView{V: v1}, &schema.AddView{V: v3}})
+	require.EqualError(t, err, `cyclic dependency of view "v1"`)
+}
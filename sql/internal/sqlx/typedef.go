@@ -0,0 +1,130 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// TypeDefs holds the custom types registered by users for a driver. Drivers consult
+// it before their own types, and therefore, it can also override their behavior.
+type TypeDefs struct {
+	mu   sync.RWMutex
+	defs map[string]*schema.TypeDef
+}
+
+// NewTypeDefs returns an empty TypeDefs registry.
+func NewTypeDefs() *TypeDefs {
+	return &TypeDefs{defs: make(map[string]*schema.TypeDef)}
+}
+
+// Register adds the given type definition to the registry.
+func (r *TypeDefs) Register(d *schema.TypeDef) error {
+	if d == nil || strings.TrimSpace(d.Name) == "" {
+		return errors.New("missing type name")
+	}
+	name := typeDefName(d.Name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.defs[name]; ok {
+		return fmt.Errorf("type %q was already registered", d.Name)
+	}
+	r.defs[name] = d
+	return nil
+}
+
+// Parse parses the given raw type using its registered definition. The
+// second return value reports if such a definition exists.
+func (r *TypeDefs) Parse(raw string) (schema.Type, bool, error) {
+	d, ok := r.lookup(raw)
+	switch {
+	case !ok:
+		return nil, false, nil
+	case d.Parse == nil:
+		return &schema.UnsupportedType{T: raw}, true, nil
+	}
+	t, err := d.Parse(raw)
+	if err == nil && t == nil {
+		err = fmt.Errorf("nil type was returned for %q", raw)
+	}
+	return t, true, err
+}
+
+// Format formats the given type using its registered definition. The
+// second return value reports if such a definition exists.
+func (r *TypeDefs) Format(t schema.Type) (string, bool, error) {
+	d, ok := r.lookup(typeField(t))
+	switch {
+	case !ok:
+		return "", false, nil
+	case d.Format == nil:
+		return typeField(t), true, nil
+	}
+	f, err := d.Format(t)
+	return f, true, err
+}
+
+// Changed reports if the column type was changed, in case one of the types
+// was registered. The second return value reports if such a definition exists.
+func (r *TypeDefs) Changed(from, to schema.Type) (bool, bool, error) {
+	d1, ok1 := r.lookup(typeField(from))
+	d2, ok2 := r.lookup(typeField(to))
+	switch {
+	case !ok1 && !ok2:
+		return false, false, nil
+	case d1 != d2:
+		return true, true, nil
+	case d1.Changed != nil:
+		return d1.Changed(from, to), true, nil
+	}
+	f1, _, err := r.Format(from)
+	if err != nil {
+		return false, true, err
+	}
+	f2, _, err := r.Format(to)
+	if err != nil {
+		return false, true, err
+	}
+	return !strings.EqualFold(f1, f2), true, nil
+}
+
+func (r *TypeDefs) lookup(raw string) (*schema.TypeDef, bool) {
+	if raw == "" {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.defs) == 0 {
+		return nil, false
+	}
+	d, ok := r.defs[typeDefName(raw)]
+	return d, ok
+}
+
+// typeDefName returns the lowercased name of the given raw type, without its arguments.
+func typeDefName(raw string) string {
+	if i := strings.IndexByte(raw, '('); i > 0 {
+		raw = raw[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(raw))
+}
+
+// typeField returns the value of the T field of the type, if exists.
+func typeField(t schema.Type) string {
+	rv := reflect.Indirect(reflect.ValueOf(t))
+	if rv.Kind() != reflect.Struct {
+		return ""
+	}
+	if f := rv.FieldByName("T"); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeDefs(t *testing.T) {
+	r := NewTypeDefs()
+	_, ok, err := r.Parse("citext")
+	require.False(t, ok)
+	require.NoError(t, err)
+
+	require.EqualError(t, r.Register(&schema.TypeDef{}), "missing type name")
+	require.NoError(t, r.Register(&schema.TypeDef{Name: "citext"}))
+	require.EqualError(t, r.Register(&schema.TypeDef{Name: "CITEXT"}), `type "CITEXT" was already registered`)
+	require.NoError(t, r.Register(&schema.TypeDef{
+		Name: "vector",
+		Parse: func(raw string) (schema.Type, error) {
+			return &schema.StringType{T: "vector", Size: len(raw)}, nil
+		},
+		Format: func(t schema.Type) (string, error) {
+			return "VECTOR", nil
+		},
+		Changed: func(from, to schema.Type) bool {
+			return from.(*schema.StringType).Size != to.(*schema.StringType).Size
+		},
+	}))
+
+	// Default behavior.
+	typ, ok, err := r.Parse("CITEXT")
+	require.True(t, ok)
+	require.NoError(t, err)
+	require.Equal(t, &schema.UnsupportedType{T: "CITEXT"}, typ)
+	f, ok, err := r.Format(typ)
+	require.True(t, ok)
+	require.NoError(t, err)
+	require.Equal(t, "CITEXT", f)
+	changed, ok, err := r.Changed(typ, &schema.UnsupportedType{T: "citext"})
+	require.True(t, ok)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	// Custom behavior.
+	typ, ok, err = r.Parse("vector(3)")
+	require.True(t, ok)
+	require.NoError(t, err)
+	require.Equal(t, &schema.StringType{T: "vector", Size: 9}, typ)
+	f, _, err = r.Format(typ)
+	require.NoError(t, err)
+	require.Equal(t, "VECTOR", f)
+	changed, _, err = r.Changed(typ, &schema.StringType{T: "vector", Size: 9})
+	require.NoError(t, err)
+	require.False(t, changed)
+	changed, _, err = r.Changed(typ, &schema.StringType{T: "vector", Size: 10})
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	// Changing from or to a registered type.
+	changed, ok, err = r.Changed(typ, &schema.StringType{T: "text"})
+	require.True(t, ok)
+	require.NoError(t, err)
+	require.True(t, changed)
+	changed, ok, err = r.Changed(&schema.StringType{T: "text"}, &schema.StringType{T: "varchar"})
+	require.False(t, ok)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	require.NoError(t, r.Register(&schema.TypeDef{
+		Name: "broken",
+		Parse: func(string) (schema.Type, error) {
+			return nil, nil
+		},
+	}))
+	_, _, err = r.Parse("broken")
+	require.EqualError(t, err, `nil type was returned for "broken"`)
+}
@@ -0,0 +1,112 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package inventory exports the column inventory of schemas, one record per table column,
+// in formats that can be ingested by data catalogs and warehouses. For example:
+//
+//	f, err := os.Create("columns.parquet")
+//	if err != nil {
+//		return err
+//	}
+//	defer f.Close()
+//	return inventory.WriteParquet(f, realm)
+package inventory
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Column is a record in the column inventory.
+type Column struct {
+	Schema   string `json:"schema"`
+	Table    string `json:"table"`
+	Name     string `json:"column"`
+	Position int    `json:"position"` // 1-based position of the column in its table.
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Header holds the names of the inventory fields, in the order they are exported.
+var Header = []string{"schema", "table", "column", "position", "type", "nullable", "comment"}
+
+// Columns returns the column inventory of the realm, ordered
+// by the schemas, tables and columns as they appear in the realm.
+func Columns(r *schema.Realm) []*Column {
+	var cs []*Column
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			for i, c := range t.Columns {
+				col := &Column{
+					Schema:   s.Name,
+					Table:    t.Name,
+					Name:     c.Name,
+					Position: i + 1,
+					Type:     typeName(c),
+					Comment:  comment(c.Attrs),
+				}
+				if c.Type != nil {
+					col.Nullable = c.Type.Null
+				}
+				cs = append(cs, col)
+			}
+		}
+	}
+	return cs
+}
+
+// WriteCSV writes the column inventory of the realm as a CSV document,
+// with a header record holding the field names.
+func WriteCSV(w io.Writer, r *schema.Realm) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Header); err != nil {
+		return err
+	}
+	for _, c := range Columns(r) {
+		if err := cw.Write(c.record()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// record returns the CSV record of the column.
+func (c *Column) record() []string {
+	return []string{c.Schema, c.Table, c.Name, strconv.Itoa(c.Position), c.Type, strconv.FormatBool(c.Nullable), c.Comment}
+}
+
+// typeName returns the string representation of the column type.
+func typeName(c *schema.Column) string {
+	if c.Type == nil || c.Type.Type == nil {
+		return ""
+	}
+	if c.Type.Raw != "" {
+		return c.Type.Raw
+	}
+	rv := reflect.Indirect(reflect.ValueOf(c.Type.Type))
+	if rv.Kind() == reflect.Struct {
+		if t := rv.FieldByName("T"); t.IsValid() && t.Kind() == reflect.String && t.String() != "" {
+			return t.String()
+		}
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", c.Type.Type), "*")
+}
+
+// comment returns the comment text from the attributes, if exists.
+func comment(attrs []schema.Attr) string {
+	for _, a := range attrs {
+		if c, ok := a.(*schema.Comment); ok {
+			return c.Text
+		}
+	}
+	return ""
+}
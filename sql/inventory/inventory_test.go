@@ -0,0 +1,76 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package inventory_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"ariga.io/atlas/sql/inventory"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumns(t *testing.T) {
+	cs := inventory.Columns(testRealm())
+	require.Len(t, cs, 4)
+	require.Equal(t, &inventory.Column{Schema: "public", Table: "users", Name: "id", Position: 1, Type: "bigint", Comment: "primary key"}, cs[0])
+	require.Equal(t, &inventory.Column{Schema: "public", Table: "users", Name: "name", Position: 2, Type: "varchar(255)", Nullable: true}, cs[1])
+	require.Equal(t, &inventory.Column{Schema: "sales", Table: "orders", Name: "total", Position: 2, Type: "decimal"}, cs[3])
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, inventory.WriteCSV(&b, testRealm()))
+	require.Equal(t, `schema,table,column,position,type,nullable,comment
+public,users,id,1,bigint,false,primary key
+public,users,name,2,varchar(255),true,
+sales,orders,id,1,bigint,false,"the ""order"" id, or number"
+sales,orders,total,2,decimal,false,
+`, b.String())
+
+	b.Reset()
+	require.NoError(t, inventory.WriteCSV(&b, schema.NewRealm()))
+	require.Equal(t, "schema,table,column,position,type,nullable,comment\n", b.String())
+}
+
+func TestWriteParquet(t *testing.T) {
+	for _, r := range []*schema.Realm{testRealm(), schema.NewRealm()} {
+		var b bytes.Buffer
+		require.NoError(t, inventory.WriteParquet(&b, r))
+		out := b.Bytes()
+		require.Equal(t, "PAR1", string(out[:4]))
+		require.Equal(t, "PAR1", string(out[len(out)-4:]))
+		n := binary.LittleEndian.Uint32(out[len(out)-8:])
+		require.LessOrEqual(t, int(n), len(out)-12, "footer must fit in the file")
+		footer := out[len(out)-8-int(n) : len(out)-8]
+		for _, name := range inventory.Header {
+			require.Contains(t, string(footer), name)
+		}
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, inventory.WriteParquet(&b, testRealm()))
+	// Strings are PLAIN encoded, prefixed by their length.
+	require.Contains(t, b.String(), "\x0c\x00\x00\x00varchar(255)")
+	require.Contains(t, b.String(), "\x0b\x00\x00\x00primary key")
+}
+
+func testRealm() *schema.Realm {
+	id := schema.NewIntColumn("id", "bigint")
+	id.SetComment("primary key")
+	oid := schema.NewIntColumn("id", "bigint")
+	oid.SetComment(`the "order" id, or number`)
+	return schema.NewRealm(
+		schema.New("public").AddTables(
+			schema.NewTable("users").AddColumns(id, schema.NewNullStringColumn("name", "varchar(255)")),
+		),
+		schema.New("sales").AddTables(
+			schema.NewTable("orders").AddColumns(oid, schema.NewDecimalColumn("total", "decimal")),
+		),
+	)
+}
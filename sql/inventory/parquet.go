@@ -0,0 +1,319 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package inventory
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Parquet physical types, repetition types, converted types and encodings.
+// See: https://github.com/apache/parquet-format/blob/master/src/main/thrift/parquet.thrift
+const (
+	pqBoolean   = 0
+	pqInt32     = 1
+	pqByteArray = 6
+
+	pqRequired = 0
+	pqOptional = 1
+
+	pqUTF8 = 0
+
+	pqPlain = 0
+	pqRLE   = 3
+
+	pqDataPage     = 0
+	pqUncompressed = 0
+)
+
+// pqMagic is the magic number that starts and ends Parquet files.
+const pqMagic = "PAR1"
+
+// pqColumn describes a column in the Parquet file.
+type pqColumn struct {
+	name     string
+	typ      int32
+	optional bool
+	// value returns the value of the column in the given
+	// record. A nil value is a NULL in optional columns.
+	value func(*Column) any
+}
+
+// pqColumns holds the columns of the inventory file, in the order of the Header.
+var pqColumns = []*pqColumn{
+	{name: "schema", typ: pqByteArray, value: func(c *Column) any { return c.Schema }},
+	{name: "table", typ: pqByteArray, value: func(c *Column) any { return c.Table }},
+	{name: "column", typ: pqByteArray, value: func(c *Column) any { return c.Name }},
+	{name: "position", typ: pqInt32, value: func(c *Column) any { return int32(c.Position) }},
+	{name: "type", typ: pqByteArray, value: func(c *Column) any { return c.Type }},
+	{name: "nullable", typ: pqBoolean, value: func(c *Column) any { return c.Nullable }},
+	{name: "comment", typ: pqByteArray, optional: true, value: func(c *Column) any {
+		if c.Comment == "" {
+			return nil
+		}
+		return c.Comment
+	}},
+}
+
+// WriteParquet writes the column inventory of the realm as a Parquet file. The file holds a single
+// row group, and its pages are PLAIN encoded and uncompressed. Empty comments are written as NULLs.
+func WriteParquet(w io.Writer, r *schema.Realm) error {
+	var (
+		cs     = Columns(r)
+		b      bytes.Buffer
+		chunks = make([]*pqChunk, 0, len(pqColumns))
+	)
+	b.WriteString(pqMagic)
+	if len(cs) > 0 {
+		for _, pc := range pqColumns {
+			chunks = append(chunks, pc.writeChunk(&b, cs))
+		}
+	}
+	meta := pqFileMeta(int64(len(cs)), chunks)
+	b.Write(meta)
+	binary.Write(&b, binary.LittleEndian, uint32(len(meta)))
+	b.WriteString(pqMagic)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// pqChunk describes a column chunk written to the file.
+type pqChunk struct {
+	*pqColumn
+	offset int64 // Offset of the data page.
+	size   int64 // Size of the page header and its data.
+	values int64
+}
+
+// writeChunk writes the column chunk of the given records as a single data page.
+func (pc *pqColumn) writeChunk(b *bytes.Buffer, cs []*Column) *pqChunk {
+	var (
+		data    bytes.Buffer
+		values  = make([]any, 0, len(cs))
+		defined = make([]bool, 0, len(cs))
+	)
+	for _, c := range cs {
+		v := pc.value(c)
+		defined = append(defined, v != nil)
+		if v != nil {
+			values = append(values, v)
+		}
+	}
+	// Definition levels are written only for optional columns,
+	// prefixed by their length as required by data pages (v1).
+	if pc.optional {
+		levels := pqLevels(defined)
+		binary.Write(&data, binary.LittleEndian, uint32(len(levels)))
+		data.Write(levels)
+	}
+	pqPlainValues(&data, pc.typ, values)
+	t := &compact{}
+	t.begin()
+	t.i32(1, pqDataPage)
+	t.i32(2, int32(data.Len()))
+	t.i32(3, int32(data.Len()))
+	t.structField(5)
+	t.i32(1, int32(len(cs)))
+	t.i32(2, pqPlain)
+	t.i32(3, pqRLE)
+	t.i32(4, pqRLE)
+	t.end()
+	t.end()
+	chunk := &pqChunk{pqColumn: pc, offset: int64(b.Len()), size: int64(t.Len() + data.Len()), values: int64(len(cs))}
+	b.Write(t.Bytes())
+	b.Write(data.Bytes())
+	return chunk
+}
+
+// pqLevels encodes the definition levels of an optional column, using the RLE
+// part of the RLE/bit-packing hybrid encoding with a bit width of 1.
+func pqLevels(defined []bool) []byte {
+	var b bytes.Buffer
+	for i := 0; i < len(defined); {
+		j := i + 1
+		for j < len(defined) && defined[j] == defined[i] {
+			j++
+		}
+		b.Write(binary.AppendUvarint(nil, uint64(j-i)<<1))
+		if defined[i] {
+			b.WriteByte(1)
+		} else {
+			b.WriteByte(0)
+		}
+		i = j
+	}
+	return b.Bytes()
+}
+
+// pqPlainValues writes the values using the PLAIN encoding of the given type.
+func pqPlainValues(b *bytes.Buffer, typ int32, values []any) {
+	switch typ {
+	case pqBoolean:
+		bits := make([]byte, (len(values)+7)/8)
+		for i, v := range values {
+			if v.(bool) {
+				bits[i/8] |= 1 << (i % 8)
+			}
+		}
+		b.Write(bits)
+	case pqInt32:
+		for _, v := range values {
+			binary.Write(b, binary.LittleEndian, v.(int32))
+		}
+	case pqByteArray:
+		for _, v := range values {
+			s := v.(string)
+			binary.Write(b, binary.LittleEndian, uint32(len(s)))
+			b.WriteString(s)
+		}
+	}
+}
+
+// pqFileMeta returns the encoded FileMetaData of the file.
+func pqFileMeta(rows int64, chunks []*pqChunk) []byte {
+	t := &compact{}
+	t.begin()
+	t.i32(1, 1)
+	t.list(2, ctStruct, len(pqColumns)+1)
+	t.begin()
+	t.str(4, "inventory")
+	t.i32(5, int32(len(pqColumns)))
+	t.end()
+	for _, pc := range pqColumns {
+		t.begin()
+		t.i32(1, pc.typ)
+		if pc.optional {
+			t.i32(3, pqOptional)
+		} else {
+			t.i32(3, pqRequired)
+		}
+		t.str(4, pc.name)
+		if pc.typ == pqByteArray {
+			t.i32(6, pqUTF8)
+		}
+		t.end()
+	}
+	t.i64(3, rows)
+	// Empty inventories are written without row groups.
+	if len(chunks) == 0 {
+		t.list(4, ctStruct, 0)
+	} else {
+		var size int64
+		for _, c := range chunks {
+			size += c.size
+		}
+		t.list(4, ctStruct, 1)
+		t.begin()
+		t.list(1, ctStruct, len(chunks))
+		for _, c := range chunks {
+			t.begin()
+			t.i64(2, c.offset)
+			t.structField(3)
+			t.i32(1, c.typ)
+			t.list(2, ctI32, 2)
+			t.varint(pqPlain)
+			t.varint(pqRLE)
+			t.list(3, ctBinary, 1)
+			t.binary(c.name)
+			t.i32(4, pqUncompressed)
+			t.i64(5, c.values)
+			t.i64(6, c.size)
+			t.i64(7, c.size)
+			t.i64(9, c.offset)
+			t.end()
+			t.end()
+		}
+		t.i64(2, size)
+		t.i64(3, rows)
+		t.end()
+	}
+	t.str(6, "atlas")
+	t.end()
+	return t.Bytes()
+}
+
+// Type identifiers of the thrift compact protocol.
+const (
+	ctI32    = 5
+	ctI64    = 6
+	ctBinary = 8
+	ctList   = 9
+	ctStruct = 12
+)
+
+// compact is a minimal encoder of the thrift compact protocol,
+// used for writing the Parquet page headers and file metadata.
+type compact struct {
+	bytes.Buffer
+	last  int16   // Last field id written in the current struct.
+	stack []int16 // Last field ids of the enclosing structs.
+}
+
+// begin starts a struct. Structs in lists and top-level structs
+// are started with begin, and struct fields with structField.
+func (t *compact) begin() {
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+// end ends the current struct.
+func (t *compact) end() {
+	t.WriteByte(0)
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+func (t *compact) field(id int16, typ byte) {
+	if d := id - t.last; d > 0 && d <= 15 {
+		t.WriteByte(byte(d)<<4 | typ)
+	} else {
+		t.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.last = id
+}
+
+func (t *compact) structField(id int16) {
+	t.field(id, ctStruct)
+	t.begin()
+}
+
+func (t *compact) list(id int16, elem byte, n int) {
+	t.field(id, ctList)
+	if n < 15 {
+		t.WriteByte(byte(n)<<4 | elem)
+	} else {
+		t.WriteByte(0xF0 | elem)
+		t.Write(binary.AppendUvarint(nil, uint64(n)))
+	}
+}
+
+func (t *compact) i32(id int16, v int32) {
+	t.field(id, ctI32)
+	t.varint(int64(v))
+}
+
+func (t *compact) i64(id int16, v int64) {
+	t.field(id, ctI64)
+	t.varint(v)
+}
+
+func (t *compact) str(id int16, s string) {
+	t.field(id, ctBinary)
+	t.binary(s)
+}
+
+// varint writes a zigzag-encoded integer.
+func (t *compact) varint(v int64) {
+	t.Write(binary.AppendVarint(nil, v))
+}
+
+func (t *compact) binary(s string) {
+	t.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	t.WriteString(s)
+}
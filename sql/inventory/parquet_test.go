@@ -0,0 +1,284 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package inventory_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/inventory"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteParquet_Decode reads the written files back, following the Parquet
+// format specification, and compares the decoded rows with the inventory.
+func TestWriteParquet_Decode(t *testing.T) {
+	for _, r := range []*schema.Realm{testRealm(), schema.NewRealm()} {
+		var b bytes.Buffer
+		require.NoError(t, inventory.WriteParquet(&b, r))
+		var (
+			want [][]any
+			out  = b.Bytes()
+		)
+		for _, c := range inventory.Columns(r) {
+			var comment any
+			if c.Comment != "" {
+				comment = c.Comment
+			}
+			want = append(want, []any{c.Schema, c.Table, c.Name, int32(c.Position), c.Type, c.Nullable, comment})
+		}
+		require.Equal(t, want, readParquet(t, out))
+	}
+}
+
+// Parquet file layout and metadata field ids, as defined in parquet.thrift.
+const (
+	fileSchema    = 2
+	fileNumRows   = 3
+	fileRowGroups = 4
+
+	elemType       = 1
+	elemRepetition = 3
+	elemName       = 4
+	elemChildren   = 5
+
+	groupColumns   = 1
+	groupByteSize  = 2
+	groupNumRows   = 3
+	chunkMeta      = 3
+	metaType       = 1
+	metaPath       = 3
+	metaCodec      = 4
+	metaNumValues  = 5
+	metaTotalSize  = 7
+	metaDataOffset = 9
+
+	pageType       = 1
+	pageSize       = 3
+	pageDataHeader = 5
+	dataNumValues  = 1
+	dataEncoding   = 2
+)
+
+// readParquet decodes the rows of a Parquet file that holds PLAIN encoded and uncompressed
+// pages of required or optional (flat) columns. NULL values are returned as nil.
+func readParquet(t *testing.T, out []byte) [][]any {
+	require.Equal(t, "PAR1", string(out[:4]))
+	require.Equal(t, "PAR1", string(out[len(out)-4:]))
+	n := int(binary.LittleEndian.Uint32(out[len(out)-8:]))
+	require.LessOrEqual(t, n, len(out)-12, "footer must fit in the file")
+	d := &tdecoder{b: out[len(out)-8-n : len(out)-8]}
+	meta := d.readStruct()
+	require.Empty(t, d.b, "footer must be fully consumed")
+
+	elems := meta.list(fileSchema)
+	require.Len(t, elems, len(inventory.Header)+1)
+	root := elems[0].(tstruct)
+	require.EqualValues(t, len(inventory.Header), root[elemChildren])
+	cols := elems[1:]
+	for i, e := range cols {
+		require.Equal(t, inventory.Header[i], e.(tstruct).str(elemName))
+	}
+	rows := int(meta[fileNumRows].(int64))
+	if rows == 0 {
+		require.Empty(t, meta.list(fileRowGroups))
+		return nil
+	}
+	groups := meta.list(fileRowGroups)
+	require.Len(t, groups, 1)
+	group := groups[0].(tstruct)
+	require.EqualValues(t, rows, group[groupNumRows])
+	chunks := group.list(groupColumns)
+	require.Len(t, chunks, len(cols))
+	var (
+		size   int64
+		values = make([][]any, len(cols))
+	)
+	for i, c := range chunks {
+		var (
+			elem = cols[i].(tstruct)
+			cm   = c.(tstruct)[chunkMeta].(tstruct)
+		)
+		require.Equal(t, elem[elemType], cm[metaType])
+		require.Equal(t, []any{elem.str(elemName)}, stringsOf(cm.list(metaPath)))
+		require.EqualValues(t, 0, cm[metaCodec], "uncompressed")
+		require.EqualValues(t, rows, cm[metaNumValues])
+		// Read the data page of the chunk.
+		off, total := cm[metaDataOffset].(int64), cm[metaTotalSize].(int64)
+		require.LessOrEqual(t, off+total, int64(len(out)-8-n))
+		pd := &tdecoder{b: out[off : off+total]}
+		ph := pd.readStruct()
+		require.EqualValues(t, 0, ph[pageType], "data page")
+		dh := ph[pageDataHeader].(tstruct)
+		require.EqualValues(t, rows, dh[dataNumValues])
+		require.EqualValues(t, 0, dh[dataEncoding], "plain")
+		require.EqualValues(t, len(pd.b), ph[pageSize], "page must span the rest of the chunk")
+		values[i] = readPage(t, pd.b, elem[elemType].(int32), elem[elemRepetition] == int32(1), rows)
+		size += total
+	}
+	require.Equal(t, size, group[groupByteSize])
+	recs := make([][]any, rows)
+	for i := range recs {
+		for _, vs := range values {
+			recs[i] = append(recs[i], vs[i])
+		}
+	}
+	return recs
+}
+
+// readPage decodes the values of a data page (v1).
+func readPage(t *testing.T, b []byte, typ int32, optional bool, rows int) []any {
+	defined := make([]bool, 0, rows)
+	if optional {
+		n := int(binary.LittleEndian.Uint32(b))
+		levels := b[4 : 4+n]
+		b = b[4+n:]
+		// RLE/bit-packing hybrid encoding with a bit width of 1.
+		for len(levels) > 0 {
+			h, k := binary.Uvarint(levels)
+			require.Positive(t, k)
+			require.Zero(t, h&1, "bit-packed runs are not expected")
+			for i := 0; i < int(h>>1); i++ {
+				defined = append(defined, levels[k] == 1)
+			}
+			levels = levels[k+1:]
+		}
+	} else {
+		for i := 0; i < rows; i++ {
+			defined = append(defined, true)
+		}
+	}
+	require.Len(t, defined, rows)
+	var (
+		vs  = make([]any, 0, rows)
+		bit int
+	)
+	for _, ok := range defined {
+		if !ok {
+			vs = append(vs, nil)
+			continue
+		}
+		switch typ {
+		case 0:
+			vs = append(vs, b[bit/8]&(1<<(bit%8)) != 0)
+			bit++
+		case 1:
+			vs = append(vs, int32(binary.LittleEndian.Uint32(b)))
+			b = b[4:]
+		case 6:
+			n := int(binary.LittleEndian.Uint32(b))
+			vs = append(vs, string(b[4:4+n]))
+			b = b[4+n:]
+		default:
+			t.Fatalf("unexpected physical type %d", typ)
+		}
+	}
+	if typ == 0 {
+		b = b[(bit+7)/8:]
+	}
+	require.Empty(t, b, "page data must be fully consumed")
+	return vs
+}
+
+// tstruct is a decoded thrift struct, keyed by field ids.
+type tstruct map[int16]any
+
+func (s tstruct) list(id int16) []any {
+	l, _ := s[id].([]any)
+	return l
+}
+
+func (s tstruct) str(id int16) string {
+	return string(s[id].([]byte))
+}
+
+func stringsOf(l []any) []any {
+	vs := make([]any, len(l))
+	for i, v := range l {
+		vs[i] = string(v.([]byte))
+	}
+	return vs
+}
+
+// tdecoder decodes values encoded with the thrift compact protocol.
+type tdecoder struct{ b []byte }
+
+func (d *tdecoder) byte() byte {
+	c := d.b[0]
+	d.b = d.b[1:]
+	return c
+}
+
+func (d *tdecoder) varint() int64 {
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		panic("invalid varint")
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *tdecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		panic("invalid uvarint")
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *tdecoder) readStruct() tstruct {
+	var (
+		s    = make(tstruct)
+		last int16
+	)
+	for {
+		h := d.byte()
+		if h == 0 {
+			return s
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(d.varint())
+		}
+		last = id
+		s[id] = d.read(h & 0x0f)
+	}
+}
+
+func (d *tdecoder) read(typ byte) any {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case 5:
+		return int32(d.varint())
+	case 6:
+		return d.varint()
+	case 8:
+		n := d.uvarint()
+		v := d.b[:n]
+		d.b = d.b[n:]
+		return v
+	case 9:
+		h := d.byte()
+		n := uint64(h >> 4)
+		if n == 15 {
+			n = d.uvarint()
+		}
+		l := make([]any, 0, n)
+		for i := uint64(0); i < n; i++ {
+			l = append(l, d.read(h&0x0f))
+		}
+		return l
+	case 12:
+		return d.readStruct()
+	default:
+		panic(fmt.Sprintf("unexpected thrift type %d", typ))
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// An AuditRecord describes a single statement that was executed by the Executor.
+	// Unlike revisions, audit records are never updated or deleted, and they are
+	// kept in a sink that is independent of the migrated database.
+	AuditRecord struct {
+		Time      time.Time     `json:"time"`            // Time the statement execution started.
+		Duration  time.Duration `json:"duration"`        // Execution duration.
+		Principal string        `json:"principal"`       // Identity of the executor, e.g. OS or CI user.
+		Target    string        `json:"target"`          // Target database URL, without credentials.
+		Version   string        `json:"version"`         // Version of the migration file.
+		File      string        `json:"file"`            // Name of the migration file.
+		Stmt      string        `json:"stmt"`            // Executed statement.
+		Error     string        `json:"error,omitempty"` // Execution error, if any.
+	}
+
+	// AuditSink records the statements executed by the Executor, e.g. for compliance
+	// requirements. A failure to record a statement stops the migration execution.
+	AuditSink interface {
+		Audit(context.Context, *AuditRecord) error
+	}
+
+	// AuditFunc allows using an ordinary function as an AuditSink.
+	AuditFunc func(context.Context, *AuditRecord) error
+
+	// auditor records the statements of an Executor to its sink.
+	auditor struct {
+		sink              AuditSink
+		principal, target string
+	}
+)
+
+// Audit implements the AuditSink interface.
+func (f AuditFunc) Audit(ctx context.Context, r *AuditRecord) error {
+	return f(ctx, r)
+}
+
+// WithAuditSink configures the Executor to record every executed statement to the given sink,
+// including statements that failed. The principal and the target are attached to all records.
+// Note that the target should not contain any credentials, e.g. use the redacted database URL.
+func WithAuditSink(s AuditSink, principal, target string) ExecutorOption {
+	return func(ex *Executor) error {
+		if s == nil {
+			return errors.New("sql/migrate: execute: nil audit sink")
+		}
+		ex.audit = &auditor{sink: s, principal: principal, target: target}
+		return nil
+	}
+}
+
+// record records the given statement, if auditing is enabled.
+func (a *auditor) record(ctx context.Context, f File, stmt string, start time.Time, err string) error {
+	if a == nil {
+		return nil
+	}
+	r := &AuditRecord{
+		Time:      start,
+		Duration:  time.Since(start),
+		Principal: a.principal,
+		Target:    a.target,
+		Version:   f.Version(),
+		File:      f.Name(),
+		Stmt:      stmt,
+		Error:     err,
+	}
+	if err := a.sink.Audit(ctx, r); err != nil {
+		return fmt.Errorf("sql/migrate: execute: audit statement %q from version %q: %w", stmt, r.Version, err)
+	}
+	return nil
+}
+
+// FileAuditSink is an AuditSink that writes records as JSON lines.
+type FileAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewFileAuditSink returns an AuditSink that writes the records as JSON lines to the given writer,
+// usually a file opened in append mode. If the writer implements the Sync method (e.g. *os.File),
+// it is called after every record to ensure the record is persisted before execution continues.
+func NewFileAuditSink(w io.Writer) *FileAuditSink {
+	return &FileAuditSink{w: w}
+}
+
+// Audit implements the AuditSink interface.
+func (s *FileAuditSink) Audit(_ context.Context, r *AuditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if f, ok := s.w.(interface{ Sync() error }); ok {
+		return f.Sync()
+	}
+	return nil
+}
+
+// TableAuditSink is an AuditSink that inserts records to a database table.
+type TableAuditSink struct {
+	db      schema.ExecQuerier
+	table   string
+	dollar  bool
+	mu      sync.Mutex
+	created bool
+}
+
+// NewTableAuditSink returns an AuditSink that inserts the records to the given table. The table is
+// created on first use, if it does not exist. Set dollar to true to use numbered placeholders ($1),
+// as required by PostgreSQL, instead of question marks (?).
+//
+// The table name is quoted by db, if it implements the IdentQuoter interface (e.g. a Driver).
+// Otherwise, it must be a plain identifier, as it is written to the statements as is.
+func NewTableAuditSink(db schema.ExecQuerier, table string, dollar bool) *TableAuditSink {
+	return &TableAuditSink{db: db, table: table, dollar: dollar}
+}
+
+// Audit implements the AuditSink interface.
+func (s *TableAuditSink) Audit(ctx context.Context, r *AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	table, err := s.ident()
+	if err != nil {
+		return err
+	}
+	if !s.created {
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (executed_at timestamp NOT NULL, duration bigint NOT NULL, principal varchar(255) NOT NULL, target varchar(2048) NOT NULL, version varchar(255) NOT NULL, file varchar(255) NOT NULL, stmt text NOT NULL, error text NULL)",
+			table,
+		)); err != nil {
+			return fmt.Errorf("create audit table: %w", err)
+		}
+		s.created = true
+	}
+	args := make([]string, 8)
+	for i := range args {
+		args[i] = "?"
+		if s.dollar {
+			args[i] = "$" + strconv.Itoa(i+1)
+		}
+	}
+	var errV any
+	if r.Error != "" {
+		errV = r.Error
+	}
+	if _, err := s.db.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (executed_at, duration, principal, target, version, file, stmt, error) VALUES (%s)", table, strings.Join(args, ", ")),
+		r.Time.UTC(), int64(r.Duration), r.Principal, r.Target, r.Version, r.File, r.Stmt, errV,
+	); err != nil {
+		return fmt.Errorf("insert audit record: %w", err)
+	}
+	return nil
+}
+
+// ident returns the table name to be written to the statements.
+func (s *TableAuditSink) ident() (string, error) {
+	if q, ok := s.db.(IdentQuoter); ok {
+		return q.QuoteIdent(s.table), nil
+	}
+	for i, r := range s.table {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return "", fmt.Errorf("sql/migrate: audit table name %q must be quoted by the driver", s.table)
+		}
+	}
+	if s.table == "" {
+		return "", errors.New("sql/migrate: missing audit table name")
+	}
+	return s.table, nil
+}
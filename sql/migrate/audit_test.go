@@ -0,0 +1,118 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_AuditSink(t *testing.T) {
+	var (
+		recs []*migrate.AuditRecord
+		drv  = &mockDriver{}
+		rrw  = &mockRevisionReadWriter{}
+		sink = migrate.AuditFunc(func(_ context.Context, r *migrate.AuditRecord) error {
+			recs = append(recs, r)
+			return nil
+		})
+	)
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	_, err = migrate.NewExecutor(drv, dir, rrw, migrate.WithAuditSink(nil, "", ""))
+	require.EqualError(t, err, "sql/migrate: execute: nil audit sink")
+	ex, err := migrate.NewExecutor(drv, dir, rrw, migrate.WithAuditSink(sink, "a8m", "mysql://localhost/test"))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	require.Len(t, recs, 2)
+	for i, stmt := range []string{"CREATE TABLE t_sub(c int);", "ALTER TABLE t_sub ADD c1 int;"} {
+		require.Equal(t, stmt, recs[i].Stmt)
+		require.Equal(t, "a8m", recs[i].Principal)
+		require.Equal(t, "mysql://localhost/test", recs[i].Target)
+		require.Equal(t, "1.a", recs[i].Version)
+		require.Equal(t, "1.a_sub.up.sql", recs[i].File)
+		require.Empty(t, recs[i].Error)
+		require.False(t, recs[i].Time.IsZero())
+	}
+
+	// Failed statements are recorded.
+	recs, *rrw, *drv = nil, mockRevisionReadWriter{}, mockDriver{}
+	drv.failOn(2, errors.New("this is an error"))
+	require.ErrorContains(t, ex.ExecuteN(context.Background(), 1), "this is an error")
+	require.Len(t, recs, 2)
+	require.Equal(t, "ALTER TABLE t_sub ADD c1 int;", recs[1].Stmt)
+	require.Equal(t, "this is an error", recs[1].Error)
+
+	// Sink failures stop the execution.
+	*rrw, *drv = mockRevisionReadWriter{}, mockDriver{}
+	ex, err = migrate.NewExecutor(drv, dir, rrw, migrate.WithAuditSink(migrate.AuditFunc(func(context.Context, *migrate.AuditRecord) error {
+		return errors.New("sink is down")
+	}), "", ""))
+	require.NoError(t, err)
+	require.EqualError(t, ex.ExecuteN(context.Background(), 1), `sql/migrate: execute: audit statement "CREATE TABLE t_sub(c int);" from version "1.a": sink is down`)
+	require.Equal(t, []string{"CREATE TABLE t_sub(c int);"}, drv.executed)
+	revs, err := rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.Equal(t, 1, revs[0].Applied)
+}
+
+func TestFileAuditSink(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		sink = migrate.NewFileAuditSink(&buf)
+	)
+	require.NoError(t, sink.Audit(context.Background(), &migrate.AuditRecord{Stmt: "CREATE TABLE t(c int);", Version: "1"}))
+	require.NoError(t, sink.Audit(context.Background(), &migrate.AuditRecord{Stmt: "DROP TABLE t;", Version: "2", Error: "failed"}))
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var r migrate.AuditRecord
+	require.NoError(t, json.Unmarshal(lines[1], &r))
+	require.Equal(t, "DROP TABLE t;", r.Stmt)
+	require.Equal(t, "failed", r.Error)
+}
+
+func TestTableAuditSink(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	r := &migrate.AuditRecord{Principal: "a8m", Target: "postgres://localhost/test", Version: "1", File: "1.sql", Stmt: "CREATE TABLE t(c int);"}
+	m.ExpectExec(`CREATE TABLE IF NOT EXISTS atlas_audit \(executed_at timestamp NOT NULL, .+\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(`INSERT INTO atlas_audit \(executed_at, duration, principal, target, version, file, stmt, error\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8\)`).
+		WithArgs(sqlmock.AnyArg(), 0, "a8m", "postgres://localhost/test", "1", "1.sql", "CREATE TABLE t(c int);", nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Table is created only once.
+	m.ExpectExec(`INSERT INTO atlas_audit`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sink := migrate.NewTableAuditSink(db, "atlas_audit", true)
+	require.NoError(t, sink.Audit(context.Background(), r))
+	require.NoError(t, sink.Audit(context.Background(), r))
+	require.NoError(t, m.ExpectationsWereMet())
+
+	// Names are quoted by drivers, and must be plain identifiers otherwise.
+	err = migrate.NewTableAuditSink(db, "audit; DROP TABLE users", true).Audit(context.Background(), r)
+	require.EqualError(t, err, `sql/migrate: audit table name "audit; DROP TABLE users" must be quoted by the driver`)
+	m.ExpectExec(`CREATE TABLE IF NOT EXISTS "audit log" \(executed_at timestamp NOT NULL, .+\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(`INSERT INTO "audit log" \(executed_at, .+\)`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sink = migrate.NewTableAuditSink(quoter{db}, "audit log", true)
+	require.NoError(t, sink.Audit(context.Background(), r))
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+type quoter struct{ schema.ExecQuerier }
+
+func (quoter) QuoteIdent(s string) string { return `"` + s + `"` }
@@ -0,0 +1,272 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// atlas:backfill directive.
+const directiveBackfill = "backfill"
+
+type (
+	// LogBackfill is sent after each batch of a backfill statement was executed.
+	LogBackfill struct {
+		SQL      string // The backfill statement.
+		Batch    int    // The batch number, starting from 1.
+		From, To int64  // The key range of the batch, [From, To].
+		Rows     int64  // The number of rows affected by the batch.
+		Total    int64  // The number of rows affected by all executed batches.
+	}
+
+	// backfiller holds the default batching configuration of backfill statements.
+	backfiller struct {
+		size  int
+		sleep time.Duration
+	}
+
+	// backfill describes the batching of a single backfill statement.
+	backfill struct {
+		table, alias, key string
+		size              int
+		sleep             time.Duration
+	}
+)
+
+func (LogBackfill) logEntry() {}
+
+// WithBackfill configures the Executor to run statements marked as backfills in key-range batches,
+// instead of a single statement, to avoid long-running locks and replication lag. An UPDATE or DELETE
+// statement is marked as a backfill using the "atlas:backfill" directive, that sets the integer key
+// column used to split the table into batches, and optionally overrides the batch size and the sleep
+// duration between batches. For example:
+//
+//	-- atlas:backfill key=id batch=1000 sleep=100ms
+//	UPDATE users SET name = concat(first_name, ' ', last_name) WHERE name IS NULL;
+//
+// The table is paginated by the key column, and the statement is executed once for every page of
+// (at most) size rows, with the key range of the page added to its WHERE clause. A LogBackfill entry
+// is sent to the Logger after every batch, and in case a batch fails, the next execution resumes the
+// statement after the last completed batch. Note, batches release their locks only if the migration
+// file is not executed in a transaction.
+func WithBackfill(size int, sleep time.Duration) ExecutorOption {
+	return func(ex *Executor) error {
+		if size <= 0 {
+			return fmt.Errorf("sql/migrate: backfill batch size must be positive, got %d", size)
+		}
+		ex.backfill = &backfiller{size: size, sleep: sleep}
+		return nil
+	}
+}
+
+// reBackfillTable extracts the target table of UPDATE and DELETE statements, and its alias.
+var reBackfillTable = regexp.MustCompile(`(?is)^\s*(?:UPDATE|DELETE\s+FROM)\s+(?:ONLY\s+)?([^\s(,;]+)(?:\s+(?:AS\s+)?([^\s(,;]+))?`)
+
+// notAlias holds the keywords that may follow the target table of UPDATE and DELETE statements.
+var notAlias = map[string]bool{
+	"SET": true, "WHERE": true, "USING": true, "FROM": true, "RETURNING": true, "ORDER": true, "LIMIT": true,
+	"JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "CROSS": true, "NATURAL": true, "STRAIGHT_JOIN": true,
+	"PARTITION": true,
+}
+
+// backfillOf returns the batching configuration of the given statement,
+// or nil if the statement is not marked as a backfill.
+func (b *backfiller) backfillOf(stmt *Stmt) (*backfill, error) {
+	ds := stmt.Directive(directiveBackfill)
+	if b == nil || len(ds) == 0 {
+		return nil, nil
+	}
+	m := reBackfillTable.FindStringSubmatch(stmt.Text)
+	if m == nil {
+		return nil, fmt.Errorf("backfill directive is supported only for UPDATE and DELETE statements")
+	}
+	bf := &backfill{table: m[1], size: b.size, sleep: b.sleep}
+	if m[2] != "" && !notAlias[strings.ToUpper(m[2])] {
+		bf.alias = m[2]
+	}
+	for _, arg := range strings.Fields(ds[0]) {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("unexpected backfill directive argument %q", arg)
+		}
+		switch k {
+		case "key":
+			bf.key = v
+		case "batch":
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid backfill batch size %q", v)
+			}
+			bf.size = n
+		case "sleep":
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid backfill sleep duration %q: %w", v, err)
+			}
+			bf.sleep = d
+		default:
+			return nil, fmt.Errorf("unknown backfill directive argument %q", k)
+		}
+	}
+	if bf.key == "" {
+		return nil, errors.New("backfill directive requires a key column")
+	}
+	return bf, nil
+}
+
+// execBackfill executes the given statement in batches, using keyset pagination on the key
+// column. Batches start after the given key, if set, and the key of the last completed batch
+// is returned, to allow resuming the statement in case of failure.
+func (e *Executor) execBackfill(ctx context.Context, stmt string, bf *backfill, after *int64) (*int64, error) {
+	key, table := bf.key, bf.table
+	if bf.alias != "" {
+		table += " AS " + bf.alias
+	}
+	// Keys that are not qualified by the user are quoted
+	// and qualified to avoid ambiguity in joins.
+	if !strings.Contains(key, ".") {
+		if q, ok := e.drv.(IdentQuoter); ok {
+			key = q.QuoteIdent(key)
+		}
+		if bf.alias != "" {
+			key = bf.alias + "." + key
+		} else {
+			key = bf.table + "." + key
+		}
+	}
+	var total int64
+	for i := 1; ; i++ {
+		var where string
+		if after != nil {
+			where = fmt.Sprintf(" WHERE %s > %d", key, *after)
+		}
+		from, to, err := e.backfillPage(ctx, fmt.Sprintf(
+			"SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT %[1]s AS atlas_key FROM %[2]s%[3]s ORDER BY %[1]s LIMIT %[4]d) AS atlas_page",
+			key, table, where, bf.size,
+		))
+		if err != nil || !to.Valid {
+			return after, err
+		}
+		if bf.sleep > 0 && i > 1 {
+			select {
+			case <-ctx.Done():
+				return after, ctx.Err()
+			case <-time.After(bf.sleep):
+			}
+		}
+		batch, err := withKeyRange(stmt, key, from.Int64, to.Int64)
+		if err != nil {
+			return after, err
+		}
+		res, err := e.execStmt(ctx, batch)
+		if err != nil {
+			return after, fmt.Errorf("backfill batch [%d, %d]: %w", from.Int64, to.Int64, err)
+		}
+		var n int64
+		// Drivers that do not report the affected rows are ignored.
+		if res != nil {
+			n, _ = res.RowsAffected()
+		}
+		total += n
+		after = &to.Int64
+		e.log.Log(LogBackfill{SQL: stmt, Batch: i, From: from.Int64, To: to.Int64, Rows: n, Total: total})
+	}
+}
+
+// backfillPage returns the key range of the next page of a backfill.
+// The returned keys are invalid if no rows are left in the table.
+func (e *Executor) backfillPage(ctx context.Context, query string) (from, to sql.NullInt64, err error) {
+	rows, err := e.drv.QueryContext(ctx, query)
+	if err != nil {
+		return from, to, fmt.Errorf("query backfill key range: %w", err)
+	}
+	if rows.Next() {
+		err = rows.Scan(&from, &to)
+	}
+	if err2 := rows.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return from, to, fmt.Errorf("scan backfill key range: %w", err)
+	}
+	return from, to, nil
+}
+
+// withKeyRange returns the statement with the key range condition added to its WHERE clause.
+// Clauses that follow the WHERE clause (e.g., RETURNING or ORDER BY) are preserved.
+func withKeyRange(stmt, key string, from, to int64) (string, error) {
+	stmt = strings.TrimRight(strings.TrimSpace(stmt), ";")
+	var (
+		cond  = fmt.Sprintf("%[1]s >= %[2]d AND %[1]s <= %[3]d", key, from, to)
+		where = -1
+		end   = len(stmt)
+	)
+	for _, w := range topLevelWords(stmt) {
+		switch k := strings.ToUpper(stmt[w[0]:w[1]]); {
+		case k == "WHERE" && where == -1:
+			where = w[1]
+		case (k == "RETURNING" || k == "ORDER" || k == "LIMIT") && end == len(stmt):
+			end = w[0]
+		}
+	}
+	if end < where {
+		return "", fmt.Errorf("unexpected backfill statement: %q", stmt)
+	}
+	if where == -1 {
+		return strings.TrimSpace(stmt[:end]) + " WHERE " + cond + suffix(stmt[end:]), nil
+	}
+	return fmt.Sprintf("%s (%s) AND %s%s", stmt[:where], strings.TrimSpace(stmt[where:end]), cond, suffix(stmt[end:])), nil
+}
+
+// suffix returns the trailing clauses of a statement, prefixed with a space.
+func suffix(s string) string {
+	if s = strings.TrimSpace(s); s != "" {
+		return " " + s
+	}
+	return ""
+}
+
+// topLevelWords returns the positions of the words in the given statement that
+// are not nested in parentheses, string literals or quoted identifiers.
+func topLevelWords(stmt string) (words [][2]int) {
+	var (
+		depth int
+		quote rune
+		start = -1
+	)
+	for i, r := range stmt {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+		if start != -1 && !isWord {
+			words = append(words, [2]int{start, i})
+			start = -1
+		}
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case isWord && depth == 0 && start == -1:
+			start = i
+		}
+	}
+	if start != -1 {
+		words = append(words, [2]int{start, len(stmt)})
+	}
+	return words
+}
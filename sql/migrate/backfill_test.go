@@ -0,0 +1,189 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	backfillDriver struct {
+		migrate.Driver
+		db *sql.DB
+	}
+	backfillLogger      []migrate.LogBackfill
+	quoteBackfillDriver struct{ *backfillDriver }
+)
+
+func (*quoteBackfillDriver) QuoteIdent(s string) string {
+	return "`" + s + "`"
+}
+
+func (d *backfillDriver) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return d.db.ExecContext(ctx, query, args...)
+}
+
+func (d *backfillDriver) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.db.QueryContext(ctx, query, args...)
+}
+
+func (*backfillDriver) Snapshot(context.Context) (migrate.RestoreFunc, error) {
+	return func(context.Context) error { return nil }, nil
+}
+
+func (*backfillDriver) CheckClean(context.Context, *migrate.TableIdent) error {
+	return nil
+}
+
+func (l *backfillLogger) Log(e migrate.LogEntry) {
+	if e, ok := e.(migrate.LogBackfill); ok {
+		*l = append(*l, e)
+	}
+}
+
+func TestExecutor_Backfill(t *testing.T) {
+	db, m, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	var (
+		log backfillLogger
+		drv = &backfillDriver{db: db}
+		rrw = &mockRevisionReadWriter{}
+		dir = &migrate.MemDir{}
+	)
+	require.NoError(t, dir.WriteFile("1_backfill.sql", []byte(`-- atlas:backfill key=id batch=10
+UPDATE users SET name = upper(name) WHERE name IS NOT NULL;
+
+-- atlas:backfill key=id
+DELETE FROM logs RETURNING id;
+
+DELETE FROM sessions;
+`)))
+	sum, err := dir.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+	_, err = migrate.NewExecutor(drv, dir, rrw, migrate.WithBackfill(0, 0))
+	require.EqualError(t, err, "sql/migrate: backfill batch size must be positive, got 0")
+	ex, err := migrate.NewExecutor(drv, dir, rrw, migrate.WithLogger(&log), migrate.WithBackfill(100, time.Millisecond))
+	require.NoError(t, err)
+
+	// Keys are paginated, and sparse keys do not produce empty batches.
+	m.ExpectQuery("SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT users.id AS atlas_key FROM users ORDER BY users.id LIMIT 10) AS atlas_page").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(1, 10))
+	m.ExpectExec("UPDATE users SET name = upper(name) WHERE (name IS NOT NULL) AND users.id >= 1 AND users.id <= 10").
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	m.ExpectQuery("SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT users.id AS atlas_key FROM users WHERE users.id > 10 ORDER BY users.id LIMIT 10) AS atlas_page").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(11, 1000000000000))
+	m.ExpectExec("UPDATE users SET name = upper(name) WHERE (name IS NOT NULL) AND users.id >= 11 AND users.id <= 1000000000000").
+		WillReturnResult(sqlmock.NewResult(0, 8))
+	m.ExpectQuery("SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT users.id AS atlas_key FROM users WHERE users.id > 1000000000000 ORDER BY users.id LIMIT 10) AS atlas_page").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(nil, nil))
+	m.ExpectQuery("SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT logs.id AS atlas_key FROM logs ORDER BY logs.id LIMIT 100) AS atlas_page").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(nil, nil))
+	m.ExpectExec("DELETE FROM sessions;").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, backfillLogger{
+		{SQL: "UPDATE users SET name = upper(name) WHERE name IS NOT NULL;", Batch: 1, From: 1, To: 10, Rows: 10, Total: 10},
+		{SQL: "UPDATE users SET name = upper(name) WHERE name IS NOT NULL;", Batch: 2, From: 11, To: 1000000000000, Rows: 8, Total: 18},
+	}, log)
+	revs, err := rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.Equal(t, 3, revs[0].Applied)
+
+	// Clauses following the WHERE clause are preserved.
+	log = nil
+	require.NoError(t, dir.WriteFile("2_backfill.sql", []byte(`-- atlas:backfill key=l.id batch=50
+DELETE FROM logs AS l WHERE l.created_at < now() - interval '30 days' RETURNING l.id;
+`)))
+	sum, err = dir.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+	m.ExpectQuery("SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT l.id AS atlas_key FROM logs AS l ORDER BY l.id LIMIT 50) AS atlas_page").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(1, 1))
+	m.ExpectExec("DELETE FROM logs AS l WHERE (l.created_at < now() - interval '30 days') AND l.id >= 1 AND l.id <= 1 RETURNING l.id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectQuery("SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT l.id AS atlas_key FROM logs AS l WHERE l.id > 1 ORDER BY l.id LIMIT 50) AS atlas_page").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(nil, nil))
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Len(t, log, 1)
+}
+
+func TestExecutor_BackfillResume(t *testing.T) {
+	db, m, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	var (
+		rrw = &mockRevisionReadWriter{}
+		dir = &migrate.MemDir{}
+		drv = &quoteBackfillDriver{&backfillDriver{db: db}}
+	)
+	require.NoError(t, dir.WriteFile("1_backfill.sql", []byte(`-- atlas:backfill key=id batch=10
+UPDATE users u JOIN teams t ON t.id = u.team_id SET u.team = t.name;
+`)))
+	sum, err := dir.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+	ex, err := migrate.NewExecutor(drv, dir, rrw, migrate.WithBackfill(100, 0))
+	require.NoError(t, err)
+
+	// Keys are quoted and qualified by the table alias.
+	m.ExpectQuery("SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT u.`id` AS atlas_key FROM users AS u ORDER BY u.`id` LIMIT 10) AS atlas_page").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(1, 10))
+	m.ExpectExec("UPDATE users u JOIN teams t ON t.id = u.team_id SET u.team = t.name WHERE u.`id` >= 1 AND u.`id` <= 10").
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	m.ExpectQuery("SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT u.`id` AS atlas_key FROM users AS u WHERE u.`id` > 10 ORDER BY u.`id` LIMIT 10) AS atlas_page").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(11, 20))
+	m.ExpectExec("UPDATE users u JOIN teams t ON t.id = u.team_id SET u.team = t.name WHERE u.`id` >= 11 AND u.`id` <= 20").
+		WillReturnError(errors.New("lock wait timeout"))
+	require.Error(t, ex.ExecuteN(context.Background(), 0))
+	require.NoError(t, m.ExpectationsWereMet())
+	revs, err := rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.Len(t, revs[0].Stmts, 1)
+	require.Equal(t, int64(10), *revs[0].Stmts[0].BackfillKey)
+
+	// The failed backfill resumes after its last completed batch.
+	m.ExpectQuery("SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT u.`id` AS atlas_key FROM users AS u WHERE u.`id` > 10 ORDER BY u.`id` LIMIT 10) AS atlas_page").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(11, 20))
+	m.ExpectExec("UPDATE users u JOIN teams t ON t.id = u.team_id SET u.team = t.name WHERE u.`id` >= 11 AND u.`id` <= 20").
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	m.ExpectQuery("SELECT MIN(atlas_key), MAX(atlas_key) FROM (SELECT u.`id` AS atlas_key FROM users AS u WHERE u.`id` > 20 ORDER BY u.`id` LIMIT 10) AS atlas_page").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(nil, nil))
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.NoError(t, m.ExpectationsWereMet())
+	revs, err = rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.Equal(t, 1, revs[0].Applied)
+	require.Empty(t, revs[0].Error)
+	require.Nil(t, revs[0].Stmts[0].BackfillKey)
+}
+
+func TestExecutor_BackfillInvalid(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	dir := &migrate.MemDir{}
+	require.NoError(t, dir.WriteFile("1_backfill.sql", []byte(`-- atlas:backfill batch=10
+UPDATE users SET name = upper(name);
+`)))
+	sum, err := dir.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+	ex, err := migrate.NewExecutor(&backfillDriver{db: db}, dir, &mockRevisionReadWriter{}, migrate.WithBackfill(100, 0))
+	require.NoError(t, err)
+	require.EqualError(t, ex.ExecuteN(context.Background(), 0), `sql/migrate: execute: executing statement "UPDATE users SET name = upper(name);" from version "1": backfill directive requires a key column`)
+}
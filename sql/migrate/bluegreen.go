@@ -0,0 +1,190 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// SchemaSwapper is implemented by drivers that support swapping the names
+	// of two schemas atomically. Clients that resolve objects by schema name
+	// (e.g., using the search_path) see the swapped schemas at once.
+	SchemaSwapper interface {
+		SwapSchemas(ctx context.Context, a, b string) error
+	}
+
+	// SequenceCloner is implemented by drivers that support sequences which are not part of the
+	// inspected schema, but may be referenced by its column defaults (e.g., standalone sequences).
+	// CloneSequences creates copies of the sequences of schema from in the cloned schema s, and
+	// rewrites the column defaults of s to reference them. The returned function sets the copies
+	// to the current values of their source sequences, and is called after the data was copied.
+	SequenceCloner interface {
+		CloneSequences(ctx context.Context, from string, s *schema.Schema) (func(context.Context) error, error)
+	}
+
+	cloneConfig struct {
+		data bool // copy the data of the schema
+	}
+	// CloneOption configures the behavior of CloneSchema.
+	CloneOption func(*cloneConfig)
+)
+
+// ErrSwapUnsupported is returned by SwapSchemas if the driver does not implement the SchemaSwapper interface.
+var ErrSwapUnsupported = errors.New("sql/migrate: driver does not support swapping schemas")
+
+// CloneWithData configures CloneSchema to copy the rows of
+// the source tables to the cloned tables.
+func CloneWithData() CloneOption {
+	return func(c *cloneConfig) {
+		c.data = true
+	}
+}
+
+// CloneSchema clones the structure of the schema named from, and optionally its data, under a new schema
+// named to. Together with SwapSchemas, it enables blue/green migrations: the live (blue) schema is cloned
+// into a green schema, pending migrations are executed on the green schema, and the two schemas are swapped
+// once the green schema is ready:
+//
+//	if err := migrate.CloneSchema(ctx, drv, "app", "app_green", migrate.CloneWithData()); err != nil {
+//		return err
+//	}
+//	// Execute the migration directory on a connection bound to the "app_green" schema.
+//	if err := migrate.SwapSchemas(ctx, drv, "app", "app_green"); err != nil {
+//		return err
+//	}
+//
+// When data is copied, the sequences of serial and identity columns of the cloned tables are set to continue
+// after the copied values. Sequences that are not owned by columns are cloned by drivers that implement the
+// SequenceCloner interface. Note, rows written to the source schema after it was cloned are not copied to the
+// new schema.
+func CloneSchema(ctx context.Context, drv Driver, from, to string, opts ...CloneOption) error {
+	c := &cloneConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	switch _, err := drv.InspectSchema(ctx, to, nil); {
+	case err == nil:
+		return fmt.Errorf("sql/migrate: schema %q already exists", to)
+	case !schema.IsNotExistError(err):
+		return err
+	}
+	src, err := drv.InspectSchema(ctx, from, nil)
+	if err != nil {
+		return err
+	}
+	// Inspect the schema again to get a copy of its
+	// objects, that are then renamed to the new schema.
+	dst, err := drv.InspectSchema(ctx, from, nil)
+	if err != nil {
+		return err
+	}
+	dst.Name = to
+	changes, err := drv.RealmDiff(schema.NewRealm(), schema.NewRealm(dst))
+	if err != nil {
+		return err
+	}
+	var syncSeqs func(context.Context) error
+	if sc, ok := drv.(SequenceCloner); ok {
+		// Sequences are created after the schema, and
+		// before the tables whose defaults reference them.
+		var add []schema.Change
+		for i := 0; i < len(changes); i++ {
+			if _, ok := changes[i].(*schema.AddSchema); ok {
+				add = append(add, changes[i])
+				changes = append(changes[:i], changes[i+1:]...)
+				i--
+			}
+		}
+		if err := drv.ApplyChanges(ctx, add); err != nil {
+			return err
+		}
+		if syncSeqs, err = sc.CloneSequences(ctx, from, dst); err != nil {
+			return err
+		}
+	}
+	if c.data {
+		tables, err := copyOrder(src.Tables)
+		if err != nil {
+			return err
+		}
+		for _, t := range tables {
+			t2, ok := dst.Table(t.Name)
+			if !ok {
+				return fmt.Errorf("sql/migrate: table %q was not found in cloned schema %q", t.Name, to)
+			}
+			var columns []string
+			for _, col := range t.Columns {
+				// Generated columns are computed by the database.
+				if !generated(col) {
+					columns = append(columns, col.Name)
+				}
+			}
+			changes = append(changes, &CopyTable{From: t, To: t2, Columns: columns})
+		}
+	}
+	if err := drv.ApplyChanges(ctx, changes); err != nil {
+		return err
+	}
+	if c.data && syncSeqs != nil {
+		return syncSeqs(ctx)
+	}
+	return nil
+}
+
+// SwapSchemas atomically swaps the names of the two given schemas, using the driver
+// SchemaSwapper implementation. ErrSwapUnsupported is returned if it is not supported.
+func SwapSchemas(ctx context.Context, drv Driver, a, b string) error {
+	s, ok := drv.(SchemaSwapper)
+	if !ok {
+		return ErrSwapUnsupported
+	}
+	return s.SwapSchemas(ctx, a, b)
+}
+
+// copyOrder returns the tables ordered by their foreign keys, such that
+// referenced tables are returned before the tables that reference them.
+func copyOrder(tables []*schema.Table) ([]*schema.Table, error) {
+	var (
+		ordered = make([]*schema.Table, 0, len(tables))
+		visited = make(map[*schema.Table]bool, len(tables))
+	)
+	for len(ordered) < len(tables) {
+		n := len(ordered)
+	Next:
+		for _, t := range tables {
+			if visited[t] {
+				continue
+			}
+			for _, fk := range t.ForeignKeys {
+				// Self-references and references to other schemas
+				// are not considered, as they are copied as-is.
+				if ref := fk.RefTable; ref != nil && ref != t && ref.Schema == t.Schema && !visited[ref] {
+					continue Next
+				}
+			}
+			visited[t] = true
+			ordered = append(ordered, t)
+		}
+		if len(ordered) == n {
+			return nil, errors.New("sql/migrate: cannot copy tables with circular foreign keys")
+		}
+	}
+	return ordered, nil
+}
+
+// generated reports if the column is a generated column.
+func generated(c *schema.Column) bool {
+	for _, a := range c.Attrs {
+		if _, ok := a.(*schema.GeneratedExpr); ok {
+			return true
+		}
+	}
+	return false
+}
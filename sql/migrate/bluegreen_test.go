@@ -0,0 +1,121 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+type cloneDriver struct {
+	*mockDriver
+	schemas map[string]func() *schema.Schema
+}
+
+func (d *cloneDriver) InspectSchema(_ context.Context, name string, _ *schema.InspectOptions) (*schema.Schema, error) {
+	f, ok := d.schemas[name]
+	if !ok {
+		return nil, &schema.NotExistError{}
+	}
+	return f(), nil
+}
+
+func (d *cloneDriver) RealmDiff(_, to *schema.Realm, _ ...schema.DiffOption) ([]schema.Change, error) {
+	changes := []schema.Change{&schema.AddSchema{S: to.Schemas[0]}}
+	for _, t := range to.Schemas[0].Tables {
+		changes = append(changes, &schema.AddTable{T: t})
+	}
+	return changes, nil
+}
+
+func TestCloneSchema(t *testing.T) {
+	drv := &cloneDriver{
+		mockDriver: &mockDriver{},
+		schemas: map[string]func() *schema.Schema{
+			"blue": func() *schema.Schema {
+				var (
+					id    = schema.NewIntColumn("id", "int")
+					owner = schema.NewIntColumn("owner_id", "int")
+					users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+					pets  = schema.NewTable("pets").
+						AddColumns(id, owner, schema.NewIntColumn("total", "int").AddAttrs(&schema.GeneratedExpr{Expr: "id + 1"})).
+						AddForeignKeys(schema.NewForeignKey("owner").AddColumns(owner).SetRefTable(users))
+				)
+				// Referencing tables are inspected first.
+				return schema.New("blue").AddTables(pets, users)
+			},
+			"green": func() *schema.Schema { return schema.New("green") },
+		},
+	}
+	ctx := context.Background()
+	err := migrate.CloneSchema(ctx, drv, "blue", "green")
+	require.EqualError(t, err, `sql/migrate: schema "green" already exists`)
+
+	require.NoError(t, migrate.CloneSchema(ctx, drv, "blue", "blue_green"))
+	require.Len(t, drv.applied, 3)
+	require.Equal(t, "blue_green", drv.applied[0].(*schema.AddSchema).S.Name)
+
+	require.NoError(t, migrate.CloneSchema(ctx, drv, "blue", "blue_green", migrate.CloneWithData()))
+	require.Len(t, drv.applied, 5)
+	users, pets := drv.applied[3].(*migrate.CopyTable), drv.applied[4].(*migrate.CopyTable)
+	require.Equal(t, "users", users.From.Name)
+	require.Equal(t, "blue", users.From.Schema.Name)
+	require.Equal(t, "blue_green", users.To.Schema.Name)
+	require.Equal(t, "pets", pets.From.Name)
+	require.Equal(t, []string{"id", "owner_id"}, pets.Columns, "generated columns are not copied")
+}
+
+type seqCloneDriver struct {
+	*cloneDriver
+	events []string
+}
+
+func (d *seqCloneDriver) ApplyChanges(ctx context.Context, changes []schema.Change, opts ...migrate.PlanOption) error {
+	for _, c := range changes {
+		d.events = append(d.events, fmt.Sprintf("%T", c))
+	}
+	return d.cloneDriver.ApplyChanges(ctx, changes, opts...)
+}
+
+func (d *seqCloneDriver) CloneSequences(_ context.Context, from string, s *schema.Schema) (func(context.Context) error, error) {
+	d.events = append(d.events, fmt.Sprintf("clone sequences of %s to %s", from, s.Name))
+	return func(context.Context) error {
+		d.events = append(d.events, "sync sequences")
+		return nil
+	}, nil
+}
+
+func TestCloneSchema_Sequences(t *testing.T) {
+	drv := &seqCloneDriver{
+		cloneDriver: &cloneDriver{
+			mockDriver: &mockDriver{},
+			schemas: map[string]func() *schema.Schema{
+				"blue": func() *schema.Schema {
+					return schema.New("blue").AddTables(schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")))
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	require.NoError(t, migrate.CloneSchema(ctx, drv, "blue", "green"))
+	// Sequences are created before the tables that may reference them.
+	require.Equal(t, []string{"*schema.AddSchema", "clone sequences of blue to green", "*schema.AddTable"}, drv.events)
+
+	drv.events = nil
+	require.NoError(t, migrate.CloneSchema(ctx, drv, "blue", "green", migrate.CloneWithData()))
+	// Sequences are synced after the data was copied.
+	require.Equal(t, []string{"*schema.AddSchema", "clone sequences of blue to green", "*schema.AddTable", "*migrate.CopyTable", "sync sequences"}, drv.events)
+}
+
+func TestSwapSchemas(t *testing.T) {
+	err := migrate.SwapSchemas(context.Background(), &mockDriver{}, "blue", "green")
+	require.ErrorIs(t, err, migrate.ErrSwapUnsupported)
+}
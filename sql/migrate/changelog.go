@@ -0,0 +1,103 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A Changelog summarizes the effects of the files in a migration directory,
+	// ordered from the latest file to the first one.
+	Changelog struct {
+		Files []*ChangelogFile `json:"Files"`
+	}
+
+	// ChangelogFile describes the effects of a single migration file.
+	ChangelogFile struct {
+		Name        string      `json:"Name"`                  // Name of the file.
+		Version     string      `json:"Version"`               // Version of the file.
+		Description string      `json:"Description,omitempty"` // Description of the file.
+		Effects     []string    `json:"Effects,omitempty"`     // Effects of the file, e.g. `table "users": column "name" dropped`.
+		Class       ChangeClass `json:"Class"`                 // Class of the file changes.
+	}
+
+	// ChangelogParser parses a statement into the schema changes it describes. Parsers
+	// are expected to return no changes for statements that do not modify the schema,
+	// e.g. INSERT or UPDATE statements.
+	ChangelogParser func(*Stmt) (schema.Changes, error)
+
+	// StmtParser is implemented by dialect parsers that statically analyze
+	// statements into the schema changes they describe, without a database
+	// connection. Its ParseStmt method can be used as a ChangelogParser.
+	StmtParser interface {
+		ParseStmt(*Stmt) (schema.Changes, error)
+	}
+
+	// StateStmtParser is an optional interface implemented by StmtParsers that can resolve
+	// statements against the schema they are executed on. For example, the kind of the
+	// constraint dropped by an "ALTER TABLE ... DROP CONSTRAINT" statement in PostgreSQL.
+	StateStmtParser interface {
+		StmtParser
+		ParseStmtOn(*Stmt, *schema.Realm) (schema.Changes, error)
+	}
+)
+
+// NewChangelog creates the Changelog of the given migration directory by statically
+// analyzing the statements of its files with the given parser.
+func NewChangelog(dir Dir, parse ChangelogParser) (*Changelog, error) {
+	files, err := dir.Files()
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: changelog: select migration files: %w", err)
+	}
+	var (
+		c = &Changelog{Files: make([]*ChangelogFile, 0, len(files))}
+		f = &ScriptFormatter{}
+	)
+	for i := len(files) - 1; i >= 0; i-- {
+		stmts, err := files[i].StmtDecls()
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: changelog: scanning statements from %q: %w", files[i].Name(), err)
+		}
+		cf := &ChangelogFile{Name: files[i].Name(), Version: files[i].Version(), Description: files[i].Desc()}
+		for _, s := range stmts {
+			changes, err := parse(s)
+			if err != nil {
+				return nil, fmt.Errorf("sql/migrate: changelog: parsing statement at position %d in %q: %w", s.Pos, files[i].Name(), err)
+			}
+			for _, ch := range changes {
+				cf.Effects = append(cf.Effects, f.reasons(ch)...)
+				cf.Class |= Classify(ch)
+			}
+		}
+		c.Files = append(c.Files, cf)
+	}
+	return c, nil
+}
+
+// WriteMarkdown writes the changelog as a Markdown document to w.
+func (c *Changelog) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Changelog\n")
+	for _, f := range c.Files {
+		fmt.Fprintf(&b, "\n## %s\n\n", f.Name)
+		if len(f.Effects) == 0 {
+			b.WriteString("No schema changes.\n")
+			continue
+		}
+		if f.Class.Has(ClassDestructive) {
+			b.WriteString("**Destructive**\n\n")
+		}
+		for _, e := range f.Effects {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
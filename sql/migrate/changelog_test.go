@@ -0,0 +1,82 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewChangelog(t *testing.T) {
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	// A naive parser for the statements in the directory.
+	parse := func(s *migrate.Stmt) (schema.Changes, error) {
+		f := strings.FieldsFunc(strings.TrimSuffix(s.Text, ";"), func(r rune) bool {
+			return r == ' ' || r == '(' || r == ')'
+		})
+		switch {
+		case f[0] == "CREATE":
+			return schema.Changes{&schema.AddTable{T: schema.NewTable(f[2])}}, nil
+		case f[0] == "ALTER" && f[3] == "ADD":
+			return schema.Changes{
+				&schema.ModifyTable{
+					T:       schema.NewTable(f[2]),
+					Changes: schema.Changes{&schema.AddColumn{C: schema.NewNullIntColumn(f[4], f[5])}},
+				},
+			}, nil
+		}
+		return nil, nil
+	}
+	c, err := migrate.NewChangelog(dir, parse)
+	require.NoError(t, err)
+	require.Len(t, c.Files, 3)
+	require.Equal(t, &migrate.ChangelogFile{
+		Name:    "3_partly.sql",
+		Version: "3",
+		Effects: []string{
+			`table "t_sub": column "c3" added with type int`,
+			`table "t_sub": column "c4" added with type int`,
+		},
+		Description: "partly",
+		Class:       migrate.ClassAdditive,
+	}, c.Files[0])
+
+	var b strings.Builder
+	require.NoError(t, c.WriteMarkdown(&b))
+	require.Equal(t, `# Changelog
+
+## 3_partly.sql
+
+- table "t_sub": column "c3" added with type int
+- table "t_sub": column "c4" added with type int
+
+## 2.10.x-20_description.sql
+
+- table "t_sub": column "c2" added with type int
+
+## 1.a_sub.up.sql
+
+- table "t_sub" added
+- table "t_sub": column "c1" added with type int
+`, b.String())
+
+	buf, err := json.Marshal(c)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `{"Name":"2.10.x-20_description.sql","Version":"2.10.x-20","Description":"description","Effects":["table \"t_sub\": column \"c2\" added with type int"],"Class":"additive"}`)
+
+	_, err = migrate.NewChangelog(dir, func(*migrate.Stmt) (schema.Changes, error) {
+		return nil, errors.New("unexpected statement")
+	})
+	require.EqualError(t, err, `sql/migrate: changelog: parsing statement at position 17 in "3_partly.sql": unexpected statement`)
+}
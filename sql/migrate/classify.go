@@ -0,0 +1,145 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// ChangeClass classifies the effect of a planned change. A change
+// can have multiple classes, and the zero class is unclassified.
+type ChangeClass uint
+
+const (
+	// ClassAdditive describes changes that add new objects
+	// without affecting existing ones, e.g. adding a table.
+	ClassAdditive ChangeClass = 1 << iota
+
+	// ClassDestructive describes changes that drop data,
+	// e.g. dropping a table or a column.
+	ClassDestructive
+
+	// ClassBackwardIncompatible describes changes that might break applications
+	// that use the previous schema, e.g. renaming a column or dropping a view.
+	ClassBackwardIncompatible
+
+	// ClassDataDependent describes changes that might fail depending on the
+	// existing data, e.g. adding a unique index or a NOT NULL constraint.
+	ClassDataDependent
+)
+
+// Has returns if the given class is set.
+func (c ChangeClass) Has(f ChangeClass) bool {
+	return c&f != 0
+}
+
+// String implements fmt.Stringer.
+func (c ChangeClass) String() string {
+	var names []string
+	for _, f := range []struct {
+		c ChangeClass
+		n string
+	}{
+		{ClassAdditive, "additive"},
+		{ClassDestructive, "destructive"},
+		{ClassBackwardIncompatible, "backward_incompatible"},
+		{ClassDataDependent, "data_dependent"},
+	} {
+		if c.Has(f.c) {
+			names = append(names, f.n)
+		}
+	}
+	if len(names) == 0 {
+		return "unclassified"
+	}
+	return strings.Join(names, "|")
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c ChangeClass) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// Class returns the union of the classes of all changes in the plan.
+func (p *Plan) Class() ChangeClass {
+	var c ChangeClass
+	for _, ch := range p.Changes {
+		c |= ch.Class
+	}
+	return c
+}
+
+// Classify returns the classes of the given schema change.
+func Classify(c schema.Change) ChangeClass {
+	switch c := c.(type) {
+	case *schema.AddSchema, *schema.AddTable, *schema.AddView, *schema.AddFunc, *schema.AddProc, *schema.AddObject:
+		return ClassAdditive
+	case *schema.DropSchema, *schema.DropTable, *schema.DropColumn:
+		return ClassDestructive | ClassBackwardIncompatible
+	case *schema.DropView, *schema.DropFunc, *schema.DropProc, *schema.DropObject,
+		*schema.RenameTable, *schema.RenameView, *schema.RenameFunc, *schema.RenameProc, *schema.RenameObject,
+		*schema.RenameColumn:
+		return ClassBackwardIncompatible
+	case *schema.ModifySchema:
+		return classifyAll(c.Changes)
+	case *schema.ModifyTable:
+		return classifyAll(c.Changes)
+	case *schema.ModifyView:
+		return classifyAll(c.Changes)
+	case *schema.AddColumn:
+		// Existing rows cannot be filled with a
+		// NOT NULL column without a default value.
+		if !c.C.Type.Null && c.C.Default == nil {
+			return ClassAdditive | ClassDataDependent
+		}
+		return ClassAdditive
+	case *schema.ModifyColumn:
+		var cl ChangeClass
+		// Converting the existing values to the new type might fail.
+		if c.Change.Is(schema.ChangeType) {
+			cl |= ClassDataDependent
+		}
+		if c.Change.Is(schema.ChangeNull) && !c.To.Type.Null {
+			cl |= ClassDataDependent
+		}
+		return cl
+	case *schema.AddIndex:
+		if c.I.Unique {
+			return ClassAdditive | ClassDataDependent
+		}
+		return ClassAdditive
+	case *schema.ModifyIndex:
+		if c.Change.Is(schema.ChangeUnique) && c.To.Unique {
+			return ClassDataDependent
+		}
+	case *schema.AddPrimaryKey, *schema.ModifyPrimaryKey, *schema.AddForeignKey, *schema.AddCheck:
+		return ClassDataDependent
+	case *BackfillColumn, *CopyTable:
+		return ClassDataDependent
+	case *RenameColumnSync:
+		return ClassAdditive | ClassDataDependent
+	}
+	return 0
+}
+
+// classify sets the class of the plan changes that were not classified by the driver.
+func classify(p *Plan) {
+	for _, c := range p.Changes {
+		if c.Class == 0 && c.Source != nil {
+			c.Class = Classify(c.Source)
+		}
+	}
+}
+
+// classifyAll returns the union of the classes of the given changes.
+func classifyAll(changes []schema.Change) ChangeClass {
+	var c ChangeClass
+	for _, ch := range changes {
+		c |= Classify(ch)
+	}
+	return c
+}
@@ -0,0 +1,83 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	var (
+		c1 = schema.NewIntColumn("c1", "int")
+		c2 = schema.NewNullIntColumn("c2", "int")
+		t1 = schema.NewTable("t1").AddColumns(c1, c2)
+	)
+	for _, tt := range []struct {
+		change schema.Change
+		class  migrate.ChangeClass
+	}{
+		{&schema.AddTable{T: t1}, migrate.ClassAdditive},
+		{&schema.DropTable{T: t1}, migrate.ClassDestructive | migrate.ClassBackwardIncompatible},
+		{&schema.RenameTable{From: t1, To: t1}, migrate.ClassBackwardIncompatible},
+		{&schema.DropView{V: schema.NewView("v1", "SELECT 1")}, migrate.ClassBackwardIncompatible},
+		{&schema.ModifyTable{T: t1, Changes: []schema.Change{&schema.AddColumn{C: c2}}}, migrate.ClassAdditive},
+		{&schema.ModifyTable{T: t1, Changes: []schema.Change{&schema.AddColumn{C: c1}}}, migrate.ClassAdditive | migrate.ClassDataDependent},
+		{&schema.ModifyTable{T: t1, Changes: []schema.Change{&schema.AddColumn{C: c2}, &schema.DropColumn{C: c1}}}, migrate.ClassAdditive | migrate.ClassDestructive | migrate.ClassBackwardIncompatible},
+		{&schema.ModifyTable{T: t1, Changes: []schema.Change{&schema.RenameColumn{From: c1, To: c1}}}, migrate.ClassBackwardIncompatible},
+		{&schema.ModifyTable{T: t1, Changes: []schema.Change{&schema.ModifyColumn{From: c2, To: c1, Change: schema.ChangeNull}}}, migrate.ClassDataDependent},
+		{&schema.ModifyTable{T: t1, Changes: []schema.Change{&schema.ModifyColumn{From: c1, To: c2, Change: schema.ChangeNull}}}, 0},
+		{&schema.ModifyTable{T: t1, Changes: []schema.Change{&schema.AddIndex{I: schema.NewUniqueIndex("i").AddColumns(c1)}}}, migrate.ClassAdditive | migrate.ClassDataDependent},
+		{&schema.ModifyTable{T: t1, Changes: []schema.Change{&schema.AddIndex{I: schema.NewIndex("i").AddColumns(c1)}}}, migrate.ClassAdditive},
+		{&schema.ModifyTable{T: t1, Changes: []schema.Change{&schema.DropIndex{I: schema.NewIndex("i").AddColumns(c1)}}}, 0},
+		{&schema.ModifyTable{T: t1, Changes: []schema.Change{&schema.AddCheck{C: schema.NewCheck().SetExpr("c1 > 0")}}}, migrate.ClassDataDependent},
+		{&migrate.BackfillColumn{T: t1, C: c2, Expr: "c1"}, migrate.ClassDataDependent},
+		{&migrate.RenameColumnSync{T: t1, From: c2, To: c2}, migrate.ClassAdditive | migrate.ClassDataDependent},
+	} {
+		require.Equal(t, tt.class, migrate.Classify(tt.change), tt.class.String())
+	}
+}
+
+func TestChangeClass_String(t *testing.T) {
+	require.Equal(t, "unclassified", migrate.ChangeClass(0).String())
+	require.Equal(t, "additive", migrate.ClassAdditive.String())
+	require.Equal(t, "destructive|backward_incompatible", (migrate.ClassDestructive | migrate.ClassBackwardIncompatible).String())
+	buf, err := json.Marshal(&migrate.Change{Cmd: "DROP TABLE t", Class: migrate.ClassDestructive})
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `"Class":"destructive"`)
+}
+
+func TestPlanner_PlanClass(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	var (
+		t1  = schema.NewTable("t1").AddColumns(schema.NewIntColumn("c", "int"))
+		drv = &mockDriver{
+			changes: []schema.Change{&schema.AddTable{T: t1}, &schema.DropTable{T: t1}},
+			plan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE TABLE t1(c int)", Source: &schema.AddTable{T: t1}},
+					{Cmd: "DROP TABLE t1", Source: &schema.DropTable{T: t1}},
+					// Classes set by the driver are kept.
+					{Cmd: "DROP TABLE t2", Source: &schema.DropTable{T: t1}, Class: migrate.ClassDestructive},
+					{Cmd: "SET foreign_key_checks = 0"},
+				},
+			},
+		}
+	)
+	plan, err := migrate.NewPlanner(drv, d).Plan(context.Background(), "", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Equal(t, migrate.ClassAdditive, plan.Changes[0].Class)
+	require.Equal(t, migrate.ClassDestructive|migrate.ClassBackwardIncompatible, plan.Changes[1].Class)
+	require.Equal(t, migrate.ClassDestructive, plan.Changes[2].Class)
+	require.Zero(t, plan.Changes[3].Class)
+	require.Equal(t, migrate.ClassAdditive|migrate.ClassDestructive|migrate.ClassBackwardIncompatible, plan.Class())
+}
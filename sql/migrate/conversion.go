@@ -0,0 +1,39 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+// Conversion classifies the safety of changing the type of column
+// in a specific dialect. See the TypeConversion function of the drivers.
+type Conversion uint
+
+const (
+	// ConversionSafe describes type changes that preserve all existing
+	// values, e.g. widening an integer column from int to bigint.
+	ConversionSafe Conversion = iota
+
+	// ConversionLossy describes type changes that might truncate or round existing
+	// values, or fail on some of them, e.g. narrowing a varchar column or casting
+	// a text column to integer.
+	ConversionLossy
+
+	// ConversionInvalid describes type changes that cannot be applied by the
+	// database without a custom conversion expression, e.g. changing a
+	// timestamp column to integer in PostgreSQL.
+	ConversionInvalid
+)
+
+// String implements fmt.Stringer.
+func (c Conversion) String() string {
+	switch c {
+	case ConversionSafe:
+		return "safe"
+	case ConversionLossy:
+		return "lossy"
+	case ConversionInvalid:
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// DataChange is implemented by changes that modify the data stored in the
+	// database, rather than its schema. Data changes can be passed to the driver
+	// PlanChanges along with schema changes, and are planned, in their order, after
+	// the schema changes of the plan.
+	//
+	//	plan, err := drv.PlanChanges(ctx, "backfill_name", []schema.Change{
+	//		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: name}}},
+	//		&migrate.BackfillColumn{T: users, C: name, Expr: "concat(first_name, ' ', last_name)", BatchSize: 1000},
+	//	})
+	DataChange interface {
+		schema.Change
+		dataChange()
+	}
+
+	// BackfillColumn describes a data change that sets the value of the column
+	// C in table T from the SQL expression Expr, for all rows that match Where
+	// (if set) and hold a different value. If Expr is empty, the value is copied
+	// from the From column. A positive BatchSize splits the update into multiple
+	// batches, each updating at most BatchSize rows, to avoid long running locks
+	// on large tables. Batches run until no row holds a different value, and
+	// therefore, Expr must be deterministic when BatchSize is set. Each batch is
+	// committed separately, and hence, plans with batched changes are not
+	// transactional. Drivers that cannot commit in batches reject BatchSize.
+	BackfillColumn struct {
+		schema.Change
+		T         *schema.Table
+		C         *schema.Column
+		Expr      string
+		From      *schema.Column
+		Where     string
+		BatchSize int
+	}
+
+	// CopyTable describes a data change that copies the rows of table From into
+	// table To. If Columns is empty, all columns of From are copied. A positive
+	// BatchSize copies the rows in batches ordered by the primary key of From,
+	// and skips rows that were already copied to To. As with BackfillColumn,
+	// each batch is committed separately.
+	CopyTable struct {
+		schema.Change
+		From, To  *schema.Table
+		Columns   []string
+		BatchSize int
+	}
+
+	// RenameColumnSync describes a change that renames a column without downtime.
+	// The To column is added to table T, its values are backfilled from the From
+	// column, and triggers are created to keep the two columns in sync. This allows
+	// old and new versions of the application to run side by side, until the From
+	// column and the triggers are dropped. SkipBackfill skips the backfill of the
+	// To column, in case it is planned separately (e.g., in another migration file).
+	RenameColumnSync struct {
+		schema.Change
+		T            *schema.Table
+		From, To     *schema.Column
+		BatchSize    int
+		SkipBackfill bool
+	}
+)
+
+func (*BackfillColumn) dataChange()   {}
+func (*CopyTable) dataChange()        {}
+func (*RenameColumnSync) dataChange() {}
+
+// SplitDataChanges splits the given changes into schema and data changes.
+func SplitDataChanges(changes []schema.Change) (schemaChanges, dataChanges []schema.Change) {
+	for _, c := range changes {
+		if _, ok := c.(DataChange); ok {
+			dataChanges = append(dataChanges, c)
+		} else {
+			schemaChanges = append(schemaChanges, c)
+		}
+	}
+	return schemaChanges, dataChanges
+}
+
+// SyncTriggerName returns the name of the trigger (or trigger
+// function) that keeps the columns of a RenameColumnSync in sync.
+func (r *RenameColumnSync) SyncTriggerName() string {
+	return "atlas_sync_" + r.T.Name + "_" + r.From.Name + "_" + r.To.Name
+}
+
+// CopyColumns returns the names of the columns copied by the CopyTable change.
+func (c *CopyTable) CopyColumns() []string {
+	if len(c.Columns) > 0 {
+		return c.Columns
+	}
+	names := make([]string, 0, len(c.From.Columns))
+	for _, col := range c.From.Columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
@@ -0,0 +1,222 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+const (
+	// DefaultStmtCost is the base duration of a statement used by the
+	// Estimator, in case it was not configured or calibrated.
+	DefaultStmtCost = 10 * time.Millisecond
+
+	// DefaultRowRate is the number of rows per second the Estimator assumes
+	// the database processes in statements that scan or rewrite tables.
+	DefaultRowRate = 100_000
+)
+
+type (
+	// Estimator estimates the execution time of migration plans and files. Each statement is
+	// estimated by its base cost, plus the time it takes to scan or rewrite the table it affects,
+	// computed from the table statistics (e.g., creating an index or changing a column type).
+	Estimator struct {
+		// Stats holds the current state of the database, inspected with
+		// statistics. See the TableStatsOf function for more info.
+		Stats *schema.Realm
+
+		// History holds the revisions that were applied on the database. If
+		// StmtCost is not set, the average duration of the successfully applied
+		// statements is used as the base cost.
+		History []*Revision
+
+		// StmtCost is the base duration of a statement.
+		// Defaults to DefaultStmtCost, if History is empty.
+		StmtCost time.Duration
+
+		// RowRate is the number of rows per second the database processes
+		// in statements that scan or rewrite tables. Defaults to DefaultRowRate.
+		RowRate int64
+	}
+
+	// Estimate describes the estimated execution time of a plan or a file.
+	Estimate struct {
+		Duration time.Duration
+		Stmts    []*StmtEstimate
+		// Calibrated reports if the base cost of the
+		// statements was computed from the history.
+		Calibrated bool
+	}
+
+	// StmtEstimate describes the estimated execution time of a statement.
+	StmtEstimate struct {
+		Stmt     string
+		Duration time.Duration
+		// Rows is the number of rows the statement scans or rewrites, zero
+		// if it does not scan tables, or -1 if the table has no statistics.
+		Rows int64
+	}
+)
+
+// Plan estimates the execution time of the given plan.
+func (e *Estimator) Plan(p *Plan) *Estimate {
+	est := e.estimate()
+	for _, c := range p.Changes {
+		var rows int64
+		if t := scannedTable(c.Source); t != nil {
+			rows = e.rows(t)
+		}
+		e.add(est, c.Cmd, rows)
+	}
+	return est
+}
+
+// File estimates the execution time of the given migration file. Statements are classified
+// lexically, and therefore, tables are scanned only by statements that are known to scan
+// them, like CREATE INDEX, UPDATE, or ALTER TABLE that adds constraints or changes types.
+func (e *Estimator) File(f File) (*Estimate, error) {
+	stmts, err := f.Stmts()
+	if err != nil {
+		return nil, err
+	}
+	est := e.estimate()
+	for _, s := range stmts {
+		var rows int64
+		if t := scannedTableName(s); t != nil {
+			rows = e.rows(t)
+		}
+		e.add(est, s, rows)
+	}
+	return est, nil
+}
+
+// Files estimates the total execution time of the given migration files.
+func (e *Estimator) Files(files ...File) (time.Duration, error) {
+	var d time.Duration
+	for _, f := range files {
+		est, err := e.File(f)
+		if err != nil {
+			return 0, err
+		}
+		d += est.Duration
+	}
+	return d, nil
+}
+
+// estimate returns an empty estimate.
+func (e *Estimator) estimate() *Estimate {
+	_, ok := e.stmtCost()
+	return &Estimate{Calibrated: ok}
+}
+
+// stmtCost returns the base cost of statements, and reports if it was computed from the history.
+func (e *Estimator) stmtCost() (time.Duration, bool) {
+	if e.StmtCost > 0 {
+		return e.StmtCost, false
+	}
+	var (
+		n int
+		d time.Duration
+	)
+	for _, r := range e.History {
+		switch {
+		case !r.Type.Has(RevisionTypeExecute):
+		// Prefer the timings of the statements, if they were recorded.
+		case len(r.Stmts) > 0:
+			for _, s := range r.Stmts {
+				if s.Error == "" {
+					n++
+					d += s.ExecutionTime
+				}
+			}
+		case r.Error == "" && r.Applied > 0 && r.ExecutionTime > 0:
+			n += r.Applied
+			d += r.ExecutionTime
+		}
+	}
+	if n == 0 {
+		return DefaultStmtCost, false
+	}
+	return d / time.Duration(n), true
+}
+
+// add adds the estimate of the given statement.
+func (e *Estimator) add(est *Estimate, stmt string, rows int64) {
+	d, _ := e.stmtCost()
+	if rows > 0 {
+		rate := e.RowRate
+		if rate <= 0 {
+			rate = DefaultRowRate
+		}
+		d += time.Duration(float64(rows) / float64(rate) * float64(time.Second))
+	}
+	est.Stmts = append(est.Stmts, &StmtEstimate{Stmt: stmt, Duration: d, Rows: rows})
+	est.Duration += d
+}
+
+// rows returns the number of rows in the table, or -1 if it has no statistics.
+func (e *Estimator) rows(t *schema.Table) int64 {
+	if s := TableStatsOf(e.Stats, t); s != nil {
+		return s.Rows
+	}
+	return -1
+}
+
+// scannedTable returns the table that is scanned or rewritten by the given change, if any.
+func scannedTable(c schema.Change) *schema.Table {
+	switch c := c.(type) {
+	case *schema.ModifyTable:
+		for _, c1 := range c.Changes {
+			if _, ok := c1.(*schema.AddIndex); ok || RewritesTable(c1) || Classify(c1).Has(ClassDataDependent) {
+				return c.T
+			}
+		}
+	case *BackfillColumn:
+		return c.T
+	case *CopyTable:
+		return c.From
+	case *RenameColumnSync:
+		if !c.SkipBackfill {
+			return c.T
+		}
+	}
+	return nil
+}
+
+// reScanStmt matches statements that scan or rewrite the table they operate on.
+var reScanStmt = regexp.MustCompile(`(?is)^\s*(?:` +
+	`CREATE\s+(?:UNIQUE\s+)?INDEX\s.*?\bON\s+(?:ONLY\s+)?([^\s(]+)` +
+	`|ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?([^\s]+)\s.*\b(?:TYPE|MODIFY|CHANGE|SET\s+NOT\s+NULL|ADD\s+(?:PRIMARY\s+KEY|UNIQUE|INDEX|KEY|CONSTRAINT|FOREIGN\s+KEY|CHECK))\b` +
+	`|UPDATE\s+([^\s]+)` +
+	`|DELETE\s+FROM\s+([^\s]+)` +
+	`)`)
+
+// scannedTableName returns the (possibly qualified) table that is scanned by the statement, if any.
+func scannedTableName(stmt string) *schema.Table {
+	m := reScanStmt.FindStringSubmatch(stmt)
+	if m == nil {
+		return nil
+	}
+	var name string
+	for _, s := range m[1:] {
+		if s != "" {
+			name = s
+			break
+		}
+	}
+	parts := strings.Split(name, ".")
+	for i := range parts {
+		parts[i] = strings.Trim(parts[i], "`\"[]")
+	}
+	t := schema.NewTable(parts[len(parts)-1])
+	if len(parts) > 1 {
+		t.SetSchema(schema.New(parts[len(parts)-2]))
+	}
+	return t
+}
@@ -0,0 +1,118 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimator_Plan(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("c", "int")).
+			AddAttrs(&schema.TableStats{Rows: 200_000})
+		current = schema.NewRealm(users.Schema.AddTables(users))
+		desired = schema.NewTable("users").SetSchema(schema.New("public"))
+		plan    = &migrate.Plan{
+			Changes: []*migrate.Change{
+				{
+					Cmd:    "ALTER TABLE users ADD COLUMN d int",
+					Source: &schema.ModifyTable{T: desired, Changes: schema.Changes{&schema.AddColumn{C: schema.NewNullIntColumn("d", "int")}}},
+				},
+				{
+					Cmd: "ALTER TABLE users ALTER COLUMN c TYPE bigint",
+					Source: &schema.ModifyTable{T: desired, Changes: schema.Changes{
+						&schema.ModifyColumn{From: users.Columns[1], To: schema.NewIntColumn("c", "bigint"), Change: schema.ChangeType},
+					}},
+				},
+				{
+					Cmd:    "CREATE INDEX i ON unknown (c)",
+					Source: &schema.ModifyTable{T: schema.NewTable("unknown"), Changes: schema.Changes{&schema.AddIndex{I: schema.NewIndex("i")}}},
+				},
+			},
+		}
+	)
+	est := (&migrate.Estimator{Stats: current}).Plan(plan)
+	require.False(t, est.Calibrated)
+	require.Len(t, est.Stmts, 3)
+	require.Equal(t, migrate.DefaultStmtCost, est.Stmts[0].Duration)
+	require.Zero(t, est.Stmts[0].Rows)
+	require.Equal(t, migrate.DefaultStmtCost+2*time.Second, est.Stmts[1].Duration)
+	require.EqualValues(t, 200_000, est.Stmts[1].Rows)
+	require.Equal(t, migrate.DefaultStmtCost, est.Stmts[2].Duration)
+	require.EqualValues(t, -1, est.Stmts[2].Rows)
+	require.Equal(t, 3*migrate.DefaultStmtCost+2*time.Second, est.Duration)
+
+	// Calibrated from history.
+	est = (&migrate.Estimator{
+		Stats:   current,
+		RowRate: 400_000,
+		History: []*migrate.Revision{
+			{Type: migrate.RevisionTypeExecute, Applied: 2, ExecutionTime: 30 * time.Millisecond},
+			{Type: migrate.RevisionTypeExecute, Applied: 2, ExecutionTime: 50 * time.Millisecond},
+			{Type: migrate.RevisionTypeExecute, Applied: 1, ExecutionTime: time.Hour, Error: "failed"},
+			{Type: migrate.RevisionTypeBaseline},
+		},
+	}).Plan(plan)
+	require.True(t, est.Calibrated)
+	require.Equal(t, 20*time.Millisecond, est.Stmts[0].Duration)
+	require.Equal(t, 20*time.Millisecond+500*time.Millisecond, est.Stmts[1].Duration)
+
+	// Recorded statements take precedence over the revision timing.
+	est = (&migrate.Estimator{
+		History: []*migrate.Revision{
+			{
+				Type: migrate.RevisionTypeExecute, Applied: 2, ExecutionTime: time.Second,
+				Stmts: []*migrate.RevisionStmt{
+					{ExecutionTime: 4 * time.Millisecond},
+					{ExecutionTime: 6 * time.Millisecond},
+					{ExecutionTime: time.Hour, Error: "failed"},
+				},
+			},
+		},
+	}).Plan(plan)
+	require.True(t, est.Calibrated)
+	require.Equal(t, 5*time.Millisecond, est.Stmts[0].Duration)
+}
+
+func TestEstimator_File(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			AddColumns(schema.NewIntColumn("id", "int")).
+			AddAttrs(&schema.TableStats{Rows: 100_000})
+		pets = schema.NewTable("pets").
+			AddColumns(schema.NewIntColumn("id", "int")).
+			AddAttrs(&schema.TableStats{Rows: 50_000})
+		e = &migrate.Estimator{
+			Stats:    schema.NewRealm(schema.New("public").AddTables(users, pets)),
+			StmtCost: time.Millisecond,
+		}
+		f = migrate.NewLocalFile("1.sql", []byte(`CREATE TABLE t (id int);
+CREATE UNIQUE INDEX "i" ON "public"."users" ("id");
+ALTER TABLE pets ADD COLUMN c int;
+ALTER TABLE ONLY pets ALTER COLUMN id TYPE bigint;
+UPDATE public.pets SET id = id + 1;
+DELETE FROM unknown;
+`))
+	)
+	est, err := e.File(f)
+	require.NoError(t, err)
+	require.Len(t, est.Stmts, 6)
+	for i, rows := range []int64{0, 100_000, 0, 50_000, 50_000, -1} {
+		require.Equal(t, rows, est.Stmts[i].Rows, est.Stmts[i].Stmt)
+	}
+	require.Equal(t, 6*time.Millisecond+2*time.Second, est.Duration)
+
+	d, err := e.Files(f, migrate.NewLocalFile("2.sql", []byte("CREATE TABLE t2 (id int);")))
+	require.NoError(t, err)
+	require.Equal(t, 7*time.Millisecond+2*time.Second, d)
+}
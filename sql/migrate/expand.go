@@ -0,0 +1,165 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// atlas:phase directive.
+const directivePhase = "phase"
+
+// Phases of an ExpandContract change, in their execution order.
+const (
+	PhaseExpand   = "expand"   // Add the new column and the dual-write triggers.
+	PhaseBackfill = "backfill" // Copy the existing values to the new column.
+	PhaseSwap     = "swap"     // Enforce the constraints of the new column.
+	PhaseContract = "contract" // Drop the dual-write triggers and the old column.
+)
+
+type (
+	// ExpandContract describes a column change, a rename or a type change, that is applied
+	// without downtime using the expand/contract (parallel change) pattern. The change is
+	// split into multiple phases, each planned to a separate migration file, allowing the
+	// application to be deployed between them:
+	//
+	//	1. expand: the To column is added as nullable, and triggers are created to keep
+	//	   the From and To columns in sync while old and new versions of the application
+	//	   write to the table.
+	//	2. backfill: the values of the From column are copied to the To column.
+	//	3. swap: the To column is set as NOT NULL, if required. Applications should switch
+	//	   to reading from the To column before the next phase. This phase is omitted in
+	//	   case the To column is nullable.
+	//	4. contract: the triggers and the From column are dropped.
+	//
+	// In case the From and To columns share the same name (i.e., a type change), the new
+	// column is created under a temporary name with the "_new" suffix, and renamed back
+	// in the contract phase, after the old column is dropped.
+	ExpandContract struct {
+		T         *schema.Table
+		From, To  *schema.Column
+		BatchSize int
+	}
+
+	// Phase is a planned phase of an ExpandContract change.
+	Phase struct {
+		Name string // The phase name, e.g., PhaseExpand.
+		Plan *Plan  // The plan of the phase.
+	}
+)
+
+// PlanExpandContract plans the phases of the given ExpandContract change using the given PlanApplier.
+// The returned plans are named after the given name and their phase, and are versioned in their execution
+// order. Use Planner.WritePhases to write them to a migration directory.
+func PlanExpandContract(ctx context.Context, pa PlanApplier, name string, c *ExpandContract, opts ...PlanOption) ([]*Phase, error) {
+	switch {
+	case c.T == nil:
+		return nil, errors.New("sql/migrate: missing table for expand/contract change")
+	case c.From == nil || c.To == nil:
+		return nil, errors.New("sql/migrate: missing columns for expand/contract change")
+	case c.To.Type == nil:
+		return nil, fmt.Errorf("sql/migrate: missing type for column %q", c.To.Name)
+	}
+	added := nullable(c.To)
+	if c.To.Name == c.From.Name {
+		added.Name += "_new"
+	}
+	var (
+		phases []*Phase
+		sync   = &RenameColumnSync{T: c.T, From: c.From, To: added, SkipBackfill: true}
+	)
+	add := func(phase string, changes ...schema.Change) (*Plan, error) {
+		plan, err := pa.PlanChanges(ctx, name+"_"+phase, changes, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: plan %s phase: %w", phase, err)
+		}
+		phases = append(phases, &Phase{Name: phase, Plan: plan})
+		return plan, nil
+	}
+	expand, err := add(PhaseExpand, sync)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := add(PhaseBackfill, &BackfillColumn{T: c.T, C: added, From: c.From, BatchSize: c.BatchSize}); err != nil {
+		return nil, err
+	}
+	if !c.To.Type.Null {
+		notnull := *added
+		notnull.Type = c.To.Type
+		if _, err := add(PhaseSwap, &schema.ModifyTable{T: c.T, Changes: []schema.Change{&schema.ModifyColumn{From: added, To: &notnull, Change: schema.ChangeNull}}}); err != nil {
+			return nil, err
+		}
+	}
+	contract := []schema.Change{&schema.ModifyTable{T: c.T, Changes: []schema.Change{&schema.DropColumn{C: c.From}}}}
+	if added.Name != c.To.Name {
+		contract = append(contract, &schema.ModifyTable{T: c.T, Changes: []schema.Change{&schema.RenameColumn{From: added, To: c.To}}})
+	}
+	plan, err := add(PhaseContract, contract...)
+	if err != nil {
+		return nil, err
+	}
+	// The sync triggers (and functions) are dropped
+	// using the reverse statements of their creation.
+	var drops []*Change
+	for i := len(expand.Changes) - 1; i >= 0; i-- {
+		if ch := expand.Changes[i]; ch.Source == sync && ch.Reverse != nil {
+			stmts, err := ch.ReverseStmts()
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range stmts {
+				drops = append(drops, &Change{
+					Cmd:     s,
+					Source:  sync,
+					Comment: "drop " + strings.TrimPrefix(ch.Comment, "create "),
+					Class:   ClassBackwardIncompatible,
+				})
+			}
+		}
+	}
+	plan.Changes = append(drops, plan.Changes...)
+	now := time.Now().UTC()
+	for i, p := range phases {
+		p.Plan.Version = now.Add(time.Duration(i) * time.Second).Format("20060102150405")
+		classify(p.Plan)
+	}
+	return phases, nil
+}
+
+// WritePhases writes the plans of the given phases to the Dir, and marks each of their files
+// with the "atlas:phase" directive that records the phase name and its position. For example:
+//
+//	-- atlas:phase expand 1/4
+func (p *Planner) WritePhases(phases []*Phase) error {
+	for i, ph := range phases {
+		files, err := p.fmt.Format(ph.Plan)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if d, ok := f.(interface{ AddDirective(string, ...string) }); ok {
+				d.AddDirective(directivePhase, ph.Name, fmt.Sprintf("%d/%d", i+1, len(phases)))
+			}
+			if err := p.dir.WriteFile(f.Name(), f.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+	return p.writeSum()
+}
+
+// nullable returns a nullable copy of the given column.
+func nullable(c *schema.Column) *schema.Column {
+	nc, t := *c, *c.Type
+	t.Null = true
+	nc.Type = &t
+	return &nc
+}
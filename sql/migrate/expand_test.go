@@ -0,0 +1,49 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanExpandContract_Invalid(t *testing.T) {
+	ctx := context.Background()
+	_, err := migrate.PlanExpandContract(ctx, nil, "name", &migrate.ExpandContract{})
+	require.EqualError(t, err, "sql/migrate: missing table for expand/contract change")
+	_, err = migrate.PlanExpandContract(ctx, nil, "name", &migrate.ExpandContract{T: schema.NewTable("t")})
+	require.EqualError(t, err, "sql/migrate: missing columns for expand/contract change")
+	_, err = migrate.PlanExpandContract(ctx, nil, "name", &migrate.ExpandContract{T: schema.NewTable("t"), From: schema.NewColumn("a"), To: schema.NewColumn("b")})
+	require.EqualError(t, err, `sql/migrate: missing type for column "b"`)
+}
+
+func TestPlanner_WritePhases(t *testing.T) {
+	var (
+		d      = &migrate.MemDir{}
+		pl     = migrate.NewPlanner(nil, d)
+		phases = []*migrate.Phase{
+			{Name: migrate.PhaseExpand, Plan: &migrate.Plan{Version: "1", Name: "rename_expand", Changes: []*migrate.Change{{Cmd: "ALTER TABLE t ADD COLUMN b int", Comment: "add column"}}}},
+			{Name: migrate.PhaseContract, Plan: &migrate.Plan{Version: "2", Name: "rename_contract", Changes: []*migrate.Change{{Cmd: "ALTER TABLE t DROP COLUMN a"}}}},
+		}
+	)
+	require.NoError(t, pl.WritePhases(phases))
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "1_rename_expand.sql", files[0].Name())
+	require.Equal(t, "-- atlas:phase expand 1/2\n\n-- Add column\nALTER TABLE t ADD COLUMN b int;\n", string(files[0].Bytes()))
+	require.Equal(t, []string{"expand 1/2"}, files[0].(*migrate.LocalFile).Directive("phase"))
+	require.Equal(t, "2_rename_contract.sql", files[1].Name())
+	require.Equal(t, []string{"contract 2/2"}, files[1].(*migrate.LocalFile).Directive("phase"))
+	sum, err := d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.Validate(d))
+	require.Len(t, sum, 2)
+}
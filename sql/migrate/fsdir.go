@@ -0,0 +1,123 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// FSDir implements Dir on top of a read-only fs.FS, such as an embed.FS, and an optional writable
+// Dir that files are written to. Reads are served from the writable Dir first, and fall back to the
+// read-only copy. This allows library users to ship their migration directory in their binary, and
+// author new migration files (and the atlas.sum file) locally, using the same Dir.
+//
+//	//go:embed migrations
+//	var migrations embed.FS
+//
+//	sub, _ := fs.Sub(migrations, "migrations")
+//	local, _ := migrate.NewLocalDir("migrations")
+//	dir := migrate.NewFSDir(sub, local)
+type FSDir struct {
+	fsys fs.FS
+	w    Dir
+}
+
+var _ CheckpointDir = (*FSDir)(nil)
+
+// NewFSDir returns a new FSDir for the given file system. The writable Dir is used for
+// writing files, and might be nil, in which case the returned Dir is read-only.
+func NewFSDir(fsys fs.FS, w Dir) *FSDir {
+	return &FSDir{fsys: fsys, w: w}
+}
+
+// Open implements fs.FS.
+func (d *FSDir) Open(name string) (fs.File, error) {
+	if d.w != nil {
+		f, err := d.w.Open(name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return f, err
+		}
+	}
+	return d.fsys.Open(name)
+}
+
+// WriteFile implements Dir.WriteFile.
+func (d *FSDir) WriteFile(name string, b []byte) error {
+	if d.w == nil {
+		return fmt.Errorf("sql/migrate: write file %q: %w", name, fs.ErrPermission)
+	}
+	return d.w.WriteFile(name, b)
+}
+
+// Files implements Dir.Files. It merges the files of the writable Dir with the files of
+// the read-only file system, and orders them by filename. In case a file exists in both,
+// the version of the writable Dir is returned.
+func (d *FSDir) Files() ([]File, error) {
+	names, err := fs.Glob(d.fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]File, len(names))
+	for _, n := range names {
+		b, err := fs.ReadFile(d.fsys, n)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: read file %q: %w", n, err)
+		}
+		files[n] = NewLocalFile(n, b)
+	}
+	if d.w != nil {
+		wfs, err := d.w.Files()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range wfs {
+			files[f.Name()] = f
+		}
+	}
+	merged := make([]File, 0, len(files))
+	for _, f := range files {
+		merged = append(merged, f)
+	}
+	// Sort files lexicographically.
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Name() < merged[j].Name()
+	})
+	return merged, nil
+}
+
+// Checksum implements Dir.Checksum. By default, it calls Files() and creates a checksum from them.
+func (d *FSDir) Checksum() (HashFile, error) {
+	files, err := d.Files()
+	if err != nil {
+		return nil, err
+	}
+	return NewHashFile(files)
+}
+
+// WriteCheckpoint is like WriteFile, but marks the file as a checkpoint file.
+func (d *FSDir) WriteCheckpoint(name, tag string, b []byte) error {
+	var (
+		args []string
+		f    = NewLocalFile(name, b)
+	)
+	if tag != "" {
+		args = append(args, tag)
+	}
+	f.AddDirective(directiveCheckpoint, args...)
+	return d.WriteFile(name, f.Bytes())
+}
+
+// CheckpointFiles implements CheckpointDir.CheckpointFiles.
+func (d *FSDir) CheckpointFiles() ([]File, error) {
+	return checkpointFiles(d)
+}
+
+// FilesFromCheckpoint implements CheckpointDir.FilesFromCheckpoint.
+func (d *FSDir) FilesFromCheckpoint(name string) ([]File, error) {
+	return filesFromCheckpoint(d, name)
+}
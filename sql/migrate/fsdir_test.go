@@ -0,0 +1,71 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSDir(t *testing.T) {
+	embedded := &migrate.MemDir{}
+	require.NoError(t, embedded.WriteFile("1_init.sql", []byte("CREATE TABLE t(c int);")))
+	require.NoError(t, embedded.WriteFile("2_add.sql", []byte("ALTER TABLE t ADD c2 int;")))
+	sum, err := embedded.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(embedded, sum))
+	fsys := fstest.MapFS{}
+	for _, n := range []string{"1_init.sql", "2_add.sql", migrate.HashFileName} {
+		b, err := fs.ReadFile(embedded, n)
+		require.NoError(t, err)
+		fsys[n] = &fstest.MapFile{Data: b}
+	}
+
+	// Read-only.
+	d := migrate.NewFSDir(fsys, nil)
+	require.NoError(t, migrate.Validate(d))
+	files, err := d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.ErrorIs(t, d.WriteFile("3_drop.sql", nil), fs.ErrPermission)
+
+	// Writes go to the paired directory, and reads fall back to the embedded copy.
+	local := &migrate.MemDir{}
+	d = migrate.NewFSDir(fsys, local)
+	require.NoError(t, d.WriteFile("3_drop.sql", []byte("DROP TABLE t;")))
+	require.ErrorIs(t, migrate.Validate(d), migrate.ErrChecksumMismatch)
+	sum, err = d.Checksum()
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	require.NoError(t, migrate.Validate(d))
+	files, err = d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.Equal(t, []string{"1_init.sql", "2_add.sql", "3_drop.sql"}, []string{files[0].Name(), files[1].Name(), files[2].Name()})
+	require.Equal(t, "DROP TABLE t;", string(files[2].Bytes()))
+	// The embedded copy is not modified.
+	require.Len(t, fsys, 3)
+	b, err := fs.ReadFile(d, "1_init.sql")
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE t(c int);", string(b))
+
+	// Files in the writable directory take precedence.
+	require.NoError(t, d.WriteCheckpoint("2_add.sql", "v2", []byte("CREATE TABLE t(c int, c2 int);")))
+	files, err = d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	cks, err := d.CheckpointFiles()
+	require.NoError(t, err)
+	require.Len(t, cks, 1)
+	require.Equal(t, "2_add.sql", cks[0].Name())
+	files, err = d.FilesFromCheckpoint("2_add.sql")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
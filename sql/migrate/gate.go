@@ -0,0 +1,82 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// A Gate is consulted before changes are applied, and allows integrating with external
+	// systems, such as feature flag services or deployment freeze calendars. A non-nil error
+	// denies the apply. Gates are expected to wrap ErrNotApproved in case they deny an apply,
+	// to distinguish denials from failures to consult the external system.
+	//
+	// A Gate can also be used as the approval callback of a ProtectionRule:
+	//
+	//	&migrate.ProtectionRule{URLs: []string{"postgres://*.prod.internal:*/*"}, Approve: g.Allow}
+	Gate interface {
+		Allow(context.Context, *ProtectedApply) error
+	}
+
+	// GateFunc allows using an ordinary function as a Gate.
+	GateFunc func(context.Context, *ProtectedApply) error
+
+	// Gates is a list of gates that must all allow an apply.
+	Gates []Gate
+
+	// gate consults a Gate before an Executor executes a file.
+	gate struct {
+		Gate
+		target string
+	}
+)
+
+// Allow implements the Gate interface.
+func (f GateFunc) Allow(ctx context.Context, a *ProtectedApply) error {
+	return f(ctx, a)
+}
+
+// Allow implements the Gate interface. Gates are consulted in
+// order, and the first gate that denies the apply stops the check.
+func (gs Gates) Allow(ctx context.Context, a *ProtectedApply) error {
+	for _, g := range gs {
+		if err := g.Allow(ctx, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithGate configures the Executor to consult the given gate before executing a migration file.
+// The target is passed to the gate as the URL of the apply. Note that the target should not
+// contain any credentials, e.g. use the redacted database URL.
+func WithGate(g Gate, target string) ExecutorOption {
+	return func(ex *Executor) error {
+		if g == nil {
+			return errors.New("sql/migrate: execute: nil gate")
+		}
+		ex.gate = &gate{Gate: g, target: target}
+		return nil
+	}
+}
+
+// allow consults the gate for the given file, if set.
+func (g *gate) allow(ctx context.Context, f File, stmts []string) error {
+	if g == nil {
+		return nil
+	}
+	a := &ProtectedApply{URL: g.target, Version: f.Version(), Time: time.Now(), Stmts: stmts}
+	for _, s := range stmts {
+		a.Class |= ClassifyStmt(s)
+	}
+	if err := g.Allow(ctx, a); err != nil {
+		return fmt.Errorf("sql/migrate: execute: gate denied version %q: %w", a.Version, err)
+	}
+	return nil
+}
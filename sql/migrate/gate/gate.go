@@ -0,0 +1,170 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package gate provides pre-apply gates that consult external systems, such as
+// feature flag services or deployment freeze calendars, before changes are applied.
+package gate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+)
+
+type (
+	// HTTP is a migrate.Gate that posts the apply as JSON to an HTTP endpoint, and
+	// allows or denies it based on the JSON response of the endpoint. For example:
+	//
+	//	POST /gate
+	//	{"url": "postgres://db.prod.internal:5432/app", "version": "20230401000000", "class": "destructive", ...}
+	//
+	//	200 OK
+	//	{"allow": false, "reason": "deployment freeze until 2023-04-03"}
+	HTTP struct {
+		url      string
+		client   *http.Client
+		header   http.Header
+		failOpen bool
+	}
+
+	// Option configures an HTTP gate.
+	Option func(*HTTP) error
+
+	// Request is the JSON body that is posted to the endpoint.
+	Request struct {
+		// Redacted URL of the target database.
+		URL string `json:"url"`
+		// Version of the migration file, if applied by a migrate.Executor.
+		Version string `json:"version,omitempty"`
+		// Class of the changes, e.g. "additive|destructive".
+		Class string `json:"class"`
+		// Time of the apply.
+		Time time.Time `json:"time"`
+		// Statements to be executed, if known.
+		Stmts []string `json:"stmts,omitempty"`
+	}
+
+	// Response is the JSON body that is expected from the endpoint.
+	Response struct {
+		Allow  bool   `json:"allow"`
+		Reason string `json:"reason,omitempty"`
+	}
+)
+
+// DefaultClient is the HTTP client used by gates, unless configured otherwise.
+// Its timeout ensures unresponsive endpoints do not hold the apply indefinitely.
+var DefaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// maxResponseSize bounds the size of the endpoint responses.
+const maxResponseSize = 1 << 20
+
+// NewHTTP returns an HTTP gate for the given endpoint URL.
+func NewHTTP(url string, opts ...Option) (*HTTP, error) {
+	if url == "" {
+		return nil, errors.New("gate: missing endpoint url")
+	}
+	h := &HTTP{
+		url:    url,
+		client: DefaultClient,
+		header: http.Header{"Content-Type": []string{"application/json"}},
+	}
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// WithHeader sets a header on the gate requests. For example, an authorization header.
+func WithHeader(k, v string) Option {
+	return func(h *HTTP) error {
+		h.header.Set(k, v)
+		return nil
+	}
+}
+
+// WithHTTPClient configures the HTTP client used for sending the requests.
+// The default is DefaultClient.
+func WithHTTPClient(c *http.Client) Option {
+	return func(h *HTTP) error {
+		if c == nil {
+			return errors.New("gate: nil http client")
+		}
+		h.client = c
+		return nil
+	}
+}
+
+// WithFailOpen allows applies in case the endpoint cannot be consulted, e.g. it is
+// unreachable or returns an unexpected response. By default, such applies are denied.
+// Applies whose context was canceled, or reached its deadline, are always denied.
+func WithFailOpen() Option {
+	return func(h *HTTP) error {
+		h.failOpen = true
+		return nil
+	}
+}
+
+// Allow implements the migrate.Gate interface. Denials wrap migrate.ErrNotApproved.
+func (h *HTTP) Allow(ctx context.Context, a *migrate.ProtectedApply) error {
+	resp, err := h.consult(ctx, a)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return ctx.Err()
+	case err != nil && h.failOpen:
+		return nil
+	case err != nil:
+		return err
+	case !resp.Allow && resp.Reason != "":
+		return fmt.Errorf("%w: %s", migrate.ErrNotApproved, resp.Reason)
+	case !resp.Allow:
+		return migrate.ErrNotApproved
+	}
+	return nil
+}
+
+// consult posts the apply to the endpoint and decodes its response.
+func (h *HTTP) consult(ctx context.Context, a *migrate.ProtectedApply) (*Response, error) {
+	r := &Request{URL: a.URL, Version: a.Version, Class: a.Class.String(), Time: a.Time, Stmts: a.Stmts}
+	if u, err := url.Parse(a.URL); err == nil {
+		r.URL = u.Redacted()
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("gate: encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("gate: create request: %w", err)
+	}
+	for k, v := range h.header {
+		req.Header[k] = v
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gate: send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("gate: unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	var gr Response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&gr); err != nil {
+		return nil, fmt.Errorf("gate: decode response: %w", err)
+	}
+	return &gr, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// HistoryQuery filters the revisions returned by QueryHistory.
+// The zero value matches all revisions.
+type HistoryQuery struct {
+	// Since and Until limit the revisions to the ones
+	// that were executed in the given time range.
+	Since, Until time.Time
+
+	// Failed limits the revisions to the ones that failed.
+	Failed bool
+
+	// MinDuration limits the revisions to the ones that took at least the
+	// given duration to execute, or had a statement that took that long.
+	MinDuration time.Duration
+
+	// Limit limits the number of returned revisions to the most recent ones.
+	Limit int
+}
+
+// QueryHistory returns the revisions that were applied on the database and match the given
+// query, ordered by their execution time. Revisions that were not executed by Atlas (e.g.,
+// baselines) are skipped. For example, to list the statements of migrations that took
+// more than a minute in the last week (their text is stored only if applied WithStmtText):
+//
+//	revs, err := migrate.QueryHistory(ctx, rrw, migrate.HistoryQuery{
+//		Since:       time.Now().AddDate(0, 0, -7),
+//		MinDuration: time.Minute,
+//	})
+//	for _, r := range revs {
+//		for _, s := range r.Stmts {
+//			fmt.Println(r.Version, s.ExecutionTime, s.Stmt)
+//		}
+//	}
+func QueryHistory(ctx context.Context, rrw RevisionReadWriter, q HistoryQuery) ([]*Revision, error) {
+	revs, err := rrw.ReadRevisions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]*Revision, 0, len(revs))
+	for _, r := range revs {
+		if q.match(r) {
+			matched = append(matched, r)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].ExecutedAt.Before(matched[j].ExecutedAt)
+	})
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[len(matched)-q.Limit:]
+	}
+	return matched, nil
+}
+
+// match reports if the revision matches the query.
+func (q *HistoryQuery) match(r *Revision) bool {
+	switch {
+	case !r.Type.Has(RevisionTypeExecute):
+		return false
+	case !q.Since.IsZero() && r.ExecutedAt.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && r.ExecutedAt.After(q.Until):
+		return false
+	case q.Failed && r.Error == "":
+		return false
+	case q.MinDuration > 0 && r.ExecutionTime < q.MinDuration:
+		for _, s := range r.Stmts {
+			if s.ExecutionTime >= q.MinDuration {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryHistory(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		now  = time.Now()
+		base = &migrate.Revision{Version: "0", Type: migrate.RevisionTypeBaseline, ExecutedAt: now.Add(-time.Hour)}
+		rev1 = &migrate.Revision{Version: "1", Type: migrate.RevisionTypeExecute, ExecutedAt: now.Add(-3 * time.Minute), ExecutionTime: time.Second}
+		rev2 = &migrate.Revision{
+			Version:       "2",
+			Type:          migrate.RevisionTypeExecute,
+			ExecutedAt:    now.Add(-2 * time.Minute),
+			ExecutionTime: 2 * time.Second,
+			Stmts: []*migrate.RevisionStmt{
+				{Stmt: "CREATE TABLE t(c int);", ExecutionTime: 500 * time.Millisecond},
+				{Stmt: "CREATE INDEX i ON t(c);", ExecutionTime: 1500 * time.Millisecond},
+			},
+		}
+		rev3 = &migrate.Revision{Version: "3", Type: migrate.RevisionTypeExecute, ExecutedAt: now.Add(-time.Minute), Error: "failed"}
+		// Revisions are read ordered by their versions.
+		rrw = &mockRevisionReadWriter{base, rev3, rev1, rev2}
+	)
+	revs, err := migrate.QueryHistory(ctx, rrw, migrate.HistoryQuery{})
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Revision{rev1, rev2, rev3}, revs)
+
+	revs, err = migrate.QueryHistory(ctx, rrw, migrate.HistoryQuery{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Revision{rev2, rev3}, revs)
+
+	revs, err = migrate.QueryHistory(ctx, rrw, migrate.HistoryQuery{Since: now.Add(-150 * time.Second), Until: now.Add(-90 * time.Second)})
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Revision{rev2}, revs)
+
+	revs, err = migrate.QueryHistory(ctx, rrw, migrate.HistoryQuery{Failed: true})
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Revision{rev3}, revs)
+
+	revs, err = migrate.QueryHistory(ctx, rrw, migrate.HistoryQuery{MinDuration: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Revision{rev1, rev2}, revs)
+
+	// Revisions with slow statements.
+	rev2.ExecutionTime = 0
+	revs, err = migrate.QueryHistory(ctx, rrw, migrate.HistoryQuery{MinDuration: 1500 * time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Revision{rev2}, revs)
+}
@@ -7,10 +7,12 @@ package migrate
 import (
 	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"ariga.io/atlas/sql/schema"
@@ -52,6 +54,10 @@ type (
 
 		// The Source that caused this change, or nil.
 		Source schema.Change
+
+		// Class of the change. Set by the Planner based on
+		// the Source of the change, if it was not set before.
+		Class ChangeClass
 	}
 )
 
@@ -106,11 +112,21 @@ type (
 		// This is useful to indicate to the driver whether the context is a live database, an empty one, or the
 		// versioned migration workflow.
 		Mode PlanMode
+		// Quote controls when identifiers are quoted in the planned statements.
+		// If not specified, identifiers are always quoted. Drivers reject plans
+		// with identifiers that must be quoted under the QuoteNever policy.
+		Quote QuotePolicy
 	}
 
 	// PlanMode defines the plan mode to use.
 	PlanMode uint8
 
+	// QuotePolicy defines when identifiers are quoted in planned statements.
+	QuotePolicy uint8
+
+	// IdentCase defines the case normalization of identifiers in the desired state.
+	IdentCase uint8
+
 	// PlanOption allows configuring a drivers' plan using functional arguments.
 	PlanOption func(*PlanOptions)
 
@@ -139,6 +155,22 @@ const (
 	PlanModeDump                     // Schema creation dump (e.g., 'schema inspect').
 )
 
+// List of identifier quoting policies.
+const (
+	QuoteAlways QuotePolicy = iota // Identifiers are always quoted (default).
+	QuoteNeeded                    // Only identifiers that cannot be written unquoted, e.g., reserved words.
+	QuoteNever                     // Identifiers are never quoted.
+)
+
+// List of identifier case normalizations. Note, the identifiers that are normalized
+// are the names of the schema objects, and not the ones that appear in raw expressions,
+// such as defaults or check constraints.
+const (
+	CaseUnchanged IdentCase = iota // Identifiers are written as-is (default).
+	CaseLower                      // Identifiers are written in lower case.
+	CaseUpper                      // Identifiers are written in upper case.
+)
+
 // Is reports whether m is match the given mode.
 func (m PlanMode) Is(m1 PlanMode) bool {
 	return m == m1 || m&m1 != 0
@@ -194,6 +226,9 @@ type (
 		sum      bool                // whether to create a sum file for the migration directory
 		planOpts []PlanOption        // plan options
 		diffOpts []schema.DiffOption // diff options
+
+		// identCase normalizes the names of the desired state.
+		identCase IdentCase
 	}
 
 	// PlannerOption allows managing a Planner using functional arguments.
@@ -216,18 +251,30 @@ type (
 
 	// A Revision denotes an applied migration in a deployment. Used to track migration executions state of a database.
 	Revision struct {
-		Version         string        `json:"Version"`             // Version of the migration.
-		Description     string        `json:"Description"`         // Description of this migration.
-		Type            RevisionType  `json:"Type"`                // Type of the migration.
-		Applied         int           `json:"Applied"`             // Applied amount of statements in the migration.
-		Total           int           `json:"Total"`               // Total amount of statements in the migration.
-		ExecutedAt      time.Time     `json:"ExecutedAt"`          // ExecutedAt is the starting point of execution.
-		ExecutionTime   time.Duration `json:"ExecutionTime"`       // ExecutionTime of the migration.
-		Error           string        `json:"Error,omitempty"`     // Error of the migration, if any occurred.
-		ErrorStmt       string        `json:"ErrorStmt,omitempty"` // ErrorStmt is the statement that raised Error.
-		Hash            string        `json:"-"`                   // Hash of migration file.
-		PartialHashes   []string      `json:"-"`                   // PartialHashes is the hashes of applied statements.
-		OperatorVersion string        `json:"OperatorVersion"`     // OperatorVersion that executed this migration.
+		Version         string          `json:"Version"`             // Version of the migration.
+		Description     string          `json:"Description"`         // Description of this migration.
+		Type            RevisionType    `json:"Type"`                // Type of the migration.
+		Applied         int             `json:"Applied"`             // Applied amount of statements in the migration.
+		Total           int             `json:"Total"`               // Total amount of statements in the migration.
+		ExecutedAt      time.Time       `json:"ExecutedAt"`          // ExecutedAt is the starting point of execution.
+		ExecutionTime   time.Duration   `json:"ExecutionTime"`       // ExecutionTime of the migration.
+		Error           string          `json:"Error,omitempty"`     // Error of the migration, if any occurred.
+		ErrorStmt       string          `json:"ErrorStmt,omitempty"` // ErrorStmt is the statement that raised Error.
+		Hash            string          `json:"-"`                   // Hash of migration file.
+		PartialHashes   []string        `json:"-"`                   // PartialHashes is the hashes of applied statements.
+		OperatorVersion string          `json:"OperatorVersion"`     // OperatorVersion that executed this migration.
+		Stmts           []*RevisionStmt `json:"Stmts,omitempty"`     // Stmts holds the execution details of the statements.
+	}
+
+	// RevisionStmt describes the execution of a statement in a revision.
+	RevisionStmt struct {
+		Stmt          string        `json:"Stmt,omitempty"`  // Stmt that was executed, if stored. See WithStmtText.
+		Hash          string        `json:"Hash,omitempty"`  // Hash of the statement and the ones before it.
+		ExecutedAt    time.Time     `json:"ExecutedAt"`      // ExecutedAt is the starting point of execution.
+		ExecutionTime time.Duration `json:"ExecutionTime"`   // ExecutionTime of the statement.
+		Error         string        `json:"Error,omitempty"` // Error of the statement, if any occurred.
+		// BackfillKey is the key of the last completed batch of a failed backfill statement.
+		BackfillKey *int64 `json:"BackfillKey,omitempty"`
 	}
 
 	// RevisionType defines the type of the revision record in the history table.
@@ -242,6 +289,12 @@ type (
 		baselineVer string             // Start the first migration after the given baseline version.
 		allowDirty  bool               // Allow start working on a non-clean database.
 		operator    string             // Revision.OperatorVersion
+		savepoints  bool               // Wrap each statement in a savepoint.
+		stmtText    bool               // Store the text of the executed statements in the revision.
+		audit       *auditor           // Record executed statements, if set.
+		backfill    *backfiller        // Execute backfill statements in batches, if set.
+		gate        *gate              // Consulted before executing a file, if set.
+		aborted     atomic.Bool        // Set by Abort to stop the execution before the next statement.
 	}
 
 	// ExecutorOption allows configuring an Executor using functional arguments.
@@ -338,6 +391,27 @@ func PlanWithMode(m PlanMode) PlannerOption {
 	}
 }
 
+// PlanWithQuotePolicy allows setting when identifiers are quoted
+// in the planned statements. For example, QuoteNeeded quotes only
+// identifiers that are reserved words or contain special characters.
+func PlanWithQuotePolicy(q QuotePolicy) PlannerOption {
+	return func(p *Planner) {
+		p.planOpts = append(p.planOpts, func(o *PlanOptions) {
+			o.Quote = q
+		})
+	}
+}
+
+// PlanWithIdentCase allows setting the case normalization of identifiers.
+// The names of the desired state objects are normalized before they are
+// compared with the current state, so that the planned statements create
+// objects with normalized names, and the next planning converges.
+func PlanWithIdentCase(c IdentCase) PlannerOption {
+	return func(p *Planner) {
+		p.identCase = c
+	}
+}
+
 // PlanWithDiffOptions allows setting custom diff options.
 func PlanWithDiffOptions(opts ...schema.DiffOption) PlannerOption {
 	return func(p *Planner) {
@@ -390,6 +464,7 @@ func (p *Planner) plan(ctx context.Context, name string, to StateReader, realmSc
 	if err != nil {
 		return nil, err
 	}
+	normalizeCase(desired, p.identCase)
 	var changes []schema.Change
 	switch {
 	case realmScope:
@@ -420,7 +495,12 @@ func (p *Planner) plan(ctx context.Context, name string, to StateReader, realmSc
 	if len(changes) == 0 {
 		return nil, ErrNoPlan
 	}
-	return p.drv.PlanChanges(ctx, name, changes, p.planOpts...)
+	plan, err := p.drv.PlanChanges(ctx, name, changes, p.planOpts...)
+	if err != nil {
+		return nil, err
+	}
+	classify(plan)
+	return plan, nil
 }
 
 // Checkpoint calculate the current state of the migration directory by executing its files,
@@ -463,7 +543,54 @@ func (p *Planner) checkpoint(ctx context.Context, name string, realmScope bool)
 	if len(changes) == 0 {
 		return &Plan{Name: name}, nil
 	}
-	return p.drv.PlanChanges(ctx, name, changes, p.planOpts...)
+	plan, err := p.drv.PlanChanges(ctx, name, changes, p.planOpts...)
+	if err != nil {
+		return nil, err
+	}
+	classify(plan)
+	return plan, nil
+}
+
+// normalizeCase normalizes the case of the object names in the realm, in place.
+func normalizeCase(r *schema.Realm, c IdentCase) {
+	var norm func(*string)
+	switch c {
+	case CaseLower:
+		norm = func(s *string) { *s = strings.ToLower(*s) }
+	case CaseUpper:
+		norm = func(s *string) { *s = strings.ToUpper(*s) }
+	default:
+		return
+	}
+	for _, s := range r.Schemas {
+		norm(&s.Name)
+		for _, t := range s.Tables {
+			norm(&t.Name)
+			for _, c := range t.Columns {
+				norm(&c.Name)
+			}
+			for _, idx := range t.Indexes {
+				norm(&idx.Name)
+			}
+			if t.PrimaryKey != nil {
+				norm(&t.PrimaryKey.Name)
+			}
+			for _, fk := range t.ForeignKeys {
+				norm(&fk.Symbol)
+			}
+			for _, a := range t.Attrs {
+				if c, ok := a.(*schema.Check); ok {
+					norm(&c.Name)
+				}
+			}
+		}
+		for _, v := range s.Views {
+			norm(&v.Name)
+			for _, c := range v.Columns {
+				norm(&c.Name)
+			}
+		}
+	}
 }
 
 // current returns the current realm state.
@@ -539,6 +666,10 @@ var (
 	ErrCleanCheckerUnsupported = errors.New("sql/migrate: driver does not support checking if database is clean")
 	// ErrRevisionNotExist is returned if the requested revision is not found in the storage.
 	ErrRevisionNotExist = errors.New("sql/migrate: revision not found")
+	// ErrAborted is returned by the Executor if the execution was stopped by a call to Abort,
+	// or by a cancellation of its context. In case of cancellation, the returned error wraps
+	// the context error as well.
+	ErrAborted = errors.New("sql/migrate: execute: execution aborted")
 )
 
 // MissingMigrationError is returned if a revision is partially applied but
@@ -620,6 +751,30 @@ func WithOperatorVersion(v string) ExecutorOption {
 	}
 }
 
+// WithSavepoints configures the Executor to wrap each statement in a savepoint. On failure, only
+// the failed statement is rolled back, and the statements of the file that were executed before it
+// are recorded in the revision as partially applied. This keeps the transaction usable, allowing
+// the caller to commit the progress and resume the file later.
+//
+// Savepoints can only be used when the driver runs in a transaction, and the database
+// supports transactional DDL, e.g. PostgreSQL or SQLite.
+func WithSavepoints(b bool) ExecutorOption {
+	return func(ex *Executor) error {
+		ex.savepoints = b
+		return nil
+	}
+}
+
+// WithStmtText configures the Executor to store the text of the executed statements in the
+// revision, alongside their timings. It is disabled by default, as statements may contain
+// literals, such as credentials or personal data, that should not be kept in the database.
+func WithStmtText(b bool) ExecutorOption {
+	return func(ex *Executor) error {
+		ex.stmtText = b
+		return nil
+	}
+}
+
 // Pending returns all pending (not fully applied) migration files in the migration directory.
 func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 	// Don't operate with a broken migration directory.
@@ -706,7 +861,16 @@ func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 
 // Execute executes the given migration file on the database. If it sees a file, that has been partially applied, it
 // will continue with the next statement in line.
+//
+// The context is checked for cancellation before every statement, but a statement that has already
+// started is executed to completion, and its state is recorded in the revision. Hence, canceling the
+// context (or calling Abort) stops the execution gracefully and returns an ErrAborted error.
+// Aborted files are recorded as partially applied revisions without an error, and LogAbort is
+// logged instead of LogError, so that aborts are not reported as failed migrations.
 func (e *Executor) Execute(ctx context.Context, m File) (err error) {
+	if err := e.interrupted(ctx); err != nil {
+		return err
+	}
 	hf, err := e.dir.Checksum()
 	if err != nil {
 		return fmt.Errorf("sql/migrate: execute: compute hash: %w", err)
@@ -719,6 +883,9 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 	if err != nil {
 		return fmt.Errorf("sql/migrate: execute: scanning statements from %q: %w", m.Name(), err)
 	}
+	if err := e.gate.allow(ctx, m, stmts); err != nil {
+		return err
+	}
 	// Create checksums for the statements.
 	var (
 		sums = make([]string, len(stmts))
@@ -737,6 +904,10 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 	if err != nil && !errors.Is(err, ErrRevisionNotExist) {
 		return fmt.Errorf("sql/migrate: execute: read revision: %w", err)
 	}
+	// Statements that were started, and their revisions, are not canceled with the
+	// context, so that the state of the database and the revisions stay consistent.
+	actx := ctx
+	ctx = withoutCancel(ctx)
 	if errors.Is(err, ErrRevisionNotExist) {
 		// Haven't seen this file before, create a new revision.
 		r = &Revision{
@@ -761,25 +932,83 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 		// If the file has been applied partially before, check if the
 		// applied statements have not changed.
 		for i := 0; i < r.Applied; i++ {
-			if i > len(sums) || sums[i] != strings.TrimPrefix(r.PartialHashes[i], "h1:") {
+			if i >= len(sums) || i >= len(r.PartialHashes) || sums[i] != strings.TrimPrefix(r.PartialHashes[i], "h1:") {
 				err = HistoryChangedError{m.Name(), i + 1}
 				e.log.Log(LogError{Error: err})
 				return err
 			}
 		}
 	}
+	var resume *int64
+	if r.Applied > 0 || r.Error != "" {
+		// Resume the execution from the failed statement. The statements after it might
+		// have been changed to fix the error, so the revision reflects the current file.
+		r.Total, r.Hash = len(stmts), hash
+		r.Error, r.ErrorStmt = "", ""
+		if len(r.Stmts) > r.Applied {
+			// Unchanged backfills resume after their last completed batch.
+			if rs := r.Stmts[r.Applied]; r.Applied < len(sums) && rs.Hash == "h1:"+sums[r.Applied] {
+				resume = rs.BackfillKey
+			}
+			r.Stmts = r.Stmts[:r.Applied]
+		}
+	}
+	var decls []*Stmt
+	if e.backfill != nil {
+		if decls, err = m.StmtDecls(); err != nil {
+			return fmt.Errorf("sql/migrate: execute: scanning statements from %q: %w", m.Name(), err)
+		}
+	}
 	e.log.Log(LogFile{m, r.Version, r.Description, r.Applied})
 	for _, stmt := range stmts[r.Applied:] {
+		if err = e.interrupted(actx); err != nil {
+			// Record the partial state without an error, as the execution did not
+			// fail. The next execution resumes from this statement.
+			e.log.Log(LogAbort{Error: err})
+			r.done()
+			return err
+		}
 		e.log.Log(LogStmt{stmt})
-		if _, err = e.drv.ExecContext(ctx, stmt); err != nil {
+		start := time.Now()
+		var (
+			bf   *backfill
+			last *int64
+		)
+		if r.Applied < len(decls) {
+			bf, err = e.backfill.backfillOf(decls[r.Applied])
+		}
+		switch {
+		case err != nil:
+		case bf != nil:
+			last, err = e.execBackfill(ctx, stmt, bf, resume)
+		default:
+			_, err = e.execStmt(ctx, stmt)
+		}
+		resume = nil
+		rs := &RevisionStmt{Hash: "h1:" + sums[r.Applied], ExecutedAt: start, ExecutionTime: time.Since(start)}
+		if e.stmtText {
+			rs.Stmt = stmt
+		}
+		if err != nil {
+			rs.Error, rs.BackfillKey = err.Error(), last
+		}
+		r.Stmts = append(r.Stmts, rs)
+		if err != nil {
 			e.log.Log(LogError{SQL: stmt, Error: err})
 			r.done()
 			r.ErrorStmt = stmt
 			r.Error = err.Error()
-			return fmt.Errorf("sql/migrate: execute: executing statement %q from version %q: %w", stmt, r.Version, err)
+			err = fmt.Errorf("sql/migrate: execute: executing statement %q from version %q: %w", stmt, r.Version, err)
+			if err2 := e.audit.record(ctx, m, stmt, start, r.Error); err2 != nil {
+				err = wrap(err2, err)
+			}
+			return err
 		}
 		r.PartialHashes = append(r.PartialHashes, "h1:"+sums[r.Applied])
 		r.Applied++
+		if err = e.audit.record(ctx, m, stmt, start, ""); err != nil {
+			return err
+		}
 		if err = e.writeRevision(ctx, r); err != nil {
 			return err
 		}
@@ -788,6 +1017,33 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 	return
 }
 
+// savepointID is used for generating unique savepoint names, as savepoints
+// with the same name shadow each other in nested or concurrent executions.
+var savepointID atomic.Uint64
+
+// execStmt executes the given statement. In case savepoints are enabled, the statement is
+// executed in a savepoint that is rolled back on failure, leaving the transaction usable.
+func (e *Executor) execStmt(ctx context.Context, stmt string) (sql.Result, error) {
+	if !e.savepoints {
+		return e.drv.ExecContext(ctx, stmt)
+	}
+	savepoint := fmt.Sprintf("atlas_stmt_%d", savepointID.Add(1))
+	if _, err := e.drv.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return nil, fmt.Errorf("create savepoint: %w", err)
+	}
+	res, err := e.drv.ExecContext(ctx, stmt)
+	if err != nil {
+		if _, err2 := e.drv.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); err2 != nil {
+			return nil, fmt.Errorf("%w: rollback to savepoint: %v", err, err2)
+		}
+		return nil, err
+	}
+	if _, err := e.drv.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); err != nil {
+		return nil, fmt.Errorf("release savepoint: %w", err)
+	}
+	return res, nil
+}
+
 func (e *Executor) writeRevision(ctx context.Context, r *Revision) error {
 	r.ExecutedAt = time.Now()
 	r.OperatorVersion = e.operator
@@ -840,6 +1096,38 @@ func (e *Executor) ExecuteTo(ctx context.Context, version string) (err error) {
 	return e.exec(ctx, pending)
 }
 
+// Abort stops the execution gracefully. The statement that is currently executed (if any) is
+// executed to completion, its revision is recorded, and the execution returns ErrAborted before
+// the next statement. Abort is safe for concurrent use, and allows external controllers (e.g.
+// deployment orchestrators) to stop an execution without canceling its context. Once called,
+// all subsequent executions of the Executor are aborted.
+func (e *Executor) Abort() {
+	e.aborted.Store(true)
+}
+
+// interrupted returns an ErrAborted error if the execution was aborted or its context was canceled.
+func (e *Executor) interrupted(ctx context.Context) error {
+	switch {
+	case e.aborted.Load():
+		return ErrAborted
+	case ctx.Err() != nil:
+		return fmt.Errorf("%w: %w", ErrAborted, ctx.Err())
+	}
+	return nil
+}
+
+// withoutCancel returns a context that keeps the values of its parent, but is never canceled.
+func withoutCancel(ctx context.Context) context.Context {
+	return noCancel{ctx}
+}
+
+// noCancel is a context that is never canceled.
+type noCancel struct{ context.Context }
+
+func (noCancel) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (noCancel) Done() <-chan struct{}       { return nil }
+func (noCancel) Err() error                  { return nil }
+
 func (e *Executor) exec(ctx context.Context, files []File) error {
 	revs, err := e.rrw.ReadRevisions(ctx)
 	if err != nil {
@@ -899,6 +1187,35 @@ func (e *Executor) Replay(ctx context.Context, r StateReader, opts ...ReplayOpti
 	return r.ReadState(ctx)
 }
 
+// ReplayDiff replays the migration directory up to the two given versions, and returns the changes
+// needed to migrate the database from the state at version "from" to the state at version "to".
+// An empty "from" version stands for the initial state of the database, and an empty "to" version
+// for the latest one. The database is restored after each replay, and therefore, the Executor is
+// expected to run on a dev database with a RevisionReadWriter that does not persist revisions,
+// e.g. NopRevisionReadWriter.
+func (e *Executor) ReplayDiff(ctx context.Context, r StateReader, from, to string, opts ...schema.DiffOption) ([]schema.Change, error) {
+	var (
+		err               error
+		previous, current *schema.Realm
+	)
+	if from == "" {
+		previous, err = r.ReadState(ctx)
+	} else {
+		previous, err = e.Replay(ctx, r, ReplayToVersion(from))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if current, err = e.Replay(ctx, r, ReplayToVersion(to)); err != nil {
+		return nil, err
+	}
+	changes, err := e.drv.RealmDiff(previous, current, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: diff versions %q and %q: %w", from, to, err)
+	}
+	return changes, nil
+}
+
 type (
 	// Snapshoter wraps the Snapshot method.
 	Snapshoter interface {
@@ -924,6 +1241,13 @@ type (
 		CheckClean(context.Context, *TableIdent) error
 	}
 
+	// IdentQuoter wraps the single QuoteIdent method. It is implemented by
+	// drivers, and used to write identifiers in statements they did not plan.
+	IdentQuoter interface {
+		// QuoteIdent returns the given identifier quoted by the driver rules.
+		QuoteIdent(string) string
+	}
+
 	// NotCleanError is returned when the connected dev-db is not in a clean state (aka it has schemas and tables).
 	// This check is done to ensure no data is lost by overriding it when working on the dev-db.
 	NotCleanError struct {
@@ -1022,6 +1346,13 @@ type (
 		Error error
 	}
 
+	// LogAbort is sent if the execution of a file was aborted gracefully
+	// before its next statement, either by Executor.Abort or by canceling
+	// the context. The file is recorded as partially applied.
+	LogAbort struct {
+		Error error // The ErrAborted error returned by the execution.
+	}
+
 	// NopLogger is a Logger that does nothing.
 	// It is useful for one-time replay of the migration directory.
 	NopLogger struct{}
@@ -1032,6 +1363,7 @@ func (LogFile) logEntry()      {}
 func (LogStmt) logEntry()      {}
 func (LogDone) logEntry()      {}
 func (LogError) logEntry()     {}
+func (LogAbort) logEntry()     {}
 
 // Log implements the Logger interface.
 func (NopLogger) Log(LogEntry) {}
@@ -9,8 +9,11 @@ import (
 	"database/sql"
 	_ "embed"
 	"errors"
+	"fmt"
 	"io/fs"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"text/template"
 	"time"
@@ -130,6 +133,16 @@ func TestPlanner_Plan(t *testing.T) {
 	plan, err = pl.Plan(ctx, "", migrate.Realm(nil))
 	require.NoError(t, err)
 	require.Equal(t, drv.plan, plan)
+
+	// Names of the desired state are normalized before diffing.
+	users := schema.NewTable("Users").AddColumns(schema.NewIntColumn("ID", "int"))
+	desired := schema.NewRealm(schema.New("Public").AddTables(users))
+	pl = migrate.NewPlanner(drv, d, migrate.PlanWithIdentCase(migrate.CaseLower))
+	_, err = pl.Plan(ctx, "", migrate.Realm(desired))
+	require.NoError(t, err)
+	require.Equal(t, "public", desired.Schemas[0].Name)
+	require.Equal(t, "users", users.Name)
+	require.Equal(t, "id", users.Columns[0].Name)
 }
 
 func TestPlanner_PlanSchema(t *testing.T) {
@@ -261,6 +274,49 @@ func TestExecutor_Replay(t *testing.T) {
 	require.ErrorAs(t, err, new(*migrate.NotCleanError))
 }
 
+func TestExecutor_ReplayDiff(t *testing.T) {
+	ctx := context.Background()
+	d, err := migrate.NewLocalDir(filepath.FromSlash("testdata/migrate/sub"))
+	require.NoError(t, err)
+	var (
+		states []int
+		drv    = &diffDriver{mockDriver: &mockDriver{}}
+		r      = migrate.StateReaderFunc(func(context.Context) (*schema.Realm, error) {
+			states = append(states, len(drv.executed))
+			return schema.NewRealm(schema.New(strconv.Itoa(len(drv.executed)))), nil
+		})
+	)
+	ex, err := migrate.NewExecutor(drv, d, migrate.NopRevisionReadWriter{})
+	require.NoError(t, err)
+	changes, err := ex.ReplayDiff(ctx, r, "1.a", "2.10.x-20")
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 5}, states)
+	require.Len(t, changes, 1)
+	require.Equal(t, "2", drv.from.Schemas[0].Name)
+	require.Equal(t, "5", drv.to.Schemas[0].Name)
+
+	// Empty versions stand for the initial and latest states.
+	states = nil
+	drv.mockDriver = &mockDriver{}
+	_, err = ex.ReplayDiff(ctx, r, "", "")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 5}, states)
+
+	_, err = ex.ReplayDiff(ctx, r, "0", "")
+	require.ErrorContains(t, err, `migration with version "0" not found`)
+}
+
+// diffDriver records the states passed to RealmDiff.
+type diffDriver struct {
+	*mockDriver
+	from, to *schema.Realm
+}
+
+func (d *diffDriver) RealmDiff(from, to *schema.Realm, _ ...schema.DiffOption) ([]schema.Change, error) {
+	d.from, d.to = from, to
+	return []schema.Change{&schema.AddSchema{S: to.Schemas[0]}}, nil
+}
+
 func TestExecutor_Pending(t *testing.T) {
 	var (
 		drv  = &mockDriver{}
@@ -496,17 +552,41 @@ func TestExecutor(t *testing.T) {
 		ErrorStmt:       "ALTER TABLE t_sub ADD c4 int;",
 		OperatorVersion: "op",
 	}, revs[len(revs)-1])
+	stmts := revs[len(revs)-1].Stmts
+	require.Len(t, stmts, 2)
+	// Statements text is not stored by default.
+	require.Empty(t, stmts[0].Stmt)
+	require.Equal(t, revs[len(revs)-1].PartialHashes[0], stmts[0].Hash)
+	require.Empty(t, stmts[0].Error)
+	require.False(t, stmts[0].ExecutedAt.IsZero())
+	require.Empty(t, stmts[1].Stmt)
+	require.NotEmpty(t, stmts[1].Hash)
+	require.Equal(t, "this is an error", stmts[1].Error)
 
 	// Will fail if applied contents hash has changed (like when editing a partially applied file to fix an error).
 	h := revs[len(revs)-1].PartialHashes[0]
 	revs[len(revs)-1].PartialHashes[0] += h
 	require.ErrorAs(t, ex.ExecuteN(context.Background(), 1), &migrate.HistoryChangedError{})
 
+	// Will fail if the applied statements hashes are missing.
+	revs[len(revs)-1].PartialHashes = nil
+	require.ErrorAs(t, ex.ExecuteN(context.Background(), 1), &migrate.HistoryChangedError{})
+
 	// Re-attempting to migrate will pick up where the execution was left off.
-	revs[len(revs)-1].PartialHashes[0] = h
+	revs[len(revs)-1].PartialHashes = []string{h}
 	*drv = mockDriver{}
 	require.NoError(t, ex.ExecuteN(context.Background(), 1))
 	require.Equal(t, []string{"ALTER TABLE t_sub ADD c4 int;"}, drv.executed)
+	revs, err = rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, revs[len(revs)-1].Applied)
+	require.Len(t, revs[len(revs)-1].PartialHashes, 2)
+	require.Empty(t, revs[len(revs)-1].Error)
+	require.Empty(t, revs[len(revs)-1].ErrorStmt)
+	// The failed attempt is replaced by the successful one.
+	require.Len(t, revs[len(revs)-1].Stmts, 2)
+	require.Equal(t, revs[len(revs)-1].PartialHashes[1], revs[len(revs)-1].Stmts[1].Hash)
+	require.Empty(t, revs[len(revs)-1].Stmts[1].Error)
 
 	// Everything is applied.
 	require.ErrorIs(t, ex.ExecuteN(context.Background(), 0), migrate.ErrNoPendingFiles)
@@ -519,6 +599,135 @@ func TestExecutor(t *testing.T) {
 	requireEqualRevisions(t, []*migrate.Revision{rev1, rev2}, *rrw)
 }
 
+func TestExecutor_StmtText(t *testing.T) {
+	var (
+		drv = &mockDriver{}
+		rrw = &mockRevisionReadWriter{}
+	)
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	ex, err := migrate.NewExecutor(drv, dir, rrw, migrate.WithStmtText(true))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	revs, err := rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.Len(t, revs[0].Stmts, 2)
+	require.Equal(t, "CREATE TABLE t_sub(c int);", revs[0].Stmts[0].Stmt)
+	require.Equal(t, "ALTER TABLE t_sub ADD c1 int;", revs[0].Stmts[1].Stmt)
+	require.Equal(t, revs[0].PartialHashes, []string{revs[0].Stmts[0].Hash, revs[0].Stmts[1].Hash})
+}
+
+func TestExecutor_Savepoints(t *testing.T) {
+	var (
+		drv = &mockDriver{}
+		rrw = &mockRevisionReadWriter{}
+		// savepoints returns the executed statements with
+		// the generated savepoint names replaced by "sp<n>".
+		savepoints = func() []string {
+			var (
+				stmts []string
+				names = make(map[string]string)
+			)
+			for _, s := range drv.executed {
+				if i := strings.Index(s, "atlas_stmt_"); i != -1 {
+					n, ok := names[s[i:]]
+					if !ok {
+						n = fmt.Sprintf("sp%d", len(names)+1)
+						names[s[i:]] = n
+					}
+					s = s[:i] + n
+				}
+				stmts = append(stmts, s)
+			}
+			return stmts
+		}
+	)
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	ex, err := migrate.NewExecutor(drv, dir, rrw, migrate.WithSavepoints(true))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	// Each statement is wrapped in a savepoint with a unique name.
+	require.Equal(t, []string{
+		"SAVEPOINT sp1", "CREATE TABLE t_sub(c int);", "RELEASE SAVEPOINT sp1",
+		"SAVEPOINT sp2", "ALTER TABLE t_sub ADD c1 int;", "RELEASE SAVEPOINT sp2",
+	}, savepoints())
+
+	// Only the failed statement is rolled back, and the
+	// revision records the statements executed before it.
+	*rrw = mockRevisionReadWriter{}
+	*drv = mockDriver{}
+	drv.failOn(5, errors.New("this is an error"))
+	require.ErrorContains(t, ex.ExecuteN(context.Background(), 1), "this is an error")
+	require.Equal(t, []string{
+		"SAVEPOINT sp1", "CREATE TABLE t_sub(c int);", "RELEASE SAVEPOINT sp1",
+		"SAVEPOINT sp2", "ROLLBACK TO SAVEPOINT sp2",
+	}, savepoints())
+	revs, err := rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.Equal(t, 1, revs[0].Applied)
+	require.Equal(t, 2, revs[0].Total)
+	require.Len(t, revs[0].PartialHashes, 1)
+	require.Equal(t, "ALTER TABLE t_sub ADD c1 int;", revs[0].ErrorStmt)
+
+	// Resuming continues from the failed statement.
+	*drv = mockDriver{}
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	require.Equal(t, []string{
+		"SAVEPOINT sp1", "ALTER TABLE t_sub ADD c1 int;", "RELEASE SAVEPOINT sp1",
+	}, savepoints())
+}
+
+func TestExecutor_Abort(t *testing.T) {
+	var (
+		rrw         = &mockRevisionReadWriter{}
+		log         = &mockLogger{}
+		ctx, cancel = context.WithCancel(context.Background())
+		drv         = &execHookDriver{mockDriver: &mockDriver{}}
+	)
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	ex, err := migrate.NewExecutor(drv, dir, rrw, migrate.WithLogger(log))
+	require.NoError(t, err)
+
+	// The in-flight statement is executed to completion, and
+	// the execution stops before the next statement.
+	drv.hook = func(ctx context.Context) {
+		cancel()
+		require.NoError(t, ctx.Err(), "statements are not canceled")
+	}
+	aerr := ex.ExecuteN(ctx, 1)
+	require.ErrorIs(t, aerr, migrate.ErrAborted)
+	require.ErrorIs(t, aerr, context.Canceled)
+	require.Equal(t, []string{"CREATE TABLE t_sub(c int);"}, drv.executed)
+	revs, err := rrw.ReadRevisions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.Equal(t, 1, revs[0].Applied)
+	require.Equal(t, 2, revs[0].Total)
+	// Aborts are not recorded, or logged, as errors.
+	require.Empty(t, revs[0].Error)
+	require.Empty(t, revs[0].ErrorStmt)
+	require.Equal(t, migrate.LogAbort{Error: aerr}, (*log)[len(*log)-1])
+	for _, e := range *log {
+		_, ok := e.(migrate.LogError)
+		require.False(t, ok)
+	}
+
+	// Resuming continues from the next statement.
+	drv.hook, drv.executed = nil, nil
+	require.NoError(t, ex.ExecuteN(context.Background(), 1))
+	require.Equal(t, []string{"ALTER TABLE t_sub ADD c1 int;"}, drv.executed)
+
+	// Aborted executors do not execute statements.
+	drv.executed = nil
+	ex.Abort()
+	require.ErrorIs(t, ex.ExecuteN(context.Background(), 1), migrate.ErrAborted)
+	require.Empty(t, drv.executed)
+}
+
 func TestExecutor_Baseline(t *testing.T) {
 	var (
 		rrw mockRevisionReadWriter
@@ -578,6 +787,20 @@ type (
 	}
 )
 
+// execHookDriver calls the hook after each statement executed by the driver.
+type execHookDriver struct {
+	*mockDriver
+	hook func(context.Context)
+}
+
+func (d *execHookDriver) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	res, err := d.mockDriver.ExecContext(ctx, query, args...)
+	if d.hook != nil {
+		d.hook(ctx)
+	}
+	return res, err
+}
+
 // the nth call to ExecContext will fail with the given error.
 func (m *mockDriver) failOn(n int, err error) {
 	m.failCounter = n
@@ -0,0 +1,389 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package notify provides notifiers for migration lifecycle events, such as
+// generic webhooks and Slack incoming webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+)
+
+type (
+	// EventType describes the type of lifecycle event.
+	EventType string
+
+	// Event describes a migration lifecycle event.
+	Event struct {
+		Type EventType `json:"type"`
+		Time time.Time `json:"time"`
+		// Plan summary. Set for plan events.
+		Plan *PlanSummary `json:"plan,omitempty"`
+		// Versions and files of the execution. Set for apply events.
+		From  string   `json:"from,omitempty"`
+		To    string   `json:"to,omitempty"`
+		Files []string `json:"files,omitempty"`
+		// Number of statements that were applied until the event.
+		Applied int `json:"applied,omitempty"`
+		// Statement that failed, and the error. Set for failure events.
+		Stmt  string `json:"stmt,omitempty"`
+		Error string `json:"error,omitempty"`
+	}
+
+	// PlanSummary summarizes a migration plan.
+	PlanSummary struct {
+		Name          string   `json:"name,omitempty"`
+		Version       string   `json:"version,omitempty"`
+		Reversible    bool     `json:"reversible"`
+		Transactional bool     `json:"transactional"`
+		Stmts         []string `json:"stmts,omitempty"`
+	}
+
+	// Notifier sends notifications for lifecycle events.
+	Notifier interface {
+		Notify(context.Context, *Event) error
+	}
+
+	// NotifierFunc allows using an ordinary function as a Notifier.
+	NotifierFunc func(context.Context, *Event) error
+
+	// Notifiers is a list of notifiers that are notified together.
+	Notifiers []Notifier
+)
+
+// List of lifecycle events.
+const (
+	PlanCreated   EventType = "plan_created"
+	ApplyStarted  EventType = "apply_started"
+	StmtFailed    EventType = "stmt_failed"
+	ApplyFinished EventType = "apply_finished"
+)
+
+// Notify implements the Notifier interface.
+func (f NotifierFunc) Notify(ctx context.Context, e *Event) error {
+	return f(ctx, e)
+}
+
+// Notify notifies all notifiers, and returns the joined errors, if any.
+func (ns Notifiers) Notify(ctx context.Context, e *Event) error {
+	var errs []error
+	for _, n := range ns {
+		if err := n.Notify(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PlanEvent returns the PlanCreated event of the given plan.
+func PlanEvent(p *migrate.Plan) *Event {
+	s := &PlanSummary{
+		Name:          p.Name,
+		Version:       p.Version,
+		Reversible:    p.Reversible,
+		Transactional: p.Transactional,
+		Stmts:         make([]string, len(p.Changes)),
+	}
+	for i, c := range p.Changes {
+		s.Stmts[i] = c.Cmd
+	}
+	return &Event{Type: PlanCreated, Time: time.Now(), Plan: s}
+}
+
+// Summary returns a short, human-readable, description of the event.
+func (e *Event) Summary() string {
+	switch e.Type {
+	case PlanCreated:
+		return fmt.Sprintf("Migration plan %q was created with %d statements", e.Plan.Name, len(e.Plan.Stmts))
+	case ApplyStarted:
+		return fmt.Sprintf("Migration started applying %d files%s", len(e.Files), e.versions())
+	case StmtFailed:
+		return fmt.Sprintf("Migration statement failed after %d statements were applied: %s", e.Applied, e.Error)
+	case ApplyFinished:
+		if e.Error != "" {
+			return fmt.Sprintf("Migration failed after %d statements were applied%s: %s", e.Applied, e.versions(), e.Error)
+		}
+		return fmt.Sprintf("Migration finished applying %d statements%s", e.Applied, e.versions())
+	default:
+		return string(e.Type)
+	}
+}
+
+func (e *Event) versions() string {
+	switch {
+	case e.From != "" && e.To != "":
+		return fmt.Sprintf(" (%s -> %s)", e.From, e.To)
+	case e.To != "":
+		return fmt.Sprintf(" (-> %s)", e.To)
+	default:
+		return ""
+	}
+}
+
+// TemplateFuncs are the functions available in payload templates.
+var TemplateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		// Payloads are not embedded in HTML.
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(b.String(), "\n"), nil
+	},
+	"join": strings.Join,
+}
+
+var (
+	// DefaultTemplate is the payload template of the generic webhook. It encodes the event as JSON.
+	DefaultTemplate = template.Must(template.New("webhook").Funcs(TemplateFuncs).Parse(`{{ json . }}`))
+	// SlackTemplate is the payload template of Slack incoming webhooks.
+	SlackTemplate = template.Must(template.New("slack").Funcs(TemplateFuncs).Parse(
+		`{"text": {{ json .Summary }}{{ with .Plan }}{{ with .Stmts }}, "blocks": [{"type": "section", "text": {"type": "mrkdwn", "text": {{ json (printf "%s\n` + "```%s```" + `" $.Summary (join . "\n")) }}}}]{{ end }}{{ end }}}`,
+	))
+)
+
+type (
+	// Webhook is a Notifier that posts templated payloads to an HTTP endpoint.
+	Webhook struct {
+		url    string
+		client *http.Client
+		tmpl   *template.Template
+		header http.Header
+		events map[EventType]bool
+	}
+
+	// WebhookOption configures a Webhook.
+	WebhookOption func(*Webhook) error
+)
+
+// DefaultClient is the HTTP client used by webhooks, unless configured otherwise.
+// Its timeout ensures unresponsive endpoints do not hold the migration execution.
+var DefaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewWebhook returns a Webhook notifier for the given URL. By default, the event is posted as JSON.
+func NewWebhook(url string, opts ...WebhookOption) (*Webhook, error) {
+	if url == "" {
+		return nil, errors.New("notify: missing webhook url")
+	}
+	w := &Webhook{
+		url:    url,
+		client: DefaultClient,
+		tmpl:   DefaultTemplate,
+		header: http.Header{"Content-Type": []string{"application/json"}},
+	}
+	for _, opt := range opts {
+		if err := opt(w); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// NewSlack returns a Webhook notifier for a Slack incoming webhook URL.
+func NewSlack(url string, opts ...WebhookOption) (*Webhook, error) {
+	return NewWebhook(url, append([]WebhookOption{WithTemplate(SlackTemplate)}, opts...)...)
+}
+
+// WithTemplate configures the template used for building the payload. The template is executed with the Event.
+func WithTemplate(t *template.Template) WebhookOption {
+	return func(w *Webhook) error {
+		if t == nil {
+			return errors.New("notify: nil template")
+		}
+		w.tmpl = t
+		return nil
+	}
+}
+
+// WithTemplateText parses the given text as the payload template. See TemplateFuncs for the available functions.
+func WithTemplateText(text string) WebhookOption {
+	return func(w *Webhook) error {
+		t, err := template.New("webhook").Funcs(TemplateFuncs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("notify: parse template: %w", err)
+		}
+		w.tmpl = t
+		return nil
+	}
+}
+
+// WithHeader sets a header on the webhook requests. For example, an authorization header.
+func WithHeader(k, v string) WebhookOption {
+	return func(w *Webhook) error {
+		w.header.Set(k, v)
+		return nil
+	}
+}
+
+// WithHTTPClient configures the HTTP client used for sending the requests.
+// Note, clients without a timeout may block the delivery of later events.
+func WithHTTPClient(c *http.Client) WebhookOption {
+	return func(w *Webhook) error {
+		if c == nil {
+			return errors.New("notify: nil http client")
+		}
+		w.client = c
+		return nil
+	}
+}
+
+// WithEvents limits the notifications to the given event types. By default, all events are sent.
+func WithEvents(types ...EventType) WebhookOption {
+	return func(w *Webhook) error {
+		w.events = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			w.events[t] = true
+		}
+		return nil
+	}
+}
+
+// Notify implements the Notifier interface.
+func (w *Webhook) Notify(ctx context.Context, e *Event) error {
+	if w.events != nil && !w.events[e.Type] {
+		return nil
+	}
+	var b bytes.Buffer
+	if err := w.tmpl.Execute(&b, e); err != nil {
+		return fmt.Errorf("notify: execute template: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, &b)
+	if err != nil {
+		return fmt.Errorf("notify: create request: %w", err)
+	}
+	for k, v := range w.header {
+		req.Header[k] = v
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notify: send %s event: %w", e.Type, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("notify: unexpected status %d for %s event: %s", resp.StatusCode, e.Type, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// Logger is a migrate.Logger that fires lifecycle events to a Notifier, on top of an
+// underlying logger. Events are delivered in order by a background goroutine, so slow
+// endpoints do not block the migration execution. Errors of the notifier do not affect
+// the execution, and are returned by the Close method.
+type Logger struct {
+	ctx     context.Context
+	next    migrate.Logger
+	n       Notifier
+	mu      sync.Mutex
+	exec    *migrate.LogExecution
+	applied int
+	closed  bool
+	queue   chan *Event
+	done    chan struct{}
+	errs    []error
+}
+
+// queueSize is the number of events that can be pending delivery. Events
+// that are fired when the queue is full are dropped, and reported as errors.
+const queueSize = 64
+
+// NewLogger returns a Logger that fires the events to the given notifier. The next logger may be nil.
+// Callers must call Close after the execution to wait for the delivery of the pending events.
+func NewLogger(ctx context.Context, n Notifier, next migrate.Logger) *Logger {
+	if next == nil {
+		next = migrate.NopLogger{}
+	}
+	l := &Logger{ctx: ctx, n: n, next: next, queue: make(chan *Event, queueSize), done: make(chan struct{})}
+	go l.deliver()
+	return l
+}
+
+// Log implements the migrate.Logger interface.
+func (l *Logger) Log(e migrate.LogEntry) {
+	l.next.Log(e)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch e := e.(type) {
+	case migrate.LogExecution:
+		l.exec, l.applied = &e, 0
+		l.notify(&Event{Type: ApplyStarted})
+	case migrate.LogStmt:
+		l.applied++
+	case migrate.LogError:
+		ev := &Event{Type: StmtFailed, Stmt: e.SQL}
+		if e.Error != nil {
+			ev.Error = e.Error.Error()
+		}
+		// Errors that were not caused by a statement fail the execution.
+		if e.SQL == "" {
+			ev.Type = ApplyFinished
+		} else if l.applied > 0 {
+			// The failed statement was counted as applied.
+			l.applied--
+		}
+		l.notify(ev)
+	case migrate.LogDone:
+		l.notify(&Event{Type: ApplyFinished})
+	}
+}
+
+// Close waits for the delivery of the pending events, and returns the errors
+// that were returned by the notifier, if any. Events logged after Close are ignored.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+	<-l.done
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return errors.Join(l.errs...)
+}
+
+// notify queues the event for delivery. It is called with the mutex held.
+func (l *Logger) notify(e *Event) {
+	if l.closed {
+		return
+	}
+	e.Time, e.Applied = time.Now(), l.applied
+	if l.exec != nil {
+		e.From, e.To = l.exec.From, l.exec.To
+		for _, f := range l.exec.Files {
+			e.Files = append(e.Files, f.Name())
+		}
+	}
+	select {
+	case l.queue <- e:
+	default:
+		l.errs = append(l.errs, fmt.Errorf("notify: dropped %s event: too many pending events", e.Type))
+	}
+}
+
+// deliver sends the queued events to the notifier.
+func (l *Logger) deliver() {
+	defer close(l.done)
+	for e := range l.queue {
+		if err := l.n.Notify(l.ctx, e); err != nil {
+			l.mu.Lock()
+			l.errs = append(l.errs, err)
+			l.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/migrate/notify"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook(t *testing.T) {
+	var (
+		bodies  []string
+		headers []http.Header
+		srv     = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			bodies, headers = append(bodies, string(b)), append(headers, r.Header)
+			if r.URL.Path == "/fail" {
+				http.Error(w, "boom", http.StatusInternalServerError)
+			}
+		}))
+		ctx  = context.Background()
+		plan = &migrate.Plan{Name: "add_users", Changes: []*migrate.Change{{Cmd: "CREATE TABLE users (id int)"}}}
+	)
+	defer srv.Close()
+
+	w, err := notify.NewWebhook(srv.URL, notify.WithHeader("Authorization", "Bearer token"))
+	require.NoError(t, err)
+	require.NoError(t, w.Notify(ctx, notify.PlanEvent(plan)))
+	var e notify.Event
+	require.NoError(t, json.Unmarshal([]byte(bodies[0]), &e))
+	require.Equal(t, notify.PlanCreated, e.Type)
+	require.Equal(t, []string{"CREATE TABLE users (id int)"}, e.Plan.Stmts)
+	require.Equal(t, "Bearer token", headers[0].Get("Authorization"))
+	require.Equal(t, "application/json", headers[0].Get("Content-Type"))
+
+	s, err := notify.NewSlack(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, s.Notify(ctx, notify.PlanEvent(plan)))
+	var msg struct {
+		Text   string
+		Blocks []struct{ Text struct{ Text string } }
+	}
+	require.NoError(t, json.Unmarshal([]byte(bodies[1]), &msg))
+	require.Equal(t, `Migration plan "add_users" was created with 1 statements`, msg.Text)
+	require.Equal(t, "Migration plan \"add_users\" was created with 1 statements\n```CREATE TABLE users (id int)```", msg.Blocks[0].Text.Text)
+
+	w, err = notify.NewWebhook(srv.URL, notify.WithTemplateText(`{"summary": {{ json .Summary }}}`), notify.WithEvents(notify.ApplyFinished))
+	require.NoError(t, err)
+	require.NoError(t, w.Notify(ctx, notify.PlanEvent(plan)))
+	require.Len(t, bodies, 2, "event type is filtered")
+	require.NoError(t, w.Notify(ctx, &notify.Event{Type: notify.ApplyFinished, Applied: 2, From: "1", To: "2"}))
+	require.Equal(t, `{"summary": "Migration finished applying 2 statements (1 -> 2)"}`, bodies[2])
+
+	w, err = notify.NewWebhook(srv.URL + "/fail")
+	require.NoError(t, err)
+	require.EqualError(t, w.Notify(ctx, notify.PlanEvent(plan)), "notify: unexpected status 500 for plan_created event: boom")
+
+	_, err = notify.NewWebhook("")
+	require.Error(t, err)
+	_, err = notify.NewWebhook(srv.URL, notify.WithTemplateText("{{"))
+	require.Error(t, err)
+}
+
+func TestLogger(t *testing.T) {
+	var (
+		events []*notify.Event
+		n      = notify.NotifierFunc(func(_ context.Context, e *notify.Event) error {
+			events = append(events, e)
+			if e.Type == notify.StmtFailed {
+				return errors.New("unreachable")
+			}
+			return nil
+		})
+		l   = notify.NewLogger(context.Background(), notify.Notifiers{n}, nil)
+		dir = &migrate.MemDir{}
+	)
+	require.NoError(t, dir.WriteFile("1_a.sql", []byte("CREATE TABLE a (id int);")))
+	files, err := dir.Files()
+	require.NoError(t, err)
+	l.Log(migrate.LogExecution{To: "1", Files: files})
+	l.Log(migrate.LogFile{File: files[0]})
+	l.Log(migrate.LogStmt{SQL: "CREATE TABLE a (id int);"})
+	l.Log(migrate.LogDone{})
+	require.NoError(t, l.Close())
+	require.Len(t, events, 2)
+	require.Equal(t, notify.ApplyStarted, events[0].Type)
+	require.Equal(t, []string{"1_a.sql"}, events[0].Files)
+	require.Equal(t, notify.ApplyFinished, events[1].Type)
+	require.Equal(t, 1, events[1].Applied)
+	require.Equal(t, "Migration finished applying 1 statements (-> 1)", events[1].Summary())
+
+	events = nil
+	l = notify.NewLogger(context.Background(), notify.Notifiers{n}, nil)
+	l.Log(migrate.LogExecution{To: "1", Files: files})
+	l.Log(migrate.LogStmt{SQL: "CREATE TABLE a (id int);"})
+	l.Log(migrate.LogError{SQL: "CREATE TABLE a (id int);", Error: errors.New("table a exists")})
+	require.EqualError(t, l.Close(), "unreachable")
+	require.Len(t, events, 2)
+	require.Equal(t, notify.StmtFailed, events[1].Type)
+	require.Equal(t, 0, events[1].Applied)
+	require.Equal(t, "table a exists", events[1].Error)
+
+	// Slow notifiers do not block the execution.
+	var (
+		release = make(chan struct{})
+		slow    = notify.NotifierFunc(func(context.Context, *notify.Event) error {
+			<-release
+			return nil
+		})
+	)
+	l = notify.NewLogger(context.Background(), slow, nil)
+	for i := 0; i < 100; i++ {
+		l.Log(migrate.LogExecution{To: "1", Files: files})
+	}
+	close(release)
+	err = l.Close()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "notify: dropped apply_started event: too many pending events")
+	// Events logged after Close are ignored.
+	l.Log(migrate.LogDone{})
+}
+
+func TestWebhook_Timeout(t *testing.T) {
+	stop := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-stop
+	}))
+	defer srv.Close()
+	defer close(stop)
+	require.NotZero(t, notify.DefaultClient.Timeout)
+	w, err := notify.NewWebhook(srv.URL, notify.WithHTTPClient(&http.Client{Timeout: 10 * time.Millisecond}))
+	require.NoError(t, err)
+	require.ErrorContains(t, w.Notify(context.Background(), &notify.Event{Type: notify.ApplyStarted}), "notify: send apply_started event:")
+	_, err = notify.NewWebhook(srv.URL, notify.WithHTTPClient(nil))
+	require.EqualError(t, err, "notify: nil http client")
+}
@@ -0,0 +1,413 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// ProtectionRule protects the targets (database URLs) matching its patterns from unwanted
+	// applies. A rule can deny destructive changes, require an explicit approval, or allow
+	// applies only within a set of time windows. All constraints of a rule must be satisfied
+	// for an apply to be allowed.
+	ProtectionRule struct {
+		// URLs holds the patterns of the protected URLs, e.g. "postgres://*.prod.internal/*".
+		// The scheme, host, port and path of a pattern are matched separately using path.Match,
+		// and the user info and query of the URL are ignored. Scheme flavours match the scheme
+		// of their driver (e.g., "postgres" matches "postgresql" and "mysql" matches "mysql+unix"),
+		// the port is matched only if set by the pattern, and a pattern without a path matches
+		// all paths. An empty list matches all URLs.
+		URLs []string
+
+		// DenyDestructive denies applies that contain destructive changes, e.g. dropping a column.
+		DenyDestructive bool
+
+		// Approve, if set, is called before the apply is executed.
+		// A non-nil error denies the apply.
+		Approve func(context.Context, *ProtectedApply) error
+
+		// Windows, if set, allows applies only within one of the given time windows.
+		Windows []TimeWindow
+	}
+
+	// TimeWindow describes a recurring daily time window, e.g. "Mon-Fri 02:00-06:00 UTC".
+	TimeWindow struct {
+		// Days of the week the window opens on. An empty list means every day.
+		Days []time.Weekday
+		// Start and End of the window as offsets from midnight. A window that ends
+		// before it starts spans midnight, e.g. 22:00-02:00.
+		Start, End time.Duration
+		// Location of the window. Defaults to UTC.
+		Location *time.Location
+	}
+
+	// ProtectedApply describes an apply that is checked against the protection rules.
+	ProtectedApply struct {
+		// URL of the target database.
+		URL string
+		// Class of the changes (or statements) being applied.
+		Class ChangeClass
+		// Time the apply is executed on. Defaults to the current time.
+		Time time.Time
+		// Stmts holds the statements to be executed, if known.
+		Stmts []string
+		// Version of the migration file, if the apply is executed by an Executor.
+		Version string
+	}
+
+	// ProtectionError is returned by CheckProtection in case an apply is denied.
+	ProtectionError struct {
+		URL    string // Redacted URL of the target.
+		Reason string // Reason the apply was denied.
+		Err    error  // Error returned by the approval callback, if any.
+	}
+)
+
+// ErrNotApproved can be returned by approval callbacks to deny an apply.
+var ErrNotApproved = errors.New("apply was not approved")
+
+// Error implements the error interface.
+func (e *ProtectionError) Error() string {
+	return fmt.Sprintf("sql/migrate: target %q is protected: %s", e.URL, e.Reason)
+}
+
+// Unwrap returns the error returned by the approval callback, if any.
+func (e *ProtectionError) Unwrap() error {
+	return e.Err
+}
+
+// Match reports if the rule applies to the given URL.
+func (r *ProtectionRule) Match(u string) (bool, error) {
+	if len(r.URLs) == 0 {
+		return true, nil
+	}
+	target, err := url.Parse(u)
+	if err != nil {
+		return false, fmt.Errorf("sql/migrate: parse target url: %w", err)
+	}
+	for _, p := range r.URLs {
+		ok, err := matchURL(p, target)
+		if err != nil {
+			return false, fmt.Errorf("sql/migrate: invalid protection pattern %q: %w", p, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchURL matches the scheme, host, port and path of the URL against the pattern.
+func matchURL(pattern string, u *url.URL) (bool, error) {
+	scheme, rest, ok := strings.Cut(pattern, "://")
+	if !ok {
+		return false, errors.New("missing scheme")
+	}
+	var (
+		err              error
+		host, p, hasPath = strings.Cut(rest, "/")
+		hostname, port   = host, ""
+	)
+	// The last colon separates the port, unless it is part of an IPv6 address.
+	if i := strings.LastIndexByte(host, ':'); i > strings.LastIndexByte(host, ']') {
+		hostname, port = host[:i], host[i+1:]
+	}
+	match := func(pattern, s string) bool {
+		if err == nil && ok {
+			ok, err = path.Match(pattern, s)
+		}
+		return ok
+	}
+	// Brackets of IPv6 addresses are stripped by url.Hostname.
+	if strings.HasPrefix(hostname, "[") && strings.HasSuffix(hostname, "]") {
+		hostname = hostname[1 : len(hostname)-1]
+	}
+	ok = match(schemeOf(scheme), schemeOf(u.Scheme)) && match(hostname, u.Hostname())
+	// The port is matched only if set by the pattern.
+	if port != "" {
+		match(port, u.Port())
+	}
+	// A pattern without a path matches all paths.
+	if hasPath {
+		target := u.Path
+		if u.Opaque != "" {
+			target = u.Opaque
+		}
+		if target == "" {
+			target = "/"
+		}
+		match("/"+p, target)
+	}
+	return ok && err == nil, err
+}
+
+// schemeFlavours maps scheme flavours to the scheme of their driver.
+var schemeFlavours = map[string]string{
+	"postgresql": "postgres",
+	"maria":      "mysql",
+	"mariadb":    "mysql",
+	"sqlite":     "sqlite3",
+}
+
+// schemeOf returns the driver scheme of the given scheme, without its transport
+// suffix (e.g., "+unix"). Unknown schemes are returned as is.
+func schemeOf(s string) string {
+	s, _, _ = strings.Cut(strings.ToLower(s), "+")
+	if d, ok := schemeFlavours[s]; ok {
+		return d
+	}
+	return s
+}
+
+// CheckProtection checks the given apply against the rules that match its URL, and returns
+// a ProtectionError in case one of them denies it. Destructive changes and time windows are
+// checked before the approval callbacks are called.
+//
+//	rules := []*migrate.ProtectionRule{
+//		{URLs: []string{"postgres://*.prod.internal/*"}, DenyDestructive: true},
+//	}
+//	if err := migrate.CheckProtection(ctx, rules, &migrate.ProtectedApply{URL: url, Class: plan.Class()}); err != nil {
+//		return err
+//	}
+func CheckProtection(ctx context.Context, rules []*ProtectionRule, a *ProtectedApply) error {
+	var matched []*ProtectionRule
+	for _, r := range rules {
+		ok, err := r.Match(a.URL)
+		if err != nil {
+			return err
+		}
+		if ok {
+			matched = append(matched, r)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	target, err := protectedURL(a.URL)
+	if err != nil {
+		return err
+	}
+	now := a.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	for _, r := range matched {
+		if r.DenyDestructive && a.Class.Has(ClassDestructive) {
+			return &ProtectionError{URL: target, Reason: "destructive changes are denied"}
+		}
+		if len(r.Windows) > 0 && !inWindows(r.Windows, now) {
+			ws := make([]string, len(r.Windows))
+			for i := range r.Windows {
+				ws[i] = r.Windows[i].String()
+			}
+			return &ProtectionError{URL: target, Reason: fmt.Sprintf("applies are allowed only within %s", strings.Join(ws, ", "))}
+		}
+	}
+	for _, r := range matched {
+		if r.Approve == nil {
+			continue
+		}
+		if err := r.Approve(ctx, a); err != nil {
+			return &ProtectionError{URL: target, Reason: err.Error(), Err: err}
+		}
+	}
+	return nil
+}
+
+// Contains reports if the given time is within the window. For windows that span
+// midnight, the day of the week is the day the window opens on.
+func (w TimeWindow) Contains(t time.Time) bool {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	since := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc))
+	switch {
+	case w.Start == w.End:
+		return w.onDay(t.Weekday())
+	case w.Start < w.End:
+		return w.onDay(t.Weekday()) && since >= w.Start && since < w.End
+	// Spans midnight.
+	case since >= w.Start:
+		return w.onDay(t.Weekday())
+	case since < w.End:
+		return w.onDay((t.Weekday() + 6) % 7)
+	}
+	return false
+}
+
+// String implements fmt.Stringer.
+func (w TimeWindow) String() string {
+	var b strings.Builder
+	if len(w.Days) > 0 {
+		days := make([]string, len(w.Days))
+		for i, d := range w.Days {
+			days[i] = d.String()[:3]
+		}
+		b.WriteString(strings.Join(days, ","))
+		b.WriteByte(' ')
+	}
+	fmt.Fprintf(&b, "%s-%s", clock(w.Start), clock(w.End))
+	if w.Location != nil {
+		b.WriteString(" " + w.Location.String())
+	} else {
+		b.WriteString(" UTC")
+	}
+	return b.String()
+}
+
+func (w TimeWindow) onDay(d time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d1 := range w.Days {
+		if d1 == d {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTimeWindow parses a time window in the format of "[DAYS] HH:MM-HH:MM [LOCATION]".
+// Days are comma-separated lists of weekdays or ranges of weekdays, and the location is an
+// IANA time zone name. For example:
+//
+//	02:00-06:00
+//	Sat,Sun 00:00-24:00
+//	Mon-Fri 22:00-02:00 Europe/Berlin
+func ParseTimeWindow(s string) (TimeWindow, error) {
+	var (
+		w      TimeWindow
+		fields = strings.Fields(s)
+	)
+	if len(fields) > 0 && !strings.Contains(fields[0], ":") {
+		days, err := parseDays(fields[0])
+		if err != nil {
+			return w, fmt.Errorf("sql/migrate: invalid time window %q: %w", s, err)
+		}
+		w.Days, fields = days, fields[1:]
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		return w, fmt.Errorf("sql/migrate: invalid time window %q: expect format [DAYS] HH:MM-HH:MM [LOCATION]", s)
+	}
+	m := reTimeRange.FindStringSubmatch(fields[0])
+	if m == nil {
+		return w, fmt.Errorf("sql/migrate: invalid time window %q: unexpected time range %q", s, fields[0])
+	}
+	var err error
+	if w.Start, err = parseClock(m[1], m[2]); err != nil {
+		return w, fmt.Errorf("sql/migrate: invalid time window %q: %w", s, err)
+	}
+	if w.End, err = parseClock(m[3], m[4]); err != nil {
+		return w, fmt.Errorf("sql/migrate: invalid time window %q: %w", s, err)
+	}
+	if len(fields) == 2 {
+		if w.Location, err = time.LoadLocation(fields[1]); err != nil {
+			return w, fmt.Errorf("sql/migrate: invalid time window %q: %w", s, err)
+		}
+	}
+	return w, nil
+}
+
+// reTimeRange matches time ranges in the format of HH:MM-HH:MM.
+var reTimeRange = regexp.MustCompile(`^(\d{1,2}):(\d{2})-(\d{1,2}):(\d{2})$`)
+
+// parseClock parses the hours and minutes of a time range boundary.
+func parseClock(hh, mm string) (time.Duration, error) {
+	h, _ := strconv.Atoi(hh)
+	m, _ := strconv.Atoi(mm)
+	if h > 24 || m > 59 || h == 24 && m > 0 {
+		return 0, fmt.Errorf("invalid time %s:%s", hh, mm)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// clock formats the given offset from midnight as HH:MM.
+func clock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// parseDays parses a comma-separated list of weekdays or weekday ranges, e.g. "Mon-Fri,Sun".
+func parseDays(s string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		d1, err := parseDay(from)
+		if err != nil {
+			return nil, err
+		}
+		d2 := d1
+		if isRange {
+			if d2, err = parseDay(to); err != nil {
+				return nil, err
+			}
+		}
+		for d := d1; ; d = (d + 1) % 7 {
+			days = append(days, d)
+			if d == d2 {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func parseDay(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if name := d.String(); strings.EqualFold(s, name) || strings.EqualFold(s, name[:3]) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+func inWindows(ws []TimeWindow, t time.Time) bool {
+	for _, w := range ws {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// protectedURL returns the URL that is matched against the protection patterns.
+func protectedURL(s string) (string, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("sql/migrate: parse target url: %w", err)
+	}
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path, Opaque: u.Opaque}).String(), nil
+}
+
+// reDestructiveStmt matches statements that drop data.
+var reDestructiveStmt = regexp.MustCompile(`(?is)^\s*(?:` +
+	`DROP\s+(?:TABLE|SCHEMA|DATABASE)\b` +
+	`|TRUNCATE\b` +
+	`|ALTER\s+TABLE\s.*\bDROP\s+(?:COLUMN\b|[^\s,]+\s*(?:,|;|$))` +
+	`)`)
+
+// ClassifyStmt classifies the given SQL statement lexically. It is used for statements
+// that were not planned by Atlas (e.g., migration files), and currently detects only
+// destructive statements, i.e., dropping a table, schema or column, or truncating a table.
+//
+// The classification is best-effort and should not be relied on as a security boundary.
+// For example, statements that follow a leading comment, DELETE statements without a WHERE
+// clause, or dropping a quoted column name followed by other clauses are not detected.
+func ClassifyStmt(stmt string) ChangeClass {
+	if reDestructiveStmt.MatchString(stmt) {
+		return ClassDestructive
+	}
+	return 0
+}
@@ -0,0 +1,249 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Cron is a parsed cron expression in the standard 5-field format:
+	// minute, hour, day of month, month and day of week.
+	Cron struct {
+		expr                          string
+		minute, hour, dom, month, dow uint64
+		domRestricted, dowRestricted  bool
+	}
+
+	// Window is a recurring maintenance window. The window opens at the
+	// times matched by the cron expression, and stays open for Duration.
+	Window struct {
+		Cron     *Cron
+		Duration time.Duration
+		// Location the cron expression is evaluated in. Defaults to UTC.
+		Location *time.Location
+	}
+)
+
+// ParseCron parses a cron expression in the standard 5-field format. Fields support
+// wildcards, lists, ranges and steps (e.g. "*/15", "1-5", "MON,WED"), and month and
+// weekday names. The descriptors @hourly, @daily, @weekly and @monthly are supported
+// as well. If both the day of month and the day of week are restricted, a time
+// matches if one of them matches.
+//
+//	c, err := schedule.ParseCron("0 2 * * SAT,SUN")
+func ParseCron(expr string) (*Cron, error) {
+	s := expr
+	switch strings.TrimSpace(expr) {
+	case "@hourly":
+		s = "0 * * * *"
+	case "@daily", "@midnight":
+		s = "0 0 * * *"
+	case "@weekly":
+		s = "0 0 * * 0"
+	case "@monthly":
+		s = "0 0 1 * *"
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule: invalid cron expression %q: expect 5 fields, got %d", expr, len(fields))
+	}
+	var (
+		c   = &Cron{expr: expr}
+		err error
+	)
+	for i, f := range []struct {
+		dst      *uint64
+		min, max int
+		names    []string
+	}{
+		{dst: &c.minute, max: 59},
+		{dst: &c.hour, max: 23},
+		{dst: &c.dom, min: 1, max: 31},
+		{dst: &c.month, min: 1, max: 12, names: monthNames},
+		{dst: &c.dow, max: 7, names: dowNames},
+	} {
+		if *f.dst, err = parseField(fields[i], f.min, f.max, f.names); err != nil {
+			return nil, fmt.Errorf("schedule: invalid cron expression %q: %w", expr, err)
+		}
+	}
+	// 7 is an alias for Sunday.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domRestricted, c.dowRestricted = fields[2] != "*", fields[4] != "*"
+	return c, nil
+}
+
+// String returns the cron expression.
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// Matches reports if the given time (truncated to the minute) matches the expression.
+func (c *Cron) Matches(t time.Time) bool {
+	return has(c.minute, t.Minute()) && has(c.hour, t.Hour()) && has(c.month, int(t.Month())) && c.dayMatches(t)
+}
+
+// Next returns the first time after t that matches the expression, in the location
+// of t. The zero time is returned if no such time exists within the next 5 years
+// (e.g. "0 0 30 2 *").
+func (c *Cron) Next(t time.Time) time.Time {
+	var (
+		loc   = t.Location()
+		limit = t.Year() + 5
+	)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+next:
+	for t.Year() <= limit {
+		for !has(c.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			if t.Month() == time.January {
+				continue next
+			}
+		}
+		for !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			if t.Day() == 1 {
+				continue next
+			}
+		}
+		for !has(c.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			if t.Hour() == 0 {
+				continue next
+			}
+		}
+		for !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue next
+			}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom, dow := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.domRestricted && c.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Contains reports if the window is open at the given time.
+func (w *Window) Contains(t time.Time) bool {
+	_, ok := w.Opened(t)
+	return ok
+}
+
+// Opened returns the time the window was opened at, if it is open at the given time.
+func (w *Window) Opened(t time.Time) (time.Time, bool) {
+	// The first opening after t-d is the
+	// last one before t, if the window is open.
+	s := w.Cron.Next(w.in(t).Add(-w.Duration))
+	if s.IsZero() || s.After(t) {
+		return time.Time{}, false
+	}
+	return s, true
+}
+
+// Next returns the next time the window opens after t.
+func (w *Window) Next(t time.Time) time.Time {
+	return w.Cron.Next(w.in(t))
+}
+
+// String implements fmt.Stringer.
+func (w *Window) String() string {
+	s := fmt.Sprintf("%q for %s", w.Cron, w.Duration)
+	if w.Location != nil {
+		s += " (" + w.Location.String() + ")"
+	}
+	return s
+}
+
+func (w *Window) in(t time.Time) time.Time {
+	if w.Location != nil {
+		return t.In(w.Location)
+	}
+	return t.UTC()
+}
+
+var (
+	monthNames = []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	dowNames   = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// parseField parses a single cron field into a bitset.
+func parseField(s string, min, max int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		var (
+			step     = 1
+			from, to int
+		)
+		if r, st, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(st)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", st)
+			}
+			part, step = r, n
+		}
+		switch a, b, isRange := strings.Cut(part, "-"); {
+		case part == "*":
+			from, to = min, max
+		case isRange:
+			var err error
+			if from, err = parseValue(a, min, max, names); err != nil {
+				return 0, err
+			}
+			if to, err = parseValue(b, min, max, names); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			v, err := parseValue(part, min, max, names)
+			if err != nil {
+				return 0, err
+			}
+			from, to = v, v
+			// A single value with a step, e.g. 5/10, means "starting at".
+			if step > 1 {
+				to = max
+			}
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, min, max int, names []string) (int, error) {
+	for i, n := range names {
+		if n != "" && strings.EqualFold(s, n) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, min, max)
+	}
+	return v, nil
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<v) != 0
+}
@@ -0,0 +1,74 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCron_Next(t *testing.T) {
+	// Saturday, April 1st, 2023.
+	now := time.Date(2023, 4, 1, 10, 30, 15, 0, time.UTC)
+	for _, tt := range []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2023, 4, 1, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2023, 4, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * SAT,SUN", time.Date(2023, 4, 2, 2, 0, 0, 0, time.UTC)},
+		{"0 2 * * 1-5", time.Date(2023, 4, 3, 2, 0, 0, 0, time.UTC)},
+		{"30 22 * * 7", time.Date(2023, 4, 2, 22, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 15 * MON", time.Date(2023, 4, 3, 12, 0, 0, 0, time.UTC)},
+		{"5/20 10 * jan,apr *", time.Date(2023, 4, 1, 10, 45, 0, 0, time.UTC)},
+		{"@daily", time.Date(2023, 4, 2, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2023, 4, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		c, err := ParseCron(tt.expr)
+		require.NoError(t, err, tt.expr)
+		require.Equal(t, tt.want, c.Next(now), tt.expr)
+		if !tt.want.IsZero() {
+			require.True(t, c.Matches(tt.want), tt.expr)
+		}
+	}
+
+	for expr, msg := range map[string]string{
+		"* * * *":     `schedule: invalid cron expression "* * * *": expect 5 fields, got 4`,
+		"60 * * * *":  `schedule: invalid cron expression "60 * * * *": value 60 out of range [0, 59]`,
+		"* * 0 * *":   `schedule: invalid cron expression "* * 0 * *": value 0 out of range [1, 31]`,
+		"* * * * FOO": `schedule: invalid cron expression "* * * * FOO": invalid value "FOO"`,
+		"*/0 * * * *": `schedule: invalid cron expression "*/0 * * * *": invalid step "0"`,
+		"5-1 * * * *": `schedule: invalid cron expression "5-1 * * * *": invalid range "5-1"`,
+	} {
+		_, err := ParseCron(expr)
+		require.EqualError(t, err, msg)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	c, err := ParseCron("0 22 * * FRI")
+	require.NoError(t, err)
+	w := &Window{Cron: c, Duration: 6 * time.Hour}
+	require.Equal(t, `"0 22 * * FRI" for 6h0m0s`, w.String())
+
+	opened, ok := w.Opened(time.Date(2023, 4, 8, 1, 0, 0, 0, time.UTC))
+	require.True(t, ok, "window spans midnight")
+	require.Equal(t, time.Date(2023, 4, 7, 22, 0, 0, 0, time.UTC), opened)
+	require.True(t, w.Contains(time.Date(2023, 4, 7, 22, 0, 0, 0, time.UTC)))
+	require.False(t, w.Contains(time.Date(2023, 4, 8, 4, 0, 0, 0, time.UTC)), "closed after 6 hours")
+	require.False(t, w.Contains(time.Date(2023, 4, 7, 21, 59, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2023, 4, 14, 22, 0, 0, 0, time.UTC), w.Next(time.Date(2023, 4, 8, 1, 0, 0, 0, time.UTC)))
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	w.Location = loc
+	require.True(t, w.Contains(time.Date(2023, 4, 8, 3, 0, 0, 0, time.UTC)), "22:00 in New York")
+	require.False(t, w.Contains(time.Date(2023, 4, 7, 23, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,544 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package schedule provides a scheduler that queues approved migration plans and
+// executes them within recurring maintenance windows, described by cron expressions.
+// Queued plans are persisted in a Store, and therefore, they survive restarts of the
+// process that runs the scheduler.
+//
+// Jobs are executed while holding the migration lock of the database (the one taken by
+// "migrate apply"), and only if the database state did not change since they were queued.
+//
+//	c, err := schedule.ParseCron("0 2 * * SAT,SUN")
+//	if err != nil {
+//		return err
+//	}
+//	s, err := schedule.New(client, schedule.NewDirStore("queue"), &schedule.Window{Cron: c, Duration: 4 * time.Hour})
+//	if err != nil {
+//		return err
+//	}
+//	if _, err := s.Enqueue(ctx, plan, "a8m"); err != nil {
+//		return err
+//	}
+//	return s.Run(ctx)
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+)
+
+type (
+	// Status describes the status of a queued job.
+	Status string
+
+	// A Job is a migration plan queued for execution.
+	Job struct {
+		ID         string    `json:"id"`
+		Plan       *Plan     `json:"plan"`
+		ApprovedBy string    `json:"approved_by"`
+		Status     Status    `json:"status"`
+		QueuedAt   time.Time `json:"queued_at"`
+		StartedAt  time.Time `json:"started_at"`
+		FinishedAt time.Time `json:"finished_at"`
+		// Number of statements that were applied.
+		Applied int `json:"applied"`
+		// Number of times the job was claimed for execution.
+		Attempts int `json:"attempts,omitempty"`
+		// State holds the version of the database state the job is expected to run on.
+		// It is recorded when the job is queued, and after a partial failure.
+		State string `json:"state"`
+		// Error of a failed job.
+		Error string `json:"error,omitempty"`
+	}
+
+	// Plan is the persisted form of a migrate.Plan.
+	Plan struct {
+		Name          string  `json:"name,omitempty"`
+		Version       string  `json:"version,omitempty"`
+		Transactional bool    `json:"transactional,omitempty"`
+		Stmts         []*Stmt `json:"stmts"`
+	}
+
+	// Stmt is a planned statement and its arguments.
+	Stmt struct {
+		Cmd     string `json:"cmd"`
+		Args    []any  `json:"args,omitempty"`
+		Comment string `json:"comment,omitempty"`
+	}
+
+	// Store persists the queued jobs.
+	Store interface {
+		// Save creates or updates the given job.
+		Save(context.Context, *Job) error
+		// Jobs returns all jobs in the store, ordered by their queuing time.
+		Jobs(context.Context) ([]*Job, error)
+		// Claim atomically marks the given queued job as running and increments its attempts.
+		// ErrClaimed is returned if the job was claimed by another scheduler, or if it is no
+		// longer queued.
+		Claim(context.Context, *Job) error
+	}
+
+	// Reporter is notified on every status change of a job.
+	Reporter interface {
+		Report(context.Context, *Job) error
+	}
+
+	// ReporterFunc allows using an ordinary function as a Reporter.
+	ReporterFunc func(context.Context, *Job) error
+
+	// Scheduler queues plans and executes them within a maintenance window. Multiple
+	// schedulers can share a store, as long as they execute on the same database.
+	Scheduler struct {
+		client    *sqlclient.Client
+		store     Store
+		window    *Window
+		reporters []Reporter
+		interval  time.Duration
+		now       func() time.Time
+		mu        sync.Mutex
+	}
+
+	// Option allows configuring a Scheduler using functional options.
+	Option func(*Scheduler) error
+)
+
+// List of job statuses.
+const (
+	StatusQueued   Status = "queued"
+	StatusRunning  Status = "running"
+	StatusApplied  Status = "applied"
+	StatusFailed   Status = "failed"
+	StatusCanceled Status = "canceled"
+)
+
+var (
+	// ErrInterrupted is set as the error of jobs that were running
+	// while the process that executed them stopped.
+	ErrInterrupted = errors.New("schedule: execution was interrupted")
+
+	// ErrDrift is set as the error of jobs that were not executed, because the state
+	// of the database changed since they were queued (or since their last attempt).
+	ErrDrift = errors.New("schedule: database state changed since the plan was queued")
+
+	// ErrClaimed is returned by Store.Claim if the job cannot be claimed.
+	ErrClaimed = errors.New("schedule: job was already claimed")
+)
+
+// lockName is the name of the database lock that is held while executing jobs. It is the
+// lock taken by "migrate apply", so scheduled jobs do not run concurrently with applies.
+const lockName = "atlas_migrate_execute"
+
+// Report implements the Reporter interface.
+func (f ReporterFunc) Report(ctx context.Context, j *Job) error {
+	return f(ctx, j)
+}
+
+// WithReporter adds reporters that are notified on every status change of a job.
+func WithReporter(rs ...Reporter) Option {
+	return func(s *Scheduler) error {
+		s.reporters = append(s.reporters, rs...)
+		return nil
+	}
+}
+
+// WithInterval sets the interval Run polls the store for queued jobs within an open window.
+// The default is one minute.
+func WithInterval(d time.Duration) Option {
+	return func(s *Scheduler) error {
+		if d <= 0 {
+			return fmt.Errorf("schedule: invalid interval %s", d)
+		}
+		s.interval = d
+		return nil
+	}
+}
+
+// New returns a Scheduler that executes the queued plans on the given client within the window.
+// The client must have a schema marshaler, that is used to record the state of the database.
+func New(client *sqlclient.Client, store Store, w *Window, opts ...Option) (*Scheduler, error) {
+	switch {
+	case client == nil:
+		return nil, errors.New("schedule: missing database connection")
+	case store == nil:
+		return nil, errors.New("schedule: missing store")
+	case w == nil || w.Cron == nil:
+		return nil, errors.New("schedule: missing window")
+	case w.Duration <= 0:
+		return nil, fmt.Errorf("schedule: invalid window duration %s", w.Duration)
+	}
+	s := &Scheduler{client: client, store: store, window: w, interval: time.Minute, now: time.Now}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// NewPlan returns the persisted form of the given plan.
+func NewPlan(p *migrate.Plan) *Plan {
+	sp := &Plan{Name: p.Name, Version: p.Version, Transactional: p.Transactional, Stmts: make([]*Stmt, len(p.Changes))}
+	for i, c := range p.Changes {
+		sp.Stmts[i] = &Stmt{Cmd: c.Cmd, Args: c.Args, Comment: c.Comment}
+	}
+	return sp
+}
+
+// Enqueue queues the given plan for execution in the next window. A plan must be approved
+// before it is queued, and the approver is recorded on the job. The current state of the
+// database is recorded as well, and therefore, the plan should be computed against it, and
+// it fails with ErrDrift if another change is applied before it. Note that arguments of
+// planned statements are persisted as JSON, and must be serializable.
+func (s *Scheduler) Enqueue(ctx context.Context, p *migrate.Plan, approvedBy string) (*Job, error) {
+	switch {
+	case approvedBy == "":
+		return nil, errors.New("schedule: plan must be approved before it is queued")
+	case len(p.Changes) == 0:
+		return nil, errors.New("schedule: cannot queue an empty plan")
+	}
+	state, err := s.state(ctx, s.client)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	j := &Job{
+		ID:         strconv.FormatInt(now.UnixNano(), 10),
+		Plan:       NewPlan(p),
+		ApprovedBy: approvedBy,
+		Status:     StatusQueued,
+		QueuedAt:   now,
+		State:      state,
+	}
+	if err := s.save(ctx, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Cancel cancels the queued job with the given id. The job is claimed before
+// it is canceled, and therefore, it cannot be executed by another scheduler.
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.job(ctx, id)
+	if err != nil {
+		return err
+	}
+	if j.Status != StatusQueued {
+		return fmt.Errorf("schedule: cannot cancel job %q in status %q", id, j.Status)
+	}
+	if err := s.store.Claim(ctx, j); err != nil {
+		return fmt.Errorf("schedule: claiming job %q: %w", id, err)
+	}
+	j.Status, j.FinishedAt = StatusCanceled, s.now()
+	return s.save(ctx, j)
+}
+
+// Resume queues the failed job with the given id for another attempt. Statements that were
+// applied by previous attempts are skipped, and the job is executed only if the state of the
+// database did not change since its last attempt. Jobs that failed with ErrDrift should be
+// planned and queued again instead.
+func (s *Scheduler) Resume(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.job(ctx, id)
+	if err != nil {
+		return err
+	}
+	if j.Status != StatusFailed {
+		return fmt.Errorf("schedule: cannot resume job %q in status %q", id, j.Status)
+	}
+	j.Status, j.Error, j.FinishedAt = StatusQueued, "", time.Time{}
+	return s.save(ctx, j)
+}
+
+// Jobs returns all jobs in the store.
+func (s *Scheduler) Jobs(ctx context.Context) ([]*Job, error) {
+	return s.store.Jobs(ctx)
+}
+
+// Tick executes the queued jobs, in their queuing order, if the window is open. Jobs are not
+// started after the window closes, but a job that was started is executed to completion. Jobs
+// that were left running by a stopped scheduler are marked as failed with ErrInterrupted.
+//
+// Tick holds the migration lock of the database, if supported by the driver, and returns
+// without executing jobs if the lock is held by another session.
+func (s *Scheduler) Tick(ctx context.Context) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.client.Driver.(schema.Locker); ok {
+		unlock, lerr := l.Lock(ctx, lockName, 0)
+		if errors.Is(lerr, schema.ErrLocked) {
+			return nil
+		}
+		if lerr != nil {
+			return fmt.Errorf("schedule: acquiring database lock: %w", lerr)
+		}
+		defer func() {
+			if uerr := unlock(); uerr != nil && err == nil {
+				err = fmt.Errorf("schedule: releasing database lock: %w", uerr)
+			}
+		}()
+	}
+	jobs, err := s.store.Jobs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, j := range jobs {
+		if j.Status == StatusRunning {
+			j.Status, j.Error, j.FinishedAt = StatusFailed, ErrInterrupted.Error(), s.now()
+			if err := s.save(ctx, j); err != nil {
+				return err
+			}
+		}
+	}
+	for _, j := range jobs {
+		if j.Status != StatusQueued {
+			continue
+		}
+		if !s.window.Contains(s.now()) {
+			return nil
+		}
+		if err := s.exec(ctx, j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run executes the queued jobs within the window, and polls the store for new jobs
+// until the context is canceled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+	for {
+		if err := s.Tick(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// exec claims the job and executes its statements. Failures of statements are recorded
+// on the job, and only errors of the store or the reporters are returned.
+func (s *Scheduler) exec(ctx context.Context, j *Job) error {
+	j.StartedAt = s.now()
+	switch err := s.store.Claim(ctx, j); {
+	case errors.Is(err, ErrClaimed):
+		return nil
+	case err != nil:
+		return fmt.Errorf("schedule: claiming job %q: %w", j.ID, err)
+	}
+	if err := s.report(ctx, j); err != nil {
+		return err
+	}
+	if err := s.apply(ctx, j); err != nil {
+		j.Status, j.Error = StatusFailed, err.Error()
+	} else {
+		j.Status = StatusApplied
+	}
+	j.FinishedAt = s.now()
+	return s.save(ctx, j)
+}
+
+// apply executes the statements of the job that were not applied by its previous attempts.
+// Transactional plans are executed in a transaction, that is rolled back on failure.
+func (s *Scheduler) apply(ctx context.Context, j *Job) error {
+	if !j.Plan.Transactional {
+		return s.applyOn(ctx, s.client, j)
+	}
+	tx, err := s.client.Tx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	applied := j.Applied
+	if err = s.applyOn(ctx, tx.Client, j); err == nil {
+		err = tx.Commit()
+	} else if rerr := tx.Rollback(); rerr != nil {
+		err = fmt.Errorf("%w: rolling back: %v", err, rerr)
+	}
+	if err != nil {
+		j.Applied = applied
+	}
+	return err
+}
+
+// applyOn checks the state of the database and executes the statements on the given client.
+func (s *Scheduler) applyOn(ctx context.Context, c *sqlclient.Client, j *Job) error {
+	switch state, err := s.state(ctx, c); {
+	case err != nil:
+		return err
+	case state != j.State:
+		return ErrDrift
+	}
+	for _, stmt := range j.Plan.Stmts[j.Applied:] {
+		if _, err := c.ExecContext(ctx, stmt.Cmd, stmt.Args...); err != nil {
+			// Statements of non-transactional plans that were applied are not rolled
+			// back. Record the state they left, to allow resuming the job from it.
+			if state, serr := s.state(ctx, c); serr == nil && !j.Plan.Transactional {
+				j.State = state
+			}
+			return fmt.Errorf("executing statement %q: %w", stmt.Cmd, err)
+		}
+		j.Applied++
+	}
+	return nil
+}
+
+// state returns the version of the current state of the database.
+func (s *Scheduler) state(ctx context.Context, c *sqlclient.Client) (string, error) {
+	st, err := c.ReadState(ctx, c.URL.Schemas...)
+	if err != nil {
+		return "", err
+	}
+	return st.Version, nil
+}
+
+// save persists the job and reports its status.
+func (s *Scheduler) save(ctx context.Context, j *Job) error {
+	if err := s.store.Save(ctx, j); err != nil {
+		return fmt.Errorf("schedule: saving job %q: %w", j.ID, err)
+	}
+	return s.report(ctx, j)
+}
+
+// report reports the status of the job.
+func (s *Scheduler) report(ctx context.Context, j *Job) error {
+	var errs []error
+	for _, r := range s.reporters {
+		if err := r.Report(ctx, j); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("schedule: reporting job %q: %w", j.ID, err)
+	}
+	return nil
+}
+
+func (s *Scheduler) job(ctx context.Context, id string) (*Job, error) {
+	jobs, err := s.store.Jobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range jobs {
+		if j.ID == id {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("schedule: job %q was not found", id)
+}
+
+// DirStore is a Store that persists each job as a JSON file in a local directory.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore returns a DirStore for the given directory. The directory
+// is created on the first save, if it does not exist.
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{dir: dir}
+}
+
+// Save implements the Store interface. Files are written atomically.
+func (s *DirStore) Save(_ context.Context, j *Job) error {
+	b, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, ".job-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		return errors.Join(err, f.Close(), os.Remove(f.Name()))
+	}
+	if err := f.Close(); err != nil {
+		return errors.Join(err, os.Remove(f.Name()))
+	}
+	return os.Rename(f.Name(), filepath.Join(s.dir, j.ID+".json"))
+}
+
+// Claim implements the Store interface. A claim file is created exclusively for each attempt
+// of a job, and therefore, an attempt can be claimed only once, even by different processes.
+func (s *DirStore) Claim(ctx context.Context, j *Job) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, fmt.Sprintf("%s.%d.claim", j.ID, j.Attempts+1)), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return ErrClaimed
+	}
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// The job might have been changed since it was read by the caller.
+	b, err := os.ReadFile(filepath.Join(s.dir, j.ID+".json"))
+	if err != nil {
+		return err
+	}
+	current := &Job{}
+	if err := json.Unmarshal(b, current); err != nil {
+		return fmt.Errorf("schedule: reading job file %q: %w", j.ID+".json", err)
+	}
+	if current.Status != StatusQueued || current.Attempts != j.Attempts {
+		return ErrClaimed
+	}
+	j.Status, j.Attempts = StatusRunning, j.Attempts+1
+	return s.Save(ctx, j)
+}
+
+// Jobs implements the Store interface.
+func (s *DirStore) Jobs(context.Context) ([]*Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*Job
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(s.dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		j := &Job{}
+		if err := json.Unmarshal(b, j); err != nil {
+			return nil, fmt.Errorf("schedule: reading job file %q: %w", e.Name(), err)
+		}
+		jobs = append(jobs, j)
+	}
+	sort.SliceStable(jobs, func(i, k int) bool {
+		return jobs[i].QueuedAt.Before(jobs[k].QueuedAt)
+	})
+	return jobs, nil
+}
@@ -0,0 +1,361 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	client, m, _ := openMock(t)
+	c, err := ParseCron("0 2 * * SAT,SUN")
+	require.NoError(t, err)
+	var (
+		ctx      = context.Background()
+		dir      = t.TempDir()
+		store    = NewDirStore(dir)
+		statuses []Status
+		reporter = ReporterFunc(func(_ context.Context, j *Job) error {
+			statuses = append(statuses, j.Status)
+			return nil
+		})
+		now = time.Date(2023, 3, 31, 12, 0, 0, 0, time.UTC) // Friday.
+	)
+	s, err := New(client, store, &Window{Cron: c, Duration: 4 * time.Hour}, WithReporter(reporter))
+	require.NoError(t, err)
+	s.now = func() time.Time { return now }
+
+	_, err = s.Enqueue(ctx, &migrate.Plan{Changes: []*migrate.Change{{Cmd: "CREATE TABLE t1(c int)"}}}, "")
+	require.EqualError(t, err, "schedule: plan must be approved before it is queued")
+	_, err = s.Enqueue(ctx, &migrate.Plan{}, "a8m")
+	require.EqualError(t, err, "schedule: cannot queue an empty plan")
+
+	j1, err := s.Enqueue(ctx, &migrate.Plan{Name: "add_t1", Changes: []*migrate.Change{
+		{Cmd: "CREATE TABLE t1(c int)"},
+		{Cmd: "INSERT INTO t1 VALUES (?)", Args: []any{"v"}},
+	}}, "a8m")
+	require.NoError(t, err)
+	require.NotEmpty(t, j1.State)
+	now = now.Add(time.Second)
+	j2, err := s.Enqueue(ctx, &migrate.Plan{Name: "add_t2", Changes: []*migrate.Change{{Cmd: "CREATE TABLE t2(c int)"}}}, "a8m")
+	require.NoError(t, err)
+	now = now.Add(time.Second)
+	j3, err := s.Enqueue(ctx, &migrate.Plan{Name: "add_t3", Changes: []*migrate.Change{{Cmd: "CREATE TABLE t3(c int)"}}}, "rotemtam")
+	require.NoError(t, err)
+	require.NoError(t, s.Cancel(ctx, j3.ID))
+	require.EqualError(t, s.Cancel(ctx, j3.ID), `schedule: cannot cancel job "`+j3.ID+`" in status "canceled"`)
+	require.EqualError(t, s.Cancel(ctx, "unknown"), `schedule: job "unknown" was not found`)
+
+	// Window is closed.
+	require.NoError(t, s.Tick(ctx))
+	jobs, err := s.Jobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	require.Equal(t, []Status{StatusQueued, StatusQueued, StatusCanceled}, []Status{jobs[0].Status, jobs[1].Status, jobs[2].Status})
+
+	// Window is open. Jobs are executed by their queuing order. The second
+	// job was queued on the state that was changed by the first one.
+	now = time.Date(2023, 4, 1, 3, 0, 0, 0, time.UTC)
+	m.ExpectExec(sqltest.Escape("CREATE TABLE t1(c int)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("INSERT INTO t1 VALUES (?)")).WithArgs("v").WillReturnResult(sqlmock.NewResult(1, 1))
+	statuses = nil
+	require.NoError(t, s.Tick(ctx))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, []Status{StatusRunning, StatusApplied, StatusRunning, StatusFailed}, statuses)
+
+	// Status is persisted.
+	jobs, err = NewDirStore(dir).Jobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	require.Equal(t, j1.ID, jobs[0].ID)
+	require.Equal(t, StatusApplied, jobs[0].Status)
+	require.Equal(t, 2, jobs[0].Applied)
+	require.Equal(t, 1, jobs[0].Attempts)
+	require.Equal(t, "a8m", jobs[0].ApprovedBy)
+	require.Equal(t, now, jobs[0].FinishedAt.UTC())
+	require.Equal(t, j2.ID, jobs[1].ID)
+	require.Equal(t, StatusFailed, jobs[1].Status)
+	require.Zero(t, jobs[1].Applied)
+	require.Equal(t, ErrDrift.Error(), jobs[1].Error)
+}
+
+func TestScheduler_Resume(t *testing.T) {
+	client, m, tables := openMock(t)
+	c, err := ParseCron("0 2 * * *")
+	require.NoError(t, err)
+	ctx := context.Background()
+	s, err := New(client, NewDirStore(t.TempDir()), &Window{Cron: c, Duration: time.Hour})
+	require.NoError(t, err)
+	s.now = func() time.Time { return time.Date(2023, 4, 1, 2, 0, 0, 0, time.UTC) }
+	j, err := s.Enqueue(ctx, &migrate.Plan{Changes: []*migrate.Change{
+		{Cmd: "CREATE TABLE t1(c int)"},
+		{Cmd: "CREATE TABLE t2(c int)"},
+	}}, "a8m")
+	require.NoError(t, err)
+	require.EqualError(t, s.Resume(ctx, j.ID), `schedule: cannot resume job "`+j.ID+`" in status "queued"`)
+
+	// Applied statements of non-transactional plans are not rolled back.
+	m.ExpectExec(sqltest.Escape("CREATE TABLE t1(c int)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("CREATE TABLE t2(c int)")).WillReturnError(errors.New("disk full"))
+	require.NoError(t, s.Tick(ctx))
+	require.NoError(t, m.ExpectationsWereMet())
+	jobs, err := s.Jobs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, jobs[0].Status)
+	require.Equal(t, 1, jobs[0].Applied)
+	require.Equal(t, `executing statement "CREATE TABLE t2(c int)": disk full`, jobs[0].Error)
+	require.NotEqual(t, j.State, jobs[0].State, "state of the partial apply is recorded")
+	require.Equal(t, []string{"t1"}, *tables)
+
+	// Resumed jobs continue from the failed statement.
+	require.NoError(t, s.Resume(ctx, j.ID))
+	m.ExpectExec(sqltest.Escape("CREATE TABLE t2(c int)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, s.Tick(ctx))
+	require.NoError(t, m.ExpectationsWereMet())
+	jobs, err = s.Jobs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, StatusApplied, jobs[0].Status)
+	require.Equal(t, 2, jobs[0].Applied)
+	require.Equal(t, 2, jobs[0].Attempts)
+}
+
+func TestScheduler_Transactional(t *testing.T) {
+	client, m, _ := openMock(t)
+	c, err := ParseCron("0 2 * * *")
+	require.NoError(t, err)
+	ctx := context.Background()
+	s, err := New(client, NewDirStore(t.TempDir()), &Window{Cron: c, Duration: time.Hour})
+	require.NoError(t, err)
+	s.now = func() time.Time { return time.Date(2023, 4, 1, 2, 0, 0, 0, time.UTC) }
+	_, err = s.Enqueue(ctx, &migrate.Plan{Transactional: true, Changes: []*migrate.Change{
+		{Cmd: "CREATE TABLE t1(c int)"},
+		{Cmd: "CREATE TABLE t2(c int)"},
+	}}, "a8m")
+	require.NoError(t, err)
+
+	m.ExpectBegin()
+	m.ExpectExec(sqltest.Escape("CREATE TABLE t1(c int)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("CREATE TABLE t2(c int)")).WillReturnError(errors.New("disk full"))
+	m.ExpectRollback()
+	require.NoError(t, s.Tick(ctx))
+	require.NoError(t, m.ExpectationsWereMet())
+	jobs, err := s.Jobs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, jobs[0].Status)
+	require.Zero(t, jobs[0].Applied, "transaction was rolled back")
+
+	require.NoError(t, s.Resume(ctx, jobs[0].ID))
+	m.ExpectBegin()
+	m.ExpectExec(sqltest.Escape("CREATE TABLE t1(c int)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("CREATE TABLE t2(c int)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectCommit()
+	require.NoError(t, s.Tick(ctx))
+	require.NoError(t, m.ExpectationsWereMet())
+	jobs, err = s.Jobs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, StatusApplied, jobs[0].Status)
+	require.Equal(t, 2, jobs[0].Applied)
+}
+
+func TestScheduler_Drift(t *testing.T) {
+	client, m, tables := openMock(t)
+	c, err := ParseCron("0 2 * * *")
+	require.NoError(t, err)
+	ctx := context.Background()
+	s, err := New(client, NewDirStore(t.TempDir()), &Window{Cron: c, Duration: time.Hour})
+	require.NoError(t, err)
+	s.now = func() time.Time { return time.Date(2023, 4, 1, 2, 0, 0, 0, time.UTC) }
+	_, err = s.Enqueue(ctx, &migrate.Plan{Changes: []*migrate.Change{{Cmd: "CREATE TABLE t1(c int)"}}}, "a8m")
+	require.NoError(t, err)
+
+	// The database was changed after the plan was queued.
+	*tables = []string{"t1"}
+	require.NoError(t, s.Tick(ctx))
+	require.NoError(t, m.ExpectationsWereMet())
+	jobs, err := s.Jobs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, jobs[0].Status)
+	require.Equal(t, ErrDrift.Error(), jobs[0].Error)
+	require.Zero(t, jobs[0].Applied)
+}
+
+func TestScheduler_Locked(t *testing.T) {
+	client, m, _ := openMock(t)
+	c, err := ParseCron("0 2 * * *")
+	require.NoError(t, err)
+	var (
+		ctx   = context.Background()
+		store = NewDirStore(t.TempDir())
+	)
+	require.NoError(t, store.Save(ctx, &Job{ID: "1", Status: StatusRunning, Plan: &Plan{Stmts: []*Stmt{{Cmd: "CREATE TABLE t(c int)"}}}}))
+	s, err := New(client, store, &Window{Cron: c, Duration: time.Hour})
+	require.NoError(t, err)
+	s.now = func() time.Time { return time.Date(2023, 4, 1, 2, 0, 0, 0, time.UTC) }
+
+	// Running jobs are not interrupted while the lock is held by another session.
+	client.Driver.(*mockDriver).locked = true
+	require.NoError(t, s.Tick(ctx))
+	jobs, err := s.Jobs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, StatusRunning, jobs[0].Status)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestScheduler_Interrupted(t *testing.T) {
+	client, m, _ := openMock(t)
+	c, err := ParseCron("0 2 * * *")
+	require.NoError(t, err)
+	var (
+		ctx   = context.Background()
+		store = NewDirStore(t.TempDir())
+	)
+	require.NoError(t, store.Save(ctx, &Job{ID: "1", Status: StatusRunning, Plan: &Plan{Stmts: []*Stmt{{Cmd: "CREATE TABLE t(c int)"}}}}))
+	s, err := New(client, store, &Window{Cron: c, Duration: time.Hour})
+	require.NoError(t, err)
+	s.now = func() time.Time { return time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC) }
+	require.NoError(t, s.Tick(ctx))
+	jobs, err := s.Jobs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, jobs[0].Status)
+	require.Equal(t, ErrInterrupted.Error(), jobs[0].Error)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	_, err = New(client, store, &Window{Cron: c})
+	require.EqualError(t, err, "schedule: invalid window duration 0s")
+	_, err = New(client, nil, &Window{Cron: c, Duration: time.Hour})
+	require.EqualError(t, err, "schedule: missing store")
+	_, err = New(client, store, &Window{Cron: c, Duration: time.Hour}, WithInterval(0))
+	require.EqualError(t, err, "schedule: invalid interval 0s")
+}
+
+func TestDirStore(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		dir   = filepath.Join(t.TempDir(), "queue")
+		store = NewDirStore(dir)
+	)
+	jobs, err := store.Jobs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, jobs, "directory does not exist")
+
+	now := time.Now()
+	require.NoError(t, store.Save(ctx, &Job{ID: "2", QueuedAt: now.Add(time.Second), Plan: &Plan{}}))
+	require.NoError(t, store.Save(ctx, &Job{ID: "1", QueuedAt: now, Plan: &Plan{}}))
+	require.NoError(t, store.Save(ctx, &Job{ID: "1", QueuedAt: now, Plan: &Plan{}, Status: StatusApplied}))
+	jobs, err = store.Jobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	require.Equal(t, "1", jobs[0].ID)
+	require.Equal(t, StatusApplied, jobs[0].Status)
+	require.Equal(t, "2", jobs[1].ID)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "3.json"), []byte("{"), 0644))
+	_, err = store.Jobs(ctx)
+	require.EqualError(t, err, `schedule: reading job file "3.json": unexpected end of JSON input`)
+}
+
+func TestDirStore_Claim(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewDirStore(t.TempDir())
+	)
+	require.NoError(t, store.Save(ctx, &Job{ID: "1", Status: StatusQueued, Plan: &Plan{}}))
+	j1, j2 := &Job{ID: "1", Status: StatusQueued, Plan: &Plan{}}, &Job{ID: "1", Status: StatusQueued, Plan: &Plan{}}
+	require.NoError(t, store.Claim(ctx, j1))
+	require.Equal(t, StatusRunning, j1.Status)
+	require.Equal(t, 1, j1.Attempts)
+	require.ErrorIs(t, store.Claim(ctx, j2), ErrClaimed, "attempt was claimed by another scheduler")
+
+	// A requeued job can be claimed for its next attempt only.
+	j1.Status = StatusQueued
+	require.NoError(t, store.Save(ctx, j1))
+	require.ErrorIs(t, store.Claim(ctx, j2), ErrClaimed, "job was changed since it was read")
+	require.NoError(t, store.Claim(ctx, j1))
+	require.Equal(t, 2, j1.Attempts)
+	jobs, err := store.Jobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1, "claim files are not listed as jobs")
+}
+
+// mockTables holds the tables of the mocked database.
+var mockTables []string
+
+func init() {
+	sqlclient.Register(
+		"schedmock",
+		sqlclient.DriverOpener(func(eq schema.ExecQuerier) (migrate.Driver, error) {
+			return &mockDriver{eq: eq}, nil
+		}),
+		sqlclient.RegisterCodec(schemahcl.MarshalerFunc(func(v any) ([]byte, error) {
+			var names []string
+			for _, t := range v.(*schema.Realm).Schemas[0].Tables {
+				names = append(names, t.Name)
+			}
+			return []byte(strings.Join(names, ",")), nil
+		}), nil),
+	)
+}
+
+// mockDriver is a migrate.Driver that executes statements on a mocked
+// connection, and returns the mocked tables on inspection.
+type mockDriver struct {
+	migrate.Driver
+	eq     schema.ExecQuerier
+	locked bool
+}
+
+// ExecContext executes the statement on the mocked connection, and adds the created tables to
+// the mocked ones. Statements executed in transactions are ignored, as they can be rolled back.
+func (d *mockDriver) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	r, err := d.eq.ExecContext(ctx, query, args...)
+	if _, ok := d.eq.(*sql.DB); ok && err == nil && strings.HasPrefix(query, "CREATE TABLE ") {
+		name, _, _ := strings.Cut(strings.TrimPrefix(query, "CREATE TABLE "), "(")
+		mockTables = append(mockTables, name)
+	}
+	return r, err
+}
+
+func (d *mockDriver) InspectRealm(context.Context, *schema.InspectRealmOption) (*schema.Realm, error) {
+	s := schema.New("main")
+	for _, t := range mockTables {
+		s.AddTables(schema.NewTable(t))
+	}
+	return schema.NewRealm(s), nil
+}
+
+func (d *mockDriver) Lock(context.Context, string, time.Duration) (schema.UnlockFunc, error) {
+	if d.locked {
+		return nil, schema.ErrLocked
+	}
+	return func() error { return nil }, nil
+}
+
+// openMock opens a client on a mocked connection. The returned tables
+// are the state of the database, and can be changed by the caller.
+func openMock(t *testing.T) (*sqlclient.Client, sqlmock.Sqlmock, *[]string) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	client, err := sqlclient.OpenDB("schedmock", db, &url.URL{Scheme: "schedmock"})
+	require.NoError(t, err)
+	mockTables = nil
+	return client, m, &mockTables
+}
@@ -0,0 +1,335 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// LockLevel describes the estimated lock level a change acquires on the
+// objects it modifies, while its statement is executed.
+type LockLevel uint
+
+// List of lock levels.
+const (
+	LockUnknown   LockLevel = iota // Lock level cannot be estimated.
+	LockNone                       // Existing objects are not locked.
+	LockShared                     // Writes are blocked, reads are allowed.
+	LockExclusive                  // Both reads and writes are blocked.
+)
+
+// String implements the fmt.Stringer interface.
+func (l LockLevel) String() string {
+	switch l {
+	case LockNone:
+		return "none"
+	case LockShared:
+		return "shared (blocks writes)"
+	case LockExclusive:
+		return "exclusive (blocks reads and writes)"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultLockLevel returns a conservative and database-agnostic estimation of the
+// lock level acquired by the change, based on its source. Drivers may acquire lighter
+// locks for some changes, e.g. adding a nullable column is a metadata-only change in
+// most databases.
+func DefaultLockLevel(c *Change) LockLevel {
+	if c.Source == nil {
+		return LockUnknown
+	}
+	return lockLevel(c.Source)
+}
+
+func lockLevel(c schema.Change) LockLevel {
+	switch c := c.(type) {
+	case *schema.AddSchema, *schema.AddTable, *schema.AddView, *schema.AddFunc, *schema.AddProc, *schema.AddObject:
+		return LockNone
+	case *schema.AddIndex, *schema.AddForeignKey, *schema.AddCheck:
+		return LockShared
+	case *schema.ModifyTable:
+		l := LockNone
+		for _, c := range c.Changes {
+			if l1 := lockLevel(c); l1 > l {
+				l = l1
+			}
+		}
+		return l
+	default:
+		return LockExclusive
+	}
+}
+
+// ScriptFormatter formats a plan as a single SQL script for manual review. Each statement
+// is preceded by comments that describe the reason of the change (e.g. "column type changed
+// from int to bigint"), mark it if it is destructive, and estimate its lock level.
+//
+//	f := &migrate.ScriptFormatter{FormatType: mysql.FormatType}
+//	if err := f.Write(os.Stdout, plan); err != nil {
+//		return err
+//	}
+type ScriptFormatter struct {
+	// FormatType formats the column types in the change reasons, e.g. mysql.FormatType.
+	// If nil, or if it fails, the raw or the underlying type names are used.
+	FormatType func(schema.Type) (string, error)
+
+	// LockLevel estimates the lock level acquired by a change.
+	// If nil, DefaultLockLevel is used.
+	LockLevel func(*Change) LockLevel
+}
+
+// Format implements the Formatter interface.
+func (f *ScriptFormatter) Format(p *Plan) ([]File, error) {
+	var b bytes.Buffer
+	if err := f.Write(&b, p); err != nil {
+		return nil, err
+	}
+	var n bytes.Buffer
+	if err := DefaultFormatter[0].N.Execute(&n, p); err != nil {
+		return nil, err
+	}
+	return []File{NewLocalFile(n.String(), b.Bytes())}, nil
+}
+
+// Write writes the plan as an SQL script to w.
+func (f *ScriptFormatter) Write(w io.Writer, p *Plan) error {
+	var (
+		b       strings.Builder
+		destroy = make([][]string, len(p.Changes))
+		total   int
+	)
+	for i, c := range p.Changes {
+		if c.Source != nil {
+			destroy[i] = destructive(c.Source)
+		}
+		if len(destroy[i]) > 0 {
+			total++
+		}
+	}
+	if p.Name != "" {
+		fmt.Fprintf(&b, "-- Plan: %s\n", p.Name)
+	}
+	fmt.Fprintf(&b, "-- Changes: %d", len(p.Changes))
+	if total > 0 {
+		fmt.Fprintf(&b, " (%d destructive)", total)
+	}
+	b.WriteString("\n")
+	if !p.Transactional {
+		b.WriteString("-- Note: the plan is not transactional and cannot be rolled back on failure.\n")
+	}
+	lock := f.LockLevel
+	if lock == nil {
+		lock = DefaultLockLevel
+	}
+	for i, c := range p.Changes {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "-- [%d/%d]", i+1, len(p.Changes))
+		if c.Comment != "" {
+			fmt.Fprintf(&b, " %s%s", strings.ToUpper(c.Comment[:1]), c.Comment[1:])
+		}
+		b.WriteString("\n")
+		if c.Source != nil {
+			for _, r := range f.reasons(c.Source) {
+				fmt.Fprintf(&b, "-- Reason: %s\n", r)
+			}
+		}
+		for _, d := range destroy[i] {
+			fmt.Fprintf(&b, "-- DESTRUCTIVE: %s\n", d)
+		}
+		fmt.Fprintf(&b, "-- Lock: %s\n", lock(c))
+		fmt.Fprintf(&b, "%s;\n", c.Cmd)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// reasons describes the schema changes that caused the given change.
+func (f *ScriptFormatter) reasons(c schema.Change) []string {
+	switch c := c.(type) {
+	case *schema.AddSchema:
+		return []string{fmt.Sprintf("schema %q added", c.S.Name)}
+	case *schema.DropSchema:
+		return []string{fmt.Sprintf("schema %q dropped", c.S.Name)}
+	case *schema.ModifySchema:
+		return f.nested(fmt.Sprintf("schema %q: ", c.S.Name), c.Changes)
+	case *schema.AddTable:
+		return []string{fmt.Sprintf("table %q added", c.T.Name)}
+	case *schema.DropTable:
+		return []string{fmt.Sprintf("table %q dropped", c.T.Name)}
+	case *schema.RenameTable:
+		return []string{fmt.Sprintf("table %q renamed to %q", c.From.Name, c.To.Name)}
+	case *schema.ModifyTable:
+		return f.nested(fmt.Sprintf("table %q: ", c.T.Name), c.Changes)
+	case *schema.AddView:
+		return []string{fmt.Sprintf("view %q added", c.V.Name)}
+	case *schema.DropView:
+		return []string{fmt.Sprintf("view %q dropped", c.V.Name)}
+	case *schema.RenameView:
+		return []string{fmt.Sprintf("view %q renamed to %q", c.From.Name, c.To.Name)}
+	case *schema.ModifyView:
+		r := []string{fmt.Sprintf("view %q definition changed", c.To.Name)}
+		if c.From.Def == c.To.Def {
+			r = nil
+		}
+		return append(r, f.nested(fmt.Sprintf("view %q: ", c.To.Name), c.Changes)...)
+	case *schema.AddTrigger:
+		return []string{fmt.Sprintf("trigger %q added to table %q", c.T.Name, c.T.Table.Name)}
+	case *schema.DropTrigger:
+		return []string{fmt.Sprintf("trigger %q dropped from table %q", c.T.Name, c.T.Table.Name)}
+	case *schema.ModifyTrigger:
+		return []string{fmt.Sprintf("trigger %q of table %q changed", c.To.Name, c.To.Table.Name)}
+	case *schema.AddFunc:
+		return []string{fmt.Sprintf("function %q added", c.F.Name)}
+	case *schema.DropFunc:
+		return []string{fmt.Sprintf("function %q dropped", c.F.Name)}
+	case *schema.ModifyFunc:
+		return []string{fmt.Sprintf("function %q changed", c.To.Name)}
+	case *schema.RenameFunc:
+		return []string{fmt.Sprintf("function %q renamed to %q", c.From.Name, c.To.Name)}
+	case *schema.AddProc:
+		return []string{fmt.Sprintf("procedure %q added", c.P.Name)}
+	case *schema.DropProc:
+		return []string{fmt.Sprintf("procedure %q dropped", c.P.Name)}
+	case *schema.ModifyProc:
+		return []string{fmt.Sprintf("procedure %q changed", c.To.Name)}
+	case *schema.RenameProc:
+		return []string{fmt.Sprintf("procedure %q renamed to %q", c.From.Name, c.To.Name)}
+	case *schema.AddColumn:
+		return []string{fmt.Sprintf("column %q added with type %s", c.C.Name, f.typ(c.C.Type))}
+	case *schema.DropColumn:
+		return []string{fmt.Sprintf("column %q dropped", c.C.Name)}
+	case *schema.RenameColumn:
+		return []string{fmt.Sprintf("column %q renamed to %q", c.From.Name, c.To.Name)}
+	case *schema.ModifyColumn:
+		return f.modifyColumn(c)
+	case *schema.AddIndex:
+		if c.I.Unique {
+			return []string{fmt.Sprintf("unique index %q added", c.I.Name)}
+		}
+		return []string{fmt.Sprintf("index %q added", c.I.Name)}
+	case *schema.DropIndex:
+		return []string{fmt.Sprintf("index %q dropped", c.I.Name)}
+	case *schema.ModifyIndex:
+		return []string{fmt.Sprintf("index %q changed (%s)", c.To.Name, kinds(c.Change))}
+	case *schema.RenameIndex:
+		return []string{fmt.Sprintf("index %q renamed to %q", c.From.Name, c.To.Name)}
+	case *schema.AddPrimaryKey:
+		return []string{"primary key added"}
+	case *schema.DropPrimaryKey:
+		return []string{"primary key dropped"}
+	case *schema.ModifyPrimaryKey:
+		return []string{fmt.Sprintf("primary key changed (%s)", kinds(c.Change))}
+	case *schema.AddForeignKey:
+		return []string{fmt.Sprintf("foreign key %q added", c.F.Symbol)}
+	case *schema.DropForeignKey:
+		return []string{fmt.Sprintf("foreign key %q dropped", c.F.Symbol)}
+	case *schema.ModifyForeignKey:
+		return []string{fmt.Sprintf("foreign key %q changed (%s)", c.To.Symbol, kinds(c.Change))}
+	case *schema.AddCheck:
+		return []string{fmt.Sprintf("check %q added", c.C.Name)}
+	case *schema.DropCheck:
+		return []string{fmt.Sprintf("check %q dropped", c.C.Name)}
+	case *schema.ModifyCheck:
+		return []string{fmt.Sprintf("check %q changed (%s)", c.To.Name, kinds(c.Change))}
+	case *schema.AddAttr, *schema.DropAttr, *schema.ModifyAttr:
+		return []string{"attributes changed"}
+	default:
+		return []string{fmt.Sprintf("%s change", strings.TrimPrefix(reflect.TypeOf(c).String(), "*schema."))}
+	}
+}
+
+// nested describes the given changes with a common prefix.
+func (f *ScriptFormatter) nested(prefix string, changes []schema.Change) []string {
+	var rs []string
+	for _, c := range changes {
+		for _, r := range f.reasons(c) {
+			rs = append(rs, prefix+r)
+		}
+	}
+	return rs
+}
+
+// modifyColumn describes the changes of a column.
+func (f *ScriptFormatter) modifyColumn(c *schema.ModifyColumn) []string {
+	var rs []string
+	if c.Change.Is(schema.ChangeType) {
+		rs = append(rs, fmt.Sprintf("column %q type changed from %s to %s", c.To.Name, f.typ(c.From.Type), f.typ(c.To.Type)))
+	}
+	if c.Change.Is(schema.ChangeNull) {
+		from, to := "NOT NULL", "NULL"
+		if !c.To.Type.Null {
+			from, to = to, from
+		}
+		rs = append(rs, fmt.Sprintf("column %q changed from %s to %s", c.To.Name, from, to))
+	}
+	if c.Change.Is(schema.ChangeDefault) {
+		rs = append(rs, fmt.Sprintf("column %q default value changed", c.To.Name))
+	}
+	if k := c.Change &^ (schema.ChangeType | schema.ChangeNull | schema.ChangeDefault); k != schema.NoChange {
+		rs = append(rs, fmt.Sprintf("column %q changed (%s)", c.To.Name, kinds(k)))
+	}
+	return rs
+}
+
+// typ returns the string representation of a column type.
+func (f *ScriptFormatter) typ(ct *schema.ColumnType) string {
+	if ct == nil || ct.Type == nil {
+		return "unknown"
+	}
+	if f.FormatType != nil {
+		if s, err := f.FormatType(ct.Type); err == nil {
+			return s
+		}
+	}
+	if ct.Raw != "" {
+		return ct.Raw
+	}
+	rv := reflect.Indirect(reflect.ValueOf(ct.Type))
+	if rv.Kind() == reflect.Struct {
+		if t := rv.FieldByName("T"); t.IsValid() && t.Kind() == reflect.String && t.String() != "" {
+			return t.String()
+		}
+	}
+	return reflect.TypeOf(ct.Type).String()
+}
+
+// kinds returns a human-readable representation of the change kind, e.g. "type, null".
+func kinds(k schema.ChangeKind) string {
+	var names []string
+	for b := schema.ChangeKind(1); b != 0 && b <= k; b <<= 1 {
+		if k.Is(b) {
+			names = append(names, strings.ToLower(strings.TrimPrefix(b.String(), "Change")))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// destructive describes the data loss caused by the change, if any.
+func destructive(c schema.Change) []string {
+	switch c := c.(type) {
+	case *schema.DropSchema:
+		return []string{fmt.Sprintf("drops schema %q and all its data", c.S.Name)}
+	case *schema.DropTable:
+		return []string{fmt.Sprintf("drops table %q and all its data", c.T.Name)}
+	case *schema.ModifyTable:
+		var ds []string
+		for _, c1 := range c.Changes {
+			if d, ok := c1.(*schema.DropColumn); ok {
+				ds = append(ds, fmt.Sprintf("drops column %q of table %q", d.C.Name, c.T.Name))
+			}
+		}
+		return ds
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptFormatter(t *testing.T) {
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		id2   = schema.NewIntColumn("id", "bigint")
+		name  = schema.NewNullStringColumn("name", "varchar", schema.StringSize(255))
+		users = schema.NewTable("users").AddColumns(id, name)
+		posts = schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "int"))
+		plan  = &migrate.Plan{
+			Name:          "script",
+			Transactional: true,
+			Changes: []*migrate.Change{
+				{
+					Cmd:     "ALTER TABLE `users` MODIFY COLUMN `id` bigint NOT NULL, DROP COLUMN `name`",
+					Comment: `modify "users" table`,
+					Source: &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{From: id, To: id2, Change: schema.ChangeType},
+							&schema.DropColumn{C: name},
+						},
+					},
+				},
+				{
+					Cmd:     "CREATE INDEX `idx` ON `users` (`id`)",
+					Comment: `create index "idx" to table: "users"`,
+					Source: &schema.ModifyTable{
+						T:       users,
+						Changes: []schema.Change{&schema.AddIndex{I: schema.NewIndex("idx").AddColumns(id2)}},
+					},
+				},
+				{
+					Cmd:     "DROP TABLE `posts`",
+					Comment: `drop "posts" table`,
+					Source:  &schema.DropTable{T: posts},
+				},
+				{
+					Cmd: "SET foreign_key_checks = 0",
+				},
+			},
+		}
+	)
+	var b strings.Builder
+	f := &migrate.ScriptFormatter{}
+	require.NoError(t, f.Write(&b, plan))
+	require.Equal(t, `-- Plan: script
+-- Changes: 4 (2 destructive)
+
+-- [1/4] Modify "users" table
+-- Reason: table "users": column "id" type changed from int to bigint
+-- Reason: table "users": column "name" dropped
+-- DESTRUCTIVE: drops column "name" of table "users"
+-- Lock: exclusive (blocks reads and writes)
+ALTER TABLE `+"`users` MODIFY COLUMN `id` bigint NOT NULL, DROP COLUMN `name`"+`;
+
+-- [2/4] Create index "idx" to table: "users"
+-- Reason: table "users": index "idx" added
+-- Lock: shared (blocks writes)
+CREATE INDEX `+"`idx` ON `users` (`id`)"+`;
+
+-- [3/4] Drop "posts" table
+-- Reason: table "posts" dropped
+-- DESTRUCTIVE: drops table "posts" and all its data
+-- Lock: exclusive (blocks reads and writes)
+DROP TABLE `+"`posts`"+`;
+
+-- [4/4]
+-- Lock: unknown
+SET foreign_key_checks = 0;
+`, b.String())
+
+	// Custom type formatting and lock estimation.
+	f = &migrate.ScriptFormatter{
+		FormatType: func(t schema.Type) (string, error) {
+			return strings.ToUpper(t.(*schema.IntegerType).T), nil
+		},
+		LockLevel: func(*migrate.Change) migrate.LockLevel {
+			return migrate.LockNone
+		},
+	}
+	plan.Transactional = false
+	plan.Changes = plan.Changes[:1]
+	files, err := f.Format(plan)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.True(t, strings.HasSuffix(files[0].Name(), "_script.sql"))
+	require.Contains(t, string(files[0].Bytes()), "-- Note: the plan is not transactional and cannot be rolled back on failure.\n")
+	require.Contains(t, string(files[0].Bytes()), `-- Reason: table "users": column "id" type changed from INT to BIGINT`)
+	require.Contains(t, string(files[0].Bytes()), "-- Lock: none\n")
+}
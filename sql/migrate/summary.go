@@ -0,0 +1,267 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Severity describes how risky a set of changes is to apply. Severities
+// are ordered, and the zero severity describes an empty set of changes.
+type Severity uint
+
+// List of severities, ordered from the least to the most risky.
+const (
+	SeverityNone Severity = iota
+	// SeverityLow describes changes that do not affect existing data or
+	// applications, e.g. adding a table or a nullable column.
+	SeverityLow
+	// SeverityMedium describes changes that might fail depending
+	// on the existing data. See ClassDataDependent for more info.
+	SeverityMedium
+	// SeverityHigh describes changes that might break applications that
+	// use the previous schema. See ClassBackwardIncompatible for more info.
+	SeverityHigh
+	// SeverityCritical describes changes that drop data.
+	// See ClassDestructive for more info.
+	SeverityCritical
+)
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	switch s {
+	case SeverityNone:
+		return "none"
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("Severity(%d)", uint(s))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Severity returns the severity of changes of the given classes.
+// Unclassified changes, such as modifying comments, are of low severity.
+func (c ChangeClass) Severity() Severity {
+	switch {
+	case c.Has(ClassDestructive):
+		return SeverityCritical
+	case c.Has(ClassBackwardIncompatible):
+		return SeverityHigh
+	case c.Has(ClassDataDependent):
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
+
+type (
+	// Summary summarizes a set of schema changes, for use in reports and gating logic,
+	// without planning them. For example, failing CI if a change is destructive:
+	//
+	//	s := migrate.Summarize(changes)
+	//	if s.Severity >= migrate.SeverityCritical {
+	//		return fmt.Errorf("destructive changes: %s", s)
+	//	}
+	Summary struct {
+		Schemas     Counts
+		Tables      Counts
+		Columns     Counts
+		Indexes     Counts // Including primary keys.
+		ForeignKeys Counts
+		Checks      Counts
+		Views       Counts
+		Funcs       Counts
+		Procs       Counts
+		Objects     Counts // Driver-specific objects, e.g. enum types.
+
+		// Number of changes by their classification. Changes
+		// of tables are counted by the changes they contain.
+		Destructive          int `json:",omitempty"`
+		BackwardIncompatible int `json:",omitempty"`
+		DataDependent        int `json:",omitempty"`
+
+		// The union of the classes of all changes, and the highest severity.
+		Class    ChangeClass
+		Severity Severity
+	}
+
+	// Counts holds the number of changes of a specific object type.
+	Counts struct {
+		Added    int `json:",omitempty"`
+		Dropped  int `json:",omitempty"`
+		Modified int `json:",omitempty"`
+		Renamed  int `json:",omitempty"`
+	}
+)
+
+// Summarize returns the Summary of the given changes.
+func Summarize(changes []schema.Change) *Summary {
+	s := &Summary{}
+	for _, c := range changes {
+		s.add(c)
+	}
+	if len(changes) > 0 {
+		s.Severity = s.Class.Severity()
+	}
+	return s
+}
+
+// Total returns the total number of changes.
+func (c Counts) Total() int {
+	return c.Added + c.Dropped + c.Modified + c.Renamed
+}
+
+// String returns a short, human-readable, description of the summary.
+// For example, "1 table added, 2 columns dropped (critical)".
+func (s *Summary) String() string {
+	var parts []string
+	for _, c := range []struct {
+		counts   Counts
+		singular string
+		plural   string
+	}{
+		{s.Schemas, "schema", "schemas"},
+		{s.Tables, "table", "tables"},
+		{s.Columns, "column", "columns"},
+		{s.Indexes, "index", "indexes"},
+		{s.ForeignKeys, "foreign key", "foreign keys"},
+		{s.Checks, "check", "checks"},
+		{s.Views, "view", "views"},
+		{s.Funcs, "function", "functions"},
+		{s.Procs, "procedure", "procedures"},
+		{s.Objects, "object", "objects"},
+	} {
+		for _, n := range []struct {
+			n      int
+			action string
+		}{
+			{c.counts.Added, "added"},
+			{c.counts.Dropped, "dropped"},
+			{c.counts.Modified, "modified"},
+			{c.counts.Renamed, "renamed"},
+		} {
+			switch {
+			case n.n == 1:
+				parts = append(parts, fmt.Sprintf("1 %s %s", c.singular, n.action))
+			case n.n > 1:
+				parts = append(parts, fmt.Sprintf("%d %s %s", n.n, c.plural, n.action))
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(parts, ", "), s.Severity)
+}
+
+// add adds the given change to the summary.
+func (s *Summary) add(c schema.Change) {
+	switch c := c.(type) {
+	case *schema.ModifyTable:
+		s.Tables.Modified++
+		for _, c := range c.Changes {
+			s.add(c)
+		}
+		// Classes are counted by the table changes.
+		return
+	case *schema.AddSchema:
+		s.Schemas.Added++
+	case *schema.DropSchema:
+		s.Schemas.Dropped++
+	case *schema.ModifySchema:
+		s.Schemas.Modified++
+	case *schema.AddTable:
+		s.Tables.Added++
+	case *schema.DropTable:
+		s.Tables.Dropped++
+	case *schema.RenameTable:
+		s.Tables.Renamed++
+	case *schema.AddColumn:
+		s.Columns.Added++
+	case *schema.DropColumn:
+		s.Columns.Dropped++
+	case *schema.ModifyColumn:
+		s.Columns.Modified++
+	case *schema.RenameColumn:
+		s.Columns.Renamed++
+	case *schema.AddIndex, *schema.AddPrimaryKey:
+		s.Indexes.Added++
+	case *schema.DropIndex, *schema.DropPrimaryKey:
+		s.Indexes.Dropped++
+	case *schema.ModifyIndex, *schema.ModifyPrimaryKey:
+		s.Indexes.Modified++
+	case *schema.RenameIndex:
+		s.Indexes.Renamed++
+	case *schema.AddForeignKey:
+		s.ForeignKeys.Added++
+	case *schema.DropForeignKey:
+		s.ForeignKeys.Dropped++
+	case *schema.ModifyForeignKey:
+		s.ForeignKeys.Modified++
+	case *schema.AddCheck:
+		s.Checks.Added++
+	case *schema.DropCheck:
+		s.Checks.Dropped++
+	case *schema.ModifyCheck:
+		s.Checks.Modified++
+	case *schema.AddView:
+		s.Views.Added++
+	case *schema.DropView:
+		s.Views.Dropped++
+	case *schema.ModifyView:
+		s.Views.Modified++
+	case *schema.RenameView:
+		s.Views.Renamed++
+	case *schema.AddFunc:
+		s.Funcs.Added++
+	case *schema.DropFunc:
+		s.Funcs.Dropped++
+	case *schema.ModifyFunc:
+		s.Funcs.Modified++
+	case *schema.RenameFunc:
+		s.Funcs.Renamed++
+	case *schema.AddProc:
+		s.Procs.Added++
+	case *schema.DropProc:
+		s.Procs.Dropped++
+	case *schema.ModifyProc:
+		s.Procs.Modified++
+	case *schema.RenameProc:
+		s.Procs.Renamed++
+	case *schema.AddObject:
+		s.Objects.Added++
+	case *schema.DropObject:
+		s.Objects.Dropped++
+	case *schema.ModifyObject:
+		s.Objects.Modified++
+	case *schema.RenameObject:
+		s.Objects.Renamed++
+	}
+	cl := Classify(c)
+	s.Class |= cl
+	if cl.Has(ClassDestructive) {
+		s.Destructive++
+	}
+	if cl.Has(ClassBackwardIncompatible) {
+		s.BackwardIncompatible++
+	}
+	if cl.Has(ClassDataDependent) {
+		s.DataDependent++
+	}
+}
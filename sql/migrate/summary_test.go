@@ -0,0 +1,82 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	s := migrate.Summarize(nil)
+	require.Equal(t, migrate.SeverityNone, s.Severity)
+	require.Equal(t, "no changes", s.String())
+
+	var (
+		c1 = schema.NewIntColumn("c1", "int")
+		c2 = schema.NewNullIntColumn("c2", "int")
+		t1 = schema.NewTable("t1").AddColumns(c1, c2)
+		t2 = schema.NewTable("t2").AddColumns(c1)
+	)
+	s = migrate.Summarize([]schema.Change{
+		&schema.AddTable{T: t2},
+		&schema.AddTable{T: t2},
+	})
+	require.Equal(t, 2, s.Tables.Added)
+	require.Equal(t, migrate.SeverityLow, s.Severity)
+	require.Equal(t, "2 tables added (low)", s.String())
+
+	s = migrate.Summarize([]schema.Change{
+		&schema.AddTable{T: t2},
+		&schema.ModifyTable{T: t1, Changes: []schema.Change{
+			&schema.AddColumn{C: c2},
+			&schema.ModifyColumn{From: c2, To: c1, Change: schema.ChangeNull},
+			&schema.AddIndex{I: schema.NewUniqueIndex("i").AddColumns(c1)},
+		}},
+	})
+	require.Equal(t, migrate.Counts{Added: 1, Modified: 1}, s.Tables)
+	require.Equal(t, migrate.Counts{Added: 1, Modified: 1}, s.Columns)
+	require.Equal(t, 1, s.Indexes.Total())
+	require.Equal(t, 2, s.DataDependent)
+	require.Zero(t, s.Destructive)
+	require.Equal(t, migrate.SeverityMedium, s.Severity)
+	require.Equal(t, "1 table added, 1 table modified, 1 column added, 1 column modified, 1 index added (medium)", s.String())
+
+	s = migrate.Summarize([]schema.Change{
+		&schema.RenameTable{From: t1, To: t1},
+		&schema.ModifyTable{T: t2, Changes: []schema.Change{
+			&schema.DropColumn{C: c1},
+			&schema.DropColumn{C: c2},
+			&schema.DropForeignKey{F: &schema.ForeignKey{Symbol: "fk"}},
+		}},
+		&schema.DropView{V: schema.NewView("v1", "SELECT 1")},
+	})
+	require.Equal(t, 2, s.Columns.Dropped)
+	require.Equal(t, 1, s.ForeignKeys.Dropped)
+	require.Equal(t, 2, s.Destructive)
+	require.Equal(t, 4, s.BackwardIncompatible)
+	require.True(t, s.Class.Has(migrate.ClassDestructive))
+	require.Equal(t, migrate.SeverityCritical, s.Severity)
+	require.Equal(t, "1 table modified, 1 table renamed, 2 columns dropped, 1 foreign key dropped, 1 view dropped (critical)", s.String())
+
+	buf, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `"Columns":{"Dropped":2}`)
+	require.Contains(t, string(buf), `"Severity":"critical"`)
+}
+
+func TestChangeClass_Severity(t *testing.T) {
+	require.Equal(t, migrate.SeverityLow, migrate.ChangeClass(0).Severity())
+	require.Equal(t, migrate.SeverityLow, migrate.ClassAdditive.Severity())
+	require.Equal(t, migrate.SeverityMedium, (migrate.ClassAdditive | migrate.ClassDataDependent).Severity())
+	require.Equal(t, migrate.SeverityHigh, migrate.ClassBackwardIncompatible.Severity())
+	require.Equal(t, migrate.SeverityCritical, (migrate.ClassDestructive | migrate.ClassBackwardIncompatible).Severity())
+	require.True(t, migrate.SeverityCritical > migrate.SeverityHigh)
+}
@@ -0,0 +1,216 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// Tenant is a single target of a multi-tenant execution, e.g. a database or a schema.
+	// Each tenant tracks its revisions separately using its own RevisionReadWriter.
+	Tenant struct {
+		Name   string             // Name of the tenant.
+		Driver Driver             // Driver connected to the tenant.
+		RRW    RevisionReadWriter // RevisionReadWriter of the tenant.
+		Close  func() error       // Optional function to release the tenant resources.
+	}
+
+	// TenantOpener opens the tenant with the given name. For example, an opener
+	// can connect to a database URL, or switch the connection to a schema using
+	// sqlclient.OpenSchema or by setting the PostgreSQL search_path.
+	TenantOpener func(ctx context.Context, name string) (*Tenant, error)
+
+	// TenantLister lists the names of the tenants to execute on. Listers are
+	// called on each execution, allowing new tenants to be picked up.
+	TenantLister func(ctx context.Context) ([]string, error)
+
+	// TenantExecutor executes a migration directory against multiple tenants.
+	TenantExecutor struct {
+		dir         Dir              // The Dir with migration files to use.
+		open        TenantOpener     // The TenantOpener to connect to tenants.
+		concurrency int              // Maximum number of tenants to execute concurrently.
+		opts        []ExecutorOption // Options passed to the Executor of each tenant.
+	}
+
+	// TenantExecutorOption allows configuring a TenantExecutor using functional arguments.
+	TenantExecutorOption func(*TenantExecutor) error
+
+	// TenantError is returned when the execution on a tenant failed.
+	TenantError struct {
+		Tenant string // Name of the tenant.
+		Err    error  // Error of the execution.
+	}
+
+	// TenantErrors aggregates the errors of the tenants that failed.
+	TenantErrors []*TenantError
+)
+
+// NewTenantExecutor creates a new TenantExecutor with default values.
+func NewTenantExecutor(dir Dir, open TenantOpener, opts ...TenantExecutorOption) (*TenantExecutor, error) {
+	if dir == nil {
+		return nil, errors.New("sql/migrate: execute: no dir given")
+	}
+	if open == nil {
+		return nil, errors.New("sql/migrate: execute: no tenant opener given")
+	}
+	ex := &TenantExecutor{dir: dir, open: open, concurrency: 1}
+	for _, opt := range opts {
+		if err := opt(ex); err != nil {
+			return nil, err
+		}
+	}
+	return ex, nil
+}
+
+// WithConcurrency sets the maximum number of tenants that are executed concurrently.
+func WithConcurrency(n int) TenantExecutorOption {
+	return func(ex *TenantExecutor) error {
+		if n < 1 {
+			return fmt.Errorf("sql/migrate: execute: invalid concurrency %d", n)
+		}
+		ex.concurrency = n
+		return nil
+	}
+}
+
+// WithExecutorOptions sets the options of the Executor created for each tenant.
+// Note that a Logger shared between tenants must be safe for concurrent use.
+func WithExecutorOptions(opts ...ExecutorOption) TenantExecutorOption {
+	return func(ex *TenantExecutor) error {
+		ex.opts = append(ex.opts, opts...)
+		return nil
+	}
+}
+
+// ExecuteN executes n pending migration files on each of the given tenants. If n<=0 all
+// pending migration files are executed. Tenants without pending files are skipped, and a
+// failure on one tenant does not stop the execution on the others. In case of failures,
+// a TenantErrors holding an error for each failed tenant is returned.
+func (e *TenantExecutor) ExecuteN(ctx context.Context, tenants []string, n int) error {
+	var (
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, e.concurrency)
+		errs = make([]error, len(tenants))
+	)
+	for i, t := range tenants {
+		select {
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int, t string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = e.execute(ctx, t, n)
+		}(i, t)
+	}
+	wg.Wait()
+	var terrs TenantErrors
+	for i, err := range errs {
+		if err != nil {
+			terrs = append(terrs, &TenantError{Tenant: tenants[i], Err: err})
+		}
+	}
+	if len(terrs) > 0 {
+		return terrs
+	}
+	return nil
+}
+
+// ExecuteListedN is like ExecuteN, but executes on the tenants returned by the given TenantLister.
+func (e *TenantExecutor) ExecuteListedN(ctx context.Context, l TenantLister, n int) error {
+	tenants, err := l(ctx)
+	if err != nil {
+		return fmt.Errorf("sql/migrate: execute: list tenants: %w", err)
+	}
+	return e.ExecuteN(ctx, tenants, n)
+}
+
+// TenantsQuery returns a TenantLister that lists the tenants using the given query. The query
+// is expected to return a single column holding the tenant names. For example:
+//
+//	TenantsQuery(db, "SELECT schema_name FROM tenants WHERE active")
+func TenantsQuery(db schema.ExecQuerier, query string, args ...any) TenantLister {
+	return func(ctx context.Context) ([]string, error) {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+		}
+		return names, rows.Err()
+	}
+}
+
+// execute executes n pending migration files on the given tenant.
+func (e *TenantExecutor) execute(ctx context.Context, name string, n int) (err error) {
+	t, err := e.open(ctx, name)
+	if err != nil {
+		return fmt.Errorf("open tenant: %w", err)
+	}
+	if t.Close != nil {
+		defer func() {
+			if err2 := t.Close(); err2 != nil {
+				err = wrap(err2, err)
+			}
+		}()
+	}
+	ex, err := NewExecutor(t.Driver, e.dir, t.RRW, e.opts...)
+	if err != nil {
+		return err
+	}
+	if err := ex.ExecuteN(ctx, n); err != nil && !errors.Is(err, ErrNoPendingFiles) {
+		return err
+	}
+	return nil
+}
+
+// Error implements the error interface.
+func (e *TenantError) Error() string {
+	return fmt.Sprintf("tenant %q: %v", e.Tenant, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TenantError) Unwrap() error {
+	return e.Err
+}
+
+// Error implements the error interface.
+func (e TenantErrors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sql/migrate: execute: %d tenant(s) failed", len(e))
+	for _, err := range e {
+		b.WriteString("\n\t")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the failed tenants.
+func (e TenantErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i := range e {
+		errs[i] = e[i]
+	}
+	return errs
+}
@@ -0,0 +1,123 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantExecutor_ExecuteN(t *testing.T) {
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	_, err = migrate.NewTenantExecutor(nil, nil)
+	require.EqualError(t, err, "sql/migrate: execute: no dir given")
+	_, err = migrate.NewTenantExecutor(dir, nil)
+	require.EqualError(t, err, "sql/migrate: execute: no tenant opener given")
+
+	var (
+		mu     sync.Mutex
+		drvs   = make(map[string]*mockDriver)
+		rrws   = make(map[string]*mockRevisionReadWriter)
+		closed = make(map[string]bool)
+	)
+	open := func(_ context.Context, name string) (*migrate.Tenant, error) {
+		if name == "unknown" {
+			return nil, errors.New("tenant does not exist")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if drvs[name] == nil {
+			drvs[name], rrws[name] = &mockDriver{}, &mockRevisionReadWriter{}
+		}
+		if name == "broken" {
+			drvs[name].failOn(1, errors.New("this is an error"))
+		}
+		return &migrate.Tenant{
+			Name:   name,
+			Driver: drvs[name],
+			RRW:    rrws[name],
+			Close: func() error {
+				mu.Lock()
+				defer mu.Unlock()
+				closed[name] = true
+				return nil
+			},
+		}, nil
+	}
+	_, err = migrate.NewTenantExecutor(dir, open, migrate.WithConcurrency(0))
+	require.EqualError(t, err, "sql/migrate: execute: invalid concurrency 0")
+	ex, err := migrate.NewTenantExecutor(dir, open, migrate.WithConcurrency(2), migrate.WithExecutorOptions(migrate.WithOperatorVersion("op")))
+	require.NoError(t, err)
+
+	require.NoError(t, ex.ExecuteN(context.Background(), []string{"t1", "t2"}, 1))
+	for _, name := range []string{"t1", "t2"} {
+		require.Equal(t, []string{"CREATE TABLE t_sub(c int);", "ALTER TABLE t_sub ADD c1 int;"}, drvs[name].executed)
+		require.Len(t, *rrws[name], 1)
+		require.Equal(t, "op", (*rrws[name])[0].OperatorVersion)
+		require.True(t, closed[name])
+	}
+
+	// Each tenant tracks its own revisions, and failures are aggregated.
+	err = ex.ExecuteN(context.Background(), []string{"t1", "broken", "t3", "unknown"}, 1)
+	var terrs migrate.TenantErrors
+	require.ErrorAs(t, err, &terrs)
+	require.Len(t, terrs, 2)
+	require.Equal(t, "broken", terrs[0].Tenant)
+	require.ErrorContains(t, terrs[0], "this is an error")
+	require.Equal(t, "unknown", terrs[1].Tenant)
+	require.EqualError(t, terrs[1].Err, "open tenant: tenant does not exist")
+	require.EqualError(t, err, `sql/migrate: execute: 2 tenant(s) failed
+	tenant "broken": sql/migrate: execute: executing statement "CREATE TABLE t_sub(c int);" from version "1.a": this is an error
+	tenant "unknown": open tenant: tenant does not exist`)
+	require.Len(t, *rrws["t1"], 2)
+	require.Len(t, *rrws["t3"], 1)
+	require.Equal(t, []string{"ALTER TABLE t_sub ADD c2 int;"}, drvs["t1"].executed[2:])
+
+	// Tenants without pending files are skipped.
+	require.NoError(t, ex.ExecuteN(context.Background(), []string{"t1", "t2"}, 0))
+	require.NoError(t, ex.ExecuteN(context.Background(), []string{"t1", "t2"}, 0))
+}
+
+func TestTenantExecutor_ExecuteListedN(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	query := "SELECT schema_name FROM tenants"
+	m.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"schema_name"}).AddRow("t1"))
+	m.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"schema_name"}).AddRow("t1").AddRow("t2"))
+	m.ExpectQuery(query).WillReturnError(errors.New("connection lost"))
+
+	dir, err := migrate.NewLocalDir(filepath.Join("testdata", "migrate", "sub"))
+	require.NoError(t, err)
+	var opened []string
+	ex, err := migrate.NewTenantExecutor(dir, func(_ context.Context, name string) (*migrate.Tenant, error) {
+		opened = append(opened, name)
+		return &migrate.Tenant{Name: name, Driver: &mockDriver{}, RRW: &mockRevisionReadWriter{}}, nil
+	})
+	require.NoError(t, err)
+	l := migrate.TenantsQuery(db, query)
+	require.NoError(t, ex.ExecuteListedN(context.Background(), l, 1))
+	require.Equal(t, []string{"t1"}, opened)
+	// Newly created tenants are picked up.
+	require.NoError(t, ex.ExecuteListedN(context.Background(), l, 1))
+	require.Equal(t, []string{"t1", "t1", "t2"}, opened)
+	require.EqualError(t, ex.ExecuteListedN(context.Background(), l, 1), "sql/migrate: execute: list tenants: connection lost")
+
+	// Tenants can be listed by a callback.
+	opened = nil
+	require.NoError(t, ex.ExecuteListedN(context.Background(), func(context.Context) ([]string, error) {
+		return []string{"t3"}, nil
+	}, 1))
+	require.Equal(t, []string{"t3"}, opened)
+	require.NoError(t, m.ExpectationsWereMet())
+}
@@ -0,0 +1,156 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// SizeThreshold defines the size of tables from which destructive changes (e.g., dropping
+	// a column) and changes that rewrite tables (e.g., changing the type of column) are risky.
+	// The size of tables is read from the statistics collected on inspection. See the Stats
+	// option of schema.InspectOptions and schema.InspectRealmOption for more info.
+	SizeThreshold struct {
+		// Rows is the maximum (estimated) number of rows of a table. Zero means no limit.
+		Rows int64
+		// Size is the maximum data size of a table in bytes. Zero means no limit.
+		Size int64
+	}
+
+	// ThresholdError is returned by CheckThreshold in case a plan
+	// affects tables that exceed the configured SizeThreshold.
+	ThresholdError struct {
+		Threshold  SizeThreshold
+		Violations []*ThresholdViolation
+	}
+
+	// ThresholdViolation describes a planned change that affects a table
+	// that exceeds the threshold.
+	ThresholdViolation struct {
+		Change *Change
+		Table  *schema.Table
+		// Stats of the table, or nil if no statistics
+		// were collected for it.
+		Stats *schema.TableStats
+	}
+)
+
+// Exceeds reports if a table with the given statistics exceeds the threshold. Tables
+// without statistics (nil) do not exceed it, as their size is unknown.
+func (t SizeThreshold) Exceeds(s *schema.TableStats) bool {
+	switch {
+	case t.Rows <= 0 && t.Size <= 0, s == nil:
+		return false
+	default:
+		return t.Rows > 0 && s.Rows > t.Rows || t.Size > 0 && s.DataSize > t.Size
+	}
+}
+
+// Error implements the error interface.
+func (e *ThresholdError) Error() string {
+	var (
+		names []string
+		seen  = make(map[*schema.Table]bool)
+	)
+	for _, v := range e.Violations {
+		if !seen[v.Table] {
+			seen[v.Table] = true
+			names = append(names, fmt.Sprintf("%q", v.Table.Name))
+		}
+	}
+	return fmt.Sprintf("sql/migrate: destructive or rewriting changes on tables exceeding the size threshold: %s", strings.Join(names, ", "))
+}
+
+// CheckThreshold checks the destructive and rewriting changes of the given plan against the threshold,
+// and returns a ThresholdError in case they affect tables that exceed it. The current realm holds the
+// state of the database the plan is executed on, and it is expected to be inspected with statistics.
+// Callers that only warn on such changes can extract the violations from the returned error:
+//
+//	var terr *migrate.ThresholdError
+//	if err := migrate.CheckThreshold(current, plan, t); errors.As(err, &terr) {
+//		for _, v := range terr.Violations {
+//			log.Printf("warning: %q affects table %q", v.Change.Cmd, v.Table.Name)
+//		}
+//	}
+func CheckThreshold(current *schema.Realm, p *Plan, t SizeThreshold) error {
+	var violations []*ThresholdViolation
+	for _, c := range p.Changes {
+		for _, tb := range riskyTables(c.Source) {
+			if s := TableStatsOf(current, tb); t.Exceeds(s) {
+				violations = append(violations, &ThresholdViolation{Change: c, Table: tb, Stats: s})
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &ThresholdError{Threshold: t, Violations: violations}
+	}
+	return nil
+}
+
+// TableStatsOf returns the statistics of the given table. If the table was not inspected
+// with statistics (e.g., it was loaded from the desired state), they are looked up in the
+// given realm by the schema and table names. A nil value is returned if no statistics
+// were found.
+func TableStatsOf(r *schema.Realm, t *schema.Table) *schema.TableStats {
+	if s := tableStats(t.Attrs); s != nil || r == nil {
+		return s
+	}
+	var s *schema.Schema
+	switch {
+	case t.Schema != nil && t.Schema.Name != "":
+		s, _ = r.Schema(t.Schema.Name)
+	case len(r.Schemas) == 1:
+		s = r.Schemas[0]
+	}
+	if s == nil {
+		return nil
+	}
+	if t, ok := s.Table(t.Name); ok {
+		return tableStats(t.Attrs)
+	}
+	return nil
+}
+
+// RewritesTable reports if the given table change requires the database to
+// rewrite the table, or to scan all its rows, e.g., changing a column type.
+func RewritesTable(c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.ModifyColumn:
+		return c.Change.Is(schema.ChangeType)
+	case *schema.AddPrimaryKey, *schema.ModifyPrimaryKey:
+		return true
+	}
+	return false
+}
+
+// riskyTables returns the tables that are dropped, lose data or rewritten by the given change.
+func riskyTables(c schema.Change) []*schema.Table {
+	switch c := c.(type) {
+	case *schema.DropSchema:
+		return c.S.Tables
+	case *schema.DropTable:
+		return []*schema.Table{c.T}
+	case *schema.ModifyTable:
+		for _, c1 := range c.Changes {
+			if _, ok := c1.(*schema.DropColumn); ok || RewritesTable(c1) {
+				return []*schema.Table{c.T}
+			}
+		}
+	}
+	return nil
+}
+
+func tableStats(attrs []schema.Attr) *schema.TableStats {
+	for _, a := range attrs {
+		if s, ok := a.(*schema.TableStats); ok {
+			return s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"errors"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeThreshold_Exceeds(t *testing.T) {
+	require.False(t, migrate.SizeThreshold{}.Exceeds(nil))
+	require.False(t, migrate.SizeThreshold{}.Exceeds(&schema.TableStats{Rows: 1 << 40}))
+	require.False(t, migrate.SizeThreshold{Rows: 10}.Exceeds(nil), "unknown size")
+	require.True(t, migrate.SizeThreshold{Rows: 10}.Exceeds(&schema.TableStats{Rows: 11}))
+	require.False(t, migrate.SizeThreshold{Rows: 10}.Exceeds(&schema.TableStats{Rows: 10, DataSize: 1 << 30}))
+	require.True(t, migrate.SizeThreshold{Rows: 10, Size: 1024}.Exceeds(&schema.TableStats{Rows: 1, DataSize: 2048}))
+	require.False(t, migrate.SizeThreshold{Rows: 10, Size: 1024}.Exceeds(&schema.TableStats{Rows: 1, DataSize: 1024}))
+}
+
+func TestCheckThreshold(t *testing.T) {
+	var (
+		large = schema.NewTable("large").
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("c", "int")).
+			AddAttrs(&schema.TableStats{Rows: 1000})
+		small = schema.NewTable("small").
+			AddColumns(schema.NewIntColumn("id", "int")).
+			AddAttrs(&schema.TableStats{Rows: 10})
+		current = schema.NewRealm(schema.New("public").AddTables(large, small))
+		// Tables of the desired state, without statistics.
+		desired = schema.NewTable("large").SetSchema(schema.New("public"))
+		plan    = &migrate.Plan{
+			Changes: []*migrate.Change{
+				{Cmd: "DROP TABLE small", Source: &schema.DropTable{T: small}},
+				{Cmd: "ALTER TABLE large ADD COLUMN d int", Source: &schema.ModifyTable{T: desired, Changes: schema.Changes{&schema.AddColumn{C: schema.NewIntColumn("d", "int")}}}},
+			},
+		}
+		th = migrate.SizeThreshold{Rows: 100}
+	)
+	require.NoError(t, migrate.CheckThreshold(current, plan, th))
+
+	plan.Changes = append(plan.Changes,
+		&migrate.Change{
+			Cmd: "ALTER TABLE large ALTER COLUMN c TYPE bigint",
+			Source: &schema.ModifyTable{T: desired, Changes: schema.Changes{
+				&schema.ModifyColumn{From: large.Columns[1], To: schema.NewIntColumn("c", "bigint"), Change: schema.ChangeType},
+			}},
+		},
+		&migrate.Change{Cmd: "DROP TABLE unknown", Source: &schema.DropTable{T: schema.NewTable("unknown")}},
+	)
+	err := migrate.CheckThreshold(current, plan, th)
+	var terr *migrate.ThresholdError
+	require.True(t, errors.As(err, &terr))
+	// Tables without statistics are not known to exceed the threshold.
+	require.Len(t, terr.Violations, 1)
+	require.Equal(t, plan.Changes[2], terr.Violations[0].Change)
+	require.Equal(t, int64(1000), terr.Violations[0].Stats.Rows)
+	require.EqualError(t, err, `sql/migrate: destructive or rewriting changes on tables exceeding the size threshold: "large"`)
+
+	// Without a threshold, no changes are blocked.
+	require.NoError(t, migrate.CheckThreshold(current, plan, migrate.SizeThreshold{}))
+}
@@ -0,0 +1,254 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// TypeConversion classifies the conversion of a column from one type to the other. MySQL converts
+// the values of all types when a column is modified, but in strict mode, the ALTER statement fails
+// on values that do not fit the new type, and otherwise, they are truncated.
+func TypeConversion(from, to schema.Type) migrate.Conversion {
+	if f1, err := FormatType(from); err == nil {
+		if f2, err := FormatType(to); err == nil && f1 == f2 {
+			return migrate.ConversionSafe
+		}
+	}
+	switch from := from.(type) {
+	case *schema.IntegerType, *schema.DecimalType, *schema.FloatType, *schema.BoolType, *BitType:
+		if safeNumeric(from, to) {
+			return migrate.ConversionSafe
+		}
+	case *schema.StringType:
+		if to, ok := to.(*schema.StringType); ok && strSize(to) >= strSize(from) {
+			return migrate.ConversionSafe
+		}
+	case *schema.BinaryType:
+		if to, ok := to.(*schema.BinaryType); ok && binSize(to) >= binSize(from) {
+			return migrate.ConversionSafe
+		}
+	case *schema.EnumType:
+		switch to := to.(type) {
+		case *schema.EnumType:
+			if subset(from.Values, to.Values) {
+				return migrate.ConversionSafe
+			}
+		case *schema.StringType:
+			if strSize(to) >= int64(maxLen(from.Values)) {
+				return migrate.ConversionSafe
+			}
+		}
+	case *SetType:
+		if to, ok := to.(*SetType); ok && subset(from.Values, to.Values) {
+			return migrate.ConversionSafe
+		}
+	case *schema.TimeType:
+		if to, ok := to.(*schema.TimeType); ok && safeTime(from, to) {
+			return migrate.ConversionSafe
+		}
+	case *schema.JSONType:
+		// JSON documents are limited by the max_allowed_packet, and
+		// therefore, can be stored in LONGTEXT columns without loss.
+		if to, ok := to.(*schema.StringType); ok && strings.ToLower(to.T) == TypeLongText {
+			return migrate.ConversionSafe
+		}
+	case *schema.SpatialType:
+		switch to := to.(type) {
+		// All spatial values can be stored in GEOMETRY columns.
+		case *schema.SpatialType:
+			if strings.ToLower(to.T) == TypeGeometry {
+				return migrate.ConversionSafe
+			}
+		case *schema.BinaryType:
+		default:
+			return migrate.ConversionInvalid
+		}
+	}
+	// Non-spatial values cannot be stored in spatial columns.
+	if _, ok := to.(*schema.SpatialType); ok {
+		if _, ok := from.(*schema.BinaryType); !ok {
+			return migrate.ConversionInvalid
+		}
+	}
+	return migrate.ConversionLossy
+}
+
+// safeNumeric reports if all values of the numeric type can be stored in the other type.
+func safeNumeric(from, to schema.Type) bool {
+	lo1, hi1, ok1 := numericRange(from)
+	lo2, hi2, ok2 := numericRange(to)
+	if !ok1 || !ok2 || lo2 > lo1 || hi2 < hi1 {
+		return false
+	}
+	s1, s2 := numericScale(from), numericScale(to)
+	return s1 <= s2
+}
+
+// numericRange returns the bounds of the values that are stored exactly by the numeric
+// type. Floating-point types are exact up to the size of their mantissa.
+func numericRange(t schema.Type) (lo, hi float64, ok bool) {
+	switch t := t.(type) {
+	case *schema.BoolType:
+		return 0, 1, true
+	case *BitType:
+		if t.Size > 63 || t.Size == 0 {
+			return 0, 1 << 63, true
+		}
+		return 0, float64(uint64(1) << t.Size), true
+	case *schema.IntegerType:
+		var bits uint
+		// Display width does not affect the range of integer types.
+		switch numericAlias(strings.TrimSpace(strings.SplitN(t.T, "(", 2)[0])) {
+		case TypeTinyInt:
+			bits = 8
+		case TypeSmallInt:
+			bits = 16
+		case TypeMediumInt:
+			bits = 24
+		case TypeInt:
+			bits = 32
+		default:
+			bits = 64
+		}
+		if t.Unsigned {
+			return 0, float64(uint64(1)<<(bits-1)) * 2, true
+		}
+		return -float64(uint64(1) << (bits - 1)), float64(uint64(1) << (bits - 1)), true
+	case *schema.DecimalType:
+		p := t.Precision
+		if p == 0 {
+			p = 10
+		}
+		hi = pow10(p - t.Scale)
+		if t.Unsigned {
+			return 0, hi, true
+		}
+		return -hi, hi, true
+	case *schema.FloatType:
+		f, err := FormatType(t)
+		if err != nil {
+			return 0, 0, false
+		}
+		hi = 1 << 24
+		if strings.HasPrefix(f, TypeDouble) {
+			hi = 1 << 53
+		}
+		if t.Unsigned {
+			return 0, hi, true
+		}
+		return -hi, hi, true
+	}
+	return 0, 0, false
+}
+
+// numericScale returns the number of fractional digits the type can hold.
+func numericScale(t schema.Type) int {
+	switch t := t.(type) {
+	case *schema.DecimalType:
+		return t.Scale
+	case *schema.FloatType:
+		// Fractional digits are stored approximately.
+		return 1 << 10
+	}
+	return 0
+}
+
+func pow10(n int) float64 {
+	v := 1.0
+	for i := 0; i < n; i++ {
+		v *= 10
+	}
+	return v
+}
+
+// strSize returns the maximum length of values in the string type.
+func strSize(t *schema.StringType) int64 {
+	switch strings.ToLower(t.T) {
+	case TypeTinyText:
+		return 1<<8 - 1
+	case TypeText:
+		return 1<<16 - 1
+	case TypeMediumText:
+		return 1<<24 - 1
+	case TypeLongText:
+		return 1<<32 - 1
+	case TypeChar:
+		if t.Size == 0 {
+			return 1
+		}
+	}
+	return int64(t.Size)
+}
+
+// binSize returns the maximum length of values in the binary type.
+func binSize(t *schema.BinaryType) int64 {
+	switch strings.ToLower(t.T) {
+	case TypeTinyBlob:
+		return 1<<8 - 1
+	case TypeBlob:
+		return 1<<16 - 1
+	case TypeMediumBlob:
+		return 1<<24 - 1
+	case TypeLongBlob:
+		return 1<<32 - 1
+	}
+	if t.Size == nil {
+		return 1
+	}
+	return int64(*t.Size)
+}
+
+// safeTime reports if all values of the time type can be stored in the other type.
+func safeTime(from, to *schema.TimeType) bool {
+	t1, t2 := strings.ToLower(from.T), strings.ToLower(to.T)
+	if precision(to.Precision) < precision(from.Precision) {
+		return false
+	}
+	switch {
+	case t1 == t2:
+		return true
+	// DATETIME has a wider range than TIMESTAMP, and includes all DATE values.
+	case t1 == TypeDate && t2 == TypeDateTime, t1 == TypeTimestamp && t2 == TypeDateTime:
+		return true
+	}
+	return false
+}
+
+func precision(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// subset reports if all values in vs1 exist in vs2.
+func subset(vs1, vs2 []string) bool {
+	for _, v1 := range vs1 {
+		var found bool
+		for _, v2 := range vs2 {
+			if v1 == v2 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func maxLen(vs []string) (n int) {
+	for _, v := range vs {
+		if l := len([]rune(v)); l > n {
+			n = l
+		}
+	}
+	return n
+}
@@ -0,0 +1,65 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeConversion(t *testing.T) {
+	p := func(i int) *int { return &i }
+	for _, tt := range []struct {
+		from, to schema.Type
+		want     migrate.Conversion
+	}{
+		// Numeric types.
+		{from: &schema.IntegerType{T: "int"}, to: &schema.IntegerType{T: "bigint"}, want: migrate.ConversionSafe},
+		{from: &schema.IntegerType{T: "int(11)"}, to: &schema.IntegerType{T: "integer"}, want: migrate.ConversionSafe},
+		{from: &schema.IntegerType{T: "bigint"}, to: &schema.IntegerType{T: "int"}, want: migrate.ConversionLossy},
+		{from: &schema.IntegerType{T: "int"}, to: &schema.IntegerType{T: "int", Unsigned: true}, want: migrate.ConversionLossy},
+		{from: &schema.IntegerType{T: "int", Unsigned: true}, to: &schema.IntegerType{T: "bigint"}, want: migrate.ConversionSafe},
+		{from: &schema.IntegerType{T: "int"}, to: &schema.DecimalType{T: "decimal", Precision: 10}, want: migrate.ConversionSafe},
+		{from: &schema.DecimalType{T: "decimal", Precision: 10, Scale: 2}, to: &schema.DecimalType{T: "decimal", Precision: 12, Scale: 2}, want: migrate.ConversionSafe},
+		{from: &schema.DecimalType{T: "decimal", Precision: 10, Scale: 2}, to: &schema.DecimalType{T: "decimal", Precision: 10, Scale: 4}, want: migrate.ConversionLossy},
+		{from: &schema.IntegerType{T: "int"}, to: &schema.FloatType{T: "double"}, want: migrate.ConversionSafe},
+		{from: &schema.IntegerType{T: "int"}, to: &schema.FloatType{T: "float"}, want: migrate.ConversionLossy},
+		{from: &schema.FloatType{T: "double"}, to: &schema.IntegerType{T: "bigint"}, want: migrate.ConversionLossy},
+		{from: &schema.BoolType{T: "bool"}, to: &schema.IntegerType{T: "tinyint"}, want: migrate.ConversionSafe},
+
+		// String and binary types.
+		{from: &schema.StringType{T: "varchar", Size: 10}, to: &schema.StringType{T: "varchar", Size: 20}, want: migrate.ConversionSafe},
+		{from: &schema.StringType{T: "varchar", Size: 255}, to: &schema.StringType{T: "text"}, want: migrate.ConversionSafe},
+		{from: &schema.StringType{T: "varchar", Size: 20}, to: &schema.StringType{T: "varchar", Size: 10}, want: migrate.ConversionLossy},
+		{from: &schema.StringType{T: "longtext"}, to: &schema.StringType{T: "text"}, want: migrate.ConversionLossy},
+		{from: &schema.StringType{T: "varchar", Size: 10}, to: &schema.IntegerType{T: "int"}, want: migrate.ConversionLossy},
+		{from: &schema.BinaryType{T: "varbinary", Size: p(10)}, to: &schema.BinaryType{T: "blob"}, want: migrate.ConversionSafe},
+		{from: &schema.BinaryType{T: "blob"}, to: &schema.BinaryType{T: "tinyblob"}, want: migrate.ConversionLossy},
+
+		// Enum and set types.
+		{from: &schema.EnumType{T: "enum", Values: []string{"a", "b"}}, to: &schema.EnumType{T: "enum", Values: []string{"a", "b", "c"}}, want: migrate.ConversionSafe},
+		{from: &schema.EnumType{T: "enum", Values: []string{"a", "b"}}, to: &schema.EnumType{T: "enum", Values: []string{"a"}}, want: migrate.ConversionLossy},
+		{from: &schema.EnumType{T: "enum", Values: []string{"a", "bcd"}}, to: &schema.StringType{T: "varchar", Size: 3}, want: migrate.ConversionSafe},
+		{from: &SetType{Values: []string{"a"}}, to: &SetType{Values: []string{"a", "b"}}, want: migrate.ConversionSafe},
+
+		// Time types.
+		{from: &schema.TimeType{T: "date"}, to: &schema.TimeType{T: "datetime"}, want: migrate.ConversionSafe},
+		{from: &schema.TimeType{T: "timestamp"}, to: &schema.TimeType{T: "datetime"}, want: migrate.ConversionSafe},
+		{from: &schema.TimeType{T: "datetime"}, to: &schema.TimeType{T: "timestamp"}, want: migrate.ConversionLossy},
+		{from: &schema.TimeType{T: "datetime", Precision: p(6)}, to: &schema.TimeType{T: "datetime", Precision: p(3)}, want: migrate.ConversionLossy},
+
+		// Other types.
+		{from: &schema.JSONType{T: "json"}, to: &schema.StringType{T: "longtext"}, want: migrate.ConversionSafe},
+		{from: &schema.SpatialType{T: "point"}, to: &schema.SpatialType{T: "geometry"}, want: migrate.ConversionSafe},
+		{from: &schema.SpatialType{T: "point"}, to: &schema.IntegerType{T: "int"}, want: migrate.ConversionInvalid},
+		{from: &schema.IntegerType{T: "int"}, to: &schema.SpatialType{T: "point"}, want: migrate.ConversionInvalid},
+	} {
+		require.Equal(t, tt.want, TypeConversion(tt.from, tt.to), "%+v -> %+v", tt.from, tt.to)
+	}
+}
@@ -13,9 +13,28 @@ import (
 	"ariga.io/atlas/sql/schema"
 )
 
+// typeDefs holds the custom types registered using RegisterType.
+var typeDefs = sqlx.NewTypeDefs()
+
+// RegisterType registers a custom column type, such as types installed by extensions,
+// that are parsed, formatted and compared using the given definition, instead of being
+// reported as unsupported. Registered types take precedence over the builtin types of
+// the driver. For example:
+//
+//	mysql.RegisterType(&schema.TypeDef{Name: "vector"})
+func RegisterType(d *schema.TypeDef) error {
+	if err := typeDefs.Register(d); err != nil {
+		return fmt.Errorf("mysql: register type: %w", err)
+	}
+	return nil
+}
+
 // FormatType converts schema type to its column form in the database.
 // An error is returned if the type cannot be recognized.
 func FormatType(t schema.Type) (string, error) {
+	if f, ok, err := typeDefs.Format(t); ok {
+		return f, err
+	}
 	var f string
 	switch t := t.(type) {
 	case *BitType:
@@ -38,7 +57,7 @@ func FormatType(t schema.Type) (string, error) {
 			f = fmt.Sprintf("%s(%d)", f, *t.Size)
 		}
 	case *schema.DecimalType:
-		if f = strings.ToLower(t.T); f != TypeDecimal && f != TypeNumeric {
+		if f = numericAlias(t.T); f != TypeDecimal {
 			return "", fmt.Errorf("unexpected decimal type: %q", t.T)
 		}
 		switch p, s := t.Precision, t.Scale; {
@@ -62,7 +81,7 @@ func FormatType(t schema.Type) (string, error) {
 	case *schema.EnumType:
 		f = fmt.Sprintf("enum(%s)", formatValues(t.Values))
 	case *schema.FloatType:
-		f = strings.ToLower(t.T)
+		f = numericAlias(t.T)
 		// FLOAT with precision > 24, become DOUBLE.
 		// Also, REAL is a synonym for DOUBLE (if REAL_AS_FLOAT was not set).
 		if f == TypeFloat && t.Precision > 24 || f == TypeReal {
@@ -72,7 +91,7 @@ func FormatType(t schema.Type) (string, error) {
 			f += " unsigned"
 		}
 	case *schema.IntegerType:
-		f = strings.ToLower(t.T)
+		f = numericAlias(t.T)
 		if t.Unsigned {
 			f += " unsigned"
 		}
@@ -110,9 +129,39 @@ func FormatType(t schema.Type) (string, error) {
 	return f, nil
 }
 
+// numericAliases maps the synonyms of numeric types to the types they are stored
+// as. See: https://dev.mysql.com/doc/refman/8.0/en/other-vendor-data-types.html
+var numericAliases = map[string]string{
+	"integer":          TypeInt,
+	"int1":             TypeTinyInt,
+	"int2":             TypeSmallInt,
+	"int3":             TypeMediumInt,
+	"middleint":        TypeMediumInt,
+	"int4":             TypeInt,
+	"int8":             TypeBigInt,
+	"dec":              TypeDecimal,
+	"fixed":            TypeDecimal,
+	TypeNumeric:        TypeDecimal,
+	"float4":           TypeFloat,
+	"float8":           TypeDouble,
+	"double precision": TypeDouble,
+}
+
+// numericAlias returns the canonical (lowercased) form of the given numeric type.
+func numericAlias(t string) string {
+	t = strings.ToLower(t)
+	if a, ok := numericAliases[t]; ok {
+		return a
+	}
+	return t
+}
+
 // ParseType returns the schema.Type value represented by the given raw type.
 // The raw value is expected to follow the format in MySQL information schema.
 func ParseType(raw string) (schema.Type, error) {
+	if t, ok, err := typeDefs.Parse(raw); ok {
+		return t, err
+	}
 	parts, size, unsigned, err := parseColumn(raw)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,157 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// planData plans the given data changes. MySQL does not support loops outside of
+// stored programs. Hence, batched changes are executed by a temporary procedure
+// that repeats the batch statement until no rows are affected, and commits after
+// each batch to release the row locks it holds. Note, the procedure body contains semicolons, and writing such plans to a migration directory requires
+// setting a custom delimiter using the "atlas:delimiter" directive.
+func (s *state) planData(changes []schema.Change) error {
+	for _, c := range changes {
+		var err error
+		switch c := c.(type) {
+		case *migrate.BackfillColumn:
+			s.backfill(c, c)
+		case *migrate.CopyTable:
+			err = s.copyTable(c)
+		case *migrate.RenameColumnSync:
+			err = s.renameColumnSync(c)
+		default:
+			err = fmt.Errorf("unsupported data change %T", c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfill plans the BackfillColumn change.
+func (s *state) backfill(c *migrate.BackfillColumn, src schema.Change) {
+	column, expr := s.Build().Ident(c.C.Name).String(), c.Expr
+	if expr == "" && c.From != nil {
+		expr = s.Build().Ident(c.From.Name).String()
+	}
+	conds := []string{fmt.Sprintf("NOT (%s <=> (%s))", column, expr)}
+	if c.Where != "" {
+		conds = append([]string{"(" + c.Where + ")"}, conds...)
+	}
+	b := s.Build("UPDATE").Table(c.T).P("SET", column, "=", expr, "WHERE", strings.Join(conds, " AND "))
+	comment := fmt.Sprintf("backfill column %q of table %q", c.C.Name, c.T.Name)
+	if c.BatchSize <= 0 {
+		s.append(&migrate.Change{Source: src, Cmd: b.String(), Comment: comment})
+		return
+	}
+	b.P("LIMIT", fmt.Sprint(c.BatchSize))
+	s.batchProc(src, comment, &schema.Proc{Name: sqlx.BoundName("atlas_backfill_"+c.T.Name+"_"+c.C.Name, maxNameLen), Schema: c.T.Schema}, b.String())
+}
+
+// copyTable plans the CopyTable change.
+func (s *state) copyTable(c *migrate.CopyTable) error {
+	columns := c.CopyColumns()
+	b := s.Build("INSERT INTO").Table(c.To).Wrap(func(b *sqlx.Builder) {
+		b.MapComma(columns, func(i int, b *sqlx.Builder) {
+			b.Ident(columns[i])
+		})
+	})
+	b.P("SELECT").MapComma(columns, func(i int, b *sqlx.Builder) {
+		b.Ident(columns[i])
+	})
+	b.P("FROM").Table(c.From)
+	comment := fmt.Sprintf("copy rows from table %q to table %q", c.From.Name, c.To.Name)
+	if c.BatchSize <= 0 {
+		s.append(&migrate.Change{Source: c, Cmd: b.String(), Comment: comment})
+		return nil
+	}
+	key, err := sqlx.CopyKey(c)
+	if err != nil {
+		return err
+	}
+	conds := make([]string, len(key))
+	for i, k := range key {
+		conds[i] = fmt.Sprintf("atlas_dst.%[1]s = atlas_src.%[1]s", s.Build().Ident(k.Name).String())
+	}
+	b.P("AS atlas_src WHERE NOT EXISTS (SELECT 1 FROM").Table(c.To).P("AS atlas_dst WHERE", strings.Join(conds, " AND ")+")", "ORDER BY")
+	b.MapComma(key, func(i int, b *sqlx.Builder) {
+		b.Ident(key[i].Name)
+	})
+	b.P("LIMIT", fmt.Sprint(c.BatchSize))
+	s.batchProc(c, comment, &schema.Proc{Name: sqlx.BoundName("atlas_copy_"+c.From.Name+"_"+c.To.Name, maxNameLen), Schema: c.From.Schema}, b.String())
+	return nil
+}
+
+// renameColumnSync plans the RenameColumnSync change. The columns are kept
+// in sync by two triggers, one for inserts and one for updates, whose
+// body is a single SET statement.
+func (s *state) renameColumnSync(c *migrate.RenameColumnSync) error {
+	if err := s.modifyTable(&schema.ModifyTable{T: c.T, Changes: []schema.Change{&schema.AddColumn{C: c.To}}}); err != nil {
+		return err
+	}
+	if !c.SkipBackfill {
+		s.backfill(&migrate.BackfillColumn{T: c.T, C: c.To, From: c.From, BatchSize: c.BatchSize}, c)
+	}
+	var (
+		from, to = s.Build().Ident(c.From.Name).String(), s.Build().Ident(c.To.Name).String()
+		ins      = &schema.Proc{Name: sqlx.BoundName(c.SyncTriggerName()+"_insert", maxNameLen), Schema: c.T.Schema}
+		upd      = &schema.Proc{Name: sqlx.BoundName(c.SyncTriggerName()+"_update", maxNameLen), Schema: c.T.Schema}
+	)
+	s.append(
+		&migrate.Change{
+			Source: c,
+			Cmd: s.Build("CREATE TRIGGER").Proc(ins).P("BEFORE INSERT ON").Table(c.T).P(
+				fmt.Sprintf("FOR EACH ROW SET NEW.%[2]s = COALESCE(NEW.%[2]s, NEW.%[1]s), NEW.%[1]s = COALESCE(NEW.%[1]s, NEW.%[2]s)", from, to),
+			).String(),
+			Reverse: s.Build("DROP TRIGGER").Proc(ins).String(),
+			Comment: fmt.Sprintf("create insert trigger for syncing column %q with column %q", c.To.Name, c.From.Name),
+		},
+		&migrate.Change{
+			Source: c,
+			Cmd: s.Build("CREATE TRIGGER").Proc(upd).P("BEFORE UPDATE ON").Table(c.T).P(
+				fmt.Sprintf("FOR EACH ROW SET NEW.%[1]s = IF(NEW.%[2]s <=> OLD.%[2]s, NEW.%[1]s, NEW.%[2]s), NEW.%[2]s = IF(NEW.%[2]s <=> OLD.%[2]s, NEW.%[1]s, NEW.%[2]s)", from, to),
+			).String(),
+			Reverse: s.Build("DROP TRIGGER").Proc(upd).String(),
+			Comment: fmt.Sprintf("create update trigger for syncing column %q with column %q", c.To.Name, c.From.Name),
+		},
+	)
+	return nil
+}
+
+// maxNameLen is the maximum length of identifiers.
+const maxNameLen = 64
+
+// batchProc appends the changes for creating, calling and dropping a temporary
+// procedure that executes the given statement until no rows are affected. The
+// row count is read before the COMMIT statement, which resets it.
+func (s *state) batchProc(src schema.Change, comment string, p *schema.Proc, stmt string) {
+	name := s.Build().Proc(p).String()
+	s.append(
+		&migrate.Change{
+			Source:  src,
+			Cmd:     fmt.Sprintf("CREATE PROCEDURE %s() BEGIN DECLARE atlas_rows BIGINT DEFAULT 0; REPEAT %s; SET atlas_rows = ROW_COUNT(); COMMIT; UNTIL atlas_rows = 0 END REPEAT; END", name, stmt),
+			Reverse: "DROP PROCEDURE " + name,
+			Comment: fmt.Sprintf("create procedure for batching the %s", comment),
+		},
+		&migrate.Change{
+			Source:  src,
+			Cmd:     fmt.Sprintf("CALL %s()", name),
+			Comment: comment,
+		},
+		&migrate.Change{
+			Source:  src,
+			Cmd:     "DROP PROCEDURE " + name,
+			Comment: fmt.Sprintf("drop the procedure used for batching the %s", comment),
+		},
+	)
+}
@@ -0,0 +1,71 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges_Data(t *testing.T) {
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		first = schema.NewStringColumn("first_name", "varchar(255)")
+		users = schema.NewTable("users").
+			SetSchema(schema.New("test")).
+			AddColumns(id, first).
+			SetPrimaryKey(schema.NewPrimaryKey(id))
+		copies = schema.NewTable("users_copy").
+			SetSchema(users.Schema).
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("first_name", "varchar(255)"))
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.BackfillColumn{T: users, C: first, Expr: "upper(first_name)", Where: "id > 10"},
+		&migrate.CopyTable{From: users, To: copies},
+		&migrate.BackfillColumn{T: users, C: first, Expr: "trim(first_name)", BatchSize: 100},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 5)
+	require.Equal(t, "UPDATE `test`.`users` SET `first_name` = upper(first_name) WHERE (id > 10) AND NOT (`first_name` <=> (upper(first_name)))", plan.Changes[0].Cmd)
+	require.Equal(t, "INSERT INTO `test`.`users_copy` (`id`, `first_name`) SELECT `id`, `first_name` FROM `test`.`users`", plan.Changes[1].Cmd)
+	require.Equal(t, "CREATE PROCEDURE `test`.`atlas_backfill_users_first_name`() BEGIN DECLARE atlas_rows BIGINT DEFAULT 0; REPEAT UPDATE `test`.`users` SET `first_name` = trim(first_name) WHERE NOT (`first_name` <=> (trim(first_name))) LIMIT 100; SET atlas_rows = ROW_COUNT(); COMMIT; UNTIL atlas_rows = 0 END REPEAT; END", plan.Changes[2].Cmd)
+	require.Equal(t, "DROP PROCEDURE `test`.`atlas_backfill_users_first_name`", plan.Changes[2].Reverse)
+	require.Equal(t, "CALL `test`.`atlas_backfill_users_first_name`()", plan.Changes[3].Cmd)
+	require.Equal(t, "DROP PROCEDURE `test`.`atlas_backfill_users_first_name`", plan.Changes[4].Cmd)
+
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.CopyTable{From: users, To: copies, BatchSize: 10},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, "CREATE PROCEDURE `test`.`atlas_copy_users_users_copy`() BEGIN DECLARE atlas_rows BIGINT DEFAULT 0; REPEAT INSERT INTO `test`.`users_copy` (`id`, `first_name`) SELECT `id`, `first_name` FROM `test`.`users` AS atlas_src WHERE NOT EXISTS (SELECT 1 FROM `test`.`users_copy` AS atlas_dst WHERE atlas_dst.`id` = atlas_src.`id`) ORDER BY `id` LIMIT 10; SET atlas_rows = ROW_COUNT(); COMMIT; UNTIL atlas_rows = 0 END REPEAT; END", plan.Changes[0].Cmd)
+
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.RenameColumnSync{T: users, From: first, To: schema.NewNullStringColumn("given_name", "varchar(255)")},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, "ALTER TABLE `test`.`users` ADD COLUMN `given_name` varchar(255) NULL", plan.Changes[0].Cmd)
+	require.Equal(t, "UPDATE `test`.`users` SET `given_name` = `first_name` WHERE NOT (`given_name` <=> (`first_name`))", plan.Changes[1].Cmd)
+	require.Equal(t, "CREATE TRIGGER `test`.`atlas_sync_users_first_name_given_name_insert` BEFORE INSERT ON `test`.`users` FOR EACH ROW SET NEW.`given_name` = COALESCE(NEW.`given_name`, NEW.`first_name`), NEW.`first_name` = COALESCE(NEW.`first_name`, NEW.`given_name`)", plan.Changes[2].Cmd)
+	require.Equal(t, "DROP TRIGGER `test`.`atlas_sync_users_first_name_given_name_insert`", plan.Changes[2].Reverse)
+	require.Equal(t, "CREATE TRIGGER `test`.`atlas_sync_users_first_name_given_name_update` BEFORE UPDATE ON `test`.`users` FOR EACH ROW SET NEW.`first_name` = IF(NEW.`given_name` <=> OLD.`given_name`, NEW.`first_name`, NEW.`given_name`), NEW.`given_name` = IF(NEW.`given_name` <=> OLD.`given_name`, NEW.`first_name`, NEW.`given_name`)", plan.Changes[3].Cmd)
+
+	// Generated names are bounded to the maximum identifier length.
+	long := schema.NewStringColumn(strings.Repeat("c", 60), "varchar(255)")
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.BackfillColumn{T: users.AddColumns(long), C: long, Expr: "''", BatchSize: 100},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Regexp(t, "^CALL `test`.`atlas_backfill_users_c+_[0-9a-f]{8}`\\(\\)$", plan.Changes[1].Cmd)
+	require.Len(t, strings.TrimSuffix(strings.TrimPrefix(plan.Changes[1].Cmd, "CALL `test`."), "()"), maxNameLen+2)
+}
@@ -8,11 +8,13 @@ import (
 	"encoding/hex"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 
 	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/mysql/internal/mysqlversion"
 	"ariga.io/atlas/sql/schema"
 )
 
@@ -33,6 +35,71 @@ type diff struct {
 	}
 }
 
+// defaultEquivs holds the DEFAULT expressions that are equivalent in MySQL,
+// but are written differently in the desired state and the inspected database.
+var defaultEquivs = func() *sqlx.Equivalents {
+	e := sqlx.NewEquivalents(
+		[]string{
+			"CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP()", "now()",
+			"LOCALTIME", "LOCALTIME()", "LOCALTIMESTAMP", "LOCALTIMESTAMP()",
+		},
+		[]string{"CURRENT_DATE", "CURRENT_DATE()", "curdate()"},
+		[]string{"UTC_TIMESTAMP", "UTC_TIMESTAMP()"},
+	)
+	// Fractional seconds precision.
+	for p := 1; p <= 6; p++ {
+		e.Register(
+			fmt.Sprintf("CURRENT_TIMESTAMP(%d)", p), fmt.Sprintf("now(%d)", p),
+			fmt.Sprintf("LOCALTIME(%d)", p), fmt.Sprintf("LOCALTIMESTAMP(%d)", p),
+		)
+		e.Register(fmt.Sprintf("UTC_TIMESTAMP(%d)", p))
+	}
+	return e
+}()
+
+// RegisterDefaultEquivalents registers the given DEFAULT expressions as equivalent, and
+// changes between them are not reported by the differ. For example:
+//
+//	mysql.RegisterDefaultEquivalents("(now() + interval 1 day)", "(current_timestamp() + interval 1 day)")
+func RegisterDefaultEquivalents(exprs ...string) {
+	defaultEquivs.Register(exprs...)
+}
+
+// indexExprs computes the canonical form of index expressions. By default, identifier
+// quotes and the charset introducers that MySQL adds to string literals on inspection
+// (e.g. "lower(`name`) = _utf8mb4'a'") are removed.
+var indexExprs = sqlx.NewCanonicalizer(func(x string) string {
+	x = reIntroducer.ReplaceAllString(x, "'")
+	return reQuotedIdent.ReplaceAllString(x, "$1")
+})
+
+var (
+	reIntroducer  = regexp.MustCompile(`(?i)\b_[a-z0-9]+'`)
+	reQuotedIdent = regexp.MustCompile("`((?:[^`]|``)+)`")
+)
+
+// CanonicalIndexExpr returns the canonical form of the given index expression. Expressions
+// with the same canonical form are considered equal by the differ, although one of them
+// was written by the user and the other was normalized by the database.
+func CanonicalIndexExpr(x string) string {
+	return indexExprs.Canonical(x)
+}
+
+// RegisterIndexExprFuncs registers functions for computing the canonical form of index
+// expressions. For example, to match a function with its synonym as inspected from the database:
+//
+//	mysql.RegisterIndexExprFuncs(func(x string) string {
+//		return strings.ReplaceAll(x, "ucase(", "upper(")
+//	})
+func RegisterIndexExprFuncs(fns ...func(string) string) {
+	indexExprs.Register(fns...)
+}
+
+// CanonicalIndexExpr implements the sqlx.IndexExprCanonicalizer interface.
+func (*diff) CanonicalIndexExpr(_ *schema.Table, x string) string {
+	return CanonicalIndexExpr(x)
+}
+
 // SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
 func (d *diff) SchemaAttrDiff(from, to *schema.Schema) []schema.Change {
 	var (
@@ -62,23 +129,28 @@ func (*diff) SchemaObjectDiff(_, _ *schema.Schema) ([]schema.Change, error) {
 // TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
 func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	var changes []schema.Change
-	if change := d.autoIncChange(from.Attrs, to.Attrs); change != noChange {
+	if change := d.autoIncChange(from.Attrs, to); change != noChange {
 		changes = append(changes, change)
 	}
 	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
 		changes = append(changes, change)
 	}
-	if change := d.charsetChange(from.Attrs, from.Schema.Attrs, to.Attrs); change != noChange {
+	if change := d.charsetChange(from.Attrs, schemaInheritedAttrs(from.Schema), to.Attrs); change != noChange {
 		changes = append(changes, change)
 	}
-	if change := d.collationChange(from.Attrs, from.Schema.Attrs, to.Attrs); change != noChange {
+	if change := d.collationChange(from.Attrs, schemaInheritedAttrs(from.Schema), to.Attrs); change != noChange {
 		changes = append(changes, change)
 	}
 	if change := d.engineChange(from.Attrs, to.Attrs); change != noChange {
 		changes = append(changes, change)
 	}
-	if !d.SupportsCheck() && sqlx.Has(to.Attrs, &schema.Check{}) {
-		return nil, fmt.Errorf("version %q does not support CHECK constraints", d.V)
+	if change := createOptionsChange(from.Attrs, to.Attrs); change != noChange {
+		changes = append(changes, change)
+	}
+	if sqlx.Has(to.Attrs, &schema.Check{}) {
+		if err := d.Require(mysqlversion.FeatureCheck); err != nil {
+			return nil, err
+		}
 	}
 	// For MariaDB, we skip JSON CHECK constraints that were created by the databases,
 	// or by Atlas for older versions. These CHECK constraints (inlined on the columns)
@@ -179,7 +251,7 @@ func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
 		fromP, toP     IndexParser
 		fromHas, toHas = sqlx.Has(from, &fromP), sqlx.Has(to, &toP)
 	)
-	return fromHas != toHas || (fromHas && fromP.P != toP.P)
+	return fromHas != toHas || (fromHas && !strings.EqualFold(fromP.P, toP.P))
 }
 
 // IndexPartAttrChanged reports if the index-part attributes (collation or prefix) were changed.
@@ -227,6 +299,12 @@ func (d *diff) Normalize(from, to *schema.Table) error {
 	return d.defaultCharset(&to.Attrs)
 }
 
+// SupportsColumnMove implements the sqlx.ColumnMover interface. Columns
+// are moved using the FIRST and AFTER clauses of MODIFY COLUMN.
+func (*diff) SupportsColumnMove() bool {
+	return true
+}
+
 // FindTable implements the DiffDriver.TableFinder method in order to provide
 // tables lookup that respect the "lower_case_table_names" system variable.
 func (d *diff) FindTable(s *schema.Schema, name string) (*schema.Table, error) {
@@ -268,6 +346,8 @@ func (*diff) collationChange(from, top, to []schema.Attr) schema.Change {
 	var fromC, topC, toC schema.Collation
 	switch fromHas, topHas, toHas := sqlx.Has(from, &fromC), sqlx.Has(top, &topC), sqlx.Has(to, &toC); {
 	case !fromHas && !toHas:
+	// Explicitly set to the inherited value.
+	case !fromHas && topHas && toC.V == topC.V:
 	case !fromHas:
 		return &schema.AddAttr{
 			A: &toC,
@@ -320,12 +400,64 @@ func (*diff) engineChange(from, to []schema.Attr) schema.Change {
 	return noChange
 }
 
+// createOptionsChange returns the schema change for migrating the extra table options
+// in case they were changed. Options are compared only if they are defined in the
+// desired state, as options that were removed cannot be reset to their defaults.
+func createOptionsChange(from, to []schema.Attr) schema.Change {
+	var fromO, toO CreateOptions
+	if !sqlx.Has(to, &toO) || strings.TrimSpace(toO.V) == "" {
+		return noChange
+	}
+	if !sqlx.Has(from, &fromO) {
+		return &schema.AddAttr{A: &toO}
+	}
+	current := createOptions(fromO.V)
+	for k, v := range createOptions(toO.V) {
+		if v1, ok := current[k]; !ok || !strings.EqualFold(strings.Trim(v, `'"`), strings.Trim(v1, `'"`)) {
+			return &schema.ModifyAttr{From: &fromO, To: &toO}
+		}
+	}
+	return noChange
+}
+
+// createOptions parses the extra table options into a map. Option names
+// are case-insensitive and returned in upper case. e.g. ROW_FORMAT=COMPRESSED.
+func createOptions(s string) map[string]string {
+	var (
+		opts  = make(map[string]string)
+		part  strings.Builder
+		quote rune
+		add   = func() {
+			if k, v, ok := strings.Cut(part.String(), "="); ok {
+				opts[strings.ToUpper(strings.TrimSpace(k))] = strings.TrimSpace(v)
+			}
+			part.Reset()
+		}
+	)
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '\'' || r == '"'):
+			quote = r
+		case quote == 0 && r == ' ':
+			add()
+			continue
+		}
+		part.WriteRune(r)
+	}
+	add()
+	return opts
+}
+
 // charsetChange returns the schema change for migrating the collation if
 // it was changed, and it is not the default attribute inherited from its parent.
 func (*diff) charsetChange(from, top, to []schema.Attr) schema.Change {
 	var fromC, topC, toC schema.Charset
 	switch fromHas, topHas, toHas := sqlx.Has(from, &fromC), sqlx.Has(top, &topC), sqlx.Has(to, &toC); {
 	case !fromHas && !toHas:
+	// Explicitly set to the inherited value.
+	case !fromHas && topHas && toC.V == topC.V:
 	case !fromHas:
 		return &schema.AddAttr{
 			A: &toC,
@@ -355,51 +487,64 @@ func (d *diff) columnCharsetChanged(fromT *schema.Table, from, to *schema.Column
 		return false, err
 	}
 	var (
-		fromC, topC, toC       schema.Charset
-		fromHas, topHas, toHas = sqlx.Has(from.Attrs, &fromC), sqlx.Has(fromT.Attrs, &topC), sqlx.Has(to.Attrs, &toC)
+		fromC, toC schema.Charset
+		top        = inheritedAttrs(fromT)
+		// Columns without an explicit CHARSET inherit
+		// it from their table, schema or server.
+		fromHas = sqlx.Has(from.Attrs, &fromC) || sqlx.Has(top, &fromC)
+		toHas   = sqlx.Has(to.Attrs, &toC) || sqlx.Has(top, &toC)
 	)
-	// Column was updated with custom CHARSET that was dropped.
-	// Hence, we should revert to the one defined on the table.
-	return fromHas && !toHas && topHas && fromC.V != topC.V ||
-		// Custom CHARSET was added to the column. Hence,
-		// Does not match the one defined in the table.
-		!fromHas && toHas && topHas && toC.V != topC.V ||
-		// CHARSET was explicitly changed.
-		fromHas && toHas && fromC.V != toC.V, nil
-
+	return fromHas && toHas && fromC.V != toC.V, nil
 }
 
-// columnCollateChanged indicates if there is a change to the column charset.
+// columnCollateChanged indicates if there is a change to the column collation.
 func (d *diff) columnCollateChanged(fromT *schema.Table, from, to *schema.Column) (bool, error) {
 	if err := d.defaultCollate(&to.Attrs); err != nil {
 		return false, err
 	}
 	var (
-		fromC, topC, toC       schema.Collation
-		fromHas, topHas, toHas = sqlx.Has(from.Attrs, &fromC), sqlx.Has(fromT.Attrs, &topC), sqlx.Has(to.Attrs, &toC)
+		fromC, toC schema.Collation
+		top        = inheritedAttrs(fromT)
+		// Columns without an explicit COLLATE inherit
+		// it from their table, schema or server.
+		fromHas = sqlx.Has(from.Attrs, &fromC) || sqlx.Has(top, &fromC)
+		toHas   = sqlx.Has(to.Attrs, &toC) || sqlx.Has(top, &toC)
 	)
-	// Column was updated with custom COLLATE that was dropped.
-	// Hence, we should revert to the one defined on the table.
-	return fromHas && !toHas && topHas && fromC.V != topC.V ||
-		// Custom COLLATE was added to the column. Hence,
-		// Does not match the one defined in the table.
-		!fromHas && toHas && topHas && toC.V != topC.V ||
-		// COLLATE was explicitly changed.
-		fromHas && toHas && fromC.V != toC.V, nil
+	return fromHas && toHas && fromC.V != toC.V, nil
+}
+
+// inheritedAttrs returns the attributes a column inherits from its table, ordered by the
+// inheritance chain: table <- schema <- server. Hence, sqlx.Has returns the closest one.
+func inheritedAttrs(t *schema.Table) []schema.Attr {
+	attrs := t.Attrs
+	if s := t.Schema; s != nil {
+		attrs = append(attrs[:len(attrs):len(attrs)], schemaInheritedAttrs(s)...)
+	}
+	return attrs
+}
 
+// schemaInheritedAttrs returns the attributes a table inherits from its schema
+// or the server (realm), ordered by the inheritance chain.
+func schemaInheritedAttrs(s *schema.Schema) []schema.Attr {
+	attrs := s.Attrs
+	if s.Realm != nil {
+		attrs = append(attrs[:len(attrs):len(attrs)], s.Realm.Attrs...)
+	}
+	return attrs
 }
 
 // autoIncChange returns the schema change for changing the AUTO_INCREMENT
 // attribute in case it is not the default.
-func (*diff) autoIncChange(from, to []schema.Attr) schema.Change {
+func (*diff) autoIncChange(from []schema.Attr, to *schema.Table) schema.Change {
 	var fromA, toA AutoIncrement
-	switch fromHas, toHas := sqlx.Has(from, &fromA), sqlx.Has(to, &toA); {
+	switch fromHas, toHas := sqlx.Has(from, &fromA), sqlx.Has(to.Attrs, &toA); {
 	// Ignore if the AUTO_INCREMENT attribute was dropped from the desired schema.
 	case fromHas && !toHas:
 	// The AUTO_INCREMENT exists in the desired schema, and may not exist in the inspected one.
 	// This can happen because older versions of MySQL (< 8.0) stored the AUTO_INCREMENT counter
 	// in main memory (not persistent), and the value is reset on process restart for empty tables.
-	case toA.V > 1 && toA.V > fromA.V:
+	case toA.V > 1 && autoIncStart(to, toA.V) > fromA.V:
+		toA.V = autoIncStart(to, toA.V)
 		// Suggest a diff only if the desired value is greater than the inspected one,
 		// because this attribute cannot be maintained in users schema and used to set
 		// up only the initial value.
@@ -411,6 +556,25 @@ func (*diff) autoIncChange(from, to []schema.Attr) schema.Change {
 	return noChange
 }
 
+// autoIncStart returns the AUTO_INCREMENT start value of the table aligned to the
+// AutoIncrementStep of its schema, i.e. the first value generated by the server.
+func autoIncStart(t *schema.Table, v int64) int64 {
+	var step AutoIncrementStep
+	if t.Schema == nil || !sqlx.Has(t.Schema.Attrs, &step) || step.Increment <= 1 {
+		return v
+	}
+	// The offset is ignored by the server if it
+	// is greater than the increment.
+	offset := step.Offset
+	if offset < 1 || offset > step.Increment {
+		offset = 1
+	}
+	if v <= offset {
+		return offset
+	}
+	return offset + (v-offset+step.Increment-1)/step.Increment*step.Increment
+}
+
 // indexType returns the index type from its attribute.
 // The default type is BTREE if no type was specified.
 func indexType(attr []schema.Attr) *IndexType {
@@ -438,6 +602,9 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	if fromT == nil || toT == nil {
 		return false, fmt.Errorf("mysql: missing type information for column %q", from.Name)
 	}
+	if changed, ok, err := typeDefs.Changed(fromT, toT); ok {
+		return changed, err
+	}
 	if reflect.TypeOf(fromT) != reflect.TypeOf(toT) {
 		return true, nil
 	}
@@ -459,20 +626,18 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 		changed = !sqlx.ValuesEqual(fromT.Values, toT.Values)
 	case *schema.IntegerType:
 		toT := toT.(*schema.IntegerType)
-		// MySQL v8.0.19 dropped both display-width
-		// and zerofill from the information schema.
-		if d.SupportsDisplayWidth() {
-			ft, _, _, err := parseColumn(fromT.T)
-			if err != nil {
-				return false, err
-			}
-			tt, _, _, err := parseColumn(toT.T)
-			if err != nil {
-				return false, err
-			}
-			fromT.T, toT.T = ft[0], tt[0]
+		// The display-width does not affect the range of values the column can
+		// store, and MySQL v8.0.19 dropped both display-width and zerofill from
+		// the information schema. Hence, only the canonical types are compared.
+		ft, _, _, err := parseColumn(fromT.T)
+		if err != nil {
+			return false, err
+		}
+		tt, _, _, err := parseColumn(toT.T)
+		if err != nil {
+			return false, err
 		}
-		changed = fromT.T != toT.T || fromT.Unsigned != toT.Unsigned
+		changed = numericAlias(ft[0]) != numericAlias(tt[0]) || fromT.Unsigned != toT.Unsigned
 	case *SetType:
 		toT := toT.(*SetType)
 		changed = !sqlx.ValuesEqual(fromT.Values, toT.Values)
@@ -489,7 +654,7 @@ func (d *diff) defaultChanged(from, to *schema.Column) (bool, error) {
 	if ok1 != ok2 {
 		return true, nil
 	}
-	if d1 == d2 {
+	if d1 == d2 || defaultEquivs.Equal(d1, d2) {
 		return false, nil
 	}
 	switch from.Type.Type.(type) {
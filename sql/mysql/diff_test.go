@@ -5,6 +5,9 @@
 package mysql
 
 import (
+	"context"
+	"strconv"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/schema"
@@ -48,6 +51,22 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "modify counter aligned to step",
+			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&AutoIncrement{V: 1}}},
+			to:   &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public", Attrs: []schema.Attr{&AutoIncrementStep{Increment: 10, Offset: 3}}}, Attrs: []schema.Attr{&AutoIncrement{V: 100}}},
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &AutoIncrement{V: 1},
+					To:   &AutoIncrement{V: 103},
+				},
+			},
+		},
+		{
+			name: "counter already aligned to step",
+			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&AutoIncrement{V: 103}}},
+			to:   &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public", Attrs: []schema.Attr{&AutoIncrementStep{Increment: 10, Offset: 3}}}, Attrs: []schema.Attr{&AutoIncrement{V: 100}}},
+		},
 		// Attributes are specified and the same.
 		{
 			name: "no engine changes",
@@ -381,6 +400,62 @@ func TestDiff_TableDiff(t *testing.T) {
 				to:   to,
 			}
 		}(),
+		// Collations inherited from the schema or server.
+		func() testcase {
+			var (
+				s = schema.New("public").
+					SetCharset("utf8mb4").
+					SetCollation("utf8mb4_bin").
+					SetRealm(schema.NewRealm().SetCollation("latin1_swedish_ci"))
+				from = schema.NewTable("t1").
+					SetSchema(s).
+					AddColumns(
+						schema.NewStringColumn("c1", "text").SetCollation("utf8mb4_bin"),
+						schema.NewStringColumn("c2", "text"),
+					)
+				to = schema.NewTable("t1").
+					SetSchema(s).
+					SetCollation("utf8mb4_bin").
+					AddColumns(
+						schema.NewStringColumn("c1", "text"),
+						schema.NewStringColumn("c2", "text").SetCollation("utf8mb4_bin"),
+					)
+			)
+			return testcase{
+				name: "inherited collation",
+				from: from,
+				to:   to,
+			}
+		}(),
+		func() testcase {
+			var (
+				r    = schema.NewRealm().SetCollation("latin1_swedish_ci")
+				from = schema.NewTable("t1").
+					SetSchema(schema.New("public").SetRealm(r)).
+					AddColumns(
+						schema.NewStringColumn("c1", "text").SetCollation("latin1_bin"),
+						schema.NewStringColumn("c2", "text"),
+					)
+				to = schema.NewTable("t1").
+					SetSchema(schema.New("public")).
+					AddColumns(
+						schema.NewStringColumn("c1", "text"),
+						schema.NewStringColumn("c2", "text").SetCollation("latin1_swedish_ci"),
+					)
+			)
+			return testcase{
+				name: "server collation",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{
+						From:   from.Columns[0],
+						To:     to.Columns[0],
+						Change: schema.ChangeCollate,
+					},
+				},
+			}
+		}(),
 		func() testcase {
 			var (
 				s    = schema.New("public")
@@ -621,7 +696,7 @@ func TestDiff_UnsupportedChecks(t *testing.T) {
 		schema.NewTable("t").SetSchema(s).AddChecks(schema.NewCheck()),
 	)
 	require.Nil(t, changes)
-	require.EqualError(t, err, `version "5.6.35" does not support CHECK constraints`)
+	require.EqualError(t, err, `version "5.6.35" does not support CHECK constraints (requires MySQL >= 8.0.16)`)
 }
 
 func TestDiff_SchemaDiff(t *testing.T) {
@@ -876,3 +951,289 @@ func TestSkipChanges(t *testing.T) {
 		require.IsType(t, &schema.DropColumn{}, changes[0].(*schema.ModifyTable).Changes[0])
 	})
 }
+
+func TestDiff_CreateOptions(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.19")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	from := schema.NewTable("t").SetSchema(schema.New("public")).AddAttrs(&CreateOptions{V: `row_format=COMPRESSED KEY_BLOCK_SIZE=8 stats_persistent=0`})
+	to := schema.NewTable("t").SetSchema(schema.New("public")).AddAttrs(&CreateOptions{V: `ROW_FORMAT=compressed key_block_size=8`})
+	changes, err := drv.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes, "options that were not defined in the desired state are ignored")
+
+	to.Attrs[0].(*CreateOptions).V = `ROW_FORMAT=DYNAMIC`
+	changes, err = drv.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyAttr{From: from.Attrs[0], To: to.Attrs[0]}}, changes)
+
+	changes, err = drv.TableDiff(schema.NewTable("t").SetSchema(schema.New("public")), to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.AddAttr{A: to.Attrs[0]}}, changes)
+	require.Equal(t, map[string]string{"COMPRESSION": `"ZLIB A"`, "STATS_PERSISTENT": "0"}, createOptions(`COMPRESSION="ZLIB A" stats_persistent=0`))
+}
+
+func TestDiff_DefaultEquivalents(t *testing.T) {
+	for _, tt := range []struct{ from, to string }{
+		{"CURRENT_TIMESTAMP", "now()"},
+		{"CURRENT_TIMESTAMP(6)", "NOW(6)"},
+		{"curdate()", "CURRENT_DATE"},
+	} {
+		from := schema.NewTable("users").SetSchema(schema.New("public")).
+			AddColumns(schema.NewTimeColumn("c", "datetime").SetDefault(&schema.RawExpr{X: tt.from}))
+		to := schema.NewTable("users").SetSchema(schema.New("public")).
+			AddColumns(schema.NewTimeColumn("c", "datetime").SetDefault(&schema.RawExpr{X: tt.to}))
+		changes, err := DefaultDiff.TableDiff(from, to)
+		require.NoError(t, err)
+		require.Empty(t, changes, "%s = %s", tt.from, tt.to)
+	}
+	require.False(t, defaultEquivs.Equal("CURRENT_TIMESTAMP(3)", "now(6)"))
+}
+
+func TestDiff_NumericAliases(t *testing.T) {
+	for _, tt := range []struct {
+		from, to schema.Type
+		changed  bool
+	}{
+		{from: &schema.IntegerType{T: "int(11)"}, to: &schema.IntegerType{T: "int"}},
+		{from: &schema.IntegerType{T: "int"}, to: &schema.IntegerType{T: "INTEGER"}},
+		{from: &schema.IntegerType{T: "int8"}, to: &schema.IntegerType{T: "bigint(20)"}},
+		{from: &schema.IntegerType{T: "int"}, to: &schema.IntegerType{T: "bigint"}, changed: true},
+		{from: &schema.IntegerType{T: "int"}, to: &schema.IntegerType{T: "int", Unsigned: true}, changed: true},
+		{from: &schema.DecimalType{T: "decimal", Precision: 10}, to: &schema.DecimalType{T: "numeric"}},
+		{from: &schema.DecimalType{T: "decimal", Precision: 10, Scale: 2}, to: &schema.DecimalType{T: "fixed", Precision: 10, Scale: 2}},
+		{from: &schema.DecimalType{T: "decimal", Precision: 10, Scale: 2}, to: &schema.DecimalType{T: "dec", Precision: 10, Scale: 4}, changed: true},
+		{from: &schema.FloatType{T: "double"}, to: &schema.FloatType{T: "double precision"}},
+		{from: &schema.FloatType{T: "double"}, to: &schema.FloatType{T: "float", Precision: 53}},
+		{from: &schema.FloatType{T: "float"}, to: &schema.FloatType{T: "float4"}},
+		{from: &schema.FloatType{T: "float"}, to: &schema.FloatType{T: "double"}, changed: true},
+	} {
+		from := schema.NewTable("users").SetSchema(schema.New("public")).
+			AddColumns(schema.NewColumn("c").SetType(tt.from))
+		to := schema.NewTable("users").SetSchema(schema.New("public")).
+			AddColumns(schema.NewColumn("c").SetType(tt.to))
+		changes, err := DefaultDiff.TableDiff(from, to)
+		require.NoError(t, err)
+		require.Equal(t, tt.changed, len(changes) == 1, "%+v -> %+v", tt.from, tt.to)
+
+		// Exact spelling reports all alias changes.
+		changes, err = DefaultDiff.TableDiff(from, to, schema.DiffExactTypes())
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.True(t, changes[0].(*schema.ModifyColumn).Change.Is(schema.ChangeType))
+	}
+}
+
+func TestDiff_RegisteredType(t *testing.T) {
+	require.NoError(t, RegisterType(&schema.TypeDef{Name: "vector"}))
+	typ, err := ParseType("VECTOR(3)")
+	require.NoError(t, err)
+	require.Equal(t, &schema.UnsupportedType{T: "VECTOR(3)"}, typ)
+	f, err := FormatType(typ)
+	require.NoError(t, err)
+	require.Equal(t, "VECTOR(3)", f)
+
+	from := schema.NewTable("items").SetSchema(schema.New("public")).
+		AddColumns(schema.NewColumn("c").SetType(typ))
+	to := schema.NewTable("items").SetSchema(schema.New("public")).
+		AddColumns(schema.NewColumn("c").SetType(&schema.UnsupportedType{T: "vector(3)"}))
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	to.Columns[0].Type.Type = &schema.UnsupportedType{T: "vector(8)"}
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+}
+
+type testResolver struct {
+	tables, columns map[string]string
+	candidates      map[string][]float64
+	// called on each resolution.
+	onResolve func()
+}
+
+func (r *testResolver) ResolveTable(from *schema.Table, cs []*schema.TableCandidate) (*schema.Table, error) {
+	if r.onResolve != nil {
+		r.onResolve()
+	}
+	for _, c := range cs {
+		r.candidates[from.Name] = append(r.candidates[from.Name], c.Score)
+		if r.tables[from.Name] == c.T.Name {
+			return c.T, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *testResolver) ResolveColumn(_ *schema.Table, from *schema.Column, cs []*schema.ColumnCandidate) (*schema.Column, error) {
+	if r.onResolve != nil {
+		r.onResolve()
+	}
+	for _, c := range cs {
+		r.candidates[from.Name] = append(r.candidates[from.Name], c.Score)
+		if r.columns[from.Name] == c.C.Name {
+			return c.C, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestDiff_Resolver(t *testing.T) {
+	var (
+		from = schema.New("test").AddTables(
+			schema.NewTable("users").AddColumns(
+				schema.NewIntColumn("id", "int"),
+				schema.NewStringColumn("name", "varchar(255)"),
+				schema.NewIntColumn("age", "int"),
+			),
+		)
+		to = schema.New("test").AddTables(
+			schema.NewTable("accounts").AddColumns(
+				schema.NewIntColumn("id", "int"),
+				schema.NewStringColumn("full_name", "varchar(255)"),
+				schema.NewIntColumn("years", "bigint"),
+			),
+		)
+		r = &testResolver{
+			tables:     map[string]string{"users": "accounts"},
+			columns:    map[string]string{"name": "full_name", "age": "years"},
+			candidates: make(map[string][]float64),
+		}
+	)
+	// The current state is not modified during the diff.
+	unchanged := func() {
+		require.Equal(t, "users", from.Tables[0].Name)
+		require.Equal(t, "name", from.Tables[0].Columns[1].Name)
+		require.Equal(t, "age", from.Tables[0].Columns[2].Name)
+	}
+	r.onResolve = unchanged
+	changes, err := DefaultDiff.SchemaDiff(from, to, schema.DiffResolver(r))
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.Equal(t, &schema.RenameTable{From: from.Tables[0], To: to.Tables[0]}, changes[0])
+	require.Equal(t, "users", changes[0].(*schema.RenameTable).From.Name)
+	renames := changes[1].(*schema.ModifyTable).Changes
+	require.Equal(t, []schema.Change{
+		&schema.RenameColumn{From: from.Tables[0].Columns[1], To: to.Tables[0].Columns[1]},
+		&schema.RenameColumn{From: from.Tables[0].Columns[2], To: to.Tables[0].Columns[2]},
+	}, renames)
+	require.Equal(t, "name", renames[0].(*schema.RenameColumn).From.Name)
+	modify := changes[2].(*schema.ModifyTable)
+	require.Equal(t, to.Tables[0], modify.T)
+	require.Len(t, modify.Changes, 1)
+	require.Equal(t, to.Tables[0].Columns[2], modify.Changes[0].(*schema.ModifyColumn).To)
+	require.True(t, modify.Changes[0].(*schema.ModifyColumn).Change.Is(schema.ChangeType))
+
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, "RENAME TABLE `test`.`users` TO `test`.`accounts`", plan.Changes[0].Cmd)
+	require.Equal(t, "ALTER TABLE `test`.`accounts` RENAME COLUMN `name` TO `full_name`, RENAME COLUMN `age` TO `years`", plan.Changes[1].Cmd)
+	require.Equal(t, "ALTER TABLE `test`.`accounts` MODIFY COLUMN `years` bigint NOT NULL", plan.Changes[2].Cmd)
+
+	// Candidates are sorted by their similarity.
+	require.Equal(t, []float64{1.0 / 3}, r.candidates["users"])
+	require.InDelta(t, 1, r.candidates["name"][0], 1e-9)
+	require.Len(t, r.candidates["age"], 1)
+	require.InDelta(t, 0.4, r.candidates["age"][0], 1e-9)
+
+	unchanged()
+
+	// Dropped and added when the resolver does not choose a candidate.
+	r = &testResolver{candidates: make(map[string][]float64)}
+	changes, err = DefaultDiff.SchemaDiff(from, to, schema.DiffResolver(r))
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.IsType(t, &schema.DropTable{}, changes[0])
+	require.IsType(t, &schema.AddTable{}, changes[1])
+	require.Len(t, r.candidates["users"], 1)
+
+	// Resolver is not called when there are no candidates.
+	r = &testResolver{candidates: make(map[string][]float64)}
+	changes, err = DefaultDiff.TableDiff(from.Tables[0], schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")), schema.DiffResolver(r))
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Empty(t, r.candidates)
+}
+
+func TestDiff_ColumnPosition(t *testing.T) {
+	table := func(cs ...*schema.Column) *schema.Table {
+		return schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(cs...)
+	}
+	from := table(schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int"), schema.NewIntColumn("c", "int"))
+	to := table(schema.NewIntColumn("c", "int"), schema.NewIntColumn("b", "bigint"), schema.NewIntColumn("a", "int"))
+	// Column order is ignored by default.
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, schema.ChangeType, changes[0].(*schema.ModifyColumn).Change)
+
+	changes, err = DefaultDiff.TableDiff(from, to, schema.DiffColumnOrder())
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	// Moved columns are reported in their desired order.
+	require.Equal(t, "c", changes[0].(*schema.ModifyColumn).To.Name)
+	require.Equal(t, schema.ChangePosition, changes[0].(*schema.ModifyColumn).Change)
+	require.Equal(t, "b", changes[1].(*schema.ModifyColumn).To.Name)
+	require.Equal(t, schema.ChangeType|schema.ChangePosition, changes[1].(*schema.ModifyColumn).Change)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: to, Changes: changes}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, "ALTER TABLE `public`.`users` MODIFY COLUMN `c` int NOT NULL FIRST, MODIFY COLUMN `b` bigint NOT NULL AFTER `c`", plan.Changes[0].Cmd)
+	require.Empty(t, plan.Changes[0].Reverse, "original positions are unknown")
+
+	// Moved columns are placed after existing columns, and added columns after their preceding column.
+	to = table(schema.NewIntColumn("a", "int"), schema.NewIntColumn("n", "int"), schema.NewIntColumn("c", "int"), schema.NewIntColumn("b", "int"), schema.NewIntColumn("m", "int"))
+	changes, err = DefaultDiff.TableDiff(from, to, schema.DiffColumnOrder())
+	require.NoError(t, err)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: to, Changes: changes}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, "ALTER TABLE `public`.`users` MODIFY COLUMN `c` int NOT NULL AFTER `a`, ADD COLUMN `n` int NOT NULL AFTER `a`, ADD COLUMN `m` int NOT NULL", plan.Changes[0].Cmd)
+
+	// By default, added columns are appended to the end of the table.
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: to, Changes: changes}})
+	require.NoError(t, err)
+	require.Equal(t, "ALTER TABLE `public`.`users` ADD COLUMN `n` int NOT NULL, ADD COLUMN `m` int NOT NULL", plan.Changes[0].Cmd)
+
+	// Columns that keep their relative order are not moved.
+	to = table(schema.NewIntColumn("n", "int"), schema.NewIntColumn("a", "int"), schema.NewIntColumn("c", "int"))
+	changes, err = DefaultDiff.TableDiff(from, to, schema.DiffColumnOrder())
+	require.NoError(t, err)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: to, Changes: changes}})
+	require.NoError(t, err)
+	require.Equal(t, "ALTER TABLE `public`.`users` DROP COLUMN `b`, ADD COLUMN `n` int NOT NULL FIRST", plan.Changes[0].Cmd)
+	require.Equal(t, "ALTER TABLE `public`.`users` DROP COLUMN `n`, ADD COLUMN `b` int NOT NULL", plan.Changes[0].Reverse)
+}
+
+func TestDiff_IndexExprCanonical(t *testing.T) {
+	table := func(xs ...string) *schema.Table {
+		t := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewStringColumn("name", "varchar(255)"))
+		for i, x := range xs {
+			t.AddIndexes(schema.NewIndex("idx" + strconv.Itoa(i)).AddExprs(&schema.RawExpr{X: x}))
+		}
+		return t
+	}
+	// Inspected expressions on the left, and user-written expressions on the right.
+	changes, err := DefaultDiff.TableDiff(
+		table("(lower(`name`))", "(concat(`name`,_utf8mb4'x'))", "ucase(`name`)", "(`name` + 1)"),
+		table("LOWER(name)", "concat(name, 'x')", "upper(name)", "name + 2"),
+	)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, "idx2", changes[0].(*schema.ModifyIndex).To.Name)
+	require.Equal(t, "idx3", changes[1].(*schema.ModifyIndex).To.Name)
+
+	// Custom functions.
+	RegisterIndexExprFuncs(func(x string) string {
+		return strings.ReplaceAll(x, "ucase(", "upper(")
+	})
+	changes, err = DefaultDiff.TableDiff(table("ucase(`name`)"), table("upper(name)"))
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
@@ -34,6 +34,9 @@ type (
 		schema.ExecQuerier
 		// The schema was set in the path (schema connection).
 		schema string
+		// The databases were set in the path (realm connection
+		// that is limited to these databases), if more than one.
+		databases []string
 		// System variables that are set on `Open`.
 		mysqlversion.V
 		collate string
@@ -82,9 +85,9 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 	}, nil
 }
 
-func opener(_ context.Context, u *url.URL) (*sqlclient.Client, error) {
+func opener(ctx context.Context, u *url.URL) (*sqlclient.Client, error) {
 	ur := parser{}.ParseURL(u)
-	db, err := sql.Open(DriverName, ur.DSN)
+	db, err := sqlclient.OpenSQL(ctx, u)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +99,7 @@ func opener(_ context.Context, u *url.URL) (*sqlclient.Client, error) {
 		return nil, err
 	}
 	drv.(*Driver).schema = ur.Schema
+	drv.(*Driver).databases = ur.Schemas
 	return &sqlclient.Client{
 		Name:   DriverName,
 		DB:     db,
@@ -114,6 +118,24 @@ func (d *Driver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*schema
 	return (&sqlx.DevDriver{Driver: d}).NormalizeSchema(ctx, s)
 }
 
+// InferViewColumns implements the schema.ViewInferrer interface.
+func (d *Driver) InferViewColumns(ctx context.Context, r *schema.Realm) error {
+	return (&sqlx.DevDriver{Driver: d}).InferViewColumns(ctx, r, &sqlx.Builder{QuoteOpening: '`', QuoteClosing: '`'}, viewColumnType)
+}
+
+// viewColumnType converts the type of a view column, as reported by the database driver,
+// to a schema type. Unsigned types are reported with a prefix (e.g. UNSIGNED INT).
+func viewColumnType(ct *sql.ColumnType) (schema.Type, error) {
+	t := strings.ToLower(ct.DatabaseTypeName())
+	if n, ok := strings.CutPrefix(t, "unsigned "); ok {
+		t = n + " unsigned"
+	}
+	if p, s, ok := ct.DecimalSize(); ok && p > 0 && strings.HasPrefix(t, TypeDecimal) {
+		t = fmt.Sprintf("%s(%d,%d)%s", TypeDecimal, p, s, strings.TrimPrefix(t, TypeDecimal))
+	}
+	return ParseType(t)
+}
+
 // Lock implements the schema.Locker interface.
 func (d *Driver) Lock(ctx context.Context, name string, timeout time.Duration) (schema.UnlockFunc, error) {
 	conn, err := sqlx.SingleConn(ctx, d.ExecQuerier)
@@ -126,7 +148,8 @@ func (d *Driver) Lock(ctx context.Context, name string, timeout time.Duration) (
 	}
 	return func() error {
 		defer conn.Close()
-		rows, err := conn.QueryContext(ctx, "SELECT RELEASE_LOCK(?)", name)
+		// Locks are released also if the context was canceled (e.g. an aborted execution).
+		rows, err := conn.QueryContext(sqlx.WithoutCancel(ctx), "SELECT RELEASE_LOCK(?)", name)
 		if err != nil {
 			return err
 		}
@@ -217,6 +240,69 @@ func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error
 	return nil
 }
 
+// SwapSchemas implements migrate.SchemaSwapper. MySQL does not support renaming
+// databases. Hence, the tables of the two schemas are moved through a temporary
+// schema using a single RENAME TABLE statement, that is executed atomically.
+// Schemas that contain views are not supported.
+func (d *Driver) SwapSchemas(ctx context.Context, a, b string) error {
+	rows, err := d.QueryContext(ctx, "SELECT `TABLE_SCHEMA`, `TABLE_NAME`, `TABLE_TYPE` FROM `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA` IN (?, ?) ORDER BY `TABLE_NAME`", a, b)
+	if err != nil {
+		return fmt.Errorf("sql/mysql: query schema tables: %w", err)
+	}
+	tables := make(map[string][]string)
+	for rows.Next() {
+		var s, name, typ string
+		if err := rows.Scan(&s, &name, &typ); err != nil {
+			rows.Close()
+			return fmt.Errorf("sql/mysql: scan schema tables: %w", err)
+		}
+		if typ == "VIEW" {
+			rows.Close()
+			return fmt.Errorf("sql/mysql: swapping schemas with views is not supported, found view %q in schema %q", name, s)
+		}
+		tables[s] = append(tables[s], name)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	var (
+		renames []string
+		b1      = &sqlx.Builder{QuoteOpening: '`', QuoteClosing: '`'}
+		q       = func(s, t string) string { return b1.Clone().Ident(s).String() + "." + b1.Clone().Ident(t).String() }
+		tmp     = "atlas_swap_" + a
+	)
+	for _, t := range tables[a] {
+		renames = append(renames, q(a, t)+" TO "+q(tmp, t))
+	}
+	for _, t := range tables[b] {
+		renames = append(renames, q(b, t)+" TO "+q(a, t))
+	}
+	for _, t := range tables[a] {
+		renames = append(renames, q(tmp, t)+" TO "+q(b, t))
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+	if _, err := d.ExecContext(ctx, b1.Clone().P("CREATE DATABASE").Ident(tmp).String()); err != nil {
+		return fmt.Errorf("sql/mysql: create temporary schema: %w", err)
+	}
+	// The temporary schema is dropped also if the swap failed, as
+	// the statement is atomic and no table was moved to it.
+	_, err = d.ExecContext(ctx, "RENAME TABLE "+strings.Join(renames, ", "))
+	if _, err2 := d.ExecContext(ctx, b1.Clone().P("DROP DATABASE").Ident(tmp).String()); err == nil && err2 != nil {
+		return fmt.Errorf("sql/mysql: drop temporary schema: %w", err2)
+	}
+	if err != nil {
+		return fmt.Errorf("sql/mysql: swap schemas %q and %q: %w", a, b, err)
+	}
+	return nil
+}
+
+// QuoteIdent returns the given identifier quoted, using the builder of the driver.
+func (*Driver) QuoteIdent(s string) string {
+	return (&state{}).Build().Ident(s).String()
+}
+
 // Version returns the version of the connected database.
 func (d *Driver) Version() string {
 	return string(d.conn.V)
@@ -262,7 +348,15 @@ func (parser) ParseURL(u *url.URL) *sqlclient.URL {
 	v := u.Query()
 	v.Set("parseTime", "true")
 	u.RawQuery = v.Encode()
-	return &sqlclient.URL{URL: u, DSN: dsn(u), Schema: strings.TrimPrefix(u.Path, "/")}
+	path := strings.TrimPrefix(u.Path, "/")
+	if !strings.Contains(path, ",") || strings.HasSuffix(u.Scheme, "+unix") {
+		return &sqlclient.URL{URL: u, DSN: dsn(u), Schema: path}
+	}
+	// Multiple databases are set in the path (e.g. "/a,b"). The connection
+	// is not bound to any of them, but limited to them in realm mode.
+	nu := *u
+	nu.Path = ""
+	return &sqlclient.URL{URL: u, DSN: dsn(&nu), Schemas: strings.Split(path, ",")}
 }
 
 // ChangeSchema implements the sqlclient.SchemaChanger interface.
@@ -272,6 +366,21 @@ func (parser) ChangeSchema(u *url.URL, s string) *url.URL {
 	return &nu
 }
 
+// ChangeReadOnly implements the sqlclient.ReadOnlyChanger interface.
+func (parser) ChangeReadOnly(u *url.URL) *url.URL {
+	nu := *u
+	q := nu.Query()
+	// System variables that are not recognized by the
+	// driver are set on the session when it is opened.
+	if strings.HasPrefix(u.Scheme, "maria") {
+		q.Set("tx_read_only", "1")
+	} else {
+		q.Set("transaction_read_only", "1")
+	}
+	nu.RawQuery = q.Encode()
+	return &nu
+}
+
 // dsn returns the MySQL standard DSN for opening
 // the sql.DB from the user provided URL.
 func dsn(u *url.URL) string {
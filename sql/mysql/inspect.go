@@ -38,6 +38,16 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 				return nil, err
 			}
 			sqlx.LinkSchemaTables(schemas)
+			if opts.Stats {
+				if err := i.inspectStats(ctx, r); err != nil {
+					return nil, err
+				}
+			}
+			if opts.Triggers {
+				if err := i.inspectTriggers(ctx, r); err != nil {
+					return nil, err
+				}
+			}
 		}
 		if mode.Is(schema.InspectViews) {
 			if err := i.inspectViews(ctx, r, nil); err != nil {
@@ -45,7 +55,10 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 			}
 		}
 	}
-	return sqlx.ExcludeRealm(r, opts.Exclude)
+	if r, err = sqlx.ExcludeRealm(r, opts.Exclude); err != nil {
+		return nil, err
+	}
+	return r, schema.AnonymizeRealm(r, opts.Anonymize)
 }
 
 // InspectSchema returns schema descriptions of the tables in the given schema.
@@ -70,13 +83,27 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 			return nil, err
 		}
 		sqlx.LinkSchemaTables(schemas)
+		if opts.Stats {
+			if err := i.inspectStats(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Triggers {
+			if err := i.inspectTriggers(ctx, r); err != nil {
+				return nil, err
+			}
+		}
 	}
 	if sqlx.ModeInspectSchema(opts).Is(schema.InspectViews) {
 		if err := i.inspectViews(ctx, r, opts); err != nil {
 			return nil, err
 		}
 	}
-	return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
+	s, err := sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return s, schema.AnonymizeSchema(s, opts.Anonymize)
 }
 
 func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
@@ -112,6 +139,16 @@ func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption)
 		args  []any
 		query = schemasQuery
 	)
+	// Realm connections that are limited to a set of databases inspect only
+	// them. The options are copied to keep the rest of the caller options.
+	if (opts == nil || len(opts.Schemas) == 0) && len(i.databases) > 0 {
+		c := schema.InspectRealmOption{}
+		if opts != nil {
+			c = *opts
+		}
+		c.Schemas = i.databases
+		opts = &c
+	}
 	if opts != nil {
 		switch n := len(opts.Schemas); {
 		case n == 1 && opts.Schemas[0] == "":
@@ -204,9 +241,11 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 				Text: comment.String,
 			})
 		}
-		if sqlx.ValidString(options) {
+		// Partitioned tables are reported as such, but
+		// this option cannot be used in CREATE TABLE.
+		if opts := strings.TrimSpace(strings.Replace(options.String, "partitioned", "", 1)); opts != "" {
 			t.Attrs = append(t.Attrs, &CreateOptions{
-				V: options.String,
+				V: opts,
 			})
 		}
 		if sqlx.ValidString(engine) && defaultE.Valid {
@@ -792,6 +831,16 @@ type (
 		V int64
 	}
 
+	// AutoIncrementStep attribute describes the auto_increment_increment and auto_increment_offset
+	// system variables of the server, used in multi-master and sharded setups. It is defined on the
+	// schema, and aligns the AUTO_INCREMENT start values of its tables to the values the server
+	// generates, e.g. 1, 11, 21 for an increment of 10 and an offset of 1.
+	AutoIncrementStep struct {
+		schema.Attr
+		Increment int64 // auto_increment_increment.
+		Offset    int64 // auto_increment_offset.
+	}
+
 	// CreateOptions attribute for describing extra options used with CREATE TABLE.
 	CreateOptions struct {
 		schema.Attr
@@ -1004,6 +1004,51 @@ func TestDriver_Realm(t *testing.T) {
 	}(), realm)
 }
 
+func TestDriver_InspectRealmDatabases(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("8.0.13")
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "IN (?, ?)"))).
+		WithArgs("a", "b").
+		WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| a           | utf8                       | utf8_general_ci        |
+| b           | utf8                       | utf8_general_ci        |
++-------------+----------------------------+------------------------+
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	drv.(*Driver).databases = []string{"a", "b"}
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas})
+	require.NoError(t, err)
+	require.Len(t, realm.Schemas, 2)
+	require.Equal(t, "a", realm.Schemas[0].Name)
+	require.Equal(t, "b", realm.Schemas[1].Name)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	// Caller options are kept.
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "IN (?, ?)"))).
+		WithArgs("a", "b").
+		WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| a           | utf8                       | utf8_general_ci        |
+| b           | utf8                       | utf8_general_ci        |
++-------------+----------------------------+------------------------+
+`))
+	opts := &schema.InspectRealmOption{Mode: schema.InspectSchemas, Exclude: []string{"b"}}
+	realm, err = drv.InspectRealm(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, realm.Schemas, 1)
+	require.Equal(t, "a", realm.Schemas[0].Name)
+	require.Empty(t, opts.Schemas, "caller options are not modified")
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
 func TestInspectMode_InspectRealm(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
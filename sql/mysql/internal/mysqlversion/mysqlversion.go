@@ -23,17 +23,13 @@ type V string
 // SupportsCheck reports if the version supports the CHECK
 // clause, and return the querying for getting them.
 func (v V) SupportsCheck() bool {
-	u := "8.0.16"
-	if v.Maria() {
-		u = "10.2.1"
-	}
-	return v.GTE(u)
+	return v.Supports(FeatureCheck)
 }
 
 // SupportsIndexExpr reports if the version supports
 // index expressions (functional key part).
 func (v V) SupportsIndexExpr() bool {
-	return !v.Maria() && v.GTE("8.0.13")
+	return v.Supports(FeatureIndexExpr)
 }
 
 // SupportsDisplayWidth reports if the version supports getting
@@ -47,37 +43,25 @@ func (v V) SupportsDisplayWidth() bool {
 // SupportsExprDefault reports if the version supports
 // expressions in the DEFAULT clause on column definition.
 func (v V) SupportsExprDefault() bool {
-	u := "8.0.13"
-	if v.Maria() {
-		u = "10.2.1"
-	}
-	return v.GTE(u)
+	return v.Supports(FeatureExprDefault)
 }
 
 // SupportsEnforceCheck reports if the version supports
 // the ENFORCED option in CHECK constraint syntax.
 func (v V) SupportsEnforceCheck() bool {
-	return !v.Maria() && v.GTE("8.0.16")
+	return v.Supports(FeatureEnforceCheck)
 }
 
 // SupportsGeneratedColumns reports if the version supports
 // the generated columns in information schema.
 func (v V) SupportsGeneratedColumns() bool {
-	u := "5.7"
-	if v.Maria() {
-		u = "10.2"
-	}
-	return v.GTE(u)
+	return v.Supports(FeatureGeneratedColumns)
 }
 
 // SupportsRenameColumn reports if the version supports
 // the "RENAME COLUMN" clause.
 func (v V) SupportsRenameColumn() bool {
-	u := "8"
-	if v.Maria() {
-		u = "10.5.2"
-	}
-	return v.GTE(u)
+	return v.Supports(FeatureRenameColumn)
 }
 
 // SupportsIndexComment reports if the version
@@ -91,7 +75,62 @@ func (v V) SupportsIndexComment() bool {
 // SupportsViewUsage reports if the version supports
 // querying the VIEW_TABLE_USAGE table.
 func (v V) SupportsViewUsage() bool {
-	return !v.Maria() && v.GTE("8.0.13")
+	return v.Supports(FeatureViewUsage)
+}
+
+// A Feature is a server capability that is gated by its version.
+type Feature string
+
+// List of features that depend on the server version.
+const (
+	FeatureCheck            Feature = "CHECK constraints"
+	FeatureEnforceCheck     Feature = "the ENFORCED option of CHECK constraints"
+	FeatureIndexExpr        Feature = "functional key parts"
+	FeatureExprDefault      Feature = "expressions in the DEFAULT clause"
+	FeatureGeneratedColumns Feature = "generated columns"
+	FeatureRenameColumn     Feature = "the RENAME COLUMN clause"
+	FeatureViewUsage        Feature = "the VIEW_TABLE_USAGE table"
+	FeatureJSON             Feature = "the JSON type"
+	FeatureNGramParser      Feature = "the ngram full-text parser"
+)
+
+// features holds the minimum MySQL and MariaDB versions that support each feature.
+// An empty version means the feature is not supported by this flavor at all.
+var features = map[Feature]struct{ MySQL, Maria string }{
+	FeatureCheck:            {MySQL: "8.0.16", Maria: "10.2.1"},
+	FeatureEnforceCheck:     {MySQL: "8.0.16"},
+	FeatureIndexExpr:        {MySQL: "8.0.13"},
+	FeatureExprDefault:      {MySQL: "8.0.13", Maria: "10.2.1"},
+	FeatureGeneratedColumns: {MySQL: "5.7", Maria: "10.2"},
+	FeatureRenameColumn:     {MySQL: "8", Maria: "10.5.2"},
+	FeatureViewUsage:        {MySQL: "8.0.13"},
+	FeatureJSON:             {MySQL: "5.7.8", Maria: "10.2.7"},
+	FeatureNGramParser:      {MySQL: "5.7.6"},
+}
+
+// Supports reports if the version supports the given feature.
+func (v V) Supports(f Feature) bool {
+	return v.Require(f) == nil
+}
+
+// Require returns an error describing the minimum server version
+// required for the given feature, if it is not supported by v.
+func (v V) Require(f Feature) error {
+	m, ok := features[f]
+	if !ok {
+		return fmt.Errorf("unknown feature %q", f)
+	}
+	flavor, min := "MySQL", m.MySQL
+	if v.Maria() {
+		flavor, min = "MariaDB", m.Maria
+	}
+	switch {
+	case min == "":
+		return fmt.Errorf("%s does not support %s", flavor, f)
+	case v.LT(min):
+		return fmt.Errorf("version %q does not support %s (requires %s >= %s)", v, f, flavor, min)
+	}
+	return nil
 }
 
 // CharsetToCollate returns the mapping from charset to its default collation.
@@ -154,6 +193,45 @@ func (v V) TiDB() bool {
 	return strings.Index(string(v), "TiDB") > 0
 }
 
+// Aurora reports if the MySQL version is Amazon Aurora MySQL.
+// For example, 8.0.mysql_aurora.3.04.0.
+func (v V) Aurora() bool {
+	return strings.Contains(string(v), "mysql_aurora")
+}
+
+// auroraBase maps Aurora releases to the MySQL version they are based on,
+// as the version string holds only the major and minor MySQL versions.
+// Unknown releases fall back to the earliest base of their major version.
+func (v V) auroraBase() string {
+	u, idx := string(v), strings.Index(string(v), "mysql_aurora")
+	parts := strings.SplitN(u[idx+len("mysql_aurora."):], ".", 3)
+	switch {
+	case len(parts) < 2:
+	case parts[0] == "3":
+		switch minor := parts[1]; {
+		case minor >= "08":
+			return "8.0.39"
+		case minor >= "07":
+			return "8.0.36"
+		case minor >= "06":
+			return "8.0.34"
+		case minor >= "05":
+			return "8.0.32"
+		case minor >= "04":
+			return "8.0.28"
+		case minor >= "03":
+			return "8.0.26"
+		default:
+			return "8.0.23"
+		}
+	case parts[0] == "2":
+		return "5.7.12"
+	case parts[0] == "1":
+		return "5.6.10"
+	}
+	return u[:idx-1]
+}
+
 // Compare returns an integer comparing two versions according to
 // semantic version precedence.
 func (v V) Compare(w string) int {
@@ -163,6 +241,8 @@ func (v V) Compare(w string) int {
 		u = u[:strings.Index(u, "MariaDB")-1]
 	case v.TiDB():
 		u = u[:strings.Index(u, "TiDB")-1]
+	case v.Aurora():
+		u = v.auroraBase()
 	case idx > 0:
 		// Remove server build information, if any.
 		u = u[:idx]
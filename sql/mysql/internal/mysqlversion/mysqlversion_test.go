@@ -35,6 +35,43 @@ func TestV_SupportsGeneratedColumns(t *testing.T) {
 	}
 }
 
+func TestV_Require(t *testing.T) {
+	require.NoError(t, mysqlversion.V("8.0.16").Require(mysqlversion.FeatureCheck))
+	require.NoError(t, mysqlversion.V("10.2.1-MariaDB").Require(mysqlversion.FeatureCheck))
+	err := mysqlversion.V("5.7.40-0ubuntu0.18.04.1").Require(mysqlversion.FeatureCheck)
+	require.EqualError(t, err, `version "5.7.40-0ubuntu0.18.04.1" does not support CHECK constraints (requires MySQL >= 8.0.16)`)
+	err = mysqlversion.V("10.1.1-MariaDB").Require(mysqlversion.FeatureJSON)
+	require.EqualError(t, err, `version "10.1.1-MariaDB" does not support the JSON type (requires MariaDB >= 10.2.7)`)
+	err = mysqlversion.V("10.6.4-MariaDB").Require(mysqlversion.FeatureIndexExpr)
+	require.EqualError(t, err, "MariaDB does not support functional key parts")
+	require.False(t, mysqlversion.V("10.6.4-MariaDB").Supports(mysqlversion.FeatureIndexExpr))
+	require.True(t, mysqlversion.V("8.0.13").Supports(mysqlversion.FeatureIndexExpr))
+}
+
+func TestV_Aurora(t *testing.T) {
+	v := mysqlversion.V("8.0.mysql_aurora.3.04.0")
+	require.True(t, v.Aurora())
+	require.True(t, v.GTE("8.0"))
+	require.True(t, v.LT("8.1"))
+	require.True(t, v.GTE("8.0.16"))
+	require.True(t, v.GTE("8.0.28"))
+	require.True(t, v.LT("8.0.29"))
+	require.True(t, v.Supports(mysqlversion.FeatureCheck))
+	require.True(t, v.Supports(mysqlversion.FeatureIndexExpr))
+	require.True(t, v.SupportsEnforceCheck())
+
+	v = mysqlversion.V("8.0.mysql_aurora.3.02.2")
+	require.True(t, v.GTE("8.0.23"))
+	require.True(t, v.LT("8.0.26"))
+	require.True(t, v.Supports(mysqlversion.FeatureIndexExpr))
+
+	v = mysqlversion.V("5.7.mysql_aurora.2.11.2")
+	require.True(t, v.GTE("5.7.12"))
+	require.False(t, v.Supports(mysqlversion.FeatureCheck))
+	require.False(t, v.Supports(mysqlversion.FeatureIndexExpr))
+	require.False(t, mysqlversion.V("8.0.31").Aurora())
+}
+
 func TestV_CollateToCharset(t *testing.T) {
 	c2c, err := mysqlversion.V("8.0.0").CollateToCharset(nil)
 	require.NoError(t, err)
@@ -13,6 +13,7 @@ import (
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql/internal/mysqlversion"
 	"ariga.io/atlas/sql/schema"
 )
 
@@ -69,11 +70,23 @@ type state struct {
 // plan builds the migration plan for applying the
 // given changes on the attached connection.
 func (s *state) plan(changes []schema.Change) error {
+	changes, data := migrate.SplitDataChanges(changes)
 	if s.SchemaQualifier != nil {
 		if err := sqlx.CheckChangesScope(s.PlanOptions, changes); err != nil {
 			return err
 		}
 	}
+	if s.Quote == migrate.QuoteNever {
+		if err := sqlx.CheckUnquoted(changes, mustQuote); err != nil {
+			return err
+		}
+	}
+	if err := s.verifyFeatures(changes); err != nil {
+		return err
+	}
+	if err := s.verifyProfile(changes); err != nil {
+		return err
+	}
 	planned, err := s.topLevel(changes)
 	if err != nil {
 		return err
@@ -82,7 +95,7 @@ func (s *state) plan(changes []schema.Change) error {
 	if err != nil {
 		return err
 	}
-	var views []schema.Change
+	var views, triggers []schema.Change
 	for _, c := range planned {
 		switch c := c.(type) {
 		case *schema.AddTable:
@@ -95,6 +108,8 @@ func (s *state) plan(changes []schema.Change) error {
 			s.renameTable(c)
 		case *schema.AddView, *schema.DropView, *schema.ModifyView, *schema.RenameView:
 			views = append(views, c)
+		case *schema.AddTrigger, *schema.DropTrigger, *schema.ModifyTrigger:
+			triggers = append(triggers, c)
 		default:
 			err = fmt.Errorf("unsupported change %T", c)
 		}
@@ -117,7 +132,11 @@ func (s *state) plan(changes []schema.Change) error {
 			s.renameView(c)
 		}
 	}
-	return nil
+	// Triggers are planned after the tables and views they might reference.
+	if err := s.triggerChanges(triggers); err != nil {
+		return err
+	}
+	return s.planData(data)
 }
 
 // topLevel appends first the changes for creating or dropping schemas (top-level schema elements).
@@ -251,6 +270,7 @@ func (s *state) addTable(add *schema.AddTable) error {
 		if pk := add.T.PrimaryKey; pk != nil {
 			b.Comma().NL().P("PRIMARY KEY")
 			indexTypeParts(b, pk)
+			clustered(b, pk)
 		}
 		if len(add.T.Indexes) > 0 {
 			b.Comma()
@@ -306,6 +326,53 @@ func (s *state) dropTable(drop *schema.DropTable) error {
 	return nil
 }
 
+// addedColumns holds the names of the columns that are added by an ALTER TABLE command.
+type addedColumns map[string]bool
+
+func addedIn(changes []schema.Change) addedColumns {
+	added := make(addedColumns)
+	for _, c := range changes {
+		if a, ok := c.(*schema.AddColumn); ok {
+			added[a.C.Name] = true
+		}
+	}
+	return added
+}
+
+// appended reports if the added column is appended to the end of the table, i.e., only added
+// columns follow it in the desired table. Otherwise, the column requires a position clause.
+func (a addedColumns) appended(t *schema.Table, c *schema.Column) bool {
+	if _, ok := t.Column(c.Name); !ok {
+		return true
+	}
+	for i := len(t.Columns) - 1; t.Columns[i].Name != c.Name; i-- {
+		if !a[t.Columns[i].Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// position writes the FIRST or AFTER clause of the column based on its position in the desired
+// table. As added columns are placed after the modified ones, modified columns are placed after
+// the nearest preceding column that exists in the table.
+func (a addedColumns) position(b *sqlx.Builder, t *schema.Table, c *schema.Column) {
+	var after *schema.Column
+	for _, tc := range t.Columns {
+		if tc.Name == c.Name {
+			break
+		}
+		if a[c.Name] || !a[tc.Name] {
+			after = tc
+		}
+	}
+	if after == nil {
+		b.P("FIRST")
+	} else {
+		b.P("AFTER").Ident(after.Name)
+	}
+}
+
 // modifyTable builds and appends the migration changes for
 // bringing the table into its modified state.
 func (s *state) modifyTable(modify *schema.ModifyTable) error {
@@ -367,6 +434,7 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 		reversible = true
 	)
 	build := func(changes []schema.Change) (string, error) {
+		added := addedIn(changes)
 		b := s.Build("ALTER TABLE").Table(t)
 		err := b.MapCommaErr(changes, func(i int, b *sqlx.Builder) error {
 			switch change := changes[i].(type) {
@@ -375,6 +443,11 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				if err := s.column(b, t, change.C); err != nil {
 					return err
 				}
+				// Columns that are not appended to the end of the table are placed
+				// after their preceding column in the desired order, if requested.
+				if sqlx.Has(change.Extra, &schema.ColumnPosition{}) && !added.appended(t, change.C) {
+					added.position(b, t, change.C)
+				}
 				reverse = append(reverse, &schema.DropColumn{C: change.C})
 			case *schema.ModifyColumn:
 				if err := checkChangeGenerated(change.From, change.To); err != nil {
@@ -384,6 +457,11 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				if err := s.column(b, t, change.To); err != nil {
 					return err
 				}
+				if change.Change.Is(schema.ChangePosition) {
+					added.position(b, t, change.To)
+					// The original position of the column is unknown at this stage.
+					reversible = false
+				}
 				reverse = append(reverse, &schema.ModifyColumn{
 					From:   change.To,
 					To:     change.From,
@@ -415,6 +493,7 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 			case *schema.AddPrimaryKey:
 				b.P("ADD PRIMARY KEY")
 				indexTypeParts(b, change.P)
+				clustered(b, change.P)
 				reverse = append(reverse, &schema.DropPrimaryKey{P: change.P})
 			case *schema.DropPrimaryKey:
 				b.P("DROP PRIMARY KEY")
@@ -422,6 +501,7 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 			case *schema.ModifyPrimaryKey:
 				b.P("DROP PRIMARY KEY, ADD PRIMARY KEY")
 				indexTypeParts(b, change.To)
+				clustered(b, change.To)
 				reverse = append(reverse, &schema.ModifyPrimaryKey{From: change.To, To: change.From, Change: change.Change})
 			case *schema.AddForeignKey:
 				b.P("ADD")
@@ -576,6 +656,11 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 			if a.V > 0 && !sqlx.Has(t.Attrs, &AutoIncrement{}) {
 				t.Attrs = append(t.Attrs, a)
 			}
+		case *AutoRandom:
+			if !s.TiDB() {
+				return fmt.Errorf("column %q: AUTO_RANDOM is supported only by TiDB", c.Name)
+			}
+			b.P(a.String())
 		default:
 			s.attr(b, a)
 		}
@@ -670,8 +755,12 @@ func (s *state) tableAttr(b *sqlx.Builder, c schema.Change, attrs ...schema.Attr
 			b.P(a.V)
 		case *AutoIncrement:
 			// Update the AUTO_INCREMENT if it is a table modification, or it is not the default.
-			if _, ok := c.(*schema.ModifyAttr); ok || a.V > 1 {
-				b.P("AUTO_INCREMENT", strconv.FormatInt(a.V, 10))
+			v := a.V
+			if add, ok := c.(*schema.AddTable); ok {
+				v = autoIncStart(add.T, v)
+			}
+			if _, ok := c.(*schema.ModifyAttr); ok || v > 1 {
+				b.P("AUTO_INCREMENT", strconv.FormatInt(v, 10))
 			}
 		case *Engine:
 			// Update the ENGINE if it is a table modification, or it is not the default.
@@ -687,6 +776,23 @@ func (s *state) tableAttr(b *sqlx.Builder, c schema.Change, attrs ...schema.Attr
 			b.P("COLLATE", a.V)
 		case *schema.Comment:
 			b.P("COMMENT", quote(a.Text))
+		case *PlacementPolicy:
+			if a.V == "" {
+				b.P("PLACEMENT POLICY", "DEFAULT")
+			} else {
+				b.P("PLACEMENT POLICY").Ident(a.V)
+			}
+		}
+	}
+}
+
+// clustered writes the CLUSTERED option of TiDB primary keys to the builder, if it was set.
+func clustered(b *sqlx.Builder, pk *schema.Index) {
+	if c := (Clustered{}); sqlx.Has(pk.Attrs, &c) {
+		if c.V {
+			b.P("CLUSTERED")
+		} else {
+			b.P("NONCLUSTERED")
 		}
 	}
 }
@@ -711,8 +817,8 @@ func (s *state) collation(t *schema.Table) string {
 	return s.collate
 }
 
-func (s *state) append(c *migrate.Change) {
-	s.Changes = append(s.Changes, c)
+func (s *state) append(c ...*migrate.Change) {
+	s.Changes = append(s.Changes, c...)
 }
 
 func (*state) attr(b *sqlx.Builder, attrs ...schema.Attr) {
@@ -752,7 +858,14 @@ func (s *state) columnDefault(b *sqlx.Builder, c *schema.Column) {
 
 // Build instantiates a new builder and writes the given phrase to it.
 func (s *state) Build(phrases ...string) *sqlx.Builder {
-	b := &sqlx.Builder{QuoteOpening: '`', QuoteClosing: '`', Schema: s.SchemaQualifier, Indent: s.Indent}
+	b := &sqlx.Builder{
+		QuoteOpening: '`',
+		QuoteClosing: '`',
+		Schema:       s.SchemaQualifier,
+		Indent:       s.Indent,
+		QuotePolicy:  s.Quote,
+		MustQuote:    mustQuote,
+	}
 	return b.P(phrases...)
 }
 
@@ -797,6 +910,111 @@ func (s *state) check(b *sqlx.Builder, c *schema.Check) {
 	}
 }
 
+// verifyFeatures verifies the changes use only features that are supported by the
+// server version, and returns an error describing the required version otherwise.
+func (s *state) verifyFeatures(changes []schema.Change) error {
+	for _, c := range changes {
+		var (
+			t       *schema.Table
+			columns []*schema.Column
+			indexes []*schema.Index
+			checks  []*schema.Check
+		)
+		switch c := c.(type) {
+		case *schema.AddTable:
+			t, columns, indexes = c.T, c.T.Columns, c.T.Indexes
+			if c.T.PrimaryKey != nil {
+				indexes = append(indexes, c.T.PrimaryKey)
+			}
+			for _, a := range c.T.Attrs {
+				if ck, ok := a.(*schema.Check); ok {
+					checks = append(checks, ck)
+				}
+			}
+		case *schema.ModifyTable:
+			t = c.T
+			for _, c := range c.Changes {
+				switch c := c.(type) {
+				case *schema.AddColumn:
+					columns = append(columns, c.C)
+				case *schema.ModifyColumn:
+					columns = append(columns, c.To)
+				case *schema.AddIndex:
+					indexes = append(indexes, c.I)
+				case *schema.ModifyIndex:
+					indexes = append(indexes, c.To)
+				case *schema.AddPrimaryKey:
+					indexes = append(indexes, c.P)
+				case *schema.ModifyPrimaryKey:
+					indexes = append(indexes, c.To)
+				case *schema.AddCheck:
+					checks = append(checks, c.C)
+				case *schema.ModifyCheck:
+					checks = append(checks, c.To)
+				}
+			}
+		default:
+			continue
+		}
+		for _, c := range columns {
+			if c.Type != nil {
+				if _, ok := c.Type.Type.(*schema.JSONType); ok {
+					if err := s.Require(mysqlversion.FeatureJSON); err != nil {
+						return fmt.Errorf("column %q of table %q: %w", c.Name, t.Name, err)
+					}
+				}
+			}
+			if sqlx.Has(c.Attrs, &schema.GeneratedExpr{}) {
+				if err := s.Require(mysqlversion.FeatureGeneratedColumns); err != nil {
+					return fmt.Errorf("column %q of table %q: %w", c.Name, t.Name, err)
+				}
+			}
+		}
+		for _, idx := range indexes {
+			if err := s.verifyFullText(idx); err != nil {
+				return fmt.Errorf("index %q of table %q: %w", idx.Name, t.Name, err)
+			}
+			for _, p := range idx.Parts {
+				if p.X == nil {
+					continue
+				}
+				if err := s.Require(mysqlversion.FeatureIndexExpr); err != nil {
+					return fmt.Errorf("index %q of table %q: %w", idx.Name, t.Name, err)
+				}
+			}
+		}
+		if len(checks) > 0 {
+			if err := s.Require(mysqlversion.FeatureCheck); err != nil {
+				return fmt.Errorf("table %q: %w", t.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// verifyFullText verifies the FULLTEXT options of the index are valid.
+func (s *state) verifyFullText(idx *schema.Index) error {
+	p := &IndexParser{}
+	if indexType(idx.Attrs).T != IndexTypeFullText {
+		if sqlx.Has(idx.Attrs, p) {
+			return fmt.Errorf("parser %q is supported only by FULLTEXT indexes", p.P)
+		}
+		return nil
+	}
+	for _, part := range idx.Parts {
+		switch {
+		case part.X != nil:
+			return errors.New("FULLTEXT indexes do not support functional key parts")
+		case sqlx.Has(part.Attrs, &SubPart{}):
+			return errors.New("FULLTEXT indexes do not support index prefixes")
+		}
+	}
+	if sqlx.Has(idx.Attrs, p) && strings.EqualFold(p.P, IndexParserNGram) {
+		return s.Require(mysqlversion.FeatureNGramParser)
+	}
+	return nil
+}
+
 // supportsCharset reports if the given type supports the CHARSET and COLLATE
 // clauses. See: https://dev.mysql.com/doc/refman/8.0/en/charset-column.html
 func supportsCharset(t schema.Type) bool {
@@ -593,6 +593,26 @@ func TestPlanChanges(t *testing.T) {
 				Changes:    []*migrate.Change{{Cmd: "CREATE TABLE `posts` (`id` bigint NOT NULL AUTO_INCREMENT, `text` text NULL, PRIMARY KEY (`id`)) AUTO_INCREMENT 10", Reverse: "DROP TABLE `posts`"}},
 			},
 		},
+		{
+			changes: []schema.Change{
+				func() *schema.AddTable {
+					t := &schema.Table{
+						Name: "posts",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&AutoIncrement{}}},
+						},
+						Attrs: []schema.Attr{&AutoIncrement{V: 11}},
+					}
+					t.PrimaryKey = &schema.Index{Parts: []*schema.IndexPart{{C: t.Columns[0]}}}
+					schema.New("test").AddAttrs(&AutoIncrementStep{Increment: 4, Offset: 2}).AddTables(t)
+					return &schema.AddTable{T: t}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes:    []*migrate.Change{{Cmd: "CREATE TABLE `test`.`posts` (`id` bigint NOT NULL AUTO_INCREMENT, PRIMARY KEY (`id`)) AUTO_INCREMENT 14", Reverse: "DROP TABLE `test`.`posts`"}},
+			},
+		},
 		{
 			changes: []schema.Change{
 				&schema.DropTable{T: schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "bigint"))},
@@ -1113,6 +1133,126 @@ func TestPlanChanges(t *testing.T) {
 	}
 }
 
+func TestPlanChanges_Features(t *testing.T) {
+	tests := []struct {
+		version string
+		change  schema.Change
+		wantErr string
+	}{
+		{
+			version: "5.7.40",
+			change: &schema.AddTable{
+				T: schema.NewTable("t").AddColumns(schema.NewIntColumn("a", "int")).AddChecks(schema.NewCheck().SetExpr("a > 0")),
+			},
+			wantErr: `table "t": version "5.7.40" does not support CHECK constraints (requires MySQL >= 8.0.16)`,
+		},
+		{
+			version: "8.0.12",
+			change: &schema.ModifyTable{
+				T: schema.NewTable("t"),
+				Changes: []schema.Change{
+					&schema.AddIndex{I: schema.NewIndex("idx").AddExprs(&schema.RawExpr{X: "(a + 1)"})},
+				},
+			},
+			wantErr: `index "idx" of table "t": version "8.0.12" does not support functional key parts (requires MySQL >= 8.0.13)`,
+		},
+		{
+			version: "10.6.4-MariaDB",
+			change: &schema.ModifyTable{
+				T: schema.NewTable("t"),
+				Changes: []schema.Change{
+					&schema.AddIndex{I: schema.NewIndex("idx").AddExprs(&schema.RawExpr{X: "(a + 1)"})},
+				},
+			},
+			wantErr: `index "idx" of table "t": MariaDB does not support functional key parts`,
+		},
+		{
+			version: "5.6.35",
+			change: &schema.AddTable{
+				T: schema.NewTable("t").AddColumns(schema.NewJSONColumn("a", "json")),
+			},
+			wantErr: `column "a" of table "t": version "5.6.35" does not support the JSON type (requires MySQL >= 5.7.8)`,
+		},
+		{
+			version: "8.0.16",
+			change: &schema.AddTable{
+				T: schema.NewTable("t").AddColumns(schema.NewJSONColumn("a", "json")).AddChecks(schema.NewCheck().SetExpr("a > 0")),
+			},
+		},
+		{
+			version: "5.7.5",
+			change: &schema.ModifyTable{
+				T: schema.NewTable("t"),
+				Changes: []schema.Change{
+					&schema.AddIndex{I: schema.NewIndex("idx").AddColumns(schema.NewStringColumn("a", "text")).AddAttrs(&IndexType{T: IndexTypeFullText}, &IndexParser{P: "ngram"})},
+				},
+			},
+			wantErr: `index "idx" of table "t": version "5.7.5" does not support the ngram full-text parser (requires MySQL >= 5.7.6)`,
+		},
+		{
+			version: "10.6.4-MariaDB",
+			change: &schema.ModifyTable{
+				T: schema.NewTable("t"),
+				Changes: []schema.Change{
+					&schema.AddIndex{I: schema.NewIndex("idx").AddColumns(schema.NewStringColumn("a", "text")).AddAttrs(&IndexParser{P: "ngram"})},
+				},
+			},
+			wantErr: `index "idx" of table "t": parser "ngram" is supported only by FULLTEXT indexes`,
+		},
+		{
+			version: "8.0.31",
+			change: &schema.ModifyTable{
+				T: schema.NewTable("t"),
+				Changes: []schema.Change{
+					&schema.AddIndex{I: schema.NewIndex("idx").AddParts(schema.NewColumnPart(schema.NewStringColumn("a", "text")).AddAttrs(&SubPart{Len: 10})).AddAttrs(&IndexType{T: IndexTypeFullText})},
+				},
+			},
+			wantErr: `index "idx" of table "t": FULLTEXT indexes do not support index prefixes`,
+		},
+		{
+			version: "8.0.31",
+			change: &schema.ModifyTable{
+				T: schema.NewTable("t").AddColumns(schema.NewStringColumn("a", "text")),
+				Changes: []schema.Change{
+					&schema.AddIndex{I: schema.NewIndex("idx").AddColumns(schema.NewStringColumn("a", "text")).AddAttrs(&IndexType{T: IndexTypeFullText}, &IndexParser{P: "ngram"})},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			db, _, err := newMigrate(tt.version)
+			require.NoError(t, err)
+			_, err = db.PlanChanges(context.Background(), "plan", []schema.Change{tt.change})
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPlanChanges_AuroraProfile(t *testing.T) {
+	drv, _, err := newMigrate("8.0.mysql_aurora.3.04.0")
+	require.NoError(t, err)
+	require.Equal(t, ProfileAurora, drv.(*Driver).Profile())
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")).AddAttrs(&Engine{V: EngineMyISAM})
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}})
+	require.EqualError(t, err, `table "users": Aurora MySQL does not support the MyISAM storage engine`)
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.ModifyAttr{From: &Engine{V: EngineInnoDB}, To: &Engine{V: EngineMyISAM}}}},
+	})
+	require.Error(t, err)
+	users.Attrs = []schema.Attr{&Engine{V: EngineInnoDB}}
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}})
+	require.NoError(t, err)
+
+	drv, _, err = newMigrate("8.0.31")
+	require.NoError(t, err)
+	require.Equal(t, ProfileDefault, drv.(*Driver).Profile())
+}
+
 func TestDefaultPlan(t *testing.T) {
 	changes, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
 		&schema.AddTable{T: schema.NewTable("t1").SetSchema(schema.New("s1")).AddColumns(schema.NewIntColumn("a", "int"))},
@@ -0,0 +1,37 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package mysqlaudit provides the MySQL dialect of the audit package.
+package mysqlaudit
+
+import (
+	"strings"
+
+	"ariga.io/atlas/sql/audit"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Dialect is the MySQL dialect. Changes are recorded by a trigger for each operation,
+// that converts the old and new rows to JSON objects, and the actor is the client user.
+var Dialect = &audit.Dialect{
+	ID: func() *schema.Column {
+		return schema.NewIntColumn(audit.ColumnID, mysql.TypeBigInt).
+			AddAttrs(&mysql.AutoIncrement{})
+	},
+	String:  &schema.StringType{T: mysql.TypeVarchar, Size: 255},
+	JSON:    &schema.JSONType{T: mysql.TypeJSON},
+	Time:    &schema.TimeType{T: mysql.TypeTimestamp, Precision: sqlx.P(6)},
+	Now:     "CURRENT_TIMESTAMP(6)",
+	Actor:   "USER()",
+	MaxName: 64,
+	Triggers: func(a *audit.Audit) error {
+		return audit.RowTriggers(a, "JSON_OBJECT", quote)
+	},
+}
+
+func quote(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
@@ -0,0 +1,56 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysqlaudit_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/audit"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/mysql/mysqlaudit"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect(t *testing.T) {
+	s := schema.New("public").AddTables(
+		schema.NewTable("users").AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewStringColumn("user's name", "text"),
+		),
+	)
+	desired, err := audit.Generate(mysqlaudit.Dialect, s, audit.WithSuffix("_history"), audit.WithActor("@app_user"))
+	require.NoError(t, err)
+	users, _ := desired.Table("users")
+	log, ok := desired.Table("users_history")
+	require.True(t, ok)
+	changes := []schema.Change{&schema.AddTable{T: log}}
+	for _, tr := range users.Triggers {
+		changes = append(changes, &schema.AddTrigger{T: tr})
+	}
+	plan, err := mysql.DefaultPlan.PlanChanges(context.Background(), "audit", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, "CREATE TABLE `public`.`users_history` (`id` bigint NOT NULL AUTO_INCREMENT, `operation` varchar(255) NOT NULL, `old_row` json NULL, `new_row` json NULL, `actor` varchar(255) NULL, `changed_at` timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6), PRIMARY KEY (`id`)) COMMENT \"Audit log of table users.\"", plan.Changes[0].Cmd)
+	require.Equal(t, "CREATE TRIGGER `public`.`users_history_insert` AFTER INSERT ON `public`.`users` FOR EACH ROW INSERT INTO `users_history` (`operation`, `old_row`, `new_row`, `actor`) VALUES ('INSERT', NULL, JSON_OBJECT('id', NEW.`id`, 'user''s name', NEW.`user's name`), @app_user)", plan.Changes[1].Cmd)
+	require.Equal(t, "CREATE TRIGGER `public`.`users_history_update` AFTER UPDATE ON `public`.`users` FOR EACH ROW INSERT INTO `users_history` (`operation`, `old_row`, `new_row`, `actor`) VALUES ('UPDATE', JSON_OBJECT('id', OLD.`id`, 'user''s name', OLD.`user's name`), JSON_OBJECT('id', NEW.`id`, 'user''s name', NEW.`user's name`), @app_user)", plan.Changes[2].Cmd)
+	require.Equal(t, "DROP TRIGGER `public`.`users_history_delete`", plan.Changes[3].Reverse)
+}
+
+func TestDialect_MaxName(t *testing.T) {
+	s := schema.New("public").AddTables(
+		schema.NewTable(strings.Repeat("t", 60)).AddColumns(schema.NewIntColumn("id", "int")),
+	)
+	desired, err := audit.Generate(mysqlaudit.Dialect, s)
+	require.NoError(t, err)
+	require.Len(t, desired.Tables, 2)
+	require.Len(t, desired.Tables[1].Name, 64)
+	for _, tr := range desired.Tables[0].Triggers {
+		require.Len(t, tr.Name, 64)
+	}
+}
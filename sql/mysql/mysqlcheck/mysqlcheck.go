@@ -208,7 +208,8 @@ func init() {
 			return nil, err
 		}
 		dd, err := datadepend.New(r, datadepend.Handler{
-			AddNotNull: addNotNull,
+			AddNotNull:     addNotNull,
+			TypeConversion: mysql.TypeConversion,
 		})
 		if err != nil {
 			return nil, err
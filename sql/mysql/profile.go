@@ -0,0 +1,72 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// A Profile describes the quirks of the engine the driver is connected to.
+// It is selected automatically from the server version string.
+type Profile string
+
+// List of engine profiles.
+const (
+	ProfileDefault Profile = ""
+	// ProfileAurora is used for Amazon Aurora MySQL, that reports
+	// versions such as 8.0.mysql_aurora.3.04.0.
+	ProfileAurora Profile = "aurora"
+)
+
+// Profile returns the engine profile of the connected database.
+func (d *Driver) Profile() Profile {
+	return d.conn.profile()
+}
+
+// profile returns the profile that matches the server version.
+func (c *conn) profile() Profile {
+	if c.Aurora() {
+		return ProfileAurora
+	}
+	return ProfileDefault
+}
+
+// verifyProfile verifies the changes are supported by the engine profile. For example,
+// Aurora MySQL supports only the InnoDB storage engine, and creating tables with other
+// engines is rejected at plan time rather than failing (or silently converted) on apply.
+func (s *state) verifyProfile(changes []schema.Change) error {
+	if s.profile() != ProfileAurora {
+		return nil
+	}
+	for _, c := range changes {
+		var (
+			t     *schema.Table
+			attrs []schema.Attr
+		)
+		switch c := c.(type) {
+		case *schema.AddTable:
+			t, attrs = c.T, c.T.Attrs
+		case *schema.ModifyTable:
+			t = c.T
+			for _, c := range c.Changes {
+				switch c := c.(type) {
+				case *schema.AddAttr:
+					attrs = append(attrs, c.A)
+				case *schema.ModifyAttr:
+					attrs = append(attrs, c.To)
+				}
+			}
+		}
+		for _, a := range attrs {
+			if e, ok := a.(*Engine); ok && e.V != "" && !strings.EqualFold(e.V, EngineInnoDB) {
+				return fmt.Errorf("table %q: Aurora MySQL does not support the %s storage engine", t.Name, e.V)
+			}
+		}
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ import (
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlspec"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -36,7 +37,7 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 		}
 		if err := specutil.Scan(v,
 			&specutil.ScanDoc{Schemas: d.Schemas, Tables: d.Tables, Views: d.Views},
-			&specutil.ScanFuncs{Table: convertTable, View: convertView},
+			&specutil.ScanFuncs{Table: convertTable, View: convertView, Mixins: mixins},
 		); err != nil {
 			return fmt.Errorf("mysql: failed converting to *schema.Realm: %w", err)
 		}
@@ -48,6 +49,9 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 			if err := convertCharset(spec, &s.Attrs); err != nil {
 				return err
 			}
+			if err := convertAutoIncStep(spec, &s.Attrs); err != nil {
+				return err
+			}
 		}
 	case *schema.Schema:
 		var d doc
@@ -60,13 +64,16 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 		r := &schema.Realm{}
 		if err := specutil.Scan(r,
 			&specutil.ScanDoc{Schemas: d.Schemas, Tables: d.Tables, Views: d.Views},
-			&specutil.ScanFuncs{Table: convertTable, View: convertView},
+			&specutil.ScanFuncs{Table: convertTable, View: convertView, Mixins: mixins},
 		); err != nil {
 			return err
 		}
 		if err := convertCharset(d.Schemas[0], &r.Schemas[0].Attrs); err != nil {
 			return err
 		}
+		if err := convertAutoIncStep(d.Schemas[0], &r.Schemas[0].Attrs); err != nil {
+			return err
+		}
 		*v = *r.Schemas[0]
 	case schema.Schema, schema.Realm:
 		return fmt.Errorf("mysql: Eval expects a pointer: received %[1]T, expected *%[1]T", v)
@@ -76,6 +83,26 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 	return nil
 }
 
+// JSONSchema returns a JSON Schema document describing the blocks, attributes and
+// types of the JSON representation of Atlas HCL documents for this driver.
+func JSONSchema() ([]byte, error) {
+	return hclState.JSONSchema(&doc{})
+}
+
+// ValidateHCL checks the parsed Atlas HCL documents for structural errors, such as
+// duplicate definitions, broken references or invalid types, without connecting to
+// a database. All errors found are returned as diagnostics.
+func ValidateHCL(p *hclparse.Parser, input map[string]cty.Value) hcl.Diagnostics {
+	var d doc
+	if err := hclState.Eval(p, &d, input); err != nil {
+		return schemahcl.Diagnostics(err)
+	}
+	return specutil.Validate(
+		&specutil.ScanDoc{Schemas: d.Schemas, Tables: d.Tables, Views: d.Views},
+		&specutil.ScanFuncs{Table: convertTable, View: convertView, Ident: identRules, Mixins: mixins},
+	)
+}
+
 // MarshalSpec marshals v into an Atlas DDL document using a schemahcl.Marshaler.
 func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
 	return specutil.Marshal(v, marshaler, schemaSpec)
@@ -107,6 +134,17 @@ var (
 	// EvalHCLBytes is a helper that evaluates an HCL document from a byte slice instead
 	// of from an hclparse.Parser instance.
 	EvalHCLBytes = specutil.HCLBytesFunc(EvalHCL)
+
+	// MergeHCL is a helper that evaluates multiple parsed HCL documents and merges them
+	// into one Realm, reporting duplicate definitions across the documents as errors.
+	MergeHCL = specutil.HCLMergeFunc(EvalHCL, ValidateHCL)
+
+	// mixins holds the builtin mixins that can be attached to table blocks. As MySQL
+	// does not support partial indexes, unique indexes are not changed by soft_delete.
+	mixins = map[string]specutil.MixinFunc{
+		specutil.MixinTimestamps: specutil.TimestampsMixin(&schemahcl.Type{T: TypeTimestamp}, "CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP"),
+		specutil.MixinSoftDelete: specutil.SoftDeleteMixin(&schemahcl.Type{T: TypeTimestamp}, nil),
+	}
 )
 
 // convertTable converts a sqlspec.Table to a schema.Table. Table conversion is done without converting
@@ -136,6 +174,20 @@ func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, er
 		}
 		t.AddAttrs(&Engine{V: v})
 	}
+	if attr, ok := spec.Attr("placement_policy"); ok {
+		v, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		t.AddAttrs(&PlacementPolicy{V: v})
+	}
+	if attr, ok := spec.Attr("create_options"); ok {
+		v, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		t.AddAttrs(&CreateOptions{V: v})
+	}
 	return t, err
 }
 
@@ -168,6 +220,13 @@ func convertPK(spec *sqlspec.PrimaryKey, parent *schema.Table) (*schema.Index, e
 	if err := convertIndexParser(spec, idx); err != nil {
 		return nil, err
 	}
+	if attr, ok := spec.Attr("clustered"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		idx.AddAttrs(&Clustered{V: b})
+	}
 	return idx, nil
 }
 
@@ -263,6 +322,20 @@ func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error
 			c.AddAttrs(&AutoIncrement{})
 		}
 	}
+	// AUTO_RANDOM is defined by its shard bits, e.g. "auto_random = 5",
+	// and optionally by its range bits, e.g. "auto_random_range = 54".
+	if attr, ok := spec.Attr("auto_random"); ok {
+		a := &AutoRandom{}
+		if a.ShardBits, err = attr.Int(); err != nil {
+			return nil, err
+		}
+		if attr, ok := spec.Attr("auto_random_range"); ok {
+			if a.RangeBits, err = attr.Int(); err != nil {
+				return nil, err
+			}
+		}
+		c.AddAttrs(a)
+	}
 	if err := specutil.ConvertGenExpr(spec.Remain(), c, storedOrVirtual); err != nil {
 		return nil, err
 	}
@@ -289,6 +362,14 @@ func schemaSpec(s *schema.Schema) (*specutil.SchemaSpec, error) {
 	if c, ok := sqlx.Collate(s.Attrs, nil); ok {
 		spec.Schema.Extra.Attrs = append(spec.Schema.Extra.Attrs, schemahcl.StringAttr("collate", c))
 	}
+	if step := (AutoIncrementStep{}); sqlx.Has(s.Attrs, &step) {
+		if step.Increment > 1 {
+			spec.Schema.Extra.Attrs = append(spec.Schema.Extra.Attrs, schemahcl.Int64Attr("auto_increment_increment", step.Increment))
+		}
+		if step.Offset > 1 {
+			spec.Schema.Extra.Attrs = append(spec.Schema.Extra.Attrs, schemahcl.Int64Attr("auto_increment_offset", step.Offset))
+		}
+	}
 	return spec, nil
 }
 
@@ -322,6 +403,13 @@ func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
 		}
 		ts.Extra.Attrs = append(ts.Extra.Attrs, attr)
 	}
+	if p := (PlacementPolicy{}); sqlx.Has(t.Attrs, &p) && p.V != "" {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr("placement_policy", p.V))
+	}
+	// Options that are not modeled by Atlas are kept as-is.
+	if o := (CreateOptions{}); sqlx.Has(t.Attrs, &o) && o.V != "" {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, schemahcl.StringAttr("create_options", o.V))
+	}
 	return ts, nil
 }
 
@@ -346,6 +434,9 @@ func pkSpec(idx *schema.Index) (*sqlspec.PrimaryKey, error) {
 		return nil, err
 	}
 	spec.Extra.Attrs = indexTypeSpec(idx, spec.Extra.Attrs)
+	if c := (Clustered{}); sqlx.Has(idx.Attrs, &c) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("clustered", c.V))
+	}
 	return spec, nil
 }
 
@@ -402,6 +493,16 @@ func columnSpec(c *schema.Column, t *schema.Table) (*sqlspec.Column, error) {
 	if sqlx.Has(c.Attrs, &AutoIncrement{}) {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("auto_increment", true))
 	}
+	if a := (AutoRandom{}); sqlx.Has(c.Attrs, &a) {
+		shard := a.ShardBits
+		if shard == 0 {
+			shard = defaultShardBits
+		}
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.IntAttr("auto_random", shard))
+		if a.RangeBits != 0 && a.RangeBits != defaultRangeBits {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.IntAttr("auto_random_range", a.RangeBits))
+		}
+	}
 	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
 		spec.Extra.Children = append(spec.Extra.Children, specutil.FromGenExpr(x, storedOrVirtual))
 	}
@@ -472,6 +573,35 @@ func convertCharset(spec specutil.Attrer, attrs *[]schema.Attr) error {
 	return nil
 }
 
+// convertAutoIncStep converts the spec auto_increment_increment
+// and auto_increment_offset attributes to an AutoIncrementStep.
+func convertAutoIncStep(spec specutil.Attrer, attrs *[]schema.Attr) error {
+	step := &AutoIncrementStep{Increment: 1, Offset: 1}
+	inc, ok1 := spec.Attr("auto_increment_increment")
+	if ok1 {
+		v, err := inc.Int64()
+		if err != nil {
+			return err
+		}
+		step.Increment = v
+	}
+	off, ok2 := spec.Attr("auto_increment_offset")
+	if ok2 {
+		v, err := off.Int64()
+		if err != nil {
+			return err
+		}
+		step.Offset = v
+	}
+	if step.Increment < 1 || step.Offset < 1 {
+		return fmt.Errorf("mysql: auto_increment_increment and auto_increment_offset must be positive, got %d and %d", step.Increment, step.Offset)
+	}
+	if ok1 || ok2 {
+		*attrs = append(*attrs, step)
+	}
+	return nil
+}
+
 // TypeRegistry contains the supported TypeSpecs for the mysql driver.
 var TypeRegistry = schemahcl.NewRegistry(
 	schemahcl.WithFormatter(FormatType),
@@ -5,11 +5,15 @@
 package mysql
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
+	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/internal/spectest"
 	"ariga.io/atlas/sql/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/stretchr/testify/require"
 )
 
@@ -557,6 +561,40 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestSQLSpec_AutoIncrementStep(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `
+schema "test" {
+  auto_increment_increment = 10
+  auto_increment_offset    = 2
+}
+table "users" {
+  schema         = schema.test
+  auto_increment = 100
+  column "id" {
+    null           = false
+    type           = bigint
+    auto_increment = true
+  }
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&AutoIncrementStep{Increment: 10, Offset: 2}}, s.Attrs)
+	buf, err := MarshalSpec(&s, hclState)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `schema "test" {
+  auto_increment_increment = 10
+  auto_increment_offset    = 2
+}`)
+
+	err = EvalHCLBytes([]byte(`schema "test" {
+  auto_increment_increment = 0
+}`), &s, nil)
+	require.EqualError(t, err, "mysql: auto_increment_increment and auto_increment_offset must be positive, got 0 and 1")
+}
+
 func TestMarshalSpec_Check(t *testing.T) {
 	s := schema.New("test").
 		AddTables(
@@ -1549,6 +1587,51 @@ func TestInputVars(t *testing.T) {
 	spectest.TestInputVars(t, EvalHCL)
 }
 
+func TestUnmarshalSpec_ErrorPosition(t *testing.T) {
+	var (
+		s   schema.Schema
+		err = EvalHCLBytes([]byte(`
+schema "public" {}
+
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  column "parent_id" {
+    type = int
+  }
+  foreign_key "parent" {
+    columns     = [column.id, column.parent_id]
+    ref_columns = [column.id]
+  }
+}
+`), &s, nil)
+	)
+	require.EqualError(t, err, `:12,3-25: sqlspec: number of referencing and referenced columns do not match for foreign-key "parent"`)
+	var pe *schemahcl.PosError
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, 12, pe.Range.Start.Line)
+	diags := schemahcl.Diagnostics(err)
+	require.Len(t, diags, 1)
+	require.Equal(t, `sqlspec: number of referencing and referenced columns do not match for foreign-key "parent"`, diags[0].Summary)
+	require.Equal(t, 12, diags[0].Subject.Start.Line)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  index "idx" {
+  }
+}
+`), &s, nil)
+	require.EqualError(t, err, `:9,3-16: specutil: cannot convert table "users": missing definition for index "idx"`)
+}
+
 func TestParseType_Decimal(t *testing.T) {
 	for _, tt := range []struct {
 		input   string
@@ -1819,3 +1902,214 @@ schema "nati.schema" {
 }
 `, string(got))
 }
+
+func TestValidateHCL(t *testing.T) {
+	p := hclparse.NewParser()
+	_, diags := p.ParseHCL([]byte(`
+schema "public" {}
+
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  column "id" {
+    type = int
+  }
+  column "name" {
+    type = varchar(255)
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+
+table "posts" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.id, column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+}
+`), "schema.hcl")
+	require.False(t, diags.HasErrors())
+	diags = ValidateHCL(p, nil)
+	require.Len(t, diags, 3)
+	require.Equal(t, `specutil: duplicate column "id" in "users"`, diags[0].Summary)
+	require.Equal(t, 9, diags[0].Subject.Start.Line)
+	require.Equal(t, `sqlspec: number of referencing and referenced columns do not match for foreign-key "author"`, diags[1].Summary)
+	require.Equal(t, 28, diags[1].Subject.Start.Line)
+	require.Equal(t, `specutil: duplicate table "users" in schema "public", previously defined at schema.hcl:4,1-16`, diags[2].Summary)
+	require.Equal(t, 34, diags[2].Subject.Start.Line)
+
+	p = hclparse.NewParser()
+	_, diags = p.ParseHCL([]byte(`
+schema "public" {}
+
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+}
+`), "schema.hcl")
+	require.False(t, diags.HasErrors())
+	require.Empty(t, ValidateHCL(p, nil))
+}
+
+func TestMergeHCL(t *testing.T) {
+	var (
+		p     = hclparse.NewParser()
+		parse = func(name, src string) *hcl.File {
+			f, diags := p.ParseHCL([]byte(src), name)
+			require.False(t, diags.HasErrors())
+			return f
+		}
+		users = parse("users.hcl", `
+schema "public" {}
+
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+}
+`)
+		posts = parse("posts.hcl", `
+table "posts" {
+  schema = schema.public
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+`)
+	)
+	r, err := MergeHCL([]*hcl.File{users, posts}, nil)
+	require.NoError(t, err)
+	require.Len(t, r.Schemas, 1)
+	require.Len(t, r.Schemas[0].Tables, 2)
+	posts1, ok := r.Schemas[0].Table("posts")
+	require.True(t, ok)
+	users1, ok := r.Schemas[0].Table("users")
+	require.True(t, ok)
+	require.Equal(t, users1, posts1.ForeignKeys[0].RefTable)
+
+	dup := parse("team.hcl", `
+schema "public" {}
+
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = bigint
+  }
+}
+`)
+	_, err = MergeHCL([]*hcl.File{users, posts, dup}, nil)
+	require.Error(t, err)
+	diags := schemahcl.Diagnostics(err)
+	require.Len(t, diags, 2)
+	require.Equal(t, `specutil: duplicate schema "public", previously defined at team.hcl:2,1-18`, diags[0].Summary)
+	require.Equal(t, "users.hcl", diags[0].Subject.Filename)
+	require.Equal(t, `specutil: duplicate table "users" in schema "public", previously defined at team.hcl:4,1-16`, diags[1].Summary)
+	require.Equal(t, "users.hcl", diags[1].Subject.Filename)
+	require.Equal(t, 4, diags[1].Subject.Start.Line)
+
+	// Duplicates are rejected only when merging documents, and
+	// evaluating a single document keeps its existing behavior.
+	var r1 schema.Realm
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = bigint
+  }
+}
+`), &r1, nil))
+	require.Len(t, r1.Schemas[0].Tables, 2)
+}
+
+func TestSQLSpec_CreateOptions(t *testing.T) {
+	f := `
+schema "test" {}
+table "users" {
+	schema = schema.test
+	create_options = "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8"
+	column "id" {
+		type = bigint
+	}
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&CreateOptions{V: "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8"}}, s.Tables[0].Attrs)
+
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	require.Equal(t, s.Tables[0].Attrs, s2.Tables[0].Attrs)
+}
+
+func TestSQLSpec_Mixins(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "email" {
+    type = varchar(255)
+  }
+  index "users_email" {
+    unique  = true
+    columns = [column.email]
+  }
+  mixin "timestamps" {
+    updated = "modified_at"
+  }
+  mixin "soft_delete" {}
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	users := s.Tables[0]
+	require.Len(t, users.Columns, 4)
+	c, ok := users.Column("created_at")
+	require.True(t, ok)
+	require.Equal(t, &schema.RawExpr{X: "CURRENT_TIMESTAMP"}, c.Default)
+	require.Empty(t, c.Attrs)
+	c, ok = users.Column("modified_at")
+	require.True(t, ok)
+	require.False(t, c.Type.Null)
+	require.Equal(t, TypeTimestamp, c.Type.Type.(*schema.TimeType).T)
+	require.Equal(t, []schema.Attr{&OnUpdate{A: "CURRENT_TIMESTAMP"}}, c.Attrs)
+	c, ok = users.Column("deleted_at")
+	require.True(t, ok)
+	require.True(t, c.Type.Null)
+	// Partial indexes are not supported by MySQL.
+	require.Empty(t, users.Indexes[0].Attrs)
+}
@@ -0,0 +1,61 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// inspectStats collects the statistics of the inspected tables. MySQL does not
+// report the unused space of each index. Hence, index bloat is reported at the
+// table level, as the unused space (DATA_FREE) of its tablespace.
+func (i *inspect) inspectStats(ctx context.Context, r *schema.Realm) error {
+	for _, s := range r.Schemas {
+		if len(s.Tables) == 0 {
+			continue
+		}
+		if err := i.tableStats(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableStats attaches the TableStats attribute to the schema tables.
+func (i *inspect) tableStats(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, tableStatsQuery, s)
+	if err != nil {
+		return fmt.Errorf("mysql: querying schema %q table statistics: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name                             string
+			tableRows, data, index, dataFree sql.NullInt64
+		)
+		if err := rows.Scan(&name, &tableRows, &data, &index, &dataFree); err != nil {
+			return fmt.Errorf("mysql: scanning table statistics: %w", err)
+		}
+		t, ok := s.Table(name)
+		if !ok {
+			return fmt.Errorf("table %q was not found in schema", name)
+		}
+		t.Attrs = append(t.Attrs, &schema.TableStats{
+			Rows:      tableRows.Int64,
+			DataSize:  data.Int64,
+			IndexSize: index.Int64,
+			FreeSize:  dataFree.Int64,
+		})
+	}
+	return rows.Err()
+}
+
+// Query to list table statistics. The values are estimations that
+// are refreshed by the server, e.g., when running ANALYZE TABLE.
+const tableStatsQuery = "SELECT `TABLE_NAME`, `TABLE_ROWS`, `DATA_LENGTH`, `INDEX_LENGTH`, `DATA_FREE` FROM `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `TABLE_NAME`"
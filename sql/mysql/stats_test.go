@@ -0,0 +1,37 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect_Stats(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	var (
+		users = schema.NewTable("users")
+		pets  = schema.NewTable("pets")
+		r     = schema.NewRealm(schema.New("test").AddTables(users, pets), schema.New("empty"))
+	)
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tableStatsQuery, "?, ?"))).
+		WithArgs("test", "users", "pets").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "TABLE_ROWS", "DATA_LENGTH", "INDEX_LENGTH", "DATA_FREE"}).
+			AddRow("pets", nil, nil, nil, nil).
+			AddRow("users", 1000, 65536, 16384, 4096))
+	i := &inspect{conn: &conn{ExecQuerier: db}}
+	require.NoError(t, i.inspectStats(context.Background(), r))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, []schema.Attr{&schema.TableStats{Rows: 1000, DataSize: 65536, IndexSize: 16384, FreeSize: 4096}}, users.Attrs)
+	require.Equal(t, []schema.Attr{&schema.TableStats{}}, pets.Attrs)
+}
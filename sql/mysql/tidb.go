@@ -27,6 +27,48 @@ type (
 	tinspect struct{ inspect }
 )
 
+type (
+	// AutoRandom attribute for TiDB columns with the "AUTO_RANDOM" option.
+	// https://docs.pingcap.com/tidb/stable/auto-random
+	AutoRandom struct {
+		schema.Attr
+		ShardBits int // Defaults to 5.
+		RangeBits int // Defaults to 64.
+	}
+
+	// Clustered attribute describes if a TiDB primary key is a clustered index.
+	// https://docs.pingcap.com/tidb/stable/clustered-indexes
+	Clustered struct {
+		schema.Attr
+		V bool
+	}
+
+	// PlacementPolicy attribute describes the placement policy attached to a TiDB table.
+	// https://docs.pingcap.com/tidb/stable/placement-rules-in-sql
+	PlacementPolicy struct {
+		schema.Attr
+		V string
+	}
+)
+
+// Default values of the AUTO_RANDOM option.
+const (
+	defaultShardBits = 5
+	defaultRangeBits = 64
+)
+
+// String returns the AUTO_RANDOM clause of the attribute.
+func (a *AutoRandom) String() string {
+	shard := a.ShardBits
+	if shard == 0 {
+		shard = defaultShardBits
+	}
+	if a.RangeBits != 0 && a.RangeBits != defaultRangeBits {
+		return fmt.Sprintf("AUTO_RANDOM(%d, %d)", shard, a.RangeBits)
+	}
+	return fmt.Sprintf("AUTO_RANDOM(%d)", shard)
+}
+
 // priority computes the priority of each change.
 //
 // TiDB does not support multischema ALTERs (i.e. multiple changes in a single ALTER statement).
@@ -153,6 +195,9 @@ func (i *tinspect) patchSchema(ctx context.Context, s *schema.Schema) (*schema.S
 		if err := i.setAutoIncrement(t); err != nil {
 			return nil, err
 		}
+		if err := i.setTiDBOptions(t); err != nil {
+			return nil, err
+		}
 		for _, c := range t.Columns {
 			i.patchColumn(ctx, c)
 		}
@@ -160,6 +205,86 @@ func (i *tinspect) patchSchema(ctx context.Context, s *schema.Schema) (*schema.S
 	return s, nil
 }
 
+var (
+	// e.g. `id` bigint(20) NOT NULL /*T![auto_rand] AUTO_RANDOM(5, 54) */,
+	reAutoRandom = regexp.MustCompile("(?im)^\\s*`((?:[^`]|``)+)`.*AUTO_RANDOM\\((\\d+)(?:\\s*,\\s*(\\d+))?\\)")
+	// e.g. PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */
+	rePKClustered = regexp.MustCompile(`(?im)^\s*PRIMARY KEY\s*\(.*\)\s*(?:/\*T!\[clustered_index]\s*)?(NONCLUSTERED|CLUSTERED)`)
+	// e.g. /*T![placement] PLACEMENT POLICY=`p1` */
+	rePlacement = regexp.MustCompile("(?i)PLACEMENT\\s+POLICY\\s*=\\s*`?((?:[^`\\s]|``)+)`?")
+)
+
+// setTiDBOptions extracts the AUTO_RANDOM, CLUSTERED and PLACEMENT POLICY
+// options from the CREATE TABLE statement.
+func (i *tinspect) setTiDBOptions(t *schema.Table) error {
+	var c CreateStmt
+	if !sqlx.Has(t.Attrs, &c) {
+		return fmt.Errorf("missing CREATE TABLE statement in attributes for %q", t.Name)
+	}
+	for _, m := range reAutoRandom.FindAllStringSubmatch(c.S, -1) {
+		col, ok := t.Column(strings.ReplaceAll(m[1], "``", "`"))
+		if !ok {
+			continue
+		}
+		a := &AutoRandom{}
+		a.ShardBits, _ = strconv.Atoi(m[2])
+		if m[3] != "" {
+			a.RangeBits, _ = strconv.Atoi(m[3])
+		}
+		schema.ReplaceOrAppend(&col.Attrs, a)
+	}
+	if m := rePKClustered.FindStringSubmatch(c.S); m != nil && t.PrimaryKey != nil {
+		schema.ReplaceOrAppend(&t.PrimaryKey.Attrs, &Clustered{V: strings.EqualFold(m[1], "CLUSTERED")})
+	}
+	if m := rePlacement.FindStringSubmatch(c.S); m != nil {
+		schema.ReplaceOrAppend(&t.Attrs, &PlacementPolicy{V: strings.ReplaceAll(m[1], "``", "`")})
+	}
+	return nil
+}
+
+// TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
+func (d *tdiff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
+	changes, err := d.diff.TableAttrDiff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var fromP, toP PlacementPolicy
+	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromP), sqlx.Has(to.Attrs, &toP); {
+	case !fromHas && toHas:
+		changes = append(changes, &schema.AddAttr{A: &toP})
+	case fromHas && !toHas:
+		// Detaching a policy is done by setting it to DEFAULT.
+		changes = append(changes, &schema.ModifyAttr{From: &fromP, To: &PlacementPolicy{}})
+	case fromHas && toHas && !strings.EqualFold(fromP.V, toP.V):
+		changes = append(changes, &schema.ModifyAttr{From: &fromP, To: &toP})
+	}
+	return changes, nil
+}
+
+// ColumnChange returns the schema changes (if any) for migrating one column to the other.
+func (d *tdiff) ColumnChange(fromT *schema.Table, from, to *schema.Column) (schema.ChangeKind, error) {
+	change, err := d.diff.ColumnChange(fromT, from, to)
+	if err != nil {
+		return schema.NoChange, err
+	}
+	var fromA, toA AutoRandom
+	if fromHas, toHas := sqlx.Has(from.Attrs, &fromA), sqlx.Has(to.Attrs, &toA); fromHas != toHas || fromA.String() != toA.String() {
+		change |= schema.ChangeAttr
+	}
+	return change, nil
+}
+
+// IndexAttrChanged reports if the index attributes were changed. The CLUSTERED option is
+// compared only if it is set explicitly on the desired state, as TiDB decides the default
+// based on the tidb_enable_clustered_index system variable.
+func (d *tdiff) IndexAttrChanged(from, to []schema.Attr) bool {
+	if d.diff.IndexAttrChanged(from, to) {
+		return true
+	}
+	var fromC, toC Clustered
+	return sqlx.Has(to, &toC) && sqlx.Has(from, &fromC) && fromC.V != toC.V
+}
+
 func (i *tinspect) patchColumn(_ context.Context, c *schema.Column) {
 	_, ok := c.Type.Type.(*BitType)
 	if !ok {
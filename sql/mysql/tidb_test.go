@@ -0,0 +1,120 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTInspect_TiDBOptions(t *testing.T) {
+	tbl := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewIntColumn("a", "bigint"))
+	tbl.SetPrimaryKey(schema.NewPrimaryKey(tbl.Columns[0]))
+	tbl.AddAttrs(&CreateStmt{S: "CREATE TABLE `users` (\n" +
+		"  `id` bigint(20) NOT NULL /*T![auto_rand] AUTO_RANDOM(5, 54) */,\n" +
+		"  `a` bigint(20) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin /*T![placement] PLACEMENT POLICY=`p1` */",
+	})
+	require.NoError(t, (&tinspect{}).setTiDBOptions(tbl))
+	require.Equal(t, []schema.Attr{&AutoRandom{ShardBits: 5, RangeBits: 54}}, tbl.Columns[0].Attrs)
+	require.Empty(t, tbl.Columns[1].Attrs)
+	require.Equal(t, []schema.Attr{&Clustered{V: true}}, tbl.PrimaryKey.Attrs)
+	p := &PlacementPolicy{}
+	require.True(t, sqlx.Has(tbl.Attrs, p))
+	require.Equal(t, "p1", p.V)
+}
+
+func TestTDiff_TiDBOptions(t *testing.T) {
+	d := &tdiff{diff{conn: &conn{V: "5.7.25-TiDB-v6.1.0"}}}
+	from := schema.NewTable("t").SetSchema(schema.New("public"))
+	to := schema.NewTable("t").SetSchema(schema.New("public")).AddAttrs(&PlacementPolicy{V: "p1"})
+	changes, err := d.TableAttrDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.AddAttr{A: &PlacementPolicy{V: "p1"}}}, changes)
+	changes, err = d.TableAttrDiff(to, from)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyAttr{From: &PlacementPolicy{V: "p1"}, To: &PlacementPolicy{}}}, changes)
+
+	// AUTO_RANDOM with default values.
+	c1 := schema.NewIntColumn("id", "bigint").AddAttrs(&AutoRandom{ShardBits: 5})
+	c2 := schema.NewIntColumn("id", "bigint").AddAttrs(&AutoRandom{ShardBits: 5, RangeBits: 64})
+	change, err := d.ColumnChange(from, c1, c2)
+	require.NoError(t, err)
+	require.Equal(t, schema.NoChange, change)
+	c2 = schema.NewIntColumn("id", "bigint").AddAttrs(&AutoRandom{ShardBits: 6})
+	change, err = d.ColumnChange(from, c1, c2)
+	require.NoError(t, err)
+	require.True(t, change.Is(schema.ChangeAttr))
+
+	// CLUSTERED is compared only if it is set on the desired state.
+	require.False(t, d.IndexAttrChanged([]schema.Attr{&Clustered{V: true}}, nil))
+	require.True(t, d.IndexAttrChanged([]schema.Attr{&Clustered{V: true}}, []schema.Attr{&Clustered{V: false}}))
+}
+
+func TestTPlanApply_TiDBOptions(t *testing.T) {
+	drv, _, err := newMigrate("5.7.25-TiDB-v6.1.0")
+	require.NoError(t, err)
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "bigint").AddAttrs(&AutoRandom{ShardBits: 5, RangeBits: 54})).
+		AddAttrs(&PlacementPolicy{V: "p1"})
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]).AddAttrs(&Clustered{V: true}))
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{
+			T:       users,
+			Changes: []schema.Change{&schema.ModifyAttr{From: &PlacementPolicy{V: "p1"}, To: &PlacementPolicy{}}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "CREATE TABLE `users` (`id` bigint NOT NULL AUTO_RANDOM(5, 54), PRIMARY KEY (`id`) CLUSTERED) PLACEMENT POLICY `p1`", plan.Changes[0].Cmd)
+	require.Equal(t, "ALTER TABLE `users` PLACEMENT POLICY DEFAULT", plan.Changes[1].Cmd)
+
+	// AUTO_RANDOM is rejected by MySQL.
+	drv, _, err = newMigrate("8.0.31")
+	require.NoError(t, err)
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}})
+	require.EqualError(t, err, `create table "users": column "id": AUTO_RANDOM is supported only by TiDB`)
+}
+
+func TestSQLSpec_TiDBOptions(t *testing.T) {
+	f := `
+schema "test" {}
+table "users" {
+	schema = schema.test
+	placement_policy = "p1"
+	column "id" {
+		type = bigint
+		auto_random = 5
+		auto_random_range = 54
+	}
+	primary_key {
+		columns = [column.id]
+		clustered = true
+	}
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	users := s.Tables[0]
+	require.Equal(t, []schema.Attr{&AutoRandom{ShardBits: 5, RangeBits: 54}}, users.Columns[0].Attrs)
+	require.Equal(t, []schema.Attr{&Clustered{V: true}}, users.PrimaryKey.Attrs)
+	require.True(t, sqlx.Has(users.Attrs, &PlacementPolicy{}))
+
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	require.Equal(t, users.Columns[0].Attrs, s2.Tables[0].Columns[0].Attrs)
+	require.Equal(t, users.PrimaryKey.Attrs, s2.Tables[0].PrimaryKey.Attrs)
+	require.Equal(t, users.Attrs, s2.Tables[0].Attrs)
+}
@@ -0,0 +1,132 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// inspectTriggers inspects the triggers of the schema tables.
+func (i *inspect) inspectTriggers(ctx context.Context, r *schema.Realm) error {
+	for _, s := range r.Schemas {
+		if len(s.Tables) == 0 {
+			continue
+		}
+		if err := i.triggers(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// triggers appends the triggers of the schema tables.
+func (i *inspect) triggers(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, triggersQuery, s)
+	if err != nil {
+		return fmt.Errorf("mysql: querying schema %q triggers: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, table, timing, event, orientation, body string
+		if err := rows.Scan(&name, &table, &timing, &event, &orientation, &body); err != nil {
+			return fmt.Errorf("mysql: scanning trigger: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("table %q was not found in schema", table)
+		}
+		t.AddTriggers(&schema.Trigger{
+			Name:       name,
+			ActionTime: schema.TriggerTime(timing),
+			Events:     []schema.TriggerEvent{schema.TriggerEvent(event)},
+			For:        schema.TriggerFor(orientation),
+			Body:       body,
+		})
+	}
+	return rows.Err()
+}
+
+// triggerChanges plans the trigger changes. Triggers cannot be replaced in
+// MySQL, and therefore, modified triggers are dropped and created again.
+func (s *state) triggerChanges(changes []schema.Change) error {
+	for _, c := range changes {
+		var err error
+		switch c := c.(type) {
+		case *schema.AddTrigger:
+			err = s.addTrigger(c.T, c)
+		case *schema.DropTrigger:
+			err = s.dropTrigger(c)
+		case *schema.ModifyTrigger:
+			if err = s.dropTrigger(&schema.DropTrigger{T: c.From}); err == nil {
+				err = s.addTrigger(c.To, c)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTrigger appends the statement for creating the trigger.
+func (s *state) addTrigger(t *schema.Trigger, source schema.Change) error {
+	cmd, err := s.triggerDef(t)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Cmd:     cmd,
+		Source:  source,
+		Reverse: s.Build("DROP TRIGGER").Table(triggerName(t)).String(),
+		Comment: fmt.Sprintf("create trigger %q on table %q", t.Name, t.Table.Name),
+	})
+	return nil
+}
+
+// dropTrigger appends the statement for dropping the trigger.
+func (s *state) dropTrigger(drop *schema.DropTrigger) error {
+	rv, err := s.triggerDef(drop.T)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Cmd:     s.Build("DROP TRIGGER").Table(triggerName(drop.T)).String(),
+		Source:  drop,
+		Reverse: rv,
+		Comment: fmt.Sprintf("drop trigger %q from table %q", drop.T.Name, drop.T.Table.Name),
+	})
+	return nil
+}
+
+// triggerDef returns the CREATE TRIGGER statement of the trigger.
+func (s *state) triggerDef(t *schema.Trigger) (string, error) {
+	switch {
+	case len(t.Events) != 1:
+		return "", fmt.Errorf("mysql: trigger %q must have exactly one event, got %d", t.Name, len(t.Events))
+	case t.ActionTime != schema.TriggerTimeBefore && t.ActionTime != schema.TriggerTimeAfter:
+		return "", fmt.Errorf("mysql: unexpected action time %q for trigger %q", t.ActionTime, t.Name)
+	case t.For != "" && t.For != schema.TriggerForRow:
+		return "", fmt.Errorf("mysql: trigger %q must be a row-level trigger", t.Name)
+	}
+	return s.Build("CREATE TRIGGER").
+		Table(triggerName(t)).
+		P(string(t.ActionTime), string(t.Events[0]), "ON").
+		Table(t.Table).
+		P("FOR EACH ROW", t.Body).
+		String(), nil
+}
+
+// triggerName returns a table with the name and the schema of the trigger,
+// as triggers are qualified by the schema of their tables in MySQL.
+func triggerName(t *schema.Trigger) *schema.Table {
+	return &schema.Table{Name: t.Name, Schema: t.Table.Schema}
+}
+
+// Query to list the triggers of the schema tables.
+const triggersQuery = "SELECT `TRIGGER_NAME`, `EVENT_OBJECT_TABLE`, `ACTION_TIMING`, `EVENT_MANIPULATION`, `ACTION_ORIENTATION`, `ACTION_STATEMENT` FROM `INFORMATION_SCHEMA`.`TRIGGERS` WHERE `TRIGGER_SCHEMA` = ? AND `EVENT_OBJECT_TABLE` IN (%s) ORDER BY `TRIGGER_NAME`"
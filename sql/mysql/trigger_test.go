@@ -0,0 +1,63 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect_Triggers(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(triggersQuery, "?"))).
+		WithArgs("public", "users").
+		WillReturnRows(sqltest.Rows(`
+ TRIGGER_NAME  | EVENT_OBJECT_TABLE | ACTION_TIMING | EVENT_MANIPULATION | ACTION_ORIENTATION | ACTION_STATEMENT
+---------------+--------------------+---------------+--------------------+--------------------+------------------
+ users_insert  | users              | AFTER         | INSERT             | ROW                | SET @n = @n + 1
+`))
+	users := schema.NewTable("users")
+	s := schema.New("public").AddTables(users)
+	err = (&inspect{&conn{ExecQuerier: db}}).triggers(context.Background(), s)
+	require.NoError(t, err)
+	require.Equal(t, []*schema.Trigger{
+		{
+			Name:       "users_insert",
+			Table:      users,
+			ActionTime: schema.TriggerTimeAfter,
+			Events:     []schema.TriggerEvent{schema.TriggerEventInsert},
+			For:        schema.TriggerForRow,
+			Body:       "SET @n = @n + 1",
+		},
+	}, users.Triggers)
+}
+
+func TestPlanChanges_Triggers(t *testing.T) {
+	users := schema.NewTable("users").SetSchema(schema.New("public"))
+	tr1 := &schema.Trigger{Name: "users_insert", ActionTime: schema.TriggerTimeAfter, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "SET @n = @n + 1"}
+	tr2 := &schema.Trigger{Name: "users_insert", ActionTime: schema.TriggerTimeBefore, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "SET @n = @n + 2"}
+	users.AddTriggers(tr1, tr2)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTrigger{From: tr1, To: tr2},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "DROP TRIGGER `public`.`users_insert`", plan.Changes[0].Cmd)
+	require.Equal(t, "CREATE TRIGGER `public`.`users_insert` AFTER INSERT ON `public`.`users` FOR EACH ROW SET @n = @n + 1", plan.Changes[0].Reverse)
+	require.Equal(t, "CREATE TRIGGER `public`.`users_insert` BEFORE INSERT ON `public`.`users` FOR EACH ROW SET @n = @n + 2", plan.Changes[1].Cmd)
+
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTrigger{T: &schema.Trigger{Name: "t", Table: users, ActionTime: schema.TriggerTimeInstead, Events: []schema.TriggerEvent{schema.TriggerEventInsert}}},
+	})
+	require.EqualError(t, err, `mysql: unexpected action time "INSTEAD OF" for trigger "t"`)
+}
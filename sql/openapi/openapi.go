@@ -0,0 +1,383 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package openapi generates OpenAPI 3.1 component schemas from database tables, so REST APIs
+// can stay in sync with the database model. Each table is mapped to an object schema, and each
+// column to a property with its type, nullability, enum values, default value and description.
+// For example:
+//
+//	c, err := openapi.Components(realm, openapi.WithProperty(func(t *schema.Table, c *schema.Column, s *openapi.Schema) (*openapi.Schema, error) {
+//		// Hide secrets from the API model.
+//		if c.Name == "password" {
+//			return nil, nil
+//		}
+//		return s, nil
+//	}))
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// JSON Schema types used by the generated schemas.
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeObject  = "object"
+	TypeNull    = "null"
+)
+
+type (
+	// Schema is an OpenAPI 3.1 (JSON Schema 2020-12) schema object.
+	Schema struct {
+		// Type holds the types of the schema. A single type is encoded as
+		// a string, and multiple types (e.g. nullable ones) as an array.
+		Type            Types              `json:"type,omitempty"`
+		Format          string             `json:"format,omitempty"`
+		Title           string             `json:"title,omitempty"`
+		Description     string             `json:"description,omitempty"`
+		Enum            []any              `json:"enum,omitempty"`
+		Default         any                `json:"default,omitempty"`
+		MaxLength       *int               `json:"maxLength,omitempty"`
+		Minimum         *float64           `json:"minimum,omitempty"`
+		ContentEncoding string             `json:"contentEncoding,omitempty"`
+		ReadOnly        bool               `json:"readOnly,omitempty"`
+		Properties      map[string]*Schema `json:"properties,omitempty"`
+		Required        []string           `json:"required,omitempty"`
+		// Extensions holds the specification extensions of the
+		// schema. Keys must start with "x-", e.g. "x-go-type".
+		Extensions map[string]any `json:"-"`
+	}
+
+	// Types is a list of JSON Schema types.
+	Types []string
+
+	// ComponentsObject holds the generated component schemas, keyed by their names.
+	ComponentsObject struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	}
+
+	// Option allows configuring the generation using functional options.
+	Option func(*options)
+
+	options struct {
+		name     func(*schema.Schema, *schema.Table) string
+		property func(*schema.Table, *schema.Column, *Schema) (*Schema, error)
+		types    func(*schema.Column) (*Schema, bool)
+	}
+)
+
+// WithName configures the function that returns the component name of a table.
+// By default, tables are named after their names, and tables that share their
+// names with tables in other schemas are qualified with their schema names.
+func WithName(f func(*schema.Schema, *schema.Table) string) Option {
+	return func(o *options) {
+		o.name = f
+	}
+}
+
+// WithProperty configures a hook that is called with every generated property schema. The
+// hook may modify the schema, replace it or return nil to omit the column from the component.
+func WithProperty(f func(*schema.Table, *schema.Column, *Schema) (*Schema, error)) Option {
+	return func(o *options) {
+		o.property = f
+	}
+}
+
+// WithTypes configures a mapper of column types that takes precedence over the default one.
+// The mapper reports false (or returns a nil schema) for columns it does not map, and the returned
+// schemas are completed with the nullability, default value and description of the columns.
+func WithTypes(f func(*schema.Column) (*Schema, bool)) Option {
+	return func(o *options) {
+		o.types = f
+	}
+}
+
+// Components generates the component schemas of the tables in the realm.
+func Components(r *schema.Realm, opts ...Option) (*ComponentsObject, error) {
+	o := &options{name: defaultName(r)}
+	for _, opt := range opts {
+		opt(o)
+	}
+	c := &ComponentsObject{Schemas: make(map[string]*Schema)}
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			name := o.name(s, t)
+			if _, ok := c.Schemas[name]; ok {
+				return nil, fmt.Errorf("openapi: duplicate component name %q for table %q", name, t.Name)
+			}
+			ts, err := o.table(t)
+			if err != nil {
+				return nil, err
+			}
+			c.Schemas[name] = ts
+		}
+	}
+	return c, nil
+}
+
+// table returns the object schema of the table.
+func (o *options) table(t *schema.Table) (*Schema, error) {
+	s := &Schema{
+		Type:        Types{TypeObject},
+		Title:       t.Name,
+		Description: comment(t.Attrs),
+		Properties:  make(map[string]*Schema),
+	}
+	for _, c := range t.Columns {
+		p, err := o.column(c)
+		if err != nil {
+			return nil, fmt.Errorf("openapi: mapping column %q.%q: %w", t.Name, c.Name, err)
+		}
+		if o.property != nil {
+			if p, err = o.property(t, c, p); err != nil {
+				return nil, err
+			}
+		}
+		// Omitted by the hook.
+		if p == nil {
+			continue
+		}
+		s.Properties[c.Name] = p
+		if !c.Type.Null {
+			s.Required = append(s.Required, c.Name)
+		}
+	}
+	return s, nil
+}
+
+// column returns the property schema of the column.
+func (o *options) column(c *schema.Column) (*Schema, error) {
+	if c.Type == nil || c.Type.Type == nil {
+		return nil, fmt.Errorf("missing column type")
+	}
+	var (
+		s  *Schema
+		ok bool
+	)
+	if o.types != nil {
+		s, ok = o.types(c)
+	}
+	if !ok || s == nil {
+		s = typeSchema(c.Type.Type)
+	}
+	if s.Description == "" {
+		s.Description = comment(c.Attrs)
+	}
+	if sqlx.Has(c.Attrs, &schema.GeneratedExpr{}) {
+		s.ReadOnly = true
+	}
+	if c.Default != nil && s.Default == nil {
+		v, err := defaultValue(s, c.Default)
+		if err != nil {
+			return nil, err
+		}
+		s.Default = v
+	}
+	if c.Type.Null && len(s.Type) > 0 && !s.Type.Nullable() {
+		s.Type = append(s.Type, TypeNull)
+		if len(s.Enum) > 0 {
+			s.Enum = append(s.Enum, nil)
+		}
+	}
+	return s, nil
+}
+
+// typeSchema returns the default schema of the given column type.
+func typeSchema(t schema.Type) *Schema {
+	switch t := t.(type) {
+	case *schema.BoolType:
+		return &Schema{Type: Types{TypeBoolean}}
+	case *schema.IntegerType:
+		s := &Schema{Type: Types{TypeInteger}, Format: "int32"}
+		switch strings.ToLower(t.T) {
+		case "bigint", "int8", "bigserial", "serial8":
+			s.Format = "int64"
+		}
+		if t.Unsigned {
+			s.Minimum = new(float64)
+		}
+		return s
+	case *schema.DecimalType:
+		s := &Schema{Type: Types{TypeNumber}}
+		if t.Unsigned {
+			s.Minimum = new(float64)
+		}
+		return s
+	case *schema.FloatType:
+		s := &Schema{Type: Types{TypeNumber}, Format: "double"}
+		switch strings.ToLower(t.T) {
+		case "float", "float4", "real":
+			// MySQL FLOAT types with precision above 24 are DOUBLE.
+			if t.Precision <= 24 {
+				s.Format = "float"
+			}
+		}
+		if t.Unsigned {
+			s.Minimum = new(float64)
+		}
+		return s
+	case *schema.StringType:
+		s := &Schema{Type: Types{TypeString}}
+		if size := t.Size; size > 0 {
+			s.MaxLength = &size
+		}
+		return s
+	case *schema.EnumType:
+		s := &Schema{Type: Types{TypeString}}
+		for _, v := range t.Values {
+			s.Enum = append(s.Enum, v)
+		}
+		return s
+	case *schema.TimeType:
+		s := &Schema{Type: Types{TypeString}, Format: "date-time"}
+		switch t := strings.ToLower(t.T); {
+		case t == "date":
+			s.Format = "date"
+		case strings.HasPrefix(t, "time") && !strings.HasPrefix(t, "timestamp"):
+			s.Format = "time"
+		case t == "year":
+			s.Type, s.Format = Types{TypeInteger}, "int32"
+		}
+		return s
+	case *schema.UUIDType:
+		return &Schema{Type: Types{TypeString}, Format: "uuid"}
+	case *schema.BinaryType:
+		return &Schema{Type: Types{TypeString}, ContentEncoding: "base64"}
+	case *schema.SpatialType:
+		return &Schema{Type: Types{TypeString}}
+	// JSON columns and types that are unknown to
+	// this package accept any value (an empty schema).
+	default:
+		return &Schema{}
+	}
+}
+
+// defaultValue returns the default value of a property from the column default. Only
+// literals are converted, as other expressions are evaluated by the database. Literals
+// that cannot be converted to the property type are skipped.
+func defaultValue(s *Schema, x schema.Expr) (any, error) {
+	lit, ok := x.(*schema.Literal)
+	if !ok || strings.EqualFold(lit.V, "null") {
+		return nil, nil
+	}
+	switch v := strings.Trim(lit.V, `'"`); {
+	case s.Type.Is(TypeBoolean):
+		switch strings.ToLower(v) {
+		case "true", "1", "t", "y", "yes", "on":
+			return true, nil
+		case "false", "0", "f", "n", "no", "off":
+			return false, nil
+		}
+	case s.Type.Is(TypeInteger):
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+	case s.Type.Is(TypeNumber):
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, nil
+		}
+	case s.Type.Is(TypeString):
+		return sqlx.Unquote(lit.V)
+	// Untyped schemas (e.g. JSON columns) and objects accept JSON values.
+	case len(s.Type) == 0, s.Type.Is(TypeObject):
+		u, err := sqlx.Unquote(lit.V)
+		if err != nil {
+			return nil, err
+		}
+		if json.Valid([]byte(u)) {
+			return json.RawMessage(u), nil
+		}
+	}
+	return nil, nil
+}
+
+// defaultName returns the default naming function of the realm tables.
+func defaultName(r *schema.Realm) func(*schema.Schema, *schema.Table) string {
+	names := make(map[string]int)
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			names[t.Name]++
+		}
+	}
+	return func(s *schema.Schema, t *schema.Table) string {
+		if names[t.Name] > 1 && s.Name != "" {
+			return s.Name + "." + t.Name
+		}
+		return t.Name
+	}
+}
+
+// Is reports if the given type is one of the types.
+func (t Types) Is(typ string) bool {
+	for _, v := range t {
+		if v == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Nullable reports if the types accept null values.
+func (t Types) Nullable() bool {
+	return t.Is(TypeNull)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Types) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = Types{s}
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
+// MarshalJSON implements json.Marshaler. Extensions are
+// inlined next to the standard fields of the schema.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type schemaT Schema
+	b, err := json.Marshal((*schemaT)(s))
+	if err != nil || len(s.Extensions) == 0 {
+		return b, err
+	}
+	m := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for k, v := range s.Extensions {
+		if !strings.HasPrefix(k, "x-") {
+			return nil, fmt.Errorf("openapi: extension %q must start with \"x-\"", k)
+		}
+		if m[k], err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(m)
+}
+
+// comment returns the comment text from the attributes, if exists.
+func comment(attrs []schema.Attr) string {
+	var c schema.Comment
+	if sqlx.Has(attrs, &c) {
+		return c.Text
+	}
+	return ""
+}
@@ -0,0 +1,179 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package openapi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"ariga.io/atlas/sql/openapi"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponents(t *testing.T) {
+	c, err := openapi.Components(testRealm())
+	require.NoError(t, err)
+	b, err := json.MarshalIndent(c, "", "  ")
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "schemas": {
+    "public.users": {
+      "type": "object",
+      "title": "users",
+      "description": "registered users",
+      "properties": {
+        "active": {
+          "type": "boolean",
+          "default": true
+        },
+        "data": {
+          "default": {
+            "a": 1
+          }
+        },
+        "id": {
+          "type": "integer",
+          "format": "int64",
+          "description": "user identifier",
+          "minimum": 0
+        },
+        "name": {
+          "type": [
+            "string",
+            "null"
+          ],
+          "default": "John O'Doe",
+          "maxLength": 255
+        },
+        "score": {
+          "type": "number",
+          "format": "double",
+          "default": 1.5
+        },
+        "slug": {
+          "type": "string",
+          "readOnly": true
+        },
+        "status": {
+          "type": [
+            "string",
+            "null"
+          ],
+          "enum": [
+            "active",
+            "banned",
+            null
+          ]
+        },
+        "updated_at": {
+          "type": "string",
+          "format": "date-time"
+        }
+      },
+      "required": [
+        "id",
+        "active",
+        "score",
+        "updated_at",
+        "slug"
+      ]
+    },
+    "sales.users": {
+      "type": "object",
+      "title": "users",
+      "properties": {
+        "uuid": {
+          "type": "string",
+          "format": "uuid"
+        }
+      },
+      "required": [
+        "uuid"
+      ]
+    }
+  }
+}`, string(b))
+}
+
+func TestComponents_Options(t *testing.T) {
+	c, err := openapi.Components(
+		testRealm(),
+		openapi.WithName(func(s *schema.Schema, t *schema.Table) string {
+			return s.Name + "_" + t.Name
+		}),
+		openapi.WithTypes(func(c *schema.Column) (*openapi.Schema, bool) {
+			switch c.Name {
+			case "data":
+				return &openapi.Schema{Type: openapi.Types{openapi.TypeObject}}, true
+			case "id":
+				// Nil schemas are treated as unmapped.
+				return nil, true
+			}
+			return nil, false
+		}),
+		openapi.WithProperty(func(t *schema.Table, c *schema.Column, s *openapi.Schema) (*openapi.Schema, error) {
+			switch c.Name {
+			case "name":
+				return nil, nil
+			case "id":
+				s.Extensions = map[string]any{"x-go-type": "int64"}
+			}
+			return s, nil
+		}),
+	)
+	require.NoError(t, err)
+	require.Len(t, c.Schemas, 2)
+	users := c.Schemas["public_users"]
+	require.NotContains(t, users.Properties, "name")
+	require.Equal(t, openapi.Types{openapi.TypeObject, openapi.TypeNull}, users.Properties["data"].Type, "mapped types are completed with nullability")
+	require.Equal(t, json.RawMessage(`{"a": 1}`), users.Properties["data"].Default)
+	b, err := json.Marshal(users.Properties["id"])
+	require.NoError(t, err)
+	require.Equal(t, `{"description":"user identifier","format":"int64","minimum":0,"type":"integer","x-go-type":"int64"}`, string(b))
+
+	// Duplicate names.
+	_, err = openapi.Components(testRealm(), openapi.WithName(func(_ *schema.Schema, t *schema.Table) string {
+		return t.Name
+	}))
+	require.EqualError(t, err, `openapi: duplicate component name "users" for table "users"`)
+
+	// Invalid extensions.
+	_, err = json.Marshal(&openapi.Schema{Extensions: map[string]any{"go-type": "int"}})
+	require.EqualError(t, err, `json: error calling MarshalJSON for type *openapi.Schema: openapi: extension "go-type" must start with "x-"`)
+}
+
+func TestTypes(t *testing.T) {
+	var ts openapi.Types
+	require.NoError(t, json.Unmarshal([]byte(`"string"`), &ts))
+	require.Equal(t, openapi.Types{"string"}, ts)
+	require.NoError(t, json.Unmarshal([]byte(`["string", "null"]`), &ts))
+	require.Equal(t, openapi.Types{"string", "null"}, ts)
+	require.True(t, ts.Nullable())
+}
+
+func testRealm() *schema.Realm {
+	id := schema.NewColumn("id").SetType(&schema.IntegerType{T: "bigint", Unsigned: true}).SetComment("user identifier")
+	slug := schema.NewStringColumn("slug", "text").SetGeneratedExpr(&schema.GeneratedExpr{Expr: "lower(name)"})
+	users := schema.NewTable("users").
+		SetComment("registered users").
+		AddColumns(
+			id,
+			schema.NewNullStringColumn("name", "varchar", schema.StringSize(255)).SetDefault(&schema.Literal{V: "'John O''Doe'"}),
+			schema.NewBoolColumn("active", "boolean").SetDefault(&schema.Literal{V: "true"}),
+			schema.NewFloatColumn("score", "double precision").SetDefault(&schema.Literal{V: "1.5"}),
+			schema.NewNullEnumColumn("status", schema.EnumName("status"), schema.EnumValues("active", "banned")),
+			schema.NewJSONColumn("data", "jsonb").SetDefault(&schema.Literal{V: `'{"a": 1}'`}),
+			schema.NewTimeColumn("updated_at", "timestamp").SetDefault(&schema.RawExpr{X: "now()"}),
+			slug,
+		)
+	// JSON columns accept any value, including null.
+	users.Columns[5].Type.Null = true
+	return schema.NewRealm(
+		schema.New("public").AddTables(users),
+		schema.New("sales").AddTables(schema.NewTable("users").AddColumns(schema.NewColumn("uuid").SetType(&schema.UUIDType{T: "uuid"}))),
+	)
+}
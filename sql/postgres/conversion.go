@@ -0,0 +1,346 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// UsingFunc returns the USING expression for converting a column to its new type,
+// and reports if it handles the given change. See RegisterUsing for more info.
+type UsingFunc func(t *schema.Table, c *schema.ModifyColumn) (string, bool)
+
+// usingFuncs holds the functions registered using RegisterUsing.
+var usingFuncs struct {
+	sync.RWMutex
+	fs []UsingFunc
+}
+
+// RegisterUsing registers a function for defining the USING expression of column type changes.
+// Functions are called in the order they were registered, and the first one that handles the change
+// takes precedence over the expression generated by the driver. For example:
+//
+//	postgres.RegisterUsing(func(t *schema.Table, c *schema.ModifyColumn) (string, bool) {
+//		if t.Name == "events" && c.To.Name == "created_at" {
+//			return `to_timestamp("created_at")`, true
+//		}
+//		return "", false
+//	})
+func RegisterUsing(f UsingFunc) {
+	usingFuncs.Lock()
+	defer usingFuncs.Unlock()
+	usingFuncs.fs = append(usingFuncs.fs, f)
+}
+
+// TypeConversion classifies the conversion of a column from one type to the other. Changes classified
+// as invalid have no cast in PostgreSQL, and cannot be planned without a registered UsingFunc.
+func TypeConversion(from, to schema.Type) migrate.Conversion {
+	c, _ := conversion(from, to)
+	return c
+}
+
+// using returns the USING expression for changing the column type, or an
+// empty string if the database can convert the existing values implicitly.
+func (s *state) using(t *schema.Table, c *schema.ModifyColumn) (string, error) {
+	usingFuncs.RLock()
+	fs := usingFuncs.fs
+	usingFuncs.RUnlock()
+	for _, f := range fs {
+		if x, ok := f(t, c); ok {
+			return x, nil
+		}
+	}
+	conv, explicit := conversion(c.From.Type.Type, c.To.Type.Type)
+	switch {
+	case conv == migrate.ConversionInvalid:
+		return "", fmt.Errorf("changing the type of column %q from %s to %s requires a USING expression (see postgres.RegisterUsing)", c.To.Name, typeString(c.From.Type), typeString(c.To.Type))
+	case !explicit:
+		return "", nil
+	}
+	to, err := s.formatType(c.To.Type.Type)
+	if err != nil {
+		return "", err
+	}
+	x := strings.TrimSpace(s.Build().Ident(c.To.Name).String())
+	switch from := c.From.Type.Type.(type) {
+	// Enums are converted through their text representation.
+	case *schema.EnumType:
+		x += "::" + TypeText
+	// Only the integer type can be cast to (and from) boolean.
+	case *schema.IntegerType:
+		if _, ok := c.To.Type.Type.(*schema.BoolType); ok && intRank(from) != 4 {
+			x += "::" + TypeInteger
+		}
+	case *schema.BoolType:
+		if to, ok := c.To.Type.Type.(*schema.IntegerType); ok && intRank(to) != 4 {
+			return x + "::" + TypeInteger, nil
+		}
+	}
+	return x + "::" + to, nil
+}
+
+// typeReversible reports if the type change of the column can be reverted.
+func (s *state) typeReversible(t *schema.Table, c *schema.ModifyColumn) bool {
+	_, err := s.using(t, &schema.ModifyColumn{From: c.To, To: c.From, Change: c.Change})
+	return err == nil
+}
+
+// conversion classifies the type conversion, and reports if the values must
+// be converted explicitly, as PostgreSQL does not have an implicit or an
+// assignment cast between the two types.
+func conversion(from, to schema.Type) (migrate.Conversion, bool) {
+	if s, ok := from.(*SerialType); ok {
+		from = s.IntegerType()
+	}
+	if s, ok := to.(*SerialType); ok {
+		to = s.IntegerType()
+	}
+	if f1, err := FormatType(from); err == nil {
+		if f2, err := FormatType(to); err == nil && f1 == f2 {
+			return migrate.ConversionSafe, false
+		}
+	}
+	// All types can be converted to string types using
+	// the automatic I/O conversion casts of the database.
+	if st, ok := to.(*schema.StringType); ok {
+		n2 := strSize(st)
+		if n2 == -1 {
+			return migrate.ConversionSafe, false
+		}
+		if fs, ok := from.(*schema.StringType); ok {
+			if n1 := strSize(fs); n1 != -1 && n1 <= n2 {
+				return migrate.ConversionSafe, false
+			}
+		}
+		return migrate.ConversionLossy, false
+	}
+	switch from := from.(type) {
+	// I/O conversion casts from string types are explicit, and
+	// might fail on values that are not valid in the new type.
+	case *schema.StringType:
+		return migrate.ConversionLossy, true
+	case *schema.IntegerType, *schema.DecimalType, *schema.FloatType:
+		return numericConversion(from, to)
+	case *schema.BoolType:
+		if _, ok := to.(*schema.IntegerType); ok {
+			return migrate.ConversionSafe, true
+		}
+	case *CurrencyType:
+		if d, ok := to.(*schema.DecimalType); ok {
+			if d.Precision == 0 {
+				return migrate.ConversionSafe, false
+			}
+			return migrate.ConversionLossy, false
+		}
+	case *schema.TimeType:
+		switch to := to.(type) {
+		case *schema.TimeType:
+			return timeConversion(from, to), false
+		case *IntervalType:
+			if timeAlias(from.T) == TypeTime {
+				return migrate.ConversionSafe, false
+			}
+		}
+	case *IntervalType:
+		switch to := to.(type) {
+		case *IntervalType:
+			if to.F == "" && precision(to.Precision) >= precision(from.Precision) {
+				return migrate.ConversionSafe, false
+			}
+			return migrate.ConversionLossy, false
+		case *schema.TimeType:
+			if timeAlias(to.T) == TypeTime {
+				return migrate.ConversionLossy, false
+			}
+		}
+	case *schema.JSONType:
+		switch to.(type) {
+		// Converting JSON to JSONB drops insignificant whitespace and duplicate keys.
+		case *schema.JSONType:
+			if strings.ToLower(from.T) == TypeJSONB {
+				return migrate.ConversionSafe, false
+			}
+			return migrate.ConversionLossy, false
+		case *schema.IntegerType, *schema.DecimalType, *schema.FloatType, *schema.BoolType:
+			if strings.ToLower(from.T) == TypeJSONB {
+				return migrate.ConversionLossy, true
+			}
+		}
+	case *NetworkType:
+		if to, ok := to.(*NetworkType); ok {
+			switch t1, t2 := strings.ToLower(from.T), strings.ToLower(to.T); {
+			case t1 == TypeCIDR && t2 == TypeInet, t1 == TypeMACAddr && t2 == TypeMACAddr8:
+				return migrate.ConversionSafe, false
+			case t1 == TypeInet && t2 == TypeCIDR, t1 == TypeMACAddr8 && t2 == TypeMACAddr:
+				return migrate.ConversionLossy, false
+			}
+		}
+	case *BitType:
+		switch to := to.(type) {
+		case *BitType:
+			if strings.ToLower(to.T) == TypeBitVar && (to.Len == 0 || to.Len >= from.Len) {
+				return migrate.ConversionSafe, false
+			}
+			return migrate.ConversionLossy, true
+		case *schema.IntegerType:
+			if intRank(to) >= 4 {
+				return migrate.ConversionLossy, true
+			}
+		}
+	case *schema.EnumType:
+		if _, ok := to.(*schema.EnumType); ok {
+			return migrate.ConversionLossy, true
+		}
+	case *ArrayType:
+		if to, ok := to.(*ArrayType); ok {
+			if from.Type == nil || to.Type == nil {
+				return migrate.ConversionLossy, false
+			}
+			return conversion(from.Type, to.Type)
+		}
+	case *schema.BinaryType, *schema.UUIDType, *XMLType, *TextSearchType, *RangeType, *schema.SpatialType:
+	// Types that are unknown to the driver (e.g., user-defined types),
+	// are left to the database to convert.
+	default:
+		return migrate.ConversionLossy, false
+	}
+	return migrate.ConversionInvalid, false
+}
+
+// numericConversion classifies the conversion between numeric types. Integers, decimals
+// and floating-point numbers can be converted to each other using assignment casts.
+func numericConversion(from, to schema.Type) (migrate.Conversion, bool) {
+	switch to := to.(type) {
+	case *schema.IntegerType:
+		if from, ok := from.(*schema.IntegerType); ok && intRank(to) >= intRank(from) {
+			return migrate.ConversionSafe, false
+		}
+		return migrate.ConversionLossy, false
+	case *schema.DecimalType:
+		switch from := from.(type) {
+		case *schema.IntegerType:
+			if to.Precision == 0 || to.Precision-to.Scale >= intDigits(from) {
+				return migrate.ConversionSafe, false
+			}
+		case *schema.DecimalType:
+			switch {
+			case to.Precision == 0:
+				return migrate.ConversionSafe, false
+			case from.Precision > 0 && to.Scale >= from.Scale && to.Precision-to.Scale >= from.Precision-from.Scale:
+				return migrate.ConversionSafe, false
+			}
+		}
+		return migrate.ConversionLossy, false
+	case *schema.FloatType:
+		f2, _ := FormatType(to)
+		switch from := from.(type) {
+		// Integers are stored exactly if they fit the mantissa of the float
+		// type (24 bits for real, and 53 bits for double precision).
+		case *schema.IntegerType:
+			if r := intRank(from); r == 2 || r == 4 && f2 == TypeDouble {
+				return migrate.ConversionSafe, false
+			}
+		case *schema.FloatType:
+			if f2 == TypeDouble {
+				return migrate.ConversionSafe, false
+			}
+		}
+		return migrate.ConversionLossy, false
+	case *schema.BoolType:
+		if _, ok := from.(*schema.IntegerType); ok {
+			return migrate.ConversionLossy, true
+		}
+	case *CurrencyType:
+		if _, ok := from.(*schema.FloatType); !ok {
+			return migrate.ConversionLossy, false
+		}
+	case *BitType:
+		if from, ok := from.(*schema.IntegerType); ok && intRank(from) >= 4 {
+			return migrate.ConversionLossy, true
+		}
+	}
+	return migrate.ConversionInvalid, false
+}
+
+// timeConversion classifies the conversion between date and time types.
+func timeConversion(from, to *schema.TimeType) migrate.Conversion {
+	t1, t2 := timeAlias(from.T), timeAlias(to.T)
+	switch {
+	case precision(to.Precision) < precision(from.Precision) && t2 != TypeDate:
+		return migrate.ConversionLossy
+	case t1 == t2:
+		return migrate.ConversionSafe
+	case t1 == TypeDate && (t2 == TypeTimestamp || t2 == TypeTimestampTZ),
+		t1 == TypeTimestamp && t2 == TypeTimestampTZ, t1 == TypeTimestampTZ && t2 == TypeTimestamp,
+		t1 == TypeTime && t2 == TypeTimeTZ:
+		return migrate.ConversionSafe
+	case (t1 == TypeTimestamp || t1 == TypeTimestampTZ) && (t2 == TypeDate || t2 == TypeTime || t2 == TypeTimeTZ),
+		t1 == TypeTimeTZ && t2 == TypeTime:
+		return migrate.ConversionLossy
+	}
+	return migrate.ConversionInvalid
+}
+
+// intRank returns the storage size of the integer type in bytes.
+func intRank(t *schema.IntegerType) int {
+	switch f, _ := FormatType(t); f {
+	case TypeSmallInt:
+		return 2
+	case TypeInteger:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// intDigits returns the number of decimal digits of the integer type.
+func intDigits(t *schema.IntegerType) int {
+	switch intRank(t) {
+	case 2:
+		return 5
+	case 4:
+		return 10
+	default:
+		return 19
+	}
+}
+
+// strSize returns the maximum length of the string type, or -1 if it is unlimited.
+func strSize(t *schema.StringType) int {
+	switch strings.ToLower(t.T) {
+	case TypeText, typeName:
+		return -1
+	case TypeChar, TypeCharacter:
+		if t.Size == 0 {
+			return 1
+		}
+	case TypeVarChar, TypeCharVar:
+		if t.Size == 0 {
+			return -1
+		}
+	}
+	return t.Size
+}
+
+// precision returns the fractional seconds precision of time types.
+func precision(p *int) int {
+	if p == nil {
+		return defaultTimePrecision
+	}
+	return *p
+}
+
+// typeString returns the formatted type for error messages.
+func typeString(t *schema.ColumnType) string {
+	if f, err := FormatType(t.Type); err == nil {
+		return f
+	}
+	return t.Raw
+}
@@ -0,0 +1,76 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeConversion(t *testing.T) {
+	p := func(i int) *int { return &i }
+	for _, tt := range []struct {
+		from, to schema.Type
+		want     migrate.Conversion
+		explicit bool
+	}{
+		// Numeric types.
+		{from: &schema.IntegerType{T: "int"}, to: &schema.IntegerType{T: "bigint"}, want: migrate.ConversionSafe},
+		{from: &schema.IntegerType{T: "bigint"}, to: &schema.IntegerType{T: "smallint"}, want: migrate.ConversionLossy},
+		{from: &SerialType{T: "serial"}, to: &schema.IntegerType{T: "int8"}, want: migrate.ConversionSafe},
+		{from: &schema.IntegerType{T: "integer"}, to: &schema.DecimalType{T: "numeric", Precision: 10}, want: migrate.ConversionSafe},
+		{from: &schema.IntegerType{T: "bigint"}, to: &schema.DecimalType{T: "numeric", Precision: 10}, want: migrate.ConversionLossy},
+		{from: &schema.DecimalType{T: "numeric", Precision: 10, Scale: 2}, to: &schema.DecimalType{T: "decimal", Precision: 12, Scale: 2}, want: migrate.ConversionSafe},
+		{from: &schema.DecimalType{T: "numeric", Precision: 10, Scale: 2}, to: &schema.DecimalType{T: "numeric", Precision: 10, Scale: 4}, want: migrate.ConversionLossy},
+		{from: &schema.DecimalType{T: "numeric"}, to: &schema.DecimalType{T: "numeric", Precision: 38}, want: migrate.ConversionLossy},
+		{from: &schema.IntegerType{T: "integer"}, to: &schema.FloatType{T: "double precision"}, want: migrate.ConversionSafe},
+		{from: &schema.IntegerType{T: "integer"}, to: &schema.FloatType{T: "real"}, want: migrate.ConversionLossy},
+		{from: &schema.FloatType{T: "real"}, to: &schema.FloatType{T: "float8"}, want: migrate.ConversionSafe},
+		{from: &schema.FloatType{T: "double precision"}, to: &schema.IntegerType{T: "bigint"}, want: migrate.ConversionLossy},
+		{from: &schema.IntegerType{T: "integer"}, to: &schema.BoolType{T: "boolean"}, want: migrate.ConversionLossy, explicit: true},
+		{from: &schema.BoolType{T: "bool"}, to: &schema.IntegerType{T: "integer"}, want: migrate.ConversionSafe, explicit: true},
+		{from: &schema.FloatType{T: "real"}, to: &schema.BoolType{T: "boolean"}, want: migrate.ConversionInvalid},
+
+		// String types.
+		{from: &schema.StringType{T: "varchar", Size: 10}, to: &schema.StringType{T: "varchar", Size: 20}, want: migrate.ConversionSafe},
+		{from: &schema.StringType{T: "varchar", Size: 20}, to: &schema.StringType{T: "varchar", Size: 10}, want: migrate.ConversionLossy},
+		{from: &schema.StringType{T: "text"}, to: &schema.StringType{T: "character varying", Size: 255}, want: migrate.ConversionLossy},
+		{from: &schema.StringType{T: "char"}, to: &schema.StringType{T: "varchar", Size: 1}, want: migrate.ConversionSafe},
+		{from: &schema.UUIDType{T: "uuid"}, to: &schema.StringType{T: "text"}, want: migrate.ConversionSafe},
+		{from: &schema.UUIDType{T: "uuid"}, to: &schema.StringType{T: "varchar", Size: 10}, want: migrate.ConversionLossy},
+		{from: &schema.StringType{T: "text"}, to: &schema.UUIDType{T: "uuid"}, want: migrate.ConversionLossy, explicit: true},
+		{from: &schema.StringType{T: "text"}, to: &schema.EnumType{T: "status"}, want: migrate.ConversionLossy, explicit: true},
+		{from: &schema.EnumType{T: "s1"}, to: &schema.EnumType{T: "s2"}, want: migrate.ConversionLossy, explicit: true},
+
+		// Time types.
+		{from: &schema.TimeType{T: "date"}, to: &schema.TimeType{T: "timestamp", Precision: p(6)}, want: migrate.ConversionSafe},
+		{from: &schema.TimeType{T: "timestamp", Precision: p(6)}, to: &schema.TimeType{T: "timestamp with time zone", Precision: p(6)}, want: migrate.ConversionSafe},
+		{from: &schema.TimeType{T: "timestamp", Precision: p(6)}, to: &schema.TimeType{T: "timestamp", Precision: p(3)}, want: migrate.ConversionLossy},
+		{from: &schema.TimeType{T: "timestamptz", Precision: p(6)}, to: &schema.TimeType{T: "date"}, want: migrate.ConversionLossy},
+		{from: &schema.TimeType{T: "date"}, to: &schema.TimeType{T: "time", Precision: p(6)}, want: migrate.ConversionInvalid},
+		{from: &schema.TimeType{T: "timestamp", Precision: p(6)}, to: &schema.IntegerType{T: "bigint"}, want: migrate.ConversionInvalid},
+
+		// Other types.
+		{from: &schema.JSONType{T: "jsonb"}, to: &schema.JSONType{T: "json"}, want: migrate.ConversionSafe},
+		{from: &schema.JSONType{T: "json"}, to: &schema.JSONType{T: "jsonb"}, want: migrate.ConversionLossy},
+		{from: &schema.JSONType{T: "jsonb"}, to: &schema.IntegerType{T: "integer"}, want: migrate.ConversionLossy, explicit: true},
+		{from: &schema.JSONType{T: "json"}, to: &schema.IntegerType{T: "integer"}, want: migrate.ConversionInvalid},
+		{from: &NetworkType{T: "cidr"}, to: &NetworkType{T: "inet"}, want: migrate.ConversionSafe},
+		{from: &NetworkType{T: "inet"}, to: &NetworkType{T: "cidr"}, want: migrate.ConversionLossy},
+		{from: &schema.UUIDType{T: "uuid"}, to: &schema.BinaryType{T: "bytea"}, want: migrate.ConversionInvalid},
+		{from: &ArrayType{T: "text[]", Type: &schema.StringType{T: "text"}}, to: &ArrayType{T: "integer[]", Type: &schema.IntegerType{T: "integer"}}, want: migrate.ConversionLossy, explicit: true},
+		{from: &UserDefinedType{T: "citext"}, to: &schema.StringType{T: "text"}, want: migrate.ConversionSafe},
+		{from: &UserDefinedType{T: "citext"}, to: &UserDefinedType{T: "hstore"}, want: migrate.ConversionLossy},
+	} {
+		c, explicit := conversion(tt.from, tt.to)
+		require.Equal(t, tt.want, c, "%+v -> %+v", tt.from, tt.to)
+		require.Equal(t, tt.explicit, explicit, "%+v -> %+v", tt.from, tt.to)
+		require.Equal(t, tt.want, TypeConversion(tt.from, tt.to))
+	}
+}
@@ -11,12 +11,37 @@ import (
 	"strconv"
 	"strings"
 
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
 )
 
+// typeDefs holds the custom types registered using RegisterType.
+var typeDefs = sqlx.NewTypeDefs()
+
+// RegisterType registers a custom column type, such as types installed by extensions,
+// that are parsed, formatted and compared using the given definition, instead of being
+// reported as unsupported. Registered types take precedence over the builtin types of
+// the driver. For example:
+//
+//	postgres.RegisterType(&schema.TypeDef{
+//		Name: "citext",
+//		Parse: func(raw string) (schema.Type, error) {
+//			return &postgres.UserDefinedType{T: raw}, nil
+//		},
+//	})
+func RegisterType(d *schema.TypeDef) error {
+	if err := typeDefs.Register(d); err != nil {
+		return fmt.Errorf("postgres: register type: %w", err)
+	}
+	return nil
+}
+
 // FormatType converts schema type to its column form in the database.
 // An error is returned if the type cannot be recognized.
 func FormatType(t schema.Type) (string, error) {
+	if f, ok, err := typeDefs.Format(t); ok {
+		return f, err
+	}
 	var f string
 	switch t := t.(type) {
 	case *ArrayType:
@@ -140,6 +165,8 @@ func FormatType(t schema.Type) (string, error) {
 		f = strings.ToLower(t.T)
 	case *schema.SpatialType:
 		f = strings.ToLower(t.T)
+	case *GeometryType:
+		return formatGeometry(t)
 	case *NetworkType:
 		f = strings.ToLower(t.T)
 	case *RangeType:
@@ -182,6 +209,9 @@ func ParseType(typ string) (schema.Type, error) {
 		err error
 		d   *columnDesc
 	)
+	if t, ok, err := typeDefs.Parse(typ); ok {
+		return t, err
+	}
 	// Normalize PostgreSQL array data types from "CREATE TABLE" format to
 	// "INFORMATION_SCHEMA" format (i.e. as it is inspected from the database).
 	if t, ok := arrayType(typ); ok {
@@ -202,6 +232,9 @@ func ParseType(typ string) (schema.Type, error) {
 }
 
 func columnType(c *columnDesc) (schema.Type, error) {
+	if t, ok, err := typeDefs.Parse(c.fmtype); ok {
+		return t, err
+	}
 	var typ schema.Type
 	switch t := c.typ; strings.ToLower(t) {
 	case TypeBigInt, TypeInt8, TypeInt, TypeInteger, TypeInt4, TypeSmallInt, TypeInt2, TypeInt64:
@@ -218,8 +251,14 @@ func columnType(c *columnDesc) (schema.Type, error) {
 		typ = &schema.StringType{T: t, Size: int(c.size)}
 	case TypeCIDR, TypeInet, TypeMACAddr, TypeMACAddr8:
 		typ = &NetworkType{T: t}
-	case TypeCircle, TypeLine, TypeLseg, TypeBox, TypePath, TypePolygon, TypePoint, TypeGeometry:
+	case TypeCircle, TypeLine, TypeLseg, TypeBox, TypePath, TypePolygon, TypePoint:
 		typ = &schema.SpatialType{T: t}
+	case TypeGeometry, TypeGeography:
+		g, err := parseGeometry(c.parts)
+		if err != nil {
+			return nil, err
+		}
+		typ = g
 	case TypeDate:
 		typ = &schema.TimeType{T: t}
 	case TypeTime, TypeTimeWOTZ, TypeTimeTZ, TypeTimeWTZ, TypeTimestamp,
@@ -282,6 +321,12 @@ func columnType(c *columnDesc) (schema.Type, error) {
 		typ = &OIDType{T: t}
 	case TypeUserDefined:
 		typ = &UserDefinedType{T: c.fmtype}
+		// PostGIS types are reported as user-defined types.
+		if d, err := parseColumn(c.fmtype); err == nil && (d.typ == TypeGeometry || d.typ == TypeGeography) {
+			if typ, err = parseGeometry(d.parts); err != nil {
+				return nil, err
+			}
+		}
 	case typeAny, typeAnyElement, typeAnyArray, typeAnyNonArray, typeAnyEnum, typeInternal,
 		typeRecord, typeTrigger, typeVoid, typeUnknown:
 		typ = &PseudoType{T: t}
@@ -411,6 +456,8 @@ func parseColumn(s string) (*columnDesc, error) {
 			}
 			c.timePrecision = &i
 		}
+	case TypeGeometry, TypeGeography:
+		// Type modifiers are parsed by parseGeometry.
 	default:
 		c.typ = s
 	}
@@ -198,11 +198,11 @@ func (i *inspect) crdbAddIndexes(s *schema.Schema, rows *sql.Rows) error {
 	names := make(map[string]*schema.Index)
 	for rows.Next() {
 		var (
-			uniq, primary                        bool
+			uniq, primary, included              bool
 			table, name, createStmt              string
 			column, contype, pred, expr, comment sql.NullString
 		)
-		if err := rows.Scan(&table, &name, &column, &primary, &uniq, &contype, &createStmt, &pred, &expr, &comment); err != nil {
+		if err := rows.Scan(&table, &name, &column, &primary, &uniq, &contype, &createStmt, &pred, &expr, &comment, &included); err != nil {
 			return fmt.Errorf("cockroach: scanning indexes for schema %q: %w", s.Name, err)
 		}
 		t, ok := s.Table(table)
@@ -238,6 +238,18 @@ func (i *inspect) crdbAddIndexes(s *schema.Schema, rows *sql.Rows) error {
 				t.Indexes = append(t.Indexes, idx)
 			}
 		}
+		// Stored (covering) columns are not part of the index key.
+		if included {
+			c, ok := t.Column(column.String)
+			if !ok {
+				return fmt.Errorf("cockroach: STORING column %q was not found for index %q", column.String, idx.Name)
+			}
+			var include IndexInclude
+			sqlx.Has(idx.Attrs, &include)
+			include.Columns = append(include.Columns, c)
+			schema.ReplaceOrAppend(&idx.Attrs, &include)
+			continue
+		}
 		part := &schema.IndexPart{SeqNo: len(idx.Parts) + 1, Desc: strings.Contains(createStmt, "DESC")}
 		switch {
 		case sqlx.ValidString(column):
@@ -279,7 +291,8 @@ SELECT
 	pgi.indexdef create_stmt,
 	pg_get_expr(idx.indpred, idx.indrelid) AS predicate,
 	pg_get_indexdef(idx.indexrelid, idx.ord, false) AS expression,
-	pg_catalog.obj_description(i.oid, 'pg_class') AS comment
+	pg_catalog.obj_description(i.oid, 'pg_class') AS comment,
+	idx.ord > idx.indnkeyatts AS included
 	FROM
 	(
 		select
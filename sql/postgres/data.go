@@ -0,0 +1,192 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// planData plans the given data changes. Batched changes are executed
+// in an anonymous code block (DO statement) that loops until no rows
+// are affected by the batch statement, and commits after each batch to
+// release the row locks it holds. Hence, plans with batched changes
+// are not transactional.
+func (s *state) planData(changes []schema.Change) error {
+	for _, c := range changes {
+		var err error
+		switch c := c.(type) {
+		case *migrate.BackfillColumn:
+			err = s.backfill(c, c)
+		case *migrate.CopyTable:
+			err = s.copyTable(c)
+		case *migrate.RenameColumnSync:
+			err = s.renameColumnSync(c)
+		default:
+			err = fmt.Errorf("unsupported data change %T", c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfill plans the BackfillColumn change.
+func (s *state) backfill(c *migrate.BackfillColumn, src schema.Change) error {
+	column, expr := s.Build().Ident(c.C.Name).String(), c.Expr
+	if expr == "" && c.From != nil {
+		expr = s.Build().Ident(c.From.Name).String()
+	}
+	conds := []string{fmt.Sprintf("%s IS DISTINCT FROM (%s)", column, expr)}
+	if c.Where != "" {
+		conds = append([]string{"(" + c.Where + ")"}, conds...)
+	}
+	b := s.Build("UPDATE").Table(c.T).P("SET", column, "=", expr, "WHERE")
+	if c.BatchSize > 0 {
+		b.P("ctid IN (SELECT ctid FROM").Table(c.T).P("WHERE", strings.Join(conds, " AND "), "LIMIT", fmt.Sprint(c.BatchSize)+")")
+	} else {
+		b.P(strings.Join(conds, " AND "))
+	}
+	cmd, err := s.batchLoop(b.String(), c.BatchSize)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  src,
+		Cmd:     cmd,
+		Comment: fmt.Sprintf("backfill column %q of table %q", c.C.Name, c.T.Name),
+	})
+	return nil
+}
+
+// copyTable plans the CopyTable change.
+func (s *state) copyTable(c *migrate.CopyTable) error {
+	columns := c.CopyColumns()
+	b := s.Build("INSERT INTO").Table(c.To).Wrap(func(b *sqlx.Builder) {
+		b.MapComma(columns, func(i int, b *sqlx.Builder) {
+			b.Ident(columns[i])
+		})
+	})
+	// Values of identity columns that are always generated
+	// by the database can be copied only by overriding them.
+	var (
+		always bool
+		seqs   []*schema.Column
+	)
+	for _, name := range columns {
+		col, ok := c.To.Column(name)
+		if !ok {
+			continue
+		}
+		if i, ok := identity(col.Attrs); ok {
+			always = always || strings.EqualFold(i.Generation, "ALWAYS")
+			seqs = append(seqs, col)
+		} else if _, ok := col.Type.Type.(*SerialType); ok {
+			seqs = append(seqs, col)
+		}
+	}
+	if always {
+		b.P("OVERRIDING SYSTEM VALUE")
+	}
+	b.P("SELECT").MapComma(columns, func(i int, b *sqlx.Builder) {
+		b.Ident(columns[i])
+	})
+	b.P("FROM").Table(c.From)
+	if c.BatchSize > 0 {
+		key, err := sqlx.CopyKey(c)
+		if err != nil {
+			return err
+		}
+		conds := make([]string, len(key))
+		for i, k := range key {
+			conds[i] = fmt.Sprintf("atlas_dst.%[1]s = atlas_src.%[1]s", s.Build().Ident(k.Name).String())
+		}
+		b.P("AS atlas_src WHERE NOT EXISTS (SELECT 1 FROM").Table(c.To).P("AS atlas_dst WHERE", strings.Join(conds, " AND ")+")", "ORDER BY")
+		b.MapComma(key, func(i int, b *sqlx.Builder) {
+			b.Ident(key[i].Name)
+		})
+		b.P("LIMIT", fmt.Sprint(c.BatchSize))
+	}
+	cmd, err := s.batchLoop(b.String(), c.BatchSize)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Source:  c,
+		Cmd:     cmd,
+		Comment: fmt.Sprintf("copy rows from table %q to table %q", c.From.Name, c.To.Name),
+	})
+	// Copied values do not advance the sequences of the
+	// serial and identity columns, and therefore, set them.
+	for _, col := range seqs {
+		table, column := s.Build().Table(c.To).String(), s.Build().Ident(col.Name).String()
+		s.append(&migrate.Change{
+			Source: c,
+			Cmd: fmt.Sprintf(
+				"SELECT setval(pg_get_serial_sequence(%s, %s), COALESCE(MAX(%s), 1), MAX(%[3]s) IS NOT NULL) FROM %s",
+				quote(table), quote(col.Name), column, table,
+			),
+			Comment: fmt.Sprintf("set the sequence of column %q of table %q", col.Name, c.To.Name),
+		})
+	}
+	return nil
+}
+
+// renameColumnSync plans the RenameColumnSync change.
+func (s *state) renameColumnSync(c *migrate.RenameColumnSync) error {
+	if err := s.modifyTable(&schema.ModifyTable{T: c.T, Changes: []schema.Change{&schema.AddColumn{C: c.To}}}); err != nil {
+		return err
+	}
+	if !c.SkipBackfill {
+		if err := s.backfill(&migrate.BackfillColumn{T: c.T, C: c.To, From: c.From, BatchSize: c.BatchSize}, c); err != nil {
+			return err
+		}
+	}
+	var (
+		name     = sqlx.BoundName(c.SyncTriggerName(), maxNameLen)
+		fn       = s.schemaPrefix(c.T.Schema) + s.Build().Ident(name).String()
+		from, to = s.Build().Ident(c.From.Name).String(), s.Build().Ident(c.To.Name).String()
+	)
+	s.append(
+		&migrate.Change{
+			Source: c,
+			Cmd: fmt.Sprintf(
+				"CREATE FUNCTION %[1]s() RETURNS trigger AS $$ BEGIN IF TG_OP = 'INSERT' THEN NEW.%[3]s := COALESCE(NEW.%[3]s, NEW.%[2]s); NEW.%[2]s := COALESCE(NEW.%[2]s, NEW.%[3]s); ELSIF NEW.%[3]s IS DISTINCT FROM OLD.%[3]s THEN NEW.%[2]s := NEW.%[3]s; ELSE NEW.%[3]s := NEW.%[2]s; END IF; RETURN NEW; END $$ LANGUAGE plpgsql",
+				fn, from, to,
+			),
+			Reverse: fmt.Sprintf("DROP FUNCTION %s()", fn),
+			Comment: fmt.Sprintf("create function for syncing column %q with column %q", c.To.Name, c.From.Name),
+		},
+		&migrate.Change{
+			Source:  c,
+			Cmd:     s.Build("CREATE TRIGGER").Ident(name).P("BEFORE INSERT OR UPDATE ON").Table(c.T).P("FOR EACH ROW EXECUTE PROCEDURE", fn+"()").String(),
+			Reverse: s.Build("DROP TRIGGER").Ident(name).P("ON").Table(c.T).String(),
+			Comment: fmt.Sprintf("create trigger for syncing column %q with column %q", c.To.Name, c.From.Name),
+		},
+	)
+	return nil
+}
+
+// maxNameLen is the maximum length of identifiers (NAMEDATALEN-1).
+const maxNameLen = 63
+
+// batchLoop wraps the given statement with a loop that executes it until no rows
+// are affected, in case a batch size was set. Each batch is committed separately,
+// which is supported only in code blocks that are not executed in a transaction.
+func (s *state) batchLoop(stmt string, size int) (string, error) {
+	if size <= 0 {
+		return stmt, nil
+	}
+	if err := s.conn.require(featureTxControl); err != nil {
+		return "", err
+	}
+	s.Plan.Transactional = false
+	return fmt.Sprintf("DO $$ DECLARE atlas_rows bigint; BEGIN LOOP %s; GET DIAGNOSTICS atlas_rows = ROW_COUNT; COMMIT; EXIT WHEN atlas_rows = 0; END LOOP; END $$", stmt), nil
+}
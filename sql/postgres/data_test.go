@@ -0,0 +1,176 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges_Data(t *testing.T) {
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		first = schema.NewStringColumn("first_name", "text")
+		name  = schema.NewNullStringColumn("name", "text")
+		users = schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(id, first).
+			SetPrimaryKey(schema.NewPrimaryKey(id))
+		copies = schema.NewTable("users_copy").
+			SetSchema(users.Schema).
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("first_name", "text"))
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.BackfillColumn{T: users, C: first, Expr: "initcap(first_name)", Where: "id > 10"},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: name}}},
+		&migrate.BackfillColumn{T: users, C: name, Expr: "first_name", BatchSize: 100},
+		&migrate.CopyTable{From: users, To: copies, Columns: []string{"id", "first_name"}, BatchSize: 100},
+	})
+	require.NoError(t, err)
+	require.False(t, plan.Reversible)
+	require.False(t, plan.Transactional, "batches are committed separately")
+	require.Len(t, plan.Changes, 4)
+	// Data changes are planned after schema changes.
+	require.Equal(t, `ALTER TABLE "public"."users" ADD COLUMN "name" text NULL`, plan.Changes[0].Cmd)
+	require.Equal(t, `UPDATE "public"."users" SET "first_name" = initcap(first_name) WHERE (id > 10) AND "first_name" IS DISTINCT FROM (initcap(first_name))`, plan.Changes[1].Cmd)
+	require.Equal(t, `DO $$ DECLARE atlas_rows bigint; BEGIN LOOP UPDATE "public"."users" SET "name" = first_name WHERE ctid IN (SELECT ctid FROM "public"."users" WHERE "name" IS DISTINCT FROM (first_name) LIMIT 100); GET DIAGNOSTICS atlas_rows = ROW_COUNT; COMMIT; EXIT WHEN atlas_rows = 0; END LOOP; END $$`, plan.Changes[2].Cmd)
+	require.Equal(t, `DO $$ DECLARE atlas_rows bigint; BEGIN LOOP INSERT INTO "public"."users_copy" ("id", "first_name") SELECT "id", "first_name" FROM "public"."users" AS atlas_src WHERE NOT EXISTS (SELECT 1 FROM "public"."users_copy" AS atlas_dst WHERE atlas_dst."id" = atlas_src."id") ORDER BY "id" LIMIT 100; GET DIAGNOSTICS atlas_rows = ROW_COUNT; COMMIT; EXIT WHEN atlas_rows = 0; END LOOP; END $$`, plan.Changes[3].Cmd)
+
+	// Batched copies require a primary key.
+	_, err = DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.CopyTable{From: copies, To: users, BatchSize: 100},
+	})
+	require.EqualError(t, err, `copying table "users_copy" in batches requires a primary key`)
+
+	// Committing in code blocks requires PostgreSQL 11.
+	_, err = (&planApply{conn: &conn{ExecQuerier: sqlx.NoRows, version: 10_00_00}}).PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.BackfillColumn{T: users, C: name, Expr: "first_name", BatchSize: 100},
+	})
+	require.EqualError(t, err, `version "10" does not support transaction control in code blocks (requires PostgreSQL >= 11)`)
+
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.RenameColumnSync{T: users, From: first, To: schema.NewNullStringColumn("given_name", "text")},
+	})
+	require.NoError(t, err)
+	require.True(t, plan.Transactional)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, `ALTER TABLE "public"."users" ADD COLUMN "given_name" text NULL`, plan.Changes[0].Cmd)
+	require.Equal(t, `UPDATE "public"."users" SET "given_name" = "first_name" WHERE "given_name" IS DISTINCT FROM ("first_name")`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE FUNCTION "public"."atlas_sync_users_first_name_given_name"() RETURNS trigger AS $$ BEGIN IF TG_OP = 'INSERT' THEN NEW."given_name" := COALESCE(NEW."given_name", NEW."first_name"); NEW."first_name" := COALESCE(NEW."first_name", NEW."given_name"); ELSIF NEW."given_name" IS DISTINCT FROM OLD."given_name" THEN NEW."first_name" := NEW."given_name"; ELSE NEW."given_name" := NEW."first_name"; END IF; RETURN NEW; END $$ LANGUAGE plpgsql`, plan.Changes[2].Cmd)
+	require.Equal(t, `DROP FUNCTION "public"."atlas_sync_users_first_name_given_name"()`, plan.Changes[2].Reverse)
+	require.Equal(t, `CREATE TRIGGER "atlas_sync_users_first_name_given_name" BEFORE INSERT OR UPDATE ON "public"."users" FOR EACH ROW EXECUTE PROCEDURE "public"."atlas_sync_users_first_name_given_name"()`, plan.Changes[3].Cmd)
+	require.Equal(t, `DROP TRIGGER "atlas_sync_users_first_name_given_name" ON "public"."users"`, plan.Changes[3].Reverse)
+
+	// Generated names are bounded to the maximum identifier length.
+	long := schema.NewNullStringColumn(strings.Repeat("c", 60), "text")
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.RenameColumnSync{T: users, From: first, To: long, SkipBackfill: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Regexp(t, `^CREATE TRIGGER "atlas_sync_users_first_name_c+_[0-9a-f]{8}" `, plan.Changes[2].Cmd)
+	require.Len(t, strings.Fields(plan.Changes[2].Cmd)[2], maxNameLen+2)
+}
+
+func TestPlanChanges_CopySequences(t *testing.T) {
+	var (
+		blue, green = schema.New("blue"), schema.New("green")
+		newT        = func(s *schema.Schema) *schema.Table {
+			return schema.NewTable("users").
+				SetSchema(s).
+				AddColumns(
+					schema.NewIntColumn("id", "int").AddAttrs(&Identity{Generation: "ALWAYS"}),
+					schema.NewColumn("num").SetType(&SerialType{T: TypeSerial}),
+					schema.NewStringColumn("name", "text"),
+				)
+		}
+		from, to = newT(blue), newT(green)
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "copy", []schema.Change{
+		&migrate.CopyTable{From: from, To: to},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	// Identity values are copied as is, and the sequences are set after the copy.
+	require.Equal(t, `INSERT INTO "green"."users" ("id", "num", "name") OVERRIDING SYSTEM VALUE SELECT "id", "num", "name" FROM "blue"."users"`, plan.Changes[0].Cmd)
+	require.Equal(t, `SELECT setval(pg_get_serial_sequence('"green"."users"', 'id'), COALESCE(MAX("id"), 1), MAX("id") IS NOT NULL) FROM "green"."users"`, plan.Changes[1].Cmd)
+	require.Equal(t, `SELECT setval(pg_get_serial_sequence('"green"."users"', 'num'), COALESCE(MAX("num"), 1), MAX("num") IS NOT NULL) FROM "green"."users"`, plan.Changes[2].Cmd)
+
+	// Identities that are generated by default can be overridden without the clause.
+	to.Columns[0].Attrs = []schema.Attr{&Identity{Generation: "BY DEFAULT"}}
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "copy", []schema.Change{
+		&migrate.CopyTable{From: from, To: to, Columns: []string{"id", "name"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `INSERT INTO "green"."users" ("id", "name") SELECT "id", "name" FROM "blue"."users"`, plan.Changes[0].Cmd)
+	require.Equal(t, `SELECT setval(pg_get_serial_sequence('"green"."users"', 'id'), COALESCE(MAX("id"), 1), MAX("id") IS NOT NULL) FROM "green"."users"`, plan.Changes[1].Cmd)
+}
+
+func TestPlanExpandContract(t *testing.T) {
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		first = schema.NewStringColumn("first_name", "text")
+		price = schema.NewIntColumn("price", "int")
+		users = schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(id, first, price).
+			SetPrimaryKey(schema.NewPrimaryKey(id))
+	)
+	phases, err := migrate.PlanExpandContract(context.Background(), DefaultPlan, "rename", &migrate.ExpandContract{
+		T:         users,
+		From:      first,
+		To:        schema.NewStringColumn("given_name", "text"),
+		BatchSize: 100,
+	})
+	require.NoError(t, err)
+	require.Len(t, phases, 4)
+	for i, name := range []string{migrate.PhaseExpand, migrate.PhaseBackfill, migrate.PhaseSwap, migrate.PhaseContract} {
+		require.Equal(t, name, phases[i].Name)
+		require.Equal(t, "rename_"+name, phases[i].Plan.Name)
+		if i > 0 {
+			require.Less(t, phases[i-1].Plan.Version, phases[i].Plan.Version)
+		}
+	}
+	expand := phases[0].Plan.Changes
+	require.Len(t, expand, 3)
+	require.Equal(t, `ALTER TABLE "public"."users" ADD COLUMN "given_name" text NULL`, expand[0].Cmd)
+	require.Equal(t, `CREATE TRIGGER "atlas_sync_users_first_name_given_name" BEFORE INSERT OR UPDATE ON "public"."users" FOR EACH ROW EXECUTE PROCEDURE "public"."atlas_sync_users_first_name_given_name"()`, expand[2].Cmd)
+	require.Len(t, phases[1].Plan.Changes, 1)
+	require.Equal(t, `DO $$ DECLARE atlas_rows bigint; BEGIN LOOP UPDATE "public"."users" SET "given_name" = "first_name" WHERE ctid IN (SELECT ctid FROM "public"."users" WHERE "given_name" IS DISTINCT FROM ("first_name") LIMIT 100); GET DIAGNOSTICS atlas_rows = ROW_COUNT; COMMIT; EXIT WHEN atlas_rows = 0; END LOOP; END $$`, phases[1].Plan.Changes[0].Cmd)
+	require.Len(t, phases[2].Plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "given_name" SET NOT NULL`, phases[2].Plan.Changes[0].Cmd)
+	contract := phases[3].Plan.Changes
+	require.Len(t, contract, 3)
+	require.Equal(t, `DROP TRIGGER "atlas_sync_users_first_name_given_name" ON "public"."users"`, contract[0].Cmd)
+	require.Equal(t, `DROP FUNCTION "public"."atlas_sync_users_first_name_given_name"()`, contract[1].Cmd)
+	require.Equal(t, `drop function for syncing column "given_name" with column "first_name"`, contract[1].Comment)
+	require.Equal(t, `ALTER TABLE "public"."users" DROP COLUMN "first_name"`, contract[2].Cmd)
+	require.Equal(t, migrate.ClassDestructive|migrate.ClassBackwardIncompatible, contract[2].Class)
+
+	// Type changes use a temporary column that is renamed back after the old
+	// column is dropped. The swap phase is omitted for nullable columns.
+	phases, err = migrate.PlanExpandContract(context.Background(), DefaultPlan, "type", &migrate.ExpandContract{
+		T:    users,
+		From: price,
+		To:   schema.NewNullIntColumn("price", "bigint"),
+	})
+	require.NoError(t, err)
+	require.Len(t, phases, 3)
+	require.Equal(t, migrate.PhaseContract, phases[2].Name)
+	require.Equal(t, `ALTER TABLE "public"."users" ADD COLUMN "price_new" bigint NULL`, phases[0].Plan.Changes[0].Cmd)
+	require.Equal(t, `UPDATE "public"."users" SET "price_new" = "price" WHERE "price_new" IS DISTINCT FROM ("price")`, phases[1].Plan.Changes[0].Cmd)
+	contract = phases[2].Plan.Changes
+	require.Len(t, contract, 4)
+	require.Equal(t, `ALTER TABLE "public"."users" DROP COLUMN "price"`, contract[2].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" RENAME COLUMN "price_new" TO "price"`, contract[3].Cmd)
+}
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -26,12 +28,100 @@ var DefaultDiff schema.Differ = &sqlx.Diff{DiffDriver: &diff{&conn{ExecQuerier:
 // A diff provides a PostgreSQL implementation for sqlx.DiffDriver.
 type diff struct{ *conn }
 
+// defaultEquivs holds the DEFAULT expressions that are equivalent in PostgreSQL,
+// but are written differently in the desired state and the inspected database.
+var defaultEquivs = sqlx.NewEquivalents(
+	[]string{"CURRENT_TIMESTAMP", "now()", "transaction_timestamp()"},
+	[]string{"CURRENT_DATE", "('now'::text)::date"},
+	[]string{"LOCALTIMESTAMP", "('now'::text)::timestamp without time zone"},
+	[]string{
+		"now() AT TIME ZONE 'utc'", "now() AT TIME ZONE 'utc'::text",
+		"now() AT TIME ZONE 'UTC'", "now() AT TIME ZONE 'UTC'::text",
+		"timezone('utc', now())", "timezone('utc'::text, now())",
+		"timezone('UTC', now())", "timezone('UTC'::text, now())",
+		"CURRENT_TIMESTAMP AT TIME ZONE 'utc'", "CURRENT_TIMESTAMP AT TIME ZONE 'UTC'",
+		"timezone('utc'::text, CURRENT_TIMESTAMP)", "timezone('UTC'::text, CURRENT_TIMESTAMP)",
+	},
+)
+
+// RegisterDefaultEquivalents registers the given DEFAULT expressions as equivalent, and
+// changes between them are not reported by the differ. For example:
+//
+//	postgres.RegisterDefaultEquivalents("now() + '1 day'::interval", "CURRENT_TIMESTAMP + interval '1 day'")
+func RegisterDefaultEquivalents(exprs ...string) {
+	defaultEquivs.Register(exprs...)
+}
+
+// indexExprs computes the canonical form of index expressions. By default, the
+// text casts that PostgreSQL adds to string literals on inspection are removed.
+var indexExprs = sqlx.NewCanonicalizer(func(x string) string {
+	return reLitCast.ReplaceAllString(x, "$1")
+})
+
+var (
+	// reLitCast matches text casts of string literals (e.g. "'x'::text").
+	reLitCast = regexp.MustCompile(`('(?:[^']|'')*')::(?:text|character varying|varchar|bpchar)\b`)
+	// reColCast matches text casts of column references (e.g. "(name)::text").
+	reColCast = regexp.MustCompile(`\(("(?:[^"]|"")+"|[\p{L}_][\p{L}\p{N}_$]*)\)::(?:text|character varying|varchar|bpchar)\b|("(?:[^"]|"")+"|\b[\p{L}_][\p{L}\p{N}_$]*)::(?:text|character varying|varchar|bpchar)\b`)
+)
+
+// CanonicalIndexExpr returns the canonical form of the given index expression of the table.
+// Expressions with the same canonical form are considered equal by the differ, although one
+// of them was written by the user and the other was normalized by the database. Text casts of
+// columns are removed only for columns of character types (e.g. "lower((name)::text)"), as
+// these are the casts PostgreSQL adds on inspection, and casts of other types are kept.
+func CanonicalIndexExpr(t *schema.Table, x string) string {
+	if t != nil {
+		x = reColCast.ReplaceAllStringFunc(x, func(m string) string {
+			if s := reColCast.FindStringSubmatch(m); isCharColumn(t, s[1]+s[2]) {
+				return s[1] + s[2]
+			}
+			return m
+		})
+	}
+	return indexExprs.Canonical(x)
+}
+
+// isCharColumn reports if the identifier references a column of a character type.
+func isCharColumn(t *schema.Table, ident string) bool {
+	if strings.HasPrefix(ident, `"`) {
+		ident = strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+	} else {
+		ident = strings.ToLower(ident)
+	}
+	c, ok := t.Column(ident)
+	if !ok || c.Type == nil {
+		return false
+	}
+	_, ok = c.Type.Type.(*schema.StringType)
+	return ok
+}
+
+// RegisterIndexExprFuncs registers functions for computing the canonical form of index
+// expressions. For example, to match user-defined functions with their schema-qualified
+// form as inspected from the database:
+//
+//	postgres.RegisterIndexExprFuncs(func(x string) string {
+//		return strings.ReplaceAll(x, "public.unaccent_lower(", "unaccent_lower(")
+//	})
+func RegisterIndexExprFuncs(fns ...func(string) string) {
+	indexExprs.Register(fns...)
+}
+
+// CanonicalIndexExpr implements the sqlx.IndexExprCanonicalizer interface.
+func (*diff) CanonicalIndexExpr(t *schema.Table, x string) string {
+	return CanonicalIndexExpr(t, x)
+}
+
 // SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
 func (*diff) SchemaAttrDiff(from, to *schema.Schema) []schema.Change {
 	var changes []schema.Change
 	if change := sqlx.CommentDiff(skipDefaultComment(from), skipDefaultComment(to)); change != nil {
 		changes = append(changes, change)
 	}
+	if change := ownerDiff(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
 	return changes
 }
 
@@ -51,7 +141,10 @@ func (*diff) SchemaObjectDiff(from, to *schema.Schema) ([]schema.Change, error)
 	for _, o1 := range from.Objects {
 		e1, ok := o1.(*schema.EnumType)
 		if !ok {
-			return nil, fmt.Errorf("unsupported object type %T", o1)
+			if !isTextSearch(o1) {
+				return nil, fmt.Errorf("unsupported object type %T", o1)
+			}
+			continue
 		}
 		o2, ok := to.Object(func(o schema.Object) bool {
 			e2, ok := o.(*schema.EnumType)
@@ -69,7 +162,10 @@ func (*diff) SchemaObjectDiff(from, to *schema.Schema) ([]schema.Change, error)
 	for _, o1 := range to.Objects {
 		e1, ok := o1.(*schema.EnumType)
 		if !ok {
-			return nil, fmt.Errorf("unsupported object type %T", o1)
+			if !isTextSearch(o1) {
+				return nil, fmt.Errorf("unsupported object type %T", o1)
+			}
+			continue
 		}
 		if _, ok := from.Object(func(o schema.Object) bool {
 			e2, ok := o.(*schema.EnumType)
@@ -78,7 +174,12 @@ func (*diff) SchemaObjectDiff(from, to *schema.Schema) ([]schema.Change, error)
 			changes = append(changes, &schema.AddObject{O: e1})
 		}
 	}
-	return changes, nil
+	// Text search objects.
+	ts, err := textSearchDiff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, ts...), nil
 }
 
 // TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
@@ -87,9 +188,18 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
 		changes = append(changes, change)
 	}
+	if change := ownerDiff(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
 	if err := d.partitionChanged(from, to); err != nil {
 		return nil, err
 	}
+	switch change, err := hypertableChanged(from, to); {
+	case err != nil:
+		return nil, err
+	case change != nil:
+		changes = append(changes, change)
+	}
 	return append(changes, sqlx.CheckDiff(from, to, func(c1, c2 *schema.Check) bool {
 		return sqlx.Has(c1.Attrs, &NoInherit{}) == sqlx.Has(c2.Attrs, &NoInherit{})
 	})...), nil
@@ -114,7 +224,7 @@ func (d *diff) ColumnChange(_ *schema.Table, from, to *schema.Column) (schema.Ch
 	if changed {
 		change |= schema.ChangeDefault
 	}
-	if identityChanged(from.Attrs, to.Attrs) {
+	if identityChanged(from.Attrs, to.Attrs) || labelsChanged(from.Attrs, to.Attrs) {
 		change |= schema.ChangeAttr
 	}
 	if changed, err = d.generatedChanged(from, to); err != nil {
@@ -133,7 +243,7 @@ func (d *diff) defaultChanged(from, to *schema.Column) (bool, error) {
 	if ok1 != ok2 {
 		return true, nil
 	}
-	if !ok1 && !ok2 || trimCast(d1) == trimCast(d2) || quote(d1) == quote(d2) {
+	if !ok1 && !ok2 || trimCast(d1) == trimCast(d2) || quote(d1) == quote(d2) || defaultEquivs.Equal(d1, d2) {
 		return false, nil
 	}
 	var (
@@ -228,7 +338,7 @@ func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
 		return true
 	}
 	var p1, p2 IndexPredicate
-	if sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || (p1.P != p2.P && p1.P != sqlx.MayWrap(p2.P)) {
+	if sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || !sqlx.ExprEqual(p1.P, p2.P) {
 		return true
 	}
 	if indexIncludeChanged(from, to) {
@@ -236,7 +346,13 @@ func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
 	}
 	s1, ok1 := indexStorageParams(from)
 	s2, ok2 := indexStorageParams(to)
-	return ok1 != ok2 || ok1 && *s1 != *s2
+	if ok1 != ok2 || ok1 && *s1 != *s2 {
+		return true
+	}
+	// Raw parameters are compared only if they are defined in the desired
+	// state, as inspected indexes include all parameters that are not modeled.
+	raw := rawStorageParams(to)
+	return raw != nil && !sqlx.ValuesEqual(rawStorageParams(from), raw)
 }
 
 // IndexPartAttrChanged reports if the index-part attributes were changed.
@@ -279,6 +395,19 @@ func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
 	return from != to
 }
 
+// ForeignKeyAttrChanged reports if the foreign-key MATCH type was changed.
+func (*diff) ForeignKeyAttrChanged(from, to []schema.Attr) bool {
+	return fkMatch(from) != fkMatch(to)
+}
+
+// fkMatch returns the MATCH type of the foreign key. Defaults to SIMPLE.
+func fkMatch(attrs []schema.Attr) string {
+	if m := (ForeignKeyMatch{}); sqlx.Has(attrs, &m) && m.T != "" {
+		return strings.ToUpper(m.T)
+	}
+	return MatchSimple
+}
+
 // DiffOptions defines PostgreSQL specific schema diffing process.
 type DiffOptions struct {
 	ConcurrentIndex struct {
@@ -326,6 +455,9 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	if fromT == nil || toT == nil {
 		return false, fmt.Errorf("postgres: missing type information for column %q", from.Name)
 	}
+	if changed, ok, err := typeDefs.Changed(fromT, toT); ok {
+		return changed, err
+	}
 	if reflect.TypeOf(fromT) != reflect.TypeOf(toT) {
 		return true, nil
 	}
@@ -356,6 +488,8 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	case *CurrencyType:
 		toT := toT.(*CurrencyType)
 		changed = fromT.T != toT.T
+	case *GeometryType:
+		changed = geometryChanged(fromT, toT.(*GeometryType))
 	case *XMLType:
 		toT := toT.(*XMLType)
 		changed = fromT.T != toT.T
@@ -499,10 +633,29 @@ func indexStorageParams(attrs []schema.Attr) (*IndexStorageParams, bool) {
 	return s, true
 }
 
+// rawStorageParams returns the raw storage parameters from the attributes, sorted
+// by their name. Names are case-insensitive, and are returned in lower case.
+func rawStorageParams(attrs []schema.Attr) []string {
+	var r RawStorageParams
+	if !sqlx.Has(attrs, &r) {
+		return nil
+	}
+	params := make([]string, len(r.Params))
+	for i, p := range r.Params {
+		k, v, _ := strings.Cut(p, "=")
+		params[i] = strings.ToLower(strings.TrimSpace(k)) + "=" + strings.TrimSpace(v)
+	}
+	sort.Strings(params)
+	return params
+}
+
 // indexIncludeChanged reports if the INCLUDE attribute clause was changed.
 func indexIncludeChanged(from, to []schema.Attr) bool {
 	var fromI, toI IndexInclude
-	if sqlx.Has(from, &fromI) != sqlx.Has(to, &toI) || len(fromI.Columns) != len(toI.Columns) {
+	// An empty INCLUDE clause is the same as no clause.
+	sqlx.Has(from, &fromI)
+	sqlx.Has(to, &toI)
+	if len(fromI.Columns) != len(toI.Columns) {
 		return true
 	}
 	for i := range fromI.Columns {
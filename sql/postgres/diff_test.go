@@ -6,6 +6,8 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/schemahcl"
@@ -607,3 +609,176 @@ concurrent_index {
 	require.Equal(t, `CREATE INDEX CONCURRENTLY "users_pkey_new" ON "public"."users" ("id")`, plan.Changes[1].Cmd)
 	require.Equal(t, `DROP INDEX CONCURRENTLY "public"."users_pkey_new"`, plan.Changes[1].Reverse)
 }
+
+func TestDiff_DefaultEquivalents(t *testing.T) {
+	for _, tt := range []struct{ from, to string }{
+		{"CURRENT_TIMESTAMP", "now()"},
+		{"(now() AT TIME ZONE 'utc'::text)", "now() AT TIME ZONE 'utc'"},
+		{"timezone('utc'::text, now())", "(now() at time zone 'UTC')"},
+		{"('now'::text)::date", "CURRENT_DATE"},
+	} {
+		from := schema.NewTable("users").SetSchema(schema.New("public")).
+			AddColumns(schema.NewTimeColumn("c", "timestamp").SetDefault(&schema.RawExpr{X: tt.from}))
+		to := schema.NewTable("users").SetSchema(schema.New("public")).
+			AddColumns(schema.NewTimeColumn("c", "timestamp").SetDefault(&schema.RawExpr{X: tt.to}))
+		changes, err := DefaultDiff.TableDiff(from, to)
+		require.NoError(t, err)
+		require.Empty(t, changes, "%s = %s", tt.from, tt.to)
+	}
+
+	RegisterDefaultEquivalents("clock_timestamp()", "statement_timestamp()")
+	require.True(t, defaultEquivs.Equal("clock_timestamp()", "STATEMENT_TIMESTAMP()"))
+	require.False(t, defaultEquivs.Equal("clock_timestamp()", "now()"))
+}
+
+func TestDiff_NumericAliases(t *testing.T) {
+	for _, tt := range []struct {
+		from, to schema.Type
+		changed  bool
+	}{
+		{from: &schema.IntegerType{T: "integer"}, to: &schema.IntegerType{T: "int4"}},
+		{from: &schema.IntegerType{T: "bigint"}, to: &schema.IntegerType{T: "INT8"}},
+		{from: &schema.IntegerType{T: "integer"}, to: &schema.IntegerType{T: "bigint"}, changed: true},
+		{from: &schema.DecimalType{T: "numeric", Precision: 10, Scale: 2}, to: &schema.DecimalType{T: "decimal", Precision: 10, Scale: 2}},
+		{from: &schema.DecimalType{T: "numeric"}, to: &schema.DecimalType{T: "decimal", Precision: 10}, changed: true},
+		{from: &schema.FloatType{T: "double precision"}, to: &schema.FloatType{T: "float8"}},
+		{from: &schema.FloatType{T: "real"}, to: &schema.FloatType{T: "float", Precision: 24}},
+		{from: &schema.FloatType{T: "real"}, to: &schema.FloatType{T: "float"}, changed: true},
+	} {
+		from := schema.NewTable("users").SetSchema(schema.New("public")).
+			AddColumns(schema.NewColumn("c").SetType(tt.from))
+		to := schema.NewTable("users").SetSchema(schema.New("public")).
+			AddColumns(schema.NewColumn("c").SetType(tt.to))
+		changes, err := DefaultDiff.TableDiff(from, to)
+		require.NoError(t, err)
+		require.Equal(t, tt.changed, len(changes) == 1, "%+v -> %+v", tt.from, tt.to)
+
+		// Exact spelling reports all alias changes.
+		changes, err = DefaultDiff.TableDiff(from, to, schema.DiffExactTypes())
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.True(t, changes[0].(*schema.ModifyColumn).Change.Is(schema.ChangeType))
+	}
+}
+
+func TestDiff_ColumnPosition(t *testing.T) {
+	from := schema.NewTable("users").SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int"))
+	to := schema.NewTable("users").SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("b", "int"), schema.NewIntColumn("a", "int"))
+	// PostgreSQL cannot move columns without rebuilding the table.
+	changes, err := DefaultDiff.TableDiff(from, to, schema.DiffColumnOrder())
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_IndexExprCanonical(t *testing.T) {
+	table := func(xs ...string) *schema.Table {
+		t := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewStringColumn("name", "varchar"),
+			schema.NewStringColumn("Nick", "varchar"),
+		)
+		for i, x := range xs {
+			t.AddIndexes(schema.NewIndex(fmt.Sprintf("idx%d", i)).AddExprs(&schema.RawExpr{X: x}))
+		}
+		return t
+	}
+	// Inspected expressions on the left, and user-written expressions on the right.
+	changes, err := DefaultDiff.TableDiff(
+		table("lower((name)::text)", "(((name)::text || 'x'::text))", `upper(("Nick")::character varying)`, "f(name)", "((id)::text)"),
+		table("LOWER(name)", "name || 'x'", `upper("Nick"::varchar)`, "g(name)", "id"),
+	)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, "idx3", changes[0].(*schema.ModifyIndex).To.Name)
+	// Casts of non-character columns are intentional.
+	require.Equal(t, "idx4", changes[1].(*schema.ModifyIndex).To.Name)
+	require.Equal(t, "lower(name)", CanonicalIndexExpr(table(), "lower((name)::text)"))
+	require.Equal(t, "(id)::text", CanonicalIndexExpr(table(), "(id)::text"))
+	require.Equal(t, "lower((name)::text)", CanonicalIndexExpr(nil, "lower((name)::text)"))
+	require.Equal(t, "lower('x')", CanonicalIndexExpr(nil, "lower('x'::text)"))
+
+	// Custom functions.
+	RegisterIndexExprFuncs(func(x string) string {
+		return strings.ReplaceAll(x, "public.unaccent_lower(", "unaccent_lower(")
+	})
+	changes, err = DefaultDiff.TableDiff(table("public.unaccent_lower((name)::text)"), table("unaccent_lower(name)"))
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_RawStorageParams(t *testing.T) {
+	table := func(attrs ...schema.Attr) *schema.Table {
+		t := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "int"))
+		return t.AddIndexes(schema.NewIndex("users_id").AddColumns(t.Columns[0]).AddAttrs(attrs...))
+	}
+	// Parameters that are not modeled are inspected as raw parameters.
+	p, raw, err := newIndexStorage("{fillfactor=70}")
+	require.NoError(t, err)
+	inspected := table(p, raw)
+
+	// Raw parameters are ignored if they are not defined in the desired state.
+	changes, err := DefaultDiff.TableDiff(inspected, table())
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	changes, err = DefaultDiff.TableDiff(inspected, table(&RawStorageParams{Params: []string{"FILLFACTOR = 70"}}))
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	changes, err = DefaultDiff.TableDiff(inspected, table(&RawStorageParams{Params: []string{"fillfactor=80"}}))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "users_id", changes[0].(*schema.ModifyIndex).To.Name)
+	changes, err = DefaultDiff.TableDiff(table(), table(&RawStorageParams{Params: []string{"fillfactor=70"}}))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+}
+
+func TestDiff_SearchPath(t *testing.T) {
+	from := schema.NewRealm(
+		schema.New("public").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")),
+		),
+	)
+	to := schema.NewRealm(
+		schema.New("").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text")),
+		),
+	)
+	// Unqualified schemas are added without a search path.
+	changes, err := DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.IsType(t, &schema.DropSchema{}, changes[0])
+	require.IsType(t, &schema.AddSchema{}, changes[1])
+
+	changes, err = DefaultDiff.RealmDiff(from, to, schema.DiffSearchPath("app", "public"))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	modify := changes[0].(*schema.ModifyTable)
+	require.Equal(t, "users", modify.T.Name)
+	require.Len(t, modify.Changes, 1)
+	require.Equal(t, "name", modify.Changes[0].(*schema.AddColumn).C.Name)
+
+	// Referenced tables are compared by their schemas.
+	realm := func(ref string) *schema.Realm {
+		r := schema.NewRealm(
+			schema.New("app").AddTables(schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))),
+			schema.New("public").AddTables(schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))),
+		)
+		s, _ := r.Schema(ref)
+		users, _ := s.Table("users")
+		posts := schema.NewTable("posts").AddColumns(schema.NewIntColumn("author_id", "int"))
+		posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+		r.Schemas[1].AddTables(posts)
+		return r
+	}
+	changes, err = DefaultDiff.RealmDiff(realm("public"), realm("app"), schema.DiffSearchPath("public"))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	fk := changes[0].(*schema.ModifyTable).Changes[0].(*schema.ModifyForeignKey)
+	require.True(t, fk.Change.Is(schema.ChangeRefTable))
+	changes, err = DefaultDiff.RealmDiff(realm("public"), realm("public"), schema.DiffSearchPath("public"))
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
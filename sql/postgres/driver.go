@@ -10,8 +10,11 @@ import (
 	"errors"
 	"fmt"
 	"hash/fnv"
+	"math"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"ariga.io/atlas/sql/internal/sqlx"
@@ -38,6 +41,9 @@ type (
 		// System variables that are set on `Open`.
 		version int
 		crdb    bool
+		profile Profile
+		// TimescaleDB is loaded by the server.
+		timescale bool
 	}
 )
 
@@ -55,9 +61,9 @@ func init() {
 	)
 }
 
-func opener(_ context.Context, u *url.URL) (*sqlclient.Client, error) {
+func opener(ctx context.Context, u *url.URL) (*sqlclient.Client, error) {
 	ur := parser{}.ParseURL(u)
-	db, err := sql.Open(DriverName, ur.DSN)
+	db, err := sqlclient.OpenSQL(ctx, u)
 	if err != nil {
 		return nil, err
 	}
@@ -89,21 +95,24 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 	if err != nil {
 		return nil, fmt.Errorf("postgres: scanning system variables: %w", err)
 	}
-	params, err := sqlx.ScanStrings(rows)
+	params, err := scanParams(rows)
 	if err != nil {
 		return nil, fmt.Errorf("postgres: failed scanning rows: %w", err)
 	}
-	if len(params) != 1 && len(params) != 2 {
-		return nil, fmt.Errorf("postgres: unexpected number of rows: %d", len(params))
+	v, ok := params["server_version_num"]
+	if !ok {
+		return nil, errors.New("postgres: missing server_version_num setting")
 	}
-	if c.version, err = strconv.Atoi(params[0]); err != nil {
-		return nil, fmt.Errorf("postgres: malformed version: %s: %w", params[0], err)
+	if c.version, err = strconv.Atoi(v); err != nil {
+		return nil, fmt.Errorf("postgres: malformed version: %s: %w", v, err)
 	}
 	if c.version < 10_00_00 {
 		return nil, fmt.Errorf("postgres: unsupported postgres version: %d", c.version)
 	}
+	c.profile = profileFor(params)
+	_, c.timescale = params[timescaleParam]
 	// Means we are connected to CockroachDB because we have a result for name='crdb_version'. see `paramsQuery`.
-	if c.crdb = len(params) == 2; c.crdb {
+	if _, c.crdb = params["crdb_version"]; c.crdb {
 		return noLockDriver{
 			&Driver{
 				conn:        c,
@@ -113,12 +122,17 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 			},
 		}, nil
 	}
-	return &Driver{
+	drv := &Driver{
 		conn:        c,
 		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
 		Inspector:   &inspect{c},
 		PlanApplier: &planApply{c},
-	}, nil
+	}
+	// Advisory locks are not supported by YugabyteDB.
+	if c.profile == ProfileYugabyte {
+		return noLockDriver{drv}, nil
+	}
+	return drv, nil
 }
 
 func (d *Driver) dev() *sqlx.DevDriver {
@@ -132,14 +146,55 @@ func (d *Driver) dev() *sqlx.DevDriver {
 	}
 }
 
-// NormalizeRealm returns the normal representation of the given database.
+// NormalizeRealm returns the normal representation of the given database. Owners
+// are not set in the dev database, and are copied to the normalized objects.
 func (d *Driver) NormalizeRealm(ctx context.Context, r *schema.Realm) (*schema.Realm, error) {
-	return d.dev().NormalizeRealm(ctx, r)
+	restore := detachOwners(r.Schemas...)
+	nr, err := d.dev().NormalizeRealm(ctx, r)
+	restore()
+	if err != nil {
+		return nil, err
+	}
+	for _, s1 := range r.Schemas {
+		if s2, ok := nr.Schema(s1.Name); ok {
+			copyOwners(s1, s2)
+		}
+	}
+	return nr, nil
 }
 
-// NormalizeSchema returns the normal representation of the given database.
+// NormalizeSchema returns the normal representation of the given database. Owners
+// are not set in the dev database, and are copied to the normalized objects.
 func (d *Driver) NormalizeSchema(ctx context.Context, s *schema.Schema) (*schema.Schema, error) {
-	return d.dev().NormalizeSchema(ctx, s)
+	restore := detachOwners(s)
+	ns, err := d.dev().NormalizeSchema(ctx, s)
+	restore()
+	if err != nil {
+		return nil, err
+	}
+	copyOwners(s, ns)
+	return ns, nil
+}
+
+// InferViewColumns implements the schema.ViewInferrer interface.
+func (d *Driver) InferViewColumns(ctx context.Context, r *schema.Realm) error {
+	return d.dev().InferViewColumns(ctx, r, &sqlx.Builder{QuoteOpening: '"', QuoteClosing: '"'}, viewColumnType)
+}
+
+// viewColumnType converts the type of a view column, as reported by the database driver,
+// to a schema type. Drivers report the type names of pg_type (e.g. INT4 or _TEXT).
+func viewColumnType(ct *sql.ColumnType) (schema.Type, error) {
+	t := strings.ToLower(ct.DatabaseTypeName())
+	if strings.HasPrefix(t, "_") {
+		return ParseType(t[1:] + "[]")
+	}
+	if p, s, ok := ct.DecimalSize(); ok && p > 0 {
+		return ParseType(fmt.Sprintf("%s(%d,%d)", t, p, s))
+	}
+	if n, ok := ct.Length(); ok && n > 0 && n < math.MaxInt32 {
+		return ParseType(fmt.Sprintf("%s(%d)", t, n))
+	}
+	return ParseType(t)
 }
 
 // Lock implements the schema.Locker interface.
@@ -157,7 +212,8 @@ func (d *Driver) Lock(ctx context.Context, name string, timeout time.Duration) (
 	}
 	return func() error {
 		defer conn.Close()
-		rows, err := conn.QueryContext(ctx, "SELECT pg_advisory_unlock($1)", id)
+		// Locks are released also if the context was canceled (e.g. an aborted execution).
+		rows, err := conn.QueryContext(sqlx.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", id)
 		if err != nil {
 			return err
 		}
@@ -275,6 +331,105 @@ func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error
 	return nil
 }
 
+// SwapSchemas implements migrate.SchemaSwapper. The schemas are renamed in a single
+// anonymous code block (DO statement), and therefore, are swapped atomically. Note,
+// objects that qualify other objects with the schema name in their definitions
+// (e.g., function bodies) are not modified.
+func (d *Driver) SwapSchemas(ctx context.Context, a, b string) error {
+	var (
+		q   = func(s string) string { return (&sqlx.Builder{QuoteOpening: '"', QuoteClosing: '"'}).Ident(s).String() }
+		tmp = q("atlas_swap_" + a)
+	)
+	if _, err := d.ExecContext(ctx, fmt.Sprintf(
+		"DO $$ BEGIN ALTER SCHEMA %[1]s RENAME TO %[3]s; ALTER SCHEMA %[2]s RENAME TO %[1]s; ALTER SCHEMA %[3]s RENAME TO %[2]s; END $$",
+		q(a), q(b), tmp,
+	)); err != nil {
+		return fmt.Errorf("sql/postgres: swap schemas %q and %q: %w", a, b, err)
+	}
+	return nil
+}
+
+// reNextvalRef matches the sequence references of "nextval" expressions.
+var reNextvalRef = regexp.MustCompile(`(?i)nextval\('(?:"?([\w$]+)"?\.)?"?([\w$]+)"?'(?:::regclass)?\)`)
+
+// CloneSequences implements migrate.SequenceCloner. The sequences of schema from that are not owned
+// by serial or identity columns (which are created along with their columns) are created in schema s,
+// and column defaults that reference them, either qualified or resolved by the search_path, are rewritten
+// to reference the created sequences.
+func (d *Driver) CloneSequences(ctx context.Context, from string, s *schema.Schema) (func(context.Context) error, error) {
+	if d.crdb {
+		return nil, errors.New("sql/postgres: cloning sequences is not supported by CockroachDB")
+	}
+	rows, err := d.QueryContext(ctx, sequencesQuery, from)
+	if err != nil {
+		return nil, fmt.Errorf("sql/postgres: query sequences of schema %q: %w", from, err)
+	}
+	type sequence struct {
+		name, typ             string
+		start, min, max, incr int64
+		cycle                 bool
+	}
+	var seqs []*sequence
+	for rows.Next() {
+		q := &sequence{}
+		if err := rows.Scan(&q.name, &q.typ, &q.start, &q.min, &q.max, &q.incr, &q.cycle); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("sql/postgres: scan sequence: %w", err)
+		}
+		seqs = append(seqs, q)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	var (
+		b       = (&state{}).Build
+		created = make(map[string]string, len(seqs))
+	)
+	for _, q := range seqs {
+		ident := b().Table(&schema.Table{Name: q.name, Schema: s}).String()
+		cycle := "NO CYCLE"
+		if q.cycle {
+			cycle = "CYCLE"
+		}
+		if _, err := d.ExecContext(ctx, fmt.Sprintf(
+			"CREATE SEQUENCE %s AS %s INCREMENT BY %d MINVALUE %d MAXVALUE %d START WITH %d %s",
+			ident, q.typ, q.incr, q.min, q.max, q.start, cycle,
+		)); err != nil {
+			return nil, fmt.Errorf("sql/postgres: create sequence %s: %w", ident, err)
+		}
+		created[q.name] = ident
+	}
+	for _, t := range s.Tables {
+		for _, c := range t.Columns {
+			x, ok := c.Default.(*schema.RawExpr)
+			if !ok {
+				continue
+			}
+			x.X = reNextvalRef.ReplaceAllStringFunc(x.X, func(m string) string {
+				sm := reNextvalRef.FindStringSubmatch(m)
+				if ident, ok := created[sm[2]]; ok && (sm[1] == "" || sm[1] == from) {
+					return fmt.Sprintf("nextval(%s::regclass)", quote(ident))
+				}
+				return m
+			})
+		}
+	}
+	return func(ctx context.Context) error {
+		for _, q := range seqs {
+			src := b().Table(&schema.Table{Name: q.name, Schema: &schema.Schema{Name: from}}).String()
+			if _, err := d.ExecContext(ctx, fmt.Sprintf("SELECT setval(%s, last_value, is_called) FROM %s", quote(created[q.name]), src)); err != nil {
+				return fmt.Errorf("sql/postgres: set sequence %s: %w", created[q.name], err)
+			}
+		}
+		return nil
+	}, nil
+}
+
+// QuoteIdent returns the given identifier quoted, using the builder of the driver.
+func (*Driver) QuoteIdent(s string) string {
+	return (&state{}).Build().Ident(s).String()
+}
+
 // Version returns the version of the connected database.
 func (d *Driver) Version() string {
 	return strconv.Itoa(d.conn.version)
@@ -315,14 +470,54 @@ func acquire(ctx context.Context, conn schema.ExecQuerier, id uint32, timeout ti
 	}
 }
 
+// A feature is a server capability that is gated by its version.
+type feature struct {
+	name    string
+	version int // Minimum version, in the server_version_num format.
+}
+
+// List of features that depend on the server version.
+var (
+	featureIndexInclude            = feature{name: "the INCLUDE clause of indexes", version: 11_00_00}
+	featureIndexNullsDistinct      = feature{name: "the NULLS [NOT] DISTINCT clause of indexes", version: 15_00_00}
+	featureGeneratedColumns        = feature{name: "generated columns", version: 12_00_00}
+	featureHashPartition           = feature{name: "hash partitioning", version: 11_00_00}
+	featureProcedures              = feature{name: "procedures", version: 11_00_00}
+	featurePartitionedTableIndexes = feature{name: "indexes on partitioned tables", version: 11_00_00}
+	featureTxControl               = feature{name: "transaction control in code blocks", version: 11_00_00}
+)
+
+// supports reports if the server supports the given feature.
+func (c *conn) supports(f feature) bool {
+	return c.version >= f.version
+}
+
+// require returns an error describing the minimum server version required for the given
+// feature, if it is not supported by the server. Unknown versions (e.g. DefaultPlan) and
+// CockroachDB, which does not follow the PostgreSQL versioning, are not checked.
+func (c *conn) require(f feature) error {
+	if c.version == 0 || c.crdb || c.supports(f) {
+		return nil
+	}
+	return fmt.Errorf("version %q does not support %s (requires PostgreSQL >= %s)", versionString(c.version), f.name, versionString(f.version))
+}
+
+// versionString formats a version number in the server_version_num format.
+func versionString(v int) string {
+	if v%10000 == 0 {
+		return strconv.Itoa(v / 10000)
+	}
+	return fmt.Sprintf("%d.%d", v/10000, v%10000)
+}
+
 // supportsIndexInclude reports if the server supports the INCLUDE clause.
 func (c *conn) supportsIndexInclude() bool {
-	return c.version >= 11_00_00
+	return c.supports(featureIndexInclude)
 }
 
 // supportsIndexNullsDistinct reports if the server supports the NULLS [NOT] DISTINCT clause.
 func (c *conn) supportsIndexNullsDistinct() bool {
-	return c.version >= 15_00_00
+	return c.supports(featureIndexNullsDistinct)
 }
 
 type parser struct{}
@@ -341,6 +536,16 @@ func (parser) ChangeSchema(u *url.URL, s string) *url.URL {
 	return &nu
 }
 
+// ChangeReadOnly implements the sqlclient.ReadOnlyChanger interface.
+func (parser) ChangeReadOnly(u *url.URL) *url.URL {
+	nu := *u
+	q := nu.Query()
+	// Unknown parameters are passed to the server as run-time parameters.
+	q.Set("default_transaction_read_only", "on")
+	nu.RawQuery = q.Encode()
+	return &nu
+}
+
 // Standard column types (and their aliases) as defined in
 // PostgreSQL codebase/website.
 const (
@@ -466,6 +671,13 @@ const (
 	defaultPagePerRange = 128
 )
 
+// List of foreign-key MATCH types.
+const (
+	MatchSimple  = "SIMPLE"
+	MatchFull    = "FULL"
+	MatchPartial = "PARTIAL"
+)
+
 // List of "GENERATED" types.
 const (
 	GeneratedTypeAlways    = "ALWAYS"
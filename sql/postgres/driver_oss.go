@@ -21,8 +21,12 @@ var (
 		View:  viewSpec,
 	}
 	scanFuncs = &specutil.ScanFuncs{
-		Table: convertTable,
-		View:  convertView,
+		Table:      convertTable,
+		View:       convertView,
+		ForeignKey: convertFK,
+		Ident:      identRules,
+		UniqueRefs: true,
+		Mixins:     mixins,
 	}
 )
 
@@ -50,8 +54,8 @@ func (*state) renameView(*schema.RenameView) {
 	// unimplemented.
 }
 
-func (d *diff) ViewAttrChanged(_, _ *schema.View) bool {
-	return false // unimplemented.
+func (d *diff) ViewAttrChanged(from, to *schema.View) bool {
+	return continuousAggregateChanged(from, to)
 }
 
 func (s *state) addFunc(*schema.AddFunc) error {
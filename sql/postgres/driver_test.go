@@ -141,6 +141,54 @@ func TestDriver_CheckClean(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestDriver_SwapSchemas(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectExec(sqltest.Escape(`DO $$ BEGIN ALTER SCHEMA "blue" RENAME TO "atlas_swap_blue"; ALTER SCHEMA "green" RENAME TO "blue"; ALTER SCHEMA "atlas_swap_blue" RENAME TO "green"; END $$`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	drv := &Driver{conn: &conn{ExecQuerier: db}}
+	require.NoError(t, drv.SwapSchemas(context.Background(), "blue", "green"))
+	require.NoError(t, m.ExpectationsWereMet())
+	// Drivers implement the swapper interface.
+	require.Implements(t, (*migrate.SchemaSwapper)(nil), drv)
+}
+
+func TestDriver_CloneSequences(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(sequencesQuery)).
+		WithArgs("blue").
+		WillReturnRows(sqlmock.NewRows([]string{"relname", "format_type", "seqstart", "seqmin", "seqmax", "seqincrement", "seqcycle"}).
+			AddRow("order_numbers", "bigint", 1000, 1, 9223372036854775807, 1, false))
+	m.ExpectExec(sqltest.Escape(`CREATE SEQUENCE "green"."order_numbers" AS bigint INCREMENT BY 1 MINVALUE 1 MAXVALUE 9223372036854775807 START WITH 1000 NO CYCLE`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	var (
+		drv    = &Driver{conn: &conn{ExecQuerier: db}}
+		green  = schema.New("green")
+		orders = schema.NewTable("orders").AddColumns(
+			// Qualified by the source schema.
+			schema.NewIntColumn("num", "bigint").SetDefault(&schema.RawExpr{X: "nextval('blue.order_numbers'::regclass)"}),
+			// Resolved by the search_path of schema-bound connections.
+			schema.NewIntColumn("ref", "bigint").SetDefault(&schema.RawExpr{X: "nextval('order_numbers'::regclass)"}),
+			// Sequences of other schemas are kept as is.
+			schema.NewIntColumn("ext", "bigint").SetDefault(&schema.RawExpr{X: "nextval('shared.order_numbers'::regclass)"}),
+		)
+	)
+	green.AddTables(orders)
+	sync, err := drv.CloneSequences(context.Background(), "blue", green)
+	require.NoError(t, err)
+	require.Equal(t, `nextval('"green"."order_numbers"'::regclass)`, orders.Columns[0].Default.(*schema.RawExpr).X)
+	require.Equal(t, `nextval('"green"."order_numbers"'::regclass)`, orders.Columns[1].Default.(*schema.RawExpr).X)
+	require.Equal(t, `nextval('shared.order_numbers'::regclass)`, orders.Columns[2].Default.(*schema.RawExpr).X)
+
+	m.ExpectExec(sqltest.Escape(`SELECT setval('"green"."order_numbers"', last_value, is_called) FROM "blue"."order_numbers"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, sync(context.Background()))
+	require.NoError(t, m.ExpectationsWereMet())
+	// Drivers implement the cloner interface.
+	require.Implements(t, (*migrate.SequenceCloner)(nil), drv)
+}
+
 func TestDriver_Version(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -151,6 +199,39 @@ func TestDriver_Version(t *testing.T) {
 	type vr interface{ Version() string }
 	require.Implements(t, (*vr)(nil), drv)
 	require.Equal(t, "130000", drv.(vr).Version())
+	require.Implements(t, (*migrate.IdentQuoter)(nil), drv)
+	require.Equal(t, `"audit log"`, drv.(migrate.IdentQuoter).QuoteIdent("audit log"))
+}
+
+func TestDriver_Profile(t *testing.T) {
+	for ext, p := range map[string]Profile{
+		"":                               ProfileDefault,
+		"btree_gin,pg_stat_statements":   ProfileRDS,
+		"apg_plan_mgmt,btree_gin,hstore": ProfileAurora,
+	} {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		rows := sqlmock.NewRows([]string{"name", "setting"}).AddRow("server_version_num", "150000")
+		if ext != "" {
+			rows.AddRow("rds.extensions", ext)
+		}
+		m.ExpectQuery(sqltest.Escape(paramsQuery)).WillReturnRows(rows)
+		drv, err := Open(db)
+		require.NoError(t, err)
+		require.Equal(t, p, drv.(*Driver).Profile())
+		require.Equal(t, p != ProfileDefault, p.Managed())
+
+		// Schemas owned by the service are excluded on managed services.
+		q := schemasQuery
+		if p.Managed() {
+			q = schemasQueryManaged
+		}
+		m.ExpectQuery(sqltest.Escape(q)).
+			WillReturnRows(sqlmock.NewRows([]string{"schema_name", "comment", "owner"}))
+		_, err = drv.InspectRealm(context.Background(), &schema.InspectRealmOption{})
+		require.NoError(t, err)
+		require.NoError(t, m.ExpectationsWereMet())
+	}
 }
 
 type mockInspector struct {
@@ -169,3 +250,31 @@ func (m *mockInspector) InspectSchema(context.Context, string, *schema.InspectOp
 func (m *mockInspector) InspectRealm(context.Context, *schema.InspectRealmOption) (*schema.Realm, error) {
 	return m.realm, nil
 }
+
+func TestDriver_ViewColumnType(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("a").OfType("INT4", int64(0)),
+		sqlmock.NewColumn("b").OfType("VARCHAR", "").WithLength(255),
+		sqlmock.NewColumn("c").OfType("NUMERIC", "").WithPrecisionAndScale(10, 2),
+		sqlmock.NewColumn("d").OfType("_TEXT", ""),
+		sqlmock.NewColumn("e").OfType("TIMESTAMPTZ", ""),
+	))
+	rows, err := db.Query("SELECT")
+	require.NoError(t, err)
+	defer rows.Close()
+	cts, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	var types []schema.Type
+	for _, ct := range cts {
+		typ, err := viewColumnType(ct)
+		require.NoError(t, err)
+		types = append(types, typ)
+	}
+	require.Equal(t, &schema.IntegerType{T: TypeInt4}, types[0])
+	require.Equal(t, &schema.StringType{T: TypeVarChar, Size: 255}, types[1])
+	require.Equal(t, &schema.DecimalType{T: TypeNumeric, Precision: 10, Scale: 2}, types[2])
+	require.Equal(t, &ArrayType{Type: &schema.StringType{T: TypeText}, T: "text[]"}, types[3])
+	require.Equal(t, TypeTimestampTZ, types[4].(*schema.TimeType).T)
+}
@@ -0,0 +1,33 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"strings"
+
+	"ariga.io/atlas/sql/internal/specutil"
+)
+
+// identRules holds the PostgreSQL identifier rules. Identifiers longer than
+// NAMEDATALEN-1 (63) bytes are truncated by the database, and the reserved
+// words below cannot be used as unquoted identifiers.
+var identRules = specutil.NewIdentRules("PostgreSQL", 63, `
+ALL ANALYSE ANALYZE AND ANY ARRAY AS ASC ASYMMETRIC AUTHORIZATION BINARY BOTH CASE CAST
+CHECK COLLATE COLLATION COLUMN CONCURRENTLY CONSTRAINT CREATE CROSS CURRENT_CATALOG
+CURRENT_DATE CURRENT_ROLE CURRENT_SCHEMA CURRENT_TIME CURRENT_TIMESTAMP CURRENT_USER
+DEFAULT DEFERRABLE DESC DISTINCT DO ELSE END EXCEPT FALSE FETCH FOR FOREIGN FREEZE FROM
+FULL GRANT GROUP HAVING ILIKE IN INITIALLY INNER INTERSECT INTO IS ISNULL JOIN LATERAL
+LEADING LEFT LIKE LIMIT LOCALTIME LOCALTIMESTAMP NATURAL NOT NOTNULL NULL OFFSET ON ONLY
+OR ORDER OUTER OVERLAPS PLACING PRIMARY REFERENCES RETURNING RIGHT SELECT SESSION_USER
+SIMILAR SOME SYMMETRIC SYSTEM_USER TABLE TABLESAMPLE THEN TO TRAILING TRUE UNION UNIQUE
+USER USING VARIADIC VERBOSE WHEN WHERE WINDOW WITH
+`)
+
+// mustQuote reports if the identifier must be quoted. Unquoted identifiers are
+// folded to lower case by PostgreSQL, and therefore, identifiers that contain
+// upper case letters are quoted to preserve their case.
+func mustQuote(s string) bool {
+	return strings.ToLower(s) != s || identRules.Reserved(s)
+}
@@ -42,11 +42,24 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 				return nil, err
 			}
 			sqlx.LinkSchemaTables(schemas)
+			if opts.Stats {
+				if err := i.inspectStats(ctx, r); err != nil {
+					return nil, err
+				}
+			}
+			if opts.Triggers {
+				if err := i.inspectTriggers(ctx, r); err != nil {
+					return nil, err
+				}
+			}
 		}
 		if mode.Is(schema.InspectViews) {
 			if err := i.inspectViews(ctx, r, nil); err != nil {
 				return nil, err
 			}
+			if err := i.inspectContinuousAggregates(ctx, r); err != nil {
+				return nil, err
+			}
 		}
 		if mode.Is(schema.InspectFuncs) {
 			if err := i.inspectFuncs(ctx, r, nil); err != nil {
@@ -56,8 +69,19 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 		if err := i.inspectEnums(ctx, r); err != nil {
 			return nil, err
 		}
+		if mode.Is(schema.InspectObjects) {
+			if err := i.inspectTextSearch(ctx, r); err != nil {
+				return nil, err
+			}
+		}
 	}
-	return sqlx.ExcludeRealm(r, opts.Exclude)
+	if !opts.Owners {
+		dropOwners(r)
+	}
+	if r, err = sqlx.ExcludeRealm(r, opts.Exclude); err != nil {
+		return nil, err
+	}
+	return r, schema.AnonymizeRealm(r, opts.Anonymize)
 }
 
 // InspectSchema returns schema descriptions of the tables in the given schema.
@@ -82,11 +106,24 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 			return nil, err
 		}
 		sqlx.LinkSchemaTables(schemas)
+		if opts.Stats {
+			if err := i.inspectStats(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Triggers {
+			if err := i.inspectTriggers(ctx, r); err != nil {
+				return nil, err
+			}
+		}
 	}
 	if sqlx.ModeInspectSchema(opts).Is(schema.InspectViews) {
 		if err := i.inspectViews(ctx, r, opts); err != nil {
 			return nil, err
 		}
+		if err := i.inspectContinuousAggregates(ctx, r); err != nil {
+			return nil, err
+		}
 	}
 	if sqlx.ModeInspectSchema(opts).Is(schema.InspectFuncs) {
 		if err := i.inspectFuncs(ctx, r, opts); err != nil {
@@ -96,13 +133,28 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 	if err := i.inspectEnums(ctx, r); err != nil {
 		return nil, err
 	}
-	return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
+	if sqlx.ModeInspectSchema(opts).Is(schema.InspectObjects) {
+		if err := i.inspectTextSearch(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if !opts.Owners {
+		dropOwners(r)
+	}
+	if s, err = sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude); err != nil {
+		return nil, err
+	}
+	return s, schema.AnonymizeSchema(s, opts.Anonymize)
 }
 
 func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
 	if err := i.tables(ctx, r, opts); err != nil {
 		return err
 	}
+	timescale, err := i.hasTimescale(ctx)
+	if err != nil {
+		return err
+	}
 	for _, s := range r.Schemas {
 		if len(s.Tables) == 0 {
 			continue
@@ -122,6 +174,21 @@ func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *sche
 		if err := i.checks(ctx, s); err != nil {
 			return err
 		}
+		if !i.crdb {
+			if err := i.securityLabels(ctx, s); err != nil {
+				return err
+			}
+		}
+		if timescale {
+			if err := i.hypertables(ctx, s); err != nil {
+				return err
+			}
+		}
+		if i.profile == ProfileYugabyte {
+			if err := i.yugabyteTables(ctx, s); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -147,8 +214,8 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var tSchema, name, comment, partattrs, partstart, partexprs sql.NullString
-		if err := rows.Scan(&tSchema, &name, &comment, &partattrs, &partstart, &partexprs); err != nil {
+		var tSchema, name, comment, partattrs, partstart, partexprs, owner sql.NullString
+		if err := rows.Scan(&tSchema, &name, &comment, &partattrs, &partstart, &partexprs, &owner); err != nil {
 			return fmt.Errorf("scan table information: %w", err)
 		}
 		if !sqlx.ValidString(tSchema) || !sqlx.ValidString(name) {
@@ -170,6 +237,9 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 				exprs: partexprs.String,
 			})
 		}
+		if sqlx.ValidString(owner) {
+			t.AddAttrs(&Owner{Name: owner.String})
+		}
 	}
 	return rows.Close()
 }
@@ -436,11 +506,14 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows, scope queryScope)
 				idx.AddAttrs(&IndexPredicate{P: pred.String})
 			}
 			if sqlx.ValidString(options) {
-				p, err := newIndexStorage(options.String)
+				p, raw, err := newIndexStorage(options.String)
 				if err != nil {
 					return err
 				}
 				idx.AddAttrs(p)
+				if len(raw.Params) > 0 {
+					idx.AddAttrs(raw)
+				}
 			}
 			if nullsnotdistinct {
 				idx.AddAttrs(&IndexNullsDistinct{V: false})
@@ -565,7 +638,14 @@ func (i *inspect) fks(ctx context.Context, s *schema.Schema) error {
 		return fmt.Errorf("postgres: querying schema %q foreign keys: %w", s.Name, err)
 	}
 	defer rows.Close()
-	if err := sqlx.TypedSchemaFKs[*ReferenceOption](s, rows); err != nil {
+	if err := sqlx.TypedSchemaFKsAttr[*ReferenceOption](s, rows, func(fk *schema.ForeignKey, m string) {
+		switch m {
+		case "f":
+			fk.Attrs = append(fk.Attrs, &ForeignKeyMatch{T: MatchFull})
+		case "p":
+			fk.Attrs = append(fk.Attrs, &ForeignKeyMatch{T: MatchPartial})
+		}
+	}); err != nil {
 		return fmt.Errorf("postgres: %w", err)
 	}
 	return rows.Err()
@@ -623,6 +703,9 @@ func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption)
 		args  []any
 		query = schemasQuery
 	)
+	if i.profile.Managed() {
+		query = schemasQueryManaged
+	}
 	if opts != nil {
 		switch n := len(opts.Schemas); {
 		case n == 1 && opts.Schemas[0] == "":
@@ -645,16 +728,19 @@ func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption)
 	var schemas []*schema.Schema
 	for rows.Next() {
 		var (
-			name    string
-			comment sql.NullString
+			name           string
+			comment, owner sql.NullString
 		)
-		if err := rows.Scan(&name, &comment); err != nil {
+		if err := rows.Scan(&name, &comment, &owner); err != nil {
 			return nil, err
 		}
 		s := schema.New(name)
 		if comment.Valid {
 			s.SetComment(comment.String)
 		}
+		if sqlx.ValidString(owner) {
+			s.AddAttrs(&Owner{Name: owner.String})
+		}
 		schemas = append(schemas, s)
 	}
 	if err := rows.Close(); err != nil {
@@ -738,7 +824,7 @@ func canConvert(t schema.Type, x string) (string, bool) {
 		if sqlx.IsLiteralNumber(x) {
 			return x, true
 		}
-	case *ArrayType, *schema.BinaryType, *schema.JSONType, *NetworkType, *schema.SpatialType, *schema.StringType, *schema.TimeType, *schema.UUIDType, *XMLType:
+	case *ArrayType, *schema.BinaryType, *schema.JSONType, *NetworkType, *schema.SpatialType, *GeometryType, *schema.StringType, *schema.TimeType, *schema.UUIDType, *XMLType:
 		return q, true
 	}
 	return "", false
@@ -856,6 +942,13 @@ type (
 		T string // c, f, p, u, t, x.
 	}
 
+	// ForeignKeyMatch describes the MATCH type of a foreign-key constraint.
+	// https://www.postgresql.org/docs/current/sql-createtable.html#SQL-CREATETABLE-PARMS-REFERENCES
+	ForeignKeyMatch struct {
+		schema.Attr
+		T string // SIMPLE, FULL, PARTIAL.
+	}
+
 	// Sequence defines (the supported) sequence options.
 	// https://postgresql.org/docs/current/sql-createsequence.html
 	Sequence struct {
@@ -907,6 +1000,14 @@ type (
 		PagesPerRange int64
 	}
 
+	// RawStorageParams describes storage parameters of an index that are not
+	// modeled by Atlas (e.g. fillfactor or fastupdate). The parameters are kept
+	// verbatim in the form of "name=value", and added as-is to the WITH clause.
+	RawStorageParams struct {
+		schema.Attr
+		Params []string
+	}
+
 	// IndexInclude describes the INCLUDE clause allows specifying
 	// a list of column which added to the index as non-key columns.
 	// https://www.postgresql.org/docs/current/sql-createindex.html
@@ -1014,6 +1115,9 @@ func (o *ReferenceOption) Scan(v any) error {
 // IsUnique reports if the type is unique constraint.
 func (c Constraint) IsUnique() bool { return strings.ToLower(c.T) == "u" }
 
+// Partial implements the sqlx.PartialAttr interface.
+func (p *IndexPredicate) Partial() bool { return p.P != "" }
+
 // IntegerType returns the underlying integer type this serial type represents.
 func (s *SerialType) IntegerType() *schema.IntegerType {
 	t := &schema.IntegerType{T: TypeInteger}
@@ -1079,6 +1183,9 @@ func (o *IndexOpClass) DefaultFor(idx *schema.Index, part *schema.IndexPart) (bo
 		err error
 	)
 	switch typ := part.C.Type.Type.(type) {
+	case *GeometryType:
+		// PostGIS operator classes are not part of the builtin ones.
+		return defaultGeometryOps[strings.ToLower(typ.T)][it.T] == o.Name, nil
 	case *schema.EnumType:
 		t = "anyenum"
 	case *ArrayType:
@@ -1153,30 +1260,33 @@ func (o *IndexOpClass) parseParams(kv string) error {
 	return nil
 }
 
-// newIndexStorage parses and returns the index storage parameters.
-func newIndexStorage(opts string) (*IndexStorageParams, error) {
-	params := &IndexStorageParams{}
+// newIndexStorage parses and returns the index storage parameters. Parameters
+// that are not modeled by IndexStorageParams are returned as raw parameters.
+func newIndexStorage(opts string) (*IndexStorageParams, *RawStorageParams, error) {
+	params, raw := &IndexStorageParams{}, &RawStorageParams{}
 	for _, p := range strings.Split(strings.Trim(opts, "{}"), ",") {
 		kv := strings.Split(p, "=")
 		if len(kv) != 2 {
-			return nil, fmt.Errorf("invalid index storage parameter: %s", p)
+			return nil, nil, fmt.Errorf("invalid index storage parameter: %s", p)
 		}
 		switch kv[0] {
 		case "autosummarize":
 			b, err := strconv.ParseBool(kv[1])
 			if err != nil {
-				return nil, fmt.Errorf("failed parsing autosummarize %q: %w", kv[1], err)
+				return nil, nil, fmt.Errorf("failed parsing autosummarize %q: %w", kv[1], err)
 			}
 			params.AutoSummarize = b
 		case "pages_per_range":
 			i, err := strconv.ParseInt(kv[1], 10, 64)
 			if err != nil {
-				return nil, fmt.Errorf("failed parsing pages_per_range %q: %w", kv[1], err)
+				return nil, nil, fmt.Errorf("failed parsing pages_per_range %q: %w", kv[1], err)
 			}
 			params.PagesPerRange = i
+		default:
+			raw.Params = append(raw.Params, p)
 		}
 	}
-	return params, nil
+	return params, raw, nil
 }
 
 // reEnumType extracts the enum type and an option schema qualifier.
@@ -1204,19 +1314,56 @@ func newEnumType(t string, id int64) *enumType {
 }
 
 const (
+	// Query to list the sequences of a schema that are not owned by serial or identity columns.
+	sequencesQuery = `
+SELECT
+	c.relname,
+	format_type(s.seqtypid, NULL),
+	s.seqstart,
+	s.seqmin,
+	s.seqmax,
+	s.seqincrement,
+	s.seqcycle
+FROM
+	pg_catalog.pg_sequence s
+	JOIN pg_catalog.pg_class c ON c.oid = s.seqrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE
+	n.nspname = $1
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = c.oid AND d.deptype IN ('a', 'i'))
+ORDER BY
+	c.relname`
+
 	// Query to list runtime parameters.
-	paramsQuery = `SELECT setting FROM pg_settings WHERE name IN ('server_version_num', 'crdb_version') ORDER BY name DESC`
+	paramsQuery = `SELECT name, setting FROM pg_settings WHERE name IN ('server_version', 'server_version_num', 'crdb_version', 'rds.extensions', 'timescaledb.license')`
 
 	// Query to list database schemas.
 	schemasQuery = `
 SELECT
 	nspname AS schema_name,
-	pg_catalog.obj_description(oid) AS comment
+	pg_catalog.obj_description(oid) AS comment,
+	pg_catalog.pg_get_userbyid(nspowner) AS owner
+FROM
+    pg_catalog.pg_namespace
+WHERE
+    nspname NOT IN ('information_schema', 'pg_catalog', 'pg_toast', 'crdb_internal', 'pg_extension')
+    AND nspname NOT LIKE 'pg_%temp_%'
+ORDER BY
+    nspname`
+
+	// Query to list database schemas on managed services, excluding
+	// the ones owned by the internal administration role.
+	schemasQueryManaged = `
+SELECT
+	nspname AS schema_name,
+	pg_catalog.obj_description(oid) AS comment,
+	pg_catalog.pg_get_userbyid(nspowner) AS owner
 FROM
     pg_catalog.pg_namespace
 WHERE
     nspname NOT IN ('information_schema', 'pg_catalog', 'pg_toast', 'crdb_internal', 'pg_extension')
     AND nspname NOT LIKE 'pg_%temp_%'
+    AND pg_catalog.pg_get_userbyid(nspowner) <> 'rdsadmin'
 ORDER BY
     nspname`
 
@@ -1224,7 +1371,8 @@ ORDER BY
 	schemasQueryArgs = `
 SELECT
 	nspname AS schema_name,
-	pg_catalog.obj_description(oid) AS comment
+	pg_catalog.obj_description(oid) AS comment,
+	pg_catalog.pg_get_userbyid(nspowner) AS owner
 FROM
     pg_catalog.pg_namespace
 WHERE
@@ -1240,7 +1388,8 @@ SELECT
 	pg_catalog.obj_description(t3.oid, 'pg_class') AS comment,
 	t4.partattrs AS partition_attrs,
 	t4.partstrat AS partition_strategy,
-	pg_get_expr(t4.partexprs, t4.partrelid) AS partition_exprs
+	pg_get_expr(t4.partexprs, t4.partrelid) AS partition_exprs,
+	pg_catalog.pg_get_userbyid(t3.relowner) AS owner
 FROM
 	INFORMATION_SCHEMA.TABLES AS t1
 	JOIN pg_catalog.pg_namespace AS t2 ON t2.nspname = t1.table_schema
@@ -1262,7 +1411,8 @@ SELECT
 	pg_catalog.obj_description(t3.oid, 'pg_class') AS comment,
 	t4.partattrs AS partition_attrs,
 	t4.partstrat AS partition_strategy,
-	pg_get_expr(t4.partexprs, t4.partrelid) AS partition_exprs
+	pg_get_expr(t4.partexprs, t4.partrelid) AS partition_exprs,
+	pg_catalog.pg_get_userbyid(t3.relowner) AS owner
 FROM
 	INFORMATION_SCHEMA.TABLES AS t1
 	JOIN pg_catalog.pg_namespace AS t2 ON t2.nspname = t1.table_schema
@@ -1343,7 +1493,8 @@ SELECT
     a2.attname AS referenced_column_name,
     fk.referenced_schema_name,
     fk.confupdtype,
-    fk.confdeltype
+    fk.confdeltype,
+    fk.confmatchtype
 	FROM 
 	    (
 	    	SELECT
@@ -1358,7 +1509,8 @@ SELECT
 	      		unnest(con.conkey) AS conkey,
 	      		unnest(con.confkey) AS confkey,
 	      		con.confupdtype,
-	      		con.confdeltype
+	      		con.confdeltype,
+	      		con.confmatchtype
 	    	FROM pg_constraint con
 	    	JOIN pg_class t1 ON t1.oid = con.conrelid
 	    	JOIN pg_class t2 ON t2.oid = con.confrelid
@@ -25,6 +25,7 @@ var (
 	queryTables      = sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))
 	queryChecks      = sqltest.Escape(fmt.Sprintf(checksQuery, "$2"))
 	queryColumns     = sqltest.Escape(fmt.Sprintf(columnsQuery, "$2"))
+	queryLabels      = sqltest.Escape(fmt.Sprintf(securityLabelsQuery, "$2"))
 	queryCRDBColumns = sqltest.Escape(fmt.Sprintf(crdbColumnsQuery, "$2"))
 	queryIndexes     = sqltest.Escape(fmt.Sprintf(indexesAbove15, "$2"))
 	queryCRDBIndexes = sqltest.Escape(fmt.Sprintf(crdbIndexesQuery, "$2"))
@@ -93,6 +94,7 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.noIndexes()
 				m.noFKs()
 				m.noChecks()
+				m.noLabels()
 				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(enumsQuery, "$1"))).
 					WithArgs("public").
 					WillReturnRows(sqltest.Rows(`
@@ -194,6 +196,7 @@ users           | tsx             | gist        | ts          | f        | f
 `))
 				m.noFKs()
 				m.noChecks()
+				m.noLabels()
 				m.noEnums()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
@@ -247,15 +250,16 @@ users      | uid         | integer             | int       | NO          |
 				m.ExpectQuery(queryFKs).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-constraint_name | table_name | column_name | table_schema | referenced_table_name | referenced_column_name | referenced_schema_name | confupdtype | condeltype
------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------
-multi_column    | users      | id          | public       | t1                    | gid                    | public                 | a            | c
-multi_column    | users      | id          | public       | t1                    | xid                    | public                 | a            | c
-multi_column    | users      | oid         | public       | t1                    | gid                    | public                 | a            | c
-multi_column    | users      | oid         | public       | t1                    | xid                    | public                 | a            | c
-self_reference  | users      | uid         | public       | users                 | id                     | public                 | a            | c
+constraint_name | table_name | column_name | table_schema | referenced_table_name | referenced_column_name | referenced_schema_name | confupdtype | condeltype | confmatchtype
+-----------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+------------+---------------
+multi_column    | users      | id          | public       | t1                    | gid                    | public                 | a            | c         | f
+multi_column    | users      | id          | public       | t1                    | xid                    | public                 | a            | c         | f
+multi_column    | users      | oid         | public       | t1                    | gid                    | public                 | a            | c         | f
+multi_column    | users      | oid         | public       | t1                    | xid                    | public                 | a            | c         | f
+self_reference  | users      | uid         | public       | users                 | id                     | public                 | a            | c         | s
 `))
 				m.noChecks()
+				m.noLabels()
 				m.noEnums()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
@@ -263,7 +267,7 @@ self_reference  | users      | uid         | public       | users
 				require.Equal("users", t.Name)
 				require.Equal("public", t.Schema.Name)
 				fks := []*schema.ForeignKey{
-					{Symbol: "multi_column", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: &schema.Table{Name: "t1", Schema: t.Schema}, RefColumns: []*schema.Column{{Name: "gid"}, {Name: "xid"}}},
+					{Symbol: "multi_column", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: &schema.Table{Name: "t1", Schema: t.Schema}, RefColumns: []*schema.Column{{Name: "gid"}, {Name: "xid"}}, Attrs: []schema.Attr{&ForeignKeyMatch{T: MatchFull}}},
 					{Symbol: "self_reference", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: t},
 				}
 				columns := []*schema.Column{
@@ -307,6 +311,7 @@ users        | users_check1       | (((c2 + c1) + c3) > 10) | c2          | {2,1
 users        | users_check1       | (((c2 + c1) + c3) > 10) | c1          | {2,1,3}        | f
 users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1,3}        | f
 `))
+				m.noLabels()
 				m.noEnums()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
@@ -340,9 +345,9 @@ users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1
 			mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
 				WithArgs("public").
 				WillReturnRows(sqltest.Rows(`
- schema_name | comment 
--------------+---------
- public      | nil
+ schema_name | comment | owner
+-------------+---------+-------
+ public      | nil | nil
 `))
 			tt.before(mk)
 			s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{
@@ -363,18 +368,18 @@ func TestDriver_InspectPartitionedTable(t *testing.T) {
 	require.NoError(t, err)
 	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= CURRENT_SCHEMA()"))).
 		WillReturnRows(sqltest.Rows(`
- schema_name | comment 
--------------+---------
- public      | nil
+ schema_name | comment | owner
+-------------+---------+-------
+ public      | nil | nil
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
 		WithArgs("public").
 		WillReturnRows(sqltest.Rows(`
- table_schema | table_name  | comment | partition_attrs | partition_strategy |                  partition_exprs                   
---------------+-------------+---------+-----------------+--------------------+----------------------------------------------------
- public       | logs1       |         |                 |                    | 
- public       | logs2       |         | 1               | r                  | 
- public       | logs3       |         | 2 0 0           | l                  | (a + b), (a + (b * 2))
+ table_schema | table_name  | comment | partition_attrs | partition_strategy |                  partition_exprs                   | owner
+--------------+-------------+---------+-----------------+--------------------+----------------------------------------------------+-------
+ public       | logs1       |         |                 |                    |                                                    |
+ public       | logs2       |         | 1               | r                  |                                                    |
+ public       | logs3       |         | 2 0 0           | l                  | (a + b), (a + (b * 2))                             |
 
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "$2, $3, $4"))).
@@ -391,9 +396,11 @@ logs3      | c5         | integer   | integer   | NO          |                |
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesAbove15, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression", "options", "indnullsnotdistinct"}))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "$2, $3, $4"))).
-		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule", "confmatchtype"}))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(securityLabelsQuery, "$2, $3, $4"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "provider", "label"}))
 	mk.noEnums()
 	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{
 		Mode: schema.InspectSchemas | schema.InspectTables,
@@ -431,19 +438,19 @@ func TestDriver_InspectCRDBSchema(t *testing.T) {
 	mk := mock{m}
 	mk.ExpectQuery(sqltest.Escape(paramsQuery)).
 		WillReturnRows(sqltest.Rows(`
-	setting
-------------
-130000
-cockroach
-				`))
+        name        |  setting
+--------------------+-----------
+ server_version_num | 130000
+ crdb_version       | cockroach
+`))
 	drv, err := Open(db)
 	require.NoError(t, err)
 	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
 		WithArgs("public").
 		WillReturnRows(sqltest.Rows(`
- schema_name | comment 
--------------+---------
- public      | nil
+ schema_name | comment | owner
+-------------+---------+-------
+ public      | nil | nil
 `))
 	mk.tableExists("public", "users", true)
 	mk.ExpectQuery(queryCRDBColumns).
@@ -459,15 +466,18 @@ users       | d           | bigint    | bigint    | NO          |
 	mk.ExpectQuery(queryCRDBIndexes).
 		WithArgs("public", "users").
 		WillReturnRows(sqltest.Rows(`
-table_name  | index_name | column_name | primary | unique | constraint_type |                                   create_stmt                                   | predicate | expression | comment 
-------------+------------+-------------+---------+--------+-----------------+---------------------------------------------------------------------------------+-----------+------------+---------
-users       | idx1       | a           | false   | false  |                 | CREATE INDEX idx1 ON defaultdb.public.serial USING btree (a ASC)                |           | a          |  
-users       | idx2       | b           | false   | true   | u               | CREATE UNIQUE INDEX idx2 ON defaultdb.public.serial USING btree (b ASC)         |           | b          |  
-users       | idx3       | c           | false   | false  |                 | CREATE INDEX idx3 ON defaultdb.public.serial USING btree (c DESC)               |           | c          | boring 
-users       | idx4       | d           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (d ASC) WHERE (d < 10) | d < 10    | d          |  
-users       | idx5       | a           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (a ASC, b ASC, c ASC)  |           | a          |  
-users       | idx5       | b           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (a ASC, b ASC, c ASC)  |           | b          |  
-users       | idx5       | c           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (a ASC, b ASC, c ASC)  |           | c          |  
+table_name  | index_name | column_name | primary | unique | constraint_type |                                   create_stmt                                   | predicate | expression | comment | included
+------------+------------+-------------+---------+--------+-----------------+---------------------------------------------------------------------------------+-----------+------------+---------+----------
+users       | idx1       | a           | false   | false  |                 | CREATE INDEX idx1 ON defaultdb.public.serial USING btree (a ASC)                |           | a          |         | false
+users       | idx2       | b           | false   | true   | u               | CREATE UNIQUE INDEX idx2 ON defaultdb.public.serial USING btree (b ASC)         |           | b          |         | false
+users       | idx3       | c           | false   | false  |                 | CREATE INDEX idx3 ON defaultdb.public.serial USING btree (c DESC)               |           | c          | boring  | false
+users       | idx4       | d           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (d ASC) WHERE (d < 10) | d < 10    | d          |         | false
+users       | idx5       | a           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (a ASC, b ASC, c ASC)  |           | a          |         | false
+users       | idx5       | b           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (a ASC, b ASC, c ASC)  |           | b          |         | false
+users       | idx5       | c           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (a ASC, b ASC, c ASC)  |           | c          |         | false
+users       | idx6       | a           | false   | false  |                 | CREATE INDEX idx6 ON defaultdb.public.serial USING btree (a ASC) STORING (b, c) |           | a          |         | false
+users       | idx6       | b           | false   | false  |                 | CREATE INDEX idx6 ON defaultdb.public.serial USING btree (a ASC) STORING (b, c) |           |            |         | true
+users       | idx6       | c           | false   | false  |                 | CREATE INDEX idx6 ON defaultdb.public.serial USING btree (a ASC) STORING (b, c) |           |            |         | true
 `))
 	mk.noFKs()
 	mk.noChecks()
@@ -490,8 +500,9 @@ users       | idx5       | c           | false   | false  |                 | CR
 		{Name: "idx3", Table: tbl, Attrs: []schema.Attr{&IndexType{T: "btree"}, &schema.Comment{Text: "boring"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[2], Desc: true}}},
 		{Name: "idx4", Table: tbl, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexPredicate{P: `d < 10`}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[3]}}},
 		{Name: "idx5", Table: tbl, Attrs: []schema.Attr{&IndexType{T: "btree"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[0]}, {SeqNo: 2, C: columns[1]}, {SeqNo: 3, C: columns[2]}}},
+		{Name: "idx6", Table: tbl, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexInclude{Columns: columns[1:3]}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[0]}}},
 	}
-	columns[0].Indexes = []*schema.Index{indexes[0], indexes[4]}
+	columns[0].Indexes = []*schema.Index{indexes[0], indexes[4], indexes[5]}
 	columns[1].Indexes = []*schema.Index{indexes[1], indexes[4]}
 	columns[2].Indexes = []*schema.Index{indexes[2], indexes[4]}
 	columns[3].Indexes = []*schema.Index{indexes[3]}
@@ -508,13 +519,13 @@ func TestDriver_InspectSchema(t *testing.T) {
 	require.NoError(t, err)
 	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= CURRENT_SCHEMA()"))).
 		WillReturnRows(sqltest.Rows(`
- schema_name | comment 
--------------+---------
- test        | boring
+ schema_name | comment | owner
+-------------+---------+-------
+ test        | boring | nil
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
 		WithArgs("test").
-		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs", "owner"}))
 	mk.noEnums()
 	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{
 		Mode: schema.InspectSchemas | schema.InspectTables,
@@ -540,14 +551,14 @@ func TestDriver_Realm(t *testing.T) {
 	require.NoError(t, err)
 	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
 		WillReturnRows(sqltest.Rows(`
- schema_name | comment 
--------------+---------
- test        | nil
- public      | nil
+ schema_name | comment | owner
+-------------+---------+-------
+ test        | nil | nil
+ public      | nil | nil
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1, $2"))).
 		WithArgs("test", "public").
-		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs", "owner"}))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(enumsQuery, "$1, $2"))).
 		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "enum_name", "comment", "enum_type", "enum_value"}))
 	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{
@@ -573,14 +584,14 @@ func TestDriver_Realm(t *testing.T) {
 	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "IN ($1, $2)"))).
 		WithArgs("test", "public").
 		WillReturnRows(sqltest.Rows(`
- schema_name | comment 
--------------+---------
- test        | nil
- public      | nil
+ schema_name | comment | owner
+-------------+---------+-------
+ test        | nil | nil
+ public      | nil | nil
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1, $2"))).
 		WithArgs("test", "public").
-		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs", "owner"}))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(enumsQuery, "$1, $2"))).
 		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "enum_name", "comment", "enum_type", "enum_value"}))
 	realm, err = drv.InspectRealm(context.Background(), &schema.InspectRealmOption{
@@ -607,13 +618,13 @@ func TestDriver_Realm(t *testing.T) {
 	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
 		WithArgs("test").
 		WillReturnRows(sqltest.Rows(`
- schema_name | comment 
--------------+---------
- test        | nil
+ schema_name | comment | owner
+-------------+---------+-------
+ test        | nil | nil
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
 		WithArgs("test").
-		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs", "owner"}))
 	mk.noEnums()
 	realm, err = drv.InspectRealm(context.Background(), &schema.InspectRealmOption{
 		Schemas: []string{"test"},
@@ -640,10 +651,10 @@ func TestInspectMode_InspectRealm(t *testing.T) {
 	mk.version("130000")
 	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
 		WillReturnRows(sqltest.Rows(`
- schema_name | comment 
--------------+---------
- test        | nil
- public      | nil
+ schema_name | comment | owner
+-------------+---------+-------
+ test        | nil | nil
+ public      | nil | nil
 `))
 	drv, err := Open(db)
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(enumsQuery, "$1, $2"))).
@@ -687,16 +698,16 @@ type mock struct {
 func (m mock) version(version string) {
 	m.ExpectQuery(sqltest.Escape(paramsQuery)).
 		WillReturnRows(sqltest.Rows(`
-  setting
-------------
- ` + version + `
+        name        | setting
+--------------------+---------
+ server_version_num | ` + version + `
 `))
 }
 
 func (m mock) tableExists(schema, table string, exists bool) {
-	rows := sqlmock.NewRows([]string{"table_schema", "table_name", "table_comment", "partition_attrs", "partition_strategy", "partition_exprs"})
+	rows := sqlmock.NewRows([]string{"table_schema", "table_name", "table_comment", "partition_attrs", "partition_strategy", "partition_exprs", "owner"})
 	if exists {
-		rows.AddRow(schema, table, nil, nil, nil, nil)
+		rows.AddRow(schema, table, nil, nil, nil, nil, nil)
 	}
 	m.ExpectQuery(queryTables).
 		WithArgs(schema).
@@ -710,7 +721,7 @@ func (m mock) noIndexes() {
 
 func (m mock) noFKs() {
 	m.ExpectQuery(queryFKs).
-		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule", "confmatchtype"}))
 }
 
 func (m mock) noChecks() {
@@ -718,6 +729,11 @@ func (m mock) noChecks() {
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
 }
 
+func (m mock) noLabels() {
+	m.ExpectQuery(queryLabels).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "provider", "label"}))
+}
+
 func (m mock) noEnums() {
 	m.ExpectQuery(queryEnums).
 		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "enum_name", "comment", "enum_type", "enum_value"}))
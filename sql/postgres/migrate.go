@@ -40,6 +40,11 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 	if err := verifyChanges(ctx, changes); err != nil {
 		return nil, err
 	}
+	if p.profile == ProfileYugabyte {
+		if err := verifyYugabyte(changes); err != nil {
+			return nil, err
+		}
+	}
 	if err := s.plan(changes); err != nil {
 		return nil, err
 	}
@@ -69,11 +74,20 @@ type state struct {
 // Exec executes the changes on the database. An error is returned
 // if one of the operations fail, or a change is not supported.
 func (s *state) plan(changes []schema.Change) error {
+	changes, data := migrate.SplitDataChanges(changes)
 	if s.SchemaQualifier != nil {
 		if err := sqlx.CheckChangesScope(s.PlanOptions, changes); err != nil {
 			return err
 		}
 	}
+	if s.Quote == migrate.QuoteNever {
+		if err := sqlx.CheckUnquoted(changes, mustQuote); err != nil {
+			return err
+		}
+	}
+	if err := s.verifyFeatures(changes); err != nil {
+		return err
+	}
 	planned, err := s.topLevel(changes)
 	if err != nil {
 		return err
@@ -82,8 +96,9 @@ func (s *state) plan(changes []schema.Change) error {
 		return err
 	}
 	var (
-		views []schema.Change
-		drop  struct{ T, O, F []schema.Change }
+		views    []schema.Change
+		triggers []schema.Change
+		drop     struct{ T, O, F []schema.Change }
 	)
 	for _, c := range planned {
 		switch c := c.(type) {
@@ -107,6 +122,8 @@ func (s *state) plan(changes []schema.Change) error {
 			err = s.renameProc(c)
 		case *schema.AddView, *schema.DropView, *schema.ModifyView, *schema.RenameView:
 			views = append(views, c)
+		case *schema.AddTrigger, *schema.DropTrigger, *schema.ModifyTrigger:
+			triggers = append(triggers, c)
 		case *schema.DropTable:
 			drop.T = append(drop.T, c)
 		case *schema.DropObject:
@@ -124,6 +141,12 @@ func (s *state) plan(changes []schema.Change) error {
 		return err
 	}
 	for _, c := range views {
+		// Continuous aggregates are planned separately from regular views.
+		if ok, err := s.continuousAggregate(c); err != nil {
+			return err
+		} else if ok {
+			continue
+		}
 		switch c := c.(type) {
 		case *schema.AddView:
 			err = s.addView(c)
@@ -138,12 +161,24 @@ func (s *state) plan(changes []schema.Change) error {
 			return err
 		}
 	}
+	if err := s.triggerChanges(triggers); err != nil {
+		return err
+	}
 	for _, c := range append(drop.T, append(drop.O, drop.F...)...) {
 		var err error
 		switch c := c.(type) {
 		case *schema.DropTable:
 			err = s.dropTable(c)
 		case *schema.DropObject:
+			if isTextSearch(c.O) {
+				change, err := s.dropTextSearch(c.O)
+				if err != nil {
+					return err
+				}
+				change.Source = c
+				s.append(change)
+				continue
+			}
 			e, ok := c.O.(*schema.EnumType)
 			if !ok {
 				return fmt.Errorf("unsupported drop object %T", c.O)
@@ -164,7 +199,7 @@ func (s *state) plan(changes []schema.Change) error {
 			return err
 		}
 	}
-	return nil
+	return s.planData(data)
 }
 
 // topLevel executes first the changes for creating or dropping schemas and
@@ -191,8 +226,15 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 			if cm := (schema.Comment{}); sqlx.Has(c.S.Attrs, &cm) {
 				s.append(s.schemaComment(c.S, cm.Text, ""))
 			}
+			if o := (Owner{}); sqlx.Has(c.S.Attrs, &o) {
+				s.append(s.schemaOwner(c.S, nil, &o))
+			}
 		case *schema.ModifySchema:
 			for i := range c.Changes {
+				if from, to, ok := ownerChange(c.Changes[i]); ok {
+					s.append(s.schemaOwner(c.S, from, to))
+					continue
+				}
 				switch change := c.Changes[i].(type) {
 				// Add schema attributes to an existing schema only if
 				// it is different from the default server configuration.
@@ -225,6 +267,17 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 				Comment: fmt.Sprintf("Drop schema named %q", c.S.Name),
 			})
 		case *schema.AddObject:
+			if isTextSearch(c.O) {
+				changes, err := s.addTextSearch(c.O)
+				if err != nil {
+					return nil, err
+				}
+				for _, change := range changes {
+					change.Source = c
+				}
+				s.append(changes...)
+				continue
+			}
 			e, ok := c.O.(*schema.EnumType)
 			if !ok {
 				return nil, fmt.Errorf("unsupported object %T", c.O)
@@ -237,6 +290,17 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 				Comment: fmt.Sprintf("create enum type %q", e.T),
 			})
 		case *schema.ModifyObject:
+			if isTextSearch(c.From) {
+				changes, err := s.modifyTextSearch(c)
+				if err != nil {
+					return nil, err
+				}
+				for _, change := range changes {
+					change.Source = c
+				}
+				s.append(changes...)
+				continue
+			}
 			if err := s.alterEnum(c); err != nil {
 				return nil, err
 			}
@@ -299,6 +363,7 @@ func (s *state) addTable(add *schema.AddTable) error {
 		}
 		b.P(s)
 	}
+	splitInto(b, add.T.Attrs)
 	if len(errs) > 0 {
 		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
 	}
@@ -308,6 +373,9 @@ func (s *state) addTable(add *schema.AddTable) error {
 		Comment: fmt.Sprintf("create %q table", add.T.Name),
 		Reverse: s.Build("DROP TABLE").Table(add.T).String(),
 	})
+	if h := (Hypertable{}); sqlx.Has(add.T.Attrs, &h) {
+		s.append(s.hypertable(add.T, &h, false)...)
+	}
 	for _, idx := range add.T.Indexes {
 		// Indexes do not need to be created concurrently on new tables.
 		if err := s.addIndexes(add.T, &schema.AddIndex{I: idx}); err != nil {
@@ -315,6 +383,10 @@ func (s *state) addTable(add *schema.AddTable) error {
 		}
 	}
 	s.addComments(add.T)
+	s.addLabels(add.T)
+	if o := (Owner{}); sqlx.Has(add.T.Attrs, &o) {
+		s.append(s.tableOwner(add.T, nil, &o))
+	}
 	return nil
 }
 
@@ -362,6 +434,7 @@ func (s *state) dropTable(drop *schema.DropTable) error {
 func (s *state) modifyTable(modify *schema.ModifyTable) error {
 	var (
 		alter   []schema.Change
+		addFK   []schema.Change
 		addI    []*schema.AddIndex
 		dropI   []*schema.DropIndex
 		changes []*migrate.Change
@@ -369,6 +442,18 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 	for _, change := range skipAutoChanges(modify.Changes) {
 		switch change := change.(type) {
 		case *schema.AddAttr, *schema.ModifyAttr:
+			if isHypertableChange(change) {
+				hc, err := s.modifyHypertable(modify.T, change)
+				if err != nil {
+					return err
+				}
+				changes = append(changes, hc...)
+				continue
+			}
+			if from, to, ok := ownerChange(change); ok {
+				changes = append(changes, s.tableOwner(modify.T, from, to))
+				continue
+			}
 			from, to, err := commentChange(change)
 			if err != nil {
 				return err
@@ -432,9 +517,19 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 			if c := (schema.Comment{}); sqlx.Has(change.C.Attrs, &c) {
 				changes = append(changes, s.columnComment(modify.T, change.C, c.Text, ""))
 			}
+			changes = append(changes, s.columnLabels(modify.T, &schema.Column{}, change.C)...)
 			alter = append(alter, change)
 		case *schema.ModifyColumn:
 			k := change.Change
+			if k.Is(schema.ChangeAttr) && labelsChanged(change.From.Attrs, change.To.Attrs) {
+				changes = append(changes, s.columnLabels(modify.T, change.From, change.To)...)
+				// If the identity of the column was not changed, only its labels were.
+				if !identityChanged(change.From.Attrs, change.To.Attrs) {
+					if k &= ^schema.ChangeAttr; k.Is(schema.NoChange) {
+						continue
+					}
+				}
+			}
 			if change.Change.Is(schema.ChangeComment) {
 				from, to, err := commentChange(sqlx.CommentDiff(change.From.Attrs, change.To.Attrs))
 				if err != nil {
@@ -447,6 +542,12 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 				}
 			}
 			alter = append(alter, &schema.ModifyColumn{To: change.To, From: change.From, Change: k})
+		case *schema.AddForeignKey:
+			if change.F.RefTable == modify.T && keyAdded(modify.Changes, change.F) {
+				addFK = append(addFK, change)
+			} else {
+				alter = append(alter, change)
+			}
 		case *schema.RenameColumn:
 			// "RENAME COLUMN" cannot be combined with other alterations.
 			b := s.Build("ALTER TABLE").Table(modify.T).P("RENAME COLUMN")
@@ -472,10 +573,34 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 	if err := s.addIndexes(modify.T, addI...); err != nil {
 		return err
 	}
+	// Self-referencing foreign keys that reference unique
+	// indexes are added after their indexes were created.
+	if len(addFK) > 0 {
+		if err := s.alterTable(modify.T, addFK); err != nil {
+			return err
+		}
+	}
 	s.append(changes...)
 	return nil
 }
 
+// keyAdded reports if the unique key referenced by the foreign key is added (or rebuilt) by the changes.
+func keyAdded(changes []schema.Change, fk *schema.ForeignKey) bool {
+	for _, c := range changes {
+		var idx *schema.Index
+		switch c := c.(type) {
+		case *schema.AddIndex:
+			idx = c.I
+		case *schema.ModifyIndex:
+			idx = c.To
+		}
+		if idx != nil && idx.Unique && sqlx.KeyOf(idx, fk.RefColumns) {
+			return true
+		}
+	}
+	return false
+}
+
 // alterTable modifies the given table by executing on it a list of changes in one SQL statement.
 func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	var (
@@ -500,7 +625,9 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				if err := s.alterColumn(b, alter, t, change); err != nil {
 					return err
 				}
-				if change.Change.Is(schema.ChangeGenerated) {
+				// Type changes without a cast in the opposite
+				// direction cannot be reverted automatically.
+				if change.Change.Is(schema.ChangeGenerated) || change.Change.Is(schema.ChangeType) && !s.typeReversible(t, change) {
 					reversible = false
 				}
 				reverse = append(reverse, &schema.ModifyColumn{
@@ -515,9 +642,13 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				// Skip reversing this operation as it is the inverse of
 				// the operation above and should not be used besides this.
 				b.P("ADD CONSTRAINT").Ident(change.I.Name).P("UNIQUE")
+				if n := (IndexNullsDistinct{}); sqlx.Has(change.I.Attrs, &n) && !n.V {
+					b.P("NULLS NOT DISTINCT")
+				}
 				if err := s.indexParts(b, change.I); err != nil {
 					return err
 				}
+				s.indexInclude(b, change.I)
 			case *schema.DropIndex:
 				b.P("DROP CONSTRAINT").Ident(change.I.Name)
 				reverse = append(reverse, &schema.AddIndex{I: change.I})
@@ -668,6 +799,7 @@ func (s *state) alterColumn(b *sqlx.Builder, alter *changeGroup, t *schema.Table
 // alterType appends the clause(s) to alter the column type and assuming the
 // "ALTER COLUMN <Name>" was called before by the alterColumn function.
 func (s *state) alterType(b *sqlx.Builder, alter *changeGroup, t *schema.Table, c *schema.ModifyColumn) error {
+	var using string
 	// Commands for creating and dropping serial sequences.
 	createDropSeq := func(st *SerialType) (string, string, string) {
 		seq := fmt.Sprintf(`%s%q`, s.schemaPrefix(t.Schema), st.sequence(t, c.To))
@@ -746,10 +878,16 @@ func (s *state) alterType(b *sqlx.Builder, alter *changeGroup, t *schema.Table,
 			return err
 		}
 		b.P("TYPE", f)
+		if using, err = s.using(t, c); err != nil {
+			return err
+		}
 	}
 	if collate := (schema.Collation{}); sqlx.Has(c.To.Attrs, &collate) {
 		b.P("COLLATE", collate.V)
 	}
+	if using != "" {
+		b.P("USING", using)
+	}
 	return nil
 }
 
@@ -915,7 +1053,8 @@ func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
 	s.columnDefault(b, c)
 	for _, attr := range c.Attrs {
 		switch a := attr.(type) {
-		case *schema.Comment:
+		case *schema.Comment, *SecurityLabel:
+			// Set using separate statements.
 		case *schema.Collation:
 			b.P("COLLATE").Ident(a.V)
 		case *Identity, *schema.GeneratedExpr:
@@ -974,6 +1113,18 @@ func (s *state) formatDefault(b *sqlx.Builder, t schema.Type, x schema.Expr) {
 	}
 }
 
+// indexInclude writes the INCLUDE clause of covering indexes, if exists.
+func (s *state) indexInclude(b *sqlx.Builder, idx *schema.Index) {
+	if c := (IndexInclude{}); sqlx.Has(idx.Attrs, &c) && len(c.Columns) > 0 {
+		b.P("INCLUDE")
+		b.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(c.Columns, func(i int, b *sqlx.Builder) {
+				b.Ident(c.Columns[i].Name)
+			})
+		})
+	}
+}
+
 func (s *state) indexParts(b *sqlx.Builder, idx *schema.Index) (err error) {
 	b.Wrap(func(b *sqlx.Builder) {
 		err = b.MapCommaErr(idx.Parts, func(i int, b *sqlx.Builder) error {
@@ -1027,6 +1178,73 @@ func (s *state) partAttrs(b *sqlx.Builder, idx *schema.Index, p *schema.IndexPar
 	return nil
 }
 
+// verifyFeatures verifies the changes use only features that are supported by the
+// server version, and returns an error describing the required version otherwise.
+func (s *state) verifyFeatures(changes []schema.Change) error {
+	for _, c := range changes {
+		var (
+			t       *schema.Table
+			columns []*schema.Column
+			indexes []*schema.Index
+		)
+		switch c := c.(type) {
+		case *schema.AddTable:
+			t, columns, indexes = c.T, c.T.Columns, c.T.Indexes
+			if p := (Partition{}); sqlx.Has(t.Attrs, &p) && strings.ToUpper(p.T) == PartitionTypeHash {
+				if err := s.require(featureHashPartition); err != nil {
+					return fmt.Errorf("table %q: %w", t.Name, err)
+				}
+			}
+		case *schema.ModifyTable:
+			t = c.T
+			for _, c := range c.Changes {
+				switch c := c.(type) {
+				case *schema.AddColumn:
+					columns = append(columns, c.C)
+				case *schema.ModifyColumn:
+					columns = append(columns, c.To)
+				case *schema.AddIndex:
+					indexes = append(indexes, c.I)
+				case *schema.ModifyIndex:
+					indexes = append(indexes, c.To)
+				}
+			}
+		case *schema.AddProc:
+			if err := s.require(featureProcedures); err != nil {
+				return fmt.Errorf("procedure %q: %w", c.P.Name, err)
+			}
+			continue
+		default:
+			continue
+		}
+		for _, c := range columns {
+			if sqlx.Has(c.Attrs, &schema.GeneratedExpr{}) {
+				if err := s.require(featureGeneratedColumns); err != nil {
+					return fmt.Errorf("column %q of table %q: %w", c.Name, t.Name, err)
+				}
+			}
+		}
+		for _, idx := range indexes {
+			if sqlx.Has(t.Attrs, &Partition{}) {
+				if err := s.require(featurePartitionedTableIndexes); err != nil {
+					return fmt.Errorf("index %q of table %q: %w", idx.Name, t.Name, err)
+				}
+			}
+			if sqlx.Has(idx.Attrs, &IndexInclude{}) {
+				if err := s.require(featureIndexInclude); err != nil {
+					return fmt.Errorf("index %q of table %q: %w", idx.Name, t.Name, err)
+				}
+			}
+			if n := (IndexNullsDistinct{}); sqlx.Has(idx.Attrs, &n) && !n.V {
+				if err := s.require(featureIndexNullsDistinct); err != nil {
+					return fmt.Errorf("index %q of table %q: %w", idx.Name, t.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (s *state) index(b *sqlx.Builder, idx *schema.Index) error {
 	// Avoid appending the default method.
 	if t := (IndexType{}); sqlx.Has(idx.Attrs, &t) && strings.ToUpper(t.T) != IndexTypeBTree {
@@ -1035,37 +1253,36 @@ func (s *state) index(b *sqlx.Builder, idx *schema.Index) error {
 	if err := s.indexParts(b, idx); err != nil {
 		return err
 	}
-	if c := (IndexInclude{}); sqlx.Has(idx.Attrs, &c) {
-		b.P("INCLUDE")
-		b.Wrap(func(b *sqlx.Builder) {
-			b.MapComma(c.Columns, func(i int, b *sqlx.Builder) {
-				b.Ident(c.Columns[i].Name)
-			})
-		})
-	}
+	s.indexInclude(b, idx)
 	// Avoid appending the default behavior, which NULL values are distinct.
 	if n := (IndexNullsDistinct{}); sqlx.Has(idx.Attrs, &n) && !n.V {
 		b.P("NULLS NOT DISTINCT")
 	}
+	var params []string
 	if p, ok := indexStorageParams(idx.Attrs); ok {
+		if p.AutoSummarize {
+			params = append(params, "autosummarize = true")
+		}
+		if p.PagesPerRange != 0 && p.PagesPerRange != defaultPagePerRange {
+			params = append(params, fmt.Sprintf("pages_per_range = %d", p.PagesPerRange))
+		}
+	}
+	if r := (RawStorageParams{}); sqlx.Has(idx.Attrs, &r) {
+		params = append(params, r.Params...)
+	}
+	if len(params) > 0 {
 		b.P("WITH")
 		b.Wrap(func(b *sqlx.Builder) {
-			var parts []string
-			if p.AutoSummarize {
-				parts = append(parts, "autosummarize = true")
-			}
-			if p.PagesPerRange != 0 && p.PagesPerRange != defaultPagePerRange {
-				parts = append(parts, fmt.Sprintf("pages_per_range = %d", p.PagesPerRange))
-			}
-			b.WriteString(strings.Join(parts, ", "))
+			b.WriteString(strings.Join(params, ", "))
 		})
 	}
+	splitInto(b, idx.Attrs)
 	if p := (IndexPredicate{}); sqlx.Has(idx.Attrs, &p) {
 		b.P("WHERE").P(p.P)
 	}
 	for _, attr := range idx.Attrs {
 		switch attr.(type) {
-		case *schema.Comment, *IndexType, *IndexInclude, *Constraint, *IndexPredicate, *IndexStorageParams, *IndexNullsDistinct:
+		case *schema.Comment, *IndexType, *IndexInclude, *Constraint, *IndexPredicate, *IndexStorageParams, *RawStorageParams, *IndexNullsDistinct, *Tablets:
 		default:
 			return fmt.Errorf("postgres: unexpected index attribute: %T", attr)
 		}
@@ -1091,6 +1308,9 @@ func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) {
 				b.Ident(fk.RefColumns[i].Name)
 			})
 		})
+		if m := fkMatch(fk.Attrs); m != MatchSimple {
+			b.P("MATCH", m)
+		}
 		if fk.OnUpdate != "" {
 			b.P("ON UPDATE", string(fk.OnUpdate))
 		}
@@ -1106,7 +1326,14 @@ func (s *state) append(c ...*migrate.Change) {
 
 // Build instantiates a new builder and writes the given phrase to it.
 func (s *state) Build(phrases ...string) *sqlx.Builder {
-	b := &sqlx.Builder{QuoteOpening: '"', QuoteClosing: '"', Schema: s.SchemaQualifier, Indent: s.Indent}
+	b := &sqlx.Builder{
+		QuoteOpening: '"',
+		QuoteClosing: '"',
+		Schema:       s.SchemaQualifier,
+		Indent:       s.Indent,
+		QuotePolicy:  s.Quote,
+		MustQuote:    mustQuote,
+	}
 	return b.P(phrases...)
 }
 
@@ -19,6 +19,7 @@ import (
 
 func TestPlanChanges(t *testing.T) {
 	tests := []struct {
+		version  string
 		changes  []schema.Change
 		options  []migrate.PlanOption
 		mock     func(mock)
@@ -181,6 +182,54 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").AddColumns(schema.NewStringColumn("nickname", "varchar(255)")),
+					Changes: []schema.Change{
+						&schema.DropIndex{
+							I: schema.NewUniqueIndex("unique_nickname").
+								AddColumns(schema.NewColumn("nickname")).
+								AddAttrs(&Constraint{T: "u"}, &IndexNullsDistinct{V: false}),
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "users" DROP CONSTRAINT "unique_nickname"`,
+						Reverse: `ALTER TABLE "users" ADD CONSTRAINT "unique_nickname" UNIQUE NULLS NOT DISTINCT ("nickname")`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").AddColumns(schema.NewStringColumn("nickname", "varchar(255)"), schema.NewIntColumn("id", "int")),
+					Changes: []schema.Change{
+						&schema.DropIndex{
+							I: schema.NewUniqueIndex("unique_nickname").
+								AddColumns(schema.NewColumn("nickname")).
+								AddAttrs(&Constraint{T: "u"}, &IndexInclude{Columns: []*schema.Column{schema.NewColumn("id")}}),
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "users" DROP CONSTRAINT "unique_nickname"`,
+						Reverse: `ALTER TABLE "users" ADD CONSTRAINT "unique_nickname" UNIQUE ("nickname") INCLUDE ("id")`,
+					},
+				},
+			},
+		},
 		{
 			changes: []schema.Change{
 				&schema.AddSchema{S: &schema.Schema{Name: "test"}},
@@ -520,6 +569,7 @@ func TestPlanChanges(t *testing.T) {
 			},
 		},
 		{
+			version: "150000",
 			changes: []schema.Change{
 				func() schema.Change {
 					users := &schema.Table{
@@ -865,7 +915,7 @@ func TestPlanChanges(t *testing.T) {
 				Changes: []*migrate.Change{
 					{Cmd: `CREATE TYPE "public"."state" AS ENUM ('on', 'off')`, Reverse: `DROP TYPE "public"."state"`},
 					{Cmd: `CREATE TYPE "test"."status" AS ENUM ('a', 'b')`, Reverse: `DROP TYPE "test"."status"`},
-					{Cmd: `ALTER TABLE "public"."users" ALTER COLUMN "state" TYPE "public"."state", ALTER COLUMN "status" TYPE "test"."status", DROP COLUMN "dc1", DROP COLUMN "dc2"`, Reverse: `ALTER TABLE "public"."users" ADD COLUMN "dc2" "public"."de" NOT NULL, ADD COLUMN "dc1" "public"."de" NOT NULL, ALTER COLUMN "status" TYPE text, ALTER COLUMN "state" TYPE text`},
+					{Cmd: `ALTER TABLE "public"."users" ALTER COLUMN "state" TYPE "public"."state" USING "state"::"public"."state", ALTER COLUMN "status" TYPE "test"."status" USING "status"::"test"."status", DROP COLUMN "dc1", DROP COLUMN "dc2"`, Reverse: `ALTER TABLE "public"."users" ADD COLUMN "dc2" "public"."de" NOT NULL, ADD COLUMN "dc1" "public"."de" NOT NULL, ALTER COLUMN "status" TYPE text, ALTER COLUMN "state" TYPE text`},
 					{Cmd: `DROP TYPE "public"."de"`, Reverse: `CREATE TYPE "public"."de" AS ENUM ('on')`},
 				},
 			},
@@ -1526,7 +1576,10 @@ func TestPlanChanges(t *testing.T) {
 			db, mk, err := sqlmock.New()
 			require.NoError(t, err)
 			m := mock{mk}
-			m.version("130000")
+			if tt.version == "" {
+				tt.version = "130000"
+			}
+			m.version(tt.version)
 			if tt.mock != nil {
 				tt.mock(m)
 			}
@@ -1548,6 +1601,66 @@ func TestPlanChanges(t *testing.T) {
 	}
 }
 
+func TestPlanChanges_Features(t *testing.T) {
+	tests := []struct {
+		version string
+		change  schema.Change
+		wantErr string
+	}{
+		{
+			version: "110005",
+			change: &schema.AddTable{
+				T: schema.NewTable("t").AddColumns(
+					schema.NewIntColumn("a", "int"),
+					schema.NewIntColumn("b", "int").SetGeneratedExpr(&schema.GeneratedExpr{Expr: "a * 2", Type: "STORED"}),
+				),
+			},
+			wantErr: `column "b" of table "t": version "11.5" does not support generated columns (requires PostgreSQL >= 12)`,
+		},
+		{
+			version: "140000",
+			change: &schema.ModifyTable{
+				T: schema.NewTable("t"),
+				Changes: []schema.Change{
+					&schema.AddIndex{I: schema.NewUniqueIndex("idx").AddColumns(schema.NewIntColumn("a", "int")).AddAttrs(&IndexNullsDistinct{V: false})},
+				},
+			},
+			wantErr: `index "idx" of table "t": version "14" does not support the NULLS [NOT] DISTINCT clause of indexes (requires PostgreSQL >= 15)`,
+		},
+		{
+			version: "100000",
+			change: &schema.AddTable{
+				T: schema.NewTable("t").AddColumns(schema.NewIntColumn("a", "int")).AddAttrs(&Partition{T: PartitionTypeHash}),
+			},
+			wantErr: `table "t": version "10" does not support hash partitioning (requires PostgreSQL >= 11)`,
+		},
+		{
+			version: "150000",
+			change: &schema.ModifyTable{
+				T: schema.NewTable("t"),
+				Changes: []schema.Change{
+					&schema.AddIndex{I: schema.NewUniqueIndex("idx").AddColumns(schema.NewIntColumn("a", "int")).AddAttrs(&IndexNullsDistinct{V: false})},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			db, mk, err := sqlmock.New()
+			require.NoError(t, err)
+			mock{mk}.version(tt.version)
+			drv, err := Open(db)
+			require.NoError(t, err)
+			_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{tt.change})
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
 func TestDefaultPlan(t *testing.T) {
 	changes, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
 		&schema.AddTable{T: schema.NewTable("t1").SetSchema(schema.New("s1")).AddColumns(schema.NewIntColumn("a", "int"))},
@@ -1562,6 +1675,117 @@ func TestDefaultPlan(t *testing.T) {
 	require.EqualError(t, err, `create "t1" table: cannot execute statements without a database connection. use Open to create a new Driver`)
 }
 
+func TestPlanChanges_QuotePolicy(t *testing.T) {
+	changes := []schema.Change{
+		&schema.AddTable{
+			T: schema.NewTable("Users").
+				SetSchema(schema.New("public")).
+				AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("order", "int")),
+		},
+	}
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", changes, func(o *migrate.PlanOptions) {
+		o.Quote = migrate.QuoteNeeded
+	})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE public."Users" (id integer NOT NULL, "order" integer NOT NULL)`, plan.Changes[0].Cmd)
+
+	// Identifiers that must be quoted are rejected by the QuoteNever policy.
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes, func(o *migrate.PlanOptions) {
+		o.Quote = migrate.QuoteNever
+	})
+	require.EqualError(t, err, `identifier "Users" must be quoted and cannot be written with the QuoteNever policy`)
+
+	changes[0].(*schema.AddTable).T.Name = "users"
+	changes[0].(*schema.AddTable).T.Columns[1].Name = "total"
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", changes, func(o *migrate.PlanOptions) {
+		o.Quote = migrate.QuoteNever
+	})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE public.users (id integer NOT NULL, total integer NOT NULL)`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP TABLE public.users`, plan.Changes[0].Reverse)
+}
+
+func TestPlanChanges_Using(t *testing.T) {
+	users := schema.NewTable("users").SetSchema(schema.New("public"))
+	modify := func(from, to *schema.Column) []schema.Change {
+		return []schema.Change{
+			&schema.ModifyTable{
+				T: users,
+				Changes: []schema.Change{
+					&schema.ModifyColumn{From: from, To: to, Change: schema.ChangeType},
+				},
+			},
+		}
+	}
+	for _, tt := range []struct {
+		from, to *schema.Column
+		cmd      string
+		reverse  any
+	}{
+		{
+			from:    schema.NewIntColumn("c", "integer"),
+			to:      schema.NewIntColumn("c", "bigint"),
+			cmd:     `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE bigint`,
+			reverse: `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE integer`,
+		},
+		{
+			from:    schema.NewStringColumn("c", "text"),
+			to:      schema.NewIntColumn("c", "integer"),
+			cmd:     `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE integer USING "c"::integer`,
+			reverse: `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE text`,
+		},
+		{
+			from:    schema.NewIntColumn("c", "bigint"),
+			to:      schema.NewBoolColumn("c", "boolean"),
+			cmd:     `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE boolean USING "c"::integer::boolean`,
+			reverse: `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE bigint USING "c"::integer`,
+		},
+		{
+			from:    schema.NewEnumColumn("c", schema.EnumName("s1"), schema.EnumValues("a")),
+			to:      schema.NewEnumColumn("c", schema.EnumName("s2"), schema.EnumValues("a")),
+			cmd:     `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE "s2" USING "c"::text::"s2"`,
+			reverse: `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE "s1" USING "c"::text::"s1"`,
+		},
+		{
+			from:    schema.NewStringColumn("c", "text"),
+			to:      schema.NewTimeColumn("c", "date"),
+			cmd:     `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE date USING "c"::date`,
+			reverse: `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE text`,
+		},
+		{
+			from: schema.NewIntColumn("c", "integer"),
+			to:   schema.NewColumn("c").SetType(&CurrencyType{T: TypeMoney}),
+			cmd:  `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE money`,
+			// Not reversible, as money cannot be cast to integer.
+		},
+	} {
+		plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", modify(tt.from, tt.to))
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 1)
+		require.Equal(t, tt.cmd, plan.Changes[0].Cmd)
+		require.Equal(t, tt.reverse, plan.Changes[0].Reverse)
+	}
+
+	// Types without a cast between them.
+	from, to := schema.NewTimeColumn("c", "timestamp"), schema.NewIntColumn("c", "bigint")
+	_, err := DefaultPlan.PlanChanges(context.Background(), "plan", modify(from, to))
+	require.EqualError(t, err, `alter table "users": changing the type of column "c" from timestamp to bigint requires a USING expression (see postgres.RegisterUsing)`)
+
+	RegisterUsing(func(t *schema.Table, c *schema.ModifyColumn) (string, bool) {
+		if t.Name != "users" || c.To.Name != "c" {
+			return "", false
+		}
+		if _, ok := c.To.Type.Type.(*schema.IntegerType); ok {
+			return `extract(epoch from "c")::bigint`, true
+		}
+		return `to_timestamp("c")`, true
+	})
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", modify(from, to))
+	require.NoError(t, err)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE bigint USING extract(epoch from "c")::bigint`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "c" TYPE timestamp USING to_timestamp("c")`, plan.Changes[0].Reverse)
+}
+
 func TestIndentedPlan(t *testing.T) {
 	tests := []struct {
 		T   *schema.Table
@@ -1675,3 +1899,37 @@ func TestIndentedPlan(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanChanges_ForeignKeyUniqueRef(t *testing.T) {
+	var (
+		id     = schema.NewIntColumn("id", "int")
+		code   = schema.NewStringColumn("code", "text")
+		parent = schema.NewNullStringColumn("parent_code", "text")
+		users  = schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(id, code, parent)
+		key    = schema.NewUniqueIndex("users_code").AddColumns(code)
+		fk     = schema.NewForeignKey("parent").AddColumns(parent).SetRefTable(users).AddRefColumns(code).AddAttrs(&ForeignKeyMatch{T: MatchFull})
+	)
+	users.AddIndexes(key).AddForeignKeys(fk)
+	// Self-referencing foreign keys are added after the unique indexes they reference.
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddForeignKey{F: fk},
+				&schema.AddIndex{I: key},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE UNIQUE INDEX "users_code" ON "public"."users" ("code")`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" ADD CONSTRAINT "parent" FOREIGN KEY ("parent_code") REFERENCES "public"."users" ("code") MATCH FULL`, plan.Changes[1].Cmd)
+
+	// Changing the MATCH type rebuilds the foreign key.
+	from := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(id, code, parent).AddIndexes(key)
+	from.AddForeignKeys(schema.NewForeignKey("parent").AddColumns(parent).SetRefTable(from).AddRefColumns(code))
+	changes, err := DefaultDiff.TableDiff(from, users)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, schema.ChangeAttr, changes[0].(*schema.ModifyForeignKey).Change)
+}
@@ -0,0 +1,164 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"fmt"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlspec"
+)
+
+// Owner describes the role that owns a schema or a table. Owners are inspected only if
+// requested (see schema.InspectOptions.Owners), and they are changed only if they are
+// defined in the desired state. That is, objects without an owner are left owned by
+// the role that created them.
+type Owner struct {
+	schema.Attr
+	Name string
+}
+
+// ownerDiff returns the change for migrating the owner of an object, if the owner is
+// defined in the desired state and is different from the current one. Objects whose
+// owners were not inspected are skipped, as their current owners are unknown.
+func ownerDiff(from, to []schema.Attr) schema.Change {
+	var o1, o2 Owner
+	if !sqlx.Has(from, &o1) || !sqlx.Has(to, &o2) || o1.Name == o2.Name {
+		return nil
+	}
+	return &schema.ModifyAttr{From: &o1, To: &o2}
+}
+
+// dropOwners removes the owners from the schemas and tables of the realm.
+func dropOwners(r *schema.Realm) {
+	drop := func(attrs *[]schema.Attr) {
+		if sqlx.Has(*attrs, &Owner{}) {
+			*attrs = schema.RemoveAttr[*Owner](*attrs)
+		}
+	}
+	for _, s := range r.Schemas {
+		drop(&s.Attrs)
+		for _, t := range s.Tables {
+			drop(&t.Attrs)
+		}
+	}
+}
+
+// detachOwners removes the owners from the schemas and tables, and returns a function
+// that restores them. Used when normalizing objects in the dev database, as the roles
+// might not exist there.
+func detachOwners(schemas ...*schema.Schema) (restore func()) {
+	var fs []func()
+	detach := func(attrs *[]schema.Attr) {
+		if prev := *attrs; sqlx.Has(prev, &Owner{}) {
+			*attrs = schema.RemoveAttr[*Owner](prev)
+			fs = append(fs, func() { *attrs = prev })
+		}
+	}
+	for _, s := range schemas {
+		detach(&s.Attrs)
+		for _, t := range s.Tables {
+			detach(&t.Attrs)
+		}
+	}
+	return func() {
+		for _, f := range fs {
+			f()
+		}
+	}
+}
+
+// copyOwners copies the owners of the schema and its tables to the normalized schema.
+func copyOwners(from, to *schema.Schema) {
+	if o := (&Owner{}); sqlx.Has(from.Attrs, o) {
+		schema.ReplaceOrAppend(&to.Attrs, o)
+	}
+	for _, t1 := range from.Tables {
+		o := &Owner{}
+		if !sqlx.Has(t1.Attrs, o) {
+			continue
+		}
+		if t2, ok := to.Table(t1.Name); ok {
+			schema.ReplaceOrAppend(&t2.Attrs, o)
+		}
+	}
+}
+
+// ownerChange returns the current and the desired owners of an attribute change,
+// if it is an owner change. The current owner is nil if it is unknown.
+func ownerChange(c schema.Change) (from, to *Owner, ok bool) {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		to, ok = c.A.(*Owner)
+	case *schema.ModifyAttr:
+		if to, ok = c.To.(*Owner); ok {
+			from, _ = c.From.(*Owner)
+		}
+	}
+	return from, to, ok
+}
+
+// alterOwner returns the statement for changing the owner of the object to the given
+// role. The reverse statement restores the current owner, if it is known. For example:
+//
+//	ALTER TABLE "public"."users" OWNER TO "app"
+func (s *state) alterOwner(b *sqlx.Builder, kind, name string, from, to *Owner) *migrate.Change {
+	c := &migrate.Change{
+		Cmd:     b.Clone().P("OWNER TO").Ident(to.Name).String(),
+		Comment: fmt.Sprintf("set owner of %s %q to %q", kind, name, to.Name),
+	}
+	if from != nil {
+		c.Reverse = b.Clone().P("OWNER TO").Ident(from.Name).String()
+	}
+	return c
+}
+
+// schemaOwner returns the statement for changing the owner of the schema.
+func (s *state) schemaOwner(sc *schema.Schema, from, to *Owner) *migrate.Change {
+	return s.alterOwner(s.Build("ALTER SCHEMA").Ident(sc.Name), "schema", sc.Name, from, to)
+}
+
+// tableOwner returns the statement for changing the owner of the table.
+func (s *state) tableOwner(t *schema.Table, from, to *Owner) *migrate.Change {
+	return s.alterOwner(s.Build("ALTER TABLE").Table(t), "table", t.Name, from, to)
+}
+
+// convertOwner converts the owner attribute of the spec, if exists.
+func convertOwner(spec schemahcl.Resource, attrs *[]schema.Attr) error {
+	a, ok := spec.Attr("owner")
+	if !ok {
+		return nil
+	}
+	v, err := a.String()
+	if err != nil {
+		return fmt.Errorf("parsing attribute owner: %w", err)
+	}
+	*attrs = append(*attrs, &Owner{Name: v})
+	return nil
+}
+
+// fromOwner appends the owner attribute to the spec, if exists.
+func fromOwner(attrs []schema.Attr, spec *schemahcl.Resource) {
+	if o := (Owner{}); sqlx.Has(attrs, &o) && o.Name != "" {
+		spec.Attrs = append(spec.Attrs, schemahcl.StringAttr("owner", o.Name))
+	}
+}
+
+// convertSchemaOwners converts the owner attributes of the schema specs.
+func convertSchemaOwners(specs []*sqlspec.Schema, r *schema.Realm) error {
+	for _, spec := range specs {
+		s, ok := r.Schema(spec.Name)
+		if !ok {
+			return fmt.Errorf("schema %q was not found in realm", spec.Name)
+		}
+		if err := convertOwner(spec.Extra, &s.Attrs); err != nil {
+			return fmt.Errorf("schema %q: %w", spec.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectOwners(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("150000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	expect := func() {
+		mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+			WithArgs("public").
+			WillReturnRows(sqlmock.NewRows([]string{"schema_name", "comment", "owner"}).AddRow("public", nil, "pg_database_owner"))
+		m.ExpectQuery(queryTables).
+			WithArgs("public").
+			WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs", "owner"}).
+				AddRow("public", "users", nil, nil, nil, nil, "app"))
+		mk.ExpectQuery(queryColumns).
+			WithArgs("public", "users").
+			WillReturnRows(sqltest.Rows(`
+table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp | oid
+-----------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+---------+-----
+users      | email      | text      | text      | NO          |                |                          |                   |                    |               |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |  25
+`))
+		mk.noIndexes()
+		mk.noFKs()
+		mk.noChecks()
+		mk.ExpectQuery(queryLabels).
+			WithArgs("public", "users").
+			WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "provider", "label"}))
+		mk.noEnums()
+	}
+	// Owners are inspected only if requested.
+	expect()
+	s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{
+		Mode: schema.InspectSchemas | schema.InspectTables,
+	})
+	require.NoError(t, err)
+	require.Empty(t, s.Attrs)
+	require.Empty(t, s.Tables[0].Attrs)
+
+	expect()
+	s, err = drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{
+		Mode:   schema.InspectSchemas | schema.InspectTables,
+		Owners: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{&Owner{Name: "pg_database_owner"}}, s.Attrs)
+	require.Equal(t, []schema.Attr{&Owner{Name: "app"}}, s.Tables[0].Attrs)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDiff_Owners(t *testing.T) {
+	d := &diff{conn: &conn{ExecQuerier: sqlx.NoRows}}
+	from := schema.New("public").AddAttrs(&Owner{Name: "postgres"})
+	// Owners are changed only if they are defined in the desired state.
+	require.Empty(t, d.SchemaAttrDiff(from, schema.New("public")))
+	require.Equal(t, []schema.Change{
+		&schema.ModifyAttr{From: &Owner{Name: "postgres"}, To: &Owner{Name: "app"}},
+	}, d.SchemaAttrDiff(from, schema.New("public").AddAttrs(&Owner{Name: "app"})))
+
+	// Owners are not changed if the current ones were not inspected.
+	require.Empty(t, d.SchemaAttrDiff(schema.New("public"), schema.New("public").AddAttrs(&Owner{Name: "app"})))
+
+	t1 := schema.NewTable("users").SetSchema(from)
+	t2 := schema.NewTable("users").SetSchema(from).AddAttrs(&Owner{Name: "app"})
+	changes, err := d.TableAttrDiff(t1, t2)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	changes, err = d.TableAttrDiff(t1.AddAttrs(&Owner{Name: "postgres"}), t2)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyAttr{From: &Owner{Name: "postgres"}, To: &Owner{Name: "app"}}}, changes)
+	changes, err = d.TableAttrDiff(t2, t2)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDetachOwners(t *testing.T) {
+	s := schema.New("public").AddAttrs(&Owner{Name: "admin"}, &schema.Comment{Text: "c"})
+	users := schema.NewTable("users").SetSchema(s).AddAttrs(&Owner{Name: "app"})
+	s.AddTables(users, schema.NewTable("posts"))
+	restore := detachOwners(s)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "c"}}, s.Attrs)
+	require.Empty(t, users.Attrs)
+	restore()
+	require.Equal(t, []schema.Attr{&Owner{Name: "admin"}, &schema.Comment{Text: "c"}}, s.Attrs)
+	require.Equal(t, []schema.Attr{&Owner{Name: "app"}}, users.Attrs)
+
+	// Owners are copied to the normalized objects.
+	ns := schema.New("public").AddTables(schema.NewTable("users"), schema.NewTable("posts"))
+	copyOwners(s, ns)
+	require.Equal(t, []schema.Attr{&Owner{Name: "admin"}}, ns.Attrs)
+	require.Equal(t, []schema.Attr{&Owner{Name: "app"}}, ns.Tables[0].Attrs)
+	require.Empty(t, ns.Tables[1].Attrs)
+}
+
+func TestPlanChanges_Owners(t *testing.T) {
+	drv := &planApply{conn: &conn{ExecQuerier: sqlx.NoRows}}
+	s := schema.New("app").AddAttrs(&Owner{Name: "app"})
+	users := schema.NewTable("users").
+		SetSchema(s).
+		AddColumns(schema.NewStringColumn("email", "text")).
+		AddAttrs(&Owner{Name: "app"})
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddSchema{S: s}, &schema.AddTable{T: users}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`CREATE SCHEMA "app"`,
+		`ALTER SCHEMA "app" OWNER TO "app"`,
+		`CREATE TABLE "app"."users" ("email" text NOT NULL)`,
+		`ALTER TABLE "app"."users" OWNER TO "app"`,
+	}, cmds(plan))
+
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifySchema{S: s, Changes: []schema.Change{
+			&schema.ModifyAttr{From: &Owner{Name: "app"}, To: &Owner{Name: "admin"}},
+		}},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.ModifyAttr{From: &Owner{Name: "app"}, To: &Owner{Name: "admin"}},
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`ALTER SCHEMA "app" OWNER TO "admin"`,
+		`ALTER TABLE "app"."users" OWNER TO "admin"`,
+	}, cmds(plan))
+	require.Equal(t, `ALTER SCHEMA "app" OWNER TO "app"`, plan.Changes[0].Reverse)
+	require.Equal(t, `ALTER TABLE "app"."users" OWNER TO "app"`, plan.Changes[1].Reverse)
+}
+
+func TestSQLSpec_Owners(t *testing.T) {
+	f := `
+schema "public" {
+	owner = "admin"
+}
+table "users" {
+	schema = schema.public
+	owner  = "app"
+	column "email" {
+		type = text
+	}
+}
+`
+	var r schema.Realm
+	require.NoError(t, EvalHCLBytes([]byte(f), &r, nil))
+	require.Equal(t, []schema.Attr{&Owner{Name: "admin"}}, r.Schemas[0].Attrs)
+	require.Equal(t, []schema.Attr{&Owner{Name: "app"}}, r.Schemas[0].Tables[0].Attrs)
+
+	buf, err := MarshalHCL(&r)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `owner = "admin"`)
+	require.Contains(t, string(buf), `owner  = "app"`)
+	var r2 schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &r2, nil))
+	require.Equal(t, r.Schemas[0].Attrs, r2.Schemas[0].Attrs)
+	require.Equal(t, r.Schemas[0].Tables[0].Attrs, r2.Schemas[0].Tables[0].Attrs)
+}
@@ -0,0 +1,191 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/schema"
+)
+
+// TypeGeography is the PostGIS geography type. See TypeGeometry for the geometry type.
+const TypeGeography = "geography"
+
+// GeometryType defines a PostGIS geometry or geography type. For example:
+//
+//	geometry
+//	geometry(Point, 4326)
+//	geography(MultiPolygonZ)
+type GeometryType struct {
+	schema.Type
+	T string // geometry or geography.
+	// Subtype is the geometry subtype, e.g. Point or Polygon.
+	// An empty subtype accepts all geometries.
+	Subtype string
+	// Dim holds the dimension modifier of the subtype: Z, M or ZM.
+	Dim string
+	// SRID is the spatial reference identifier. Zero means the
+	// default: unknown for geometry types, and 4326 for geography.
+	SRID int
+}
+
+// geometrySubtypes holds the canonical names of the PostGIS geometry subtypes.
+var geometrySubtypes = []string{
+	"Geometry", "Point", "LineString", "Polygon", "MultiPoint", "MultiLineString",
+	"MultiPolygon", "GeometryCollection", "CircularString", "CompoundCurve",
+	"CurvePolygon", "MultiCurve", "MultiSurface", "PolyhedralSurface", "Triangle", "Tin",
+}
+
+// defaultSRID returns the SRID of the type, or its default if it was not set.
+func (t *GeometryType) defaultSRID() int {
+	if t.SRID == 0 && strings.EqualFold(t.T, TypeGeography) {
+		return 4326
+	}
+	return t.SRID
+}
+
+// subtype returns the type modifier of the geometry, e.g. PointZ.
+func (t *GeometryType) subtype() string {
+	s := t.Subtype
+	if s == "" && (t.Dim != "" || t.SRID != 0) {
+		s = "Geometry"
+	}
+	return s + strings.ToUpper(t.Dim)
+}
+
+// formatGeometry returns the column form of the geometry type.
+func formatGeometry(t *GeometryType) (string, error) {
+	f := strings.ToLower(t.T)
+	if f != TypeGeometry && f != TypeGeography {
+		return "", fmt.Errorf("postgres: unexpected spatial type: %q", t.T)
+	}
+	switch s := t.subtype(); {
+	case s == "":
+	case t.SRID != 0:
+		f = fmt.Sprintf("%s(%s,%d)", f, s, t.SRID)
+	default:
+		f = fmt.Sprintf("%s(%s)", f, s)
+	}
+	return f, nil
+}
+
+// parseGeometry parses the geometry type from its parts. e.g. [geometry, PointZ, 4326].
+func parseGeometry(parts []string) (*GeometryType, error) {
+	t := &GeometryType{T: strings.ToLower(parts[0])}
+	if len(parts) > 1 {
+		s, d, err := splitGeometrySubtype(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		t.Subtype, t.Dim = s, d
+	}
+	if len(parts) > 2 {
+		srid, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("postgres: parse srid %q: %w", parts[2], err)
+		}
+		t.SRID = srid
+	}
+	// The Geometry subtype accepts all geometries.
+	if t.Subtype == "Geometry" {
+		t.Subtype = ""
+	}
+	return t, nil
+}
+
+// splitGeometrySubtype splits the subtype into its canonical name and dimension modifier.
+func splitGeometrySubtype(s string) (string, string, error) {
+	for _, d := range []string{"ZM", "Z", "M", ""} {
+		if !strings.HasSuffix(strings.ToUpper(s), d) {
+			continue
+		}
+		name := s[:len(s)-len(d)]
+		for _, n := range geometrySubtypes {
+			if strings.EqualFold(n, name) {
+				return n, d, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("postgres: unknown geometry subtype %q", s)
+}
+
+// geometryChanged reports if the geometry type was changed.
+func geometryChanged(from, to *GeometryType) bool {
+	return !strings.EqualFold(from.T, to.T) || from.Subtype != to.Subtype ||
+		!strings.EqualFold(from.Dim, to.Dim) || from.defaultSRID() != to.defaultSRID()
+}
+
+// defaultGeometryOps holds the default operator classes of the PostGIS types, per index method.
+var defaultGeometryOps = map[string]map[string]string{
+	TypeGeometry: {
+		IndexTypeBTree:  "btree_geometry_ops",
+		IndexTypeHash:   "hash_geometry_ops",
+		IndexTypeGiST:   "gist_geometry_ops_2d",
+		IndexTypeSPGiST: "spgist_geometry_ops_2d",
+		IndexTypeBRIN:   "brin_geometry_inclusion_ops_2d",
+	},
+	TypeGeography: {
+		IndexTypeBTree: "btree_geography_ops",
+		IndexTypeGiST:  "gist_geography_ops",
+		IndexTypeBRIN:  "brin_geography_inclusion_ops",
+	},
+}
+
+// geometryTypeSpecs returns the HCL specs of the PostGIS types. For example:
+//
+//	type = geometry
+//	type = geometry("PointZ", 4326)
+//	type = geography("Polygon")
+func geometryTypeSpecs() []*schemahcl.TypeSpec {
+	opts := []schemahcl.TypeSpecOption{
+		schemahcl.WithAttributes(
+			&schemahcl.TypeAttr{Name: "subtype", Kind: reflect.String},
+			&schemahcl.TypeAttr{Name: "srid", Kind: reflect.Int},
+		),
+		schemahcl.WithToSpec(func(t schema.Type) (*schemahcl.Type, error) {
+			g, ok := t.(*GeometryType)
+			if !ok {
+				return nil, fmt.Errorf("postgres: unexpected spatial type %T", t)
+			}
+			spec := &schemahcl.Type{T: strings.ToLower(g.T)}
+			if s := g.subtype(); s != "" {
+				spec.Attrs = append(spec.Attrs, schemahcl.StringAttr("subtype", s))
+			}
+			if g.SRID != 0 {
+				spec.Attrs = append(spec.Attrs, schemahcl.IntAttr("srid", g.SRID))
+			}
+			return spec, nil
+		}),
+		schemahcl.WithFromSpec(func(t *schemahcl.Type) (schema.Type, error) {
+			parts := []string{t.T}
+			if a, ok := attr(t, "subtype"); ok {
+				s, err := a.String()
+				if err != nil {
+					return nil, fmt.Errorf(`postgres: parsing attribute "subtype": %w`, err)
+				}
+				parts = append(parts, s)
+			}
+			if a, ok := attr(t, "srid"); ok {
+				srid, err := a.Int()
+				if err != nil {
+					return nil, fmt.Errorf(`postgres: parsing attribute "srid": %w`, err)
+				}
+				if len(parts) == 1 {
+					parts = append(parts, "Geometry")
+				}
+				parts = append(parts, strconv.Itoa(srid))
+			}
+			return parseGeometry(parts)
+		}),
+	}
+	return []*schemahcl.TypeSpec{
+		schemahcl.NewTypeSpec(TypeGeometry, opts...),
+		schemahcl.NewTypeSpec(TypeGeography, opts...),
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeometryType(t *testing.T) {
+	for _, tt := range []struct {
+		raw, formatted string
+		typ            *GeometryType
+	}{
+		{raw: "geometry", formatted: "geometry", typ: &GeometryType{T: TypeGeometry}},
+		{raw: "geometry(Geometry)", formatted: "geometry", typ: &GeometryType{T: TypeGeometry}},
+		{raw: "geometry(point)", formatted: "geometry(Point)", typ: &GeometryType{T: TypeGeometry, Subtype: "Point"}},
+		{raw: "geometry(Point,4326)", formatted: "geometry(Point,4326)", typ: &GeometryType{T: TypeGeometry, Subtype: "Point", SRID: 4326}},
+		{raw: "geometry(POINTZM, 3857)", formatted: "geometry(PointZM,3857)", typ: &GeometryType{T: TypeGeometry, Subtype: "Point", Dim: "ZM", SRID: 3857}},
+		{raw: "geometry(GeometryZ,4326)", formatted: "geometry(GeometryZ,4326)", typ: &GeometryType{T: TypeGeometry, Dim: "Z", SRID: 4326}},
+		{raw: "geography(MultiPolygonM)", formatted: "geography(MultiPolygonM)", typ: &GeometryType{T: TypeGeography, Subtype: "MultiPolygon", Dim: "M"}},
+	} {
+		t.Run(tt.raw, func(t *testing.T) {
+			typ, err := ParseType(tt.raw)
+			require.NoError(t, err)
+			require.Equal(t, tt.typ, typ)
+			f, err := FormatType(typ)
+			require.NoError(t, err)
+			require.Equal(t, tt.formatted, f)
+		})
+	}
+	_, err := ParseType("geometry(Cube)")
+	require.EqualError(t, err, `postgres: unknown geometry subtype "Cube"`)
+
+	// Inspected as user-defined types.
+	typ, err := columnType(&columnDesc{typ: TypeUserDefined, fmtype: "geography(Point,4326)"})
+	require.NoError(t, err)
+	require.Equal(t, &GeometryType{T: TypeGeography, Subtype: "Point", SRID: 4326}, typ)
+}
+
+func TestDiff_GeometryType(t *testing.T) {
+	d := &diff{conn: &conn{}}
+	for _, tt := range []struct {
+		from, to *GeometryType
+		changed  bool
+	}{
+		{from: &GeometryType{T: TypeGeometry}, to: &GeometryType{T: TypeGeometry}},
+		{from: &GeometryType{T: TypeGeography, Subtype: "Point", SRID: 4326}, to: &GeometryType{T: TypeGeography, Subtype: "Point"}},
+		{from: &GeometryType{T: TypeGeometry, Subtype: "Point", SRID: 4326}, to: &GeometryType{T: TypeGeometry, Subtype: "Point"}, changed: true},
+		{from: &GeometryType{T: TypeGeometry, Subtype: "Point"}, to: &GeometryType{T: TypeGeometry, Subtype: "Point", Dim: "Z"}, changed: true},
+		{from: &GeometryType{T: TypeGeometry, Subtype: "Point"}, to: &GeometryType{T: TypeGeography, Subtype: "Point"}, changed: true},
+	} {
+		changed, err := d.typeChanged(
+			schema.NewColumn("c").SetType(tt.from),
+			schema.NewColumn("c").SetType(tt.to),
+		)
+		require.NoError(t, err)
+		require.Equal(t, tt.changed, changed)
+	}
+}
+
+func TestIndexOpClass_DefaultForGeometry(t *testing.T) {
+	c := schema.NewColumn("geom").SetType(&GeometryType{T: TypeGeometry, Subtype: "Point", SRID: 4326})
+	idx := schema.NewIndex("idx").AddAttrs(&IndexType{T: IndexTypeGiST})
+	part := &schema.IndexPart{C: c}
+	ok, err := (&IndexOpClass{Name: "gist_geometry_ops_2d"}).DefaultFor(idx, part)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = (&IndexOpClass{Name: "gist_geometry_ops_nd"}).DefaultFor(idx, part)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestSQLSpec_GeometryType(t *testing.T) {
+	f := `
+schema "public" {}
+table "places" {
+	schema = schema.public
+	column "a" {
+		type = geometry
+	}
+	column "b" {
+		type = geometry("PointZ", 4326)
+	}
+	column "c" {
+		type = geography("Polygon")
+	}
+	index "places_b" {
+		type    = GIST
+		columns = [column.b]
+	}
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	places := s.Tables[0]
+	require.Equal(t, &GeometryType{T: TypeGeometry}, places.Columns[0].Type.Type)
+	require.Equal(t, &GeometryType{T: TypeGeometry, Subtype: "Point", Dim: "Z", SRID: 4326}, places.Columns[1].Type.Type)
+	require.Equal(t, &GeometryType{T: TypeGeography, Subtype: "Polygon"}, places.Columns[2].Type.Type)
+
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `type = geometry("PointZ",4326)`)
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	for i, c := range places.Columns {
+		require.Equal(t, c.Type.Type, s2.Tables[0].Columns[i].Type.Type)
+	}
+}
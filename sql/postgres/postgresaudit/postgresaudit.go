@@ -0,0 +1,60 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package postgresaudit provides the PostgreSQL dialect of the audit package.
+package postgresaudit
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/audit"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Dialect is the PostgreSQL dialect. Changes are recorded by a trigger function
+// that converts the old and new rows to JSONB, and the actor is the current user.
+var Dialect = &audit.Dialect{
+	ID: func() *schema.Column {
+		return schema.NewIntColumn(audit.ColumnID, postgres.TypeBigInt).
+			AddAttrs(&postgres.Identity{Generation: "BY DEFAULT"})
+	},
+	String:  &schema.StringType{T: postgres.TypeText},
+	JSON:    &schema.JSONType{T: postgres.TypeJSONB},
+	Time:    &schema.TimeType{T: postgres.TypeTimestampTZ},
+	Now:     "now()",
+	Actor:   "current_user",
+	MaxName: 63,
+	Triggers: func(a *audit.Audit) error {
+		log := quote(a.Log.Name)
+		if a.Log.Schema != nil && a.Log.Schema.Name != "" {
+			log = quote(a.Log.Schema.Name) + "." + log
+		}
+		a.T.AddTriggers(&schema.Trigger{
+			Name:       a.Log.Name,
+			ActionTime: schema.TriggerTimeAfter,
+			Events:     []schema.TriggerEvent{schema.TriggerEventInsert, schema.TriggerEventUpdate, schema.TriggerEventDelete},
+			For:        schema.TriggerForRow,
+			Attrs: []schema.Attr{
+				&postgres.TriggerFunc{
+					F: &schema.Func{
+						Name: a.Name("_trigger"),
+						Lang: "plpgsql",
+						Ret:  &schema.UnsupportedType{T: "trigger"},
+						Body: fmt.Sprintf(
+							"BEGIN\n\tINSERT INTO %s (%s) VALUES (TG_OP, CASE WHEN TG_OP <> 'INSERT' THEN to_jsonb(OLD) END, CASE WHEN TG_OP <> 'DELETE' THEN to_jsonb(NEW) END, %s);\n\tRETURN NULL;\nEND;",
+							log, audit.Columns(quote), a.Actor,
+						),
+					},
+				},
+			},
+		})
+		return nil
+	},
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,67 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgresaudit_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/audit"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/postgres/postgresaudit"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect(t *testing.T) {
+	s := schema.New("public").AddTables(
+		schema.NewTable("users").AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewStringColumn("user's name", "text"),
+		),
+	)
+	desired, err := audit.Generate(postgresaudit.Dialect, s)
+	require.NoError(t, err)
+	users, _ := desired.Table("users")
+	require.Len(t, users.Triggers, 1)
+	log, ok := desired.Table("users_audit")
+	require.True(t, ok)
+	plan, err := postgres.DefaultPlan.PlanChanges(context.Background(), "audit", []schema.Change{
+		&schema.AddTable{T: log},
+		&schema.AddTrigger{T: users.Triggers[0]},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, `CREATE TABLE "public"."users_audit" ("id" bigint NOT NULL GENERATED BY DEFAULT AS IDENTITY, "operation" text NOT NULL, "old_row" jsonb NULL, "new_row" jsonb NULL, "actor" text NULL, "changed_at" timestamptz NOT NULL DEFAULT now(), PRIMARY KEY ("id"))`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE OR REPLACE FUNCTION "public"."users_audit_trigger"() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+	INSERT INTO "public"."users_audit" ("operation", "old_row", "new_row", "actor") VALUES (TG_OP, CASE WHEN TG_OP <> 'INSERT' THEN to_jsonb(OLD) END, CASE WHEN TG_OP <> 'DELETE' THEN to_jsonb(NEW) END, current_user);
+	RETURN NULL;
+END;
+$$`, plan.Changes[2].Cmd)
+	require.Equal(t, `CREATE TRIGGER "users_audit" AFTER INSERT OR UPDATE OR DELETE ON "public"."users" FOR EACH ROW EXECUTE FUNCTION "public"."users_audit_trigger"()`, plan.Changes[3].Cmd)
+	require.Equal(t, `DROP TRIGGER "users_audit" ON "public"."users"`, plan.Changes[3].Reverse)
+}
+
+func TestDialect_MaxName(t *testing.T) {
+	s := schema.New("public").AddTables(
+		schema.NewTable(strings.Repeat("t", 60)).AddColumns(schema.NewIntColumn("id", "int")),
+	)
+	desired, err := audit.Generate(postgresaudit.Dialect, s)
+	require.NoError(t, err)
+	require.Len(t, desired.Tables[1].Name, 63)
+	tr := desired.Tables[0].Triggers[0]
+	require.Len(t, tr.Name, 63)
+	var f postgres.TriggerFunc
+	for _, a := range tr.Attrs {
+		if a, ok := a.(*postgres.TriggerFunc); ok {
+			f = *a
+		}
+	}
+	require.Len(t, f.F.Name, 63)
+	require.NotEqual(t, tr.Name, f.F.Name)
+}
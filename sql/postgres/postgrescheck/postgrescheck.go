@@ -49,7 +49,8 @@ func init() {
 			return nil, err
 		}
 		dd, err := datadepend.New(r, datadepend.Handler{
-			AddNotNull: addNotNull,
+			AddNotNull:     addNotNull,
+			TypeConversion: postgres.TypeConversion,
 		})
 		bc, err := incompatible.New(r)
 		if err != nil {
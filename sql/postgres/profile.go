@@ -0,0 +1,68 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// A Profile describes the quirks of the engine the driver is connected to. It is
+// selected automatically when the driver is opened, based on the server settings.
+type Profile string
+
+// List of engine profiles.
+const (
+	ProfileDefault Profile = ""
+	// ProfileRDS is used for Amazon RDS for PostgreSQL.
+	ProfileRDS Profile = "rds"
+	// ProfileAurora is used for Amazon Aurora PostgreSQL.
+	ProfileAurora Profile = "aurora"
+	// ProfileYugabyte is used for YugabyteDB (YSQL).
+	ProfileYugabyte Profile = "yugabyte"
+)
+
+// Managed reports if the profile describes a managed service, in which the
+// master user is not a superuser and some objects are owned by the service.
+func (p Profile) Managed() bool {
+	return p == ProfileRDS || p == ProfileAurora
+}
+
+// Profile returns the engine profile of the connected database.
+func (d *Driver) Profile() Profile {
+	return d.conn.profile
+}
+
+// profileFor returns the profile that matches the server settings. The rds.extensions
+// setting exists only on RDS and Aurora, and lists the extensions allowed by the service.
+// Aurora is identified by its query plan management extension, that is not available on RDS.
+// YugabyteDB is identified by its marker in the server_version setting.
+func profileFor(params map[string]string) Profile {
+	exts, ok := params["rds.extensions"]
+	switch {
+	case strings.Contains(params["server_version"], yugabyteVersion):
+		return ProfileYugabyte
+	case !ok:
+		return ProfileDefault
+	case strings.Contains(exts, "apg_plan_mgmt"):
+		return ProfileAurora
+	default:
+		return ProfileRDS
+	}
+}
+
+// scanParams scans the rows returned by the paramsQuery into a map.
+func scanParams(rows *sql.Rows) (map[string]string, error) {
+	defer rows.Close()
+	params := make(map[string]string)
+	for rows.Next() {
+		var name, setting string
+		if err := rows.Scan(&name, &setting); err != nil {
+			return nil, err
+		}
+		params[name] = setting
+	}
+	return params, rows.Close()
+}
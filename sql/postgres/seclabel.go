@@ -0,0 +1,250 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlspec"
+)
+
+// SecurityLabel describes a security label that was defined on a column by a label
+// provider. Labels are opaque to Atlas, and are used by extensions to attach vendor
+// specific rules to columns. For example, the masking rules of PostgreSQL Anonymizer:
+//
+//	SECURITY LABEL FOR anon ON COLUMN users.email IS 'MASKED WITH FUNCTION anon.fake_email()';
+type SecurityLabel struct {
+	schema.Attr
+	Provider string // e.g. anon or sepgsql.
+	Label    string
+}
+
+// MaskedWith returns the PostgreSQL Anonymizer label that masks a column
+// using the given function. For example, anon.fake_email().
+func MaskedWith(fn string) *SecurityLabel {
+	return &SecurityLabel{Provider: "anon", Label: "MASKED WITH FUNCTION " + fn}
+}
+
+// labels returns the security labels of the attributes, keyed by their provider.
+func labels(attrs []schema.Attr) map[string]string {
+	m := make(map[string]string)
+	for _, a := range attrs {
+		if l, ok := a.(*SecurityLabel); ok {
+			m[l.Provider] = l.Label
+		}
+	}
+	return m
+}
+
+// labelsChanged reports if the security labels of the column were changed.
+func labelsChanged(from, to []schema.Attr) bool {
+	return !mapsEqual(labels(from), labels(to))
+}
+
+// securityLabels adds the security labels to the columns of the schema tables.
+func (i *inspect) securityLabels(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, securityLabelsQuery, s)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q security labels: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, column, provider, label string
+		if err := rows.Scan(&table, &column, &provider, &label); err != nil {
+			return fmt.Errorf("postgres: scanning security label: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("table %q was not found in schema", table)
+		}
+		c, ok := t.Column(column)
+		if !ok {
+			return fmt.Errorf("postgres: column %q was not found for security label of table %q", column, table)
+		}
+		c.Attrs = append(c.Attrs, &SecurityLabel{Provider: provider, Label: label})
+	}
+	return rows.Err()
+}
+
+// columnLabels returns the statements for setting the security labels of the column.
+func (s *state) columnLabels(t *schema.Table, from, to *schema.Column) []*migrate.Change {
+	var (
+		changes    []*migrate.Change
+		fromL, toL = labels(from.Attrs), labels(to.Attrs)
+		providers  = make(map[string]bool)
+	)
+	for p := range fromL {
+		providers[p] = true
+	}
+	for p := range toL {
+		providers[p] = true
+	}
+	for _, p := range sortedKeys(providers) {
+		l1, ok1 := fromL[p]
+		l2, ok2 := toL[p]
+		if ok1 == ok2 && l1 == l2 {
+			continue
+		}
+		b := s.Build("SECURITY LABEL FOR").Ident(p).P("ON COLUMN").Table(t)
+		b.WriteByte('.')
+		b.Ident(to.Name).P("IS")
+		changes = append(changes, &migrate.Change{
+			Cmd:     b.Clone().P(labelValue(l2, ok2)).String(),
+			Comment: fmt.Sprintf("set %s security label to column: %q on table: %q", p, to.Name, t.Name),
+			Reverse: b.Clone().P(labelValue(l1, ok1)).String(),
+		})
+	}
+	return changes
+}
+
+// addLabels sets the security labels of the columns of a new table.
+func (s *state) addLabels(t *schema.Table) {
+	for _, c := range t.Columns {
+		s.append(s.columnLabels(t, &schema.Column{}, c)...)
+	}
+}
+
+// labelValue returns the value of the SECURITY LABEL statement. NULL drops the label.
+func labelValue(l string, ok bool) string {
+	if !ok {
+		return "NULL"
+	}
+	return quote(l)
+}
+
+// A labelAttr is an HCL column attribute that is stored as a security label.
+type labelAttr struct {
+	name, provider string
+	prefix, suffix string
+}
+
+var labelAttrs struct {
+	sync.Mutex
+	attrs []*labelAttr
+}
+
+// RegisterLabelAttr registers an HCL column attribute that is stored as a security
+// label of the given provider. The format describes the label, and must contain one
+// %s verb that is replaced by the attribute value. For example, the builtin masked_with
+// attribute of PostgreSQL Anonymizer is registered as follows:
+//
+//	RegisterLabelAttr("masked_with", "anon", "MASKED WITH FUNCTION %s")
+//
+// Security labels of providers that were not registered are preserved using the
+// generic security_label block.
+func RegisterLabelAttr(name, provider, format string) {
+	prefix, suffix, ok := strings.Cut(format, "%s")
+	if !ok || strings.Contains(suffix, "%s") {
+		panic("postgres: RegisterLabelAttr format must contain exactly one %s verb")
+	}
+	labelAttrs.Lock()
+	defer labelAttrs.Unlock()
+	for _, a := range labelAttrs.attrs {
+		if a.name == name {
+			panic("postgres: RegisterLabelAttr called twice for attribute " + name)
+		}
+	}
+	labelAttrs.attrs = append(labelAttrs.attrs, &labelAttr{name: name, provider: provider, prefix: prefix, suffix: suffix})
+}
+
+func init() {
+	RegisterLabelAttr("masked_with", "anon", "MASKED WITH FUNCTION %s")
+}
+
+// convertLabels converts the security labels of the column spec.
+func convertLabels(spec *sqlspec.Column, c *schema.Column) error {
+	labelAttrs.Lock()
+	defer labelAttrs.Unlock()
+	for _, a := range labelAttrs.attrs {
+		attr, ok := spec.Attr(a.name)
+		if !ok {
+			continue
+		}
+		v, err := attr.String()
+		if err != nil {
+			return fmt.Errorf("parsing %s.%s: %w", c.Name, a.name, err)
+		}
+		c.Attrs = append(c.Attrs, &SecurityLabel{Provider: a.provider, Label: a.prefix + v + a.suffix})
+	}
+	for _, r := range spec.Extra.Children {
+		if r.Type != "security_label" {
+			continue
+		}
+		var l struct {
+			Provider string `spec:"provider"`
+			Label    string `spec:"label"`
+		}
+		if err := r.As(&l); err != nil {
+			return fmt.Errorf("parsing %s.security_label: %w", c.Name, err)
+		}
+		if l.Provider == "" {
+			return fmt.Errorf("missing attribute %s.security_label.provider", c.Name)
+		}
+		c.Attrs = append(c.Attrs, &SecurityLabel{Provider: l.Provider, Label: l.Label})
+	}
+	return nil
+}
+
+// fromLabels converts the security labels of the column to their spec.
+func fromLabels(c *schema.Column, spec *sqlspec.Column) {
+	labelAttrs.Lock()
+	defer labelAttrs.Unlock()
+	for _, a := range c.Attrs {
+		l, ok := a.(*SecurityLabel)
+		if !ok {
+			continue
+		}
+		if a, ok := registeredLabel(l); ok {
+			v := strings.TrimSuffix(strings.TrimPrefix(l.Label, a.prefix), a.suffix)
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr(a.name, v))
+			continue
+		}
+		spec.Extra.Children = append(spec.Extra.Children, &schemahcl.Resource{
+			Type: "security_label",
+			Attrs: []*schemahcl.Attr{
+				schemahcl.StringAttr("provider", l.Provider),
+				schemahcl.StringAttr("label", l.Label),
+			},
+		})
+	}
+}
+
+// registeredLabel returns the registered attribute that matches the label, if any.
+func registeredLabel(l *SecurityLabel) (*labelAttr, bool) {
+	for _, a := range labelAttrs.attrs {
+		if a.provider == l.Provider && strings.HasPrefix(l.Label, a.prefix) && strings.HasSuffix(l.Label, a.suffix) &&
+			len(l.Label) > len(a.prefix)+len(a.suffix) {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// Query to list the security labels of the columns in the schema tables.
+const securityLabelsQuery = `
+SELECT
+	c.relname AS table_name,
+	a.attname AS column_name,
+	l.provider,
+	l.label
+FROM
+	pg_catalog.pg_seclabel l
+	JOIN pg_catalog.pg_class c ON c.oid = l.objoid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_attribute a ON a.attrelid = c.oid AND a.attnum = l.objsubid
+WHERE
+	l.classoid = 'pg_catalog.pg_class'::regclass
+	AND l.objsubid > 0
+	AND n.nspname = $1
+	AND c.relname IN (%s)
+ORDER BY
+	c.relname, a.attnum, l.provider
+`
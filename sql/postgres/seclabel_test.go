@@ -0,0 +1,125 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectSecurityLabels(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("150000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "comment", "owner"}).AddRow("public", nil, nil))
+	mk.tableExists("public", "users", true)
+	mk.ExpectQuery(queryColumns).
+		WithArgs("public", "users").
+		WillReturnRows(sqltest.Rows(`
+table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp | oid
+-----------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+---------+-----
+users      | email      | text      | text      | NO          |                |                          |                   |                    |               |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |  25
+`))
+	mk.noIndexes()
+	mk.noFKs()
+	mk.noChecks()
+	mk.ExpectQuery(queryLabels).
+		WithArgs("public", "users").
+		WillReturnRows(sqltest.Rows(`
+ table_name | column_name | provider | label
+------------+-------------+----------+-------------------------------------------
+ users      | email       | anon     | MASKED WITH FUNCTION anon.fake_email()
+ users      | email       | sepgsql  | system_u:object_r:sepgsql_table_t:s0
+`))
+	mk.noEnums()
+	s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{
+		Mode: schema.InspectSchemas | schema.InspectTables,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{
+		MaskedWith("anon.fake_email()"),
+		&SecurityLabel{Provider: "sepgsql", Label: "system_u:object_r:sepgsql_table_t:s0"},
+	}, s.Tables[0].Columns[0].Attrs)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDiff_SecurityLabels(t *testing.T) {
+	d := &diff{conn: &conn{ExecQuerier: sqlx.NoRows}}
+	from := schema.NewStringColumn("email", "text")
+	to := schema.NewStringColumn("email", "text").AddAttrs(MaskedWith("anon.fake_email()"))
+	change, err := d.ColumnChange(nil, from, to)
+	require.NoError(t, err)
+	require.Equal(t, schema.ChangeAttr, change)
+	change, err = d.ColumnChange(nil, to, to)
+	require.NoError(t, err)
+	require.Equal(t, schema.NoChange, change)
+}
+
+func TestPlanChanges_SecurityLabels(t *testing.T) {
+	drv := &planApply{conn: &conn{ExecQuerier: sqlx.NoRows}}
+	users := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewStringColumn("email", "text").AddAttrs(MaskedWith("anon.fake_email()")))
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`CREATE TABLE "public"."users" ("email" text NOT NULL)`,
+		`SECURITY LABEL FOR "anon" ON COLUMN "public"."users" ."email" IS 'MASKED WITH FUNCTION anon.fake_email()'`,
+	}, cmds(plan))
+
+	to := schema.NewStringColumn("email", "text").AddAttrs(&SecurityLabel{Provider: "sepgsql", Label: "unconfined_u"})
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.ModifyColumn{From: users.Columns[0], To: to, Change: schema.ChangeAttr},
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`SECURITY LABEL FOR "anon" ON COLUMN "public"."users" ."email" IS NULL`,
+		`SECURITY LABEL FOR "sepgsql" ON COLUMN "public"."users" ."email" IS 'unconfined_u'`,
+	}, cmds(plan))
+	require.Equal(t, `SECURITY LABEL FOR "anon" ON COLUMN "public"."users" ."email" IS 'MASKED WITH FUNCTION anon.fake_email()'`, plan.Changes[0].Reverse)
+}
+
+func TestSQLSpec_SecurityLabels(t *testing.T) {
+	f := `
+schema "public" {}
+table "users" {
+	schema = schema.public
+	column "email" {
+		type        = text
+		masked_with = "anon.fake_email()"
+		security_label {
+			provider = "sepgsql"
+			label    = "unconfined_u"
+		}
+	}
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	attrs := []schema.Attr{MaskedWith("anon.fake_email()"), &SecurityLabel{Provider: "sepgsql", Label: "unconfined_u"}}
+	require.Equal(t, attrs, s.Tables[0].Columns[0].Attrs)
+
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `masked_with = "anon.fake_email()"`)
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	require.Equal(t, attrs, s2.Tables[0].Columns[0].Attrs)
+}
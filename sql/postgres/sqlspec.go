@@ -5,6 +5,7 @@
 package postgres
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -17,19 +18,22 @@ import (
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlspec"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/zclconf/go-cty/cty"
 )
 
 type (
 	doc struct {
-		Tables       []*sqlspec.Table  `spec:"table"`
-		Views        []*sqlspec.View   `spec:"view"`
-		Materialized []*sqlspec.View   `spec:"materialized"`
-		Enums        []*Enum           `spec:"enum"`
-		Funcs        []*sqlspec.Func   `spec:"function"`
-		Procs        []*sqlspec.Func   `spec:"procedure"`
-		Schemas      []*sqlspec.Schema `spec:"schema"`
+		Tables       []*sqlspec.Table        `spec:"table"`
+		Views        []*sqlspec.View         `spec:"view"`
+		Materialized []*sqlspec.View         `spec:"materialized"`
+		Enums        []*Enum                 `spec:"enum"`
+		TSDicts      []*TextSearchDictSpec   `spec:"text_search_dictionary"`
+		TSConfigs    []*TextSearchConfigSpec `spec:"text_search_config"`
+		Funcs        []*sqlspec.Func         `spec:"function"`
+		Procs        []*sqlspec.Func         `spec:"procedure"`
+		Schemas      []*sqlspec.Schema       `spec:"schema"`
 	}
 	// Enum holds a specification for an enum, that can be referenced as a column type.
 	Enum struct {
@@ -47,6 +51,8 @@ func (d *doc) merge(d1 *doc) {
 	d.Views = append(d.Views, d1.Views...)
 	d.Materialized = append(d.Materialized, d1.Materialized...)
 	d.Enums = append(d.Enums, d1.Enums...)
+	d.TSDicts = append(d.TSDicts, d1.TSDicts...)
+	d.TSConfigs = append(d.TSConfigs, d1.TSConfigs...)
 	d.Funcs = append(d.Funcs, d1.Funcs...)
 	d.Procs = append(d.Procs, d1.Procs...)
 	d.Schemas = append(d.Schemas, d1.Schemas...)
@@ -70,6 +76,11 @@ func init() {
 
 // evalSpec evaluates an Atlas DDL document into v using the input.
 func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
+	return evalSpecWith(p, v, input, scanFuncs)
+}
+
+// evalSpecWith evaluates an Atlas DDL document into v using the input and the scan functions.
+func evalSpecWith(p *hclparse.Parser, v any, input map[string]cty.Value, scanFuncs *specutil.ScanFuncs) error {
 	switch v := v.(type) {
 	case *schema.Realm:
 		var d doc
@@ -82,11 +93,17 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 		); err != nil {
 			return fmt.Errorf("specutil: failed converting to *schema.Realm: %w", err)
 		}
+		if err := convertSchemaOwners(d.Schemas, v); err != nil {
+			return err
+		}
 		if len(d.Enums) > 0 {
 			if err := convertEnums(d.Tables, d.Enums, v); err != nil {
 				return err
 			}
 		}
+		if err := convertTextSearch(&d, v); err != nil {
+			return err
+		}
 	case *schema.Schema:
 		var d doc
 		if err := hclState.Eval(p, &d, input); err != nil {
@@ -102,9 +119,15 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 		); err != nil {
 			return err
 		}
+		if err := convertSchemaOwners(d.Schemas, r); err != nil {
+			return err
+		}
 		if err := convertEnums(d.Tables, d.Enums, r); err != nil {
 			return err
 		}
+		if err := convertTextSearch(&d, r); err != nil {
+			return err
+		}
 		*v = *r.Schemas[0]
 	case schema.Schema, schema.Realm:
 		return fmt.Errorf("postgres: Eval expects a pointer: received %[1]T, expected *%[1]T", v)
@@ -114,6 +137,26 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 	return nil
 }
 
+// JSONSchema returns a JSON Schema document describing the blocks, attributes and
+// types of the JSON representation of Atlas HCL documents for this driver.
+func JSONSchema() ([]byte, error) {
+	return hclState.JSONSchema(&doc{})
+}
+
+// ValidateHCL checks the parsed Atlas HCL documents for structural errors, such as
+// duplicate definitions, broken references or invalid types, without connecting to
+// a database. All errors found are returned as diagnostics.
+func ValidateHCL(p *hclparse.Parser, input map[string]cty.Value) hcl.Diagnostics {
+	var d doc
+	if err := hclState.Eval(p, &d, input); err != nil {
+		return schemahcl.Diagnostics(err)
+	}
+	return specutil.Validate(
+		&specutil.ScanDoc{Schemas: d.Schemas, Tables: d.Tables, Views: d.Views, Materialized: d.Materialized, Funcs: d.Funcs, Procs: d.Procs},
+		scanFuncs,
+	)
+}
+
 // MarshalSpec marshals v into an Atlas DDL document using a schemahcl.Marshaler.
 func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
 	var d doc
@@ -144,6 +187,12 @@ func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
 		if err := specutil.QualifyObjects(d.Enums); err != nil {
 			return nil, err
 		}
+		if err := specutil.QualifyObjects(d.TSDicts); err != nil {
+			return nil, err
+		}
+		if err := specutil.QualifyObjects(d.TSConfigs); err != nil {
+			return nil, err
+		}
 		if err := specutil.QualifyObjects(d.Funcs); err != nil {
 			return nil, err
 		}
@@ -171,6 +220,7 @@ var (
 		schemahcl.WithScopedEnums("table.column.as.type", "STORED"),
 		schemahcl.WithScopedEnums("table.foreign_key.on_update", specutil.ReferenceVars...),
 		schemahcl.WithScopedEnums("table.foreign_key.on_delete", specutil.ReferenceVars...),
+		schemahcl.WithScopedEnums("table.foreign_key.match", MatchSimple, MatchFull, MatchPartial),
 		schemahcl.WithScopedEnums("table.index.on.ops", func() (ops []string) {
 			for _, op := range postgresop.Classes {
 				ops = append(ops, op.Name)
@@ -188,8 +238,32 @@ var (
 	// EvalHCLBytes is a helper that evaluates an HCL document from a byte slice instead
 	// of from an hclparse.Parser instance.
 	EvalHCLBytes = specutil.HCLBytesFunc(EvalHCL)
+
+	// MergeHCL is a helper that evaluates multiple parsed HCL documents and merges them
+	// into one Realm, reporting duplicate definitions across the documents as errors.
+	MergeHCL = specutil.HCLMergeFunc(EvalHCL, ValidateHCL)
+
+	// mixins holds the builtin mixins that can be attached to table blocks. PostgreSQL does
+	// not support ON UPDATE expressions, and therefore, timestamps can add only created_at.
+	mixins = map[string]specutil.MixinFunc{
+		specutil.MixinTimestamps: specutil.TimestampsMixin(&schemahcl.Type{T: TypeTimestampTZ}, "CURRENT_TIMESTAMP", ""),
+		specutil.MixinSoftDelete: specutil.SoftDeleteMixin(&schemahcl.Type{T: TypeTimestampTZ}, func(s string) string {
+			return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+		}),
+	}
 )
 
+// EvalHCLSearchPath returns an Evaluator that resolves unqualified references, such as
+// table.users, like the PostgreSQL search_path. References are resolved from the schema
+// of the referencing object first, and then from the given schemas by their order.
+func EvalHCLSearchPath(schemas ...string) schemahcl.Evaluator {
+	funcs := *scanFuncs
+	funcs.SearchPath = schemas
+	return schemahcl.EvalFunc(func(p *hclparse.Parser, v any, input map[string]cty.Value) error {
+		return evalSpecWith(p, v, input, &funcs)
+	})
+}
+
 // convertTable converts a sqlspec.Table to a schema.Table. Table conversion is done without converting
 // ForeignKeySpecs into ForeignKeys, as the target tables do not necessarily exist in the schema
 // at this point. Instead, the linking is done by the convertSchema function.
@@ -201,6 +275,15 @@ func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, er
 	if err := convertPartition(spec.Extra, t); err != nil {
 		return nil, err
 	}
+	if err := convertHypertable(spec.Extra, t); err != nil {
+		return nil, err
+	}
+	if err := convertOwner(spec.Extra, &t.Attrs); err != nil {
+		return nil, err
+	}
+	if err := convertTablets(spec.Extra, &t.Attrs); err != nil {
+		return nil, err
+	}
 	return t, nil
 }
 
@@ -223,6 +306,9 @@ func convertView(spec *sqlspec.View, parent *schema.Schema) (*schema.View, error
 	if err != nil {
 		return nil, err
 	}
+	if err := convertContinuousAggregate(spec.Extra, v); err != nil {
+		return nil, err
+	}
 	return v, nil
 }
 
@@ -336,6 +422,9 @@ func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error
 	if err := specutil.ConvertGenExpr(spec.Remain(), c, generatedType); err != nil {
 		return nil, err
 	}
+	if err := convertLabels(spec, c); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
@@ -417,6 +506,9 @@ func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
 	if err := convertIndexPK(spec, t, idx); err != nil {
 		return nil, err
 	}
+	if err := convertTablets(spec.Extra, &idx.Attrs); err != nil {
+		return nil, err
+	}
 	return idx, nil
 }
 
@@ -429,6 +521,18 @@ func convertIndexPK(spec specutil.Attrer, t *schema.Table, idx *schema.Index) er
 		}
 		idx.Attrs = append(idx.Attrs, &IndexStorageParams{PagesPerRange: p})
 	}
+	if attr, ok := spec.Attr("storage_params"); ok {
+		params, err := attr.Strings()
+		if err != nil {
+			return err
+		}
+		for _, p := range params {
+			if !strings.Contains(p, "=") {
+				return fmt.Errorf("unexpected storage parameter %q in index %q definition (expect name=value)", p, idx.Name)
+			}
+		}
+		idx.Attrs = append(idx.Attrs, &RawStorageParams{Params: params})
+	}
 	if attr, ok := spec.Attr("include"); ok {
 		refs, err := attr.Refs()
 		if err != nil {
@@ -449,6 +553,16 @@ func convertIndexPK(spec specutil.Attrer, t *schema.Table, idx *schema.Index) er
 }
 
 func convertPart(spec *sqlspec.IndexPart, part *schema.IndexPart) error {
+	if attr, ok := spec.Attr("text_search"); ok {
+		config, err := attr.String()
+		if err != nil {
+			return err
+		}
+		if part.C == nil {
+			return errors.New("attribute 'on.text_search' requires a column")
+		}
+		part.X, part.C = &schema.RawExpr{X: tsvectorExpr(config, part.C.Name)}, nil
+	}
 	switch opc, ok := spec.Attr("ops"); {
 	case !ok:
 	case opc.IsRawExpr():
@@ -596,6 +710,7 @@ func schemaSpec(s *schema.Schema) (*doc, error) {
 		Schemas:      []*sqlspec.Schema{spec.Schema},
 		Enums:        make([]*Enum, 0, len(s.Objects)),
 	}
+	fromOwner(s.Attrs, &spec.Schema.Extra)
 	for _, o := range s.Objects {
 		if e, ok := o.(*schema.EnumType); ok {
 			d.Enums = append(d.Enums, &Enum{
@@ -605,6 +720,7 @@ func schemaSpec(s *schema.Schema) (*doc, error) {
 			})
 		}
 	}
+	textSearchSpec(s, d)
 	return d, nil
 }
 
@@ -615,7 +731,7 @@ func tableSpec(table *schema.Table) (*sqlspec.Table, error) {
 		tableColumnSpec,
 		pkSpec,
 		indexSpec,
-		specutil.FromForeignKey,
+		fkSpec,
 		specutil.FromCheck,
 	)
 	if err != nil {
@@ -624,6 +740,35 @@ func tableSpec(table *schema.Table) (*sqlspec.Table, error) {
 	if p := (Partition{}); sqlx.Has(table.Attrs, &p) {
 		spec.Extra.Children = append(spec.Extra.Children, fromPartition(p))
 	}
+	if h := (&Hypertable{}); sqlx.Has(table.Attrs, h) {
+		spec.Extra.Children = append(spec.Extra.Children, fromHypertable(h))
+	}
+	fromOwner(table.Attrs, &spec.Extra)
+	fromTablets(table.Attrs, &spec.Extra)
+	return spec, nil
+}
+
+// convertFK converts the PostgreSQL-specific attributes of a sqlspec.ForeignKey.
+func convertFK(spec *sqlspec.ForeignKey, fk *schema.ForeignKey) error {
+	if attr, ok := spec.Attr("match"); ok {
+		m, err := attr.String()
+		if err != nil {
+			return err
+		}
+		fk.Attrs = append(fk.Attrs, &ForeignKeyMatch{T: strings.ToUpper(m)})
+	}
+	return nil
+}
+
+// fkSpec converts from a concrete PostgreSQL schema.ForeignKey to a sqlspec.ForeignKey.
+func fkSpec(fk *schema.ForeignKey) (*sqlspec.ForeignKey, error) {
+	spec, err := specutil.FromForeignKey(fk)
+	if err != nil {
+		return nil, err
+	}
+	if m := fkMatch(fk.Attrs); m != MatchSimple {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("match", m))
+	}
 	return spec, nil
 }
 
@@ -639,6 +784,9 @@ func viewSpec(view *schema.View) (*sqlspec.View, error) {
 	if err != nil {
 		return nil, err
 	}
+	if a := (&ContinuousAggregate{}); sqlx.Has(view.Attrs, a) {
+		spec.Extra.Children = append(spec.Extra.Children, fromContinuousAggregate(a))
+	}
 	return spec, nil
 }
 
@@ -661,12 +809,13 @@ func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("type", strings.ToUpper(i.T)))
 	}
 	if i := (IndexPredicate{}); sqlx.Has(idx.Attrs, &i) && i.P != "" {
-		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("where", strconv.Quote(i.P)))
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("where", i.P))
 	}
 	if i := (IndexNullsDistinct{}); sqlx.Has(idx.Attrs, &i) && !i.V {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("nulls_distinct", i.V))
 	}
 	spec.Extra.Attrs = indexPKSpec(idx, spec.Extra.Attrs)
+	fromTablets(idx.Attrs, &spec.Extra)
 	return spec, nil
 }
 
@@ -681,10 +830,18 @@ func indexPKSpec(idx *schema.Index, attrs []*schemahcl.Attr) []*schemahcl.Attr {
 	if p, ok := indexStorageParams(idx.Attrs); ok {
 		attrs = append(attrs, schemahcl.Int64Attr("page_per_range", p.PagesPerRange))
 	}
+	if r := (RawStorageParams{}); sqlx.Has(idx.Attrs, &r) && len(r.Params) > 0 {
+		attrs = append(attrs, schemahcl.StringsAttr("storage_params", r.Params...))
+	}
 	return attrs
 }
 
 func partAttr(idx *schema.Index, part *schema.IndexPart, spec *sqlspec.IndexPart) error {
+	// Print text search parts using their column form.
+	if config, c, ok := tsvectorPart(idx, part); ok {
+		spec.Column, spec.Expr = specutil.ColumnRef(c.Name), ""
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("text_search", config))
+	}
 	var op IndexOpClass
 	if !sqlx.Has(part.Attrs, &op) {
 		return nil
@@ -713,6 +870,7 @@ func tableColumnSpec(c *schema.Column, _ *schema.Table) (*sqlspec.Column, error)
 	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
 		s.Extra.Children = append(s.Extra.Children, specutil.FromGenExpr(x, generatedType))
 	}
+	fromLabels(c, s)
 	return s, nil
 }
 
@@ -824,6 +982,8 @@ var TypeRegistry = schemahcl.NewRegistry(
 		schemahcl.NewTypeSpec("hstore"),
 		schemahcl.NewTypeSpec("sql", schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "def", Required: true, Kind: reflect.String})),
 	),
+	// PostGIS types.
+	schemahcl.WithSpecs(geometryTypeSpecs()...),
 	// PostgreSQL internal, pseudo, and special types.
 	schemahcl.WithSpecs(func() (specs []*schemahcl.TypeSpec) {
 		for _, t := range []string{
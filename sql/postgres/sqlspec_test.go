@@ -5,13 +5,18 @@
 package postgres
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/internal/spectest"
+	"ariga.io/atlas/sql/internal/specutil"
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/stretchr/testify/require"
 )
@@ -739,7 +744,7 @@ table "logs" {
 				}
 			}
 		`), &schema.Schema{}, nil)
-		require.EqualError(t, err, `specutil: cannot convert table "logs": missing columns or expressions for logs.partition`)
+		require.EqualError(t, err, `:3,4-18: specutil: cannot convert table "logs": missing columns or expressions for logs.partition`)
 
 		err = EvalHCLBytes([]byte(`
 			schema "test" {}
@@ -753,7 +758,7 @@ table "logs" {
 				}
 			}
 		`), &schema.Schema{}, nil)
-		require.EqualError(t, err, `specutil: cannot convert table "logs": multiple definitions for logs.partition, use "columns" or "by"`)
+		require.EqualError(t, err, `:3,4-18: specutil: cannot convert table "logs": multiple definitions for logs.partition, use "columns" or "by"`)
 	})
 }
 
@@ -858,6 +863,50 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestSQLSpec_IndexPredicateRoundTrip(t *testing.T) {
+	for _, p := range []string{
+		"id <> 0",
+		`name <> 'a "b"'`,
+		"id > 0\n  AND name IS NOT NULL",
+		"name = '${name}' OR name = '%{name}'",
+	} {
+		users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text"))
+		users.AddIndexes(schema.NewIndex("idx").AddColumns(users.Columns[0]).AddAttrs(&IndexPredicate{P: p}))
+		buf, err := MarshalSpec(schema.New("test").AddTables(users), hclState)
+		require.NoError(t, err)
+		var s schema.Schema
+		require.NoError(t, EvalHCLBytes(buf, &s, nil), string(buf))
+		idx, ok := s.Tables[0].Index("idx")
+		require.True(t, ok)
+		var got IndexPredicate
+		require.True(t, sqlx.Has(idx.Attrs, &got))
+		require.Equal(t, p, got.P)
+	}
+}
+
+func TestSQLSpec_IndexExprRoundTrip(t *testing.T) {
+	for _, x := range []string{
+		"lower((name)::text)",
+		`(data ->> 'a "b"'::text)`,
+		"COALESCE(name, '${name}'::character varying)",
+	} {
+		users := schema.NewTable("users").AddColumns(schema.NewStringColumn("name", "varchar"), schema.NewJSONColumn("data", "jsonb"))
+		users.AddIndexes(schema.NewIndex("idx").AddExprs(&schema.RawExpr{X: x}).AddColumns(users.Columns[0]))
+		buf, err := MarshalSpec(schema.New("test").AddTables(users), hclState)
+		require.NoError(t, err)
+		var s schema.Schema
+		require.NoError(t, EvalHCLBytes(buf, &s, nil), string(buf))
+		idx, ok := s.Tables[0].Index("idx")
+		require.True(t, ok)
+		require.Len(t, idx.Parts, 2)
+		require.Equal(t, x, idx.Parts[0].X.(*schema.RawExpr).X)
+		require.Equal(t, "name", idx.Parts[1].C.Name)
+		changes, err := DefaultDiff.TableDiff(users, s.Tables[0])
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	}
+}
+
 func TestMarshalSpec_IndexNullsDistinct(t *testing.T) {
 	s := schema.New("public").
 		AddTables(
@@ -2024,8 +2073,55 @@ func TestParseType_Interval(t *testing.T) {
 	}
 }
 
+func TestRegisterType(t *testing.T) {
+	require.NoError(t, RegisterType(&schema.TypeDef{
+		Name: "vector",
+		Parse: func(raw string) (schema.Type, error) {
+			return &UserDefinedType{T: raw}, nil
+		},
+	}))
+	require.EqualError(t, RegisterType(&schema.TypeDef{Name: "VECTOR"}), `postgres: register type: type "VECTOR" was already registered`)
+
+	typ, err := ParseType("vector(3)")
+	require.NoError(t, err)
+	require.Equal(t, &UserDefinedType{T: "vector(3)"}, typ)
+	f, err := FormatType(typ)
+	require.NoError(t, err)
+	require.Equal(t, "vector(3)", f)
+
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "public" {}
+table "items" {
+  schema = schema.public
+  column "embedding" {
+    type = sql("vector(3)")
+  }
+}
+`), &s, nil))
+	c, ok := s.Tables[0].Column("embedding")
+	require.True(t, ok)
+	require.Equal(t, &UserDefinedType{T: "vector(3)"}, c.Type.Type)
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `type = sql("vector(3)")`)
+
+	from := schema.NewTable("items").SetSchema(schema.New("public")).
+		AddColumns(schema.NewColumn("embedding").SetType(&UserDefinedType{T: "vector(3)"}))
+	to := schema.NewTable("items").SetSchema(schema.New("public")).
+		AddColumns(schema.NewColumn("embedding").SetType(&UserDefinedType{T: "VECTOR(3)"}))
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	to.Columns[0].Type.Type = &UserDefinedType{T: "vector(4)"}
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+}
+
 func TestRegistrySanity(t *testing.T) {
-	spectest.RegistrySanityTest(t, TypeRegistry, []string{"enum"})
+	// PostGIS types are tested separately, as they require a valid subtype.
+	spectest.RegistrySanityTest(t, TypeRegistry, []string{"enum", TypeGeometry, TypeGeography})
 }
 
 func TestInputVars(t *testing.T) {
@@ -2260,3 +2356,343 @@ func TestEvalHCLError(t *testing.T) {
 		require.EqualError(t, err, tt.err)
 	}
 }
+
+func TestJSONSchema(t *testing.T) {
+	buf, err := JSONSchema()
+	require.NoError(t, err)
+	var s struct {
+		Properties map[string]struct {
+			AdditionalProperties struct {
+				Properties map[string]json.RawMessage
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(buf, &s))
+	for _, b := range []string{"schema", "table", "view", "materialized", "enum", "function", "procedure", "variable", "locals"} {
+		require.Contains(t, s.Properties, b)
+	}
+	require.Contains(t, s.Properties["table"].AdditionalProperties.Properties, "column")
+	require.Contains(t, s.Properties["enum"].AdditionalProperties.Properties, "values")
+}
+
+func TestSQLSpec_RawStorageParams(t *testing.T) {
+	f := `
+schema "s" {}
+table "t" {
+	schema = schema.s
+	column "c" {
+		type = int
+	}
+	index "i" {
+		columns = [column.c]
+		storage_params = ["fillfactor=70", "deduplicate_items=off"]
+	}
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	idx := s.Tables[0].Indexes[0]
+	require.Equal(t, []schema.Attr{&RawStorageParams{Params: []string{"fillfactor=70", "deduplicate_items=off"}}}, idx.Attrs)
+
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	require.Equal(t, idx.Attrs, s2.Tables[0].Indexes[0].Attrs)
+
+	d := &diff{conn: &conn{ExecQuerier: sqlx.NoRows}}
+	require.False(t, d.IndexAttrChanged(idx.Attrs, []schema.Attr{&RawStorageParams{Params: []string{"deduplicate_items=off", "FILLFACTOR=70"}}}))
+	require.True(t, d.IndexAttrChanged(idx.Attrs, []schema.Attr{&RawStorageParams{Params: []string{"fillfactor=80"}}}))
+
+	drv := &planApply{conn: &conn{ExecQuerier: sqlx.NoRows}}
+	plan, err := drv.PlanChanges(context.Background(), "", []schema.Change{&schema.ModifyTable{T: s.Tables[0], Changes: []schema.Change{&schema.AddIndex{I: idx}}}})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE INDEX "i" ON "s"."t" ("c") WITH (fillfactor=70, deduplicate_items=off)`, plan.Changes[0].Cmd)
+
+	f = strings.Replace(f, `"fillfactor=70"`, `"fillfactor"`, 1)
+	require.EqualError(t, EvalHCLBytes([]byte(f), &schema.Schema{}, nil), `:8,2-13: specutil: cannot convert table "t": unexpected storage parameter "fillfactor" in index "i" definition (expect name=value)`)
+}
+
+func TestValidateHCL_Idents(t *testing.T) {
+	p := hclparse.NewParser()
+	_, diags := p.ParseHCL([]byte(`
+schema "public" {}
+
+table "user" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  column "order" {
+    type = int
+  }
+  index "user_order_index_with_a_very_long_name_that_is_truncated_by_postgres" {
+    columns = [column.order]
+  }
+}
+`), "schema.hcl")
+	require.False(t, diags.HasErrors())
+	diags = ValidateHCL(p, nil)
+	require.Len(t, diags, 3)
+	// Reserved words are reported as warnings, as Atlas quotes identifiers.
+	require.Equal(t, hcl.DiagWarning, diags[0].Severity)
+	require.Equal(t, `specutil: table name "user" is a reserved word in PostgreSQL and must be quoted in queries. Consider renaming it, e.g., "users"`, diags[0].Summary)
+	require.Equal(t, 4, diags[0].Subject.Start.Line)
+	require.Equal(t, hcl.DiagWarning, diags[1].Severity)
+	require.Equal(t, `specutil: column name "order" is a reserved word in PostgreSQL and must be quoted in queries. Consider renaming it, e.g., "user_order"`, diags[1].Summary)
+	require.Equal(t, 9, diags[1].Subject.Start.Line)
+	require.Equal(t, hcl.DiagError, diags[2].Severity)
+	require.Contains(t, diags[2].Summary, `exceeds the PostgreSQL limit of 63 bytes (68 bytes)`)
+	require.Equal(t, 12, diags[2].Subject.Start.Line)
+
+	// Schemas with reserved words are loaded.
+	f, diags := hclparse.NewParser().ParseHCL([]byte(`
+schema "public" {}
+table "user" {
+  schema = schema.public
+  column "order" {
+    type = int
+  }
+}
+`), "schema.hcl")
+	require.False(t, diags.HasErrors())
+	r, err := MergeHCL([]*hcl.File{f}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "user", r.Schemas[0].Tables[0].Name)
+}
+
+func TestSQLSpec_ForeignKeyUniqueRef(t *testing.T) {
+	f := `
+schema "public" {}
+table "users" {
+	schema = schema.public
+	column "id" {
+		type = int
+	}
+	column "org" {
+		type = int
+	}
+	column "email" {
+		type = text
+	}
+	primary_key {
+		columns = [column.id]
+	}
+	index "org_email" {
+		unique  = true
+		columns = [column.org, column.email]
+	}
+}
+table "invites" {
+	schema = schema.public
+	column "org" {
+		type = int
+	}
+	column "email" {
+		type = text
+	}
+	foreign_key "invitee" {
+		columns     = [column.email, column.org]
+		ref_columns = [table.users.column.email, table.users.column.org]
+		match       = FULL
+	}
+}
+`
+	var r schema.Realm
+	require.NoError(t, EvalHCLBytes([]byte(f), &r, nil))
+	invites, ok := r.Schemas[0].Table("invites")
+	require.True(t, ok)
+	require.Len(t, invites.ForeignKeys, 1)
+	require.Equal(t, []schema.Attr{&ForeignKeyMatch{T: MatchFull}}, invites.ForeignKeys[0].Attrs)
+	buf, err := MarshalSpec(&r, hclState)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `  foreign_key "invitee" {
+    columns     = [column.email, column.org]
+    ref_columns = [table.users.column.email, table.users.column.org]
+    match       = FULL
+  }`)
+
+	// Referenced columns must form a unique key.
+	f = strings.Replace(f, "table.users.column.org]", "table.users.column.id]", 1)
+	err = EvalHCLBytes([]byte(f), &schema.Realm{}, nil)
+	require.ErrorContains(t, err, `specutil: referenced columns ("email", "id") of foreign-key "invitee" do not form a unique key in table "users"`)
+
+	// Partial unique indexes cannot be referenced.
+	f = strings.Replace(f, "table.users.column.id]", "table.users.column.org]", 1)
+	f = strings.Replace(f, "columns = [column.org, column.email]\n", "columns = [column.org, column.email]\n    where   = \"email <> ''\"\n", 1)
+	err = EvalHCLBytes([]byte(f), &schema.Realm{}, nil)
+	require.ErrorContains(t, err, `specutil: referenced columns ("email", "org") of foreign-key "invitee" do not form a unique key in table "users"`)
+}
+
+func TestSQLSpec_Mixins(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  column "email" {
+    type = text
+  }
+  column "handle" {
+    type = text
+  }
+  index "users_email" {
+    unique  = true
+    columns = [column.email]
+  }
+  index "users_handle" {
+    unique  = true
+    columns = [column.handle]
+  }
+  index "users_id" {
+    unique  = true
+    columns = [column.id]
+    where   = "id > 0"
+  }
+  mixin "timestamps" {
+    updated = ""
+  }
+  mixin "soft_delete" {
+    column = "removed_at"
+  }
+}
+table "posts" {
+  schema = schema.public
+  column "author" {
+    type = text
+  }
+  foreign_key "author" {
+    columns     = [column.author]
+    ref_columns = [table.users.column.handle]
+  }
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Len(t, users.Columns, 5)
+	_, ok = users.Column("updated_at")
+	require.False(t, ok)
+	c, ok := users.Column("created_at")
+	require.True(t, ok)
+	require.False(t, c.Type.Null)
+	require.Equal(t, TypeTimestampTZ, c.Type.Type.(*schema.TimeType).T)
+	require.Equal(t, &schema.RawExpr{X: "CURRENT_TIMESTAMP"}, c.Default)
+	c, ok = users.Column("removed_at")
+	require.True(t, ok)
+	require.True(t, c.Type.Null)
+	for name, p := range map[string]string{"users_email": `"removed_at" IS NULL`, "users_id": `(id > 0) AND "removed_at" IS NULL`} {
+		idx, ok := users.Index(name)
+		require.True(t, ok)
+		var got IndexPredicate
+		require.True(t, sqlx.Has(idx.Attrs, &got))
+		require.Equal(t, p, got.P)
+	}
+	// Unique indexes that are referenced by foreign keys are not made partial.
+	idx, ok := users.Index("users_handle")
+	require.True(t, ok)
+	require.False(t, sqlx.Has(idx.Attrs, &IndexPredicate{}))
+
+	err := EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  mixin "timestamps" {}
+}
+`), &s, nil)
+	require.ErrorContains(t, err, `specutil: mixin "timestamps" of table "users": column "updated_at" cannot be maintained on updates without ON UPDATE support, set updated = "" to add only "created_at"`)
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "created_at" {
+    type = timestamptz
+  }
+  mixin "timestamps" {
+    updated = ""
+  }
+}
+`), &s, nil)
+	require.ErrorContains(t, err, `specutil: mixin "timestamps" of table "users": column "created_at" already exists`)
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  mixin "unknown" {}
+}
+`), &s, nil)
+	require.ErrorContains(t, err, `specutil: unknown mixin "unknown" for table "users"`)
+}
+
+func TestEvalHCLSearchPath(t *testing.T) {
+	f := `
+schema "app" {}
+schema "public" {}
+table "app" "users" {
+  schema = schema.app
+  column "id" {
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = int
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+table "posts" {
+  schema = schema.public
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+table "comments" {
+  schema = schema.blog
+  column "author_id" {
+    type = int
+  }
+  foreign_key "author" {
+    columns     = [column.author_id]
+    ref_columns = [table.users.column.id]
+  }
+}
+`
+	f = strings.Replace(f, `schema "public" {}`, `schema "public" {}
+schema "blog" {}`, 1)
+	var r schema.Realm
+	err := EvalHCLBytes([]byte(f), &r, nil)
+	require.ErrorContains(t, err, `multiple refrences tables/views found for "users"`)
+
+	// References are resolved from the schema of the referencing
+	// table first, and then from the schemas in the search path.
+	for path, want := range map[string][2]string{
+		"public":         {"public", "public"},
+		"app,public":     {"public", "app"},
+		"missing,public": {"public", "public"},
+	} {
+		var r schema.Realm
+		require.NoError(t, specutil.HCLBytesFunc(EvalHCLSearchPath(strings.Split(path, ",")...))([]byte(f), &r, nil))
+		for i, n := range [][2]string{{"public", "posts"}, {"blog", "comments"}} {
+			s, ok := r.Schema(n[0])
+			require.True(t, ok)
+			t1, ok := s.Table(n[1])
+			require.True(t, ok)
+			require.Equal(t, want[i], t1.ForeignKeys[0].RefTable.Schema.Name, path)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// inspectStats collects the statistics of the inspected tables and their indexes.
+// Statistics are not supported by CockroachDB, as it does not expose the sizes of
+// its relations.
+func (i *inspect) inspectStats(ctx context.Context, r *schema.Realm) error {
+	if i.crdb {
+		return nil
+	}
+	for _, s := range r.Schemas {
+		if len(s.Tables) == 0 {
+			continue
+		}
+		if err := i.tableStats(ctx, s); err != nil {
+			return err
+		}
+		if err := i.indexStats(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableStats attaches the TableStats attribute to the schema tables.
+func (i *inspect) tableStats(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, tableStatsQuery, s)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q table statistics: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name  string
+			stats schema.TableStats
+		)
+		if err := rows.Scan(&name, &stats.Rows, &stats.DataSize, &stats.IndexSize); err != nil {
+			return fmt.Errorf("postgres: scanning table statistics: %w", err)
+		}
+		t, ok := s.Table(name)
+		if !ok {
+			return fmt.Errorf("table %q was not found in schema", name)
+		}
+		t.Attrs = append(t.Attrs, &stats)
+	}
+	return rows.Err()
+}
+
+// indexStats attaches the IndexStats attribute to the table indexes.
+func (i *inspect) indexStats(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, indexStatsQuery, s)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q index statistics: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			table, name, method                   string
+			size, pages, tuples, width, blockSize int64
+		)
+		if err := rows.Scan(&table, &name, &method, &size, &pages, &tuples, &width, &blockSize); err != nil {
+			return fmt.Errorf("postgres: scanning index statistics: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("table %q was not found in schema", table)
+		}
+		idx, ok := t.Index(name)
+		if !ok && t.PrimaryKey != nil && t.PrimaryKey.Name == name {
+			idx, ok = t.PrimaryKey, true
+		}
+		// Skip indexes that were not inspected.
+		if !ok {
+			continue
+		}
+		stats := &schema.IndexStats{Size: size}
+		// Bloat is estimated only for B-tree indexes, as the
+		// layout of other access methods cannot be estimated.
+		if strings.ToUpper(method) == IndexTypeBTree {
+			stats.Bloat = indexBloat(pages, tuples, width, blockSize)
+		}
+		idx.Attrs = append(idx.Attrs, stats)
+	}
+	return rows.Err()
+}
+
+// indexBloat estimates the ratio of unused space in a B-tree index, by comparing
+// its number of pages with the number of pages required to store its tuples.
+func indexBloat(pages, tuples, width, blockSize int64) float64 {
+	if pages <= 1 || blockSize <= 0 {
+		return 0
+	}
+	// Each index tuple holds an 8-byte header and a 4-byte line pointer. Leaf
+	// pages are filled up to 90% (the default fillfactor) of their space that
+	// remains after the 24-byte page header and the 16-byte special space.
+	var (
+		perPage  = float64(blockSize-40) * 0.9 / float64(width+12)
+		expected = math.Ceil(float64(tuples)/perPage) + 1 // Including the meta page.
+	)
+	if expected >= float64(pages) {
+		return 0
+	}
+	return math.Round((1-expected/float64(pages))*100) / 100
+}
+
+const (
+	// Query to list table statistics. Row estimations are negative (-1)
+	// for tables that were not analyzed yet, and are reported as zero.
+	tableStatsQuery = `
+SELECT
+	t.relname AS table_name,
+	GREATEST(t.reltuples, 0)::bigint AS rows,
+	pg_table_size(t.oid) AS data_size,
+	pg_indexes_size(t.oid) AS index_size
+FROM
+	pg_catalog.pg_class t
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+WHERE
+	t.relkind IN ('r', 'p')
+	AND n.nspname = $1
+	AND t.relname IN (%s)
+ORDER BY
+	t.relname
+`
+
+	// Query to list index statistics, including the estimated width
+	// of their keys, as collected by the statistics collector.
+	indexStatsQuery = `
+SELECT
+	t.relname AS table_name,
+	c.relname AS index_name,
+	am.amname AS index_type,
+	pg_relation_size(c.oid) AS size,
+	c.relpages AS pages,
+	GREATEST(c.reltuples, 0)::bigint AS tuples,
+	COALESCE((
+		SELECT SUM(s.avg_width)
+		FROM pg_catalog.pg_attribute a
+		JOIN pg_catalog.pg_stats s ON s.schemaname = n.nspname AND s.tablename = t.relname AND s.attname = a.attname
+		WHERE a.attrelid = t.oid AND a.attnum = ANY(i.indkey)
+	), 0)::bigint AS key_width,
+	current_setting('block_size')::bigint AS block_size
+FROM
+	pg_catalog.pg_index i
+	JOIN pg_catalog.pg_class c ON c.oid = i.indexrelid
+	JOIN pg_catalog.pg_class t ON t.oid = i.indrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+	JOIN pg_catalog.pg_am am ON am.oid = c.relam
+WHERE
+	n.nspname = $1
+	AND t.relname IN (%s)
+ORDER BY
+	t.relname, c.relname
+`
+)
@@ -0,0 +1,61 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect_Stats(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		users = schema.NewTable("users").
+			AddColumns(id).
+			SetPrimaryKey(schema.NewPrimaryKey(id).SetName("users_pkey"))
+		name = schema.NewIndex("users_name").AddColumns(schema.NewStringColumn("name", "text"))
+	)
+	users.AddIndexes(name)
+	r := schema.NewRealm(schema.New("public").AddTables(users))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tableStatsQuery, "$2"))).
+		WithArgs("public", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "rows", "data_size", "index_size"}).
+			AddRow("users", 1000, 65536, 40960))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexStatsQuery, "$2"))).
+		WithArgs("public", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "index_type", "size", "pages", "tuples", "key_width", "block_size"}).
+			AddRow("users", "users_name", "btree", 16384, 2, 1000, 4, 8192).
+			AddRow("users", "users_pkey", "btree", 65536, 8, 1000, 4, 8192).
+			AddRow("users", "users_unknown", "hash", 8192, 1, 1000, 4, 8192))
+	i := &inspect{conn: &conn{ExecQuerier: db}}
+	require.NoError(t, i.inspectStats(context.Background(), r))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, []schema.Attr{&schema.TableStats{Rows: 1000, DataSize: 65536, IndexSize: 40960}}, users.Attrs)
+	require.Equal(t, []schema.Attr{&schema.IndexStats{Size: 16384}}, name.Attrs)
+	require.Equal(t, []schema.Attr{&schema.IndexStats{Size: 65536, Bloat: 0.5}}, users.PrimaryKey.Attrs)
+
+	// Statistics are not collected on CockroachDB.
+	i = &inspect{conn: &conn{ExecQuerier: db, crdb: true}}
+	require.NoError(t, i.inspectStats(context.Background(), r))
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestIndexBloat(t *testing.T) {
+	// 1000 tuples of 16 bytes fit in 4 pages (including the meta page).
+	require.Zero(t, indexBloat(4, 1000, 4, 8192))
+	require.Equal(t, 0.6, indexBloat(10, 1000, 4, 8192))
+	require.Equal(t, 0.96, indexBloat(100, 1000, 4, 8192))
+	require.Zero(t, indexBloat(1, 0, 4, 8192))
+	require.Zero(t, indexBloat(10, 1000, 4, 0))
+}
@@ -0,0 +1,703 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/specutil"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// TextSearchConfig describes a text search configuration. For example:
+	//
+	//	CREATE TEXT SEARCH CONFIGURATION english_simple (PARSER = default);
+	//	ALTER TEXT SEARCH CONFIGURATION english_simple ADD MAPPING FOR asciiword WITH english_stem;
+	//
+	TextSearchConfig struct {
+		schema.Object
+		Name   string
+		Schema *schema.Schema
+		// Parser is the name of the parser, e.g. "default".
+		Parser string
+		// Mappings of token types to dictionaries.
+		Mappings []*TextSearchMapping
+		Attrs    []schema.Attr
+	}
+
+	// TextSearchMapping maps token types to the dictionaries that are consulted for them, in order.
+	TextSearchMapping struct {
+		Tokens []string
+		Dicts  []string
+	}
+
+	// TextSearchDict describes a text search dictionary. For example:
+	//
+	//	CREATE TEXT SEARCH DICTIONARY english_stem (TEMPLATE = snowball, language = english);
+	//
+	TextSearchDict struct {
+		schema.Object
+		Name   string
+		Schema *schema.Schema
+		// Template is the name of the template, e.g. "snowball".
+		Template string
+		// Options holds the template options, e.g. language or stopwords.
+		Options []*TextSearchOption
+		Attrs   []schema.Attr
+	}
+
+	// TextSearchOption describes a text search dictionary option.
+	TextSearchOption struct {
+		Name, Value string
+	}
+)
+
+// tokens returns the dictionaries of each token in the configuration.
+func (c *TextSearchConfig) tokens() map[string][]string {
+	m := make(map[string][]string)
+	for _, mp := range c.Mappings {
+		for _, t := range mp.Tokens {
+			m[t] = mp.Dicts
+		}
+	}
+	return m
+}
+
+// options returns the options of the dictionary as a map.
+func (d *TextSearchDict) options() map[string]string {
+	m := make(map[string]string, len(d.Options))
+	for _, o := range d.Options {
+		m[strings.ToLower(o.Name)] = o.Value
+	}
+	return m
+}
+
+// inspectTextSearch inspects the text search dictionaries and configurations of the realm.
+func (i *inspect) inspectTextSearch(ctx context.Context, r *schema.Realm) error {
+	// CockroachDB does not support text search configurations and dictionaries.
+	if i.crdb || len(r.Schemas) == 0 {
+		return nil
+	}
+	args := make([]any, len(r.Schemas))
+	for j, s := range r.Schemas {
+		args[j] = s.Name
+	}
+	if err := i.textSearchDicts(ctx, r, args); err != nil {
+		return err
+	}
+	return i.textSearchConfigs(ctx, r, args)
+}
+
+// textSearchDicts adds the text search dictionaries of the realm.
+func (i *inspect) textSearchDicts(ctx context.Context, r *schema.Realm, args []any) error {
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(tsDictsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying text search dictionaries: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ns, name, tmpl, opts, comment string
+		if err := rows.Scan(&ns, &name, &tmpl, &opts, &comment); err != nil {
+			return fmt.Errorf("postgres: scanning text search dictionary: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q for text search dictionary %q was not found in inspection", ns, name)
+		}
+		d := &TextSearchDict{Name: name, Schema: s, Template: tmpl, Options: parseTextSearchOptions(opts)}
+		if comment != "" {
+			d.Attrs = append(d.Attrs, &schema.Comment{Text: comment})
+		}
+		s.Objects = append(s.Objects, d)
+	}
+	return rows.Err()
+}
+
+// textSearchConfigs adds the text search configurations of the realm.
+func (i *inspect) textSearchConfigs(ctx context.Context, r *schema.Realm, args []any) error {
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(tsConfigsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying text search configurations: %w", err)
+	}
+	defer rows.Close()
+	configs := make(map[[2]string]*TextSearchConfig)
+	for rows.Next() {
+		var ns, name, parser, comment, token, dicts string
+		if err := rows.Scan(&ns, &name, &parser, &comment, &token, &dicts); err != nil {
+			return fmt.Errorf("postgres: scanning text search configuration: %w", err)
+		}
+		c, ok := configs[[2]string{ns, name}]
+		if !ok {
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q for text search configuration %q was not found in inspection", ns, name)
+			}
+			c = &TextSearchConfig{Name: name, Schema: s, Parser: parser}
+			if comment != "" {
+				c.Attrs = append(c.Attrs, &schema.Comment{Text: comment})
+			}
+			configs[[2]string{ns, name}] = c
+			s.Objects = append(s.Objects, c)
+		}
+		if token == "" {
+			continue
+		}
+		// Group consecutive tokens that are mapped to the same dictionaries.
+		ds := strings.Split(dicts, ",")
+		if n := len(c.Mappings); n > 0 && sqlx.ValuesEqual(c.Mappings[n-1].Dicts, ds) {
+			c.Mappings[n-1].Tokens = append(c.Mappings[n-1].Tokens, token)
+		} else {
+			c.Mappings = append(c.Mappings, &TextSearchMapping{Tokens: []string{token}, Dicts: ds})
+		}
+	}
+	return rows.Err()
+}
+
+// parseTextSearchOptions parses the dictionary options as stored in pg_ts_dict.dictinitoption.
+// For example: "language = 'english', stopwords = 'english'".
+func parseTextSearchOptions(s string) []*TextSearchOption {
+	var (
+		opts []*TextSearchOption
+		part strings.Builder
+		in   bool
+	)
+	add := func() {
+		if k, v, ok := strings.Cut(part.String(), "="); ok {
+			v = strings.TrimSpace(v)
+			if sqlx.IsQuoted(v, '\'') {
+				v = strings.ReplaceAll(v[1:len(v)-1], "''", "'")
+			}
+			opts = append(opts, &TextSearchOption{Name: strings.TrimSpace(k), Value: v})
+		}
+		part.Reset()
+	}
+	for _, r := range s {
+		switch {
+		case r == '\'':
+			in = !in
+		case r == ',' && !in:
+			add()
+			continue
+		}
+		part.WriteRune(r)
+	}
+	add()
+	return opts
+}
+
+// tsvectorExpr returns the expression of a key part that indexes the
+// text search document of the given column. For example:
+//
+//	to_tsvector('english'::regconfig, body)
+func tsvectorExpr(config, column string) string {
+	if !reSimpleIdent.MatchString(column) {
+		column = `"` + strings.ReplaceAll(column, `"`, `""`) + `"`
+	}
+	return fmt.Sprintf("to_tsvector(%s::regconfig, %s)", quote(config), column)
+}
+
+var (
+	// reSimpleIdent matches identifiers that are not quoted by Postgres.
+	reSimpleIdent = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	// reTSVector matches key parts created by tsvectorExpr.
+	reTSVector = regexp.MustCompile(`^to_tsvector\('((?:[^']|'')+)'::regconfig, ("(?:[^"]|"")+"|[a-z0-9_]+)\)$`)
+)
+
+// tsvectorPart reports if the key part indexes the text search document of
+// a table column, and returns its text search configuration and column.
+func tsvectorPart(idx *schema.Index, part *schema.IndexPart) (string, *schema.Column, bool) {
+	x, ok := part.X.(*schema.RawExpr)
+	if !ok || idx.Table == nil {
+		return "", nil, false
+	}
+	m := reTSVector.FindStringSubmatch(x.X)
+	if len(m) != 3 {
+		return "", nil, false
+	}
+	config, name := strings.ReplaceAll(m[1], "''", "'"), m[2]
+	if strings.HasPrefix(name, `"`) {
+		name = strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	}
+	c, ok := idx.Table.Column(name)
+	if !ok {
+		return "", nil, false
+	}
+	return config, c, true
+}
+
+// isTextSearch reports if the object is a text search object.
+func isTextSearch(o schema.Object) bool {
+	switch o.(type) {
+	case *TextSearchDict, *TextSearchConfig:
+		return true
+	}
+	return false
+}
+
+// textSearchDiff returns the changes for migrating the text search objects of the schema.
+// Dictionaries are created before the configurations that use them, and dropped after.
+func textSearchDiff(from, to *schema.Schema) ([]schema.Change, error) {
+	var adds, drops, modifies []schema.Change
+	for _, o1 := range from.Objects {
+		switch o1 := o1.(type) {
+		case *TextSearchDict:
+			o2, ok := to.Object(func(o schema.Object) bool {
+				d, ok := o.(*TextSearchDict)
+				return ok && d.Name == o1.Name
+			})
+			if !ok {
+				drops = append(drops, &schema.DropObject{O: o1})
+				continue
+			}
+			if d2 := o2.(*TextSearchDict); d2.Template != o1.Template {
+				return nil, fmt.Errorf("changing the template of text search dictionary %q is not supported (drop and add is required)", o1.Name)
+			} else if !mapsEqual(o1.options(), d2.options()) || sqlx.CommentDiff(o1.Attrs, d2.Attrs) != nil {
+				modifies = append(modifies, &schema.ModifyObject{From: o1, To: d2})
+			}
+		case *TextSearchConfig:
+			o2, ok := to.Object(func(o schema.Object) bool {
+				c, ok := o.(*TextSearchConfig)
+				return ok && c.Name == o1.Name
+			})
+			if !ok {
+				drops = append([]schema.Change{&schema.DropObject{O: o1}}, drops...)
+				continue
+			}
+			if c2 := o2.(*TextSearchConfig); c2.Parser != o1.Parser {
+				return nil, fmt.Errorf("changing the parser of text search configuration %q is not supported (drop and add is required)", o1.Name)
+			} else if !mappingsEqual(o1.tokens(), c2.tokens()) || sqlx.CommentDiff(o1.Attrs, c2.Attrs) != nil {
+				modifies = append(modifies, &schema.ModifyObject{From: o1, To: c2})
+			}
+		}
+	}
+	for _, o1 := range to.Objects {
+		switch o1 := o1.(type) {
+		case *TextSearchDict:
+			if _, ok := from.Object(func(o schema.Object) bool {
+				d, ok := o.(*TextSearchDict)
+				return ok && d.Name == o1.Name
+			}); !ok {
+				adds = append([]schema.Change{&schema.AddObject{O: o1}}, adds...)
+			}
+		case *TextSearchConfig:
+			if _, ok := from.Object(func(o schema.Object) bool {
+				c, ok := o.(*TextSearchConfig)
+				return ok && c.Name == o1.Name
+			}); !ok {
+				adds = append(adds, &schema.AddObject{O: o1})
+			}
+		}
+	}
+	return append(append(drops, modifies...), adds...), nil
+}
+
+// addTextSearch returns the statements for creating the given text search object.
+func (s *state) addTextSearch(o schema.Object) ([]*migrate.Change, error) {
+	switch o := o.(type) {
+	case *TextSearchDict:
+		b := s.Build("CREATE TEXT SEARCH DICTIONARY").P(s.objectIdent(o.Schema, o.Name)).Wrap(func(b *sqlx.Builder) {
+			b.P("TEMPLATE =", o.Template)
+			for _, opt := range o.Options {
+				b.Comma().P(opt.Name, "=", quote(opt.Value))
+			}
+		})
+		changes := []*migrate.Change{{
+			Cmd:     b.String(),
+			Comment: fmt.Sprintf("create text search dictionary %q", o.Name),
+			Reverse: s.Build("DROP TEXT SEARCH DICTIONARY").P(s.objectIdent(o.Schema, o.Name)).String(),
+		}}
+		if c := (schema.Comment{}); sqlx.Has(o.Attrs, &c) {
+			changes = append(changes, s.textSearchComment("DICTIONARY", o.Schema, o.Name, c.Text, ""))
+		}
+		return changes, nil
+	case *TextSearchConfig:
+		name := s.objectIdent(o.Schema, o.Name)
+		changes := []*migrate.Change{{
+			Cmd:     s.Build("CREATE TEXT SEARCH CONFIGURATION").P(name).Wrap(func(b *sqlx.Builder) { b.P("PARSER =", o.Parser) }).String(),
+			Comment: fmt.Sprintf("create text search configuration %q", o.Name),
+			Reverse: s.Build("DROP TEXT SEARCH CONFIGURATION").P(name).String(),
+		}}
+		for _, m := range o.Mappings {
+			changes = append(changes, s.textSearchMapping(name, "ADD", m.Tokens, m.Dicts))
+		}
+		if c := (schema.Comment{}); sqlx.Has(o.Attrs, &c) {
+			changes = append(changes, s.textSearchComment("CONFIGURATION", o.Schema, o.Name, c.Text, ""))
+		}
+		return changes, nil
+	default:
+		return nil, fmt.Errorf("unsupported object %T", o)
+	}
+}
+
+// dropTextSearch returns the statement for dropping the given text search object.
+func (s *state) dropTextSearch(o schema.Object) (*migrate.Change, error) {
+	rs := &state{conn: s.conn, PlanOptions: s.PlanOptions}
+	create, err := rs.addTextSearch(o)
+	if err != nil {
+		return nil, err
+	}
+	reverse := make([]string, len(create))
+	for i, c := range create {
+		reverse[i] = c.Cmd
+	}
+	c := &migrate.Change{Cmd: create[0].Reverse.(string), Reverse: reverse}
+	switch o := o.(type) {
+	case *TextSearchDict:
+		c.Comment = fmt.Sprintf("drop text search dictionary %q", o.Name)
+	case *TextSearchConfig:
+		c.Comment = fmt.Sprintf("drop text search configuration %q", o.Name)
+	}
+	return c, nil
+}
+
+// modifyTextSearch returns the statements for modifying the given text search object.
+func (s *state) modifyTextSearch(m *schema.ModifyObject) ([]*migrate.Change, error) {
+	var changes []*migrate.Change
+	switch from := m.From.(type) {
+	case *TextSearchDict:
+		to := m.To.(*TextSearchDict)
+		fromO, toO := from.options(), to.options()
+		if !mapsEqual(fromO, toO) {
+			b := s.Build("ALTER TEXT SEARCH DICTIONARY").P(s.objectIdent(to.Schema, to.Name))
+			r := b.Clone()
+			b.Wrap(func(b *sqlx.Builder) { textSearchOptionsDiff(b, fromO, toO) })
+			r.Wrap(func(b *sqlx.Builder) { textSearchOptionsDiff(b, toO, fromO) })
+			changes = append(changes, &migrate.Change{
+				Cmd:     b.String(),
+				Comment: fmt.Sprintf("modify text search dictionary %q", to.Name),
+				Reverse: r.String(),
+			})
+		}
+		if c := sqlx.CommentDiff(from.Attrs, to.Attrs); c != nil {
+			fromC, toC, err := commentChange(c)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, s.textSearchComment("DICTIONARY", to.Schema, to.Name, toC, fromC))
+		}
+	case *TextSearchConfig:
+		to := m.To.(*TextSearchConfig)
+		name := s.objectIdent(to.Schema, to.Name)
+		fromT, toT := from.tokens(), to.tokens()
+		for _, t := range sortedKeys(fromT) {
+			switch d, ok := toT[t]; {
+			case !ok:
+				changes = append(changes, s.textSearchMapping(name, "DROP", []string{t}, fromT[t]))
+			case !sqlx.ValuesEqual(d, fromT[t]):
+				changes = append(changes, &migrate.Change{
+					Cmd:     s.Build("ALTER TEXT SEARCH CONFIGURATION").P(name, "ALTER MAPPING FOR", t, "WITH", strings.Join(d, ", ")).String(),
+					Comment: fmt.Sprintf("modify mapping of text search configuration %q", to.Name),
+					Reverse: s.Build("ALTER TEXT SEARCH CONFIGURATION").P(name, "ALTER MAPPING FOR", t, "WITH", strings.Join(fromT[t], ", ")).String(),
+				})
+			}
+		}
+		for _, t := range sortedKeys(toT) {
+			if _, ok := fromT[t]; !ok {
+				changes = append(changes, s.textSearchMapping(name, "ADD", []string{t}, toT[t]))
+			}
+		}
+		if c := sqlx.CommentDiff(from.Attrs, to.Attrs); c != nil {
+			fromC, toC, err := commentChange(c)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, s.textSearchComment("CONFIGURATION", to.Schema, to.Name, toC, fromC))
+		}
+	default:
+		return nil, fmt.Errorf("altering objects (%T) to (%T) is not supported", m.From, m.To)
+	}
+	return changes, nil
+}
+
+// textSearchMapping returns the statement for adding or dropping a mapping of a text search configuration.
+func (s *state) textSearchMapping(name, op string, tokens, dicts []string) *migrate.Change {
+	add := s.Build("ALTER TEXT SEARCH CONFIGURATION").P(name, "ADD MAPPING FOR", strings.Join(tokens, ", "), "WITH", strings.Join(dicts, ", ")).String()
+	drop := s.Build("ALTER TEXT SEARCH CONFIGURATION").P(name, "DROP MAPPING FOR", strings.Join(tokens, ", ")).String()
+	if op == "DROP" {
+		return &migrate.Change{Cmd: drop, Reverse: add, Comment: fmt.Sprintf("drop mapping from text search configuration %s", name)}
+	}
+	return &migrate.Change{Cmd: add, Reverse: drop, Comment: fmt.Sprintf("add mapping to text search configuration %s", name)}
+}
+
+// textSearchComment returns the statement for setting the comment of a text search object.
+func (s *state) textSearchComment(kind string, ns *schema.Schema, name, to, from string) *migrate.Change {
+	b := s.Build("COMMENT ON TEXT SEARCH", kind).P(s.objectIdent(ns, name), "IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Comment: fmt.Sprintf("set comment to text search %s: %q", strings.ToLower(kind), name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+// objectIdent returns the qualified identifier of a schema object.
+func (s *state) objectIdent(ns *schema.Schema, name string) string {
+	switch {
+	// In case the plan uses a specific schema qualifier.
+	case s.SchemaQualifier != nil:
+		if *s.SchemaQualifier != "" {
+			return fmt.Sprintf("%q.%q", *s.SchemaQualifier, name)
+		}
+	case ns != nil && ns.Name != "":
+		return fmt.Sprintf("%q.%q", ns.Name, name)
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// textSearchOptionsDiff writes the options that were changed between the two option sets.
+// Removed options are reset to their default by setting them without a value.
+func textSearchOptionsDiff(b *sqlx.Builder, from, to map[string]string) {
+	var opts []string
+	for _, k := range sortedKeys(to) {
+		if v, ok := from[k]; !ok || v != to[k] {
+			opts = append(opts, k+" = "+quote(to[k]))
+		}
+	}
+	for _, k := range sortedKeys(from) {
+		if _, ok := to[k]; !ok {
+			opts = append(opts, k)
+		}
+	}
+	b.P(strings.Join(opts, ", "))
+}
+
+func mapsEqual(m1, m2 map[string]string) bool {
+	if len(m1) != len(m2) {
+		return false
+	}
+	for k, v := range m1 {
+		if v2, ok := m2[k]; !ok || v != v2 {
+			return false
+		}
+	}
+	return true
+}
+
+func mappingsEqual(m1, m2 map[string][]string) bool {
+	if len(m1) != len(m2) {
+		return false
+	}
+	for k, v := range m1 {
+		if v2, ok := m2[k]; !ok || !sqlx.ValuesEqual(v, v2) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type (
+	// TextSearchConfigSpec holds a specification for a text search configuration.
+	TextSearchConfigSpec struct {
+		Name      string           `spec:",name"`
+		Qualifier string           `spec:",qualifier"`
+		Schema    *schemahcl.Ref   `spec:"schema"`
+		Parser    string           `spec:"parser"`
+		Comment   *string          `spec:"comment"`
+		Mappings  []*tsMappingSpec `spec:"mapping"`
+		schemahcl.DefaultExtension
+	}
+
+	// TextSearchDictSpec holds a specification for a text search dictionary.
+	TextSearchDictSpec struct {
+		Name      string          `spec:",name"`
+		Qualifier string          `spec:",qualifier"`
+		Schema    *schemahcl.Ref  `spec:"schema"`
+		Template  string          `spec:"template"`
+		Comment   *string         `spec:"comment"`
+		Options   []*tsOptionSpec `spec:"option"`
+		schemahcl.DefaultExtension
+	}
+
+	tsMappingSpec struct {
+		Tokens []string `spec:"tokens"`
+		Dicts  []string `spec:"dictionaries"`
+	}
+
+	tsOptionSpec struct {
+		Name  string `spec:",name"`
+		Value string `spec:"value"`
+	}
+)
+
+// Label returns the defaults label used for the resource.
+func (c *TextSearchConfigSpec) Label() string { return c.Name }
+
+// QualifierLabel returns the qualifier label used for the resource, if any.
+func (c *TextSearchConfigSpec) QualifierLabel() string { return c.Qualifier }
+
+// SetQualifier sets the qualifier label used for the resource.
+func (c *TextSearchConfigSpec) SetQualifier(q string) { c.Qualifier = q }
+
+// SchemaRef returns the schema reference for the resource.
+func (c *TextSearchConfigSpec) SchemaRef() *schemahcl.Ref { return c.Schema }
+
+// Label returns the defaults label used for the resource.
+func (d *TextSearchDictSpec) Label() string { return d.Name }
+
+// QualifierLabel returns the qualifier label used for the resource, if any.
+func (d *TextSearchDictSpec) QualifierLabel() string { return d.Qualifier }
+
+// SetQualifier sets the qualifier label used for the resource.
+func (d *TextSearchDictSpec) SetQualifier(q string) { d.Qualifier = q }
+
+// SchemaRef returns the schema reference for the resource.
+func (d *TextSearchDictSpec) SchemaRef() *schemahcl.Ref { return d.Schema }
+
+func init() {
+	schemahcl.Register("text_search_config", &TextSearchConfigSpec{})
+	schemahcl.Register("text_search_dictionary", &TextSearchDictSpec{})
+}
+
+// convertTextSearch converts the text search specs and appends them to the schemas of the realm.
+func convertTextSearch(d *doc, r *schema.Realm) error {
+	objectSchema := func(ref *schemahcl.Ref, kind, name string) (*schema.Schema, error) {
+		ns, err := specutil.SchemaName(ref)
+		if err != nil {
+			return nil, fmt.Errorf("extract schema name from %s reference: %w", kind, err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return nil, fmt.Errorf("schema %q defined on %s %q was not found in realm", ns, kind, name)
+		}
+		return s, nil
+	}
+	for _, spec := range d.TSDicts {
+		s, err := objectSchema(spec.Schema, "text_search_dictionary", spec.Name)
+		if err != nil {
+			return err
+		}
+		if spec.Template == "" {
+			return fmt.Errorf("missing attribute text_search_dictionary.%s.template", spec.Name)
+		}
+		o := &TextSearchDict{Name: spec.Name, Schema: s, Template: spec.Template}
+		for _, opt := range spec.Options {
+			o.Options = append(o.Options, &TextSearchOption{Name: opt.Name, Value: opt.Value})
+		}
+		if spec.Comment != nil {
+			o.Attrs = append(o.Attrs, &schema.Comment{Text: *spec.Comment})
+		}
+		s.Objects = append(s.Objects, o)
+	}
+	for _, spec := range d.TSConfigs {
+		s, err := objectSchema(spec.Schema, "text_search_config", spec.Name)
+		if err != nil {
+			return err
+		}
+		if spec.Parser == "" {
+			return fmt.Errorf("missing attribute text_search_config.%s.parser", spec.Name)
+		}
+		o := &TextSearchConfig{Name: spec.Name, Schema: s, Parser: spec.Parser}
+		for _, m := range spec.Mappings {
+			if len(m.Tokens) == 0 || len(m.Dicts) == 0 {
+				return fmt.Errorf("missing tokens or dictionaries for text_search_config.%s.mapping", spec.Name)
+			}
+			o.Mappings = append(o.Mappings, &TextSearchMapping{Tokens: m.Tokens, Dicts: m.Dicts})
+		}
+		if spec.Comment != nil {
+			o.Attrs = append(o.Attrs, &schema.Comment{Text: *spec.Comment})
+		}
+		s.Objects = append(s.Objects, o)
+	}
+	return nil
+}
+
+// textSearchSpec converts the text search objects of the schema to their specs.
+func textSearchSpec(s *schema.Schema, d *doc) {
+	for _, o := range s.Objects {
+		var comment *string
+		switch o := o.(type) {
+		case *TextSearchDict:
+			if c := (schema.Comment{}); sqlx.Has(o.Attrs, &c) {
+				comment = &c.Text
+			}
+			spec := &TextSearchDictSpec{Name: o.Name, Schema: specutil.SchemaRef(s.Name), Template: o.Template, Comment: comment}
+			for _, opt := range o.Options {
+				spec.Options = append(spec.Options, &tsOptionSpec{Name: opt.Name, Value: opt.Value})
+			}
+			d.TSDicts = append(d.TSDicts, spec)
+		case *TextSearchConfig:
+			if c := (schema.Comment{}); sqlx.Has(o.Attrs, &c) {
+				comment = &c.Text
+			}
+			spec := &TextSearchConfigSpec{Name: o.Name, Schema: specutil.SchemaRef(s.Name), Parser: o.Parser, Comment: comment}
+			for _, m := range o.Mappings {
+				spec.Mappings = append(spec.Mappings, &tsMappingSpec{Tokens: m.Tokens, Dicts: m.Dicts})
+			}
+			d.TSConfigs = append(d.TSConfigs, spec)
+		}
+	}
+}
+
+const (
+	// Query to list the text search dictionaries, excluding the builtin ones.
+	tsDictsQuery = `
+SELECT
+	n.nspname AS schema_name,
+	d.dictname AS dict_name,
+	CASE WHEN tn.nspname = 'pg_catalog' THEN t.tmplname ELSE format('%%I.%%I', tn.nspname, t.tmplname) END AS template,
+	COALESCE(d.dictinitoption, '') AS options,
+	COALESCE(obj_description(d.oid, 'pg_ts_dict'), '') AS comment
+FROM
+	pg_catalog.pg_ts_dict d
+	JOIN pg_catalog.pg_namespace n ON n.oid = d.dictnamespace
+	JOIN pg_catalog.pg_ts_template t ON t.oid = d.dicttemplate
+	JOIN pg_catalog.pg_namespace tn ON tn.oid = t.tmplnamespace
+WHERE
+	n.nspname IN (%s)
+ORDER BY
+	n.nspname, d.dictname
+`
+
+	// Query to list the text search configurations and their mappings, ordered by token type.
+	tsConfigsQuery = `
+SELECT
+	n.nspname AS schema_name,
+	c.cfgname AS config_name,
+	CASE WHEN pn.nspname = 'pg_catalog' THEN p.prsname ELSE format('%%I.%%I', pn.nspname, p.prsname) END AS parser,
+	COALESCE(obj_description(c.oid, 'pg_ts_config'), '') AS comment,
+	COALESCE(m.token, '') AS token,
+	COALESCE(m.dicts, '') AS dicts
+FROM
+	pg_catalog.pg_ts_config c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.cfgnamespace
+	JOIN pg_catalog.pg_ts_parser p ON p.oid = c.cfgparser
+	JOIN pg_catalog.pg_namespace pn ON pn.oid = p.prsnamespace
+	LEFT JOIN LATERAL (
+		SELECT
+			t.alias AS token,
+			t.tokid,
+			string_agg(CASE WHEN dn.nspname = 'pg_catalog' THEN d.dictname ELSE format('%%I.%%I', dn.nspname, d.dictname) END, ',' ORDER BY cm.mapseqno) AS dicts
+		FROM
+			pg_catalog.pg_ts_config_map cm
+			JOIN pg_catalog.ts_token_type(c.cfgparser) t ON t.tokid = cm.maptokentype
+			JOIN pg_catalog.pg_ts_dict d ON d.oid = cm.mapdict
+			JOIN pg_catalog.pg_namespace dn ON dn.oid = d.dictnamespace
+		WHERE
+			cm.mapcfg = c.oid
+		GROUP BY
+			t.alias, t.tokid
+	) m ON true
+WHERE
+	n.nspname IN (%s)
+ORDER BY
+	n.nspname, c.cfgname, m.tokid
+`
+)
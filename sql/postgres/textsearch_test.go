@@ -0,0 +1,273 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectTextSearch(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("150000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "comment", "owner"}).AddRow("public", nil, nil))
+	mk.noEnums()
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(tsDictsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | dict_name    | template | options                                          | comment
+-------------+--------------+----------+--------------------------------------------------+---------
+ public      | english_stem | snowball | language = 'english', stopwords = 'en,''custom''' | stemmer
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(tsConfigsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "config_name", "parser", "comment", "token", "dicts"}).
+			AddRow("public", "english2", "default", "", "asciiword", "english_stem").
+			AddRow("public", "english2", "default", "", "word", "english_stem").
+			AddRow("public", "english2", "default", "", "numword", "simple,english_stem").
+			AddRow("public", "empty", "default", "", "", ""))
+	s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{
+		Mode: schema.InspectSchemas | schema.InspectObjects,
+	})
+	require.NoError(t, err)
+	require.Len(t, s.Objects, 3)
+	require.Equal(t, &TextSearchDict{
+		Name:     "english_stem",
+		Schema:   s,
+		Template: "snowball",
+		Options:  []*TextSearchOption{{Name: "language", Value: "english"}, {Name: "stopwords", Value: "en,'custom'"}},
+		Attrs:    []schema.Attr{&schema.Comment{Text: "stemmer"}},
+	}, s.Objects[0])
+	require.Equal(t, &TextSearchConfig{
+		Name:   "english2",
+		Schema: s,
+		Parser: "default",
+		Mappings: []*TextSearchMapping{
+			{Tokens: []string{"asciiword", "word"}, Dicts: []string{"english_stem"}},
+			{Tokens: []string{"numword"}, Dicts: []string{"simple", "english_stem"}},
+		},
+	}, s.Objects[1])
+	require.Equal(t, &TextSearchConfig{Name: "empty", Schema: s, Parser: "default"}, s.Objects[2])
+	require.NoError(t, m.ExpectationsWereMet())
+
+	// Text search objects are not inspected on CockroachDB.
+	i := &inspect{conn: &conn{ExecQuerier: db, crdb: true}}
+	require.NoError(t, i.inspectTextSearch(context.Background(), schema.NewRealm(schema.New("public"))))
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDiff_TextSearch(t *testing.T) {
+	d := &diff{conn: &conn{ExecQuerier: sqlx.NoRows}}
+	dict := &TextSearchDict{Name: "english_stem", Template: "snowball", Options: []*TextSearchOption{{Name: "language", Value: "english"}}}
+	config := &TextSearchConfig{Name: "english2", Parser: "default", Mappings: []*TextSearchMapping{{Tokens: []string{"word"}, Dicts: []string{"english_stem"}}}}
+	from, to := schema.New("public"), schema.New("public").AddObjects(config, dict)
+	changes, err := d.SchemaObjectDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.AddObject{O: dict}, &schema.AddObject{O: config}}, changes)
+	changes, err = d.SchemaObjectDiff(to, from)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.DropObject{O: config}, &schema.DropObject{O: dict}}, changes)
+	changes, err = d.SchemaObjectDiff(to, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	config2 := &TextSearchConfig{Name: "english2", Parser: "default", Mappings: []*TextSearchMapping{{Tokens: []string{"word"}, Dicts: []string{"simple"}}}}
+	changes, err = d.SchemaObjectDiff(to, schema.New("public").AddObjects(dict, config2))
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyObject{From: config, To: config2}}, changes)
+
+	dict2 := &TextSearchDict{Name: "english_stem", Template: "simple"}
+	_, err = d.SchemaObjectDiff(to, schema.New("public").AddObjects(dict2, config))
+	require.EqualError(t, err, `changing the template of text search dictionary "english_stem" is not supported (drop and add is required)`)
+}
+
+func TestPlanChanges_TextSearch(t *testing.T) {
+	drv := &planApply{conn: &conn{ExecQuerier: sqlx.NoRows}}
+	s := schema.New("public")
+	dict := &TextSearchDict{
+		Name: "english_stem", Schema: s, Template: "snowball",
+		Options: []*TextSearchOption{{Name: "language", Value: "english"}},
+		Attrs:   []schema.Attr{&schema.Comment{Text: "stemmer"}},
+	}
+	config := &TextSearchConfig{
+		Name: "english2", Schema: s, Parser: "default",
+		Mappings: []*TextSearchMapping{{Tokens: []string{"asciiword", "word"}, Dicts: []string{"english_stem", "simple"}}},
+	}
+	docs := schema.NewTable("docs").
+		SetSchema(s).
+		AddColumns(
+			schema.NewStringColumn("body", "text"),
+			schema.NewColumn("tsv").
+				SetType(&TextSearchType{T: TypeTSVector}).
+				SetGeneratedExpr(&schema.GeneratedExpr{Expr: "to_tsvector('english2', body)", Type: "STORED"}),
+		)
+	docs.AddIndexes(schema.NewIndex("docs_tsv").AddColumns(docs.Columns[1]).AddAttrs(&IndexType{T: IndexTypeGIN}))
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: docs},
+		&schema.AddObject{O: config},
+		&schema.AddObject{O: dict},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`CREATE TEXT SEARCH CONFIGURATION "public"."english2" (PARSER = default)`,
+		`ALTER TEXT SEARCH CONFIGURATION "public"."english2" ADD MAPPING FOR asciiword, word WITH english_stem, simple`,
+		`CREATE TEXT SEARCH DICTIONARY "public"."english_stem" (TEMPLATE = snowball, language = 'english')`,
+		`COMMENT ON TEXT SEARCH DICTIONARY "public"."english_stem" IS 'stemmer'`,
+		`CREATE TABLE "public"."docs" ("body" text NOT NULL, "tsv" tsvector NOT NULL GENERATED ALWAYS AS (to_tsvector('english2', body)) STORED)`,
+		`CREATE INDEX "docs_tsv" ON "public"."docs" USING GIN ("tsv")`,
+	}, cmds(plan))
+
+	// Modify mappings and options.
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyObject{
+			From: config,
+			To: &TextSearchConfig{
+				Name: "english2", Schema: s, Parser: "default",
+				Mappings: []*TextSearchMapping{{Tokens: []string{"word", "numword"}, Dicts: []string{"simple"}}},
+			},
+		},
+		&schema.ModifyObject{
+			From: dict,
+			To: &TextSearchDict{
+				Name: "english_stem", Schema: s, Template: "snowball",
+				Options: []*TextSearchOption{{Name: "stopwords", Value: "english"}},
+				Attrs:   []schema.Attr{&schema.Comment{Text: "stemmer"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`ALTER TEXT SEARCH CONFIGURATION "public"."english2" DROP MAPPING FOR asciiword`,
+		`ALTER TEXT SEARCH CONFIGURATION "public"."english2" ALTER MAPPING FOR word WITH simple`,
+		`ALTER TEXT SEARCH CONFIGURATION "public"."english2" ADD MAPPING FOR numword WITH simple`,
+		`ALTER TEXT SEARCH DICTIONARY "public"."english_stem" (stopwords = 'english', language)`,
+	}, cmds(plan))
+
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.DropObject{O: config},
+		&schema.DropObject{O: dict},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`DROP TEXT SEARCH CONFIGURATION "public"."english2"`,
+		`DROP TEXT SEARCH DICTIONARY "public"."english_stem"`,
+	}, cmds(plan))
+	require.Equal(t, []string{
+		`CREATE TEXT SEARCH DICTIONARY "public"."english_stem" (TEMPLATE = snowball, language = 'english')`,
+		`COMMENT ON TEXT SEARCH DICTIONARY "public"."english_stem" IS 'stemmer'`,
+	}, plan.Changes[1].Reverse)
+}
+
+func TestSQLSpec_TextSearch(t *testing.T) {
+	f := `
+schema "public" {}
+text_search_dictionary "english_stem" {
+	schema   = schema.public
+	template = "snowball"
+	comment  = "stemmer"
+	option "language" {
+		value = "english"
+	}
+}
+text_search_config "english2" {
+	schema = schema.public
+	parser = "default"
+	mapping {
+		tokens       = ["asciiword", "word"]
+		dictionaries = ["english_stem", "simple"]
+	}
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Len(t, s.Objects, 2)
+	require.Equal(t, &TextSearchDict{
+		Name:     "english_stem",
+		Schema:   &s,
+		Template: "snowball",
+		Options:  []*TextSearchOption{{Name: "language", Value: "english"}},
+		Attrs:    []schema.Attr{&schema.Comment{Text: "stemmer"}},
+	}, s.Objects[0])
+	require.Equal(t, []*TextSearchMapping{{Tokens: []string{"asciiword", "word"}, Dicts: []string{"english_stem", "simple"}}}, s.Objects[1].(*TextSearchConfig).Mappings)
+
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	require.Len(t, s2.Objects, 2)
+	require.Equal(t, s.Objects[0].(*TextSearchDict).Options, s2.Objects[0].(*TextSearchDict).Options)
+	require.Equal(t, s.Objects[1].(*TextSearchConfig).Mappings, s2.Objects[1].(*TextSearchConfig).Mappings)
+}
+
+func TestSQLSpec_TextSearchIndex(t *testing.T) {
+	f := `
+schema "public" {}
+table "docs" {
+	schema = schema.public
+	column "body" {
+		type = text
+	}
+	column "Title" {
+		type = text
+	}
+	index "docs_body" {
+		type = GIN
+		on {
+			column      = column.body
+			text_search = "english"
+		}
+		on {
+			column      = column.Title
+			text_search = "simple"
+		}
+	}
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	idx := s.Tables[0].Indexes[0]
+	require.Equal(t, &schema.RawExpr{X: `to_tsvector('english'::regconfig, body)`}, idx.Parts[0].X)
+	require.Equal(t, &schema.RawExpr{X: `to_tsvector('simple'::regconfig, "Title")`}, idx.Parts[1].X)
+	require.Nil(t, idx.Parts[0].C)
+
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `text_search = "english"`)
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	require.Equal(t, idx.Parts[0].X, s2.Tables[0].Indexes[0].Parts[0].X)
+	require.Equal(t, idx.Parts[1].X, s2.Tables[0].Indexes[0].Parts[1].X)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table "docs" {
+	schema = schema.public
+	column "body" {
+		type = text
+	}
+	index "docs_body" {
+		on {
+			expr        = "lower(body)"
+			text_search = "english"
+		}
+	}
+}
+`), &s, nil)
+	require.ErrorContains(t, err, "attribute 'on.text_search' requires a column")
+}
@@ -0,0 +1,638 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/specutil"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// Hypertable describes the TimescaleDB hypertable configuration of a table.
+	// Tables with this attribute are converted to hypertables using create_hypertable.
+	Hypertable struct {
+		schema.Attr
+		TimeColumn string
+		// ChunkInterval holds the chunk interval of the time dimension. For example,
+		// "7 days" for time columns, or a number for integer columns. Optional.
+		ChunkInterval string
+		// SpaceColumn and Partitions describe an optional space dimension.
+		SpaceColumn string
+		Partitions  int
+		// Compression holds the native compression settings of the hypertable. Optional.
+		Compression *HypertableCompression
+	}
+
+	// HypertableCompression describes the compression settings of a hypertable.
+	HypertableCompression struct {
+		SegmentBy []string
+		OrderBy   string // e.g. "ts DESC".
+		// After is the interval of the compression policy. Optional.
+		After string
+	}
+
+	// ContinuousAggregate marks a materialized view as a TimescaleDB continuous aggregate.
+	ContinuousAggregate struct {
+		schema.Attr
+		MaterializedOnly bool
+		// Refresh holds the refresh policy of the continuous aggregate. Optional.
+		Refresh *RefreshPolicy
+	}
+
+	// RefreshPolicy describes the refresh policy of a continuous aggregate.
+	RefreshPolicy struct {
+		StartOffset      string // Empty means NULL (unbounded).
+		EndOffset        string // Empty means NULL (unbounded).
+		ScheduleInterval string
+	}
+)
+
+// timescaleParam is the setting that indicates TimescaleDB is loaded. See `paramsQuery`.
+const timescaleParam = "timescaledb.license"
+
+// hasTimescale reports if the timescaledb extension is installed in the connected database.
+func (i *inspect) hasTimescale(ctx context.Context) (bool, error) {
+	if !i.timescale {
+		return false, nil
+	}
+	rows, err := i.QueryContext(ctx, timescaleExtQuery)
+	if err != nil {
+		return false, fmt.Errorf("postgres: checking timescaledb extension: %w", err)
+	}
+	exists, err := sqlx.ScanNullBool(rows)
+	if err != nil {
+		return false, fmt.Errorf("postgres: checking timescaledb extension: %w", err)
+	}
+	return exists.Bool, nil
+}
+
+// hypertables appends the hypertable configuration to the tables of the schema.
+func (i *inspect) hypertables(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, hypertablesQuery, s)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q hypertables: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			table, column, interval, space string
+			partitions                     int
+			compressed                     bool
+			segmentBy, orderBy, after      sql.NullString
+		)
+		if err := rows.Scan(&table, &column, &interval, &space, &partitions, &compressed, &segmentBy, &orderBy, &after); err != nil {
+			return err
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("postgres: hypertable %q was not found in schema", table)
+		}
+		h := &Hypertable{TimeColumn: column, ChunkInterval: interval, SpaceColumn: space, Partitions: partitions}
+		if compressed {
+			h.Compression = &HypertableCompression{OrderBy: orderBy.String, After: after.String}
+			if segmentBy.String != "" {
+				h.Compression.SegmentBy = strings.Split(segmentBy.String, ",")
+			}
+		}
+		t.AddAttrs(h)
+	}
+	return rows.Err()
+}
+
+// inspectContinuousAggregates inspects the continuous aggregates of the realm, if TimescaleDB is installed.
+func (i *inspect) inspectContinuousAggregates(ctx context.Context, r *schema.Realm) error {
+	switch timescale, err := i.hasTimescale(ctx); {
+	case err != nil:
+		return err
+	case timescale:
+		return i.continuousAggregates(ctx, r)
+	}
+	return nil
+}
+
+// continuousAggregates appends the continuous aggregates of the realm as materialized views.
+// Existing materialized views are marked with the ContinuousAggregate attribute.
+func (i *inspect) continuousAggregates(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, len(r.Schemas))
+	for j, s := range r.Schemas {
+		args[j] = s.Name
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(continuousAggregatesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying continuous aggregates: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, def        string
+			materializedOnly     bool
+			start, end, schedule sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &materializedOnly, &def, &start, &end, &schedule); err != nil {
+			return err
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		a := &ContinuousAggregate{MaterializedOnly: materializedOnly}
+		if schedule.Valid {
+			a.Refresh = &RefreshPolicy{StartOffset: start.String, EndOffset: end.String, ScheduleInterval: schedule.String}
+		}
+		v, ok := s.Materialized(name)
+		if !ok {
+			v = schema.NewMaterializedView(name, def)
+			s.AddViews(v)
+		}
+		schema.ReplaceOrAppend(&v.Attrs, a)
+	}
+	return rows.Err()
+}
+
+// hypertable returns the statements for converting the table to a hypertable.
+func (s *state) hypertable(t *schema.Table, h *Hypertable, migrateData bool) []*migrate.Change {
+	args := []string{s.regclass(t), quote(h.TimeColumn)}
+	if h.ChunkInterval != "" {
+		args = append(args, "chunk_time_interval => "+interval(h.ChunkInterval))
+	}
+	if h.SpaceColumn != "" {
+		args = append(args, "partitioning_column => "+quote(h.SpaceColumn), "number_partitions => "+strconv.Itoa(h.Partitions))
+	}
+	if migrateData {
+		args = append(args, "migrate_data => true")
+	}
+	changes := []*migrate.Change{{
+		Cmd:     fmt.Sprintf("SELECT create_hypertable(%s)", strings.Join(args, ", ")),
+		Comment: fmt.Sprintf("convert %q table to a hypertable", t.Name),
+	}}
+	if h.Compression != nil {
+		changes = append(changes, s.compression(t, h.Compression)...)
+	}
+	return changes
+}
+
+// compression returns the statements for enabling compression on the hypertable.
+func (s *state) compression(t *schema.Table, c *HypertableCompression) []*migrate.Change {
+	opts := []string{"timescaledb.compress"}
+	if len(c.SegmentBy) > 0 {
+		opts = append(opts, "timescaledb.compress_segmentby = "+quote(strings.Join(c.SegmentBy, ", ")))
+	}
+	if c.OrderBy != "" {
+		opts = append(opts, "timescaledb.compress_orderby = "+quote(c.OrderBy))
+	}
+	changes := []*migrate.Change{{
+		Cmd:     s.Build("ALTER TABLE").Table(t).P("SET").Wrap(func(b *sqlx.Builder) { b.P(strings.Join(opts, ", ")) }).String(),
+		Comment: fmt.Sprintf("enable compression on %q hypertable", t.Name),
+		Reverse: s.Build("ALTER TABLE").Table(t).P("SET (timescaledb.compress = false)").String(),
+	}}
+	if c.After != "" {
+		changes = append(changes, s.compressionPolicy(t, c.After))
+	}
+	return changes
+}
+
+// compressionPolicy returns the statement for adding a compression policy to the hypertable.
+func (s *state) compressionPolicy(t *schema.Table, after string) *migrate.Change {
+	return &migrate.Change{
+		Cmd:     fmt.Sprintf("SELECT add_compression_policy(%s, %s)", s.regclass(t), interval(after)),
+		Comment: fmt.Sprintf("add compression policy to %q hypertable", t.Name),
+		Reverse: fmt.Sprintf("SELECT remove_compression_policy(%s)", s.regclass(t)),
+	}
+}
+
+// modifyHypertable returns the statements for applying a hypertable attribute change.
+func (s *state) modifyHypertable(t *schema.Table, c schema.Change) ([]*migrate.Change, error) {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		return s.hypertable(t, c.A.(*Hypertable), true), nil
+	case *schema.ModifyAttr:
+		from, to := c.From.(*Hypertable), c.To.(*Hypertable)
+		if from.TimeColumn != to.TimeColumn || from.SpaceColumn != to.SpaceColumn || from.Partitions != to.Partitions {
+			return nil, fmt.Errorf("changing the dimensions of hypertable %q is not supported", t.Name)
+		}
+		var changes []*migrate.Change
+		if from.ChunkInterval != to.ChunkInterval && to.ChunkInterval != "" {
+			change := &migrate.Change{
+				Cmd:     fmt.Sprintf("SELECT set_chunk_time_interval(%s, %s)", s.regclass(t), interval(to.ChunkInterval)),
+				Comment: fmt.Sprintf("set chunk interval of %q hypertable", t.Name),
+			}
+			if from.ChunkInterval != "" {
+				change.Reverse = fmt.Sprintf("SELECT set_chunk_time_interval(%s, %s)", s.regclass(t), interval(from.ChunkInterval))
+			}
+			changes = append(changes, change)
+		}
+		switch fromC, toC := from.Compression, to.Compression; {
+		case fromC == nil && toC != nil:
+			changes = append(changes, s.compression(t, toC)...)
+		case fromC != nil && toC == nil:
+			if fromC.After != "" {
+				changes = append(changes, reverseChange(s.compressionPolicy(t, fromC.After)))
+			}
+			changes = append(changes, reverseChanges(s.compression(t, &HypertableCompression{SegmentBy: fromC.SegmentBy, OrderBy: fromC.OrderBy}))...)
+		case fromC != nil && toC != nil:
+			if strings.Join(fromC.SegmentBy, ",") != strings.Join(toC.SegmentBy, ",") || fromC.OrderBy != toC.OrderBy {
+				changes = append(changes, s.compression(t, &HypertableCompression{SegmentBy: toC.SegmentBy, OrderBy: toC.OrderBy})...)
+			}
+			if fromC.After != toC.After {
+				if fromC.After != "" {
+					changes = append(changes, reverseChange(s.compressionPolicy(t, fromC.After)))
+				}
+				if toC.After != "" {
+					changes = append(changes, s.compressionPolicy(t, toC.After))
+				}
+			}
+		}
+		return changes, nil
+	default:
+		return nil, fmt.Errorf("unexpected hypertable change %T", c)
+	}
+}
+
+// continuousAggregate plans the given view change if it describes a continuous
+// aggregate, and reports if the change was handled.
+func (s *state) continuousAggregate(c schema.Change) (bool, error) {
+	var a ContinuousAggregate
+	switch c := c.(type) {
+	case *schema.AddView:
+		if !sqlx.Has(c.V.Attrs, &a) {
+			return false, nil
+		}
+		s.append(s.addContinuousAggregate(c.V, &a)...)
+	case *schema.DropView:
+		if !sqlx.Has(c.V.Attrs, &a) {
+			return false, nil
+		}
+		s.append(reverseChanges(s.addContinuousAggregate(c.V, &a))...)
+	case *schema.ModifyView:
+		var from ContinuousAggregate
+		fromHas, toHas := sqlx.Has(c.From.Attrs, &from), sqlx.Has(c.To.Attrs, &a)
+		switch {
+		case !fromHas && !toHas:
+			return false, nil
+		case !fromHas || !toHas || sqlx.ViewDefChanged(c.From, c.To):
+			// Continuous aggregates cannot be converted or
+			// redefined, and therefore, they are recreated.
+			if fromHas {
+				s.append(reverseChanges(s.addContinuousAggregate(c.From, &from))...)
+			} else if err := s.dropView(&schema.DropView{V: c.From}); err != nil {
+				return true, err
+			}
+			if toHas {
+				s.append(s.addContinuousAggregate(c.To, &a)...)
+			} else if err := s.addView(&schema.AddView{V: c.To}); err != nil {
+				return true, err
+			}
+		default:
+			s.append(s.modifyContinuousAggregate(c.To, &from, &a)...)
+		}
+	case *schema.RenameView:
+		if !sqlx.Has(c.From.Attrs, &a) {
+			return false, nil
+		}
+		s.append(&migrate.Change{
+			Source:  c,
+			Comment: fmt.Sprintf("rename a continuous aggregate from %q to %q", c.From.Name, c.To.Name),
+			Cmd:     s.Build("ALTER MATERIALIZED VIEW").View(c.From).P("RENAME TO").Ident(c.To.Name).String(),
+			Reverse: s.Build("ALTER MATERIALIZED VIEW").View(c.To).P("RENAME TO").Ident(c.From.Name).String(),
+		})
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// addContinuousAggregate returns the statements for creating the continuous aggregate.
+func (s *state) addContinuousAggregate(v *schema.View, a *ContinuousAggregate) []*migrate.Change {
+	b := s.Build("CREATE MATERIALIZED VIEW").View(v).P("WITH")
+	if a.MaterializedOnly {
+		b.P("(timescaledb.continuous, timescaledb.materialized_only = true)")
+	} else {
+		b.P("(timescaledb.continuous)")
+	}
+	changes := []*migrate.Change{{
+		Cmd:     b.P("AS", strings.TrimSuffix(strings.TrimSpace(v.Def), ";"), "WITH NO DATA").String(),
+		Comment: fmt.Sprintf("create %q continuous aggregate", v.Name),
+		Reverse: s.Build("DROP MATERIALIZED VIEW").View(v).String(),
+	}}
+	if a.Refresh != nil {
+		changes = append(changes, s.refreshPolicy(v, a.Refresh))
+	}
+	return changes
+}
+
+// modifyContinuousAggregate returns the statements for modifying the options of a continuous aggregate.
+func (s *state) modifyContinuousAggregate(v *schema.View, from, to *ContinuousAggregate) []*migrate.Change {
+	var changes []*migrate.Change
+	if from.MaterializedOnly != to.MaterializedOnly {
+		set := func(b bool) string {
+			return s.Build("ALTER MATERIALIZED VIEW").View(v).P(fmt.Sprintf("SET (timescaledb.materialized_only = %t)", b)).String()
+		}
+		changes = append(changes, &migrate.Change{
+			Cmd:     set(to.MaterializedOnly),
+			Comment: fmt.Sprintf("set materialized_only option of %q continuous aggregate", v.Name),
+			Reverse: set(from.MaterializedOnly),
+		})
+	}
+	if from.Refresh == nil && to.Refresh == nil || from.Refresh != nil && to.Refresh != nil && *from.Refresh == *to.Refresh {
+		return changes
+	}
+	if from.Refresh != nil {
+		changes = append(changes, reverseChange(s.refreshPolicy(v, from.Refresh)))
+	}
+	if to.Refresh != nil {
+		changes = append(changes, s.refreshPolicy(v, to.Refresh))
+	}
+	return changes
+}
+
+// refreshPolicy returns the statement for adding a refresh policy to the continuous aggregate.
+func (s *state) refreshPolicy(v *schema.View, p *RefreshPolicy) *migrate.Change {
+	name := quote(s.Build().View(v).String())
+	offset := func(s string) string {
+		if s == "" {
+			return "NULL"
+		}
+		return interval(s)
+	}
+	args := []string{name, "start_offset => " + offset(p.StartOffset), "end_offset => " + offset(p.EndOffset)}
+	if p.ScheduleInterval != "" {
+		args = append(args, "schedule_interval => "+interval(p.ScheduleInterval))
+	}
+	return &migrate.Change{
+		Cmd:     fmt.Sprintf("SELECT add_continuous_aggregate_policy(%s)", strings.Join(args, ", ")),
+		Comment: fmt.Sprintf("add refresh policy to %q continuous aggregate", v.Name),
+		Reverse: fmt.Sprintf("SELECT remove_continuous_aggregate_policy(%s)", name),
+	}
+}
+
+// regclass returns the table name quoted as a regclass literal.
+func (s *state) regclass(t *schema.Table) string {
+	return quote(s.Build().Table(t).String())
+}
+
+// hypertableChanged checks and returns an error if the table is converted back from a hypertable.
+func hypertableChanged(from, to *schema.Table) (schema.Change, error) {
+	var fromH, toH Hypertable
+	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromH), sqlx.Has(to.Attrs, &toH); {
+	case fromHas && !toHas:
+		return nil, fmt.Errorf("hypertable %q cannot be converted back to a regular table (drop and add is required)", from.Name)
+	case !fromHas && toHas:
+		return &schema.AddAttr{A: &toH}, nil
+	case fromHas && toHas && !fromH.equal(&toH):
+		return &schema.ModifyAttr{From: &fromH, To: &toH}, nil
+	}
+	return nil, nil
+}
+
+// equal reports if the two hypertable configurations are equal.
+func (h *Hypertable) equal(x *Hypertable) bool {
+	if h.TimeColumn != x.TimeColumn || h.ChunkInterval != x.ChunkInterval || h.SpaceColumn != x.SpaceColumn || h.Partitions != x.Partitions {
+		return false
+	}
+	switch c1, c2 := h.Compression, x.Compression; {
+	case c1 == nil || c2 == nil:
+		return c1 == c2
+	default:
+		return strings.Join(c1.SegmentBy, ",") == strings.Join(c2.SegmentBy, ",") && c1.OrderBy == c2.OrderBy && c1.After == c2.After
+	}
+}
+
+// isHypertableChange reports if the given attribute change describes a hypertable change.
+func isHypertableChange(c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		_, ok := c.A.(*Hypertable)
+		return ok
+	case *schema.ModifyAttr:
+		_, ok := c.To.(*Hypertable)
+		return ok
+	}
+	return false
+}
+
+// continuousAggregateChanged reports if the continuous aggregate options of the views were changed.
+func continuousAggregateChanged(from, to *schema.View) bool {
+	var fromA, toA ContinuousAggregate
+	fromHas, toHas := sqlx.Has(from.Attrs, &fromA), sqlx.Has(to.Attrs, &toA)
+	if fromHas != toHas || fromA.MaterializedOnly != toA.MaterializedOnly {
+		return true
+	}
+	switch r1, r2 := fromA.Refresh, toA.Refresh; {
+	case r1 == nil || r2 == nil:
+		return r1 != r2
+	default:
+		return *r1 != *r2
+	}
+}
+
+// interval formats the given interval as an SQL expression. Numbers are
+// returned as-is, as they are used for hypertables with integer time columns.
+func interval(s string) string {
+	if sqlx.IsLiteralNumber(s) {
+		return s
+	}
+	return "INTERVAL " + quote(s)
+}
+
+// reverseChange returns the reverse of the given change.
+func reverseChange(c *migrate.Change) *migrate.Change {
+	return &migrate.Change{Cmd: c.Reverse.(string), Reverse: c.Cmd, Comment: "reverse: " + c.Comment}
+}
+
+// reverseChanges returns the reverse of the given changes in the opposite order.
+func reverseChanges(cs []*migrate.Change) []*migrate.Change {
+	r := make([]*migrate.Change, 0, len(cs))
+	for i := len(cs) - 1; i >= 0; i-- {
+		r = append(r, reverseChange(cs[i]))
+	}
+	return r
+}
+
+// convertHypertable converts and appends the hypertable block into the table attributes if exists.
+func convertHypertable(spec schemahcl.Resource, table *schema.Table) error {
+	r, ok := spec.Resource("hypertable")
+	if !ok {
+		return nil
+	}
+	var h struct {
+		TimeColumn    *schemahcl.Ref `spec:"time_column"`
+		ChunkInterval string         `spec:"chunk_interval"`
+		SpaceColumn   *schemahcl.Ref `spec:"space_column"`
+		Partitions    int            `spec:"partitions"`
+	}
+	if err := r.As(&h); err != nil {
+		return fmt.Errorf("parsing %s.hypertable: %w", table.Name, err)
+	}
+	if h.TimeColumn == nil {
+		return fmt.Errorf("missing attribute %s.hypertable.time_column", table.Name)
+	}
+	c, err := specutil.ColumnByRef(table, h.TimeColumn)
+	if err != nil {
+		return err
+	}
+	attr := &Hypertable{TimeColumn: c.Name, ChunkInterval: h.ChunkInterval, Partitions: h.Partitions}
+	if h.SpaceColumn != nil {
+		c, err := specutil.ColumnByRef(table, h.SpaceColumn)
+		if err != nil {
+			return err
+		}
+		if h.Partitions == 0 {
+			return fmt.Errorf("missing attribute %s.hypertable.partitions for space column %q", table.Name, c.Name)
+		}
+		attr.SpaceColumn = c.Name
+	}
+	if r, ok := r.Resource("compression"); ok {
+		var c struct {
+			SegmentBy []*schemahcl.Ref `spec:"segment_by"`
+			OrderBy   string           `spec:"order_by"`
+			After     string           `spec:"after"`
+		}
+		if err := r.As(&c); err != nil {
+			return fmt.Errorf("parsing %s.hypertable.compression: %w", table.Name, err)
+		}
+		attr.Compression = &HypertableCompression{OrderBy: c.OrderBy, After: c.After}
+		for _, ref := range c.SegmentBy {
+			column, err := specutil.ColumnByRef(table, ref)
+			if err != nil {
+				return err
+			}
+			attr.Compression.SegmentBy = append(attr.Compression.SegmentBy, column.Name)
+		}
+	}
+	table.AddAttrs(attr)
+	return nil
+}
+
+// fromHypertable returns the resource spec for representing the hypertable block.
+func fromHypertable(h *Hypertable) *schemahcl.Resource {
+	r := &schemahcl.Resource{
+		Type: "hypertable",
+		Attrs: []*schemahcl.Attr{
+			schemahcl.RefAttr("time_column", specutil.ColumnRef(h.TimeColumn)),
+		},
+	}
+	if h.ChunkInterval != "" {
+		r.Attrs = append(r.Attrs, schemahcl.StringAttr("chunk_interval", h.ChunkInterval))
+	}
+	if h.SpaceColumn != "" {
+		r.Attrs = append(r.Attrs, schemahcl.RefAttr("space_column", specutil.ColumnRef(h.SpaceColumn)), schemahcl.IntAttr("partitions", h.Partitions))
+	}
+	if c := h.Compression; c != nil {
+		cr := &schemahcl.Resource{Type: "compression"}
+		if len(c.SegmentBy) > 0 {
+			refs := make([]*schemahcl.Ref, len(c.SegmentBy))
+			for i, n := range c.SegmentBy {
+				refs[i] = specutil.ColumnRef(n)
+			}
+			cr.Attrs = append(cr.Attrs, schemahcl.RefsAttr("segment_by", refs...))
+		}
+		if c.OrderBy != "" {
+			cr.Attrs = append(cr.Attrs, schemahcl.StringAttr("order_by", c.OrderBy))
+		}
+		if c.After != "" {
+			cr.Attrs = append(cr.Attrs, schemahcl.StringAttr("after", c.After))
+		}
+		r.Children = append(r.Children, cr)
+	}
+	return r
+}
+
+// convertContinuousAggregate converts and appends the continuous_aggregate block into the view attributes if exists.
+func convertContinuousAggregate(spec schemahcl.Resource, v *schema.View) error {
+	r, ok := spec.Resource("continuous_aggregate")
+	if !ok {
+		return nil
+	}
+	var a struct {
+		MaterializedOnly bool `spec:"materialized_only"`
+		Refresh          *struct {
+			StartOffset      string `spec:"start_offset"`
+			EndOffset        string `spec:"end_offset"`
+			ScheduleInterval string `spec:"schedule_interval"`
+		} `spec:"refresh"`
+	}
+	if err := r.As(&a); err != nil {
+		return fmt.Errorf("parsing %s.continuous_aggregate: %w", v.Name, err)
+	}
+	attr := &ContinuousAggregate{MaterializedOnly: a.MaterializedOnly}
+	if a.Refresh != nil {
+		attr.Refresh = &RefreshPolicy{StartOffset: a.Refresh.StartOffset, EndOffset: a.Refresh.EndOffset, ScheduleInterval: a.Refresh.ScheduleInterval}
+	}
+	v.AddAttrs(attr)
+	return nil
+}
+
+// fromContinuousAggregate returns the resource spec for representing the continuous_aggregate block.
+func fromContinuousAggregate(a *ContinuousAggregate) *schemahcl.Resource {
+	r := &schemahcl.Resource{Type: "continuous_aggregate"}
+	if a.MaterializedOnly {
+		r.Attrs = append(r.Attrs, schemahcl.BoolAttr("materialized_only", true))
+	}
+	if p := a.Refresh; p != nil {
+		pr := &schemahcl.Resource{Type: "refresh"}
+		for _, kv := range [][2]string{{"start_offset", p.StartOffset}, {"end_offset", p.EndOffset}, {"schedule_interval", p.ScheduleInterval}} {
+			if kv[1] != "" {
+				pr.Attrs = append(pr.Attrs, schemahcl.StringAttr(kv[0], kv[1]))
+			}
+		}
+		r.Children = append(r.Children, pr)
+	}
+	return r
+}
+
+const (
+	// Query to check if the timescaledb extension is installed.
+	timescaleExtQuery = `SELECT EXISTS(SELECT 1 FROM pg_catalog.pg_extension WHERE extname = 'timescaledb')`
+
+	// Query to list the hypertables of a schema, including their dimensions and compression settings.
+	hypertablesQuery = `
+SELECT
+	h.hypertable_name,
+	d.column_name,
+	COALESCE(d.time_interval::text, d.integer_interval::text, '') AS chunk_interval,
+	COALESCE(p.column_name, '') AS space_column,
+	COALESCE(p.num_partitions, 0) AS num_partitions,
+	h.compression_enabled,
+	(SELECT string_agg(c.attname, ',' ORDER BY c.segmentby_column_index) FROM timescaledb_information.compression_settings c WHERE c.hypertable_schema = h.hypertable_schema AND c.hypertable_name = h.hypertable_name AND c.segmentby_column_index IS NOT NULL) AS segment_by,
+	(SELECT string_agg(c.attname || CASE WHEN c.orderby_asc THEN '' ELSE ' DESC' END, ', ' ORDER BY c.orderby_column_index) FROM timescaledb_information.compression_settings c WHERE c.hypertable_schema = h.hypertable_schema AND c.hypertable_name = h.hypertable_name AND c.orderby_column_index IS NOT NULL) AS order_by,
+	(SELECT j.config->>'compress_after' FROM timescaledb_information.jobs j WHERE j.proc_name = 'policy_compression' AND j.hypertable_schema = h.hypertable_schema AND j.hypertable_name = h.hypertable_name LIMIT 1) AS compress_after
+FROM
+	timescaledb_information.hypertables h
+	JOIN timescaledb_information.dimensions d ON d.hypertable_schema = h.hypertable_schema AND d.hypertable_name = h.hypertable_name AND d.dimension_number = 1
+	LEFT JOIN timescaledb_information.dimensions p ON p.hypertable_schema = h.hypertable_schema AND p.hypertable_name = h.hypertable_name AND p.dimension_number = 2
+WHERE
+	h.hypertable_schema = $1 AND h.hypertable_name IN (%s)
+ORDER BY
+	h.hypertable_name
+`
+
+	// Query to list the continuous aggregates and their refresh policies.
+	continuousAggregatesQuery = `
+SELECT
+	ca.view_schema,
+	ca.view_name,
+	ca.materialized_only,
+	ca.view_definition,
+	j.config->>'start_offset' AS start_offset,
+	j.config->>'end_offset' AS end_offset,
+	j.schedule_interval::text AS schedule_interval
+FROM
+	timescaledb_information.continuous_aggregates ca
+	LEFT JOIN timescaledb_information.jobs j ON j.proc_name = 'policy_refresh_continuous_aggregate' AND j.hypertable_schema = ca.materialization_hypertable_schema AND j.hypertable_name = ca.materialization_hypertable_name
+WHERE
+	ca.view_schema IN (%s)
+ORDER BY
+	ca.view_schema, ca.view_name
+`
+)
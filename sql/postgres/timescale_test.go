@@ -0,0 +1,223 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectHypertable(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	m.ExpectQuery(sqltest.Escape(paramsQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "setting"}).
+			AddRow("server_version_num", "150000").
+			AddRow("timescaledb.license", "timescale"))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "comment", "owner"}).AddRow("public", nil, nil))
+	mk.tableExists("public", "metrics", true)
+	mk.ExpectQuery(sqltest.Escape(timescaleExtQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mk.ExpectQuery(queryColumns).
+		WithArgs("public", "metrics").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "data_type", "formatted", "is_nullable", "column_default", "character_maximum_length", "numeric_precision", "datetime_precision", "numeric_scale", "interval_type", "character_set_name", "collation_name", "is_identity", "identity_start", "identity_increment", "identity_last", "identity_generation", "generation_expression", "comment", "typtype", "typelem", "elemtyp", "oid"}))
+	mk.noIndexes()
+	mk.noFKs()
+	mk.noChecks()
+	mk.noLabels()
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(hypertablesQuery, "$2"))).
+		WithArgs("public", "metrics").
+		WillReturnRows(sqltest.Rows(`
+ hypertable_name | column_name | chunk_interval | space_column | num_partitions | compression_enabled | segment_by | order_by | compress_after
+-----------------+-------------+----------------+--------------+----------------+---------------------+------------+----------+----------------
+ metrics         | ts          | 7 days         | device       | 4              | true                | device     | ts DESC  | 30 days
+`))
+	mk.noEnums()
+	s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{
+		Mode: schema.InspectSchemas | schema.InspectTables,
+	})
+	require.NoError(t, err)
+	h := &Hypertable{}
+	require.True(t, sqlx.Has(s.Tables[0].Attrs, h))
+	require.Equal(t, &Hypertable{
+		TimeColumn:    "ts",
+		ChunkInterval: "7 days",
+		SpaceColumn:   "device",
+		Partitions:    4,
+		Compression:   &HypertableCompression{SegmentBy: []string{"device"}, OrderBy: "ts DESC", After: "30 days"},
+	}, h)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestPlanChanges_Timescale(t *testing.T) {
+	drv := &planApply{conn: &conn{ExecQuerier: sqlx.NoRows}}
+	metrics := schema.NewTable("metrics").
+		SetSchema(schema.New("public")).
+		AddColumns(
+			schema.NewTimeColumn("ts", TypeTimestampTZ),
+			schema.NewStringColumn("device", "text"),
+		).
+		AddAttrs(&Hypertable{
+			TimeColumn:    "ts",
+			ChunkInterval: "1 day",
+			Compression:   &HypertableCompression{SegmentBy: []string{"device"}, OrderBy: "ts DESC", After: "7 days"},
+		})
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: metrics}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`CREATE TABLE "public"."metrics" ("ts" timestamptz NOT NULL, "device" text NOT NULL)`,
+		`SELECT create_hypertable('"public"."metrics"', 'ts', chunk_time_interval => INTERVAL '1 day')`,
+		`ALTER TABLE "public"."metrics" SET (timescaledb.compress, timescaledb.compress_segmentby = 'device', timescaledb.compress_orderby = 'ts DESC')`,
+		`SELECT add_compression_policy('"public"."metrics"', INTERVAL '7 days')`,
+	}, cmds(plan))
+
+	// Convert an existing table and change its policies.
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: metrics, Changes: []schema.Change{
+			&schema.AddAttr{A: &Hypertable{TimeColumn: "ts", ChunkInterval: "86400", SpaceColumn: "device", Partitions: 2}},
+		}},
+		&schema.ModifyTable{T: metrics, Changes: []schema.Change{
+			&schema.ModifyAttr{
+				From: &Hypertable{TimeColumn: "ts", ChunkInterval: "1 day", Compression: &HypertableCompression{After: "7 days"}},
+				To:   &Hypertable{TimeColumn: "ts", ChunkInterval: "2 days", Compression: &HypertableCompression{After: "1 day"}},
+			},
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`SELECT create_hypertable('"public"."metrics"', 'ts', chunk_time_interval => 86400, partitioning_column => 'device', number_partitions => 2, migrate_data => true)`,
+		`SELECT set_chunk_time_interval('"public"."metrics"', INTERVAL '2 days')`,
+		`SELECT remove_compression_policy('"public"."metrics"')`,
+		`SELECT add_compression_policy('"public"."metrics"', INTERVAL '1 day')`,
+	}, cmds(plan))
+
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: metrics, Changes: []schema.Change{
+			&schema.ModifyAttr{From: &Hypertable{TimeColumn: "ts"}, To: &Hypertable{TimeColumn: "created_at"}},
+		}},
+	})
+	require.EqualError(t, err, `changing the dimensions of hypertable "metrics" is not supported`)
+
+	// Continuous aggregates.
+	daily := schema.NewMaterializedView("daily", "SELECT time_bucket('1 day', ts) AS day, count(*) FROM metrics GROUP BY 1;").
+		SetSchema(metrics.Schema).
+		AddAttrs(&ContinuousAggregate{Refresh: &RefreshPolicy{StartOffset: "1 month", ScheduleInterval: "1 hour"}})
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddView{V: daily}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`CREATE MATERIALIZED VIEW "public"."daily" WITH (timescaledb.continuous) AS SELECT time_bucket('1 day', ts) AS day, count(*) FROM metrics GROUP BY 1 WITH NO DATA`,
+		`SELECT add_continuous_aggregate_policy('"public"."daily"', start_offset => INTERVAL '1 month', end_offset => NULL, schedule_interval => INTERVAL '1 hour')`,
+	}, cmds(plan))
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.DropView{V: daily}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`SELECT remove_continuous_aggregate_policy('"public"."daily"')`,
+		`DROP MATERIALIZED VIEW "public"."daily"`,
+	}, cmds(plan))
+	to := schema.NewMaterializedView(daily.Name, daily.Def).SetSchema(daily.Schema).AddAttrs(&ContinuousAggregate{MaterializedOnly: true})
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyView{From: daily, To: to}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`ALTER MATERIALIZED VIEW "public"."daily" SET (timescaledb.materialized_only = true)`,
+		`SELECT remove_continuous_aggregate_policy('"public"."daily"')`,
+	}, cmds(plan))
+}
+
+func TestDiff_Timescale(t *testing.T) {
+	d := &diff{conn: &conn{ExecQuerier: sqlx.NoRows}}
+	from := schema.NewTable("metrics").SetSchema(schema.New("public"))
+	to := schema.NewTable("metrics").SetSchema(schema.New("public")).AddAttrs(&Hypertable{TimeColumn: "ts"})
+	changes, err := d.TableAttrDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.AddAttr{A: &Hypertable{TimeColumn: "ts"}}}, changes)
+	_, err = d.TableAttrDiff(to, from)
+	require.EqualError(t, err, `hypertable "metrics" cannot be converted back to a regular table (drop and add is required)`)
+	changes, err = d.TableAttrDiff(to, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	v1 := schema.NewMaterializedView("daily", "SELECT 1").AddAttrs(&ContinuousAggregate{})
+	v2 := schema.NewMaterializedView("daily", "SELECT 1").AddAttrs(&ContinuousAggregate{Refresh: &RefreshPolicy{ScheduleInterval: "1 hour"}})
+	require.False(t, d.ViewAttrChanged(v1, v1))
+	require.True(t, d.ViewAttrChanged(v1, v2))
+}
+
+func TestSQLSpec_Timescale(t *testing.T) {
+	f := `
+schema "public" {}
+table "metrics" {
+	schema = schema.public
+	column "ts" {
+		type = timestamptz
+	}
+	column "device" {
+		type = text
+	}
+	hypertable {
+		time_column    = column.ts
+		chunk_interval = "1 day"
+		space_column   = column.device
+		partitions     = 4
+		compression {
+			segment_by = [column.device]
+			order_by   = "ts DESC"
+			after      = "7 days"
+		}
+	}
+}
+materialized "daily" {
+	schema = schema.public
+	as     = "SELECT time_bucket('1 day', ts) AS day, count(*) FROM metrics GROUP BY 1"
+	continuous_aggregate {
+		materialized_only = true
+		refresh {
+			start_offset      = "1 month"
+			schedule_interval = "1 hour"
+		}
+	}
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&Hypertable{
+		TimeColumn:    "ts",
+		ChunkInterval: "1 day",
+		SpaceColumn:   "device",
+		Partitions:    4,
+		Compression:   &HypertableCompression{SegmentBy: []string{"device"}, OrderBy: "ts DESC", After: "7 days"},
+	}}, s.Tables[0].Attrs)
+	a := &ContinuousAggregate{}
+	require.True(t, sqlx.Has(s.Views[0].Attrs, a))
+	require.Equal(t, &ContinuousAggregate{MaterializedOnly: true, Refresh: &RefreshPolicy{StartOffset: "1 month", ScheduleInterval: "1 hour"}}, a)
+
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	require.Equal(t, s.Tables[0].Attrs, s2.Tables[0].Attrs)
+	require.True(t, sqlx.Has(s2.Views[0].Attrs, &ContinuousAggregate{}))
+}
+
+func cmds(p *migrate.Plan) []string {
+	c := make([]string, len(p.Changes))
+	for i := range p.Changes {
+		c[i] = p.Changes[i].Cmd
+	}
+	return c
+}
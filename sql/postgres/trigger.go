@@ -0,0 +1,281 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// TriggerFunc describes the function executed by a trigger. In PostgreSQL, the body of
+// the trigger is the body of its function, and the Body field of the trigger is ignored.
+//
+// Trigger functions are created (or replaced) along with their triggers, but they are
+// left in place when the triggers are dropped, as they might be shared by other triggers.
+// A function without a schema resides in the schema of the trigger table.
+type TriggerFunc struct {
+	schema.Attr
+	F *schema.Func
+}
+
+// Bits of the pg_trigger.tgtype column.
+const (
+	triggerTypeRow      = 1 << 0
+	triggerTypeBefore   = 1 << 1
+	triggerTypeInsert   = 1 << 2
+	triggerTypeDelete   = 1 << 3
+	triggerTypeUpdate   = 1 << 4
+	triggerTypeTruncate = 1 << 5
+	triggerTypeInstead  = 1 << 6
+)
+
+// inspectTriggers inspects the triggers of the schema tables and their functions.
+func (i *inspect) inspectTriggers(ctx context.Context, r *schema.Realm) error {
+	if i.crdb {
+		return nil // unsupported.
+	}
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		if len(s.Tables) > 0 {
+			args = append(args, s.Name)
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(triggersQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying triggers: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			typ                                   int64
+			ns, table, name, fns, fn, lang, fbody string
+		)
+		if err := rows.Scan(&ns, &table, &name, &typ, &fns, &fn, &lang, &fbody); err != nil {
+			return fmt.Errorf("postgres: scanning trigger: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			continue
+		}
+		f := &schema.Func{Name: fn, Body: fbody, Lang: lang, Ret: &schema.UnsupportedType{T: "trigger"}}
+		if fns != ns {
+			f.Schema = &schema.Schema{Name: fns}
+		}
+		tr := &schema.Trigger{
+			Name:       name,
+			ActionTime: schema.TriggerTimeAfter,
+			For:        schema.TriggerForStmt,
+			Attrs:      []schema.Attr{&TriggerFunc{F: f}},
+		}
+		switch {
+		case typ&triggerTypeBefore != 0:
+			tr.ActionTime = schema.TriggerTimeBefore
+		case typ&triggerTypeInstead != 0:
+			tr.ActionTime = schema.TriggerTimeInstead
+		}
+		if typ&triggerTypeRow != 0 {
+			tr.For = schema.TriggerForRow
+		}
+		for _, e := range []struct {
+			bit int64
+			e   schema.TriggerEvent
+		}{
+			{triggerTypeInsert, schema.TriggerEventInsert},
+			{triggerTypeUpdate, schema.TriggerEventUpdate},
+			{triggerTypeDelete, schema.TriggerEventDelete},
+			{triggerTypeTruncate, "TRUNCATE"},
+		} {
+			if typ&e.bit != 0 {
+				tr.Events = append(tr.Events, e.e)
+			}
+		}
+		t.AddTriggers(tr)
+	}
+	return rows.Err()
+}
+
+// TriggerChanged reports if the trigger or the function it executes were changed.
+func (d *diff) TriggerChanged(t1, t2 *schema.Trigger) bool {
+	// The trigger bodies are ignored, as they are defined by their functions.
+	c1, c2 := *t1, *t2
+	c1.Body, c2.Body = "", ""
+	if sqlx.TriggerChanged(&c1, &c2) {
+		return true
+	}
+	var f1, f2 TriggerFunc
+	switch ok1, ok2 := sqlx.Has(t1.Attrs, &f1), sqlx.Has(t2.Attrs, &f2); {
+	case ok1 != ok2:
+		return true
+	case !ok1:
+		return false
+	}
+	var (
+		ns = func(t *schema.Trigger, f *schema.Func) string {
+			if s := funcSchema(t, f); s != nil {
+				return s.Name
+			}
+			return ""
+		}
+		lang = func(f *schema.Func) string {
+			if f.Lang == "" {
+				return "plpgsql"
+			}
+			return strings.ToLower(f.Lang)
+		}
+	)
+	return f1.F.Name != f2.F.Name || ns(t1, f1.F) != ns(t2, f2.F) ||
+		lang(f1.F) != lang(f2.F) || strings.TrimSpace(f1.F.Body) != strings.TrimSpace(f2.F.Body)
+}
+
+// triggerChanges plans the trigger changes. Modified triggers are dropped and created again,
+// as CREATE OR REPLACE TRIGGER is not supported by all PostgreSQL versions.
+func (s *state) triggerChanges(changes []schema.Change) error {
+	for _, c := range changes {
+		var err error
+		switch c := c.(type) {
+		case *schema.AddTrigger:
+			err = s.addTrigger(c.T, c)
+		case *schema.DropTrigger:
+			err = s.dropTrigger(c)
+		case *schema.ModifyTrigger:
+			if err = s.dropTrigger(&schema.DropTrigger{T: c.From}); err == nil {
+				err = s.addTrigger(c.To, c)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTrigger appends the statements for creating the trigger and its function.
+func (s *state) addTrigger(t *schema.Trigger, source schema.Change) error {
+	var tf TriggerFunc
+	if !sqlx.Has(t.Attrs, &tf) {
+		return fmt.Errorf("postgres: missing function for trigger %q", t.Name)
+	}
+	if tf.F.Body != "" {
+		s.append(&migrate.Change{
+			Cmd:     s.triggerFuncDef(t, tf.F),
+			Source:  source,
+			Comment: fmt.Sprintf("create or replace function %q of trigger %q", tf.F.Name, t.Name),
+		})
+	}
+	cmd, err := s.triggerDef(t, tf.F)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Cmd:     cmd,
+		Source:  source,
+		Reverse: s.Build("DROP TRIGGER").Ident(t.Name).P("ON").Table(t.Table).String(),
+		Comment: fmt.Sprintf("create trigger %q on table %q", t.Name, t.Table.Name),
+	})
+	return nil
+}
+
+// dropTrigger appends the statement for dropping the trigger.
+func (s *state) dropTrigger(drop *schema.DropTrigger) error {
+	c := &migrate.Change{
+		Cmd:     s.Build("DROP TRIGGER").Ident(drop.T.Name).P("ON").Table(drop.T.Table).String(),
+		Source:  drop,
+		Comment: fmt.Sprintf("drop trigger %q from table %q", drop.T.Name, drop.T.Table.Name),
+	}
+	var tf TriggerFunc
+	if sqlx.Has(drop.T.Attrs, &tf) {
+		rv, err := s.triggerDef(drop.T, tf.F)
+		if err != nil {
+			return err
+		}
+		c.Reverse = rv
+	}
+	s.append(c)
+	return nil
+}
+
+// triggerDef returns the CREATE TRIGGER statement of the trigger.
+func (s *state) triggerDef(t *schema.Trigger, f *schema.Func) (string, error) {
+	if len(t.Events) == 0 {
+		return "", fmt.Errorf("postgres: missing events for trigger %q", t.Name)
+	}
+	b := s.Build("CREATE TRIGGER").Ident(t.Name).P(string(t.ActionTime))
+	for i, e := range t.Events {
+		if i > 0 {
+			b.P("OR")
+		}
+		b.P(string(e))
+	}
+	b.P("ON").Table(t.Table)
+	if t.For != "" {
+		b.P("FOR EACH", string(t.For))
+	}
+	return b.P("EXECUTE FUNCTION", s.funcIdent(t, f)+"()").String(), nil
+}
+
+// triggerFuncDef returns the CREATE OR REPLACE FUNCTION statement of the trigger function.
+func (s *state) triggerFuncDef(t *schema.Trigger, f *schema.Func) string {
+	lang, tag := f.Lang, "$$"
+	if lang == "" {
+		lang = "plpgsql"
+	}
+	if strings.Contains(f.Body, tag) {
+		tag = "$atlas$"
+	}
+	return s.Build("CREATE OR REPLACE FUNCTION").
+		P(s.funcIdent(t, f)+"()", "RETURNS trigger LANGUAGE", lang, "AS", tag+"\n"+strings.TrimSpace(f.Body)+"\n"+tag).
+		String()
+}
+
+// funcIdent returns the (optionally qualified) identifier of the trigger function.
+func (s *state) funcIdent(t *schema.Trigger, f *schema.Func) string {
+	return strings.TrimSpace(s.Build().Func(&schema.Func{Name: f.Name, Schema: funcSchema(t, f)}).String())
+}
+
+// funcSchema returns the schema of the trigger function. Functions without
+// a schema reside in the schema of the trigger table.
+func funcSchema(t *schema.Trigger, f *schema.Func) *schema.Schema {
+	if f.Schema == nil && t.Table != nil {
+		return t.Table.Schema
+	}
+	return f.Schema
+}
+
+// Query to list the triggers of the schema tables, and the functions they execute.
+const triggersQuery = `
+SELECT
+	n.nspname AS table_schema,
+	c.relname AS table_name,
+	t.tgname AS trigger_name,
+	t.tgtype AS trigger_type,
+	pn.nspname AS func_schema,
+	p.proname AS func_name,
+	l.lanname AS func_lang,
+	p.prosrc AS func_body
+FROM
+	pg_catalog.pg_trigger t
+	JOIN pg_catalog.pg_class c ON c.oid = t.tgrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_proc p ON p.oid = t.tgfoid
+	JOIN pg_catalog.pg_namespace pn ON pn.oid = p.pronamespace
+	JOIN pg_catalog.pg_language l ON l.oid = p.prolang
+WHERE
+	NOT t.tgisinternal
+	AND n.nspname IN (%s)
+ORDER BY
+	n.nspname, t.tgname
+`
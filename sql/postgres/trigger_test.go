@@ -0,0 +1,119 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect_Triggers(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(triggersQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ table_schema | table_name | trigger_name | trigger_type | func_schema | func_name | func_lang | func_body
+--------------+------------+--------------+--------------+-------------+-----------+-----------+-----------
+ public       | users      | t1           | 29           | public      | f1        | plpgsql   | BEGIN RETURN NULL; END;
+ public       | users      | t2           | 6            | util        | f2        | plpgsql   | BEGIN RETURN NEW; END;
+ public       | other      | t3           | 32           | public      | f3        | plpgsql   | BEGIN RETURN NULL; END;
+`))
+	users := schema.NewTable("users")
+	r := schema.NewRealm(schema.New("public").AddTables(users))
+	err = (&inspect{&conn{ExecQuerier: db}}).inspectTriggers(context.Background(), r)
+	require.NoError(t, err)
+	require.Equal(t, []*schema.Trigger{
+		{
+			Name:       "t1",
+			Table:      users,
+			ActionTime: schema.TriggerTimeAfter,
+			Events:     []schema.TriggerEvent{schema.TriggerEventInsert, schema.TriggerEventUpdate, schema.TriggerEventDelete},
+			For:        schema.TriggerForRow,
+			Attrs: []schema.Attr{
+				&TriggerFunc{F: &schema.Func{Name: "f1", Lang: "plpgsql", Body: "BEGIN RETURN NULL; END;", Ret: &schema.UnsupportedType{T: "trigger"}}},
+			},
+		},
+		{
+			Name:       "t2",
+			Table:      users,
+			ActionTime: schema.TriggerTimeBefore,
+			Events:     []schema.TriggerEvent{schema.TriggerEventInsert},
+			For:        schema.TriggerForStmt,
+			Attrs: []schema.Attr{
+				&TriggerFunc{F: &schema.Func{Name: "f2", Schema: &schema.Schema{Name: "util"}, Lang: "plpgsql", Body: "BEGIN RETURN NEW; END;", Ret: &schema.UnsupportedType{T: "trigger"}}},
+			},
+		},
+	}, users.Triggers)
+}
+
+func TestPlanChanges_Triggers(t *testing.T) {
+	var (
+		users = schema.NewTable("users").SetSchema(schema.New("public"))
+		tr1   = &schema.Trigger{
+			Name:       "users_audit",
+			ActionTime: schema.TriggerTimeAfter,
+			Events:     []schema.TriggerEvent{schema.TriggerEventInsert, schema.TriggerEventDelete},
+			For:        schema.TriggerForRow,
+			Attrs:      []schema.Attr{&TriggerFunc{F: &schema.Func{Name: "audit", Body: "BEGIN RETURN NULL; END;"}}},
+		}
+		tr2 = &schema.Trigger{
+			Name:       "users_audit",
+			ActionTime: schema.TriggerTimeAfter,
+			Events:     []schema.TriggerEvent{schema.TriggerEventInsert},
+			For:        schema.TriggerForStmt,
+			Attrs:      []schema.Attr{&TriggerFunc{F: &schema.Func{Name: "audit", Schema: schema.New("util"), Body: "BEGIN RETURN '$$'; END;"}}},
+		}
+	)
+	users.AddTriggers(tr1, tr2)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTrigger{From: tr1, To: tr2},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `DROP TRIGGER "users_audit" ON "public"."users"`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TRIGGER "users_audit" AFTER INSERT OR DELETE ON "public"."users" FOR EACH ROW EXECUTE FUNCTION "public"."audit"()`, plan.Changes[0].Reverse)
+	require.Equal(t, "CREATE OR REPLACE FUNCTION \"util\".\"audit\"() RETURNS trigger LANGUAGE plpgsql AS $atlas$\nBEGIN RETURN '$$'; END;\n$atlas$", plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE TRIGGER "users_audit" AFTER INSERT ON "public"."users" FOR EACH STATEMENT EXECUTE FUNCTION "util"."audit"()`, plan.Changes[2].Cmd)
+
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTrigger{T: &schema.Trigger{Name: "t", Table: users, Events: []schema.TriggerEvent{schema.TriggerEventInsert}}},
+	})
+	require.EqualError(t, err, `postgres: missing function for trigger "t"`)
+}
+
+func TestDiff_TriggerChanged(t *testing.T) {
+	var (
+		d     = &diff{conn: &conn{}}
+		users = schema.NewTable("users").SetSchema(schema.New("public"))
+		tr    = func(f *schema.Func) *schema.Trigger {
+			return &schema.Trigger{
+				Name:       "t",
+				Table:      users,
+				ActionTime: schema.TriggerTimeAfter,
+				Events:     []schema.TriggerEvent{schema.TriggerEventInsert},
+				For:        schema.TriggerForRow,
+				Attrs:      []schema.Attr{&TriggerFunc{F: f}},
+			}
+		}
+	)
+	// Bodies of triggers are ignored, and functions without a schema reside in the table schema.
+	t1, t2 := tr(&schema.Func{Name: "f", Body: "BEGIN RETURN NULL; END;"}), tr(&schema.Func{Name: "f", Schema: schema.New("public"), Lang: "PLPGSQL", Body: "\nBEGIN RETURN NULL; END;\n"})
+	t2.Body = "ignored"
+	require.False(t, d.TriggerChanged(t1, t2))
+	require.True(t, d.TriggerChanged(t1, tr(&schema.Func{Name: "f", Body: "BEGIN RETURN NEW; END;"})))
+	require.True(t, d.TriggerChanged(t1, tr(&schema.Func{Name: "f", Schema: schema.New("util"), Body: "BEGIN RETURN NULL; END;"})))
+	require.True(t, d.TriggerChanged(t1, tr(&schema.Func{Name: "g", Body: "BEGIN RETURN NULL; END;"})))
+	t2 = tr(&schema.Func{Name: "f", Body: "BEGIN RETURN NULL; END;"})
+	t2.For = schema.TriggerForStmt
+	require.True(t, d.TriggerChanged(t1, t2))
+}
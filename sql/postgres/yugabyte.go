@@ -0,0 +1,191 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Tablets describes the SPLIT INTO <N> TABLETS clause of hash-sharded tables and
+// indexes in YugabyteDB. The clause is applied only when the table or the index is
+// created, and since tablets may also be split automatically by the server, changes
+// to the number of tablets are not reported by the differ.
+type Tablets struct {
+	schema.Attr
+	N int
+}
+
+// List of index access methods used by YugabyteDB instead of the PostgreSQL ones.
+// On YugabyteDB, BTREE indexes are created as LSM indexes, and GIN indexes as YBGIN.
+const (
+	yugabyteIndexLSM   = "LSM"
+	yugabyteIndexYBGIN = "YBGIN"
+)
+
+// yugabyteVersion is the marker of YugabyteDB in the server_version setting.
+// For example, "11.2-YB-2.20.1.0-b0" or "15.2-YB-2024.2.0.0-b0".
+const yugabyteVersion = "-YB-"
+
+// yugabyteTables patches the inspected tables and indexes of the schema with
+// the YugabyteDB attributes, and normalizes its index access methods.
+func (i *inspect) yugabyteTables(ctx context.Context, s *schema.Schema) error {
+	for _, t := range s.Tables {
+		indexes := t.Indexes
+		if t.PrimaryKey != nil {
+			indexes = append(indexes[:len(indexes):len(indexes)], t.PrimaryKey)
+		}
+		for _, idx := range indexes {
+			if it := (&IndexType{}); sqlx.Has(idx.Attrs, it) {
+				switch strings.ToUpper(it.T) {
+				case yugabyteIndexLSM:
+					schema.ReplaceOrAppend(&idx.Attrs, &IndexType{T: IndexTypeBTree})
+				case yugabyteIndexYBGIN:
+					schema.ReplaceOrAppend(&idx.Attrs, &IndexType{T: IndexTypeGIN})
+				}
+			}
+		}
+	}
+	rows, err := i.querySchema(ctx, tabletsQuery, s)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q tablets: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			table string
+			index sql.NullString
+			n     int
+		)
+		if err := rows.Scan(&table, &index, &n); err != nil {
+			return fmt.Errorf("postgres: scanning tablets for schema %q: %w", s.Name, err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			return fmt.Errorf("postgres: table %q was not found in schema", table)
+		}
+		if !index.Valid {
+			t.AddAttrs(&Tablets{N: n})
+			continue
+		}
+		// Indexes that were not inspected (e.g., unique constraints
+		// of partitioned tables) are ignored.
+		if idx, ok := t.Index(index.String); ok {
+			idx.AddAttrs(&Tablets{N: n})
+		}
+	}
+	return rows.Err()
+}
+
+// verifyYugabyte checks that the changes do not use features that are not supported by YugabyteDB.
+func verifyYugabyte(changes []schema.Change) error {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			for _, idx := range c.T.Indexes {
+				if err := yugabyteIndex(idx); err != nil {
+					return err
+				}
+			}
+		case *schema.ModifyTable:
+			for _, c := range c.Changes {
+				switch c := c.(type) {
+				case *schema.AddIndex:
+					if err := yugabyteIndex(c.I); err != nil {
+						return err
+					}
+				case *schema.ModifyIndex:
+					if err := yugabyteIndex(c.To); err != nil {
+						return err
+					}
+				case *schema.DropIndex:
+					if sqlx.Has(c.Extra, &Concurrently{}) {
+						return fmt.Errorf("postgres: dropping index %q concurrently is not supported by YugabyteDB", c.I.Name)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// yugabyteIndex checks that the index access method is supported by YugabyteDB.
+func yugabyteIndex(idx *schema.Index) error {
+	t := &IndexType{T: IndexTypeBTree}
+	sqlx.Has(idx.Attrs, t)
+	switch strings.ToUpper(t.T) {
+	case IndexTypeBTree, IndexTypeGIN, yugabyteIndexLSM, yugabyteIndexYBGIN:
+		return nil
+	default:
+		return fmt.Errorf("postgres: index type %s of index %q is not supported by YugabyteDB", t.T, idx.Name)
+	}
+}
+
+// splitInto writes the SPLIT INTO clause of the table or the index, if exists.
+func splitInto(b *sqlx.Builder, attrs []schema.Attr) {
+	if t := (Tablets{}); sqlx.Has(attrs, &t) && t.N > 0 {
+		b.P(fmt.Sprintf("SPLIT INTO %d TABLETS", t.N))
+	}
+}
+
+// convertTablets converts the tablets attribute of a table or an index spec.
+func convertTablets(spec schemahcl.Resource, attrs *[]schema.Attr) error {
+	a, ok := spec.Attr("tablets")
+	if !ok {
+		return nil
+	}
+	n, err := a.Int()
+	if err != nil {
+		return fmt.Errorf("parsing attribute tablets: %w", err)
+	}
+	if n <= 0 {
+		return fmt.Errorf("unexpected number of tablets: %d", n)
+	}
+	*attrs = append(*attrs, &Tablets{N: n})
+	return nil
+}
+
+// fromTablets appends the tablets attribute to the spec, if exists.
+func fromTablets(attrs []schema.Attr, spec *schemahcl.Resource) {
+	if t := (Tablets{}); sqlx.Has(attrs, &t) && t.N > 0 {
+		spec.Attrs = append(spec.Attrs, schemahcl.IntAttr("tablets", t.N))
+	}
+}
+
+// Query to list the number of tablets of hash-sharded tables and their secondary
+// indexes. Colocated relations share a single tablet and are therefore excluded.
+const tabletsQuery = `
+SELECT
+	t.relname AS table_name,
+	NULL AS index_name,
+	p.num_tablets
+FROM
+	pg_catalog.pg_class AS t
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = t.relnamespace,
+	LATERAL yb_table_properties(t.oid) AS p
+WHERE
+	n.nspname = $1 AND t.relname IN (%[1]s) AND p.num_hash_key_columns > 0 AND NOT p.is_colocated
+UNION ALL
+SELECT
+	t.relname AS table_name,
+	c.relname AS index_name,
+	p.num_tablets
+FROM
+	pg_catalog.pg_index AS i
+	JOIN pg_catalog.pg_class AS t ON t.oid = i.indrelid
+	JOIN pg_catalog.pg_class AS c ON c.oid = i.indexrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = t.relnamespace,
+	LATERAL yb_table_properties(c.oid) AS p
+WHERE
+	n.nspname = $1 AND t.relname IN (%[1]s) AND NOT i.indisprimary AND p.num_hash_key_columns > 0 AND NOT p.is_colocated
+ORDER BY
+	table_name, index_name NULLS FIRST
+`
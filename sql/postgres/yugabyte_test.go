@@ -0,0 +1,166 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectYugabyte(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	m.ExpectQuery(sqltest.Escape(paramsQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "setting"}).
+			AddRow("server_version", "15.2-YB-2024.2.0.0-b0").
+			AddRow("server_version_num", "150002"))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	// Advisory locks are not supported by YugabyteDB.
+	_, ok := drv.(schema.Locker)
+	require.False(t, ok)
+	require.Equal(t, ProfileYugabyte, drv.(noLockDriver).noLocker.(*Driver).Profile())
+	require.False(t, ProfileYugabyte.Managed())
+
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "comment", "owner"}).AddRow("public", nil, nil))
+	mk.tableExists("public", "events", true)
+	mk.ExpectQuery(queryColumns).
+		WithArgs("public", "events").
+		WillReturnRows(sqltest.Rows(`
+ table_name | column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment | identity_last | identity_generation | generation_expression | comment | typtype | typelem | elemtyp | oid
+------------+-------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+---------------+---------------------+-----------------------+---------+---------+---------+---------+-----
+ events     | id          | bigint    | int8      | NO          |                |                          | 64                |                    | 0             |               |                    |                | NO          |                |                    |               |                     |                       |         | b       |         |         | 20
+ events     | kind        | text      | text      | NO          |                |                          |                   |                    |               |               |                    |                | NO          |                |                    |               |                     |                       |         | b       |         |         | 25
+`))
+	m.ExpectQuery(queryIndexes).
+		WithArgs("public", "events").
+		WillReturnRows(sqltest.Rows(`
+ table_name | index_name  | index_type | column_name | included | primary | unique | constraints          | predicate | expression | desc | nulls_first | nulls_last | comment | options | opclass_name | opclass_default | opclass_params | indnullsnotdistinct
+------------+-------------+------------+-------------+----------+---------+--------+----------------------+-----------+------------+------+-------------+------------+---------+---------+--------------+-----------------+----------------+---------------------
+ events     | events_pkey | lsm        | id          | f        | t       | t      | {"events_pkey": "p"} |           | id         | f    | f           | f          |         |         | int8_ops     | t               |                | f
+ events     | events_kind | lsm        | kind        | f        | f       | f      |                      |           | kind       | f    | f           | f          |         |         | text_ops     | t               |                | f
+`))
+	mk.noFKs()
+	mk.noChecks()
+	mk.noLabels()
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tabletsQuery, "$2"))).
+		WithArgs("public", "events").
+		WillReturnRows(sqltest.Rows(`
+ table_name | index_name  | num_tablets
+------------+-------------+-------------
+ events     |             | 16
+ events     | events_kind | 4
+`))
+	mk.noEnums()
+	s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{
+		Mode: schema.InspectSchemas | schema.InspectTables,
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	events := s.Tables[0]
+	require.Equal(t, []schema.Attr{&Tablets{N: 16}}, events.Attrs)
+	require.True(t, sqlx.Has(events.PrimaryKey.Attrs, &IndexType{T: IndexTypeBTree}))
+	idx, ok := events.Index("events_kind")
+	require.True(t, ok)
+	require.True(t, sqlx.Has(idx.Attrs, &IndexType{T: IndexTypeBTree}))
+	require.True(t, sqlx.Has(idx.Attrs, &Tablets{N: 4}))
+
+	// Tablets are applied only on creation, and LSM indexes are BTREE indexes.
+	to := schema.NewTable("events").SetSchema(schema.New("public")).AddColumns(events.Columns...)
+	to.SetPrimaryKey(schema.NewPrimaryKey(events.Columns[0]))
+	to.AddIndexes(schema.NewIndex("events_kind").AddColumns(events.Columns[1]).AddAttrs(&Tablets{N: 8}))
+	changes, err := drv.TableDiff(events, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_Yugabyte(t *testing.T) {
+	drv := &planApply{conn: &conn{ExecQuerier: sqlx.NoRows, profile: ProfileYugabyte}}
+	events := schema.NewTable("events").
+		SetSchema(schema.New("public")).
+		AddColumns(
+			schema.NewIntColumn("id", TypeBigInt),
+			schema.NewStringColumn("kind", "text"),
+		).
+		AddAttrs(&Tablets{N: 16})
+	events.SetPrimaryKey(schema.NewPrimaryKey(events.Columns[0]))
+	events.AddIndexes(schema.NewIndex("events_kind").AddColumns(events.Columns[1]).AddAttrs(&Tablets{N: 4}))
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: events}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`CREATE TABLE "public"."events" ("id" bigint NOT NULL, "kind" text NOT NULL, PRIMARY KEY ("id")) SPLIT INTO 16 TABLETS`,
+		`CREATE INDEX "events_kind" ON "public"."events" ("kind") SPLIT INTO 4 TABLETS`,
+	}, cmds(plan))
+
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: events, Changes: []schema.Change{
+			&schema.AddIndex{I: schema.NewIndex("events_brin").AddColumns(events.Columns[0]).AddAttrs(&IndexType{T: IndexTypeBRIN})},
+		}},
+	})
+	require.EqualError(t, err, `postgres: index type BRIN of index "events_brin" is not supported by YugabyteDB`)
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: events, Changes: []schema.Change{
+			&schema.DropIndex{I: events.Indexes[0], Extra: []schema.Clause{&Concurrently{}}},
+		}},
+	})
+	require.EqualError(t, err, `postgres: dropping index "events_kind" concurrently is not supported by YugabyteDB`)
+}
+
+func TestSQLSpec_Yugabyte(t *testing.T) {
+	f := `
+schema "public" {}
+table "events" {
+	schema = schema.public
+	column "id" {
+		type = bigint
+	}
+	column "kind" {
+		type = text
+	}
+	primary_key {
+		columns = [column.id]
+	}
+	index "events_kind" {
+		columns = [column.kind]
+		tablets = 4
+	}
+	tablets = 16
+}
+`
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	require.Equal(t, []schema.Attr{&Tablets{N: 16}}, s.Tables[0].Attrs)
+	require.Equal(t, []schema.Attr{&Tablets{N: 4}}, s.Tables[0].Indexes[0].Attrs)
+
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	var s2 schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &s2, nil))
+	require.Equal(t, s.Tables[0].Attrs, s2.Tables[0].Attrs)
+	require.Equal(t, s.Tables[0].Indexes[0].Attrs, s2.Tables[0].Indexes[0].Attrs)
+
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+table "events" {
+	schema = schema.public
+	column "id" {
+		type = bigint
+	}
+	tablets = 0
+}
+`), &s, nil)
+	require.ErrorContains(t, err, "unexpected number of tablets: 0")
+}
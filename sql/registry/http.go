@@ -0,0 +1,284 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// The HTTP API of the registry. Names, references and tags are path-escaped.
+//
+//	POST /v1/snapshots			pushes the snapshot in the body.
+//	GET  /v1/snapshots/{name}		lists the snapshots of the given name.
+//	GET  /v1/snapshots/{name}/{ref}		pulls the snapshot that matches the reference.
+//	PUT  /v1/snapshots/{name}/tags/{tag}	tags the version in the body ({"version": "..."}).
+const apiPath = "/v1/snapshots"
+
+// NewHandler returns an http.Handler that serves the given registry over HTTP. Note, the
+// handler does not authenticate requests, and should be wrapped by an authenticating handler.
+func NewHandler(r Registry) http.Handler {
+	return &handler{r: r}
+}
+
+type handler struct {
+	r Registry
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, ok := strings.CutPrefix(r.URL.EscapedPath(), apiPath)
+	if !ok || path != "" && !strings.HasPrefix(path, "/") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("path %q was not found", r.URL.Path))
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i := range parts {
+		p, err := url.PathUnescape(parts[i])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		parts[i] = p
+	}
+	ctx := r.Context()
+	switch {
+	case r.Method == http.MethodPost && path == "":
+		var s Snapshot
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := h.r.Push(ctx, &s); err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] != "":
+		snaps, err := h.r.List(ctx, parts[0])
+		if err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		writeJSON(w, snaps)
+	case r.Method == http.MethodGet && len(parts) == 2:
+		s, err := h.r.Pull(ctx, parts[0], parts[1])
+		if err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		writeJSON(w, s)
+	case r.Method == http.MethodPut && len(parts) == 3 && parts[1] == "tags":
+		var req tagRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := validTag(parts[2]); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := h.r.Tag(ctx, parts[0], req.Version, parts[2]); err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s %s was not found", r.Method, r.URL.Path))
+	}
+}
+
+type tagRequest struct {
+	Version string `json:"version"`
+}
+
+type (
+	// Client is a Registry that is backed by a registry served over HTTP. See NewHandler.
+	Client struct {
+		url    string
+		client *http.Client
+		header http.Header
+	}
+
+	// ClientOption configures a Client.
+	ClientOption func(*Client) error
+)
+
+// NewClient returns a Client for the registry served at the given URL.
+func NewClient(u string, opts ...ClientOption) (*Client, error) {
+	if u == "" {
+		return nil, errors.New("registry: missing registry url")
+	}
+	c := &Client{
+		url:    strings.TrimSuffix(u, "/"),
+		client: http.DefaultClient,
+		header: make(http.Header),
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// WithHeader sets a header on the registry requests. For example, an authorization header.
+func WithHeader(k, v string) ClientOption {
+	return func(c *Client) error {
+		c.header.Set(k, v)
+		return nil
+	}
+}
+
+// WithHTTPClient configures the HTTP client used for sending the requests.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) error {
+		c.client = hc
+		return nil
+	}
+}
+
+// Push implements the Registry interface.
+func (c *Client) Push(ctx context.Context, s *Snapshot) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, apiPath, s, nil)
+}
+
+// Pull implements the Registry interface.
+func (c *Client) Pull(ctx context.Context, name, ref string) (*Snapshot, error) {
+	if ref == "" {
+		ref = Latest
+	}
+	var s Snapshot
+	if err := c.do(ctx, http.MethodGet, c.path(name, ref), nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List implements the Registry interface.
+func (c *Client) List(ctx context.Context, name string) ([]*Snapshot, error) {
+	var snaps []*Snapshot
+	if err := c.do(ctx, http.MethodGet, c.path(name), nil, &snaps); err != nil {
+		return nil, err
+	}
+	return snaps, nil
+}
+
+// Tag implements the Registry interface.
+func (c *Client) Tag(ctx context.Context, name, version, tag string) error {
+	if err := validTag(tag); err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPut, c.path(name, "tags", tag), &tagRequest{Version: version}, nil)
+}
+
+// path returns the API path of the given (escaped) elements.
+func (*Client) path(elems ...string) string {
+	for i := range elems {
+		elems[i] = url.PathEscape(elems[i])
+	}
+	return apiPath + "/" + strings.Join(elems, "/")
+}
+
+// do sends the request, and decodes the response body into v, if it is not nil.
+func (c *Client) do(ctx context.Context, method, path string, body, v any) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, r)
+	if err != nil {
+		return fmt.Errorf("registry: create request: %w", err)
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry: send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		if json.Unmarshal(b, &e) != nil || e.Error == "" {
+			e.Error = string(bytes.TrimSpace(b))
+		}
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return wrapError(ErrNotExist, e.Error)
+		case http.StatusConflict:
+			return wrapError(ErrVersionExists, e.Error)
+		default:
+			return fmt.Errorf("registry: unexpected status %d: %s", resp.StatusCode, e.Error)
+		}
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("registry: decode response: %w", err)
+	}
+	return nil
+}
+
+// errorCode returns the HTTP status code of the given registry error.
+func errorCode(err error) int {
+	switch {
+	case errors.Is(err, ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, ErrVersionExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// wrapError wraps the sentinel error with the error message returned
+// by the server, which may already start with the sentinel message.
+func wrapError(sentinel error, msg string) error {
+	if rest, ok := strings.CutPrefix(msg, sentinel.Error()); ok {
+		return fmt.Errorf("%w%s", sentinel, rest)
+	}
+	return fmt.Errorf("%w: %s", sentinel, msg)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+}
+
+var _ Registry = (*Client)(nil)
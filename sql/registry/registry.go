@@ -0,0 +1,256 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package registry provides a schema registry for publishing inspected schemas as versioned
+// snapshots, allowing services to consume the latest approved schema of other teams. For
+// example, a team publishes the schema of its database, and tags it once it was approved:
+//
+//	s, err := registry.Publish(ctx, r, client, "billing")
+//	if err != nil {
+//		return err
+//	}
+//	err = r.Tag(ctx, "billing", s.Version, "approved")
+//
+// And other services pull the approved schema and evaluate it:
+//
+//	s, err := r.Pull(ctx, "billing", "approved")
+//	if err != nil {
+//		return err
+//	}
+//	realm, err := s.Realm(postgres.EvalHCL)
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// Latest is the reference of the most recently pushed snapshot.
+const Latest = "latest"
+
+var (
+	// ErrNotExist is returned when a snapshot does not exist in the registry.
+	ErrNotExist = errors.New("registry: snapshot does not exist")
+
+	// ErrVersionExists is returned when a snapshot is pushed with
+	// a version that already exists with a different content.
+	ErrVersionExists = errors.New("registry: snapshot version already exists")
+)
+
+type (
+	// Registry stores versioned snapshots of schemas.
+	Registry interface {
+		// Push stores the given snapshot. Versions are immutable, and therefore, pushing an
+		// existing version with a different content fails with ErrVersionExists. The tags of
+		// the snapshot are moved to its version, if they are attached to other versions.
+		Push(context.Context, *Snapshot) error
+
+		// Pull returns the snapshot of the given name that matches the reference. A reference is
+		// either a version or a tag. An empty reference, or Latest, returns the most recently
+		// pushed snapshot. ErrNotExist is returned if no snapshot matches the reference.
+		Pull(ctx context.Context, name, ref string) (*Snapshot, error)
+
+		// List returns the snapshots of the given name, ordered by their push time.
+		List(ctx context.Context, name string) ([]*Snapshot, error)
+
+		// Tag attaches the tag to the given version, and detaches it from other versions.
+		Tag(ctx context.Context, name, version, tag string) error
+	}
+
+	// Snapshot is a versioned snapshot of a schema (realm), stored as an HCL document.
+	Snapshot struct {
+		Name    string    `json:"name"`
+		Version string    `json:"version"`
+		Tags    []string  `json:"tags,omitempty"`
+		Driver  string    `json:"driver,omitempty"`
+		HCL     string    `json:"hcl"`
+		Created time.Time `json:"created"`
+	}
+)
+
+// Realm evaluates the HCL document of the snapshot using the given evaluator. For
+// example, postgres.EvalHCL for snapshots that were published by PostgreSQL clients.
+func (s *Snapshot) Realm(ev schemahcl.Evaluator) (*schema.Realm, error) {
+	p := hclparse.NewParser()
+	if _, diags := p.ParseHCL([]byte(s.HCL), s.Name+".hcl"); diags.HasErrors() {
+		return nil, fmt.Errorf("registry: parse snapshot %s@%s: %w", s.Name, s.Version, diags)
+	}
+	var r schema.Realm
+	if err := ev.Eval(p, &r, nil); err != nil {
+		return nil, fmt.Errorf("registry: evaluate snapshot %s@%s: %w", s.Name, s.Version, err)
+	}
+	return &r, nil
+}
+
+// Publish inspects the database of the client and pushes its schema to the registry under the
+// given name and tags. The snapshot version is computed from the inspected schema, and therefore,
+// publishing a schema that was not changed since it was published returns the existing version.
+func Publish(ctx context.Context, r Registry, c *sqlclient.Client, name string, tags ...string) (*Snapshot, error) {
+	st, err := c.ReadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{
+		Name:    name,
+		Version: st.Version,
+		Tags:    tags,
+		Driver:  c.Name,
+		HCL:     st.HCL,
+		Created: time.Now(),
+	}
+	if err := r.Push(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// validate reports if the snapshot can be pushed to a registry.
+func (s *Snapshot) validate() error {
+	switch {
+	case s.Name == "":
+		return errors.New("registry: missing snapshot name")
+	case s.Version == "":
+		return errors.New("registry: missing snapshot version")
+	case s.Version == Latest:
+		return fmt.Errorf("registry: %q is a reserved version", Latest)
+	}
+	for _, t := range s.Tags {
+		if err := validTag(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validTag reports if the tag can be attached to snapshots.
+func validTag(t string) error {
+	if t == "" || t == Latest {
+		return fmt.Errorf("registry: invalid tag %q", t)
+	}
+	return nil
+}
+
+// MemRegistry provides an in-memory Registry implementation.
+type MemRegistry struct {
+	mu    sync.RWMutex
+	snaps map[string][]*Snapshot       // Snapshots by name, ordered by push time.
+	tags  map[string]map[string]string // Tag to version, by name.
+}
+
+// Push implements the Registry interface.
+func (r *MemRegistry) Push(_ context.Context, s *Snapshot) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.snaps == nil {
+		r.snaps = make(map[string][]*Snapshot)
+		r.tags = make(map[string]map[string]string)
+	}
+	if cur := r.version(s.Name, s.Version); cur != nil {
+		if cur.HCL != s.HCL {
+			return fmt.Errorf("%w: %s@%s", ErrVersionExists, s.Name, s.Version)
+		}
+	} else {
+		c := *s
+		c.Tags = nil
+		if c.Created.IsZero() {
+			c.Created = time.Now()
+		}
+		r.snaps[s.Name] = append(r.snaps[s.Name], &c)
+	}
+	for _, t := range s.Tags {
+		r.tag(s.Name, s.Version, t)
+	}
+	return nil
+}
+
+// Pull implements the Registry interface.
+func (r *MemRegistry) Pull(_ context.Context, name, ref string) (*Snapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var s *Snapshot
+	switch snaps := r.snaps[name]; {
+	case len(snaps) == 0:
+	case ref == "" || ref == Latest:
+		s = snaps[len(snaps)-1]
+	default:
+		if s = r.version(name, ref); s == nil {
+			if v, ok := r.tags[name][ref]; ok {
+				s = r.version(name, v)
+			}
+		}
+	}
+	if s == nil {
+		return nil, fmt.Errorf("%w: %s@%s", ErrNotExist, name, ref)
+	}
+	return r.snapshot(s), nil
+}
+
+// List implements the Registry interface.
+func (r *MemRegistry) List(_ context.Context, name string) ([]*Snapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	snaps := make([]*Snapshot, 0, len(r.snaps[name]))
+	for _, s := range r.snaps[name] {
+		snaps = append(snaps, r.snapshot(s))
+	}
+	return snaps, nil
+}
+
+// Tag implements the Registry interface.
+func (r *MemRegistry) Tag(_ context.Context, name, version, tag string) error {
+	if err := validTag(tag); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.version(name, version) == nil {
+		return fmt.Errorf("%w: %s@%s", ErrNotExist, name, version)
+	}
+	r.tag(name, version, tag)
+	return nil
+}
+
+// version returns the snapshot of the given version, or nil if it does not exist.
+func (r *MemRegistry) version(name, version string) *Snapshot {
+	for _, s := range r.snaps[name] {
+		if s.Version == version {
+			return s
+		}
+	}
+	return nil
+}
+
+func (r *MemRegistry) tag(name, version, tag string) {
+	if r.tags[name] == nil {
+		r.tags[name] = make(map[string]string)
+	}
+	r.tags[name][tag] = version
+}
+
+// snapshot returns a copy of the stored snapshot with its tags.
+func (r *MemRegistry) snapshot(s *Snapshot) *Snapshot {
+	c := *s
+	for t, v := range r.tags[s.Name] {
+		if v == s.Version {
+			c.Tags = append(c.Tags, t)
+		}
+	}
+	sort.Strings(c.Tags)
+	return &c
+}
+
+var _ Registry = (*MemRegistry)(nil)
@@ -0,0 +1,169 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package registry_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/registry"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemRegistry(t *testing.T) {
+	runRegistryTests(t, &registry.MemRegistry{})
+}
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(registry.NewHandler(&registry.MemRegistry{}))
+	defer srv.Close()
+	c, err := registry.NewClient(srv.URL)
+	require.NoError(t, err)
+	runRegistryTests(t, c)
+
+	_, err = registry.NewClient("")
+	require.EqualError(t, err, "registry: missing registry url")
+
+	// Headers are sent with the requests.
+	var auth string
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": "invalid token"}`))
+	}))
+	defer srv.Close()
+	c, err = registry.NewClient(srv.URL, registry.WithHeader("Authorization", "Bearer token"))
+	require.NoError(t, err)
+	_, err = c.List(context.Background(), "billing")
+	require.EqualError(t, err, "registry: unexpected status 401: invalid token")
+	require.Equal(t, "Bearer token", auth)
+}
+
+func runRegistryTests(t *testing.T, r registry.Registry) {
+	ctx := context.Background()
+	_, err := r.Pull(ctx, "billing", "")
+	require.ErrorIs(t, err, registry.ErrNotExist)
+	snaps, err := r.List(ctx, "billing")
+	require.NoError(t, err)
+	require.Empty(t, snaps)
+
+	require.EqualError(t, r.Push(ctx, &registry.Snapshot{Version: "v1"}), "registry: missing snapshot name")
+	require.EqualError(t, r.Push(ctx, &registry.Snapshot{Name: "billing"}), "registry: missing snapshot version")
+	require.EqualError(t, r.Push(ctx, &registry.Snapshot{Name: "billing", Version: "v1", Tags: []string{"latest"}}), `registry: invalid tag "latest"`)
+
+	require.NoError(t, r.Push(ctx, &registry.Snapshot{Name: "billing", Version: "v1", HCL: "schema \"main\" {}", Tags: []string{"approved"}}))
+	require.NoError(t, r.Push(ctx, &registry.Snapshot{Name: "billing", Version: "v2", HCL: "schema \"public\" {}"}))
+	// Pushing an existing version is allowed only with the same content.
+	require.NoError(t, r.Push(ctx, &registry.Snapshot{Name: "billing", Version: "v2", HCL: "schema \"public\" {}", Tags: []string{"staging"}}))
+	err = r.Push(ctx, &registry.Snapshot{Name: "billing", Version: "v2", HCL: "schema \"other\" {}"})
+	require.ErrorIs(t, err, registry.ErrVersionExists)
+	require.EqualError(t, err, "registry: snapshot version already exists: billing@v2")
+
+	s, err := r.Pull(ctx, "billing", "")
+	require.NoError(t, err)
+	require.Equal(t, "v2", s.Version)
+	require.Equal(t, []string{"staging"}, s.Tags)
+	require.False(t, s.Created.IsZero())
+	s, err = r.Pull(ctx, "billing", registry.Latest)
+	require.NoError(t, err)
+	require.Equal(t, "v2", s.Version)
+	s, err = r.Pull(ctx, "billing", "approved")
+	require.NoError(t, err)
+	require.Equal(t, "v1", s.Version)
+	require.Equal(t, "schema \"main\" {}", s.HCL)
+	s, err = r.Pull(ctx, "billing", "v1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"approved"}, s.Tags)
+	_, err = r.Pull(ctx, "billing", "v3")
+	require.ErrorIs(t, err, registry.ErrNotExist)
+	require.EqualError(t, err, "registry: snapshot does not exist: billing@v3")
+
+	// Tags are moved between versions.
+	require.NoError(t, r.Tag(ctx, "billing", "v2", "approved"))
+	s, err = r.Pull(ctx, "billing", "approved")
+	require.NoError(t, err)
+	require.Equal(t, "v2", s.Version)
+	require.Equal(t, []string{"approved", "staging"}, s.Tags)
+	require.ErrorIs(t, r.Tag(ctx, "billing", "v3", "approved"), registry.ErrNotExist)
+	require.EqualError(t, r.Tag(ctx, "billing", "v1", registry.Latest), `registry: invalid tag "latest"`)
+
+	snaps, err = r.List(ctx, "billing")
+	require.NoError(t, err)
+	require.Len(t, snaps, 2)
+	require.Equal(t, "v1", snaps[0].Version)
+	require.Empty(t, snaps[0].Tags)
+	require.Equal(t, "v2", snaps[1].Version)
+
+	// Names are escaped.
+	require.NoError(t, r.Push(ctx, &registry.Snapshot{Name: "team/billing", Version: "v1"}))
+	s, err = r.Pull(ctx, "team/billing", "v1")
+	require.NoError(t, err)
+	require.Equal(t, "team/billing", s.Name)
+}
+
+func TestPublish(t *testing.T) {
+	var (
+		ctx = context.Background()
+		r   = &registry.MemRegistry{}
+		drv = &inspectDriver{
+			realm: schema.NewRealm(
+				schema.New("main").AddTables(
+					schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")),
+				),
+			),
+		}
+		c = &sqlclient.Client{
+			Name:      "sqlite3",
+			URL:       &sqlclient.URL{URL: &url.URL{Scheme: "sqlite"}},
+			Driver:    drv,
+			Marshaler: sqlite.MarshalHCL,
+			Evaluator: sqlite.EvalHCL,
+		}
+	)
+	s1, err := registry.Publish(ctx, r, c, "billing", "approved")
+	require.NoError(t, err)
+	require.NotEmpty(t, s1.Version)
+	require.Equal(t, "sqlite3", s1.Driver)
+	require.Contains(t, s1.HCL, `table "users"`)
+
+	// Publishing an unchanged schema returns the existing version.
+	s2, err := registry.Publish(ctx, r, c, "billing")
+	require.NoError(t, err)
+	require.Equal(t, s1.Version, s2.Version)
+	snaps, err := r.List(ctx, "billing")
+	require.NoError(t, err)
+	require.Len(t, snaps, 1)
+
+	s, err := r.Pull(ctx, "billing", "approved")
+	require.NoError(t, err)
+	realm, err := s.Realm(sqlite.EvalHCL)
+	require.NoError(t, err)
+	require.Len(t, realm.Schemas, 1)
+	users, ok := realm.Schemas[0].Table("users")
+	require.True(t, ok)
+	require.Len(t, users.Columns, 1)
+	require.Equal(t, "id", users.Columns[0].Name)
+
+	_, err = (&registry.Snapshot{Name: "billing", Version: "v1", HCL: "schema {"}).Realm(sqlite.EvalHCL)
+	require.ErrorContains(t, err, "registry: parse snapshot billing@v1")
+}
+
+// inspectDriver is a migrate.Driver that returns the given realm on inspection.
+type inspectDriver struct {
+	migrate.Driver
+	realm *schema.Realm
+}
+
+func (d *inspectDriver) InspectRealm(context.Context, *schema.InspectRealmOption) (*schema.Realm, error) {
+	return d.realm, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AnonymizeOptions describes the metadata that is anonymized on inspection, allowing
+// production schemas to be shared with third parties or committed publicly. Anonymized
+// values are stripped, or replaced with a hash of their value if Hash is set. Hashing
+// keeps the schema comparable, as equal values are replaced with equal hashes.
+type AnonymizeOptions struct {
+	// Comments anonymizes the comments of all resources.
+	Comments bool
+
+	// Defaults anonymizes the literal default values of columns. In case Hash is
+	// set, string literals are replaced with a hash string, and other literals
+	// (e.g., numbers) are stripped.
+	Defaults bool
+
+	// Checks is a list of regular expressions. Check constraints whose
+	// expressions match one of them are anonymized.
+	Checks []string
+
+	// Hash replaces anonymized values with their hash, instead of stripping them.
+	Hash bool
+}
+
+// AnonymizeRealm anonymizes the given realm in place, according to the given
+// options. A nil AnonymizeOptions leaves the realm unchanged.
+func AnonymizeRealm(r *Realm, opts *AnonymizeOptions) error {
+	a, err := newAnonymizer(opts)
+	if err != nil || a == nil {
+		return err
+	}
+	r.Attrs = a.attrs(r.Attrs)
+	for _, s := range r.Schemas {
+		a.schema(s)
+	}
+	return nil
+}
+
+// AnonymizeSchema anonymizes the given schema in place, according to the given
+// options. A nil AnonymizeOptions leaves the schema unchanged.
+func AnonymizeSchema(s *Schema, opts *AnonymizeOptions) error {
+	a, err := newAnonymizer(opts)
+	if err != nil || a == nil {
+		return err
+	}
+	a.schema(s)
+	return nil
+}
+
+// anonymizer applies the AnonymizeOptions on schema resources.
+type anonymizer struct {
+	*AnonymizeOptions
+	checks []*regexp.Regexp
+}
+
+func newAnonymizer(opts *AnonymizeOptions) (*anonymizer, error) {
+	if opts == nil {
+		return nil, nil
+	}
+	a := &anonymizer{AnonymizeOptions: opts}
+	for _, p := range opts.Checks {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("schema: invalid check pattern %q: %w", p, err)
+		}
+		a.checks = append(a.checks, re)
+	}
+	return a, nil
+}
+
+func (a *anonymizer) schema(s *Schema) {
+	s.Attrs = a.attrs(s.Attrs)
+	for _, t := range s.Tables {
+		t.Attrs = a.attrs(t.Attrs)
+		for _, c := range t.Columns {
+			a.column(c)
+		}
+		for _, idx := range t.Indexes {
+			idx.Attrs = a.attrs(idx.Attrs)
+		}
+		if t.PrimaryKey != nil {
+			t.PrimaryKey.Attrs = a.attrs(t.PrimaryKey.Attrs)
+		}
+	}
+	for _, v := range s.Views {
+		v.Attrs = a.attrs(v.Attrs)
+		for _, c := range v.Columns {
+			a.column(c)
+		}
+		for _, idx := range v.Indexes {
+			idx.Attrs = a.attrs(idx.Attrs)
+		}
+	}
+	for _, f := range s.Funcs {
+		f.Attrs = a.attrs(f.Attrs)
+	}
+	for _, p := range s.Procs {
+		p.Attrs = a.attrs(p.Attrs)
+	}
+}
+
+func (a *anonymizer) column(c *Column) {
+	c.Attrs = a.attrs(c.Attrs)
+	if x, ok := c.Default.(*Literal); ok && a.Defaults {
+		switch {
+		case a.Hash && len(x.V) > 1 && strings.HasPrefix(x.V, "'") && strings.HasSuffix(x.V, "'"):
+			c.Default = &Literal{V: "'" + a.hash(x.V) + "'"}
+		default:
+			c.Default = nil
+		}
+	}
+}
+
+// attrs returns the attributes after anonymizing their comments and checks.
+func (a *anonymizer) attrs(attrs []Attr) []Attr {
+	anonymized := attrs[:0]
+	for _, at := range attrs {
+		switch at := at.(type) {
+		case *Comment:
+			switch {
+			case !a.Comments:
+			case a.Hash:
+				at.Text = a.hash(at.Text)
+			default:
+				continue
+			}
+		case *Check:
+			switch {
+			case !a.matchCheck(at.Expr):
+			case a.Hash:
+				// A valid boolean expression that
+				// is unique for each check expression.
+				at.Expr = fmt.Sprintf("'%s' <> ''", a.hash(at.Expr))
+			default:
+				continue
+			}
+		}
+		anonymized = append(anonymized, at)
+	}
+	return anonymized
+}
+
+func (a *anonymizer) matchCheck(expr string) bool {
+	for _, re := range a.checks {
+		if re.MatchString(expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// hash returns the anonymized form of the given value.
+func (*anonymizer) hash(v string) string {
+	h := sha256.Sum256([]byte(v))
+	return "anon_" + hex.EncodeToString(h[:8])
+}
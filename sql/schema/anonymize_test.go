@@ -0,0 +1,66 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymizeRealm(t *testing.T) {
+	newRealm := func() *schema.Realm {
+		return schema.NewRealm(
+			schema.New("public").
+				SetComment("payments schema").
+				AddTables(
+					schema.NewTable("users").
+						SetComment("customers of acme corp").
+						AddColumns(
+							schema.NewIntColumn("age", "int").SetDefault(&schema.Literal{V: "18"}),
+							schema.NewStringColumn("ssn", "text").
+								SetDefault(&schema.Literal{V: "'000-00-0000'"}).
+								SetComment("social security number"),
+							schema.NewStringColumn("created", "text").SetDefault(&schema.RawExpr{X: "now()"}),
+						).
+						AddChecks(
+							schema.NewCheck().SetName("ssn_valid").SetExpr("ssn <> '123-45-6789'"),
+							schema.NewCheck().SetName("age_valid").SetExpr("age > 0"),
+						),
+				),
+		)
+	}
+	r := newRealm()
+	require.NoError(t, schema.AnonymizeRealm(r, nil))
+	require.Equal(t, newRealm(), r, "nil options do not modify the realm")
+
+	require.NoError(t, schema.AnonymizeRealm(r, &schema.AnonymizeOptions{Comments: true, Defaults: true, Checks: []string{`\d{3}-\d{2}-\d{4}`}}))
+	s := r.Schemas[0]
+	require.Empty(t, s.Attrs)
+	users := s.Tables[0]
+	require.Len(t, users.Attrs, 1)
+	require.Equal(t, "age_valid", users.Attrs[0].(*schema.Check).Name)
+	require.Nil(t, users.Columns[0].Default)
+	require.Nil(t, users.Columns[1].Default)
+	require.Empty(t, users.Columns[1].Attrs)
+	require.Equal(t, &schema.RawExpr{X: "now()"}, users.Columns[2].Default, "raw expressions are kept")
+
+	r, r2 := newRealm(), newRealm()
+	opts := &schema.AnonymizeOptions{Comments: true, Defaults: true, Checks: []string{"ssn"}, Hash: true}
+	require.NoError(t, schema.AnonymizeRealm(r, opts))
+	require.NoError(t, schema.AnonymizeRealm(r2, opts))
+	require.Equal(t, r2, r, "hashing is deterministic")
+	users = r.Schemas[0].Tables[0]
+	require.Equal(t, "anon_d3d49bf2b77fce27", users.Attrs[0].(*schema.Comment).Text)
+	require.Regexp(t, `^'anon_[0-9a-f]{16}' <> ''$`, users.Attrs[1].(*schema.Check).Expr)
+	require.Equal(t, "age > 0", users.Attrs[2].(*schema.Check).Expr)
+	require.Nil(t, users.Columns[0].Default, "non-string literals are stripped")
+	require.Regexp(t, `^'anon_[0-9a-f]{16}'$`, users.Columns[1].Default.(*schema.Literal).V)
+
+	err := schema.AnonymizeSchema(schema.New("public"), &schema.AnonymizeOptions{Checks: []string{"("}})
+	require.EqualError(t, err, "schema: invalid check pattern \"(\": error parsing regexp: missing closing ): `(`")
+}
@@ -24,9 +24,10 @@ func _() {
 	_ = x[ChangeRefTable-4096]
 	_ = x[ChangeUpdateAction-8192]
 	_ = x[ChangeDeleteAction-16384]
+	_ = x[ChangePosition-32768]
 }
 
-const _ChangeKind_name = "NoChangeChangeAttrChangeCharsetChangeCollateChangeCommentChangeNullChangeTypeChangeDefaultChangeGeneratedChangeUniqueChangePartsChangeColumnChangeRefColumnChangeRefTableChangeUpdateActionChangeDeleteAction"
+const _ChangeKind_name = "NoChangeChangeAttrChangeCharsetChangeCollateChangeCommentChangeNullChangeTypeChangeDefaultChangeGeneratedChangeUniqueChangePartsChangeColumnChangeRefColumnChangeRefTableChangeUpdateActionChangeDeleteActionChangePosition"
 
 var _ChangeKind_map = map[ChangeKind]string{
 	0:     _ChangeKind_name[0:8],
@@ -45,6 +46,7 @@ var _ChangeKind_map = map[ChangeKind]string{
 	4096:  _ChangeKind_name[155:169],
 	8192:  _ChangeKind_name[169:187],
 	16384: _ChangeKind_name[187:205],
+	32768: _ChangeKind_name[205:219],
 }
 
 func (i ChangeKind) String() string {
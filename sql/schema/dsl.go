@@ -238,6 +238,15 @@ func (t *Table) AddForeignKeys(fks ...*ForeignKey) *Table {
 	return t
 }
 
+// AddTriggers appends the given triggers to the table trigger list.
+func (t *Table) AddTriggers(triggers ...*Trigger) *Table {
+	for _, tr := range triggers {
+		tr.Table = t
+	}
+	t.Triggers = append(t.Triggers, triggers...)
+	return t
+}
+
 // AddAttrs adds and additional attributes to the table.
 func (t *Table) AddAttrs(attrs ...Attr) *Table {
 	t.Attrs = append(t.Attrs, attrs...)
@@ -834,6 +843,13 @@ func (f *ForeignKey) SetOnDelete(o ReferenceOption) *ForeignKey {
 	return f
 }
 
+// AddAttrs adds additional attributes to the foreign-key.
+// For example, the MATCH type of the constraint.
+func (f *ForeignKey) AddAttrs(attrs ...Attr) *ForeignKey {
+	f.Attrs = append(f.Attrs, attrs...)
+	return f
+}
+
 // ReplaceOrAppend searches an attribute of the same type as v in
 // the list and replaces it. Otherwise, v is appended to the list.
 func ReplaceOrAppend(attrs *[]Attr, v Attr) {
@@ -0,0 +1,296 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// An Impact describes a schema object that is affected by a planned change.
+// For example, a view that selects a column that is dropped by the change.
+type Impact struct {
+	// Change is the planned change. Table level changes, like dropping
+	// a column, are reported with their nested change (e.g. *DropColumn).
+	Change Change
+	// Object is the affected object. One of: *Table, *View, *Index,
+	// *ForeignKey, *Check, *Func or *Proc.
+	Object any
+	// Reason describes why the object is affected.
+	Reason string
+}
+
+// AnalyzeImpact walks the dependency graph of the given realm and returns all objects
+// that are affected by the planned changes, but are not changed by them. The realm is
+// the current state the changes are planned on, and changed tables and views are
+// resolved from it by their name, as the changes may hold their desired state. Views are
+// resolved using their dependencies (View.Deps), and views that depend on affected
+// views are reported as well. Functions and procedures are matched by their body.
+//
+//	impacts := schema.AnalyzeImpact(current, changes)
+//	for _, i := range impacts {
+//		fmt.Println(i.Reason)
+//	}
+func AnalyzeImpact(r *Realm, changes []Change) []*Impact {
+	a := &impacts{realm: r, seen: make(map[Change]map[any]bool), dropped: make(map[Object]bool)}
+	// Objects that are dropped by the plan are not reported.
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *DropSchema:
+			for _, t := range c.S.Tables {
+				a.dropped[t] = true
+			}
+			for _, v := range c.S.Views {
+				a.dropped[v] = true
+			}
+		case *DropTable:
+			a.dropped[a.currentT(c.T)] = true
+		case *DropView:
+			a.dropped[a.currentV(c.V)] = true
+		}
+	}
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *DropSchema:
+			for _, t := range c.S.Tables {
+				a.table(c, t)
+			}
+			for _, v := range c.S.Views {
+				a.view(c, v)
+			}
+		case *DropTable:
+			a.table(c, a.currentT(c.T))
+		case *RenameTable:
+			a.table(c, a.currentT(c.From))
+		case *DropView:
+			a.view(c, a.currentV(c.V))
+		case *RenameView:
+			a.view(c, a.currentV(c.From))
+		case *ModifyTable:
+			t := a.currentT(c.T)
+			for _, mc := range c.Changes {
+				switch mc := mc.(type) {
+				case *DropColumn:
+					a.column(mc, c, t, mc.C)
+				case *RenameColumn:
+					a.column(mc, c, t, mc.From)
+				case *ModifyColumn:
+					if mc.Change.Is(ChangeType) {
+						a.column(mc, c, t, mc.From)
+					}
+				}
+			}
+		}
+	}
+	return a.list
+}
+
+// impacts collects the impacts of changes.
+type impacts struct {
+	realm   *Realm
+	list    []*Impact
+	seen    map[Change]map[any]bool
+	dropped map[Object]bool
+}
+
+// currentT returns the table from the current realm, matched by its schema
+// and name. The given table is returned if it does not exist in the realm.
+func (a *impacts) currentT(t *Table) *Table {
+	for _, s := range a.realm.Schemas {
+		if t.Schema != nil && t.Schema.Name != s.Name {
+			continue
+		}
+		if t1, ok := s.Table(t.Name); ok {
+			return t1
+		}
+	}
+	return t
+}
+
+// currentV returns the view from the current realm, matched by its schema
+// and name. The given view is returned if it does not exist in the realm.
+func (a *impacts) currentV(v *View) *View {
+	for _, s := range a.realm.Schemas {
+		if v.Schema != nil && v.Schema.Name != s.Name {
+			continue
+		}
+		if v1, ok := s.View(v.Name); ok {
+			return v1
+		}
+	}
+	return v
+}
+
+// add records the impact, and reports if it was not recorded before.
+func (a *impacts) add(c Change, o any, format string, args ...any) bool {
+	if a.seen[c] == nil {
+		a.seen[c] = make(map[any]bool)
+	}
+	if a.seen[c][o] {
+		return false
+	}
+	a.seen[c][o] = true
+	a.list = append(a.list, &Impact{Change: c, Object: o, Reason: fmt.Sprintf(format, args...)})
+	return true
+}
+
+// table records the objects that depend on the given table.
+func (a *impacts) table(c Change, t *Table) {
+	a.dependents(c, t, fmt.Sprintf("table %q", t.Name))
+	for _, s := range a.realm.Schemas {
+		for _, t1 := range s.Tables {
+			if t1 == t || a.dropped[t1] {
+				continue
+			}
+			for _, fk := range t1.ForeignKeys {
+				if fk.RefTable == t {
+					a.add(c, fk, "foreign key %q of table %q references table %q", fk.Symbol, t1.Name, t.Name)
+				}
+			}
+		}
+		a.routines(c, s, t.Name, fmt.Sprintf("table %q", t.Name))
+	}
+}
+
+// view records the objects that depend on the given view.
+func (a *impacts) view(c Change, v *View) {
+	a.dependents(c, v, fmt.Sprintf("view %q", v.Name))
+	for _, s := range a.realm.Schemas {
+		a.routines(c, s, v.Name, fmt.Sprintf("view %q", v.Name))
+	}
+}
+
+// dependents records the views that depend on the given object, and the objects depending on them.
+func (a *impacts) dependents(c Change, o Object, name string) {
+	for _, s := range a.realm.Schemas {
+		for _, v := range s.Views {
+			if a.dropped[v] || !hasDep(v, o) {
+				continue
+			}
+			if a.add(c, v, "view %q depends on %s", v.Name, name) {
+				a.view(c, v)
+			}
+		}
+	}
+}
+
+// column records the objects that depend on the given column of the modified table.
+// The table t is the current state of the table that is modified by m.
+func (a *impacts) column(c Change, m *ModifyTable, t *Table, col *Column) {
+	if pk := t.PrimaryKey; pk != nil && hasPart(pk, col) && !changed(m, func(c Change) bool {
+		_, ok := c.(*DropPrimaryKey)
+		return ok
+	}) {
+		a.add(c, pk, "primary key of table %q contains column %q", t.Name, col.Name)
+	}
+	for _, idx := range t.Indexes {
+		if hasPart(idx, col) && !changed(m, func(c Change) bool {
+			d, ok := c.(*DropIndex)
+			return ok && d.I.Name == idx.Name
+		}) {
+			a.add(c, idx, "index %q of table %q contains column %q", idx.Name, t.Name, col.Name)
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		if hasColumn(fk.Columns, col) && !changed(m, func(c Change) bool {
+			d, ok := c.(*DropForeignKey)
+			return ok && d.F.Symbol == fk.Symbol
+		}) {
+			a.add(c, fk, "foreign key %q of table %q contains column %q", fk.Symbol, t.Name, col.Name)
+		}
+	}
+	for _, at := range t.Attrs {
+		if ck, ok := at.(*Check); ok && mentions(ck.Expr, col.Name) && !changed(m, func(c Change) bool {
+			d, ok := c.(*DropCheck)
+			return ok && d.C.Name == ck.Name && d.C.Expr == ck.Expr
+		}) {
+			a.add(c, ck, "check %q of table %q references column %q", ck.Name, t.Name, col.Name)
+		}
+	}
+	for _, s := range a.realm.Schemas {
+		for _, t1 := range s.Tables {
+			if t1 == t || a.dropped[t1] {
+				continue
+			}
+			for _, fk := range t1.ForeignKeys {
+				if fk.RefTable == t && hasColumn(fk.RefColumns, col) {
+					a.add(c, fk, "foreign key %q of table %q references column %q of table %q", fk.Symbol, t1.Name, col.Name, t.Name)
+				}
+			}
+		}
+		for _, v := range s.Views {
+			if !a.dropped[v] && hasDep(v, t) && mentions(v.Def, col.Name) && a.add(c, v, "view %q references column %q of table %q", v.Name, col.Name, t.Name) {
+				a.view(c, v)
+			}
+		}
+	}
+}
+
+// routines records the functions and procedures of the schema that their body mentions the given name.
+func (a *impacts) routines(c Change, s *Schema, name, desc string) {
+	for _, f := range s.Funcs {
+		if mentions(f.Body, name) {
+			a.add(c, f, "function %q references %s", f.Name, desc)
+		}
+	}
+	for _, p := range s.Procs {
+		if mentions(p.Body, name) {
+			a.add(c, p, "procedure %q references %s", p.Name, desc)
+		}
+	}
+}
+
+// changed reports if any of the table changes matches the predicate.
+func changed(m *ModifyTable, f func(Change) bool) bool {
+	for _, c := range m.Changes {
+		if f(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDep(v *View, o Object) bool {
+	for _, d := range v.Deps {
+		if d == o {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPart(idx *Index, c *Column) bool {
+	for _, p := range idx.Parts {
+		if p.C == c || p.C != nil && p.C.Name == c.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasColumn(columns []*Column, c *Column) bool {
+	for _, c1 := range columns {
+		if c1 == c || c1.Name == c.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// mentions reports if the given text contains the identifier.
+func mentions(text, ident string) bool {
+	if ident == "" {
+		return false
+	}
+	for _, w := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$'
+	}) {
+		if strings.EqualFold(w, ident) {
+			return true
+		}
+	}
+	return false
+}
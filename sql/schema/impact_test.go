@@ -0,0 +1,95 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeImpact(t *testing.T) {
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		name  = schema.NewStringColumn("name", "text")
+		users = schema.NewTable("users").
+			AddColumns(id, name).
+			SetPrimaryKey(schema.NewPrimaryKey(id)).
+			AddIndexes(schema.NewIndex("name").AddColumns(name)).
+			AddChecks(schema.NewCheck().SetName("name_len").SetExpr("length(name) > 1"))
+		uid   = schema.NewIntColumn("user_id", "int")
+		posts = schema.NewTable("posts").AddColumns(uid)
+		fk    = schema.NewForeignKey("author").AddColumns(uid).SetRefTable(users).AddRefColumns(id)
+		v1    = schema.NewView("names", "SELECT name FROM users").AddDeps(users)
+		v2    = schema.NewView("short_names", "SELECT * FROM names WHERE length(name) < 5").AddDeps(v1)
+		v3    = schema.NewView("ids", "SELECT id FROM users").AddDeps(users)
+		fn    = &schema.Func{Name: "count_users", Body: "SELECT count(*) FROM users"}
+	)
+	posts.AddForeignKeys(fk)
+	schema.NewRealm(schema.New("public").AddTables(users, posts).AddViews(v1, v2, v3).AddFuncs(fn))
+	r := users.Schema.Realm
+
+	drop := &schema.DropColumn{C: name}
+	impacts := schema.AnalyzeImpact(r, []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{drop, &schema.DropIndex{I: schema.NewIndex("name")}}},
+	})
+	require.Len(t, impacts, 3)
+	require.Equal(t, &schema.Impact{Change: drop, Object: users.Attrs[0], Reason: `check "name_len" of table "users" references column "name"`}, impacts[0])
+	require.Equal(t, &schema.Impact{Change: drop, Object: v1, Reason: `view "names" references column "name" of table "users"`}, impacts[1])
+	require.Equal(t, &schema.Impact{Change: drop, Object: v2, Reason: `view "short_names" depends on view "names"`}, impacts[2])
+
+	impacts = schema.AnalyzeImpact(r, []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.ModifyColumn{From: id, To: id, Change: schema.ChangeNull}}},
+	})
+	require.Empty(t, impacts, "only type changes affect dependents")
+
+	dropT := &schema.DropTable{T: users}
+	impacts = schema.AnalyzeImpact(r, []schema.Change{dropT, &schema.DropView{V: v3}})
+	reasons := make([]string, len(impacts))
+	for i := range impacts {
+		require.Equal(t, dropT, impacts[i].Change)
+		reasons[i] = impacts[i].Reason
+	}
+	require.Equal(t, []string{
+		`view "names" depends on table "users"`,
+		`view "short_names" depends on view "names"`,
+		`foreign key "author" of table "posts" references table "users"`,
+		`function "count_users" references table "users"`,
+	}, reasons)
+}
+
+func TestAnalyzeImpact_Desired(t *testing.T) {
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		users = schema.NewTable("users").AddColumns(id).SetPrimaryKey(schema.NewPrimaryKey(id))
+		uid   = schema.NewIntColumn("user_id", "int")
+		posts = schema.NewTable("posts").AddColumns(uid)
+		v     = schema.NewView("ids", "SELECT id FROM users").AddDeps(users)
+	)
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(uid).SetRefTable(users).AddRefColumns(id))
+	current := schema.NewRealm(schema.New("public").AddTables(users, posts).AddViews(v))
+
+	// Changes computed by a diff hold the desired state of modified
+	// tables, and the dropped columns from the current state.
+	desired := schema.NewTable("users").AddColumns(schema.NewIntColumn("uuid", "int"))
+	schema.NewRealm(schema.New("public").AddTables(desired))
+	drop := &schema.DropColumn{C: id}
+	impacts := schema.AnalyzeImpact(current, []schema.Change{
+		&schema.ModifyTable{T: desired, Changes: []schema.Change{drop, &schema.DropPrimaryKey{P: users.PrimaryKey}}},
+	})
+	require.Equal(t, []*schema.Impact{
+		{Change: drop, Object: posts.ForeignKeys[0], Reason: `foreign key "author" of table "posts" references column "id" of table "users"`},
+		{Change: drop, Object: v, Reason: `view "ids" references column "id" of table "users"`},
+	}, impacts)
+
+	// Dropped tables are resolved from the current state as well.
+	dropT := &schema.DropTable{T: schema.NewTable("users").SetSchema(schema.New("public"))}
+	impacts = schema.AnalyzeImpact(current, []schema.Change{dropT})
+	require.Len(t, impacts, 2)
+	require.Equal(t, `view "ids" depends on table "users"`, impacts[0].Reason)
+	require.Equal(t, `foreign key "author" of table "posts" references table "users"`, impacts[1].Reason)
+}
@@ -49,6 +49,10 @@ const (
 
 	// InspectFuncs enables schema functions / procedures inspection.
 	InspectFuncs
+
+	// InspectObjects enables inspection of driver-specific schema
+	// objects, such as Postgres text search configurations.
+	InspectObjects
 )
 
 // Is reports whether the given mode is enabled.
@@ -75,6 +79,26 @@ type (
 		//	*.* // the last item defines the filtering; all resourced under all tables are excluded.
 		//
 		Exclude []string
+
+		// Anonymize, if set, strips or hashes sensitive metadata from the inspected schema.
+		Anonymize *AnonymizeOptions
+
+		// Stats enables collection of table and index statistics (e.g., row counts and
+		// sizes), that are attached to the inspected tables and indexes as TableStats and
+		// IndexStats attributes. It is disabled by default, as collecting statistics may
+		// be expensive on large databases.
+		Stats bool
+
+		// Triggers enables inspection of table triggers. It is disabled by default, as
+		// triggers are not part of the schema definitions (e.g., HCL) by default, and
+		// comparing them would drop the triggers that exist only in the database.
+		Triggers bool
+
+		// Owners enables inspection of the roles that own the inspected objects, on
+		// databases that support it (e.g., PostgreSQL). It is disabled by default, as
+		// roles usually differ between environments, and owner changes are planned
+		// only for objects whose current owners were inspected.
+		Owners bool
 	}
 
 	// InspectRealmOption describes options for RealmInspector.
@@ -99,6 +123,18 @@ type (
 		//	*.*.* // the last item defines the filtering; all resources are excluded in all tables.
 		//
 		Exclude []string
+
+		// Anonymize, if set, strips or hashes sensitive metadata from the inspected realm.
+		Anonymize *AnonymizeOptions
+
+		// Stats enables collection of table and index statistics. See InspectOptions.Stats.
+		Stats bool
+
+		// Triggers enables inspection of table triggers. See InspectOptions.Triggers.
+		Triggers bool
+
+		// Owners enables inspection of object owners. See InspectOptions.Owners.
+		Owners bool
 	}
 
 	// Inspector is the interface implemented by the different database
@@ -125,3 +161,11 @@ type Normalizer interface {
 	// NormalizeRealm returns the normal representation of a database.
 	NormalizeRealm(context.Context, *Realm) (*Realm, error)
 }
+
+// ViewInferrer is the interface implemented by the different database drivers that
+// can infer the columns of views that were defined without them (e.g. in HCL), by
+// preparing their definitions against a dev database.
+type ViewInferrer interface {
+	// InferViewColumns populates the columns of views that have none.
+	InferViewColumns(context.Context, *Realm) error
+}
@@ -162,6 +162,23 @@ type (
 		From, To *Proc
 	}
 
+	// AddTrigger describes a trigger creation change.
+	AddTrigger struct {
+		T     *Trigger
+		Extra []Clause // Extra clauses and options.
+	}
+
+	// DropTrigger describes a trigger removal change.
+	DropTrigger struct {
+		T     *Trigger
+		Extra []Clause // Extra clauses.
+	}
+
+	// ModifyTrigger describes a trigger modification change.
+	ModifyTrigger struct {
+		From, To *Trigger
+	}
+
 	// AddObject describes a generic object creation change.
 	AddObject struct {
 		O     Object
@@ -188,7 +205,8 @@ type (
 
 	// AddColumn describes a column creation change.
 	AddColumn struct {
-		C *Column
+		C     *Column
+		Extra []Clause // Extra clauses and options.
 	}
 
 	// DropColumn describes a column removal change.
@@ -300,6 +318,11 @@ type (
 	// IfNotExists represents a clause in a schema change that is commonly
 	// supported by multiple statements (e.g. CREATE TABLE or CREATE SCHEMA).
 	IfNotExists struct{}
+
+	// ColumnPosition represents a clause in a column creation change that places the
+	// column in its position in the desired table, instead of appending it to the end
+	// of the table. It is set only if DiffOptions.ColumnOrder is enabled.
+	ColumnPosition struct{}
 )
 
 // A ChangeKind describes a change kind that can be combined
@@ -357,6 +380,11 @@ const (
 	ChangeUpdateAction
 	// ChangeDeleteAction describes a change to the foreign-key delete action.
 	ChangeDeleteAction
+
+	// ChangePosition describes a change to the position of a column in its table.
+	// It is reported only if DiffOptions.ColumnOrder is enabled, by drivers that
+	// support moving columns without rebuilding their tables. e.g., MySQL MODIFY ... AFTER.
+	ChangePosition
 )
 
 // Is reports whether c is match the given change kind.
@@ -390,6 +418,30 @@ type (
 		// SkipChanges defines a list of change types to skip.
 		SkipChanges []Change
 
+		// ExactTypes indicates that column types are compared by their exact
+		// spelling. By default, the drivers ignore the differences between type
+		// aliases that do not alter the storage semantics of the column, such as
+		// DECIMAL and NUMERIC, or the display width of MySQL integer types.
+		ExactTypes bool
+
+		// ColumnOrder indicates that changes to the order of the columns of existing tables
+		// are planned, on drivers that can move columns without rebuilding their tables (e.g.
+		// MySQL). By default, column order is ignored and new columns are appended to the end
+		// of their tables, as moving columns may rebuild large tables.
+		ColumnOrder bool
+
+		// Resolver, if set, is called when the differ cannot tell if a table or a
+		// column was renamed, or dropped and another one was added instead. If not
+		// set, such changes are planned as DROP and ADD.
+		Resolver Resolver
+
+		// SearchPath holds the schemas that unqualified objects are resolved from,
+		// ordered by their priority. For example, the PostgreSQL search_path. If set,
+		// a schema without a name in the desired state is matched to the first schema
+		// in the path that exists in the current state, and tables referenced by
+		// foreign keys are compared by their (resolved) schemas as well.
+		SearchPath []string
+
 		// Extra defines per-driver configuration. If not
 		// nil, should be set to schemahcl.Extension.
 		Extra any // avoid circular dependency with schemahcl.
@@ -397,6 +449,35 @@ type (
 
 	// DiffOption allows configuring the DiffOptions using functional options.
 	DiffOption func(*DiffOptions)
+
+	// Resolver resolves ambiguous diffs, in which an element exists only in the current
+	// state, and one or more elements of the same kind exist only in the desired state.
+	// Candidates are sorted by their similarity score, and each method returns the element
+	// the given one was renamed to, or nil in case it was dropped.
+	Resolver interface {
+		// ResolveTable is called for each table that was not found in the desired schema.
+		ResolveTable(from *Table, candidates []*TableCandidate) (*Table, error)
+
+		// ResolveColumn is called for each column of table t that was not found in the
+		// desired table.
+		ResolveColumn(t *Table, from *Column, candidates []*ColumnCandidate) (*Column, error)
+	}
+
+	// TableCandidate describes a table that might be the new name of a table.
+	TableCandidate struct {
+		T *Table
+		// Score is the similarity of the two tables in the range of [0, 1],
+		// computed from their column layout.
+		Score float64
+	}
+
+	// ColumnCandidate describes a column that might be the new name of a column.
+	ColumnCandidate struct {
+		C *Column
+		// Score is the similarity of the two columns in the range of [0, 1],
+		// computed from their types, nullability and positions in the table.
+		Score float64
+	}
 )
 
 // NewDiffOptions creates a new DiffOptions from the given configuration.
@@ -418,6 +499,38 @@ func DiffSkipChanges(changes ...Change) DiffOption {
 	}
 }
 
+// DiffExactTypes returns a DiffOption that compares column types by their exact
+// spelling, instead of the canonical forms of their aliases in the dialect.
+func DiffExactTypes() DiffOption {
+	return func(o *DiffOptions) {
+		o.ExactTypes = true
+	}
+}
+
+// DiffColumnOrder returns a DiffOption that plans changes to the order of the columns
+// of existing tables, on drivers that support moving columns. See DiffOptions.ColumnOrder.
+func DiffColumnOrder() DiffOption {
+	return func(o *DiffOptions) {
+		o.ColumnOrder = true
+	}
+}
+
+// DiffResolver returns a DiffOption that sets the Resolver for
+// choosing between renames and DROP and ADD changes.
+func DiffResolver(r Resolver) DiffOption {
+	return func(o *DiffOptions) {
+		o.Resolver = r
+	}
+}
+
+// DiffSearchPath returns a DiffOption that sets the search path
+// for resolving unqualified objects, ordered by their priority.
+func DiffSearchPath(schemas ...string) DiffOption {
+	return func(o *DiffOptions) {
+		o.SearchPath = append(o.SearchPath, schemas...)
+	}
+}
+
 // Skipped reports whether the given change should be skipped.
 func (o *DiffOptions) Skipped(c Change) bool {
 	for _, s := range o.SkipChanges {
@@ -602,6 +715,9 @@ func (*AddProc) change()          {}
 func (*DropProc) change()         {}
 func (*ModifyProc) change()       {}
 func (*RenameProc) change()       {}
+func (*AddTrigger) change()       {}
+func (*DropTrigger) change()      {}
+func (*ModifyTrigger) change()    {}
 func (*AddObject) change()        {}
 func (*DropObject) change()       {}
 func (*ModifyObject) change()     {}
@@ -625,5 +741,6 @@ func (*DropForeignKey) change()   {}
 func (*ModifyForeignKey) change() {}
 
 // clauses.
-func (*IfExists) clause()    {}
-func (*IfNotExists) clause() {}
+func (*IfExists) clause()       {}
+func (*IfNotExists) clause()    {}
+func (*ColumnPosition) clause() {}
@@ -0,0 +1,181 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"strings"
+)
+
+// DefaultRenameScore is the minimum score used by RenameHeuristics if not configured otherwise.
+const DefaultRenameScore = 0.75
+
+// DefaultRenameNameScore is the minimum name similarity of renamed columns
+// used by RenameHeuristics if not configured otherwise.
+const DefaultRenameNameScore = 0.7
+
+// RenameHeuristics is a Resolver that detects likely renames of tables and columns. Candidates
+// are scored by combining the layout similarity computed by the differ (e.g., same type, same
+// position) with the similarity of the names and constraints, and the best candidate is chosen
+// if it reaches the minimum score, and no other candidate shares its score.
+//
+// Columns of the same type and position reach the minimum score by their layout alone.
+// Therefore, column candidates are considered only if their names are related, i.e., one
+// name contains the other (e.g., "email" and "email_address"), or their name similarity
+// reaches MinNameScore. For example, "deleted_at" is not renamed to "archived_at".
+type RenameHeuristics struct {
+	// MinScore is the minimum score in the range of [0, 1] for treating
+	// a candidate as a rename. Defaults to DefaultRenameScore.
+	MinScore float64
+
+	// MinNameScore is the minimum name similarity in the range of [0, 1] of
+	// column candidates. Defaults to DefaultRenameNameScore.
+	MinNameScore float64
+}
+
+// ResolveTable implements the Resolver interface.
+func (h *RenameHeuristics) ResolveTable(from *Table, cs []*TableCandidate) (*Table, error) {
+	scores := make([]float64, len(cs))
+	for i, c := range cs {
+		scores[i] = h.TableScore(from, c.T, c.Score)
+	}
+	if i := h.Choose(scores); i != -1 {
+		return cs[i].T, nil
+	}
+	return nil, nil
+}
+
+// ResolveColumn implements the Resolver interface.
+func (h *RenameHeuristics) ResolveColumn(_ *Table, from *Column, cs []*ColumnCandidate) (*Column, error) {
+	scores := make([]float64, len(cs))
+	for i, c := range cs {
+		if h.NamesRelated(from.Name, c.C.Name) {
+			scores[i] = h.ColumnScore(from, c.C, c.Score)
+		}
+	}
+	if i := h.Choose(scores); i != -1 {
+		return cs[i].C, nil
+	}
+	return nil, nil
+}
+
+// TableScore combines the layout score of the two tables with the similarity of their names.
+func (*RenameHeuristics) TableScore(from, to *Table, layout float64) float64 {
+	return 0.7*layout + 0.3*NameSimilarity(from.Name, to.Name)
+}
+
+// ColumnScore combines the layout score of the two columns with
+// the similarity of their names and the constraints they are part of.
+func (*RenameHeuristics) ColumnScore(from, to *Column, layout float64) float64 {
+	score := 0.6*layout + 0.25*NameSimilarity(from.Name, to.Name)
+	if constraintsEqual(from, to) {
+		score += 0.15
+	}
+	return score
+}
+
+// Likely reports if the given score indicates a likely rename.
+func (h *RenameHeuristics) Likely(score float64) bool {
+	m := h.MinScore
+	if m == 0 {
+		m = DefaultRenameScore
+	}
+	return score >= m
+}
+
+// NamesRelated reports if the names of a column candidate are related, i.e., one contains
+// the other, or their similarity reaches the minimum name score. See NameSimilarity.
+func (h *RenameHeuristics) NamesRelated(a, b string) bool {
+	m := h.MinNameScore
+	if m == 0 {
+		m = DefaultRenameNameScore
+	}
+	a, b = normalizeName(a), normalizeName(b)
+	if a != "" && b != "" && (strings.Contains(a, b) || strings.Contains(b, a)) {
+		return true
+	}
+	return NameSimilarity(a, b) >= m
+}
+
+// Choose returns the index of the highest score, if it indicates
+// a likely rename and is not shared with other scores, or -1.
+func (h *RenameHeuristics) Choose(scores []float64) int {
+	best := -1
+	for i, s := range scores {
+		switch {
+		case !h.Likely(s):
+		case best == -1 || s > scores[best]:
+			best = i
+		}
+	}
+	for i, s := range scores {
+		if best != -1 && i != best && s == scores[best] {
+			return -1
+		}
+	}
+	return best
+}
+
+// NameSimilarity returns the similarity of two identifiers in the range of [0, 1], computed
+// from their edit distance. Identifiers are compared case-insensitively, and without their
+// underscores. For example, "user_name" and "username" are considered identical.
+func NameSimilarity(a, b string) float64 {
+	r1, r2 := []rune(normalizeName(a)), []rune(normalizeName(b))
+	n := len(r1)
+	if len(r2) > n {
+		n = len(r2)
+	}
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(r1, r2))/float64(n)
+}
+
+// normalizeName returns the name in lower case and without underscores.
+func normalizeName(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
+
+// editDistance returns the Levenshtein distance between the two strings.
+func editDistance(r1, r2 []rune) int {
+	prev, cur := make([]int, len(r2)+1), make([]int, len(r2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(r1); i++ {
+		cur[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(r2)]
+}
+
+// constraintsEqual reports if the two columns are part of the same kind of constraints.
+func constraintsEqual(c1, c2 *Column) bool {
+	if len(c1.Indexes) != len(c2.Indexes) || len(c1.ForeignKeys) != len(c2.ForeignKeys) {
+		return false
+	}
+	var u1, u2 int
+	for i := range c1.Indexes {
+		if c1.Indexes[i].Unique {
+			u1++
+		}
+		if c2.Indexes[i].Unique {
+			u2++
+		}
+	}
+	return u1 == u2
+}
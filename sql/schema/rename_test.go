@@ -0,0 +1,81 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	require.Equal(t, 1.0, schema.NameSimilarity("user_name", "UserName"))
+	require.Equal(t, 1.0, schema.NameSimilarity("", ""))
+	require.Equal(t, 0.0, schema.NameSimilarity("a", "b"))
+	require.Equal(t, 0.5, schema.NameSimilarity("name", "full_name"))
+	require.InDelta(t, 0.8, schema.NameSimilarity("email", "emails"), 0.04)
+}
+
+func TestRenameHeuristics_ResolveColumn(t *testing.T) {
+	var (
+		h     schema.RenameHeuristics
+		email = schema.NewStringColumn("email", "varchar(255)")
+		mail  = schema.NewStringColumn("email_address", "varchar(255)")
+		note  = schema.NewStringColumn("note", "text")
+	)
+	// Same layout with a similar name.
+	c, err := h.ResolveColumn(nil, email, []*schema.ColumnCandidate{{C: mail, Score: 1}, {C: note, Score: 0.4}})
+	require.NoError(t, err)
+	require.Equal(t, mail, c)
+
+	// Different layout.
+	c, err = h.ResolveColumn(nil, email, []*schema.ColumnCandidate{{C: note, Score: 0.4}})
+	require.NoError(t, err)
+	require.Nil(t, c)
+
+	// Ambiguous candidates.
+	c, err = h.ResolveColumn(nil, note, []*schema.ColumnCandidate{{C: schema.NewStringColumn("b", "text"), Score: 1}, {C: schema.NewStringColumn("c", "text"), Score: 1}})
+	require.NoError(t, err)
+	require.Nil(t, c)
+
+	// Columns that are part of different constraints.
+	unique := schema.NewStringColumn("email_address", "varchar(255)")
+	schema.NewUniqueIndex("unique").AddColumns(unique)
+	require.Less(t, h.ColumnScore(email, unique, 1), h.ColumnScore(email, mail, 1))
+
+	// Columns of the same layout with unrelated names.
+	deleted, archived := schema.NewTimeColumn("deleted_at", "timestamp"), schema.NewTimeColumn("archived_at", "timestamp")
+	require.GreaterOrEqual(t, h.ColumnScore(deleted, archived, 1), schema.DefaultRenameScore)
+	c, err = h.ResolveColumn(nil, deleted, []*schema.ColumnCandidate{{C: archived, Score: 1}})
+	require.NoError(t, err)
+	require.Nil(t, c)
+	c, err = h.ResolveColumn(nil, schema.NewTimeColumn("created_at", "timestamp"), []*schema.ColumnCandidate{{C: schema.NewTimeColumn("updated_at", "timestamp"), Score: 1}})
+	require.NoError(t, err)
+	require.Nil(t, c)
+	require.True(t, h.NamesRelated("name", "full_name"))
+	require.True(t, h.NamesRelated("first_name", "FirstName"))
+	require.False(t, h.NamesRelated("deleted_at", "archived_at"))
+
+	// Custom threshold.
+	h.MinScore = 0.95
+	c, err = h.ResolveColumn(nil, email, []*schema.ColumnCandidate{{C: mail, Score: 1}})
+	require.NoError(t, err)
+	require.Nil(t, c)
+}
+
+func TestRenameHeuristics_ResolveTable(t *testing.T) {
+	var (
+		h     schema.RenameHeuristics
+		users = schema.NewTable("users")
+	)
+	t1, err := h.ResolveTable(users, []*schema.TableCandidate{{T: schema.NewTable("user"), Score: 1}, {T: schema.NewTable("posts"), Score: 1}})
+	require.NoError(t, err)
+	require.Equal(t, "user", t1.Name)
+	t1, err = h.ResolveTable(users, []*schema.TableCandidate{{T: schema.NewTable("accounts"), Score: 0.5}})
+	require.NoError(t, err)
+	require.Nil(t, t1)
+}
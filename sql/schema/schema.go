@@ -39,6 +39,7 @@ type (
 		Indexes     []*Index
 		PrimaryKey  *Index
 		ForeignKeys []*ForeignKey
+		Triggers    []*Trigger
 		Attrs       []Attr // Attrs, constraints and options.
 	}
 
@@ -106,6 +107,7 @@ type (
 		RefColumns []*Column
 		OnUpdate   ReferenceOption
 		OnDelete   ReferenceOption
+		Attrs      []Attr
 	}
 
 	// Func represents a function definition.
@@ -129,6 +131,26 @@ type (
 		Attrs  []Attr // Extra driver specific attributes.
 	}
 
+	// A Trigger represents a trigger definition.
+	Trigger struct {
+		Name       string
+		Table      *Table         // Table the trigger is defined on.
+		ActionTime TriggerTime    // BEFORE, AFTER or INSTEAD OF.
+		Events     []TriggerEvent // INSERT, UPDATE or DELETE.
+		For        TriggerFor     // FOR EACH ROW or FOR EACH STATEMENT.
+		Body       string         // Trigger body only.
+		Attrs      []Attr         // Extra driver specific attributes.
+	}
+
+	// TriggerTime represents the time a trigger is fired.
+	TriggerTime string
+
+	// TriggerEvent represents the event that fires a trigger.
+	TriggerEvent string
+
+	// TriggerFor represents the granularity of a trigger.
+	TriggerFor string
+
 	// A FuncArg represents a single function argument.
 	FuncArg struct {
 		Name    string      // Optional name.
@@ -149,6 +171,20 @@ const (
 	FuncArgModeVariadic FuncArgMode = "VARIADIC"
 )
 
+// List of supported trigger action times, events and granularities.
+const (
+	TriggerTimeBefore  TriggerTime = "BEFORE"
+	TriggerTimeAfter   TriggerTime = "AFTER"
+	TriggerTimeInstead TriggerTime = "INSTEAD OF"
+
+	TriggerEventInsert TriggerEvent = "INSERT"
+	TriggerEventUpdate TriggerEvent = "UPDATE"
+	TriggerEventDelete TriggerEvent = "DELETE"
+
+	TriggerForRow  TriggerFor = "ROW"
+	TriggerForStmt TriggerFor = "STATEMENT"
+)
+
 // Schema returns the first schema that matched the given name.
 func (r *Realm) Schema(name string) (*Schema, bool) {
 	for _, s := range r.Schemas {
@@ -249,6 +285,16 @@ func (t *Table) ForeignKey(symbol string) (*ForeignKey, bool) {
 	return nil, false
 }
 
+// Trigger returns the first trigger that matched the given name.
+func (t *Table) Trigger(name string) (*Trigger, bool) {
+	for _, tr := range t.Triggers {
+		if tr.Name == name {
+			return tr, true
+		}
+	}
+	return nil, false
+}
+
 // Materialized reports if the view is materialized.
 func (v *View) Materialized() bool {
 	for _, a := range v.Attrs {
@@ -420,6 +466,28 @@ type (
 	}
 )
 
+// A TypeDef defines how a driver parses, formats and compares a column type that
+// it does not support natively, such as types installed by database extensions.
+// TypeDefs are registered using the RegisterType function of the drivers.
+type TypeDef struct {
+	// Name of the type in the database (e.g., citext). Raw types are
+	// matched by their name, ignoring case and type arguments.
+	Name string
+
+	// Parse converts the raw type of a column, as inspected from the database or
+	// defined in the schema, into a Type. If nil, the raw type is returned as an
+	// UnsupportedType.
+	Parse func(raw string) (Type, error)
+
+	// Format converts the parsed type to its column form in the database.
+	// If nil, the T field of the type is used.
+	Format func(Type) (string, error)
+
+	// Changed reports if the column type was changed. If nil, the
+	// formatted types are compared, ignoring case.
+	Changed func(from, to Type) bool
+}
+
 type (
 	// Expr defines an SQL expression in schema DDL.
 	//
@@ -490,6 +558,23 @@ type (
 		V string // LOCAL, CASCADED, NONE, or driver specific.
 	}
 
+	// TableStats describes the statistics of a table, collected on inspection
+	// in case statistics collection is enabled. The values are estimations
+	// reported by the database, and might be out of date.
+	TableStats struct {
+		Rows      int64 // Estimated number of rows.
+		DataSize  int64 // Size of the table data, in bytes.
+		IndexSize int64 // Total size of the table indexes, in bytes.
+		FreeSize  int64 // Allocated but unused space, in bytes, if reported by the database.
+	}
+
+	// IndexStats describes the statistics of an index, collected on
+	// inspection in case statistics collection is enabled.
+	IndexStats struct {
+		Size  int64   // Size of the index, in bytes.
+		Bloat float64 // Estimated ratio of unused space in the index, between 0 and 1.
+	}
+
 	// Materialized is a schema attribute that attached to views to indicates
 	// they are MATERIALIZED VIEWs.
 	Materialized struct {
@@ -507,6 +592,7 @@ const (
 // objects.
 func (*Table) obj()    {}
 func (*View) obj()     {}
+func (*Trigger) obj()  {}
 func (*EnumType) obj() {}
 
 // expressions.
@@ -534,6 +620,8 @@ func (*Charset) attr()         {}
 func (*Collation) attr()       {}
 func (*GeneratedExpr) attr()   {}
 func (*ViewCheckOption) attr() {}
+func (*TableStats) attr()      {}
+func (*IndexStats) attr()      {}
 
 // UnderlyingExpr returns the underlying expression of x.
 func UnderlyingExpr(x Expr) Expr {
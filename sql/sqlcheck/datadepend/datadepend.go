@@ -32,6 +32,10 @@ type (
 		// ModifyNotNull is an optional handler applied when
 		// a nullable column was changed to non-nullable.
 		ModifyNotNull ColumnHandler
+
+		// TypeConversion is an optional handler that classifies the conversion
+		// of column types. Lossy and invalid conversions are reported.
+		TypeConversion func(from, to schema.Type) migrate.Conversion
 	}
 
 	// ColumnPass wraps the information needed
@@ -74,6 +78,7 @@ var (
 	codeModUniqueI  = sqlcheck.Code("MF102")
 	codeAddNotNullC = sqlcheck.Code("MF103")
 	codeModNotNullC = sqlcheck.Code("MF104")
+	codeModTypeC    = sqlcheck.Code("MF105")
 )
 
 // Diagnostics runs the common analysis on the file and returns its diagnostics.
@@ -130,6 +135,9 @@ func (a *Analyzer) Diagnostics(_ context.Context, p *sqlcheck.Pass) (diags []sql
 						diags = append(diags, d...)
 					}
 				case *schema.ModifyColumn:
+					if d, ok := a.typeConversion(p, sc, m.T, c); ok {
+						diags = append(diags, d)
+					}
 					switch {
 					case p.File.TableSpan(m.T)&sqlcheck.SpanAdded == 1 || !(c.From.Type.Null && !c.To.Type.Null):
 					case a.ModifyNotNull != nil:
@@ -160,6 +168,23 @@ func (a *Analyzer) Diagnostics(_ context.Context, p *sqlcheck.Pass) (diags []sql
 	return
 }
 
+// typeConversion reports lossy and invalid changes of column types in existing tables.
+func (a *Analyzer) typeConversion(p *sqlcheck.Pass, sc *sqlcheck.Change, t *schema.Table, c *schema.ModifyColumn) (sqlcheck.Diagnostic, bool) {
+	if a.TypeConversion == nil || !c.Change.Is(schema.ChangeType) || p.File.TableSpan(t)&sqlcheck.SpanAdded == 1 {
+		return sqlcheck.Diagnostic{}, false
+	}
+	d := sqlcheck.Diagnostic{Code: codeModTypeC, Pos: sc.Stmt.Pos}
+	switch a.TypeConversion(c.From.Type.Type, c.To.Type.Type) {
+	case migrate.ConversionLossy:
+		d.Text = fmt.Sprintf("Changing the type of column %q might fail or lose data in case existing values do not fit the new type", c.To.Name)
+	case migrate.ConversionInvalid:
+		d.Text = fmt.Sprintf("Changing the type of column %q will fail, as existing values cannot be converted to the new type", c.To.Name)
+	default:
+		return d, false
+	}
+	return d, true
+}
+
 // Report provides standard reporting for data-dependent changes. Drivers that
 // decorate this Analyzer should call this function to get consistent reporting
 // between dialects.
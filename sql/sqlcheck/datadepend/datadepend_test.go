@@ -177,6 +177,76 @@ func TestAnalyzer_ModifyNullability(t *testing.T) {
 	require.Equal(t, `Modifying nullable column "a" to non-nullable might fail in case it contains NULL values`, report.Diagnostics[0].Text)
 }
 
+func TestAnalyzer_ModifyType(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		pass   = &sqlcheck.Pass{
+			Dev: &sqlclient.Client{},
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: &migrate.Stmt{
+							Text: "ALTER TABLE users",
+						},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: schema.NewTable("users").
+									SetSchema(schema.New("test")).
+									AddColumns(
+										schema.NewIntColumn("a", "bigint"),
+										schema.NewIntColumn("b", "int"),
+										schema.NewIntColumn("c", "int"),
+									),
+								Changes: []schema.Change{
+									&schema.ModifyColumn{
+										From:   schema.NewIntColumn("a", "int"),
+										To:     schema.NewIntColumn("a", "bigint"),
+										Change: schema.ChangeType,
+									},
+									&schema.ModifyColumn{
+										From:   schema.NewIntColumn("b", "bigint"),
+										To:     schema.NewIntColumn("b", "int"),
+										Change: schema.ChangeType,
+									},
+									&schema.ModifyColumn{
+										From:   schema.NewTimeColumn("c", "timestamp"),
+										To:     schema.NewIntColumn("c", "int"),
+										Change: schema.ChangeType,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	az, err := datadepend.New(nil, datadepend.Handler{
+		TypeConversion: func(from, to schema.Type) migrate.Conversion {
+			switch from.(type) {
+			case *schema.TimeType:
+				return migrate.ConversionInvalid
+			}
+			if from.(*schema.IntegerType).T == "bigint" {
+				return migrate.ConversionLossy
+			}
+			return migrate.ConversionSafe
+		},
+	})
+	require.NoError(t, err)
+	err = az.Analyze(context.Background(), pass)
+	require.NoError(t, err)
+	require.Equal(t, "data dependent changes detected", report.Text)
+	require.Len(t, report.Diagnostics, 2)
+	require.Equal(t, "MF105", report.Diagnostics[0].Code)
+	require.Equal(t, `Changing the type of column "b" might fail or lose data in case existing values do not fit the new type`, report.Diagnostics[0].Text)
+	require.Equal(t, `Changing the type of column "c" will fail, as existing values cannot be converted to the new type`, report.Diagnostics[1].Text)
+}
+
 func TestAnalyzer_Options(t *testing.T) {
 	var (
 		report *sqlcheck.Report
@@ -8,18 +8,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlcheck"
 )
 
-// Analyzer checks for destructive changes.
-type Analyzer struct {
-	sqlcheck.Options
-}
+type (
+	// Analyzer checks for destructive changes.
+	Analyzer struct {
+		sqlcheck.Options
+		// Threshold limits the reported changes to tables that exceed it,
+		// and enables reporting changes that rewrite such tables. The size
+		// of tables is read from the statistics attached to them, or from
+		// the Stats realm of the analysis pass.
+		Threshold migrate.SizeThreshold
+	}
+	// thresholdOptions defines the size threshold of the analyzer.
+	thresholdOptions struct {
+		Rows int64 `spec:"rows"`
+		Size int64 `spec:"size"`
+	}
+)
 
 // New creates a new destructive changes Analyzer with the given options.
 func New(r *schemahcl.Resource) (*Analyzer, error) {
@@ -29,6 +43,13 @@ func New(r *schemahcl.Resource) (*Analyzer, error) {
 		if err := r.As(&az.Options); err != nil {
 			return nil, fmt.Errorf("sql/sqlcheck: parsing destructive check options: %w", err)
 		}
+		if tr, ok := r.Resource("threshold"); ok {
+			var opts thresholdOptions
+			if err := tr.As(&opts); err != nil {
+				return nil, fmt.Errorf("sql/sqlcheck: parsing destructive threshold options: %w", err)
+			}
+			az.Threshold = migrate.SizeThreshold{Rows: opts.Rows, Size: opts.Size}
+		}
 	}
 	return az, nil
 }
@@ -38,6 +59,7 @@ var (
 	codeDropS = sqlcheck.Code("DS101")
 	codeDropT = sqlcheck.Code("DS102")
 	codeDropC = sqlcheck.Code("DS103")
+	codeRewrT = sqlcheck.Code("DS104")
 )
 
 // Name of the analyzer. Implements the sqlcheck.NamedAnalyzer interface.
@@ -45,6 +67,11 @@ func (*Analyzer) Name() string {
 	return "destructive"
 }
 
+// UsesStats implements the sqlcheck.StatsAnalyzer interface.
+func (a *Analyzer) UsesStats() bool {
+	return a.Threshold != (migrate.SizeThreshold{})
+}
+
 // Analyze implements sqlcheck.Analyzer.
 func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
 	var diags []sqlcheck.Diagnostic
@@ -52,7 +79,7 @@ func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
 		for _, c := range sc.Changes {
 			switch c := c.(type) {
 			case *schema.DropSchema:
-				if p.File.SchemaSpan(c.S) != sqlcheck.SpanTemporary {
+				if p.File.SchemaSpan(c.S) != sqlcheck.SpanTemporary && a.exceeds(p, c.S.Tables...) {
 					var text string
 					switch n := len(c.S.Tables); {
 					case n == 0:
@@ -69,25 +96,47 @@ func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
 					})
 				}
 			case *schema.DropTable:
-				if p.File.SchemaSpan(c.T.Schema) != sqlcheck.SpanDropped && p.File.TableSpan(c.T) != sqlcheck.SpanTemporary {
+				if p.File.SchemaSpan(c.T.Schema) != sqlcheck.SpanDropped && p.File.TableSpan(c.T) != sqlcheck.SpanTemporary && a.exceeds(p, c.T) {
+					text := fmt.Sprintf("Dropping table %q", c.T.Name)
+					if t, ok := renamedTable(p, c.T); ok {
+						text += fmt.Sprintf(", which might have been renamed to %q", t.Name)
+					}
 					diags = append(diags, sqlcheck.Diagnostic{
 						Code: codeDropT,
 						Pos:  sc.Stmt.Pos,
-						Text: fmt.Sprintf("Dropping table %q", c.T.Name),
+						Text: text,
 					})
 				}
 			case *schema.ModifyTable:
+				if !a.exceeds(p, c.T) {
+					continue
+				}
 				for i := range c.Changes {
-					d, ok := c.Changes[i].(*schema.DropColumn)
-					if !ok || p.File.ColumnSpan(c.T, d.C) == sqlcheck.SpanTemporary {
-						continue
-					}
-					if g := (schema.GeneratedExpr{}); !sqlx.Has(d.C.Attrs, &g) || strings.ToUpper(g.Type) != "VIRTUAL" {
-						diags = append(diags, sqlcheck.Diagnostic{
-							Code: codeDropC,
-							Pos:  sc.Stmt.Pos,
-							Text: fmt.Sprintf("Dropping non-virtual column %q", d.C.Name),
-						})
+					switch c1 := c.Changes[i].(type) {
+					case *schema.DropColumn:
+						if p.File.ColumnSpan(c.T, c1.C) == sqlcheck.SpanTemporary {
+							continue
+						}
+						if g := (schema.GeneratedExpr{}); !sqlx.Has(c1.C.Attrs, &g) || strings.ToUpper(g.Type) != "VIRTUAL" {
+							text := fmt.Sprintf("Dropping non-virtual column %q", c1.C.Name)
+							if c2, ok := renamedColumn(p, c.T, c1.C); ok {
+								text += fmt.Sprintf(", which might have been renamed to %q", c2.Name)
+							}
+							diags = append(diags, sqlcheck.Diagnostic{
+								Code: codeDropC,
+								Pos:  sc.Stmt.Pos,
+								Text: text,
+							})
+						}
+					case *schema.ModifyColumn:
+						// Rewrites are reported only for tables that exceed the threshold.
+						if a.Threshold != (migrate.SizeThreshold{}) && migrate.RewritesTable(c1) {
+							diags = append(diags, sqlcheck.Diagnostic{
+								Code: codeRewrT,
+								Pos:  sc.Stmt.Pos,
+								Text: fmt.Sprintf("Changing the type of column %q rewrites table %q", c1.To.Name, c.T.Name),
+							})
+						}
 					}
 				}
 			}
@@ -102,3 +151,101 @@ func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
 	}
 	return nil
 }
+
+// exceeds reports if one of the given tables exceeds the threshold. All tables exceed
+// a zero threshold, as the analyzer reports destructive changes regardless of their size.
+func (a *Analyzer) exceeds(p *sqlcheck.Pass, tables ...*schema.Table) bool {
+	if a.Threshold == (migrate.SizeThreshold{}) {
+		return true
+	}
+	for _, t := range tables {
+		if a.Threshold.Exceeds(migrate.TableStatsOf(p.Stats, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// renamedTable returns the table that was added in the file, and likely replaces the dropped one.
+func renamedTable(p *sqlcheck.Pass, t1 *schema.Table) (*schema.Table, bool) {
+	var (
+		h      schema.RenameHeuristics
+		tables []*schema.Table
+		scores []float64
+	)
+	for _, sc := range p.File.Changes {
+		for _, c := range sc.Changes {
+			if a, ok := c.(*schema.AddTable); ok && a.T != t1 {
+				tables = append(tables, a.T)
+				scores = append(scores, h.TableScore(t1, a.T, tableLayout(t1, a.T)))
+			}
+		}
+	}
+	if i := h.Choose(scores); i != -1 {
+		return tables[i], true
+	}
+	return nil, false
+}
+
+// renamedColumn returns the column that was added to the table
+// in the file, and likely replaces the dropped one.
+func renamedColumn(p *sqlcheck.Pass, t *schema.Table, c1 *schema.Column) (*schema.Column, bool) {
+	var (
+		h       schema.RenameHeuristics
+		columns []*schema.Column
+		scores  []float64
+	)
+	for _, sc := range p.File.Changes {
+		for _, c := range sc.Changes {
+			m, ok := c.(*schema.ModifyTable)
+			if !ok || m.T.Name != t.Name || (m.T.Schema != nil && t.Schema != nil && m.T.Schema.Name != t.Schema.Name) {
+				continue
+			}
+			for _, c := range m.Changes {
+				if a, ok := c.(*schema.AddColumn); ok && h.NamesRelated(c1.Name, a.C.Name) {
+					columns = append(columns, a.C)
+					scores = append(scores, h.ColumnScore(c1, a.C, columnLayout(c1, a.C)))
+				}
+			}
+		}
+	}
+	if i := h.Choose(scores); i != -1 {
+		return columns[i], true
+	}
+	return nil, false
+}
+
+// tableLayout returns the ratio of columns that exist in both tables with the same type.
+func tableLayout(t1, t2 *schema.Table) float64 {
+	n := len(t1.Columns)
+	if len(t2.Columns) > n {
+		n = len(t2.Columns)
+	}
+	if n == 0 {
+		return 0
+	}
+	var same int
+	for _, c1 := range t1.Columns {
+		if c2, ok := t2.Column(c1.Name); ok && columnLayout(c1, c2) == 1 {
+			same++
+		}
+	}
+	return float64(same) / float64(n)
+}
+
+// columnLayout scores the similarity of the column types (0.75) and nullability (0.25).
+// Unlike the differ, types are compared structurally as both were inspected from the
+// dev database.
+func columnLayout(c1, c2 *schema.Column) float64 {
+	if c1.Type == nil || c2.Type == nil {
+		return 0
+	}
+	var score float64
+	if reflect.DeepEqual(c1.Type.Type, c2.Type.Type) {
+		score += 0.75
+	}
+	if c1.Type.Null == c2.Type.Null {
+		score += 0.25
+	}
+	return score
+}
@@ -247,6 +247,143 @@ func TestAnalyzer_DropColumn(t *testing.T) {
 	require.Equal(t, `Dropping non-virtual column "c"`, report.Diagnostics[0].Text)
 }
 
+func TestAnalyzer_LikelyRename(t *testing.T) {
+	var (
+		report sqlcheck.Report
+		users  = schema.NewTable("users").
+			SetSchema(schema.New("test")).
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "varchar(255)"))
+		pass = &sqlcheck.Pass{
+			Dev: &sqlclient.Client{Name: "mysql"},
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: &migrate.Stmt{Text: "ALTER TABLE `pets`"},
+						Changes: []schema.Change{
+							&schema.ModifyTable{
+								T: schema.NewTable("pets").SetSchema(schema.New("test")),
+								Changes: schema.Changes{
+									&schema.DropColumn{C: schema.NewStringColumn("user_name", "varchar(255)")},
+									&schema.AddColumn{C: schema.NewStringColumn("username", "varchar(255)")},
+									&schema.DropColumn{C: schema.NewIntColumn("age", "int")},
+									&schema.AddColumn{C: schema.NewStringColumn("bio", "text")},
+								},
+							},
+						},
+					},
+					{
+						Stmt:    &migrate.Stmt{Text: "DROP TABLE `users`"},
+						Changes: []schema.Change{&schema.DropTable{T: users}},
+					},
+					{
+						Stmt: &migrate.Stmt{Text: "CREATE TABLE `user`"},
+						Changes: []schema.Change{
+							&schema.AddTable{
+								T: schema.NewTable("user").
+									SetSchema(schema.New("test")).
+									AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "varchar(255)")),
+							},
+						},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = r
+			}),
+		}
+	)
+	az, err := destructive.New(nil)
+	require.NoError(t, err)
+	require.Error(t, az.Analyze(context.Background(), pass))
+	require.Len(t, report.Diagnostics, 3)
+	require.Equal(t, `Dropping non-virtual column "user_name", which might have been renamed to "username"`, report.Diagnostics[0].Text)
+	require.Equal(t, `Dropping non-virtual column "age"`, report.Diagnostics[1].Text)
+	require.Equal(t, `Dropping table "users", which might have been renamed to "user"`, report.Diagnostics[2].Text)
+}
+
+func TestAnalyzer_Threshold(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		users  = schema.NewTable("users").
+			SetSchema(schema.New("test")).
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("age", "int"))
+		pass = &sqlcheck.Pass{
+			Dev: &sqlclient.Client{},
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: &migrate.Stmt{
+							Text: "DROP TABLE `posts`",
+						},
+						Changes: schema.Changes{
+							&schema.DropTable{
+								T: schema.NewTable("posts").
+									SetSchema(schema.New("test")),
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{
+							Text: "ALTER TABLE `users` MODIFY COLUMN `age` bigint",
+						},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: schema.Changes{
+									&schema.ModifyColumn{
+										From:   users.Columns[1],
+										To:     schema.NewIntColumn("age", "bigint"),
+										Change: schema.ChangeType,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Stats: schema.NewRealm(
+				schema.New("test").AddTables(
+					schema.NewTable("posts").AddAttrs(&schema.TableStats{Rows: 10}),
+					schema.NewTable("users").AddAttrs(&schema.TableStats{Rows: 1000}),
+				),
+			),
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	az, err := destructive.New(&schemahcl.Resource{
+		Children: []*schemahcl.Resource{
+			{
+				Type: "destructive",
+				Children: []*schemahcl.Resource{
+					{
+						Type: "threshold",
+						Attrs: []*schemahcl.Attr{
+							schemahcl.IntAttr("rows", 100),
+						},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, migrate.SizeThreshold{Rows: 100}, az.Threshold)
+	err = az.Analyze(context.Background(), pass)
+	require.Error(t, err)
+	require.Len(t, report.Diagnostics, 1)
+	require.Equal(t, "DS104", report.Diagnostics[0].Code)
+	require.Equal(t, `Changing the type of column "age" rewrites table "users"`, report.Diagnostics[0].Text)
+
+	// Tables without statistics are not reported, as their size is unknown.
+	report, pass.Stats = nil, nil
+	require.NoError(t, az.Analyze(context.Background(), pass))
+	require.Nil(t, report)
+	require.True(t, az.UsesStats())
+}
+
 type testFile struct {
 	name string
 	migrate.File
@@ -36,6 +36,7 @@ func New(r *schemahcl.Resource) (*Analyzer, error) {
 var (
 	codeRenameT = sqlcheck.Code("BC101")
 	codeRenameC = sqlcheck.Code("BC102")
+	codeMoveC   = sqlcheck.Code("BC103")
 )
 
 // Name of the analyzer. Implements the sqlcheck.NamedAnalyzer interface.
@@ -45,10 +46,31 @@ func (*Analyzer) Name() string {
 
 // Analyze implements sqlcheck.Analyzer.
 func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
-	var diags []sqlcheck.Diagnostic
+	var (
+		diags   []sqlcheck.Diagnostic
+		dropped = make(map[string]*schema.Table)
+		// Databases that cannot move columns (e.g., PostgreSQL), require recreating
+		// the table in order to change the column order. Hence, such changes are
+		// detected when a dropped table is replaced by a table with the same name.
+		replaced = func(pos int, t *schema.Table) {
+			if t1, ok := dropped[tableKey(t)]; ok && orderChanged(t1, t) {
+				diags = append(diags, sqlcheck.Diagnostic{
+					Code: codeMoveC,
+					Pos:  pos,
+					Text: fmt.Sprintf("Changing the column order of table %q", t.Name),
+				})
+			}
+		}
+	)
 	for i, sc := range p.File.Changes {
 		for _, c := range sc.Changes {
 			switch c := c.(type) {
+			case *schema.DropTable:
+				if p.File.TableSpan(c.T)&sqlcheck.SpanAdded == 0 {
+					dropped[tableKey(c.T)] = c.T
+				}
+			case *schema.AddTable:
+				replaced(sc.Stmt.Pos, c.T)
 			case *schema.RenameTable:
 				if p.File.SchemaSpan(c.From.Schema)&sqlcheck.SpanAdded == 0 && p.File.TableSpan(c.From)&sqlcheck.SpanAdded == 0 &&
 					!ViewForRenamedT(p.File, c.From.Name, c.To.Name, sc.Stmt.Pos) {
@@ -58,15 +80,28 @@ func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
 						Text: fmt.Sprintf("Renaming table %q to %q", c.From.Name, c.To.Name),
 					})
 				}
+				replaced(sc.Stmt.Pos, c.To)
 			case *schema.ModifyTable:
 				for j := range c.Changes {
-					r, ok := c.Changes[j].(*schema.RenameColumn)
-					if ok && p.File.TableSpan(c.T)&sqlcheck.SpanAdded == 0 && !wasAddedBack(p.File.Changes[i:], r.From) {
-						diags = append(diags, sqlcheck.Diagnostic{
-							Code: codeRenameC,
-							Pos:  sc.Stmt.Pos,
-							Text: fmt.Sprintf("Renaming column %q to %q", r.From.Name, r.To.Name),
-						})
+					switch r := c.Changes[j].(type) {
+					case *schema.RenameColumn:
+						if p.File.TableSpan(c.T)&sqlcheck.SpanAdded == 0 && !wasAddedBack(p.File.Changes[i:], r.From) {
+							diags = append(diags, sqlcheck.Diagnostic{
+								Code: codeRenameC,
+								Pos:  sc.Stmt.Pos,
+								Text: fmt.Sprintf("Renaming column %q to %q", r.From.Name, r.To.Name),
+							})
+						}
+					// Applications that rely on the column order (e.g., SELECT * or INSERT
+					// without a column list) may break when columns are moved.
+					case *schema.ModifyColumn:
+						if r.Change.Is(schema.ChangePosition) && p.File.TableSpan(c.T)&sqlcheck.SpanAdded == 0 {
+							diags = append(diags, sqlcheck.Diagnostic{
+								Code: codeMoveC,
+								Pos:  sc.Stmt.Pos,
+								Text: fmt.Sprintf("Changing the position of column %q in table %q", r.To.Name, c.T.Name),
+							})
+						}
 					}
 				}
 			}
@@ -96,6 +131,36 @@ func ViewForRenamedT(f *sqlcheck.File, old, new string, pos int) bool {
 	return created
 }
 
+// tableKey returns the key of the table in the dropped tables map.
+func tableKey(t *schema.Table) string {
+	if t.Schema != nil {
+		return t.Schema.Name + "." + t.Name
+	}
+	return t.Name
+}
+
+// orderChanged reports if the relative order of the columns that
+// exist in both tables was changed between the two versions.
+func orderChanged(from, to *schema.Table) bool {
+	var names []string
+	for _, c := range from.Columns {
+		if _, ok := to.Column(c.Name); ok {
+			names = append(names, c.Name)
+		}
+	}
+	var i int
+	for _, c := range to.Columns {
+		if _, ok := from.Column(c.Name); !ok {
+			continue
+		}
+		if names[i] != c.Name {
+			return true
+		}
+		i++
+	}
+	return false
+}
+
 func wasAddedBack(changes []*sqlcheck.Change, old *schema.Column) bool {
 	for _, sc := range changes {
 		for _, c := range sc.Changes {
@@ -259,6 +259,120 @@ func TestAnalyzer_RenameTable(t *testing.T) {
 	require.Equal(t, `Renaming table "pets" to "Pets"`, report.Diagnostics[0].Text)
 }
 
+func TestAnalyzer_ColumnPosition(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		s      = schema.New("test")
+		users  = func(cs ...string) *schema.Table {
+			t := schema.NewTable("users").SetSchema(s)
+			for _, c := range cs {
+				t.AddColumns(schema.NewIntColumn(c, "int"))
+			}
+			return t
+		}
+		pass = &sqlcheck.Pass{
+			Dev: &sqlclient.Client{},
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					// MySQL.
+					{
+						Stmt: &migrate.Stmt{
+							Text: "ALTER TABLE `users` MODIFY COLUMN `b` int NOT NULL FIRST",
+							Pos:  1,
+						},
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users("b", "a"),
+								Changes: schema.Changes{
+									&schema.ModifyColumn{
+										From:   schema.NewIntColumn("b", "int"),
+										To:     schema.NewIntColumn("b", "int"),
+										Change: schema.ChangePosition,
+									},
+								},
+							},
+						},
+					},
+					// PostgreSQL. Table is recreated with a different column order.
+					{
+						Stmt: &migrate.Stmt{
+							Text: "CREATE TABLE pets (id int, name int)",
+							Pos:  2,
+						},
+						Changes: schema.Changes{
+							&schema.AddTable{
+								T: schema.NewTable("pets_new").SetSchema(s).AddColumns(schema.NewIntColumn("name", "int"), schema.NewIntColumn("id", "int")),
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{
+							Text: "DROP TABLE pets",
+							Pos:  3,
+						},
+						Changes: schema.Changes{
+							&schema.DropTable{
+								T: schema.NewTable("pets").SetSchema(s).AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("name", "int"), schema.NewIntColumn("age", "int")),
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{
+							Text: "ALTER TABLE pets_new RENAME TO pets",
+							Pos:  4,
+						},
+						Changes: schema.Changes{
+							&schema.RenameTable{
+								From: schema.NewTable("pets_new").SetSchema(s),
+								To:   schema.NewTable("pets").SetSchema(s).AddColumns(schema.NewIntColumn("name", "int"), schema.NewIntColumn("id", "int")),
+							},
+						},
+					},
+					// Recreating a table with the same column order is allowed.
+					{
+						Stmt: &migrate.Stmt{
+							Text: "DROP TABLE cards",
+							Pos:  5,
+						},
+						Changes: schema.Changes{
+							&schema.DropTable{
+								T: schema.NewTable("cards").SetSchema(s).AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("name", "int")),
+							},
+						},
+					},
+					{
+						Stmt: &migrate.Stmt{
+							Text: "CREATE TABLE cards (id int, number int, name int)",
+							Pos:  6,
+						},
+						Changes: schema.Changes{
+							&schema.AddTable{
+								T: schema.NewTable("cards").SetSchema(s).AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("number", "int"), schema.NewIntColumn("name", "int")),
+							},
+						},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	az, err := incompatible.New(nil)
+	require.NoError(t, err)
+	err = az.Analyze(context.Background(), pass)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Len(t, report.Diagnostics, 2)
+	require.Equal(t, "BC103", report.Diagnostics[0].Code)
+	require.Equal(t, 1, report.Diagnostics[0].Pos)
+	require.Equal(t, `Changing the position of column "b" in table "users"`, report.Diagnostics[0].Text)
+	require.Equal(t, "BC103", report.Diagnostics[1].Code)
+	require.Equal(t, 4, report.Diagnostics[1].Pos)
+	require.Equal(t, `Changing the column order of table "pets"`, report.Diagnostics[1].Text)
+}
+
 type testFile struct {
 	name  string
 	stmts []*migrate.Stmt
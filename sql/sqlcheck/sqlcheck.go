@@ -35,6 +35,14 @@ type (
 		Name() string
 	}
 
+	// A StatsAnalyzer describes an Analyzer that takes the size of the tables into
+	// account, and therefore requires the Pass.Stats realm to be populated.
+	StatsAnalyzer interface {
+		Analyzer
+		// UsesStats reports if the analyzer reads the table statistics.
+		UsesStats() bool
+	}
+
 	// A Pass provides information to the Run function that
 	// applies a specific analyzer to an SQL file.
 	Pass struct {
@@ -46,6 +54,11 @@ type (
 
 		// Report reports analysis reports.
 		Reporter ReportWriter
+
+		// Stats is an optional realm that holds the statistics of the tables in the
+		// target database. It is used by analyzers that take the size of the tables
+		// into account. See schema.InspectRealmOption.Stats for more info.
+		Stats *schema.Realm
 	}
 
 	// File represents a parsed version of a migration file.
@@ -6,12 +6,16 @@ package sqlclient
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/url"
+	"strconv"
 	"sync"
+	"time"
 
 	"ariga.io/atlas/schemahcl"
 	"ariga.io/atlas/sql/migrate"
@@ -45,6 +49,12 @@ type (
 		// Functions registered by the drivers and used for opening transactions and their clients.
 		openDriver func(schema.ExecQuerier) (migrate.Driver, error)
 		openTx     TxOpener
+
+		// Indicates the client was opened in read-only mode.
+		readOnly bool
+
+		// Interceptors of the driver, applied also on transactions.
+		interceptors []Interceptor
 	}
 
 	// TxClient is returned by calling Client.Tx. It behaves the same as Client,
@@ -66,6 +76,11 @@ type (
 
 		// The Schema this client is connected to.
 		Schema string
+
+		// Schemas holds the schemas (e.g. MySQL databases) the client is limited
+		// to, if more than one were set in the URL. In this case, Schema is empty,
+		// and the client operates on these schemas as a realm.
+		Schemas []string
 	}
 )
 
@@ -74,6 +89,13 @@ func (c *Client) Tx(ctx context.Context, opts *sql.TxOptions) (*TxClient, error)
 	if c.openDriver == nil {
 		return nil, errors.New("sql/sqlclient: unexpected driver opener: <nil>")
 	}
+	if c.readOnly {
+		ro := sql.TxOptions{ReadOnly: true}
+		if opts != nil {
+			ro.Isolation = opts.Isolation
+		}
+		opts = &ro
+	}
 	var tx *Tx
 	switch {
 	case c.openTx != nil:
@@ -89,10 +111,13 @@ func (c *Client) Tx(ctx context.Context, opts *sql.TxOptions) (*TxClient, error)
 		}
 		tx = &Tx{Tx: ttx}
 	}
-	drv, err := c.openDriver(tx)
+	drv, err := c.openDriver(Intercept(tx, c.interceptors...))
 	if err != nil {
 		return nil, fmt.Errorf("sql/sqlclient: opening atlas driver: %w", err)
 	}
+	if c.readOnly {
+		drv = &readOnlyDriver{Driver: drv}
+	}
 	ic := *c
 	ic.Driver = drv
 	return &TxClient{Client: &ic, Tx: tx}, nil
@@ -149,11 +174,25 @@ type (
 		ChangeSchema(*url.URL, string) *url.URL
 	}
 
+	// ReadOnlyChanger is implemented by a driver if it knows how to change the connection URL
+	// to open read-only sessions. For example, by setting the default transaction mode.
+	ReadOnlyChanger interface {
+		ChangeReadOnly(*url.URL) *url.URL
+	}
+
 	driver struct {
 		Opener
 		name     string
 		parser   URLParser
 		txOpener TxOpener
+		secrets  []string
+		// Registered by RegisterDriverOpener and RegisterCodec,
+		// and used for opening clients on existing connections.
+		openDriver func(schema.ExecQuerier) (migrate.Driver, error)
+		codec      interface {
+			schemahcl.Marshaler
+			schemahcl.Evaluator
+		}
 	}
 )
 
@@ -180,16 +219,78 @@ func ParseURL(s string) (*url.URL, error) {
 	return u, nil
 }
 
+// Redacted is like url.URL.Redacted, but also redacts the query
+// parameters that were registered as secrets by the driver.
+func (u *URL) Redacted() string {
+	return RedactURL(u.URL)
+}
+
+// RedactURL returns the URL string with the password and the values of the query
+// parameters that were registered as secrets by the driver replaced with "xxxxx".
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	v, ok := drivers.Load(u.Scheme)
+	if !ok || len(v.(*driver).secrets) == 0 {
+		return u.Redacted()
+	}
+	q := u.Query()
+	for _, p := range v.(*driver).secrets {
+		if q.Has(p) {
+			q.Set(p, "xxxxx")
+		}
+	}
+	u1 := *u
+	u1.RawQuery = q.Encode()
+	return u1.Redacted()
+}
+
 var drivers sync.Map
 
 type (
 	// openOptions holds additional configuration values for opening a Client.
 	openOptions struct {
-		schema *string
+		schema       *string
+		pool         *PoolOptions
+		tls          *tls.Config
+		dialer       Dialer
+		creds        CredentialsProvider
+		ssh          *SSHTunnel
+		readOnly     bool
+		interceptors []Interceptor
 	}
 
 	// OpenOption allows to configure a openOptions using functional arguments.
 	OpenOption func(*openOptions) error
+
+	// PoolOptions configures the connection pool of the client database.
+	// Zero values keep the defaults of the database/sql package.
+	//
+	// The options can also be set using the following URL query parameters,
+	// that are removed from the URL before it is passed to the driver:
+	//
+	//	max_open_conns, max_idle_conns, conn_max_lifetime, conn_max_idle_time
+	//
+	PoolOptions struct {
+		MaxOpenConns    int
+		MaxIdleConns    int
+		ConnMaxLifetime time.Duration
+		ConnMaxIdleTime time.Duration
+	}
+
+	// Dialer is a function for dialing the database server.
+	Dialer func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// ctxKey is the type of the context keys used by this package.
+	ctxKey struct{ name string }
+)
+
+// Context keys for passing the TLS configuration, dialer and credentials to openers.
+var (
+	tlsKey    = ctxKey{"tls"}
+	dialerKey = ctxKey{"dialer"}
+	credsKey  = ctxKey{"credentials"}
 )
 
 // ErrUnsupported is returned if a registered driver does not support changing the schema.
@@ -228,16 +329,76 @@ func OpenURL(ctx context.Context, u *url.URL, opts ...OpenOption) (*Client, erro
 		}
 		u = sc.ChangeSchema(u, *cfg.schema)
 	}
+	u, pool, err := poolFromURL(u)
+	if err != nil {
+		return nil, err
+	}
+	// Options passed explicitly take precedence over the URL.
+	if cfg.pool != nil {
+		pool = cfg.pool
+	}
+	if cfg.tls != nil {
+		ctx = context.WithValue(ctx, tlsKey, cfg.tls)
+	}
+	if cfg.dialer != nil {
+		ctx = context.WithValue(ctx, dialerKey, cfg.dialer)
+	}
+	u, creds, err := credsFromURL(u)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.creds != nil {
+		creds = cfg.creds
+	}
+	if creds != nil {
+		ctx = context.WithValue(ctx, credsKey, creds)
+	}
+	u, ssh, err := sshFromURL(u)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ssh != nil {
+		ssh = cfg.ssh
+	}
+	var tunnel io.Closer
+	if ssh != nil {
+		if u, tunnel, err = ssh.open(ctx, u); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.readOnly {
+		if rc, ok := drv.parser.(ReadOnlyChanger); ok {
+			u = rc.ChangeReadOnly(u)
+		}
+	}
 	client, err := drv.Open(ctx, u)
 	if err != nil {
+		if tunnel != nil {
+			err = errors.Join(err, tunnel.Close())
+		}
 		return nil, err
 	}
+	if tunnel != nil {
+		client.AddClosers(tunnel)
+	}
+	if pool != nil && client.DB != nil {
+		pool.apply(client.DB)
+	}
 	if client.URL == nil {
 		client.URL = drv.parser.ParseURL(u)
 	}
 	if client.openTx == nil && drv.txOpener != nil {
 		client.openTx = drv.txOpener
 	}
+	if len(cfg.interceptors) > 0 {
+		if err := client.intercept(cfg.interceptors); err != nil {
+			return nil, errors.Join(err, client.Close())
+		}
+	}
+	if cfg.readOnly {
+		client.readOnly = true
+		client.Driver = &readOnlyDriver{Driver: client.Driver}
+	}
 	return client, nil
 }
 
@@ -250,12 +411,151 @@ func OpenSchema(s string) OpenOption {
 	}
 }
 
+// ErrReadOnly is returned when a write operation is executed on a read-only client.
+var ErrReadOnly = errors.New("sql/sqlclient: write operation on a read-only client")
+
+// OpenReadOnly opens the client in read-only mode, that guarantees no writes are executed
+// by Atlas. The sessions are set to read-only in case the driver supports it, and the
+// client driver rejects calls to ExecContext and ApplyChanges with ErrReadOnly. Note that
+// driver-specific capabilities (e.g. locking, normalization or snapshots) are not exposed
+// by read-only clients. This mode is useful for running drift detection against production.
+func OpenReadOnly() OpenOption {
+	return func(c *openOptions) error {
+		c.readOnly = true
+		return nil
+	}
+}
+
+// readOnlyDriver wraps a migrate.Driver and rejects write operations.
+type readOnlyDriver struct {
+	migrate.Driver
+}
+
+// ExecContext implements the schema.ExecQuerier interface.
+func (*readOnlyDriver) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, ErrReadOnly
+}
+
+// ApplyChanges implements the migrate.PlanApplier interface.
+func (*readOnlyDriver) ApplyChanges(context.Context, []schema.Change, ...migrate.PlanOption) error {
+	return ErrReadOnly
+}
+
+// OpenPool configures the connection pool of the opened client.
+func OpenPool(opts PoolOptions) OpenOption {
+	return func(c *openOptions) error {
+		if err := opts.validate(); err != nil {
+			return err
+		}
+		c.pool = &opts
+		return nil
+	}
+}
+
+// OpenTLS sets the TLS configuration used for connecting to the database. The configuration
+// is passed to the registered Opener through its context and can be retrieved using the
+// TLSConfigFromContext function. Note, drivers that open their connections using the DSN
+// (e.g. MySQL, PostgreSQL and SQLite) do not support this option and fail to open the client.
+// Use the TLS parameters of their URLs instead, e.g. "sslmode=verify-full" for PostgreSQL.
+func OpenTLS(cfg *tls.Config) OpenOption {
+	return func(c *openOptions) error {
+		c.tls = cfg
+		return nil
+	}
+}
+
+// OpenDialer sets the dialer used for connecting to the database. Like the TLS configuration,
+// the dialer is passed to the registered Opener through its context and can be retrieved using
+// the DialerFromContext function, and is not supported by drivers that open their connections
+// using the DSN.
+func OpenDialer(d Dialer) OpenOption {
+	return func(c *openOptions) error {
+		c.dialer = d
+		return nil
+	}
+}
+
+// TLSConfigFromContext returns the TLS configuration set by OpenTLS, if any.
+func TLSConfigFromContext(ctx context.Context) (*tls.Config, bool) {
+	cfg, ok := ctx.Value(tlsKey).(*tls.Config)
+	return cfg, ok
+}
+
+// DialerFromContext returns the dialer set by OpenDialer, if any.
+func DialerFromContext(ctx context.Context) (Dialer, bool) {
+	d, ok := ctx.Value(dialerKey).(Dialer)
+	return d, ok
+}
+
+// validate checks that the pool options are valid.
+func (o *PoolOptions) validate() error {
+	if o.MaxOpenConns < 0 || o.MaxIdleConns < 0 || o.ConnMaxLifetime < 0 || o.ConnMaxIdleTime < 0 {
+		return errors.New("sql/sqlclient: pool options must not be negative")
+	}
+	return nil
+}
+
+// apply applies the pool options on the given database.
+func (o *PoolOptions) apply(db *sql.DB) {
+	if o.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(o.MaxOpenConns)
+	}
+	if o.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(o.MaxIdleConns)
+	}
+	if o.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(o.ConnMaxLifetime)
+	}
+	if o.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(o.ConnMaxIdleTime)
+	}
+}
+
+// poolFromURL extracts the pool options from the URL query, and returns
+// a copy of the URL without them. A nil PoolOptions is returned in case
+// the URL does not configure the pool.
+func poolFromURL(u *url.URL) (*url.URL, *PoolOptions, error) {
+	q := u.Query()
+	var (
+		opts  PoolOptions
+		found bool
+	)
+	for _, p := range []struct {
+		name string
+		set  func(string) error
+	}{
+		{"max_open_conns", func(v string) (err error) { opts.MaxOpenConns, err = strconv.Atoi(v); return }},
+		{"max_idle_conns", func(v string) (err error) { opts.MaxIdleConns, err = strconv.Atoi(v); return }},
+		{"conn_max_lifetime", func(v string) (err error) { opts.ConnMaxLifetime, err = time.ParseDuration(v); return }},
+		{"conn_max_idle_time", func(v string) (err error) { opts.ConnMaxIdleTime, err = time.ParseDuration(v); return }},
+	} {
+		if !q.Has(p.name) {
+			continue
+		}
+		if err := p.set(q.Get(p.name)); err != nil {
+			return nil, nil, fmt.Errorf("sql/sqlclient: invalid %s value %q: %w", p.name, q.Get(p.name), err)
+		}
+		q.Del(p.name)
+		found = true
+	}
+	if !found {
+		return u, nil, nil
+	}
+	if err := opts.validate(); err != nil {
+		return nil, nil, err
+	}
+	u1 := *u
+	u1.RawQuery = q.Encode()
+	return &u1, &opts, nil
+}
+
 type (
 	registerOptions struct {
 		openDriver func(schema.ExecQuerier) (migrate.Driver, error)
 		txOpener   TxOpener
 		parser     URLParser
 		flavours   []string
+		secrets    []string
 		codec      interface {
 			schemahcl.Marshaler
 			schemahcl.Evaluator
@@ -274,6 +574,14 @@ func RegisterFlavours(flavours ...string) RegisterOption {
 	}
 }
 
+// RegisterSecretParams allows registering the query parameters of the URL that
+// hold credentials (e.g. tokens), and are redacted by URL.Redacted and RedactURL.
+func RegisterSecretParams(params ...string) RegisterOption {
+	return func(opts *registerOptions) {
+		opts.secrets = params
+	}
+}
+
 // RegisterURLParser allows registering a function for parsing
 // the url.URL and attach additional info to the extended URL.
 func RegisterURLParser(p URLParser) RegisterOption {
@@ -307,14 +615,13 @@ func RegisterDriverOpener(open func(schema.ExecQuerier) (migrate.Driver, error))
 
 // DriverOpener is a helper Opener creator for sharing between all drivers.
 func DriverOpener(open func(schema.ExecQuerier) (migrate.Driver, error)) Opener {
-	return OpenerFunc(func(_ context.Context, u *url.URL) (*Client, error) {
+	return &driverOpener{open: open, OpenerFunc: func(ctx context.Context, u *url.URL) (*Client, error) {
 		v, ok := drivers.Load(u.Scheme)
 		if !ok {
 			return nil, fmt.Errorf("sql/sqlclient: unexpected missing opener %q", u.Scheme)
 		}
 		drv := v.(*driver)
-		ur := drv.parser.ParseURL(u)
-		db, err := sql.Open(drv.name, ur.DSN)
+		db, err := OpenSQL(ctx, u)
 		if err != nil {
 			return nil, err
 		}
@@ -328,12 +635,42 @@ func DriverOpener(open func(schema.ExecQuerier) (migrate.Driver, error)) Opener
 		return &Client{
 			Name:       drv.name,
 			DB:         db,
-			URL:        ur,
+			URL:        drv.parser.ParseURL(u),
 			Driver:     mdr,
 			openDriver: open,
 			openTx:     drv.txOpener,
 		}, nil
-	})
+	}}
+}
+
+// OpenSQL opens a database handle for the given URL using the database/sql driver registered
+// for its scheme, and is used by openers that connect to the database using the driver DSN.
+// Credentials set by OpenCredentials are fetched for every new connection. Since TLS
+// configurations and dialers cannot be passed through the DSN, OpenSQL fails in case
+// they were set by OpenTLS or OpenDialer.
+func OpenSQL(ctx context.Context, u *url.URL) (*sql.DB, error) {
+	v, ok := drivers.Load(u.Scheme)
+	if !ok {
+		return nil, fmt.Errorf("sql/sqlclient: unexpected missing opener %q", u.Scheme)
+	}
+	drv := v.(*driver)
+	if _, ok := TLSConfigFromContext(ctx); ok {
+		return nil, fmt.Errorf("sql/sqlclient: driver %q does not support custom TLS configuration. Use the URL parameters of the driver instead", u.Scheme)
+	}
+	if _, ok := DialerFromContext(ctx); ok {
+		return nil, fmt.Errorf("sql/sqlclient: driver %q does not support custom dialers", u.Scheme)
+	}
+	if creds, ok := CredentialsFromContext(ctx); ok {
+		return openCreds(drv, u, creds)
+	}
+	return sql.Open(drv.name, drv.parser.ParseURL(u).DSN)
+}
+
+// driverOpener is the Opener returned by DriverOpener. It allows OpenDB
+// to open drivers that were not registered with RegisterDriverOpener.
+type driverOpener struct {
+	OpenerFunc
+	open func(schema.ExecQuerier) (migrate.Driver, error)
 }
 
 type (
@@ -377,6 +714,18 @@ func Register(name string, opener Opener, opts ...RegisterOption) {
 	if opener == nil {
 		panic("sql/sqlclient: Register opener is nil")
 	}
+	if dup := register(name, opener, opts...); dup != "" {
+		panic("sql/sqlclient: Register called twice for " + dup)
+	}
+}
+
+// registerMu guards the registration of drivers and plugins, that may be
+// registered at runtime, after the program was initialized.
+var registerMu sync.Mutex
+
+// register registers the opener with the given name and its flavours. If one of the
+// names is already registered, nothing is registered and the name is returned.
+func register(name string, opener Opener, opts ...RegisterOption) (dup string) {
 	opt := &registerOptions{
 		// Default URL parser uses the URL as the DSN.
 		parser: URLParserFunc(func(u *url.URL) *URL { return &URL{URL: u, DSN: u.String()} }),
@@ -384,6 +733,10 @@ func Register(name string, opener Opener, opts ...RegisterOption) {
 	for i := range opts {
 		opts[i](opt)
 	}
+	openDriver := opt.openDriver
+	if o, ok := opener.(*driverOpener); ok && openDriver == nil {
+		openDriver = o.open
+	}
 	if opt.codec != nil {
 		f := opener
 		opener = OpenerFunc(func(ctx context.Context, u *url.URL) (*Client, error) {
@@ -407,11 +760,17 @@ func Register(name string, opener Opener, opts ...RegisterOption) {
 			return c, err
 		})
 	}
-	drv := &driver{Opener: opener, name: name, parser: opt.parser, txOpener: opt.txOpener}
-	for _, f := range append(opt.flavours, name) {
+	drv := &driver{Opener: opener, name: name, parser: opt.parser, txOpener: opt.txOpener, secrets: opt.secrets, openDriver: openDriver, codec: opt.codec}
+	names := append(opt.flavours, name)
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	for _, f := range names {
 		if _, ok := drivers.Load(f); ok {
-			panic("sql/sqlclient: Register called twice for " + f)
+			return f
 		}
+	}
+	for _, f := range names {
 		drivers.Store(f, drv)
 	}
+	return ""
 }
@@ -0,0 +1,321 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Schemes of the cloud URLs that are resolved to the URLs of the underlying database engine.
+const (
+	SchemeAWSRDS = "aws-rds"
+	SchemeGCP    = "gcp"
+)
+
+// openAWSRDS opens a client for an RDS instance, cluster or proxy endpoint using IAM authentication.
+// The URL format is as follows, where the engine is inferred from the port if it is not set:
+//
+//	aws-rds://user@endpoint[:port]/database?engine=mysql|postgres
+func openAWSRDS(ctx context.Context, u *url.URL) (*Client, error) {
+	u, err := awsRDSURL(ctx, u, rdsCAEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	return OpenURL(ctx, u)
+}
+
+// awsRDSURL resolves the aws-rds URL to the URL of the database engine. Server certificates
+// are verified using the RDS CA bundle, that is fetched from the given endpoint if needed.
+func awsRDSURL(ctx context.Context, u *url.URL, caEndpoint string) (*url.URL, error) {
+	q := u.Query()
+	engine := q.Get("engine")
+	q.Del("engine")
+	if engine == "" {
+		switch u.Port() {
+		case "3306":
+			engine = "mysql"
+		case "5432":
+			engine = "postgres"
+		default:
+			return nil, fmt.Errorf("sql/sqlclient: missing engine parameter for %s URL", SchemeAWSRDS)
+		}
+	}
+	return cloudURL(u, engine, u.Host, q, CredentialsAWSRDSIAM, func() (*serverCA, error) {
+		b, err := getPEM(ctx, caEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlclient: fetch RDS CA bundle: %w", err)
+		}
+		return &serverCA{name: "aws-rds", pem: b}, nil
+	})
+}
+
+// openGCP opens a client for a Cloud SQL instance. The address and the engine of the
+// instance are resolved using the Cloud SQL Admin API, and the connection is made
+// directly with IAM authentication and TLS, without requiring the Cloud SQL Auth Proxy.
+// The URL format is as follows:
+//
+//	gcp://user@project/region/instance/database?ip_type=public|private
+func openGCP(ctx context.Context, u *url.URL) (*Client, error) {
+	u, err := gcpURL(ctx, u, cloudSQLAdminEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	return OpenURL(ctx, u)
+}
+
+// gcpURL resolves the gcp URL to the URL of the database engine, using the given Cloud SQL Admin API endpoint.
+func gcpURL(ctx context.Context, u *url.URL, endpoint string) (*url.URL, error) {
+	path := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if u.Host == "" || len(path) < 2 || path[0] == "" || path[1] == "" {
+		return nil, fmt.Errorf("sql/sqlclient: invalid %s URL. Expect: gcp://user@project/region/instance/database", SchemeGCP)
+	}
+	q := u.Query()
+	ipType := q.Get("ip_type")
+	q.Del("ip_type")
+	switch strings.ToLower(ipType) {
+	case "", "public":
+		ipType = "PRIMARY"
+	case "private":
+		ipType = "PRIVATE"
+	default:
+		return nil, fmt.Errorf("sql/sqlclient: unknown ip_type %q", ipType)
+	}
+	s, err := cloudSQLSettings(ctx, endpoint, u.Host, path[1])
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlclient: resolve Cloud SQL instance %s:%s: %w", u.Host, path[1], err)
+	}
+	var engine string
+	switch v := strings.ToUpper(s.DatabaseVersion); {
+	case strings.HasPrefix(v, "MYSQL"):
+		engine = "mysql"
+	case strings.HasPrefix(v, "POSTGRES"):
+		engine = "postgres"
+	default:
+		return nil, fmt.Errorf("sql/sqlclient: unsupported Cloud SQL database version %q", s.DatabaseVersion)
+	}
+	var host string
+	for _, ip := range s.IPAddresses {
+		if ip.Type == ipType {
+			host = ip.IPAddress
+		}
+	}
+	if host == "" {
+		return nil, fmt.Errorf("sql/sqlclient: Cloud SQL instance %s:%s has no %s ip address", u.Host, path[1], strings.ToLower(ipType))
+	}
+	u1 := *u
+	u1.Path = "/" + strings.Join(path[2:], "/")
+	return cloudURL(&u1, engine, host, q, CredentialsGCPCloudSQL, func() (*serverCA, error) {
+		if s.ServerCACert.Cert == "" {
+			return nil, fmt.Errorf("sql/sqlclient: Cloud SQL instance %s:%s has no server CA certificate", u.Host, path[1])
+		}
+		// Server certificates are issued for the instance name, and not for its IP address.
+		return &serverCA{name: "gcp-" + u.Host + "-" + path[1], pem: []byte(s.ServerCACert.Cert), chainOnly: true}, nil
+	})
+}
+
+// cloudURL returns the URL of the given engine and host. Unless the URL contains a password,
+// the given credentials provider is used. Unless configured otherwise, TLS is required and the
+// server certificates are verified using the CA bundle of the provider.
+func cloudURL(u *url.URL, engine, host string, q url.Values, creds string, ca func() (*serverCA, error)) (*url.URL, error) {
+	if _, ok := u.User.Password(); !ok && !q.Has(credsParam) {
+		q.Set(credsParam, creds)
+	}
+	switch engine {
+	case "mysql":
+		if !q.Has("tls") {
+			// The MySQL driver accepts custom TLS configurations only by name. Without
+			// a registered function, certificates are verified using the system roots.
+			v := "true"
+			if f, ok := tlsFuncs.Load(engine); ok {
+				c, err := ca()
+				if err != nil {
+					return nil, err
+				}
+				if v, err = c.register(f.(func(string, *tls.Config) error)); err != nil {
+					return nil, err
+				}
+			}
+			q.Set("tls", v)
+		}
+		// Tokens are sent in cleartext, and therefore, TLS is required.
+		if q.Get(credsParam) != "" && !q.Has("allowCleartextPasswords") {
+			q.Set("allowCleartextPasswords", "1")
+		}
+	case "postgres":
+		if !q.Has("sslmode") {
+			c, err := ca()
+			if err != nil {
+				return nil, err
+			}
+			path, err := c.file()
+			if err != nil {
+				return nil, err
+			}
+			q.Set("sslmode", "verify-full")
+			if c.chainOnly {
+				q.Set("sslmode", "verify-ca")
+			}
+			q.Set("sslrootcert", path)
+		}
+	default:
+		return nil, fmt.Errorf("sql/sqlclient: unsupported engine %q", engine)
+	}
+	u1 := *u
+	u1.Scheme, u1.Host, u1.RawQuery = engine, host, q.Encode()
+	return &u1, nil
+}
+
+// tlsFuncs holds the functions for registering named TLS configurations on database/sql drivers.
+var tlsFuncs sync.Map
+
+// RegisterTLSConfigFunc registers the function used for registering named TLS configurations on
+// the database/sql driver of the given engine. Cloud URLs use it to verify the server certificates
+// of MySQL databases using the CA bundle of the provider, as its driver accepts custom TLS
+// configurations only by name. For example:
+//
+//	sqlclient.RegisterTLSConfigFunc("mysql", mysql.RegisterTLSConfig)
+func RegisterTLSConfigFunc(engine string, f func(string, *tls.Config) error) {
+	if f == nil {
+		panic("sql/sqlclient: RegisterTLSConfigFunc function is nil")
+	}
+	tlsFuncs.Store(engine, f)
+}
+
+// rdsCAEndpoint serves the CA bundle of all AWS RDS regions.
+const rdsCAEndpoint = "https://truststore.pki.rds.amazonaws.com/global/global-bundle.pem"
+
+// serverCA holds the CA bundle used for verifying the server certificates of a cloud database.
+type serverCA struct {
+	name string
+	pem  []byte
+	// chainOnly indicates that only the certificate chain can be verified,
+	// as the server certificate is not issued for the dialed address.
+	chainOnly bool
+}
+
+// id returns a unique name for the bundle.
+func (c *serverCA) id() string {
+	h := sha256.Sum256(c.pem)
+	return fmt.Sprintf("atlas-%s-%x", c.name, h[:8])
+}
+
+// file writes the bundle to the user cache directory, and returns its path.
+func (c *serverCA) file() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("sql/sqlclient: locate cache directory for CA bundle: %w", err)
+	}
+	dir = filepath.Join(dir, "atlas", "certs")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("sql/sqlclient: create CA bundle directory: %w", err)
+	}
+	path := filepath.Join(dir, c.id()+".pem")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.WriteFile(path, c.pem, 0600); err != nil {
+		return "", fmt.Errorf("sql/sqlclient: write CA bundle: %w", err)
+	}
+	return path, nil
+}
+
+// register registers the TLS configuration of the bundle using f, and returns its name.
+func (c *serverCA) register(f func(string, *tls.Config) error) (string, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(c.pem) {
+		return "", fmt.Errorf("sql/sqlclient: no certificates found in %s CA bundle", c.name)
+	}
+	cfg := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	if c.chainOnly {
+		// The standard verification is skipped, as it checks the hostname too, and the chain
+		// is verified manually. This is the equivalent of the "verify-ca" mode of PostgreSQL.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("sql/sqlclient: missing server certificate")
+			}
+			opts := x509.VerifyOptions{Roots: pool, Intermediates: x509.NewCertPool()}
+			for _, ic := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(ic)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		}
+	}
+	name := c.id()
+	if err := f(name, cfg); err != nil {
+		return "", fmt.Errorf("sql/sqlclient: register %s TLS configuration: %w", c.name, err)
+	}
+	return name, nil
+}
+
+// getPEM fetches the PEM-encoded certificates served by the given endpoint.
+func getPEM(ctx context.Context, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// cloudSQLAdminEndpoint is the endpoint of the Cloud SQL Admin API.
+const cloudSQLAdminEndpoint = "https://sqladmin.googleapis.com/sql/v1beta4"
+
+// cloudSQLConnectSettings describes the connection settings of a Cloud SQL instance.
+type cloudSQLConnectSettings struct {
+	DatabaseVersion string `json:"databaseVersion"`
+	ServerCACert    struct {
+		Cert string `json:"cert"`
+	} `json:"serverCaCert"`
+	IPAddresses []struct {
+		Type      string `json:"type"`
+		IPAddress string `json:"ipAddress"`
+	} `json:"ipAddresses"`
+}
+
+// cloudSQLSettings fetches the connection settings of the given instance.
+func cloudSQLSettings(ctx context.Context, endpoint, project, instance string) (*cloudSQLConnectSettings, error) {
+	p, ok := providers.Load(CredentialsGCPCloudSQL)
+	if !ok {
+		return nil, fmt.Errorf("missing %s credentials provider", CredentialsGCPCloudSQL)
+	}
+	info, err := p.(CredentialsProvider).Credentials(ctx, &url.URL{})
+	if err != nil {
+		return nil, err
+	}
+	token, _ := info.Password()
+	var s cloudSQLConnectSettings
+	if err := getJSON(ctx, fmt.Sprintf("%s/projects/%s/instances/%s/connectSettings", endpoint, url.PathEscape(project), url.PathEscape(instance)), map[string]string{
+		"Authorization": "Bearer " + token,
+	}, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func init() {
+	Register(SchemeAWSRDS, OpenerFunc(openAWSRDS))
+	Register(SchemeGCP, OpenerFunc(openGCP))
+}
@@ -0,0 +1,367 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// CredentialsProvider provides the credentials for connecting to the database, and
+	// is called every time a new connection is opened. Hence, short-lived credentials,
+	// like IAM tokens, are refreshed automatically during long-running operations.
+	CredentialsProvider interface {
+		Credentials(ctx context.Context, u *url.URL) (*url.Userinfo, error)
+	}
+
+	// CredentialsProviderFunc allows using a function as a CredentialsProvider.
+	CredentialsProviderFunc func(context.Context, *url.URL) (*url.Userinfo, error)
+)
+
+// Credentials calls f(ctx, u).
+func (f CredentialsProviderFunc) Credentials(ctx context.Context, u *url.URL) (*url.Userinfo, error) {
+	return f(ctx, u)
+}
+
+// Names of the builtin credentials providers.
+const (
+	CredentialsAWSRDSIAM     = "aws_rds_iam"      // AWS RDS IAM authentication.
+	CredentialsGCPCloudSQL   = "gcp_cloudsql_iam" // GCP Cloud SQL IAM authentication.
+	CredentialsAzureADTokens = "azure_ad"         // Azure AD (Entra ID) authentication.
+)
+
+// credsParam is the URL query parameter for selecting a registered provider.
+const credsParam = "auth_provider"
+
+var providers sync.Map
+
+// RegisterCredentialsProvider registers a CredentialsProvider with the given name. Registered
+// providers can be selected using the "auth_provider" query parameter of the URL. For example:
+//
+//	mysql://user@host:3306/db?auth_provider=aws_rds_iam&tls=true&allowCleartextPasswords=1
+func RegisterCredentialsProvider(name string, p CredentialsProvider) {
+	if p == nil {
+		panic("sql/sqlclient: RegisterCredentialsProvider provider is nil")
+	}
+	providers.Store(name, p)
+}
+
+// OpenCredentials sets the CredentialsProvider used for connecting to the database.
+// Note that this option is supported only by drivers that open their connections
+// using the DSN (see OpenSQL).
+func OpenCredentials(p CredentialsProvider) OpenOption {
+	return func(c *openOptions) error {
+		c.creds = p
+		return nil
+	}
+}
+
+// CredentialsFromContext returns the CredentialsProvider set by OpenCredentials
+// or by the "auth_provider" URL parameter, if any.
+func CredentialsFromContext(ctx context.Context) (CredentialsProvider, bool) {
+	p, ok := ctx.Value(credsKey).(CredentialsProvider)
+	return p, ok
+}
+
+// credsFromURL extracts the credentials provider from the URL query,
+// and returns a copy of the URL without it.
+func credsFromURL(u *url.URL) (*url.URL, CredentialsProvider, error) {
+	q := u.Query()
+	if !q.Has(credsParam) {
+		return u, nil, nil
+	}
+	name := q.Get(credsParam)
+	p, ok := providers.Load(name)
+	if !ok {
+		return nil, nil, fmt.Errorf("sql/sqlclient: unknown auth_provider %q", name)
+	}
+	q.Del(credsParam)
+	u1 := *u
+	u1.RawQuery = q.Encode()
+	return &u1, p.(CredentialsProvider), nil
+}
+
+// credsConnector is a sqldriver.Connector that fetches the credentials before opening each connection.
+type credsConnector struct {
+	u      *url.URL
+	drv    *driver
+	creds  CredentialsProvider
+	driver sqldriver.Driver
+}
+
+// Connect implements the sqldriver.Connector interface.
+func (c *credsConnector) Connect(ctx context.Context) (sqldriver.Conn, error) {
+	user, err := c.creds.Credentials(ctx, c.u)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlclient: fetch credentials: %w", err)
+	}
+	u := *c.u
+	u.User = user
+	dsn := c.drv.parser.ParseURL(&u).DSN
+	if dc, ok := c.driver.(sqldriver.DriverContext); ok {
+		conn, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return conn.Connect(ctx)
+	}
+	return c.driver.Open(dsn)
+}
+
+// Driver implements the sqldriver.Connector interface.
+func (c *credsConnector) Driver() sqldriver.Driver {
+	return c.driver
+}
+
+// openCreds opens a database that uses the credentials provider for its connections.
+func openCreds(drv *driver, u *url.URL, p CredentialsProvider) (*sql.DB, error) {
+	// Extract the sql/driver.Driver registered for the given name.
+	db, err := sql.Open(drv.name, "")
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(&credsConnector{u: u, drv: drv, creds: p, driver: d}), nil
+}
+
+// tokenCache caches a token until it is about to expire.
+type tokenCache struct {
+	sync.Mutex
+	token  string
+	expiry time.Time
+	fetch  func(context.Context) (string, time.Time, error)
+}
+
+// get returns the cached token, or fetches a new one if it is about to expire.
+func (c *tokenCache) get(ctx context.Context) (string, error) {
+	c.Lock()
+	defer c.Unlock()
+	if c.token != "" && time.Until(c.expiry) > time.Minute {
+		return c.token, nil
+	}
+	t, exp, err := c.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.expiry = t, exp
+	return t, nil
+}
+
+// tokenProvider returns a CredentialsProvider that uses the token
+// as the password of the user defined in the URL.
+func tokenProvider(c *tokenCache) CredentialsProvider {
+	return CredentialsProviderFunc(func(ctx context.Context, u *url.URL) (*url.Userinfo, error) {
+		t, err := c.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return url.UserPassword(u.User.Username(), t), nil
+	})
+}
+
+// getJSON sends a GET request with the given headers and decodes its JSON response into v.
+func getJSON(ctx context.Context, endpoint string, header map[string]string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// gcpTokenEndpoint returns the endpoint of the GCP metadata server for fetching access tokens.
+func gcpTokenEndpoint() string {
+	host := "metadata.google.internal"
+	if h := os.Getenv("GCE_METADATA_HOST"); h != "" {
+		host = h
+	}
+	return "http://" + host + "/computeMetadata/v1/instance/service-accounts/default/token"
+}
+
+// gcpToken fetches an OAuth2 access token of the attached service account from the GCP metadata
+// server. An empty endpoint means the default metadata server (see GCE_METADATA_HOST).
+func gcpToken(endpoint string) func(context.Context) (string, time.Time, error) {
+	return func(ctx context.Context) (string, time.Time, error) {
+		var r struct {
+			Token     string `json:"access_token"`
+			ExpiresIn int64  `json:"expires_in"`
+		}
+		e := endpoint
+		if e == "" {
+			e = gcpTokenEndpoint()
+		}
+		if err := getJSON(ctx, e, map[string]string{"Metadata-Flavor": "Google"}, &r); err != nil {
+			return "", time.Time{}, fmt.Errorf("gcp: fetch access token: %w", err)
+		}
+		return r.Token, time.Now().Add(time.Duration(r.ExpiresIn) * time.Second), nil
+	}
+}
+
+// azureTokenEndpoint is the endpoint of the Azure Instance Metadata Service for fetching
+// tokens of the managed identity, for the Azure Database (MySQL and PostgreSQL) resource.
+const azureTokenEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&resource=https%3A%2F%2Fossrdbms-aad.database.windows.net"
+
+// azureToken fetches an access token of the managed identity from the Azure metadata service.
+func azureToken(endpoint string) func(context.Context) (string, time.Time, error) {
+	return func(ctx context.Context) (string, time.Time, error) {
+		var r struct {
+			Token     string `json:"access_token"`
+			ExpiresOn string `json:"expires_on"`
+		}
+		if err := getJSON(ctx, endpoint, map[string]string{"Metadata": "true"}, &r); err != nil {
+			return "", time.Time{}, fmt.Errorf("azure: fetch access token: %w", err)
+		}
+		sec, err := strconv.ParseInt(r.ExpiresOn, 10, 64)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("azure: parse token expiration %q: %w", r.ExpiresOn, err)
+		}
+		return r.Token, time.Unix(sec, 0), nil
+	}
+}
+
+// awsCreds holds the AWS credentials used for signing the RDS authentication tokens.
+type awsCreds struct {
+	AccessKeyID, SecretAccessKey, SessionToken, Region string
+}
+
+// awsEnvCreds loads the AWS credentials from the standard environment variables.
+func awsEnvCreds() (*awsCreds, error) {
+	c := &awsCreds{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Region:          os.Getenv("AWS_REGION"),
+	}
+	if c.Region == "" {
+		c.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	switch {
+	case c.AccessKeyID == "" || c.SecretAccessKey == "":
+		return nil, errors.New("aws: missing AWS_ACCESS_KEY_ID or AWS_SECRET_ACCESS_KEY")
+	case c.Region == "":
+		return nil, errors.New("aws: missing AWS_REGION")
+	}
+	return c, nil
+}
+
+// rdsToken builds an RDS IAM authentication token for the given endpoint (host:port)
+// and database user. The token is a SigV4 presigned URL that is valid for 15 minutes.
+func rdsToken(c *awsCreds, endpoint, user string, now time.Time) string {
+	const (
+		service   = "rds-db"
+		algorithm = "AWS4-HMAC-SHA256"
+	)
+	var (
+		date  = now.UTC().Format("20060102")
+		stamp = now.UTC().Format("20060102T150405Z")
+		scope = strings.Join([]string{date, c.Region, service, "aws4_request"}, "/")
+		q     = url.Values{
+			"Action":              {"connect"},
+			"DBUser":              {user},
+			"X-Amz-Algorithm":     {algorithm},
+			"X-Amz-Credential":    {c.AccessKeyID + "/" + scope},
+			"X-Amz-Date":          {stamp},
+			"X-Amz-Expires":       {"900"},
+			"X-Amz-SignedHeaders": {"host"},
+		}
+	)
+	if c.SessionToken != "" {
+		q.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+	query := awsEncode(q)
+	creq := strings.Join([]string{http.MethodGet, "/", query, "host:" + endpoint + "\n", "host", hexSHA256("")}, "\n")
+	sts := strings.Join([]string{algorithm, stamp, scope, hexSHA256(creq)}, "\n")
+	key := []byte("AWS4" + c.SecretAccessKey)
+	for _, s := range []string{date, c.Region, service, "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	return endpoint + "/?" + query + "&X-Amz-Signature=" + hex.EncodeToString(hmacSHA256(key, sts))
+}
+
+// awsEncode encodes the values in the canonical form defined by SigV4.
+func awsEncode(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, awsEscape(k)+"="+awsEscape(q.Get(k)))
+	}
+	return strings.Join(parts, "&")
+}
+
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hexSHA256(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+// awsRDSIAM returns a provider that generates RDS IAM authentication tokens. Tokens
+// are signed locally, and therefore, a new token is generated for each connection.
+func awsRDSIAM(load func() (*awsCreds, error)) CredentialsProvider {
+	return CredentialsProviderFunc(func(_ context.Context, u *url.URL) (*url.Userinfo, error) {
+		c, err := load()
+		if err != nil {
+			return nil, err
+		}
+		endpoint := u.Host
+		if u.Port() == "" {
+			port := "3306"
+			if strings.HasPrefix(u.Scheme, "postgres") {
+				port = "5432"
+			}
+			endpoint = net.JoinHostPort(u.Hostname(), port)
+		}
+		user := u.User.Username()
+		return url.UserPassword(user, rdsToken(c, endpoint, user, time.Now())), nil
+	})
+}
+
+func init() {
+	RegisterCredentialsProvider(CredentialsAWSRDSIAM, awsRDSIAM(awsEnvCreds))
+	RegisterCredentialsProvider(CredentialsGCPCloudSQL, tokenProvider(&tokenCache{fetch: gcpToken("")}))
+	RegisterCredentialsProvider(CredentialsAzureADTokens, tokenProvider(&tokenCache{fetch: azureToken(azureTokenEndpoint)}))
+}
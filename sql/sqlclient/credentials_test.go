@@ -0,0 +1,134 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_Credentials(t *testing.T) {
+	var calls int
+	RegisterCredentialsProvider("test", CredentialsProviderFunc(func(_ context.Context, u *url.URL) (*url.Userinfo, error) {
+		calls++
+		return url.UserPassword(u.User.Username(), fmt.Sprintf("token%d", calls)), nil
+	}))
+	parser := RegisterURLParser(URLParserFunc(func(u *url.URL) *URL {
+		p, _ := u.User.Password()
+		return &URL{URL: u, DSN: u.User.Username() + ":" + p}
+	}))
+	for _, opener := range []Opener{
+		DriverOpener(func(schema.ExecQuerier) (migrate.Driver, error) { return nil, nil }),
+		// Openers of the builtin drivers (e.g. MySQL) use OpenSQL.
+		OpenerFunc(func(ctx context.Context, u *url.URL) (*Client, error) {
+			db, err := OpenSQL(ctx, u)
+			if err != nil {
+				return nil, err
+			}
+			return &Client{DB: db, URL: &URL{URL: u}}, nil
+		}),
+	} {
+		drivers.Delete("sqlmock")
+		Register("sqlmock", opener, parser)
+		for i := 1; i <= 2; i++ {
+			db, mock, err := sqlmock.NewWithDSN(fmt.Sprintf("user:token%d", calls+i))
+			require.NoError(t, err)
+			mock.ExpectClose()
+			defer db.Close()
+		}
+		c, err := Open(context.Background(), "sqlmock://user@localhost?auth_provider=test")
+		require.NoError(t, err)
+		require.Zero(t, c.URL.Query().Encode(), "auth_provider is not passed to the driver")
+		// Credentials are fetched for every new connection.
+		prev := calls
+		conn1, err := c.DB.Conn(context.Background())
+		require.NoError(t, err)
+		conn2, err := c.DB.Conn(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, prev+2, calls)
+		require.NoError(t, conn1.Close())
+		require.NoError(t, conn2.Close())
+		require.NoError(t, c.Close())
+	}
+
+	_, err := Open(context.Background(), "sqlmock://user@localhost?auth_provider=unknown")
+	require.EqualError(t, err, `sql/sqlclient: unknown auth_provider "unknown"`)
+}
+
+func TestTokenCache(t *testing.T) {
+	var (
+		calls int
+		exp   = time.Now().Add(time.Hour)
+		c     = &tokenCache{fetch: func(context.Context) (string, time.Time, error) {
+			calls++
+			return fmt.Sprintf("token%d", calls), exp, nil
+		}}
+	)
+	p := tokenProvider(c)
+	u := &url.URL{User: url.User("user")}
+	for i := 0; i < 2; i++ {
+		info, err := p.Credentials(context.Background(), u)
+		require.NoError(t, err)
+		require.Equal(t, "user", info.Username())
+		pass, _ := info.Password()
+		require.Equal(t, "token1", pass)
+	}
+	// Tokens that are about to expire are refreshed.
+	c.expiry = time.Now().Add(time.Second)
+	info, err := p.Credentials(context.Background(), u)
+	require.NoError(t, err)
+	pass, _ := info.Password()
+	require.Equal(t, "token2", pass)
+}
+
+func TestCloudTokens(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Metadata-Flavor") == "Google":
+			fmt.Fprint(w, `{"access_token":"gcp","expires_in":3600}`)
+		case r.Header.Get("Metadata") == "true":
+			fmt.Fprintf(w, `{"access_token":"azure","expires_on":"%d"}`, time.Now().Add(time.Hour).Unix())
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+	tok, exp, err := gcpToken(srv.URL)(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "gcp", tok)
+	require.True(t, exp.After(time.Now().Add(59*time.Minute)))
+	tok, _, err = azureToken(srv.URL)(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "azure", tok)
+	err = getJSON(context.Background(), srv.URL, nil, nil)
+	require.Error(t, err)
+}
+
+func TestRDSToken(t *testing.T) {
+	c := &awsCreds{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session", Region: "us-east-1"}
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tok := rdsToken(c, "db.example.com:5432", "iam user", now)
+	require.True(t, strings.HasPrefix(tok, "db.example.com:5432/?Action=connect&DBUser=iam%20user&X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=AKID%2F20230101%2Fus-east-1%2Frds-db%2Faws4_request&X-Amz-Date=20230101T000000Z&X-Amz-Expires=900&X-Amz-Security-Token=session&X-Amz-SignedHeaders=host&X-Amz-Signature="), tok)
+	require.Len(t, tok[strings.LastIndex(tok, "=")+1:], 64)
+	require.Equal(t, tok, rdsToken(c, "db.example.com:5432", "iam user", now), "tokens are deterministic")
+
+	p := awsRDSIAM(func() (*awsCreds, error) { return c, nil })
+	info, err := p.Credentials(context.Background(), &url.URL{Scheme: "postgres", Host: "db.example.com", User: url.User("iam user")})
+	require.NoError(t, err)
+	pass, _ := info.Password()
+	require.True(t, strings.HasPrefix(pass, "db.example.com:5432/?Action=connect"))
+}
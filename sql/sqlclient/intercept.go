@@ -0,0 +1,141 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// ExecFunc executes a statement without returning any rows.
+	ExecFunc func(ctx context.Context, query string, args ...any) (sql.Result, error)
+
+	// QueryFunc executes a query that returns rows.
+	QueryFunc func(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+
+	// An Interceptor wraps the ExecContext and QueryContext calls of a driver. Interceptors
+	// allow injecting statement rewriting, rate limiting or custom telemetry uniformly for
+	// all drivers. An interceptor receives the next function in the chain, and it is
+	// responsible for calling it, unless it decides to fail (or skip) the call.
+	//
+	//	sqlclient.Open(ctx, url, sqlclient.OpenInterceptors(
+	//		sqlclient.InterceptorFuncs{
+	//			Exec: func(next sqlclient.ExecFunc) sqlclient.ExecFunc {
+	//				return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	//					defer func(start time.Time) { log.Println(query, time.Since(start)) }(time.Now())
+	//					return next(ctx, query, args...)
+	//				}
+	//			},
+	//		},
+	//	))
+	Interceptor interface {
+		InterceptExec(next ExecFunc) ExecFunc
+		InterceptQuery(next QueryFunc) QueryFunc
+	}
+
+	// InterceptorFuncs allows using functions as an Interceptor.
+	// Nil functions leave the corresponding calls unchanged.
+	InterceptorFuncs struct {
+		Exec  func(ExecFunc) ExecFunc
+		Query func(QueryFunc) QueryFunc
+	}
+)
+
+// InterceptExec calls f.Exec(next), if set.
+func (f InterceptorFuncs) InterceptExec(next ExecFunc) ExecFunc {
+	if f.Exec == nil {
+		return next
+	}
+	return f.Exec(next)
+}
+
+// InterceptQuery calls f.Query(next), if set.
+func (f InterceptorFuncs) InterceptQuery(next QueryFunc) QueryFunc {
+	if f.Query == nil {
+		return next
+	}
+	return f.Query(next)
+}
+
+// Intercept wraps the given ExecQuerier with the interceptors. Interceptors are called in
+// the order they were passed, i.e., the first one is the outermost in the chain. Single
+// connections obtained from the returned ExecQuerier (e.g. for locking) are intercepted
+// as well.
+func Intercept(eq schema.ExecQuerier, is ...Interceptor) schema.ExecQuerier {
+	if len(is) == 0 {
+		return eq
+	}
+	i := &intercepted{eq: eq, is: is, exec: eq.ExecContext, query: eq.QueryContext}
+	for j := len(is) - 1; j >= 0; j-- {
+		i.exec = is[j].InterceptExec(i.exec)
+		i.query = is[j].InterceptQuery(i.query)
+	}
+	return i
+}
+
+// OpenInterceptors wraps the ExecContext and QueryContext calls of the client and its
+// transactions with the given interceptors. The migrate.Driver of the client is opened
+// again on top of the intercepted connection, and therefore, this option is supported
+// only by drivers that registered a driver opener (e.g. DriverOpener). Note that
+// statements executed directly on the Client.DB are not intercepted.
+func OpenInterceptors(is ...Interceptor) OpenOption {
+	return func(c *openOptions) error {
+		c.interceptors = append(c.interceptors, is...)
+		return nil
+	}
+}
+
+// intercept reopens the client driver with the given interceptors.
+func (c *Client) intercept(is []Interceptor) error {
+	if c.openDriver == nil {
+		return fmt.Errorf("sql/sqlclient: driver %q does not support interceptors", c.Name)
+	}
+	drv, err := c.openDriver(Intercept(c.DB, is...))
+	if err != nil {
+		return fmt.Errorf("sql/sqlclient: opening atlas driver: %w", err)
+	}
+	c.Driver, c.interceptors = drv, is
+	return nil
+}
+
+// intercepted wraps an ExecQuerier with a chain of interceptors.
+type intercepted struct {
+	eq    schema.ExecQuerier
+	is    []Interceptor
+	exec  ExecFunc
+	query QueryFunc
+}
+
+// ExecContext implements the schema.ExecQuerier interface.
+func (i *intercepted) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return i.exec(ctx, query, args...)
+}
+
+// QueryContext implements the schema.ExecQuerier interface.
+func (i *intercepted) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return i.query(ctx, query, args...)
+}
+
+// SingleConn returns a single connection of the underlying ExecQuerier,
+// wrapped with the same interceptors. It is used by sqlx.SingleConn.
+func (i *intercepted) SingleConn(ctx context.Context) (sqlx.ExecQueryCloser, error) {
+	conn, err := sqlx.SingleConn(ctx, i.eq)
+	if err != nil {
+		return nil, err
+	}
+	return &interceptedCloser{ExecQuerier: Intercept(conn, i.is...), Closer: conn}, nil
+}
+
+// interceptedCloser is an intercepted single connection.
+type interceptedCloser struct {
+	schema.ExecQuerier
+	io.Closer
+}
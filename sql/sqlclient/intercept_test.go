@@ -0,0 +1,165 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+// execDriver is a migrate.Driver that executes statements on the ExecQuerier it was opened with.
+type execDriver struct {
+	migrate.Driver
+	eq schema.ExecQuerier
+}
+
+func (d *execDriver) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return d.eq.ExecContext(ctx, query, args...)
+}
+
+func (d *execDriver) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.eq.QueryContext(ctx, query, args...)
+}
+
+func TestOpenInterceptors(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	sqlclient.Register(
+		"intercept",
+		sqlclient.OpenerFunc(func(context.Context, *url.URL) (*sqlclient.Client, error) {
+			return &sqlclient.Client{Name: "intercept", DB: db, Driver: &execDriver{eq: db}}, nil
+		}),
+		sqlclient.RegisterDriverOpener(func(eq schema.ExecQuerier) (migrate.Driver, error) {
+			return &execDriver{eq: eq}, nil
+		}),
+	)
+	var calls []string
+	tag := func(name string) sqlclient.Interceptor {
+		return sqlclient.InterceptorFuncs{
+			Exec: func(next sqlclient.ExecFunc) sqlclient.ExecFunc {
+				return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+					calls = append(calls, name+":exec")
+					return next(ctx, query+" /* "+name+" */", args...)
+				}
+			},
+			Query: func(next sqlclient.QueryFunc) sqlclient.QueryFunc {
+				return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+					calls = append(calls, name+":query")
+					return next(ctx, query, args...)
+				}
+			},
+		}
+	}
+	deny := sqlclient.InterceptorFuncs{
+		Exec: func(next sqlclient.ExecFunc) sqlclient.ExecFunc {
+			return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+				if strings.HasPrefix(query, "DROP") {
+					return nil, errors.New("denied")
+				}
+				return next(ctx, query, args...)
+			}
+		},
+	}
+	c, err := sqlclient.Open(context.Background(), "intercept://", sqlclient.OpenInterceptors(tag("a"), tag("b"), deny))
+	require.NoError(t, err)
+
+	// Interceptors are called in order.
+	m.ExpectExec(sqltest.Escape("CREATE TABLE t(c int) /* a */ /* b */")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = c.ExecContext(context.Background(), "CREATE TABLE t(c int)")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a:exec", "b:exec"}, calls)
+
+	// Interceptors may fail the call.
+	calls = nil
+	_, err = c.ExecContext(context.Background(), "DROP TABLE t")
+	require.EqualError(t, err, "denied")
+	require.Equal(t, []string{"a:exec", "b:exec"}, calls)
+
+	calls = nil
+	m.ExpectQuery(sqltest.Escape("SELECT 1")).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	rows, err := c.QueryContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.Equal(t, []string{"a:query", "b:query"}, calls)
+
+	// Transactions are intercepted as well.
+	calls = nil
+	m.ExpectBegin()
+	m.ExpectExec(sqltest.Escape("INSERT INTO t VALUES (1) /* a */ /* b */")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	m.ExpectCommit()
+	tx, err := c.Tx(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(context.Background(), "INSERT INTO t VALUES (1)")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	require.Equal(t, []string{"a:exec", "b:exec"}, calls)
+
+	// Statements executed on the underlying database are not intercepted.
+	calls = nil
+	m.ExpectExec(sqltest.Escape("DROP TABLE t")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = c.DB.ExecContext(context.Background(), "DROP TABLE t")
+	require.NoError(t, err)
+	require.Empty(t, calls)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	sqlclient.Register("nointercept", sqlclient.OpenerFunc(func(context.Context, *url.URL) (*sqlclient.Client, error) {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		m.ExpectClose()
+		return &sqlclient.Client{Name: "nointercept", DB: db}, nil
+	}))
+	_, err = sqlclient.Open(context.Background(), "nointercept://", sqlclient.OpenInterceptors(deny))
+	require.EqualError(t, err, `sql/sqlclient: driver "nointercept" does not support interceptors`)
+}
+
+func TestIntercept(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	require.True(t, sqlclient.Intercept(db) == schema.ExecQuerier(db), "no interceptors")
+	var n int
+	count := sqlclient.InterceptorFuncs{
+		Query: func(next sqlclient.QueryFunc) sqlclient.QueryFunc {
+			return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+				n++
+				return next(ctx, query, args...)
+			}
+		},
+	}
+	eq := sqlclient.Intercept(db, count)
+	m.ExpectExec(sqltest.Escape("SET x = 1")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = eq.ExecContext(context.Background(), "SET x = 1")
+	require.NoError(t, err)
+	require.Zero(t, n, "nil functions leave calls unchanged")
+
+	// Single connections (e.g. used for locking) are intercepted.
+	m.ExpectQuery(sqltest.Escape("SELECT pg_try_advisory_lock(1)")).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(true))
+	conn, err := sqlx.SingleConn(context.Background(), eq)
+	require.NoError(t, err)
+	rows, err := conn.QueryContext(context.Background(), "SELECT pg_try_advisory_lock(1)")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.NoError(t, conn.Close())
+	require.Equal(t, 1, n)
+	require.NoError(t, m.ExpectationsWereMet())
+}
@@ -0,0 +1,111 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// PluginAPIVersion is the version of the plugin API. It is incremented only
+// on changes that break plugins that were built against previous versions.
+const PluginAPIVersion = 1
+
+// A Plugin describes a driver that is shipped by a third party and registered at
+// runtime, without changing the Atlas codebase. Plugins are registered the same
+// way as the builtin drivers, and can be opened using their URL scheme.
+//
+//	err := sqlclient.RegisterPlugin(sqlclient.Plugin{
+//		Name:       "duckdb",
+//		APIVersion: sqlclient.PluginAPIVersion,
+//		Opener:     sqlclient.OpenerFunc(openDuckDB),
+//		Options: []sqlclient.RegisterOption{
+//			sqlclient.RegisterFlavours("duck"),
+//		},
+//	})
+//
+// Plugins that connect to databases that speak the dialect of a builtin driver
+// (e.g. postgres) can use OpenDB to reuse its inspection, diffing and planning.
+type Plugin struct {
+	// Name is the URL scheme the plugin is registered with.
+	Name string
+	// APIVersion is the version of the plugin API the plugin was built against.
+	// Plugins built against an unsupported version fail to register.
+	APIVersion int
+	// Opener opens clients for URLs of the plugin scheme.
+	Opener Opener
+	// Options holds the options of the registration (e.g. flavours or URL parser).
+	Options []RegisterOption
+}
+
+// RegisterPlugin registers the given plugin. Unlike Register, that is called by the
+// builtin drivers on initialization, an error is returned if the plugin is invalid
+// or one of its schemes is already registered.
+func RegisterPlugin(p Plugin) error {
+	switch {
+	case p.Name == "":
+		return errors.New("sql/sqlclient: missing plugin name")
+	case p.Opener == nil:
+		return fmt.Errorf("sql/sqlclient: missing opener for plugin %q", p.Name)
+	case p.APIVersion != PluginAPIVersion:
+		return fmt.Errorf("sql/sqlclient: plugin %q was built against API version %d, expected %d", p.Name, p.APIVersion, PluginAPIVersion)
+	}
+	if dup := register(p.Name, p.Opener, p.Options...); dup != "" {
+		return fmt.Errorf("sql/sqlclient: plugin %q: scheme %q is already registered", p.Name, dup)
+	}
+	return nil
+}
+
+// Schemes returns the sorted list of the URL schemes that were registered
+// by the builtin drivers and the plugins, including their flavours.
+func Schemes() []string {
+	var schemes []string
+	drivers.Range(func(k, _ any) bool {
+		schemes = append(schemes, k.(string))
+		return true
+	})
+	sort.Strings(schemes)
+	return schemes
+}
+
+// OpenDB opens a client of the registered dialect (e.g. postgres) on top of the given
+// database connection. It is used by plugins that connect to databases that speak the
+// dialect of another driver, but require a different transport. The dialect must have
+// been registered with a driver opener (see, RegisterDriverOpener or DriverOpener).
+//
+// The URL is the one the plugin was opened with. It is parsed by the URL parser of
+// the dialect to extract the connected schema, but its scheme is kept.
+func OpenDB(dialect string, db *sql.DB, u *url.URL) (*Client, error) {
+	v, ok := drivers.Load(dialect)
+	if !ok {
+		return nil, fmt.Errorf("sql/sqlclient: unknown dialect %q", dialect)
+	}
+	drv := v.(*driver)
+	if drv.openDriver == nil {
+		return nil, fmt.Errorf("sql/sqlclient: dialect %q does not support opening existing connections", dialect)
+	}
+	u1 := *u
+	u1.Scheme = dialect
+	ur := drv.parser.ParseURL(&u1)
+	mdr, err := drv.openDriver(db)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		Name:       drv.name,
+		DB:         db,
+		URL:        &URL{URL: u, DSN: ur.DSN, Schema: ur.Schema, Schemas: ur.Schemas},
+		Driver:     mdr,
+		openDriver: drv.openDriver,
+		openTx:     drv.txOpener,
+	}
+	if drv.codec != nil {
+		c.Marshaler, c.Evaluator = drv.codec, drv.codec
+	}
+	return c, nil
+}
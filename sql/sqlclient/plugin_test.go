@@ -0,0 +1,86 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterPlugin(t *testing.T) {
+	opener := sqlclient.OpenerFunc(func(context.Context, *url.URL) (*sqlclient.Client, error) {
+		return &sqlclient.Client{Name: "plugin"}, nil
+	})
+	err := sqlclient.RegisterPlugin(sqlclient.Plugin{Opener: opener, APIVersion: sqlclient.PluginAPIVersion})
+	require.EqualError(t, err, "sql/sqlclient: missing plugin name")
+	err = sqlclient.RegisterPlugin(sqlclient.Plugin{Name: "plugin", APIVersion: sqlclient.PluginAPIVersion})
+	require.EqualError(t, err, `sql/sqlclient: missing opener for plugin "plugin"`)
+	err = sqlclient.RegisterPlugin(sqlclient.Plugin{Name: "plugin", Opener: opener, APIVersion: 100})
+	require.EqualError(t, err, `sql/sqlclient: plugin "plugin" was built against API version 100, expected 1`)
+
+	err = sqlclient.RegisterPlugin(sqlclient.Plugin{
+		Name:       "plugin",
+		APIVersion: sqlclient.PluginAPIVersion,
+		Opener:     opener,
+		Options:    []sqlclient.RegisterOption{sqlclient.RegisterFlavours("plugin+v2")},
+	})
+	require.NoError(t, err)
+	require.Contains(t, sqlclient.Schemes(), "plugin")
+	require.Contains(t, sqlclient.Schemes(), "plugin+v2")
+	c, err := sqlclient.Open(context.Background(), "plugin+v2://localhost")
+	require.NoError(t, err)
+	require.Equal(t, "plugin", c.Name)
+
+	// Schemes are not registered partially.
+	err = sqlclient.RegisterPlugin(sqlclient.Plugin{
+		Name:       "plugin2",
+		APIVersion: sqlclient.PluginAPIVersion,
+		Opener:     opener,
+		Options:    []sqlclient.RegisterOption{sqlclient.RegisterFlavours("plugin+v2")},
+	})
+	require.EqualError(t, err, `sql/sqlclient: plugin "plugin2": scheme "plugin+v2" is already registered`)
+	require.NotContains(t, sqlclient.Schemes(), "plugin2")
+}
+
+func TestOpenDB(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	sqlclient.Register(
+		"dialect",
+		sqlclient.DriverOpener(func(schema.ExecQuerier) (migrate.Driver, error) {
+			return nil, nil
+		}),
+		sqlclient.RegisterURLParser(sqlclient.URLParserFunc(func(u *url.URL) *sqlclient.URL {
+			return &sqlclient.URL{URL: u, DSN: "dsn", Schema: u.Scheme}
+		})),
+	)
+	sqlclient.Register("opener", sqlclient.OpenerFunc(func(context.Context, *url.URL) (*sqlclient.Client, error) {
+		return nil, nil
+	}))
+	u, err := url.Parse("remote://localhost/db")
+	require.NoError(t, err)
+
+	_, err = sqlclient.OpenDB("unknown", db, u)
+	require.EqualError(t, err, `sql/sqlclient: unknown dialect "unknown"`)
+	_, err = sqlclient.OpenDB("opener", db, u)
+	require.EqualError(t, err, `sql/sqlclient: dialect "opener" does not support opening existing connections`)
+
+	c, err := sqlclient.OpenDB("dialect", db, u)
+	require.NoError(t, err)
+	require.Equal(t, "dialect", c.Name)
+	require.True(t, c.DB == db)
+	require.Equal(t, "dialect", c.URL.Schema, "URL is parsed with the dialect scheme")
+	require.Equal(t, "remote", c.URL.Scheme, "original scheme is kept")
+	require.Equal(t, "dsn", c.URL.DSN)
+}
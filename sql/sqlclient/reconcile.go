@@ -0,0 +1,256 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type (
+	// ReconcileStatus describes the result of a Reconcile call. Its structure follows
+	// the conventions of Kubernetes resources status, and can be copied as-is to the
+	// status of a custom resource.
+	ReconcileStatus struct {
+		// Conditions describe the current state of the reconciliation.
+		Conditions []*Condition `json:"conditions,omitempty"`
+		// Version is the hash of the desired state. It is set to the
+		// desired state version only after it was applied successfully.
+		Version string `json:"version,omitempty"`
+		// Applied holds the statements that were applied to the target.
+		Applied []*Stmt `json:"applied,omitempty"`
+		// Pending holds the statements that were not applied to the target,
+		// in case of dry-run mode or if an error occurred.
+		Pending []*Stmt `json:"pending,omitempty"`
+	}
+
+	// Condition describes one aspect of the reconciliation state.
+	Condition struct {
+		Type               string    `json:"type"`   // e.g. Ready.
+		Status             string    `json:"status"` // True, False or Unknown.
+		Reason             string    `json:"reason,omitempty"`
+		Message            string    `json:"message,omitempty"`
+		LastTransitionTime time.Time `json:"lastTransitionTime"`
+	}
+
+	// ReconcileOption allows configuring Reconcile using functional options.
+	ReconcileOption func(*reconcileOptions)
+
+	reconcileOptions struct {
+		dryRun   bool
+		vars     map[string]cty.Value
+		diffOpts []schema.DiffOption
+		exclude  []string
+	}
+)
+
+// List of condition types and reasons reported by Reconcile.
+const (
+	ConditionReady = "Ready"
+
+	ReasonSynced       = "Synced"       // Target is in sync with the desired state.
+	ReasonApplied      = "Applied"      // Changes were applied to the target.
+	ReasonPending      = "Pending"      // Changes are pending (dry-run mode).
+	ReasonInvalidState = "InvalidState" // Desired state could not be loaded.
+	ReasonInspectError = "InspectError" // Target could not be inspected.
+	ReasonDiffError    = "DiffError"    // Changes could not be computed or planned.
+	ReasonApplyError   = "ApplyError"   // Changes failed to be applied.
+)
+
+// ReconcileDryRun computes the changes without applying them. The changes are reported as pending.
+func ReconcileDryRun() ReconcileOption {
+	return func(o *reconcileOptions) {
+		o.dryRun = true
+	}
+}
+
+// ReconcileVars sets the input variables used for evaluating the desired state.
+func ReconcileVars(vars map[string]cty.Value) ReconcileOption {
+	return func(o *reconcileOptions) {
+		o.vars = vars
+	}
+}
+
+// ReconcileDiffOptions sets the options used for computing the changes.
+func ReconcileDiffOptions(opts ...schema.DiffOption) ReconcileOption {
+	return func(o *reconcileOptions) {
+		o.diffOpts = append(o.diffOpts, opts...)
+	}
+}
+
+// ReconcileExclude sets the glob patterns used to filter resources on inspection.
+func ReconcileExclude(patterns ...string) ReconcileOption {
+	return func(o *reconcileOptions) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// Reconcile opens a client to the target URL and reconciles it with the desired state.
+// See Client.Reconcile for more information.
+func Reconcile(ctx context.Context, desired []byte, targetURL string, opts ...ReconcileOption) (*ReconcileStatus, error) {
+	c, err := Open(ctx, targetURL)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.Reconcile(ctx, desired, opts...)
+}
+
+// Reconcile computes the changes between the connected database and the desired state (an
+// HCL document) and applies them. Reconcile is idempotent, and calling it on a database that
+// is in sync with the desired state is a no-op. The returned status is set also in case of an
+// error, and describes the stage in which the reconciliation failed.
+func (c *Client) Reconcile(ctx context.Context, desired []byte, opts ...ReconcileOption) (*ReconcileStatus, error) {
+	var (
+		o      reconcileOptions
+		status = &ReconcileStatus{}
+	)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	version, changes, reason, err := c.desiredChanges(ctx, desired, &o)
+	if err != nil {
+		return status.fail(reason, err)
+	}
+	if len(changes) == 0 {
+		status.Version = version
+		return status.set(ReasonSynced, "Target is in sync with the desired state"), nil
+	}
+	plan, err := c.PlanChanges(ctx, "", changes)
+	if err != nil {
+		return status.fail(ReasonDiffError, err)
+	}
+	planned, err := stmts(plan.Changes)
+	if err != nil {
+		return status.fail(ReasonDiffError, err)
+	}
+	if o.dryRun {
+		status.Pending = planned
+		return status.set(ReasonPending, fmt.Sprintf("%d changes are pending", len(planned))), nil
+	}
+	if err := c.ApplyChanges(ctx, changes); err != nil {
+		applied := 0
+		if i, ok := err.(interface{ Applied() int }); ok && i.Applied() < len(planned) {
+			applied = i.Applied()
+		}
+		status.Applied, status.Pending = planned[:applied], planned[applied:]
+		return status.fail(ReasonApplyError, err)
+	}
+	status.Version, status.Applied = version, planned
+	return status.set(ReasonApplied, fmt.Sprintf("%d changes were applied", len(planned))), nil
+}
+
+// Ready returns the Ready condition of the status, if exists.
+func (s *ReconcileStatus) Ready() (*Condition, bool) {
+	for _, c := range s.Conditions {
+		if c.Type == ConditionReady {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// set sets the Ready condition of the status.
+func (s *ReconcileStatus) set(reason, msg string) *ReconcileStatus {
+	st := "False"
+	switch reason {
+	case ReasonSynced, ReasonApplied:
+		st = "True"
+	case ReasonPending:
+		st = "Unknown"
+	}
+	s.Conditions = []*Condition{{
+		Type:               ConditionReady,
+		Status:             st,
+		Reason:             reason,
+		Message:            msg,
+		LastTransitionTime: time.Now(),
+	}}
+	return s
+}
+
+// fail sets the Ready condition of the status to False and returns the error.
+func (s *ReconcileStatus) fail(reason string, err error) (*ReconcileStatus, error) {
+	return s.set(reason, err.Error()), fmt.Errorf("sql/sqlclient: reconcile: %w", err)
+}
+
+// desiredChanges evaluates the desired state, and computes the changes needed for moving the
+// connected database to this state. In case of an error, the reason of the failure is returned.
+func (c *Client) desiredChanges(ctx context.Context, desired []byte, o *reconcileOptions) (string, []schema.Change, string, error) {
+	if c.Evaluator == nil {
+		return "", nil, ReasonInvalidState, errors.New("missing schema evaluator")
+	}
+	// Load the desired state.
+	p := hclparse.NewParser()
+	if _, diags := p.ParseHCL(desired, "desired.hcl"); diags.HasErrors() {
+		return "", nil, ReasonInvalidState, diags
+	}
+	to := &schema.Realm{}
+	if err := c.Eval(p, to, o.vars); err != nil {
+		return "", nil, ReasonInvalidState, err
+	}
+	// An empty state is rejected, as it results in dropping all schemas.
+	if len(to.Schemas) == 0 {
+		return "", nil, ReasonInvalidState, errors.New("desired state does not contain any schema")
+	}
+	version, err := c.stateVersion(to)
+	if err != nil {
+		return "", nil, ReasonInvalidState, err
+	}
+	// Inspect the current state and compute the changes.
+	var changes []schema.Change
+	switch {
+	case c.URL.Schema != "":
+		if len(to.Schemas) != 1 {
+			return "", nil, ReasonInvalidState, fmt.Errorf("expect exactly 1 schema in desired state when connected to schema %q, got %d", c.URL.Schema, len(to.Schemas))
+		}
+		from, err := c.InspectSchema(ctx, c.URL.Schema, &schema.InspectOptions{Exclude: o.exclude})
+		if err != nil {
+			return "", nil, ReasonInspectError, err
+		}
+		// Compare the contents of the schemas, regardless of their names.
+		cur, des := *from, *to.Schemas[0]
+		cur.Name, des.Name = "", ""
+		if changes, err = c.SchemaDiff(&cur, &des, o.diffOpts...); err != nil {
+			return "", nil, ReasonDiffError, err
+		}
+	default:
+		names := make([]string, len(to.Schemas))
+		for i, s := range to.Schemas {
+			names[i] = s.Name
+		}
+		from, err := c.InspectRealm(ctx, &schema.InspectRealmOption{Schemas: names, Exclude: o.exclude})
+		if err != nil {
+			return "", nil, ReasonInspectError, err
+		}
+		if changes, err = c.RealmDiff(from, to, o.diffOpts...); err != nil {
+			return "", nil, ReasonDiffError, err
+		}
+	}
+	return version, changes, "", nil
+}
+
+// stateVersion returns the version of the desired state. The version is computed from the
+// marshaled state, and therefore it is not affected by formatting changes of the document.
+func (c *Client) stateVersion(r *schema.Realm) (string, error) {
+	if c.Marshaler == nil {
+		return "", errors.New("missing schema marshaler")
+	}
+	b, err := c.MarshalSpec(r)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Reconcile(t *testing.T) {
+	var (
+		ctx = context.Background()
+		drv = &reconcileDriver{current: schema.NewRealm(schema.New("main"))}
+		c   = &sqlclient.Client{
+			Name:      "sqlite3",
+			URL:       &sqlclient.URL{URL: &url.URL{Scheme: "sqlite"}},
+			Driver:    drv,
+			Marshaler: sqlite.MarshalHCL,
+			Evaluator: sqlite.EvalHCL,
+		}
+		desired = []byte(`
+schema "main" {}
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+}
+`)
+	)
+	// Dry-run mode reports pending changes.
+	status, err := c.Reconcile(ctx, desired, sqlclient.ReconcileDryRun())
+	require.NoError(t, err)
+	require.Empty(t, status.Version)
+	require.Empty(t, status.Applied)
+	require.Len(t, status.Pending, 1)
+	require.Equal(t, "CREATE TABLE `users` (`id` int NOT NULL)", status.Pending[0].Cmd)
+	cond, ok := status.Ready()
+	require.True(t, ok)
+	require.Equal(t, "Unknown", cond.Status)
+	require.Equal(t, sqlclient.ReasonPending, cond.Reason)
+
+	status, err = c.Reconcile(ctx, desired)
+	require.NoError(t, err)
+	require.NotEmpty(t, status.Version)
+	require.Len(t, status.Applied, 1)
+	require.Empty(t, status.Pending)
+	cond, _ = status.Ready()
+	require.Equal(t, "True", cond.Status)
+	require.Equal(t, sqlclient.ReasonApplied, cond.Reason)
+	version := status.Version
+
+	// Reconcile is idempotent.
+	status, err = c.Reconcile(ctx, desired)
+	require.NoError(t, err)
+	require.Equal(t, version, status.Version)
+	require.Empty(t, status.Applied)
+	require.Empty(t, status.Pending)
+	cond, _ = status.Ready()
+	require.Equal(t, "True", cond.Status)
+	require.Equal(t, sqlclient.ReasonSynced, cond.Reason)
+	require.Equal(t, 1, drv.applied)
+
+	// Errors are reported in the status.
+	status, err = c.Reconcile(ctx, []byte(`schema "main" {`))
+	require.Error(t, err)
+	cond, _ = status.Ready()
+	require.Equal(t, "False", cond.Status)
+	require.Equal(t, sqlclient.ReasonInvalidState, cond.Reason)
+
+	status, err = c.Reconcile(ctx, []byte(`# empty`))
+	require.EqualError(t, err, "sql/sqlclient: reconcile: desired state does not contain any schema")
+	cond, _ = status.Ready()
+	require.Equal(t, sqlclient.ReasonInvalidState, cond.Reason)
+
+	drv.err = errors.New("boom")
+	status, err = c.Reconcile(ctx, append(desired, []byte(`
+table "pets" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+}
+`)...))
+	require.EqualError(t, err, "sql/sqlclient: reconcile: boom")
+	require.Empty(t, status.Version)
+	require.Empty(t, status.Applied)
+	require.Len(t, status.Pending, 1)
+	cond, _ = status.Ready()
+	require.Equal(t, "False", cond.Status)
+	require.Equal(t, sqlclient.ReasonApplyError, cond.Reason)
+}
+
+// reconcileDriver is a migrate.Driver that applies the desired state as-is.
+type reconcileDriver struct {
+	migrate.Driver
+	current, desired *schema.Realm
+	applied          int
+	err              error
+}
+
+func (d *reconcileDriver) InspectRealm(context.Context, *schema.InspectRealmOption) (*schema.Realm, error) {
+	return d.current, nil
+}
+
+func (d *reconcileDriver) RealmDiff(from, to *schema.Realm, opts ...schema.DiffOption) ([]schema.Change, error) {
+	d.desired = to
+	return sqlite.DefaultDiff.RealmDiff(from, to, opts...)
+}
+
+func (d *reconcileDriver) PlanChanges(ctx context.Context, name string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
+	return sqlite.DefaultPlan.PlanChanges(ctx, name, changes, opts...)
+}
+
+func (d *reconcileDriver) ApplyChanges(context.Context, []schema.Change, ...migrate.PlanOption) error {
+	if d.err != nil {
+		return d.err
+	}
+	d.current = d.desired
+	d.applied++
+	return nil
+}
@@ -0,0 +1,370 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// StatePlan describes the changes required for moving the connected database to a desired
+	// state. A StatePlan can be encoded to JSON, stored by external tools (e.g. Terraform), and
+	// applied later using Client.ApplyPlan.
+	StatePlan struct {
+		// Version of the desired state.
+		Version string `json:"version"`
+		// Desired holds the desired state document.
+		Desired string `json:"desired"`
+		// Objects holds the schema objects that are changed by the plan.
+		Objects []*ObjectChange `json:"objects,omitempty"`
+		// Stmts holds the statements that are executed by the plan.
+		Stmts []*Stmt `json:"stmts,omitempty"`
+	}
+
+	// ObjectChange describes a change of a schema object.
+	ObjectChange struct {
+		ID     string `json:"id"`     // See ObjectID.
+		Action string `json:"action"` // create, drop, modify or rename.
+	}
+
+	// A Stmt is a planned statement. Unlike migrate.Change,
+	// it does not hold the source change and can be encoded.
+	Stmt struct {
+		Cmd     string   `json:"cmd"`
+		Args    []any    `json:"args,omitempty"`
+		Comment string   `json:"comment,omitempty"`
+		Reverse []string `json:"reverse,omitempty"`
+	}
+
+	// State describes the current state of the connected database.
+	State struct {
+		// Version of the current state.
+		Version string `json:"version"`
+		// HCL holds the current state as an HCL document.
+		HCL string `json:"hcl"`
+		// Objects holds the identifiers of the schema objects.
+		Objects []string `json:"objects,omitempty"`
+		// Realm holds the inspected state.
+		Realm *schema.Realm `json:"-"`
+	}
+)
+
+// List of actions reported in ObjectChange.
+const (
+	ActionCreate = "create"
+	ActionDrop   = "drop"
+	ActionModify = "modify"
+	ActionRename = "rename"
+)
+
+// ErrStalePlan is returned by ApplyPlan in case the connected database
+// or the desired state were changed since the plan was computed.
+var ErrStalePlan = errors.New("sql/sqlclient: plan is stale and needs to be recomputed")
+
+// PlanState computes the plan for moving the connected database to the desired state. The returned
+// plan may be empty (no statements), in case the database is in sync with the desired state.
+func (c *Client) PlanState(ctx context.Context, desired []byte, opts ...ReconcileOption) (*StatePlan, error) {
+	var o reconcileOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	version, changes, _, err := c.desiredChanges(ctx, desired, &o)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlclient: plan state: %w", err)
+	}
+	p := &StatePlan{Version: version, Desired: string(desired), Objects: objectChanges(nil, changes)}
+	if len(changes) == 0 {
+		return p, nil
+	}
+	plan, err := c.PlanChanges(ctx, "", changes)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlclient: plan state: %w", err)
+	}
+	if p.Stmts, err = stmts(plan.Changes); err != nil {
+		return nil, fmt.Errorf("sql/sqlclient: plan state: %w", err)
+	}
+	return p, nil
+}
+
+// ApplyPlan applies the given plan on the connected database. The plan is recomputed before it
+// is applied, and ErrStalePlan is returned in case it does not match the given plan. Note, the
+// options must be the same as the ones that were used for computing the plan.
+func (c *Client) ApplyPlan(ctx context.Context, p *StatePlan, opts ...ReconcileOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var o reconcileOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	version, changes, _, err := c.desiredChanges(ctx, []byte(p.Desired), &o)
+	if err != nil {
+		return fmt.Errorf("sql/sqlclient: apply plan: %w", err)
+	}
+	if version != p.Version || len(changes) == 0 && len(p.Stmts) > 0 {
+		return ErrStalePlan
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	plan, err := c.PlanChanges(ctx, "", changes)
+	if err != nil {
+		return fmt.Errorf("sql/sqlclient: apply plan: %w", err)
+	}
+	if len(plan.Changes) != len(p.Stmts) {
+		return ErrStalePlan
+	}
+	for i := range p.Stmts {
+		if plan.Changes[i].Cmd != p.Stmts[i].Cmd {
+			return ErrStalePlan
+		}
+	}
+	if err := c.ApplyChanges(ctx, changes); err != nil {
+		return fmt.Errorf("sql/sqlclient: apply plan: %w", err)
+	}
+	return nil
+}
+
+// ReadState inspects the connected database and returns its current state. If the client
+// is not bound to a schema, the given schemas are inspected, or all of them if none given.
+func (c *Client) ReadState(ctx context.Context, schemas ...string) (*State, error) {
+	var r *schema.Realm
+	switch {
+	case c.URL.Schema != "":
+		s, err := c.InspectSchema(ctx, c.URL.Schema, nil)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlclient: read state: %w", err)
+		}
+		r = schema.NewRealm(s)
+	default:
+		var err error
+		if r, err = c.InspectRealm(ctx, &schema.InspectRealmOption{Schemas: schemas}); err != nil {
+			return nil, fmt.Errorf("sql/sqlclient: read state: %w", err)
+		}
+	}
+	if c.Marshaler == nil {
+		return nil, errors.New("sql/sqlclient: read state: missing schema marshaler")
+	}
+	doc, err := c.MarshalSpec(r)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlclient: read state: %w", err)
+	}
+	version, err := c.stateVersion(r)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlclient: read state: %w", err)
+	}
+	return &State{Version: version, HCL: string(doc), Objects: ObjectIDs(r), Realm: r}, nil
+}
+
+// ObjectID returns a deterministic identifier for the given schema object. Identifiers are built
+// from the object type and its qualified name. For example, "table/public/users". An empty string
+// is returned for objects that are not supported. Columns and checks are identified by ColumnID
+// and CheckID, as they do not reference their tables.
+func ObjectID(o any) string {
+	switch o := o.(type) {
+	case *schema.Schema:
+		return objectID("schema", o.Name)
+	case *schema.Table:
+		return objectID("table", schemaName(o.Schema), o.Name)
+	case *schema.View:
+		return objectID("view", schemaName(o.Schema), o.Name)
+	case *schema.Func:
+		return objectID("function", schemaName(o.Schema), o.Name)
+	case *schema.Proc:
+		return objectID("procedure", schemaName(o.Schema), o.Name)
+	case *schema.Index:
+		if o.Table == nil {
+			return ""
+		}
+		return objectID("index", schemaName(o.Table.Schema), o.Table.Name, o.Name)
+	case *schema.ForeignKey:
+		if o.Table == nil {
+			return ""
+		}
+		return objectID("foreign_key", schemaName(o.Table.Schema), o.Table.Name, o.Symbol)
+	case *schema.Trigger:
+		if o.Table == nil {
+			return ""
+		}
+		return objectID("trigger", schemaName(o.Table.Schema), o.Table.Name, o.Name)
+	}
+	return ""
+}
+
+// ColumnID returns a deterministic identifier for the given table column.
+func ColumnID(t *schema.Table, c *schema.Column) string {
+	return objectID("column", schemaName(t.Schema), t.Name, c.Name)
+}
+
+// CheckID returns a deterministic identifier for the given table check.
+func CheckID(t *schema.Table, c *schema.Check) string {
+	return objectID("check", schemaName(t.Schema), t.Name, c.Name)
+}
+
+// ObjectIDs returns the identifiers of all supported objects in the realm.
+func ObjectIDs(r *schema.Realm) []string {
+	var ids []string
+	for _, s := range r.Schemas {
+		ids = append(ids, ObjectID(s))
+		for _, t := range s.Tables {
+			ids = append(ids, ObjectID(t))
+			for _, c := range t.Columns {
+				ids = append(ids, ColumnID(t, c))
+			}
+			for _, idx := range t.Indexes {
+				ids = append(ids, ObjectID(idx))
+			}
+			for _, fk := range t.ForeignKeys {
+				ids = append(ids, ObjectID(fk))
+			}
+			for _, a := range t.Attrs {
+				if c, ok := a.(*schema.Check); ok {
+					ids = append(ids, CheckID(t, c))
+				}
+			}
+			for _, tr := range t.Triggers {
+				ids = append(ids, ObjectID(tr))
+			}
+		}
+		for _, v := range s.Views {
+			ids = append(ids, ObjectID(v))
+		}
+		for _, f := range s.Funcs {
+			ids = append(ids, ObjectID(f))
+		}
+		for _, p := range s.Procs {
+			ids = append(ids, ObjectID(p))
+		}
+	}
+	return ids
+}
+
+// objectChanges returns the object changes of the given schema changes.
+func objectChanges(t *schema.Table, changes []schema.Change) []*ObjectChange {
+	var objs []*ObjectChange
+	add := func(id, action string) {
+		if id != "" {
+			objs = append(objs, &ObjectChange{ID: id, Action: action})
+		}
+	}
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			add(ObjectID(c.S), ActionCreate)
+		case *schema.DropSchema:
+			add(ObjectID(c.S), ActionDrop)
+		case *schema.ModifySchema:
+			add(ObjectID(c.S), ActionModify)
+		case *schema.AddTable:
+			add(ObjectID(c.T), ActionCreate)
+		case *schema.DropTable:
+			add(ObjectID(c.T), ActionDrop)
+		case *schema.ModifyTable:
+			add(ObjectID(c.T), ActionModify)
+			objs = append(objs, objectChanges(c.T, c.Changes)...)
+		case *schema.RenameTable:
+			add(ObjectID(c.From), ActionRename)
+		case *schema.AddView:
+			add(ObjectID(c.V), ActionCreate)
+		case *schema.DropView:
+			add(ObjectID(c.V), ActionDrop)
+		case *schema.ModifyView:
+			add(ObjectID(c.To), ActionModify)
+		case *schema.RenameView:
+			add(ObjectID(c.From), ActionRename)
+		case *schema.AddTrigger:
+			add(ObjectID(c.T), ActionCreate)
+		case *schema.DropTrigger:
+			add(ObjectID(c.T), ActionDrop)
+		case *schema.ModifyTrigger:
+			add(ObjectID(c.To), ActionModify)
+		case *schema.AddFunc:
+			add(ObjectID(c.F), ActionCreate)
+		case *schema.DropFunc:
+			add(ObjectID(c.F), ActionDrop)
+		case *schema.ModifyFunc:
+			add(ObjectID(c.To), ActionModify)
+		case *schema.RenameFunc:
+			add(ObjectID(c.From), ActionRename)
+		case *schema.AddProc:
+			add(ObjectID(c.P), ActionCreate)
+		case *schema.DropProc:
+			add(ObjectID(c.P), ActionDrop)
+		case *schema.ModifyProc:
+			add(ObjectID(c.To), ActionModify)
+		case *schema.RenameProc:
+			add(ObjectID(c.From), ActionRename)
+		case *schema.AddIndex:
+			add(ObjectID(c.I), ActionCreate)
+		case *schema.DropIndex:
+			add(ObjectID(c.I), ActionDrop)
+		case *schema.ModifyIndex:
+			add(ObjectID(c.To), ActionModify)
+		case *schema.RenameIndex:
+			add(ObjectID(c.From), ActionRename)
+		case *schema.AddForeignKey:
+			add(ObjectID(c.F), ActionCreate)
+		case *schema.DropForeignKey:
+			add(ObjectID(c.F), ActionDrop)
+		case *schema.ModifyForeignKey:
+			add(ObjectID(c.To), ActionModify)
+		}
+		if t == nil {
+			continue
+		}
+		// Table-level changes.
+		switch c := c.(type) {
+		case *schema.AddColumn:
+			add(ColumnID(t, c.C), ActionCreate)
+		case *schema.DropColumn:
+			add(ColumnID(t, c.C), ActionDrop)
+		case *schema.ModifyColumn:
+			add(ColumnID(t, c.To), ActionModify)
+		case *schema.RenameColumn:
+			add(ColumnID(t, c.From), ActionRename)
+		case *schema.AddCheck:
+			add(CheckID(t, c.C), ActionCreate)
+		case *schema.DropCheck:
+			add(CheckID(t, c.C), ActionDrop)
+		case *schema.ModifyCheck:
+			add(CheckID(t, c.To), ActionModify)
+		}
+	}
+	return objs
+}
+
+// stmts converts the planned changes to statements.
+func stmts(changes []*migrate.Change) ([]*Stmt, error) {
+	stmts := make([]*Stmt, len(changes))
+	for i, c := range changes {
+		r, err := c.ReverseStmts()
+		if err != nil {
+			return nil, err
+		}
+		stmts[i] = &Stmt{Cmd: c.Cmd, Args: c.Args, Comment: c.Comment, Reverse: r}
+	}
+	return stmts, nil
+}
+
+func objectID(typ string, names ...string) string {
+	for i := range names {
+		names[i] = url.PathEscape(names[i])
+	}
+	return typ + "/" + strings.Join(names, "/")
+}
+
+func schemaName(s *schema.Schema) string {
+	if s == nil {
+		return ""
+	}
+	return s.Name
+}
@@ -0,0 +1,97 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_PlanState(t *testing.T) {
+	var (
+		ctx = context.Background()
+		drv = &reconcileDriver{current: schema.NewRealm(schema.New("main"))}
+		c   = &sqlclient.Client{
+			Name:      "sqlite3",
+			URL:       &sqlclient.URL{URL: &url.URL{Scheme: "sqlite"}},
+			Driver:    drv,
+			Marshaler: sqlite.MarshalHCL,
+			Evaluator: sqlite.EvalHCL,
+		}
+		desired = []byte(`
+schema "main" {}
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = int
+  }
+}
+`)
+	)
+	plan, err := c.PlanState(ctx, desired)
+	require.NoError(t, err)
+	require.NotEmpty(t, plan.Version)
+	require.Equal(t, []*sqlclient.ObjectChange{{ID: "table/main/users", Action: sqlclient.ActionCreate}}, plan.Objects)
+	require.Len(t, plan.Stmts, 1)
+	require.Equal(t, "CREATE TABLE `users` (`id` int NOT NULL)", plan.Stmts[0].Cmd)
+	require.Equal(t, []string{"DROP TABLE `users`"}, plan.Stmts[0].Reverse)
+
+	// Plans can be encoded and decoded.
+	buf, err := json.Marshal(plan)
+	require.NoError(t, err)
+	var decoded sqlclient.StatePlan
+	require.NoError(t, json.Unmarshal(buf, &decoded))
+	require.Equal(t, plan, &decoded)
+
+	// Canceled contexts are not applied.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, c.ApplyPlan(canceled, &decoded), context.Canceled)
+	require.Zero(t, drv.applied)
+
+	// Plans that do not match the current state are rejected.
+	stale := decoded
+	stale.Stmts = nil
+	require.ErrorIs(t, c.ApplyPlan(ctx, &stale), sqlclient.ErrStalePlan)
+	require.NoError(t, c.ApplyPlan(ctx, &decoded))
+	require.Equal(t, 1, drv.applied)
+	require.ErrorIs(t, c.ApplyPlan(ctx, &decoded), sqlclient.ErrStalePlan)
+
+	// The database is in sync with the desired state.
+	plan, err = c.PlanState(ctx, desired)
+	require.NoError(t, err)
+	require.Empty(t, plan.Objects)
+	require.Empty(t, plan.Stmts)
+	require.NoError(t, c.ApplyPlan(ctx, plan))
+	require.Equal(t, 1, drv.applied)
+
+	state, err := c.ReadState(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, state.Version)
+	require.Contains(t, state.HCL, `table "users"`)
+	require.Equal(t, []string{"schema/main", "table/main/users", "column/main/users/id"}, state.Objects)
+}
+
+func TestObjectID(t *testing.T) {
+	users := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("id", "int"))
+	users.AddIndexes(schema.NewIndex("users/id").AddColumns(users.Columns[0]))
+	require.Equal(t, "schema/public", sqlclient.ObjectID(users.Schema))
+	require.Equal(t, "table/public/users", sqlclient.ObjectID(users))
+	require.Equal(t, "index/public/users/users%2Fid", sqlclient.ObjectID(users.Indexes[0]))
+	require.Equal(t, "column/public/users/id", sqlclient.ColumnID(users, users.Columns[0]))
+	require.Equal(t, "view/public/v", sqlclient.ObjectID(schema.NewView("v", "SELECT 1").SetSchema(users.Schema)))
+	require.Empty(t, sqlclient.ObjectID(schema.NewIndex("i")))
+	require.Empty(t, sqlclient.ObjectID(1))
+}
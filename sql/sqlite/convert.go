@@ -9,14 +9,34 @@ import (
 	"strconv"
 	"strings"
 
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
 )
 
+// typeDefs holds the custom types registered using RegisterType.
+var typeDefs = sqlx.NewTypeDefs()
+
+// RegisterType registers a custom column type, such as types of loadable extensions,
+// that are parsed, formatted and compared using the given definition, instead of being
+// reported as unsupported. Registered types take precedence over the builtin types of
+// the driver. For example:
+//
+//	sqlite.RegisterType(&schema.TypeDef{Name: "vector"})
+func RegisterType(d *schema.TypeDef) error {
+	if err := typeDefs.Register(d); err != nil {
+		return fmt.Errorf("sqlite: register type: %w", err)
+	}
+	return nil
+}
+
 // FormatType converts types to one format. A lowered format.
 // This is due to SQLite flexibility to allow any data types
 // and use a set of rules to define the type affinity.
 // See: https://www.sqlite.org/datatype3.html
 func FormatType(t schema.Type) (string, error) {
+	if f, ok, err := typeDefs.Format(t); ok {
+		return f, err
+	}
 	var f string
 	switch t := t.(type) {
 	case *schema.BoolType:
@@ -53,6 +73,9 @@ func FormatType(t schema.Type) (string, error) {
 // It is expected to be one of the types in https://www.sqlite.org/datatypes.html,
 // or some of the common types used by ORMs like Ent.
 func ParseType(c string) (schema.Type, error) {
+	if t, ok, err := typeDefs.Parse(c); ok {
+		return t, err
+	}
 	// A datatype may be zero or more names.
 	if c == "" {
 		return &schema.BinaryType{T: "blob"}, nil
@@ -0,0 +1,62 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// planData plans the given data changes. SQLite databases allow a single writer
+// at a time and do not support loops in plain SQL. Hence, data changes are planned
+// as a single statement, and batched changes are rejected.
+func (s *state) planData(changes []schema.Change) error {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *migrate.BackfillColumn:
+			if c.BatchSize > 0 {
+				return fmt.Errorf("sqlite: backfilling column %q of table %q in batches is not supported", c.C.Name, c.T.Name)
+			}
+			column, expr := s.Build().Ident(c.C.Name).String(), c.Expr
+			if expr == "" && c.From != nil {
+				expr = s.Build().Ident(c.From.Name).String()
+			}
+			conds := []string{fmt.Sprintf("%s IS NOT (%s)", column, expr)}
+			if c.Where != "" {
+				conds = append([]string{"(" + c.Where + ")"}, conds...)
+			}
+			s.append(&migrate.Change{
+				Source:  c,
+				Cmd:     s.Build("UPDATE").Table(c.T).P("SET", column, "=", expr, "WHERE", strings.Join(conds, " AND ")).String(),
+				Comment: fmt.Sprintf("backfill column %q of table %q", c.C.Name, c.T.Name),
+			})
+		case *migrate.CopyTable:
+			if c.BatchSize > 0 {
+				return fmt.Errorf("sqlite: copying table %q in batches is not supported", c.From.Name)
+			}
+			columns := c.CopyColumns()
+			b := s.Build("INSERT INTO").Table(c.To).Wrap(func(b *sqlx.Builder) {
+				b.MapComma(columns, func(i int, b *sqlx.Builder) {
+					b.Ident(columns[i])
+				})
+			})
+			b.P("SELECT").MapComma(columns, func(i int, b *sqlx.Builder) {
+				b.Ident(columns[i])
+			})
+			s.append(&migrate.Change{
+				Source:  c,
+				Cmd:     b.P("FROM").Table(c.From).String(),
+				Comment: fmt.Sprintf("copy rows from table %q to table %q", c.From.Name, c.To.Name),
+			})
+		default:
+			return fmt.Errorf("unsupported data change %T", c)
+		}
+	}
+	return nil
+}
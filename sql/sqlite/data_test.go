@@ -0,0 +1,45 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges_Data(t *testing.T) {
+	var (
+		first = schema.NewStringColumn("first_name", "text")
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), first)
+	)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.BackfillColumn{T: users, C: first, Expr: "upper(first_name)"},
+		&migrate.CopyTable{From: users, To: schema.NewTable("users_copy"), Columns: []string{"first_name"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "UPDATE `users` SET `first_name` = upper(first_name) WHERE `first_name` IS NOT (upper(first_name))", plan.Changes[0].Cmd)
+	require.Equal(t, "INSERT INTO `users_copy` (`first_name`) SELECT `first_name` FROM `users`", plan.Changes[1].Cmd)
+
+	_, err = DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.RenameColumnSync{T: users, From: first, To: schema.NewStringColumn("given_name", "text")},
+	})
+	require.EqualError(t, err, "unsupported data change *migrate.RenameColumnSync")
+
+	// Batches are not supported.
+	_, err = DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.BackfillColumn{T: users, C: first, Expr: "upper(first_name)", BatchSize: 100},
+	})
+	require.EqualError(t, err, `sqlite: backfilling column "first_name" of table "users" in batches is not supported`)
+	_, err = DefaultPlan.PlanChanges(context.Background(), "data", []schema.Change{
+		&migrate.CopyTable{From: users, To: schema.NewTable("users_copy"), BatchSize: 100},
+	})
+	require.EqualError(t, err, `sqlite: copying table "users" in batches is not supported`)
+}
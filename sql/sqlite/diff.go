@@ -22,6 +22,47 @@ var DefaultDiff schema.Differ = &sqlx.Diff{DiffDriver: &diff{}}
 // A diff provides a SQLite implementation for sqlx.DiffDriver.
 type diff struct{}
 
+// defaultEquivs holds the DEFAULT expressions that are equivalent in SQLite,
+// but are written differently in the desired state and the inspected database.
+var defaultEquivs = sqlx.NewEquivalents(
+	[]string{"CURRENT_TIMESTAMP", "datetime('now')", "datetime()"},
+	[]string{"CURRENT_DATE", "date('now')", "date()"},
+	[]string{"CURRENT_TIME", "time('now')", "time()"},
+)
+
+// RegisterDefaultEquivalents registers the given DEFAULT expressions as equivalent, and
+// changes between them are not reported by the differ. For example:
+//
+//	sqlite.RegisterDefaultEquivalents("unixepoch()", "unixepoch('now')")
+func RegisterDefaultEquivalents(exprs ...string) {
+	defaultEquivs.Register(exprs...)
+}
+
+// indexExprs computes the canonical form of index expressions. SQLite keeps expressions
+// as they were written, and therefore, only their formatting is normalized by default.
+var indexExprs = sqlx.NewCanonicalizer()
+
+// CanonicalIndexExpr returns the canonical form of the given index expression. Expressions
+// with the same canonical form are considered equal by the differ.
+func CanonicalIndexExpr(x string) string {
+	return indexExprs.Canonical(x)
+}
+
+// RegisterIndexExprFuncs registers functions for computing the canonical form of index
+// expressions. For example, to match application-defined functions with their aliases:
+//
+//	sqlite.RegisterIndexExprFuncs(func(x string) string {
+//		return strings.ReplaceAll(x, "my_lower(", "lower(")
+//	})
+func RegisterIndexExprFuncs(fns ...func(string) string) {
+	indexExprs.Register(fns...)
+}
+
+// CanonicalIndexExpr implements the sqlx.IndexExprCanonicalizer interface.
+func (*diff) CanonicalIndexExpr(_ *schema.Table, x string) string {
+	return CanonicalIndexExpr(x)
+}
+
 // SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
 func (*diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
 	// No special schema attribute diffing for SQLite.
@@ -84,6 +125,9 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	if fromT == nil || toT == nil {
 		return false, fmt.Errorf("sqlite: missing type information for column %q", from.Name)
 	}
+	if changed, ok, err := typeDefs.Changed(fromT, toT); ok {
+		return changed, err
+	}
 	// Types are mismatched if they do not have the same "type affinity".
 	return reflect.TypeOf(fromT) != reflect.TypeOf(toT), nil
 }
@@ -95,7 +139,7 @@ func (d *diff) defaultChanged(from, to *schema.Column) bool {
 	if ok1 != ok2 {
 		return true
 	}
-	if d1 == d2 {
+	if d1 == d2 || defaultEquivs.Equal(d1, d2) {
 		return false
 	}
 	x1, err1 := sqlx.Unquote(d1)
@@ -138,7 +182,7 @@ func (d *diff) FindGeneratedIndex(t *schema.Table, idx *schema.Index) (*schema.I
 // IndexAttrChanged reports if the index attributes were changed.
 func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
 	var p1, p2 IndexPredicate
-	return sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || (p1.P != p2.P && p1.P != sqlx.MayWrap(p2.P))
+	return sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || !sqlx.ExprEqual(p1.P, p2.P)
 }
 
 // IndexPartAttrChanged reports if the index-part attributes were changed.
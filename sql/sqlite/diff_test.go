@@ -361,3 +361,32 @@ func TestDefaultDiff(t *testing.T) {
 	require.Len(t, changes, 1)
 	require.IsType(t, &schema.DropTable{}, changes[0])
 }
+
+func TestDiff_DefaultEquivalents(t *testing.T) {
+	from := schema.NewTable("users").SetSchema(schema.New("main")).
+		AddColumns(schema.NewTimeColumn("c", "datetime").SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP"}))
+	to := schema.NewTable("users").SetSchema(schema.New("main")).
+		AddColumns(schema.NewTimeColumn("c", "datetime").SetDefault(&schema.RawExpr{X: "datetime('now')"}))
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_IndexPredicate(t *testing.T) {
+	from := schema.NewTable("users").SetSchema(schema.New("main")).
+		AddColumns(schema.NewTimeColumn("deleted_at", "datetime"), schema.NewBoolColumn("active", "bool"))
+	from.AddIndexes(
+		schema.NewIndex("live").AddColumns(from.Columns[0]).AddAttrs(&IndexPredicate{P: "deleted_at  IS NULL AND active"}),
+		schema.NewIndex("dead").AddColumns(from.Columns[0]).AddAttrs(&IndexPredicate{P: "deleted_at IS NOT NULL"}),
+	)
+	to := schema.NewTable("users").SetSchema(schema.New("main")).
+		AddColumns(schema.NewTimeColumn("deleted_at", "datetime"), schema.NewBoolColumn("active", "bool"))
+	to.AddIndexes(
+		schema.NewIndex("live").AddColumns(to.Columns[0]).AddAttrs(&IndexPredicate{P: "(deleted_at is null and active)"}),
+		schema.NewIndex("dead").AddColumns(to.Columns[0]).AddAttrs(&IndexPredicate{P: "deleted_at IS NULL"}),
+	)
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "dead", changes[0].(*schema.ModifyIndex).To.Name)
+}
@@ -51,14 +51,7 @@ func init() {
 		sqlclient.RegisterTxOpener(OpenTx),
 		sqlclient.RegisterCodec(MarshalHCL, EvalHCL),
 		sqlclient.RegisterFlavours("sqlite"),
-		sqlclient.RegisterURLParser(sqlclient.URLParserFunc(func(u *url.URL) *sqlclient.URL {
-			uc := &sqlclient.URL{URL: u, DSN: strings.TrimPrefix(u.String(), u.Scheme+"://"), Schema: mainFile}
-			if mode := u.Query().Get("mode"); mode == "memory" {
-				// The "file:" prefix is mandatory for memory modes.
-				uc.DSN = "file:" + uc.DSN
-			}
-			return uc
-		})),
+		sqlclient.RegisterURLParser(parser{}),
 	)
 	sqlclient.Register(
 		"libsql",
@@ -72,6 +65,27 @@ func init() {
 	)
 }
 
+type parser struct{}
+
+// ParseURL implements the sqlclient.URLParser interface.
+func (parser) ParseURL(u *url.URL) *sqlclient.URL {
+	uc := &sqlclient.URL{URL: u, DSN: strings.TrimPrefix(u.String(), u.Scheme+"://"), Schema: mainFile}
+	if mode := u.Query().Get("mode"); mode == "memory" {
+		// The "file:" prefix is mandatory for memory modes.
+		uc.DSN = "file:" + uc.DSN
+	}
+	return uc
+}
+
+// ChangeReadOnly implements the sqlclient.ReadOnlyChanger interface.
+func (parser) ChangeReadOnly(u *url.URL) *url.URL {
+	nu := *u
+	q := nu.Query()
+	q.Set("_query_only", "true")
+	nu.RawQuery = q.Encode()
+	return &nu
+}
+
 // Open opens a new SQLite driver.
 func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 	var (
@@ -123,6 +137,18 @@ func (d *Driver) Snapshot(ctx context.Context) (migrate.RestoreFunc, error) {
 	}, nil
 }
 
+// InferViewColumns implements the schema.ViewInferrer interface.
+func (d *Driver) InferViewColumns(ctx context.Context, r *schema.Realm) error {
+	return (&sqlx.DevDriver{Driver: d}).InferViewColumns(ctx, r, &sqlx.Builder{QuoteOpening: '`', QuoteClosing: '`'}, viewColumnType)
+}
+
+// viewColumnType converts the type of a view column, as reported by the database driver,
+// to a schema type. Drivers report the declared types of columns, and an empty name for
+// expressions.
+func viewColumnType(ct *sql.ColumnType) (schema.Type, error) {
+	return ParseType(strings.ToLower(ct.DatabaseTypeName()))
+}
+
 // CheckClean implements migrate.CleanChecker.
 func (d *Driver) CheckClean(ctx context.Context, revT *migrate.TableIdent) error {
 	r, err := d.InspectRealm(ctx, nil)
@@ -163,6 +189,11 @@ func (d *Driver) Lock(_ context.Context, name string, timeout time.Duration) (sc
 	return acquireLock(path, timeout)
 }
 
+// QuoteIdent returns the given identifier quoted, using the builder of the driver.
+func (*Driver) QuoteIdent(s string) string {
+	return (&state{}).Build().Ident(s).String()
+}
+
 // Version returns the version of the connected database.
 func (d *Driver) Version() string {
 	return d.conn.version
@@ -8,6 +8,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -44,6 +45,13 @@ func TestParser_ParseURL(t *testing.T) {
 	require.Equal(t, []string{"wss://example.com/db.sqlite3?_fk=1"}, drv.opened)
 }
 
+func TestParser_ChangeReadOnly(t *testing.T) {
+	u, err := url.Parse("sqlite://file.db?_fk=1")
+	require.NoError(t, err)
+	u = parser{}.ChangeReadOnly(u)
+	require.Equal(t, "file.db?_fk=1&_query_only=true", parser{}.ParseURL(u).DSN)
+}
+
 func TestDriver_LockAcquired(t *testing.T) {
 	drv := &Driver{}
 
@@ -48,13 +48,28 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 			}
 		}
 		sqlx.LinkSchemaTables(r.Schemas)
+		if opts.Stats {
+			if err := i.inspectStats(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Triggers {
+			for _, s := range schemas {
+				if err := i.inspectTriggers(ctx, s); err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
 	if sqlx.ModeInspectRealm(opts).Is(schema.InspectViews) {
 		if err := i.inspectViews(ctx, r, nil); err != nil {
 			return nil, err
 		}
 	}
-	return sqlx.ExcludeRealm(r, opts.Exclude)
+	if r, err = sqlx.ExcludeRealm(r, opts.Exclude); err != nil {
+		return nil, err
+	}
+	return r, schema.AnonymizeRealm(r, opts.Anonymize)
 }
 
 // InspectSchema returns schema descriptions of the tables in the given schema.
@@ -90,13 +105,27 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 			}
 		}
 		sqlx.LinkSchemaTables(schemas)
+		if opts.Stats {
+			if err := i.inspectStats(ctx, r); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Triggers {
+			if err := i.inspectTriggers(ctx, r.Schemas[0]); err != nil {
+				return nil, err
+			}
+		}
 	}
 	if sqlx.ModeInspectSchema(opts).Is(schema.InspectViews) {
 		if err := i.inspectViews(ctx, r, opts); err != nil {
 			return nil, err
 		}
 	}
-	return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
+	s, err := sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return s, schema.AnonymizeSchema(s, opts.Anonymize)
 }
 
 func (i *inspect) inspectTable(ctx context.Context, t *schema.Table) error {
@@ -218,13 +247,11 @@ func (i *inspect) addIndexes(t *schema.Table, rows *sql.Rows) error {
 			},
 		}
 		if partial {
-			i := strings.Index(stmt.String, "WHERE")
-			if i == -1 {
+			p, ok := indexPredicate(stmt.String)
+			if !ok {
 				return fmt.Errorf("missing partial WHERE clause in: %s", stmt.String)
 			}
-			idx.Attrs = append(idx.Attrs, &IndexPredicate{
-				P: strings.TrimSpace(stmt.String[i+5:]),
-			})
+			idx.Attrs = append(idx.Attrs, &IndexPredicate{P: p})
 		}
 		t.Indexes = append(t.Indexes, idx)
 	}
@@ -237,6 +264,40 @@ var (
 	reIdxDesc  = regexp.MustCompile("(?i)\\s+DESC\\s*$")
 )
 
+// indexPredicate extracts the predicate of a partial index from its CREATE statement.
+// Unlike a plain text search, keywords in quoted strings or identifiers are skipped.
+func indexPredicate(stmt string) (string, bool) {
+	word := func(i int) bool {
+		if i < 0 || i >= len(stmt) {
+			return false
+		}
+		c := stmt[i]
+		return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+	}
+	for i, depth := 0, 0; i < len(stmt); i++ {
+		switch c := stmt[i]; c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '\'', '"', '`', '[':
+			if c == '[' {
+				c = ']'
+			}
+			j := strings.IndexByte(stmt[i+1:], c)
+			if j == -1 {
+				return "", false
+			}
+			i += j + 1
+		case 'w', 'W':
+			if depth == 0 && i+5 < len(stmt) && strings.EqualFold(stmt[i:i+5], "WHERE") && !word(i-1) && !word(i+5) {
+				return strings.TrimSpace(stmt[i+5:]), true
+			}
+		}
+	}
+	return "", false
+}
+
 func (i *inspect) indexInfo(ctx context.Context, t *schema.Table, idx *schema.Index) error {
 	var (
 		hasExpr   bool
@@ -530,6 +591,9 @@ func defaultExpr(x string) schema.Expr {
 	}
 }
 
+// Partial implements the sqlx.PartialAttr interface.
+func (p *IndexPredicate) Partial() bool { return p.P != "" }
+
 // blob literals are hex strings preceded by 'x' (or 'X).
 func isBlob(s string) bool {
 	if (strings.HasPrefix(s, "x'") || strings.HasPrefix(s, "X'")) && strings.HasSuffix(s, "'") {
@@ -296,6 +296,22 @@ CREATE TABLE users(
 	}
 }
 
+func TestIndexPredicate(t *testing.T) {
+	for stmt, want := range map[string]string{
+		"CREATE INDEX i ON t(c) WHERE c <> NULL":                         "c <> NULL",
+		"CREATE INDEX i ON t(c)\nwhere\tc > 0":                           "c > 0",
+		"CREATE INDEX i ON t(c) WHERE c = 'WHERE'":                       "c = 'WHERE'",
+		"CREATE INDEX \"where\" ON \"where\"(c) WHERE c IS NOT NULL":     "c IS NOT NULL",
+		"CREATE INDEX i ON somewhere(c, iif(c, 1, 0)) WHERE [where] > 0": "[where] > 0",
+	} {
+		p, ok := indexPredicate(stmt)
+		require.True(t, ok, stmt)
+		require.Equal(t, want, p, stmt)
+	}
+	_, ok := indexPredicate("CREATE INDEX i ON t(c)")
+	require.False(t, ok)
+}
+
 func TestRegex_TableFK(t *testing.T) {
 	tests := []struct {
 		input   string
@@ -0,0 +1,499 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/sqlclient"
+)
+
+// The libSQL remote protocol (Hrana over HTTP) allows executing statements on hosted SQLite
+// databases, such as Turso, using HTTPS requests. Databases are opened using URLs with the
+// "libsql+https" scheme (or "libsql+http" for local servers), and the authentication token
+// is passed using the "authToken" parameter, that is redacted from printed URLs. For example:
+//
+//	libsql+https://db-org.turso.io?authToken=<token>
+//
+// See: https://github.com/tursodatabase/libsql/blob/main/docs/HRANA_3_SPEC.md
+const (
+	libsqlHTTPS = "libsql+https"
+	libsqlHTTP  = "libsql+http"
+)
+
+func init() {
+	sqlclient.Register(
+		libsqlHTTPS,
+		sqlclient.OpenerFunc(openRemote),
+		sqlclient.RegisterDriverOpener(Open),
+		sqlclient.RegisterTxOpener(OpenTx),
+		sqlclient.RegisterCodec(MarshalHCL, EvalHCL),
+		sqlclient.RegisterFlavours(libsqlHTTP),
+		sqlclient.RegisterURLParser(remoteParser{}),
+		sqlclient.RegisterSecretParams("authToken"),
+	)
+}
+
+type remoteParser struct{}
+
+// ParseURL implements the sqlclient.URLParser interface. The DSN holds the
+// HTTP(S) address of the database, without the authentication token.
+func (remoteParser) ParseURL(u *url.URL) *sqlclient.URL {
+	du := *u
+	du.Scheme = strings.TrimPrefix(u.Scheme, "libsql+")
+	q := du.Query()
+	q.Del("authToken")
+	du.RawQuery = q.Encode()
+	return &sqlclient.URL{URL: u, DSN: du.String(), Schema: mainFile}
+}
+
+// openRemote opens a client to a database that is accessed using the libSQL remote protocol.
+func openRemote(ctx context.Context, u *url.URL) (*sqlclient.Client, error) {
+	ur := remoteParser{}.ParseURL(u)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg, ok := sqlclient.TLSConfigFromContext(ctx); ok {
+		transport.TLSClientConfig = cfg
+	}
+	if d, ok := sqlclient.DialerFromContext(ctx); ok {
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return d(ctx, network, addr)
+		}
+	}
+	db := sql.OpenDB(&remoteConnector{
+		url:    ur.DSN,
+		token:  u.Query().Get("authToken"),
+		client: &http.Client{Transport: transport},
+	})
+	drv, err := Open(db)
+	if err != nil {
+		if cerr := db.Close(); cerr != nil {
+			err = fmt.Errorf("%w: %v", err, cerr)
+		}
+		return nil, err
+	}
+	return &sqlclient.Client{
+		Name:   libsqlHTTPS,
+		DB:     db,
+		URL:    ur,
+		Driver: drv,
+	}, nil
+}
+
+type (
+	// remoteConnector implements the driver.Connector interface for
+	// databases that are accessed using the libSQL remote protocol.
+	remoteConnector struct {
+		url    string
+		token  string
+		client *http.Client
+	}
+
+	// remoteConn represents a stream on the server. Streams are kept open (using their
+	// baton) only during transactions, as they expire on the server after a short idle time.
+	remoteConn struct {
+		*remoteConnector
+		baseURL string
+		baton   string
+		inTx    bool
+	}
+
+	// remoteTx implements the driver.Tx interface.
+	remoteTx struct{ c *remoteConn }
+
+	// remoteResult implements the driver.Result interface.
+	remoteResult struct{ affected, lastID int64 }
+
+	// remoteRows implements the driver.Rows interface.
+	remoteRows struct {
+		cols []remoteCol
+		rows [][]remoteValue
+		pos  int
+	}
+)
+
+var (
+	_ driver.Connector                      = (*remoteConnector)(nil)
+	_ driver.ExecerContext                  = (*remoteConn)(nil)
+	_ driver.QueryerContext                 = (*remoteConn)(nil)
+	_ driver.ConnBeginTx                    = (*remoteConn)(nil)
+	_ driver.Pinger                         = (*remoteConn)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*remoteRows)(nil)
+)
+
+// Connect implements the driver.Connector interface.
+func (c *remoteConnector) Connect(context.Context) (driver.Conn, error) {
+	return &remoteConn{remoteConnector: c, baseURL: c.url}, nil
+}
+
+// Driver implements the driver.Connector interface.
+func (c *remoteConnector) Driver() driver.Driver {
+	return remoteDriver{}
+}
+
+// remoteDriver implements the driver.Driver interface. Connections
+// are opened using the remoteConnector, and not by their name.
+type remoteDriver struct{}
+
+// Open implements the driver.Driver interface.
+func (remoteDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("sql/sqlite: libsql remote connections must be opened using a connector")
+}
+
+// Prepare implements the driver.Conn interface. Statements are not prepared on the server,
+// and are sent as-is on execution.
+func (c *remoteConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+// Close implements the driver.Conn interface.
+func (c *remoteConn) Close() error {
+	if c.baton == "" {
+		return nil
+	}
+	_, err := c.pipeline(context.Background(), nil)
+	return err
+}
+
+// Begin implements the driver.Conn interface.
+func (c *remoteConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements the driver.ConnBeginTx interface.
+func (c *remoteConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.inTx {
+		return nil, errors.New("sql/sqlite: nested transactions are not supported")
+	}
+	stmt := "BEGIN"
+	if opts.ReadOnly {
+		stmt = "BEGIN DEFERRED"
+	}
+	c.inTx = true
+	if _, err := c.execute(ctx, stmt, nil); err != nil {
+		c.inTx = false
+		return nil, err
+	}
+	return &remoteTx{c: c}, nil
+}
+
+// Ping implements the driver.Pinger interface.
+func (c *remoteConn) Ping(ctx context.Context) error {
+	_, err := c.execute(ctx, "SELECT 1", nil)
+	return err
+}
+
+// ExecContext implements the driver.ExecerContext interface.
+func (c *remoteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r, err := c.execute(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	res := &remoteResult{affected: r.AffectedRowCount}
+	if r.LastInsertRowID != nil {
+		if res.lastID, err = strconv.ParseInt(*r.LastInsertRowID, 10, 64); err != nil {
+			return nil, fmt.Errorf("sql/sqlite: parse last insert rowid %q: %w", *r.LastInsertRowID, err)
+		}
+	}
+	return res, nil
+}
+
+// QueryContext implements the driver.QueryerContext interface.
+func (c *remoteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r, err := c.execute(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteRows{cols: r.Cols, rows: r.Rows}, nil
+}
+
+// Commit implements the driver.Tx interface.
+func (tx *remoteTx) Commit() error {
+	return tx.end("COMMIT")
+}
+
+// Rollback implements the driver.Tx interface.
+func (tx *remoteTx) Rollback() error {
+	return tx.end("ROLLBACK")
+}
+
+// end ends the transaction, and closes its stream on the server.
+func (tx *remoteTx) end(stmt string) error {
+	tx.c.inTx = false
+	_, err := tx.c.execute(context.Background(), stmt, nil)
+	return err
+}
+
+// LastInsertId implements the driver.Result interface.
+func (r *remoteResult) LastInsertId() (int64, error) {
+	return r.lastID, nil
+}
+
+// RowsAffected implements the driver.Result interface.
+func (r *remoteResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+// Columns implements the driver.Rows interface.
+func (r *remoteRows) Columns() []string {
+	names := make([]string, len(r.cols))
+	for i, c := range r.cols {
+		if c.Name != nil {
+			names[i] = *c.Name
+		}
+	}
+	return names
+}
+
+// ColumnTypeDatabaseTypeName implements the driver.RowsColumnTypeDatabaseTypeName interface.
+// Like the SQLite drivers, the declared type of the column is returned, and an empty string
+// for expressions.
+func (r *remoteRows) ColumnTypeDatabaseTypeName(i int) string {
+	if t := r.cols[i].DeclType; t != nil {
+		return strings.ToUpper(*t)
+	}
+	return ""
+}
+
+// Close implements the driver.Rows interface.
+func (r *remoteRows) Close() error {
+	r.pos = len(r.rows)
+	return nil
+}
+
+// Next implements the driver.Rows interface.
+func (r *remoteRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	for i, v := range r.rows[r.pos] {
+		x, err := v.value()
+		if err != nil {
+			return err
+		}
+		dest[i] = x
+	}
+	r.pos++
+	return nil
+}
+
+type (
+	// remoteStmt is the statement sent to the server.
+	remoteStmt struct {
+		SQL      string        `json:"sql"`
+		Args     []remoteValue `json:"args,omitempty"`
+		WantRows bool          `json:"want_rows"`
+	}
+
+	// remoteRequest is a request in a pipeline.
+	remoteRequest struct {
+		Type string      `json:"type"`
+		Stmt *remoteStmt `json:"stmt,omitempty"`
+	}
+
+	// remoteCol is a column in a statement result.
+	remoteCol struct {
+		Name     *string `json:"name"`
+		DeclType *string `json:"decltype"`
+	}
+
+	// remoteStmtResult is the result of an executed statement.
+	remoteStmtResult struct {
+		Cols             []remoteCol     `json:"cols"`
+		Rows             [][]remoteValue `json:"rows"`
+		AffectedRowCount int64           `json:"affected_row_count"`
+		LastInsertRowID  *string         `json:"last_insert_rowid"`
+	}
+
+	// remoteValue is a value that is sent to, or returned by, the server.
+	remoteValue struct {
+		Type   string          `json:"type"`
+		Value  json.RawMessage `json:"value,omitempty"`
+		Base64 string          `json:"base64,omitempty"`
+	}
+)
+
+// execute sends the given statement to the server and returns its result.
+// Statements executed outside of transactions close their stream.
+func (c *remoteConn) execute(ctx context.Context, query string, args []driver.NamedValue) (*remoteStmtResult, error) {
+	stmt := &remoteStmt{SQL: query, WantRows: true}
+	for _, a := range args {
+		if a.Name != "" {
+			return nil, fmt.Errorf("sql/sqlite: named argument %q is not supported by libsql remote connections", a.Name)
+		}
+		v, err := toRemoteValue(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Args = append(stmt.Args, v)
+	}
+	return c.pipeline(ctx, stmt)
+}
+
+// pipeline sends the given statement (if any) to the server, followed by a close
+// request, unless the connection is in a transaction.
+func (c *remoteConn) pipeline(ctx context.Context, stmt *remoteStmt) (*remoteStmtResult, error) {
+	var (
+		reqs []remoteRequest
+		body = struct {
+			Baton    *string         `json:"baton"`
+			Requests []remoteRequest `json:"requests"`
+		}{}
+	)
+	if stmt != nil {
+		reqs = append(reqs, remoteRequest{Type: "execute", Stmt: stmt})
+	}
+	if !c.inTx {
+		reqs = append(reqs, remoteRequest{Type: "close"})
+	}
+	if c.baton != "" {
+		body.Baton = &c.baton
+	}
+	body.Requests = reqs
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.baseURL, "/")+"/v2/pipeline", bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sql/sqlite: libsql request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("sql/sqlite: libsql request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	var res struct {
+		Baton   *string `json:"baton"`
+		BaseURL *string `json:"base_url"`
+		Results []struct {
+			Type     string `json:"type"`
+			Response *struct {
+				Type   string            `json:"type"`
+				Result *remoteStmtResult `json:"result"`
+			} `json:"response"`
+			Error *struct {
+				Message string `json:"message"`
+				Code    string `json:"code"`
+			} `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("sql/sqlite: decode libsql response: %w", err)
+	}
+	// The baton and the base URL must be used
+	// in the following requests of the stream.
+	c.baton = ""
+	if res.Baton != nil {
+		c.baton = *res.Baton
+	}
+	if res.BaseURL != nil && *res.BaseURL != "" {
+		c.baseURL = *res.BaseURL
+	}
+	if len(res.Results) != len(reqs) {
+		return nil, fmt.Errorf("sql/sqlite: unexpected number of libsql results: %d", len(res.Results))
+	}
+	for _, r := range res.Results {
+		if r.Type == "error" && r.Error != nil {
+			if r.Error.Code != "" {
+				return nil, fmt.Errorf("sql/sqlite: %s (%s)", r.Error.Message, r.Error.Code)
+			}
+			return nil, fmt.Errorf("sql/sqlite: %s", r.Error.Message)
+		}
+	}
+	if stmt == nil {
+		return nil, nil
+	}
+	if r := res.Results[0].Response; r != nil && r.Result != nil {
+		return r.Result, nil
+	}
+	return nil, errors.New("sql/sqlite: missing libsql statement result")
+}
+
+// toRemoteValue converts a driver value to its protocol representation.
+// Integers are encoded as strings to avoid precision loss.
+func toRemoteValue(v driver.Value) (remoteValue, error) {
+	var (
+		x   any
+		typ string
+	)
+	switch v := v.(type) {
+	case nil:
+		return remoteValue{Type: "null"}, nil
+	case int64:
+		typ, x = "integer", strconv.FormatInt(v, 10)
+	case float64:
+		typ, x = "float", v
+	case bool:
+		typ, x = "integer", "0"
+		if v {
+			x = "1"
+		}
+	case string:
+		typ, x = "text", v
+	case []byte:
+		return remoteValue{Type: "blob", Base64: base64.StdEncoding.EncodeToString(v)}, nil
+	case time.Time:
+		typ, x = "text", v.Format(time.RFC3339Nano)
+	default:
+		return remoteValue{}, fmt.Errorf("sql/sqlite: unsupported argument type %T", v)
+	}
+	buf, err := json.Marshal(x)
+	if err != nil {
+		return remoteValue{}, err
+	}
+	return remoteValue{Type: typ, Value: buf}, nil
+}
+
+// value returns the driver value of a protocol value.
+func (v remoteValue) value() (driver.Value, error) {
+	switch v.Type {
+	case "null":
+		return nil, nil
+	case "integer":
+		var s string
+		if err := json.Unmarshal(v.Value, &s); err != nil {
+			return nil, fmt.Errorf("sql/sqlite: invalid integer value %s: %w", v.Value, err)
+		}
+		return strconv.ParseInt(s, 10, 64)
+	case "float":
+		var f float64
+		if err := json.Unmarshal(v.Value, &f); err != nil {
+			return nil, fmt.Errorf("sql/sqlite: invalid float value %s: %w", v.Value, err)
+		}
+		return f, nil
+	case "text":
+		var s string
+		if err := json.Unmarshal(v.Value, &s); err != nil {
+			return nil, fmt.Errorf("sql/sqlite: invalid text value %s: %w", v.Value, err)
+		}
+		return s, nil
+	case "blob":
+		return base64.StdEncoding.DecodeString(v.Base64)
+	default:
+		return nil, fmt.Errorf("sql/sqlite: unexpected value type %q", v.Type)
+	}
+}
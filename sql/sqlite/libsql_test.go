@@ -0,0 +1,162 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+// libsqlServer is a fake libSQL server that responds to statements
+// with the results registered in the results map.
+type libsqlServer struct {
+	*httptest.Server
+	results map[string]any
+	reqs    []json.RawMessage
+	auth    []string
+}
+
+func newLibsqlServer(t *testing.T, results map[string]any) *libsqlServer {
+	s := &libsqlServer{results: results}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/v2/pipeline", r.URL.Path)
+		var body struct {
+			Baton    *string `json:"baton"`
+			Requests []struct {
+				Type string `json:"type"`
+				Stmt *struct {
+					SQL string `json:"sql"`
+				} `json:"stmt"`
+			} `json:"requests"`
+		}
+		raw := json.RawMessage{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&raw))
+		require.NoError(t, json.Unmarshal(raw, &body))
+		s.reqs = append(s.reqs, raw)
+		s.auth = append(s.auth, r.Header.Get("Authorization"))
+		var (
+			baton   any = "baton"
+			results []any
+		)
+		for _, req := range body.Requests {
+			switch req.Type {
+			case "close":
+				baton = nil
+				results = append(results, map[string]any{"type": "ok", "response": map[string]any{"type": "close"}})
+			case "execute":
+				res, ok := s.results[req.Stmt.SQL]
+				if !ok {
+					results = append(results, map[string]any{"type": "error", "error": map[string]any{"message": "no such table: t", "code": "SQLITE_ERROR"}})
+					continue
+				}
+				results = append(results, map[string]any{"type": "ok", "response": map[string]any{"type": "execute", "result": res}})
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"baton": baton, "base_url": nil, "results": results}))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestRemoteParser_ParseURL(t *testing.T) {
+	u, err := url.Parse("libsql+https://db-org.turso.io?authToken=secret&a=b")
+	require.NoError(t, err)
+	ur := remoteParser{}.ParseURL(u)
+	require.Equal(t, "https://db-org.turso.io?a=b", ur.DSN)
+	require.Equal(t, mainFile, ur.Schema)
+
+	u, err = url.Parse("libsql+http://127.0.0.1:8080")
+	require.NoError(t, err)
+	require.Equal(t, "http://127.0.0.1:8080", remoteParser{}.ParseURL(u).DSN)
+}
+
+func TestRemote_Open(t *testing.T) {
+	srv := newLibsqlServer(t, map[string]any{
+		"SELECT sqlite_version()": map[string]any{
+			"cols": []any{map[string]any{"name": "sqlite_version()", "decltype": nil}},
+			"rows": []any{[]any{map[string]any{"type": "text", "value": "3.44.0"}}},
+		},
+		"SELECT name FROM pragma_collation_list()": map[string]any{
+			"cols": []any{map[string]any{"name": "name", "decltype": nil}},
+			"rows": []any{
+				[]any{map[string]any{"type": "text", "value": "BINARY"}},
+				[]any{map[string]any{"type": "text", "value": "NOCASE"}},
+			},
+		},
+	})
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	c, err := sqlclient.Open(context.Background(), "libsql+"+u.Scheme+"://"+u.Host+"?authToken=secret")
+	require.NoError(t, err)
+	defer c.Close()
+	require.Equal(t, "3.44.0", c.Driver.(*Driver).Version())
+	require.Equal(t, srv.URL, c.URL.DSN)
+	require.Equal(t, "libsql+"+u.Scheme+"://"+u.Host+"?authToken=xxxxx", c.URL.Redacted())
+	require.Equal(t, []string{"Bearer secret", "Bearer secret"}, srv.auth)
+	// Statements executed outside of transactions close their stream.
+	require.JSONEq(t, `{"baton":null,"requests":[{"type":"execute","stmt":{"sql":"SELECT sqlite_version()","want_rows":true}},{"type":"close"}]}`, string(srv.reqs[0]))
+}
+
+func TestRemote_Tx(t *testing.T) {
+	srv := newLibsqlServer(t, map[string]any{
+		"BEGIN": map[string]any{},
+		"INSERT INTO t VALUES (?, ?, ?, ?)": map[string]any{
+			"affected_row_count": 1,
+			"last_insert_rowid":  "10",
+		},
+		"SELECT a, b FROM t": map[string]any{
+			"cols": []any{map[string]any{"name": "a", "decltype": "integer"}, map[string]any{"name": "b", "decltype": "blob"}},
+			"rows": []any{[]any{map[string]any{"type": "integer", "value": "1"}, map[string]any{"type": "blob", "base64": "AQI="}}},
+		},
+		"COMMIT": map[string]any{},
+	})
+	db := sql.OpenDB(&remoteConnector{url: srv.URL, client: srv.Client()})
+	defer db.Close()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	res, err := tx.Exec("INSERT INTO t VALUES (?, ?, ?, ?)", 1, "a", []byte{1, 2}, nil)
+	require.NoError(t, err)
+	n, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	id, err := res.LastInsertId()
+	require.NoError(t, err)
+	require.EqualValues(t, 10, id)
+	rows, err := tx.Query("SELECT a, b FROM t")
+	require.NoError(t, err)
+	types, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	require.Equal(t, "INTEGER", types[0].DatabaseTypeName())
+	require.Equal(t, "BLOB", types[1].DatabaseTypeName())
+	require.True(t, rows.Next())
+	var (
+		a int
+		b []byte
+	)
+	require.NoError(t, rows.Scan(&a, &b))
+	require.Equal(t, 1, a)
+	require.Equal(t, []byte{1, 2}, b)
+	require.False(t, rows.Next())
+	require.NoError(t, rows.Close())
+	_, err = tx.Exec("DELETE FROM t")
+	require.EqualError(t, err, "sql/sqlite: no such table: t (SQLITE_ERROR)")
+	require.NoError(t, tx.Commit())
+
+	require.Len(t, srv.reqs, 5)
+	// The stream is kept open using the baton until the transaction ends.
+	require.JSONEq(t, `{"baton":null,"requests":[{"type":"execute","stmt":{"sql":"BEGIN","want_rows":true}}]}`, string(srv.reqs[0]))
+	require.JSONEq(t, `{"baton":"baton","requests":[{"type":"execute","stmt":{"sql":"INSERT INTO t VALUES (?, ?, ?, ?)","args":[{"type":"integer","value":"1"},{"type":"text","value":"a"},{"type":"blob","base64":"AQI="},{"type":"null"}],"want_rows":true}}]}`, string(srv.reqs[1]))
+	require.JSONEq(t, `{"baton":"baton","requests":[{"type":"execute","stmt":{"sql":"COMMIT","want_rows":true}},{"type":"close"}]}`, string(srv.reqs[4]))
+}
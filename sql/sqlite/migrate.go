@@ -71,11 +71,20 @@ type state struct {
 	migrate.Plan
 	migrate.PlanOptions
 	skipFKs bool
+	// Tables that were recreated by the plan.
+	recreated map[string]bool
 }
 
 // Exec executes the changes on the database. An error is returned
 // if one of the operations fail, or a change is not supported.
 func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
+	changes, data := migrate.SplitDataChanges(changes)
+	if s.Quote == migrate.QuoteNever {
+		if err := sqlx.CheckUnquoted(changes, mustQuote); err != nil {
+			return err
+		}
+	}
+	var triggers []schema.Change
 	for _, c := range changes {
 		switch c := c.(type) {
 		case *schema.AddTable:
@@ -94,6 +103,8 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
 			err = s.modifyView(c)
 		case *schema.RenameView:
 			err = s.renameView(c)
+		case *schema.AddTrigger, *schema.DropTrigger, *schema.ModifyTrigger:
+			triggers = append(triggers, c)
 		default:
 			err = fmt.Errorf("unsupported change %T", c)
 		}
@@ -101,7 +112,10 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
 			return err
 		}
 	}
-	return nil
+	if err := s.triggerChanges(triggers); err != nil {
+		return err
+	}
+	return s.planData(data)
 }
 
 // addTable builds and executes the query for creating a table in a schema.
@@ -228,7 +242,20 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 		Source:  modify,
 		Comment: fmt.Sprintf("rename temporary table %q to %q", newT.Name, modify.T.Name),
 	})
-	return s.addIndexes(modify.T, indexes...)
+	if err := s.addIndexes(modify.T, indexes...); err != nil {
+		return err
+	}
+	// Triggers are dropped along with the table.
+	if s.recreated == nil {
+		s.recreated = make(map[string]bool)
+	}
+	s.recreated[modify.T.Name] = true
+	for _, t := range modify.T.Triggers {
+		if err := s.addTrigger(t, modify); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *state) renameTable(c *schema.RenameTable) {
@@ -571,7 +598,14 @@ func autoincPK(pk *schema.Index) bool {
 
 // Build instantiates a new builder and writes the given phrase to it.
 func (s *state) Build(phrases ...string) *sqlx.Builder {
-	b := &sqlx.Builder{QuoteOpening: '`', QuoteClosing: '`', Schema: s.SchemaQualifier, Indent: s.Indent}
+	b := &sqlx.Builder{
+		QuoteOpening: '`',
+		QuoteClosing: '`',
+		Schema:       s.SchemaQualifier,
+		Indent:       s.Indent,
+		QuotePolicy:  s.Quote,
+		MustQuote:    mustQuote,
+	}
 	return b.P(phrases...)
 }
 
@@ -0,0 +1,36 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqliteaudit provides the SQLite dialect of the audit package.
+package sqliteaudit
+
+import (
+	"strings"
+
+	"ariga.io/atlas/sql/audit"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+)
+
+// Dialect is the SQLite dialect. Changes are recorded by a trigger for each operation,
+// that converts the old and new rows to JSON objects. SQLite has no users, and therefore,
+// the actor is NULL unless configured otherwise (e.g. using an application-defined function).
+var Dialect = &audit.Dialect{
+	ID: func() *schema.Column {
+		return schema.NewIntColumn(audit.ColumnID, sqlite.TypeInteger).
+			AddAttrs(&sqlite.AutoIncrement{})
+	},
+	String: &schema.StringType{T: sqlite.TypeText},
+	JSON:   &schema.JSONType{T: "json"},
+	Time:   &schema.TimeType{T: "datetime"},
+	Now:    "CURRENT_TIMESTAMP",
+	Actor:  "NULL",
+	Triggers: func(a *audit.Audit) error {
+		return audit.RowTriggers(a, "json_object", quote)
+	},
+}
+
+func quote(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
@@ -0,0 +1,41 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqliteaudit_test
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/audit"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+	"ariga.io/atlas/sql/sqlite/sqliteaudit"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect(t *testing.T) {
+	s := schema.New("main").AddTables(
+		schema.NewTable("users").AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewStringColumn("user's name", "text"),
+		),
+	)
+	desired, err := audit.Generate(sqliteaudit.Dialect, s)
+	require.NoError(t, err)
+	users, _ := desired.Table("users")
+	log, ok := desired.Table("users_audit")
+	require.True(t, ok)
+	changes := []schema.Change{&schema.AddTable{T: log}}
+	for _, tr := range users.Triggers {
+		changes = append(changes, &schema.AddTrigger{T: tr})
+	}
+	plan, err := sqlite.DefaultPlan.PlanChanges(context.Background(), "audit", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, "CREATE TABLE `users_audit` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `operation` text NOT NULL, `old_row` json NULL, `new_row` json NULL, `actor` text NULL, `changed_at` datetime NOT NULL DEFAULT (CURRENT_TIMESTAMP))", plan.Changes[0].Cmd)
+	require.Equal(t, "CREATE TRIGGER `users_audit_update` AFTER UPDATE ON `users` FOR EACH ROW BEGIN INSERT INTO `users_audit` (`operation`, `old_row`, `new_row`, `actor`) VALUES ('UPDATE', json_object('id', OLD.`id`, 'user''s name', OLD.`user's name`), json_object('id', NEW.`id`, 'user''s name', NEW.`user's name`), NULL); END", plan.Changes[2].Cmd)
+	require.Equal(t, "DROP TRIGGER `users_audit_update`", plan.Changes[2].Reverse)
+}
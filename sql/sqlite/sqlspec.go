@@ -6,7 +6,6 @@ package sqlite
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"ariga.io/atlas/schemahcl"
@@ -15,6 +14,7 @@ import (
 	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlspec"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -35,7 +35,7 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 		}
 		if err := specutil.Scan(v,
 			&specutil.ScanDoc{Schemas: d.Schemas, Tables: d.Tables, Views: d.Views},
-			&specutil.ScanFuncs{Table: convertTable, View: convertView},
+			&specutil.ScanFuncs{Table: convertTable, View: convertView, WarnRefs: true, Mixins: mixins},
 		); err != nil {
 			return fmt.Errorf("specutil: failed converting to *schema.Realm: %w", err)
 		}
@@ -50,7 +50,7 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 		r := &schema.Realm{}
 		if err := specutil.Scan(r,
 			&specutil.ScanDoc{Schemas: d.Schemas, Tables: d.Tables, Views: d.Views},
-			&specutil.ScanFuncs{Table: convertTable, View: convertView},
+			&specutil.ScanFuncs{Table: convertTable, View: convertView, WarnRefs: true, Mixins: mixins},
 		); err != nil {
 			return err
 		}
@@ -63,6 +63,26 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 	return nil
 }
 
+// JSONSchema returns a JSON Schema document describing the blocks, attributes and
+// types of the JSON representation of Atlas HCL documents for this driver.
+func JSONSchema() ([]byte, error) {
+	return hclState.JSONSchema(&doc{})
+}
+
+// ValidateHCL checks the parsed Atlas HCL documents for structural errors, such as
+// duplicate definitions, broken references or invalid types, without connecting to
+// a database. All errors found are returned as diagnostics.
+func ValidateHCL(p *hclparse.Parser, input map[string]cty.Value) hcl.Diagnostics {
+	var d doc
+	if err := hclState.Eval(p, &d, input); err != nil {
+		return schemahcl.Diagnostics(err)
+	}
+	return specutil.Validate(
+		&specutil.ScanDoc{Schemas: d.Schemas, Tables: d.Tables, Views: d.Views},
+		&specutil.ScanFuncs{Table: convertTable, View: convertView, WarnRefs: true, Mixins: mixins},
+	)
+}
+
 // MarshalSpec marshals v into an Atlas DDL document using a schemahcl.Marshaler.
 func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
 	return specutil.Marshal(v, marshaler, schemaSpec)
@@ -211,7 +231,7 @@ func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 		return nil, err
 	}
 	if i := (IndexPredicate{}); sqlx.Has(idx.Attrs, &i) && i.P != "" {
-		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("where", strconv.Quote(i.P)))
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("where", i.P))
 	}
 	return spec, nil
 }
@@ -298,6 +318,19 @@ var (
 	// EvalHCLBytes is a helper that evaluates an HCL document from a byte slice instead
 	// of from an hclparse.Parser instance.
 	EvalHCLBytes = specutil.HCLBytesFunc(EvalHCL)
+
+	// MergeHCL is a helper that evaluates multiple parsed HCL documents and merges them
+	// into one Realm, reporting duplicate definitions across the documents as errors.
+	MergeHCL = specutil.HCLMergeFunc(EvalHCL, ValidateHCL)
+
+	// mixins holds the builtin mixins that can be attached to table blocks. SQLite does
+	// not support ON UPDATE expressions, and therefore, timestamps can add only created_at.
+	mixins = map[string]specutil.MixinFunc{
+		specutil.MixinTimestamps: specutil.TimestampsMixin(&schemahcl.Type{T: "datetime"}, "CURRENT_TIMESTAMP", ""),
+		specutil.MixinSoftDelete: specutil.SoftDeleteMixin(&schemahcl.Type{T: "datetime"}, func(s string) string {
+			return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+		}),
+	}
 )
 
 // storedOrVirtual returns a STORED or VIRTUAL
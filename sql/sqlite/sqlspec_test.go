@@ -9,7 +9,11 @@ import (
 	"testing"
 
 	"ariga.io/atlas/sql/internal/spectest"
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/stretchr/testify/require"
 )
 
@@ -329,6 +333,68 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestSQLSpec_IndexPredicateRoundTrip(t *testing.T) {
+	for _, p := range []string{
+		"id <> 0",
+		`name <> 'a "b"'`,
+		"id > 0\n  AND name IS NOT NULL",
+		"name = '${name}' OR name = '%{name}'",
+	} {
+		users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text"))
+		users.AddIndexes(schema.NewIndex("idx").AddColumns(users.Columns[0]).AddAttrs(&IndexPredicate{P: p}))
+		buf, err := MarshalSpec(schema.New("test").AddTables(users), hclState)
+		require.NoError(t, err)
+		var s schema.Schema
+		require.NoError(t, EvalHCLBytes(buf, &s, nil), string(buf))
+		idx, ok := s.Tables[0].Index("idx")
+		require.True(t, ok)
+		var got IndexPredicate
+		require.True(t, sqlx.Has(idx.Attrs, &got))
+		require.Equal(t, p, got.P)
+	}
+}
+
+func TestValidateHCL_RefKey(t *testing.T) {
+	f := []byte(`
+schema "main" {}
+
+table "users" {
+  schema = schema.main
+  column "id" {
+    type = integer
+  }
+  column "email" {
+    type = text
+  }
+  primary_key {
+    columns = [column.id]
+  }
+}
+
+table "invites" {
+  schema = schema.main
+  column "email" {
+    type = text
+  }
+  foreign_key "invitee" {
+    columns     = [column.email]
+    ref_columns = [table.users.column.email]
+  }
+}
+`)
+	// SQLite accepts foreign keys that do not reference a unique key.
+	var s schema.Schema
+	require.NoError(t, EvalHCLBytes(f, &s, nil))
+	p := hclparse.NewParser()
+	_, diags := p.ParseHCL(f, "schema.hcl")
+	require.False(t, diags.HasErrors())
+	diags = ValidateHCL(p, nil)
+	require.Len(t, diags, 1)
+	require.Equal(t, hcl.DiagWarning, diags[0].Severity)
+	require.Equal(t, `specutil: referenced columns ("email") of foreign-key "invitee" do not form a unique key in table "users"`, diags[0].Summary)
+	require.Equal(t, 22, diags[0].Subject.Start.Line)
+}
+
 func TestTypes(t *testing.T) {
 	for _, tt := range []struct {
 		typeExpr string
@@ -518,3 +584,49 @@ schema "test" {
 func TestInputVars(t *testing.T) {
 	spectest.TestInputVars(t, EvalHCL)
 }
+
+func TestSQLSpec_Mixins(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `
+schema "main" {}
+table "users" {
+  schema = schema.main
+  column "email" {
+    type = text
+  }
+  index "users_email" {
+    unique  = true
+    columns = [column.email]
+  }
+  mixin "timestamps" {
+    updated = ""
+  }
+  mixin "soft_delete" {}
+}
+`
+	)
+	require.NoError(t, EvalHCLBytes([]byte(f), &s, nil))
+	users := s.Tables[0]
+	require.Len(t, users.Columns, 3)
+	c, ok := users.Column("created_at")
+	require.True(t, ok)
+	require.False(t, c.Type.Null)
+	require.Equal(t, &schema.TimeType{T: "datetime"}, c.Type.Type)
+	require.Equal(t, &schema.RawExpr{X: "CURRENT_TIMESTAMP"}, c.Default)
+	c, ok = users.Column("deleted_at")
+	require.True(t, ok)
+	require.True(t, c.Type.Null)
+	var p IndexPredicate
+	require.True(t, sqlx.Has(users.Indexes[0].Attrs, &p))
+	require.Equal(t, "`deleted_at` IS NULL", p.P)
+
+	err := EvalHCLBytes([]byte(`
+schema "main" {}
+table "users" {
+  schema = schema.main
+  mixin "timestamps" {}
+}
+`), &s, nil)
+	require.ErrorContains(t, err, `column "updated_at" cannot be maintained on updates without ON UPDATE support`)
+}
@@ -0,0 +1,59 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// inspectStats collects the row estimates of the inspected tables from the sqlite_stat1
+// table, that is populated by the ANALYZE command. SQLite does not report the size of
+// tables, and tables that were not analyzed are left without statistics.
+func (i *inspect) inspectStats(ctx context.Context, r *schema.Realm) error {
+	rows, err := i.QueryContext(ctx, statTableExistsQuery)
+	if err != nil {
+		return fmt.Errorf("sqlite: checking statistics table existence: %w", err)
+	}
+	var n int
+	if err := sqlx.ScanOne(rows, &n); err != nil {
+		return fmt.Errorf("sqlite: scanning statistics table existence: %w", err)
+	}
+	if n == 0 {
+		return nil
+	}
+	if rows, err = i.QueryContext(ctx, tableStatsQuery); err != nil {
+		return fmt.Errorf("sqlite: querying table statistics: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name      string
+			tableRows sql.NullInt64
+		)
+		if err := rows.Scan(&name, &tableRows); err != nil {
+			return fmt.Errorf("sqlite: scanning table statistics: %w", err)
+		}
+		for _, s := range r.Schemas {
+			if t, ok := s.Table(name); ok {
+				t.Attrs = append(t.Attrs, &schema.TableStats{Rows: tableRows.Int64})
+			}
+		}
+	}
+	return rows.Err()
+}
+
+const (
+	// Query to check if the statistics table exists. It is created by the first ANALYZE command.
+	statTableExistsQuery = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'sqlite_stat1'"
+
+	// Query to list the row estimates of the analyzed tables. The first integer of the stat
+	// column holds the number of rows in the table (or in the index, which are the same).
+	tableStatsQuery = "SELECT `tbl`, MAX(CAST(`stat` AS INTEGER)) FROM `sqlite_stat1` GROUP BY `tbl` ORDER BY `tbl`"
+)
@@ -0,0 +1,44 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect_Stats(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		pets  = schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "int"))
+		r     = schema.NewRealm(schema.New("main").AddTables(users, pets))
+		i     = &inspect{conn: &conn{ExecQuerier: db}}
+	)
+	// Database was never analyzed.
+	m.ExpectQuery(sqltest.Escape(statTableExistsQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	require.NoError(t, i.inspectStats(context.Background(), r))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Empty(t, users.Attrs)
+
+	m.ExpectQuery(sqltest.Escape(statTableExistsQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	m.ExpectQuery(sqltest.Escape(tableStatsQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"tbl", "rows"}).
+			AddRow("users", 1000).
+			AddRow("unknown", 10))
+	require.NoError(t, i.inspectStats(context.Background(), r))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, []schema.Attr{&schema.TableStats{Rows: 1000}}, users.Attrs)
+	require.Empty(t, pets.Attrs, "tables that were not analyzed have no statistics")
+}
@@ -0,0 +1,145 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// inspectTriggers inspects the triggers of the schema tables. Note, only the action time,
+// the event and the body of the triggers are inspected, and the UPDATE OF columns and the
+// WHEN conditions are ignored.
+func (i *inspect) inspectTriggers(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.QueryContext(ctx, triggersQuery)
+	if err != nil {
+		return fmt.Errorf("sqlite: querying triggers: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, table, stmt string
+		if err := rows.Scan(&name, &table, &stmt); err != nil {
+			return fmt.Errorf("sqlite: scanning trigger: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			continue
+		}
+		tr, err := parseTrigger(stmt)
+		if err != nil {
+			return fmt.Errorf("sqlite: parsing trigger %q: %w", name, err)
+		}
+		tr.Name = name
+		t.AddTriggers(tr)
+	}
+	return rows.Err()
+}
+
+// reTrigger matches the parts of a CREATE TRIGGER statement that follow the trigger name.
+var reTrigger = regexp.MustCompile(`(?is)\s(BEFORE\s+|AFTER\s+|INSTEAD\s+OF\s+)?(INSERT|UPDATE|DELETE)\b.*?\bON\s.*?\bBEGIN\b(.*)\bEND\s*;?\s*$`)
+
+// parseTrigger parses the action time, event and body of the trigger statement.
+func parseTrigger(stmt string) (*schema.Trigger, error) {
+	m := reTrigger.FindStringSubmatch(stmt)
+	if m == nil {
+		return nil, fmt.Errorf("unexpected trigger definition: %q", stmt)
+	}
+	// The action time defaults to BEFORE.
+	at := schema.TriggerTimeBefore
+	if m[1] != "" {
+		at = schema.TriggerTime(strings.ToUpper(strings.Join(strings.Fields(m[1]), " ")))
+	}
+	return &schema.Trigger{
+		ActionTime: at,
+		Events:     []schema.TriggerEvent{schema.TriggerEvent(strings.ToUpper(m[2]))},
+		For:        schema.TriggerForRow,
+		Body:       strings.TrimSpace(m[3]),
+	}, nil
+}
+
+// triggerChanges plans the trigger changes. Triggers of tables that were recreated by the
+// plan were dropped along with their tables, and are created again from the desired state.
+func (s *state) triggerChanges(changes []schema.Change) error {
+	for _, c := range changes {
+		var err error
+		switch c := c.(type) {
+		case *schema.AddTrigger:
+			if !s.recreated[c.T.Table.Name] {
+				err = s.addTrigger(c.T, c)
+			}
+		case *schema.DropTrigger:
+			if !s.recreated[c.T.Table.Name] {
+				err = s.dropTrigger(c)
+			}
+		case *schema.ModifyTrigger:
+			if !s.recreated[c.To.Table.Name] {
+				if err = s.dropTrigger(&schema.DropTrigger{T: c.From}); err == nil {
+					err = s.addTrigger(c.To, c)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTrigger appends the statement for creating the trigger.
+func (s *state) addTrigger(t *schema.Trigger, source schema.Change) error {
+	b, err := s.triggerDef(t)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Cmd:     b,
+		Source:  source,
+		Reverse: s.Build("DROP TRIGGER").Ident(t.Name).String(),
+		Comment: fmt.Sprintf("create trigger %q on table %q", t.Name, t.Table.Name),
+	})
+	return nil
+}
+
+// dropTrigger appends the statement for dropping the trigger.
+func (s *state) dropTrigger(drop *schema.DropTrigger) error {
+	rv, err := s.triggerDef(drop.T)
+	if err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Cmd:     s.Build("DROP TRIGGER").Ident(drop.T.Name).String(),
+		Source:  drop,
+		Reverse: rv,
+		Comment: fmt.Sprintf("drop trigger %q from table %q", drop.T.Name, drop.T.Table.Name),
+	})
+	return nil
+}
+
+// triggerDef returns the CREATE TRIGGER statement of the trigger.
+func (s *state) triggerDef(t *schema.Trigger) (string, error) {
+	switch {
+	case len(t.Events) != 1:
+		return "", fmt.Errorf("sqlite: trigger %q must have exactly one event, got %d", t.Name, len(t.Events))
+	case t.For != "" && t.For != schema.TriggerForRow:
+		return "", fmt.Errorf("sqlite: trigger %q must be a row-level trigger", t.Name)
+	}
+	body := strings.TrimSpace(t.Body)
+	if !strings.HasSuffix(body, ";") {
+		body += ";"
+	}
+	b := s.Build("CREATE TRIGGER").Ident(t.Name)
+	if t.ActionTime != "" {
+		b.P(string(t.ActionTime))
+	}
+	return b.P(string(t.Events[0]), "ON").Ident(t.Table.Name).P("FOR EACH ROW BEGIN", body, "END").String(), nil
+}
+
+// Query to list the triggers of the main database.
+const triggersQuery = "SELECT `name`, `tbl_name`, `sql` FROM `sqlite_master` WHERE `type` = 'trigger' ORDER BY `name`"
@@ -0,0 +1,146 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrigger(t *testing.T) {
+	for stmt, want := range map[string]*schema.Trigger{
+		"CREATE TRIGGER t AFTER INSERT ON users BEGIN INSERT INTO logs VALUES (NEW.id); END": {
+			ActionTime: schema.TriggerTimeAfter,
+			Events:     []schema.TriggerEvent{schema.TriggerEventInsert},
+			Body:       "INSERT INTO logs VALUES (NEW.id);",
+		},
+		"CREATE TRIGGER IF NOT EXISTS `t` update OF name ON `users`\nFOR EACH ROW WHEN NEW.name <> OLD.name\nBEGIN\n\tSELECT 1;\nEND;": {
+			ActionTime: schema.TriggerTimeBefore,
+			Events:     []schema.TriggerEvent{schema.TriggerEventUpdate},
+			Body:       "SELECT 1;",
+		},
+		"CREATE TRIGGER t INSTEAD  OF DELETE ON v BEGIN DELETE FROM users WHERE id = OLD.id; END": {
+			ActionTime: schema.TriggerTimeInstead,
+			Events:     []schema.TriggerEvent{schema.TriggerEventDelete},
+			Body:       "DELETE FROM users WHERE id = OLD.id;",
+		},
+	} {
+		want.For = schema.TriggerForRow
+		got, err := parseTrigger(stmt)
+		require.NoError(t, err, stmt)
+		require.Equal(t, want, got, stmt)
+	}
+	_, err := parseTrigger("CREATE TABLE t(c int)")
+	require.Error(t, err)
+}
+
+func TestDriver_InspectTriggers(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.tableExists("users", true, "CREATE TABLE users(id INTEGER)")
+	mk.noColumns("users")
+	mk.noIndexes("users")
+	mk.noFKs("users")
+	mk.ExpectQuery(sqltest.Escape(triggersQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "tbl_name", "sql"}).
+			AddRow("users_insert", "users", "CREATE TRIGGER users_insert AFTER INSERT ON users BEGIN SELECT 1; END").
+			AddRow("other", "other", "CREATE TRIGGER other AFTER INSERT ON other BEGIN SELECT 1; END"))
+	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{
+		Tables:   []string{"users"},
+		Mode:     ^schema.InspectViews,
+		Triggers: true,
+	})
+	require.NoError(t, err)
+	users := s.Tables[0]
+	require.Len(t, users.Triggers, 1)
+	require.Equal(t, "users_insert", users.Triggers[0].Name)
+	require.True(t, users.Triggers[0].Table == users)
+	require.Equal(t, "SELECT 1;", users.Triggers[0].Body)
+}
+
+func TestPlanChanges_Triggers(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+	tr1 := &schema.Trigger{Name: "users_insert", ActionTime: schema.TriggerTimeAfter, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "SELECT 1"}
+	tr2 := &schema.Trigger{Name: "users_insert", ActionTime: schema.TriggerTimeBefore, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "SELECT 2;"}
+	users.AddTriggers(tr1)
+	plan, err := DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.AddTrigger{T: tr1},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "CREATE TRIGGER `users_insert` AFTER INSERT ON `users` FOR EACH ROW BEGIN SELECT 1; END", plan.Changes[1].Cmd)
+	require.Equal(t, "DROP TRIGGER `users_insert`", plan.Changes[1].Reverse)
+
+	schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")).AddTriggers(tr2)
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTrigger{From: tr1, To: tr2},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "DROP TRIGGER `users_insert`", plan.Changes[0].Cmd)
+	require.Equal(t, "CREATE TRIGGER `users_insert` BEFORE INSERT ON `users` FOR EACH ROW BEGIN SELECT 2; END", plan.Changes[1].Cmd)
+
+	// Triggers of recreated tables are created again from the desired state.
+	plan, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T: tr2.Table,
+			Changes: []schema.Change{
+				&schema.ModifyColumn{From: schema.NewIntColumn("id", "int"), To: tr2.Table.Columns[0], Change: schema.ChangeNull},
+			},
+		},
+		&schema.ModifyTrigger{From: tr1, To: tr2},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TRIGGER `users_insert` BEFORE INSERT ON `users` FOR EACH ROW BEGIN SELECT 2; END", plan.Changes[len(plan.Changes)-2].Cmd)
+	for _, c := range plan.Changes {
+		require.NotEqual(t, "DROP TRIGGER `users_insert`", c.Cmd)
+	}
+
+	_, err = DefaultPlan.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTrigger{T: &schema.Trigger{Name: "t", Table: users, Events: []schema.TriggerEvent{schema.TriggerEventInsert, schema.TriggerEventDelete}}},
+	})
+	require.EqualError(t, err, `sqlite: trigger "t" must have exactly one event, got 2`)
+}
+
+func TestDiff_Triggers(t *testing.T) {
+	var (
+		from = schema.New("main").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")).AddTriggers(
+				&schema.Trigger{Name: "t1", ActionTime: schema.TriggerTimeAfter, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "SELECT 1;"},
+				&schema.Trigger{Name: "t2", ActionTime: schema.TriggerTimeAfter, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "SELECT 1;"},
+				&schema.Trigger{Name: "t3", ActionTime: schema.TriggerTimeAfter, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "SELECT 1;"},
+			),
+		)
+		to = schema.New("main").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")).AddTriggers(
+				&schema.Trigger{Name: "t1", ActionTime: "after", Events: []schema.TriggerEvent{"insert"}, Body: "  select 1  "},
+				&schema.Trigger{Name: "t3", ActionTime: schema.TriggerTimeAfter, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "SELECT 2;"},
+				&schema.Trigger{Name: "t4", ActionTime: schema.TriggerTimeAfter, Events: []schema.TriggerEvent{schema.TriggerEventDelete}, Body: "SELECT 1;"},
+			),
+			schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "int")).AddTriggers(
+				&schema.Trigger{Name: "t5", ActionTime: schema.TriggerTimeAfter, Events: []schema.TriggerEvent{schema.TriggerEventInsert}, Body: "SELECT 1;"},
+			),
+		)
+	)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+	require.IsType(t, &schema.AddTable{}, changes[0])
+	require.Equal(t, "t2", changes[1].(*schema.DropTrigger).T.Name)
+	require.Equal(t, "t3", changes[2].(*schema.ModifyTrigger).To.Name)
+	require.Equal(t, "t4", changes[3].(*schema.AddTrigger).T.Name)
+	require.Equal(t, "t5", changes[4].(*schema.AddTrigger).T.Name)
+}